@@ -0,0 +1,69 @@
+// Package apiusage tracks how close the app is to Instagram's rate limits, as reported with
+// Graph API responses, so that background jobs can back off before requests get throttled.
+package apiusage
+
+import (
+	"sync"
+	"time"
+)
+
+// Usage is a rate limit usage report
+type Usage struct {
+	Percent  int        // Highest usage across the reported limits, 0-100
+	RegainAt *time.Time // When Instagram lets a throttled app or account call again; nil if not throttled
+	At       time.Time  // When the report was received
+}
+
+// Monitor keeps the latest usage report and decides when background jobs should pause.
+// A nil Monitor never reports throttling. All methods are safe for concurrent use.
+type Monitor struct {
+	threshold int           // Usage percent at which jobs pause; 0 disables pausing
+	cooldown  time.Duration // How long a report at or over the threshold pauses jobs
+
+	mu   sync.Mutex
+	last *Usage
+}
+
+// NewMonitor creates a monitor that pauses jobs for cooldown after usage reaches threshold percent
+func NewMonitor(threshold int, cooldown time.Duration) *Monitor {
+	return &Monitor{threshold: threshold, cooldown: cooldown}
+}
+
+// Record stores a usage report; the latest report replaces the previous one
+func (m *Monitor) Record(u Usage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.last = &u
+}
+
+// Last returns the latest usage report, or nil before the first one
+func (m *Monitor) Last() *Usage {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.last == nil {
+		return nil
+	}
+	u := *m.last
+	return &u
+}
+
+// Throttled reports whether jobs should pause at now: Instagram has not let the app regain
+// access yet, or the latest report reached the threshold less than a cooldown ago.
+// Paused jobs make no requests, so an old report at the threshold expires after the cooldown
+// and the next run probes the usage again.
+func (m *Monitor) Throttled(now time.Time) bool {
+	if m == nil || m.threshold <= 0 {
+		return false
+	}
+	u := m.Last()
+	if u == nil {
+		return false
+	}
+	if u.RegainAt != nil && now.Before(*u.RegainAt) {
+		return true
+	}
+	return u.Percent >= m.threshold && now.Sub(u.At) < m.cooldown
+}
@@ -0,0 +1,66 @@
+package apiusage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestThrottledAtThreshold(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	m := NewMonitor(90, 5*time.Minute)
+
+	if m.Throttled(now) {
+		t.Error("throttled before any report")
+	}
+
+	m.Record(Usage{Percent: 89, At: now})
+	if m.Throttled(now) {
+		t.Error("throttled below the threshold")
+	}
+
+	m.Record(Usage{Percent: 90, At: now})
+	if !m.Throttled(now.Add(time.Minute)) {
+		t.Error("not throttled at the threshold")
+	}
+	// Without new reports the pause ends after the cooldown
+	if m.Throttled(now.Add(5 * time.Minute)) {
+		t.Error("still throttled after the cooldown")
+	}
+
+	// A report below the threshold resumes jobs right away
+	m.Record(Usage{Percent: 40, At: now.Add(2 * time.Minute)})
+	if m.Throttled(now.Add(2 * time.Minute)) {
+		t.Error("throttled after usage recovered")
+	}
+}
+
+func TestThrottledUntilRegainAccess(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	regain := now.Add(10 * time.Minute)
+	m := NewMonitor(90, time.Minute)
+
+	m.Record(Usage{Percent: 100, RegainAt: &regain, At: now})
+	if !m.Throttled(now.Add(9 * time.Minute)) {
+		t.Error("not throttled before Instagram lets the app regain access")
+	}
+	if m.Throttled(regain) {
+		t.Error("throttled after the regain time")
+	}
+}
+
+func TestDisabledMonitorNeverThrottles(t *testing.T) {
+	now := time.Now()
+	var nilMonitor *Monitor
+	if nilMonitor.Throttled(now) || nilMonitor.Last() != nil {
+		t.Error("nil monitor reports usage")
+	}
+
+	m := NewMonitor(0, time.Minute)
+	m.Record(Usage{Percent: 100, At: now})
+	if m.Throttled(now) {
+		t.Error("throttled with pausing disabled")
+	}
+	if u := m.Last(); u == nil || u.Percent != 100 {
+		t.Errorf("Last = %+v, want the recorded report", u)
+	}
+}
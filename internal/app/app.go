@@ -1,14 +1,19 @@
 package app
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"image/color"
 	"io"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -16,9 +21,14 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/vadim/neo-metric/internal/apiusage"
 	"github.com/vadim/neo-metric/internal/config"
 	httpcontroller "github.com/vadim/neo-metric/internal/controller/http"
 	"github.com/vadim/neo-metric/internal/database"
+	auditDao "github.com/vadim/neo-metric/internal/domain/audit/dao"
+	auditEntity "github.com/vadim/neo-metric/internal/domain/audit/entity"
+	auditService "github.com/vadim/neo-metric/internal/domain/audit/service"
+	commentClassifier "github.com/vadim/neo-metric/internal/domain/comment/classifier"
 	commentDao "github.com/vadim/neo-metric/internal/domain/comment/dao"
 	commentEntity "github.com/vadim/neo-metric/internal/domain/comment/entity"
 	commentPolicy "github.com/vadim/neo-metric/internal/domain/comment/policy"
@@ -30,6 +40,7 @@ import (
 	directScheduler "github.com/vadim/neo-metric/internal/domain/direct/scheduler"
 	directService "github.com/vadim/neo-metric/internal/domain/direct/service"
 	"github.com/vadim/neo-metric/internal/domain/publication/dao"
+	pubEntity "github.com/vadim/neo-metric/internal/domain/publication/entity"
 	"github.com/vadim/neo-metric/internal/domain/publication/policy"
 	publicationScheduler "github.com/vadim/neo-metric/internal/domain/publication/scheduler"
 	"github.com/vadim/neo-metric/internal/domain/publication/service"
@@ -37,7 +48,13 @@ import (
 	templateEntity "github.com/vadim/neo-metric/internal/domain/template/entity"
 	templatePolicy "github.com/vadim/neo-metric/internal/domain/template/policy"
 	templateService "github.com/vadim/neo-metric/internal/domain/template/service"
+	"github.com/vadim/neo-metric/internal/httpx/mediacheck"
+	httpmw "github.com/vadim/neo-metric/internal/httpx/middleware"
+	"github.com/vadim/neo-metric/internal/httpx/response"
 	"github.com/vadim/neo-metric/internal/httpx/upstream/instagram"
+	"github.com/vadim/neo-metric/internal/httpx/webhook"
+	"github.com/vadim/neo-metric/internal/imagefit"
+	"github.com/vadim/neo-metric/internal/liveness"
 	"github.com/vadim/neo-metric/internal/storage"
 )
 
@@ -50,30 +67,56 @@ type App struct {
 	pg         *pgxpool.Pool
 	s3         *storage.S3Storage
 
+	// Accepted API keys (nil when authentication is disabled)
+	apiKeys []httpmw.APIKey
+
+	// Request rate limits (nil when rate limiting is disabled)
+	rateLimit *httpmw.RateLimitOptions
+
 	// Domain policies (interfaces for HTTP handlers)
 	publicationPolicy *policy.Policy
 	commentPolicy     *commentPolicy.Policy
 	directPolicy      *directPolicy.Policy
 	templatePolicy    *templatePolicy.Policy
 
+	// Audit log of mutating actions (nil without a database)
+	auditService *auditService.Service
+
 	// Services for sync schedulers
 	commentService *commentService.Service
 	directService  *directService.Service
 
-	// Account lister for HTTP handlers
-	accountLister *accountListerAdapter
+	// Account adapters for HTTP handlers
+	accountLister     *accountListerAdapter
+	profileRefresher  *accountProfileRefresherAdapter
+	accountSyncStatus *accountSyncStatusAdapter
+
+	// Short-lived cache of account access tokens and Instagram user IDs
+	accountCredentials *dao.CredentialsCache
 
 	// Publication repository for comment sync
 	publicationRepo dao.PublicationRepository
 
+	// Outbound publication status notifications (nil when WEBHOOK_URL is empty)
+	webhook *webhook.Sender
+
 	// Scheduler for processing scheduled publications
 	scheduler *publicationScheduler.Scheduler
 
+	// Async publish queue worker
+	publishQueue *publicationScheduler.Scheduler
+
+	// Publish reconciliation job (nil if disabled)
+	publishReconciler *publicationScheduler.Scheduler
+
 	// Comment sync scheduler
 	commentSyncScheduler *commentScheduler.Scheduler
 
 	// Direct message sync scheduler
 	directSyncScheduler *directScheduler.Scheduler
+
+	// Instagram rate limit usage reported by the client; pauses the sync schedulers
+	apiUsage *apiusage.Monitor
 }
 
 // parseLogLevel converts string log level to slog.Level
@@ -94,6 +137,10 @@ func parseLogLevel(level string) slog.Level {
 
 // NewApp creates and initializes the application
 func NewApp(ctx context.Context, cfg config.Config) (*App, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
 	// Initialize logger with configurable level
 	logLevel := parseLogLevel(cfg.Logger.Level)
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
@@ -106,7 +153,28 @@ func NewApp(ctx context.Context, cfg config.Config) (*App, error) {
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Logger)
-	r.Use(middleware.Timeout(5 * time.Minute)) // Extended timeout for video processing (Reels)
+	r.Use(httpmw.Timeout(httpmw.TimeoutOptions{
+		Read:   cfg.Server.ReadRouteTimeout,
+		Write:  cfg.Server.WriteRouteTimeout,
+		Long:   cfg.Server.LongRouteTimeout,
+		IsLong: isLongRunningRoute,
+	}))
+
+	// CORS runs before routing so preflight requests are answered for every route
+	if len(cfg.CORS.AllowedOrigins) > 0 {
+		cors, err := httpmw.CORS(httpmw.CORSOptions{
+			AllowedOrigins:   cfg.CORS.AllowedOrigins,
+			AllowedMethods:   cfg.CORS.AllowedMethods,
+			AllowedHeaders:   cfg.CORS.AllowedHeaders,
+			ExposedHeaders:   cfg.CORS.ExposedHeaders,
+			AllowCredentials: cfg.CORS.AllowCredentials,
+			MaxAge:           cfg.CORS.MaxAge,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("configuring CORS: %w", err)
+		}
+		r.Use(cors)
+	}
 
 	app := &App{
 		cfg:    cfg,
@@ -114,6 +182,30 @@ func NewApp(ctx context.Context, cfg config.Config) (*App, error) {
 		logger: logger,
 	}
 
+	if cfg.Auth.Enabled {
+		keys, err := httpmw.ParseAPIKeys(cfg.Auth.APIKeys)
+		if err != nil {
+			return nil, fmt.Errorf("parsing API keys: %w", err)
+		}
+		app.apiKeys = keys
+	}
+
+	if cfg.RateLimit.Enabled {
+		tiers, err := httpmw.ParseRateLimitTiers(cfg.RateLimit.Tiers)
+		if err != nil {
+			return nil, fmt.Errorf("parsing rate limit tiers: %w", err)
+		}
+		for i, k := range app.apiKeys {
+			if _, ok := tiers[k.Tier]; k.Tier != "" && !ok {
+				return nil, fmt.Errorf("API key %d uses unknown rate limit tier %q", i, k.Tier)
+			}
+		}
+		app.rateLimit = &httpmw.RateLimitOptions{
+			Default: httpmw.Limit{Rate: cfg.RateLimit.Rate, Burst: cfg.RateLimit.Burst},
+			Tiers:   tiers,
+		}
+	}
+
 	// Initialize infrastructure
 	if err := app.initInfrastructure(ctx); err != nil {
 		return nil, fmt.Errorf("initializing infrastructure: %w", err)
@@ -136,39 +228,72 @@ func NewApp(ctx context.Context, cfg config.Config) (*App, error) {
 		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
+	// Initialize the publish queue worker; it runs whenever async publishing is on,
+	// otherwise queued publications would never leave status publishing
+	if cfg.Scheduler.PublishAsync {
+		app.publishQueue = publicationScheduler.New(
+			publicationScheduler.ProcessorFunc(app.publicationPolicy.ProcessQueuedPublications),
+			cfg.Scheduler.PublishQueueInterval,
+			logger,
+		).
+			WithName("publish queue").
+			WithStopTimeout(cfg.Scheduler.StopTimeout)
+	}
+
+	// Initialize the publish reconciliation job
+	if cfg.Scheduler.PublishReconcileEnabled {
+		app.publishReconciler = publicationScheduler.New(
+			publicationScheduler.ProcessorFunc(app.publicationPolicy.ReconcilePublications),
+			cfg.Scheduler.PublishReconcileInterval,
+			logger,
+		).
+			WithName("publish reconciliation").
+			WithJitter(cfg.Scheduler.Jitter, cfg.Scheduler.StartupJitter).
+			WithStopTimeout(cfg.Scheduler.StopTimeout)
+	}
+
 	// Initialize scheduler
 	if cfg.Scheduler.Enabled {
-		app.scheduler = publicationScheduler.New(app.publicationPolicy, cfg.Scheduler.Interval, logger)
+		app.scheduler = publicationScheduler.New(app.publicationPolicy, cfg.Scheduler.Interval, logger).
+			WithJitter(cfg.Scheduler.Jitter, cfg.Scheduler.StartupJitter).
+			WithStopTimeout(cfg.Scheduler.StopTimeout)
 
 		// Initialize comment sync scheduler if we have the necessary components
 		if app.commentService != nil && app.publicationRepo != nil && app.accountLister != nil {
 			app.commentSyncScheduler = commentScheduler.New(
 				app.commentService,
 				&publicationRepoAdapter{app.publicationRepo},
-				&accountProviderAdapter{dao.NewAccountPostgres(app.pg)},
+				&accountProviderAdapter{repo: dao.NewAccountPostgres(app.pg), creds: app.accountCredentials},
 				commentScheduler.Config{
-					Interval:   cfg.Scheduler.CommentSyncInterval,
-					SyncAge:    cfg.Scheduler.CommentSyncAge,
-					BatchSize:  cfg.Scheduler.CommentSyncBatchSize,
-					MaxRetries: cfg.Scheduler.CommentSyncMaxRetries,
+					Interval:      cfg.Scheduler.CommentSyncInterval,
+					SyncAge:       cfg.Scheduler.CommentSyncAge,
+					BatchSize:     cfg.Scheduler.CommentSyncBatchSize,
+					MaxRetries:    cfg.Scheduler.CommentSyncMaxRetries,
+					MaxPostAge:    cfg.Scheduler.CommentSyncMaxPostAge,
+					Jitter:        cfg.Scheduler.Jitter,
+					StartupJitter: cfg.Scheduler.StartupJitter,
+					StopTimeout:   cfg.Scheduler.StopTimeout,
 				},
 				logger,
-			)
+			).WithThrottle(app.apiUsage)
 		}
 
 		// Initialize direct message sync scheduler
 		if app.directService != nil && app.pg != nil {
 			app.directSyncScheduler = directScheduler.New(
 				app.directService,
-				&accountProviderAdapter{dao.NewAccountPostgres(app.pg)},
+				&accountProviderAdapter{repo: dao.NewAccountPostgres(app.pg), creds: app.accountCredentials},
 				directScheduler.Config{
-					Interval:   cfg.Scheduler.DirectSyncInterval,
-					SyncAge:    cfg.Scheduler.DirectSyncAge,
-					BatchSize:  cfg.Scheduler.DirectSyncBatchSize,
-					MaxRetries: cfg.Scheduler.DirectSyncMaxRetries,
+					Interval:      cfg.Scheduler.DirectSyncInterval,
+					SyncAge:       cfg.Scheduler.DirectSyncAge,
+					BatchSize:     cfg.Scheduler.DirectSyncBatchSize,
+					MaxRetries:    cfg.Scheduler.DirectSyncMaxRetries,
+					Jitter:        cfg.Scheduler.Jitter,
+					StartupJitter: cfg.Scheduler.StartupJitter,
+					StopTimeout:   cfg.Scheduler.StopTimeout,
 				},
 				logger,
-			)
+			).WithThrottle(app.apiUsage)
 		}
 	}
 
@@ -179,12 +304,29 @@ func NewApp(ctx context.Context, cfg config.Config) (*App, error) {
 func (a *App) initInfrastructure(ctx context.Context) error {
 	// Initialize PostgreSQL connection if DSN is provided
 	if a.cfg.Database.PostgresDSN != "" {
-		pool, err := database.NewPostgresPool(ctx, a.cfg.Database.PostgresDSN)
+		pool, err := database.NewPostgresPool(ctx, a.cfg.Database.PostgresDSN, database.PoolConfig{
+			MaxConns:        a.cfg.Database.MaxConns,
+			MinConns:        a.cfg.Database.MinConns,
+			MaxConnLifetime: a.cfg.Database.MaxConnLifetime,
+			MaxConnIdleTime: a.cfg.Database.MaxConnIdleTime,
+
+			SlowQueryThreshold: a.cfg.Database.SlowQueryThreshold,
+			Logger:             a.logger,
+		})
 		if err != nil {
 			return fmt.Errorf("connecting to postgres: %w", err)
 		}
 		a.pg = pool
-		a.logger.Info("connected to PostgreSQL")
+
+		// Effective settings, after the DSN's pool_* parameters
+		poolCfg := pool.Config()
+		a.logger.Info("connected to PostgreSQL",
+			"max_conns", poolCfg.MaxConns,
+			"min_conns", poolCfg.MinConns,
+			"max_conn_lifetime", poolCfg.MaxConnLifetime,
+			"max_conn_idle_time", poolCfg.MaxConnIdleTime,
+			"slow_query_threshold", a.cfg.Database.SlowQueryThreshold,
+		)
 	}
 
 	// Initialize S3 storage
@@ -196,6 +338,10 @@ func (a *App) initInfrastructure(ctx context.Context) error {
 			Bucket:          a.cfg.S3.Bucket,
 			Region:          a.cfg.S3.Region,
 			PublicURL:       a.cfg.S3.PublicURL,
+			UploadAttempts:  a.cfg.S3.UploadAttempts,
+
+			MultipartThreshold: a.cfg.S3.MultipartThreshold,
+			PartSize:           a.cfg.S3.PartSize,
 		})
 		if err != nil {
 			return fmt.Errorf("initializing s3 storage: %w", err)
@@ -210,12 +356,18 @@ func (a *App) initInfrastructure(ctx context.Context) error {
 // initDomains initializes domain layers (DAO, Service, Policy)
 func (a *App) initDomains(_ context.Context) error {
 	// Initialize Instagram client
+	a.apiUsage = apiusage.NewMonitor(a.cfg.Instagram.UsagePauseThreshold, a.cfg.Instagram.UsagePauseCooldown)
 	igClient := instagram.New(
 		instagram.WithBaseURL(a.cfg.Instagram.BaseURL),
 		instagram.WithAPIVersion(a.cfg.Instagram.APIVersion),
+		instagram.WithUserAgent(a.cfg.Instagram.UserAgent),
 		instagram.WithLogger(a.logger),
+		instagram.WithUsageRecorder(a.apiUsage),
 	)
 	igPublisher := instagram.NewPublisher(igClient)
+	if a.cfg.Instagram.CarouselItemCacheTTL > 0 {
+		igPublisher.WithContainerCache(instagram.NewContainerCache(a.cfg.Instagram.CarouselItemCacheTTL))
+	}
 
 	// Initialize DAOs
 	var publicationsRepo dao.PublicationRepository
@@ -223,6 +375,7 @@ func (a *App) initDomains(_ context.Context) error {
 	var accountProvider policy.AccountProvider
 	var commentRepo commentService.CommentRepository
 	var commentSyncRepo commentService.SyncStatusRepository
+	var commentAccountSettings commentService.AccountSettings
 
 	// Direct message repositories
 	var directConvRepo directService.ConversationRepository
@@ -233,45 +386,106 @@ func (a *App) initDomains(_ context.Context) error {
 	// Template repository
 	var templateRepo templateService.TemplateRepository
 
+	// Audit repository
+	var auditRepo auditService.AuditRepository
+
 	if a.pg != nil {
 		// Use PostgreSQL implementations
 		publicationsRepo = dao.NewPublicationPostgres(a.pg)
 		mediaRepo = dao.NewMediaPostgres(a.pg)
 		accountRepo := dao.NewAccountPostgres(a.pg)
-		accountProvider = &accountProviderAdapter{accountRepo}
+		a.accountCredentials = dao.NewCredentialsCache(accountRepo, a.cfg.Instagram.CredentialsCacheTTL)
+		accountProvider = &accountProviderAdapter{repo: accountRepo, creds: a.accountCredentials}
 		a.accountLister = &accountListerAdapter{accountRepo}
+		a.profileRefresher = &accountProfileRefresherAdapter{repo: accountRepo, client: igClient}
+		commentAccountSettings = accountRepo
 		a.publicationRepo = publicationsRepo
 
 		// Comment repositories
-		commentRepo = &commentRepoAdapter{commentDao.NewCommentPostgres(a.pg)}
+		commentRepo = &commentRepoAdapter{commentDao.NewCommentPostgres(a.pg).WithBatchSize(a.cfg.Database.UpsertBatchSize)}
 		commentSyncRepo = &commentSyncRepoAdapter{commentDao.NewSyncStatusPostgres(a.pg)}
 
 		// Direct message repositories
-		directConvRepo = &directConvRepoAdapter{directDao.NewConversationPostgres(a.pg)}
-		directMsgRepo = &directMsgRepoAdapter{directDao.NewMessagePostgres(a.pg)}
+		directConvRepo = &directConvRepoAdapter{directDao.NewConversationPostgres(a.pg).WithBatchSize(a.cfg.Database.UpsertBatchSize)}
+		directMsgRepo = &directMsgRepoAdapter{directDao.NewMessagePostgres(a.pg).WithBatchSize(a.cfg.Database.UpsertBatchSize)}
 		directConvSyncRepo = &directConvSyncRepoAdapter{directDao.NewConversationSyncPostgres(a.pg)}
 		directAccountSyncRepo = &directAccountSyncRepoAdapter{directDao.NewAccountSyncPostgres(a.pg)}
+		a.accountSyncStatus = &accountSyncStatusAdapter{
+			comments:      commentDao.NewSyncStatusPostgres(a.pg),
+			conversations: directDao.NewConversationSyncPostgres(a.pg),
+			accounts:      directDao.NewAccountSyncPostgres(a.pg),
+		}
 
 		// Template repository
 		templateRepo = &templateRepoAdapter{templateDao.NewTemplatePostgres(a.pg)}
+
+		// Audit repository
+		auditRepo = &auditRepoAdapter{auditDao.NewAuditPostgres(a.pg)}
+	}
+
+	// Audit log; policies record to it best-effort
+	var auditRecorder *auditRecorderAdapter
+	if auditRepo != nil {
+		a.auditService = auditService.New(auditRepo)
+		auditRecorder = &auditRecorderAdapter{svc: a.auditService, logger: a.logger}
 	}
 
 	// Initialize publication service
-	pubService := service.New(publicationsRepo, mediaRepo)
+	pubService := service.New(publicationsRepo, mediaRepo).WithDefaultSort(
+		pubEntity.PublicationSort(a.cfg.Server.PublicationsDefaultSort),
+		pubEntity.SortOrder(a.cfg.Server.PublicationsDefaultOrder),
+	)
+	if a.cfg.Webhook.URL != "" {
+		a.webhook = webhook.NewSender(a.cfg.Webhook.URL, a.cfg.Webhook.Secret, a.cfg.Webhook.Timeout, a.cfg.Webhook.MaxRetries, a.logger)
+		pubService.WithStatusNotifier(&publicationWebhookAdapter{a.webhook})
+	}
 
 	// Initialize publication policy
-	a.publicationPolicy = policy.New(pubService, &instagramPublisherAdapter{igPublisher}, accountProvider)
+	a.publicationPolicy = policy.New(pubService, &instagramPublisherAdapter{igPublisher}, accountProvider).
+		WithRetry(policy.RetryPolicy{
+			MaxAttempts: a.cfg.Scheduler.PublishMaxAttempts,
+			BaseDelay:   a.cfg.Scheduler.PublishRetryDelay,
+			MaxDelay:    a.cfg.Scheduler.PublishRetryMaxDelay,
+		}).
+		WithDailyPublishingLimit(a.cfg.Scheduler.PublishDailyLimit).
+		WithAsyncPublish(a.cfg.Scheduler.PublishAsync).
+		WithAccountAuthorizer(apiKeyScopeAdapter{})
+	if a.cfg.Scheduler.PublishPrecreateContainers {
+		a.publicationPolicy.WithContainerPrecreation(a.cfg.Scheduler.PublishPrecreateWindow)
+	}
+	if a.cfg.Scheduler.PublishReconcileEnabled {
+		a.publicationPolicy.WithReconciliation(&instagramPublisherAdapter{igPublisher})
+	}
+	if a.cfg.Scheduler.PublishCheckMediaURLs {
+		a.publicationPolicy.WithMediaURLCheck(mediacheck.New(a.cfg.Scheduler.PublishMediaCheckTimeout, a.logger))
+	}
+	if auditRecorder != nil {
+		a.publicationPolicy.WithAuditRecorder(auditRecorder)
+	}
 
 	// Initialize comment domain
 	igCommentAdapter := &instagramCommentAdapter{igClient}
 	if commentRepo != nil && commentSyncRepo != nil {
 		a.commentService = commentService.NewWithRepo(igCommentAdapter, commentRepo, commentSyncRepo).
-			WithSyncMaxAge(a.cfg.Scheduler.CommentCacheMaxAge)
+			WithSyncMaxAge(a.cfg.Scheduler.CommentCacheMaxAge).
+			WithAccountSettings(commentAccountSettings).
+			WithReconcileInterval(a.cfg.Scheduler.CommentSyncReconcileInterval)
 	} else {
 		a.commentService = commentService.New(igCommentAdapter).
 			WithSyncMaxAge(a.cfg.Scheduler.CommentCacheMaxAge)
 	}
-	a.commentPolicy = commentPolicy.New(a.commentService, accountProvider)
+	if a.cfg.Scheduler.CommentSentimentEnabled {
+		a.commentService.WithClassifier(commentClassifier.NewLexicon())
+	}
+	a.commentPolicy = commentPolicy.New(a.commentService, accountProvider).
+		WithAccountAuthorizer(apiKeyScopeAdapter{}).
+		WithInstagramMediaOwners(igCommentAdapter)
+	if a.publicationRepo != nil {
+		a.commentPolicy.WithMediaOwners(&publicationRepoAdapter{a.publicationRepo})
+	}
+	if auditRecorder != nil {
+		a.commentPolicy.WithAuditRecorder(auditRecorder)
+	}
 
 	// Initialize direct message domain
 	igDirectAdapter := &instagramDirectAdapter{igClient}
@@ -282,11 +496,21 @@ func (a *App) initDomains(_ context.Context) error {
 			directMsgRepo,
 			directConvSyncRepo,
 			directAccountSyncRepo,
-		)
+		).WithMaxMessages(a.cfg.Scheduler.DirectMaxMessagesPerConversation).
+			WithMaxMessagePages(a.cfg.Scheduler.DirectMaxMessagePages).
+			WithFullSyncInterval(a.cfg.Scheduler.DirectSyncFullInterval)
 	} else {
 		a.directService = directService.New(igDirectAdapter)
 	}
-	a.directPolicy = directPolicy.New(a.directService, accountProvider)
+	a.directPolicy = directPolicy.New(a.directService, accountProvider).
+		WithAccountAuthorizer(apiKeyScopeAdapter{})
+	if auditRecorder != nil {
+		a.directPolicy.WithAuditRecorder(auditRecorder)
+	}
+	if a.accountSyncStatus != nil {
+		a.accountSyncStatus.commentPolicy = a.commentPolicy
+		a.accountSyncStatus.directPolicy = a.directPolicy
+	}
 
 	// Wire DirectSender for send_to_direct functionality
 	if a.directService != nil && accountProvider != nil {
@@ -300,7 +524,15 @@ func (a *App) initDomains(_ context.Context) error {
 	// Initialize template domain
 	if templateRepo != nil {
 		tmplService := templateService.New(templateRepo)
-		a.templatePolicy = templatePolicy.New(tmplService)
+		a.templatePolicy = templatePolicy.New(tmplService).WithAccountAuthorizer(apiKeyScopeAdapter{})
+
+		// Wire DM templates for quick replies and template_id messages
+		if a.directPolicy != nil {
+			a.directPolicy.WithTemplateRenderer(&directTemplateAdapter{a.templatePolicy})
+		}
+
+		// Hashtag set templates are posted as the first comment of publications
+		a.publicationPolicy.WithFirstComment(&hashtagSetAdapter{a.templatePolicy}, igCommentAdapter)
 	}
 
 	return nil
@@ -312,46 +544,114 @@ func (a *App) registerRoutes() {
 	a.router.Get("/healthz", a.healthHandler)
 	a.router.Get("/readyz", a.readyHandler)
 
+	// Page sizes shared by list endpoints
+	pages := response.PageLimits{Default: a.cfg.Server.PageDefaultLimit, Max: a.cfg.Server.PageMaxLimit}
+
 	// Swagger UI documentation
 	swaggerHandler := httpcontroller.NewSwaggerHandler("Neo-Metric Instagram API", OpenAPISpec)
 	swaggerHandler.RegisterRoutes(a.router)
 
-	// API v1
+	// API v1; health checks above stay outside the rate limit
 	a.router.Route("/api/v1", func(r chi.Router) {
-		// Publication routes
-		pubHandler := httpcontroller.NewPublicationHandler(a.publicationPolicy)
-		pubHandler.RegisterRoutes(r)
+		if a.apiKeys != nil {
+			r.Use(httpmw.APIKeyAuth(a.apiKeys))
+		}
+		// After authentication, so limits apply per key rather than per proxy address
+		if a.rateLimit != nil {
+			r.Use(httpmw.RateLimit(*a.rateLimit))
+		}
+		if a.cfg.Server.Compress {
+			r.Use(httpmw.Compress(httpmw.CompressOptions{MinSize: a.cfg.Server.CompressMinSize}))
+		}
 
-		// Comment routes
-		commentHandler := httpcontroller.NewCommentHandler(a.commentPolicy)
-		commentHandler.RegisterRoutes(r)
+		// JSON endpoints: content type, body size and strict decoding
+		r.Group(func(r chi.Router) {
+			r.Use(httpmw.JSONBody(a.cfg.Server.MaxBodySize))
+
+			// Publication routes
+			pubHandler := httpcontroller.NewPublicationHandler(a.publicationPolicy).
+				WithPageLimits(pages).
+				WithAsyncPublish(a.cfg.Scheduler.PublishAsync)
+			pubHandler.RegisterRoutes(r)
+
+			// Comment routes
+			commentHandler := httpcontroller.NewCommentHandler(a.commentPolicy).WithPageLimits(pages)
+			commentHandler.RegisterRoutes(r)
+
+			// Direct message routes
+			var directStats httpcontroller.DirectStatisticsGetter
+			if a.directPolicy != nil {
+				directHandler := httpcontroller.NewDirectHandler(a.directPolicy).WithPageLimits(pages)
+				directHandler.RegisterRoutes(r)
+				directStats = a.directPolicy
+			}
 
-		// Direct message routes
-		if a.directPolicy != nil {
-			directHandler := httpcontroller.NewDirectHandler(a.directPolicy)
-			directHandler.RegisterRoutes(r)
-		}
+			// Statistics across accounts
+			statsHandler := httpcontroller.NewStatisticsHandler(a.commentPolicy, directStats)
+			statsHandler.RegisterRoutes(r)
 
-		// Template routes
-		if a.templatePolicy != nil {
-			templateHandler := httpcontroller.NewTemplateHandler(a.templatePolicy)
-			templateHandler.RegisterRoutes(r)
-		}
+			// Template routes
+			if a.templatePolicy != nil {
+				templateHandler := httpcontroller.NewTemplateHandler(a.templatePolicy).WithPageLimits(pages)
+				templateHandler.RegisterRoutes(r)
+			}
+
+			// Media fit routes
+			if a.s3 != nil {
+				fitHandler := httpcontroller.NewMediaFitHandler(&mediaFitterAdapter{a.s3})
+				fitHandler.RegisterRoutes(r)
+			}
+		})
 
 		// Account routes
 		if a.accountLister != nil {
-			accHandler := httpcontroller.NewAccountHandler(a.accountLister)
+			accHandler := httpcontroller.NewAccountHandler(a.accountLister, a.profileRefresher, a.accountSyncStatus).
+				WithPageLimits(pages).
+				WithProber(a.profileRefresher)
 			accHandler.RegisterRoutes(r)
 		}
 
+		// Audit log routes
+		if a.auditService != nil {
+			auditHandler := httpcontroller.NewAuditHandler(a.auditService).WithPageLimits(pages)
+			auditHandler.RegisterRoutes(r)
+		}
+
 		// Media upload routes
 		if a.s3 != nil {
 			mediaHandler := httpcontroller.NewMediaHandler(&mediaUploaderAdapter{a.s3})
 			mediaHandler.RegisterRoutes(r)
 		}
+
+		// Scheduler liveness
+		schedulerHandler := httpcontroller.NewSchedulerHandler(schedulerStatusAdapter{a})
+		schedulerHandler.RegisterRoutes(r)
 	})
 }
 
+// isLongRunningRoute reports whether a request waits on Instagram: publishing polls
+// container processing (minutes for Reels) and sync endpoints page through the API
+func isLongRunningRoute(r *http.Request) bool {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	if r.Method == http.MethodGet {
+		return strings.HasSuffix(path, "/export") // Streams a participant's whole message history
+	}
+	if r.Method != http.MethodPost {
+		return false
+	}
+
+	switch {
+	case path == "/api/v1/publications": // Create may publish immediately (publish_now)
+		return true
+	case strings.HasSuffix(path, "/publish"), strings.HasSuffix(path, "/sync"):
+		return true
+	case strings.HasSuffix(path, "/schedule"): // May pre-create the container and wait for processing
+		return true
+	default:
+		return false
+	}
+}
+
 // healthHandler handles health check requests
 func (a *App) healthHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -372,10 +672,77 @@ func (a *App) readyHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// A stalled scheduler leaves publications and syncs unprocessed while HTTP keeps working
+	for _, s := range (schedulerStatusAdapter{a}).SchedulerStatuses() {
+		if s.Stalled {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"status":"not ready","error":"scheduler stalled: ` + s.Name + `"}`))
+			return
+		}
+	}
+
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(`{"status":"ready"}`))
 }
 
+// schedulerStatusAdapter reports the app's schedulers for httpcontroller.SchedulerStatusProvider;
+// disabled schedulers are listed with their configured interval
+type schedulerStatusAdapter struct {
+	app *App
+}
+
+// statusReporter is implemented by every scheduler
+type statusReporter interface {
+	Status() liveness.Status
+}
+
+func (a schedulerStatusAdapter) SchedulerStatuses() []httpcontroller.SchedulerStatus {
+	cfg := a.app.cfg.Scheduler
+	now := time.Now()
+
+	throttled := a.app.apiUsage.Throttled(now)
+
+	var out []httpcontroller.SchedulerStatus
+	add := func(name string, enabled bool, s statusReporter, interval time.Duration) {
+		status := httpcontroller.SchedulerStatus{Name: name, Enabled: enabled, Interval: interval.String()}
+		if enabled {
+			st := s.Status()
+			status.Interval = st.Interval.String()
+			status.Running = st.Running
+			status.LastRunAt = st.LastRunAt
+			status.LastRunDurationMs = st.LastRunDuration.Milliseconds()
+			status.Stalled = st.Stalled(now)
+		}
+		out = append(out, status)
+	}
+	add("publication", a.app.scheduler != nil, a.app.scheduler, cfg.Interval)
+	add("publish_queue", a.app.publishQueue != nil, a.app.publishQueue, cfg.PublishQueueInterval)
+	add("publish_reconcile", a.app.publishReconciler != nil, a.app.publishReconciler, cfg.PublishReconcileInterval)
+	add("comment_sync", a.app.commentSyncScheduler != nil, a.app.commentSyncScheduler, cfg.CommentSyncInterval)
+	add("direct_sync", a.app.directSyncScheduler != nil, a.app.directSyncScheduler, cfg.DirectSyncInterval)
+
+	// Only the sync schedulers pause on high usage; publishing stays on time
+	for i := range out {
+		if out[i].Enabled && (out[i].Name == "comment_sync" || out[i].Name == "direct_sync") {
+			out[i].Throttled = throttled
+		}
+	}
+	return out
+}
+
+func (a schedulerStatusAdapter) InstagramUsage() *httpcontroller.InstagramUsage {
+	u := a.app.apiUsage.Last()
+	if u == nil {
+		return nil
+	}
+	return &httpcontroller.InstagramUsage{
+		Percent:   u.Percent,
+		Throttled: a.app.apiUsage.Throttled(time.Now()),
+		RegainAt:  u.RegainAt,
+		UpdatedAt: u.At,
+	}
+}
+
 // Run starts the application and blocks until shutdown signal
 func (a *App) Run(ctx context.Context) error {
 	// Start scheduler if enabled
@@ -383,6 +750,21 @@ func (a *App) Run(ctx context.Context) error {
 		go a.scheduler.Start(ctx)
 	}
 
+	// Start async publish queue worker if enabled
+	if a.publishQueue != nil {
+		go a.publishQueue.Start(ctx)
+	}
+
+	// Start publish reconciliation if enabled
+	if a.publishReconciler != nil {
+		go a.publishReconciler.Start(ctx)
+	}
+
+	// Check account tokens in the background; results are only reported
+	if a.cfg.Instagram.VerifyTokensOnStart && a.profileRefresher != nil {
+		go a.verifyAccountTokens(ctx)
+	}
+
 	// Start comment sync scheduler if enabled
 	if a.commentSyncScheduler != nil {
 		go a.commentSyncScheduler.Start(ctx)
@@ -421,24 +803,68 @@ func (a *App) Run(ctx context.Context) error {
 	return a.Shutdown(context.Background())
 }
 
+// verifyAccountTokens checks every account's access token against Instagram,
+// logs the accounts that need reconnecting and stores the result on the account row
+func (a *App) verifyAccountTokens(ctx context.Context) {
+	accounts, err := a.profileRefresher.repo.ListAccounts(ctx, dao.AccountFilter{})
+	if err != nil {
+		a.logger.Error("token check: listing accounts", "error", err)
+		return
+	}
+
+	var invalid int
+	for _, acc := range accounts {
+		status := a.profileRefresher.CheckToken(ctx, acc)
+		switch status {
+		case dao.TokenStatusValid:
+			a.logger.Debug("token check: token valid", "account_id", acc.ID)
+		case dao.TokenStatusError:
+			a.logger.Warn("token check: could not verify token", "account_id", acc.ID, "username", acc.Username)
+		default:
+			invalid++
+			a.logger.Warn("token check: account needs reconnecting", "account_id", acc.ID, "username", acc.Username, "token_status", status)
+			// Do not hand out the stale token; the next lookup picks up a reconnected one
+			a.accountCredentials.Invalidate(acc.ID)
+		}
+
+		if err := a.profileRefresher.repo.SetTokenStatus(ctx, acc.ID, status); err != nil {
+			a.logger.Error("token check: storing status", "account_id", acc.ID, "error", err)
+		}
+	}
+
+	a.logger.Info("token check finished", "accounts", len(accounts), "invalid", invalid)
+}
+
 // Shutdown gracefully shuts down the application
 func (a *App) Shutdown(ctx context.Context) error {
 	a.logger.Info("shutting down...")
 
-	// Stop scheduler
+	// Stop all schedulers in parallel; each Stop blocks until its in-flight
+	// iteration drains, so the database pool stays open until they are done
+	var wg sync.WaitGroup
+	stop := func(s interface{ Stop() }) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Stop()
+		}()
+	}
 	if a.scheduler != nil {
-		a.scheduler.Stop()
+		stop(a.scheduler)
+	}
+	if a.publishQueue != nil {
+		stop(a.publishQueue)
+	}
+	if a.publishReconciler != nil {
+		stop(a.publishReconciler)
 	}
-
-	// Stop comment sync scheduler
 	if a.commentSyncScheduler != nil {
-		a.commentSyncScheduler.Stop()
+		stop(a.commentSyncScheduler)
 	}
-
-	// Stop direct message sync scheduler
 	if a.directSyncScheduler != nil {
-		a.directSyncScheduler.Stop()
+		stop(a.directSyncScheduler)
 	}
+	wg.Wait()
 
 	// Shutdown HTTP server with timeout
 	shutdownCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
@@ -448,6 +874,16 @@ func (a *App) Shutdown(ctx context.Context) error {
 		return fmt.Errorf("shutting down HTTP server: %w", err)
 	}
 
+	// No syncs run anymore; let queued comment classification finish within the window
+	if a.commentService != nil {
+		a.commentService.Close(shutdownCtx)
+	}
+
+	// Give pending webhook deliveries the rest of the shutdown window
+	if a.webhook != nil {
+		a.webhook.Wait(shutdownCtx)
+	}
+
 	// Close database connections
 	if a.pg != nil {
 		a.pg.Close()
@@ -457,6 +893,30 @@ func (a *App) Shutdown(ctx context.Context) error {
 	return nil
 }
 
+// publicationWebhookAdapter adapts webhook.Sender to service.StatusNotifier
+type publicationWebhookAdapter struct {
+	sender *webhook.Sender
+}
+
+// publicationWebhookData is the data of publication.published and publication.error events
+type publicationWebhookData struct {
+	PublicationID    string `json:"publication_id"`
+	AccountID        string `json:"account_id"`
+	Status           string `json:"status"`
+	InstagramMediaID string `json:"instagram_media_id,omitempty"`
+	ErrorMessage     string `json:"error_message,omitempty"`
+}
+
+func (a *publicationWebhookAdapter) PublicationStatusChanged(_ context.Context, pub *pubEntity.Publication) {
+	a.sender.Send("publication."+string(pub.Status), publicationWebhookData{
+		PublicationID:    pub.ID,
+		AccountID:        pub.AccountID,
+		Status:           string(pub.Status),
+		InstagramMediaID: pub.InstagramMediaID,
+		ErrorMessage:     pub.ErrorMessage,
+	})
+}
+
 // instagramPublisherAdapter adapts instagram.Publisher to policy.InstagramPublisher
 type instagramPublisherAdapter struct {
 	publisher *instagram.Publisher
@@ -464,16 +924,51 @@ type instagramPublisherAdapter struct {
 
 func (a *instagramPublisherAdapter) Publish(ctx context.Context, in policy.PublishInput) (*policy.PublishOutput, error) {
 	out, err := a.publisher.Publish(ctx, instagram.PublishInput{
+		UserID:             in.UserID,
+		AccessToken:        in.AccessToken,
+		Publication:        in.Publication,
+		OnContainerCreated: in.OnContainerCreated,
+	})
+	if err != nil {
+		return nil, mapPublishAPIError(err)
+	}
+	return &policy.PublishOutput{
+		InstagramMediaID: out.InstagramMediaID,
+		Permalink:        out.Permalink,
+		MediaProductType: out.MediaProductType,
+		CommentsEnabled:  out.CommentsEnabled,
+	}, nil
+}
+
+func (a *instagramPublisherAdapter) PrepareContainer(ctx context.Context, in policy.PublishInput) (string, error) {
+	containerID, err := a.publisher.PrepareContainer(ctx, instagram.PublishInput{
 		UserID:      in.UserID,
 		AccessToken: in.AccessToken,
 		Publication: in.Publication,
 	})
 	if err != nil {
-		return nil, err
+		return "", mapPublishAPIError(err)
+	}
+	return containerID, nil
+}
+
+func (a *instagramPublisherAdapter) FindPublishedMedia(ctx context.Context, in policy.PublishInput) (*policy.PublishOutput, error) {
+	out, err := a.publisher.FindPublishedMedia(ctx, instagram.PublishInput{
+		UserID:      in.UserID,
+		AccessToken: in.AccessToken,
+		Publication: in.Publication,
+	})
+	if err != nil {
+		return nil, mapPublishAPIError(err)
+	}
+	if out == nil {
+		return nil, nil
 	}
 	return &policy.PublishOutput{
 		InstagramMediaID: out.InstagramMediaID,
 		Permalink:        out.Permalink,
+		MediaProductType: out.MediaProductType,
+		CommentsEnabled:  out.CommentsEnabled,
 	}, nil
 }
 
@@ -481,17 +976,68 @@ func (a *instagramPublisherAdapter) Delete(ctx context.Context, mediaID, accessT
 	return a.publisher.Delete(ctx, mediaID, accessToken)
 }
 
-// accountProviderAdapter adapts AccountPostgres to policy.AccountProvider
+func (a *instagramPublisherAdapter) GetPermalink(ctx context.Context, mediaID, accessToken string) (string, error) {
+	permalink, err := a.publisher.GetPermalink(ctx, mediaID, accessToken)
+	if err != nil {
+		return "", mapPublishAPIError(err)
+	}
+	return permalink, nil
+}
+
+func (a *instagramPublisherAdapter) SetCommentEnabled(ctx context.Context, mediaID, accessToken string, enabled bool) error {
+	err := a.publisher.SetCommentEnabled(ctx, mediaID, accessToken, enabled)
+	if err == nil {
+		return nil
+	}
+
+	var apiErr *instagram.APIError
+	if errors.As(err, &apiErr) && apiErr.IsTokenInvalid() {
+		return fmt.Errorf("%w: %v", pubEntity.ErrInstagramUnauthorized, err)
+	}
+	if mapped := mapPublishAPIError(err); mapped != err {
+		return mapped
+	}
+	return fmt.Errorf("%w: %v", pubEntity.ErrInstagramAPIFailure, err)
+}
+
+// mapPublishAPIError translates temporary Instagram failures to publication domain errors,
+// so the scheduler can tell them apart from permanent ones
+func mapPublishAPIError(err error) error {
+	var apiErr *instagram.APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.IsRateLimited():
+			return fmt.Errorf("%w: %v", pubEntity.ErrInstagramRateLimited, err)
+		case apiErr.IsTemporary():
+			return fmt.Errorf("%w: %v", pubEntity.ErrInstagramUnavailable, err)
+		}
+		return err
+	}
+
+	var httpErr *instagram.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode >= 500 {
+		return fmt.Errorf("%w: %v", pubEntity.ErrInstagramUnavailable, err)
+	}
+	return err
+}
+
+// accountProviderAdapter adapts AccountPostgres to policy.AccountProvider;
+// access tokens and user IDs are read through the credentials cache
 type accountProviderAdapter struct {
-	repo *dao.AccountPostgres
+	repo  *dao.AccountPostgres
+	creds *dao.CredentialsCache
 }
 
 func (a *accountProviderAdapter) GetAccessToken(ctx context.Context, accountID string) (string, error) {
-	return a.repo.GetAccessToken(ctx, accountID)
+	return a.creds.GetAccessToken(ctx, accountID)
 }
 
 func (a *accountProviderAdapter) GetInstagramUserID(ctx context.Context, accountID string) (string, error) {
-	return a.repo.GetInstagramUserID(ctx, accountID)
+	return a.creds.GetInstagramUserID(ctx, accountID)
+}
+
+func (a *accountProviderAdapter) GetAccountCredentials(ctx context.Context, accountID string) (string, string, error) {
+	return a.creds.GetAccountCredentials(ctx, accountID)
 }
 
 func (a *accountProviderAdapter) GetUsername(ctx context.Context, accountID string) (string, error) {
@@ -503,22 +1049,123 @@ type accountListerAdapter struct {
 	repo *dao.AccountPostgres
 }
 
-func (a *accountListerAdapter) ListAccounts(ctx context.Context) ([]httpcontroller.AccountInfo, error) {
-	accounts, err := a.repo.ListAccounts(ctx)
+func (a *accountListerAdapter) ListAccounts(ctx context.Context, f httpcontroller.AccountListFilter) ([]httpcontroller.AccountInfo, int64, error) {
+	filter := dao.AccountFilter{Query: f.Query, IDs: f.IDs, Limit: f.Limit, Offset: f.Offset}
+	accounts, err := a.repo.ListAccounts(ctx, filter)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+	total := int64(len(accounts))
+	if f.Limit > 0 || f.Offset > 0 {
+		if total, err = a.repo.CountAccounts(ctx, filter); err != nil {
+			return nil, 0, err
+		}
 	}
 
 	result := make([]httpcontroller.AccountInfo, len(accounts))
 	for i, acc := range accounts {
 		result[i] = httpcontroller.AccountInfo{
-			ID:              acc.ID,
-			InstagramUserID: acc.InstagramUserID,
-			Username:        acc.Username,
-			HasAccessToken:  acc.AccessToken != "",
+			ID:                 acc.ID,
+			InstagramUserID:    acc.InstagramUserID,
+			Username:           acc.Username,
+			HasAccessToken:     acc.AccessToken != "",
+			Name:               acc.Name,
+			ProfilePictureURL:  acc.ProfilePictureURL,
+			FollowersCount:     acc.FollowersCount,
+			MediaCount:         acc.MediaCount,
+			ProfileRefreshedAt: acc.ProfileRefreshedAt,
+			TokenStatus:        acc.TokenStatus,
+			TokenCheckedAt:     acc.TokenCheckedAt,
 		}
 	}
-	return result, nil
+	return result, total, nil
+}
+
+// accountProfileRefresherAdapter fetches account profiles from Instagram and stores them via AccountPostgres
+type accountProfileRefresherAdapter struct {
+	repo   *dao.AccountPostgres
+	client *instagram.Client
+}
+
+func (a *accountProfileRefresherAdapter) RefreshProfile(ctx context.Context, accountID string) error {
+	token, err := a.repo.GetAccessToken(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	userID, err := a.repo.GetInstagramUserID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	profile, err := a.client.GetAccountProfile(ctx, instagram.GetAccountProfileInput{
+		UserID:      userID,
+		AccessToken: token,
+	})
+	if err != nil {
+		return fmt.Errorf("fetching account profile: %w", err)
+	}
+
+	return a.repo.UpdateProfile(ctx, accountID, dao.AccountProfile{
+		Username:          profile.Username,
+		Name:              profile.Name,
+		ProfilePictureURL: profile.ProfilePictureURL,
+		FollowersCount:    profile.FollowersCount,
+		MediaCount:        profile.MediaCount,
+	})
+}
+
+// CheckToken makes a lightweight profile request to find out whether the account's token still works
+func (a *accountProfileRefresherAdapter) CheckToken(ctx context.Context, acc dao.AccountInfo) string {
+	if acc.AccessToken == "" {
+		return dao.TokenStatusMissing
+	}
+
+	_, err := a.client.GetAccountProfile(ctx, instagram.GetAccountProfileInput{
+		UserID:      acc.InstagramUserID,
+		AccessToken: acc.AccessToken,
+	})
+	if err == nil {
+		return dao.TokenStatusValid
+	}
+
+	var apiErr *instagram.APIError
+	if errors.As(err, &apiErr) && apiErr.IsTokenInvalid() {
+		return dao.TokenStatusUnauthorized
+	}
+	return dao.TokenStatusError
+}
+
+// Probe makes the cheapest read that needs the capability; writes are never tried
+func (a *accountProfileRefresherAdapter) Probe(ctx context.Context, accountID, capability string) error {
+	token, err := a.repo.GetAccessToken(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	userID, err := a.repo.GetInstagramUserID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	var in instagram.GraphGetInput
+	switch capability {
+	case httpcontroller.CapabilityProfile:
+		_, err := a.client.GetAccountProfile(ctx, instagram.GetAccountProfileInput{UserID: userID, AccessToken: token})
+		return err
+	case httpcontroller.CapabilityContentPublishing:
+		// Needs instagram_content_publish
+		in = instagram.GraphGetInput{Path: userID + "/content_publishing_limit", Fields: []string{"quota_usage"}}
+	case httpcontroller.CapabilityComments:
+		// Needs instagram_manage_comments; succeeds trivially for an account without media
+		in = instagram.GraphGetInput{Path: userID + "/media", Fields: []string{"id", "comments.limit(1){id}"}, Params: map[string]string{"limit": "1"}}
+	case httpcontroller.CapabilityMessaging:
+		// Needs instagram_manage_messages
+		in = instagram.GraphGetInput{Path: userID + "/conversations", Fields: []string{"id"}, Params: map[string]string{"platform": "instagram", "limit": "1"}}
+	default:
+		return fmt.Errorf("unknown capability %q", capability)
+	}
+	in.AccessToken = token
+
+	return a.client.Get(ctx, in, &struct{}{})
 }
 
 // mediaUploaderAdapter adapts S3Storage to httpcontroller.MediaUploader
@@ -533,7 +1180,12 @@ func (a *mediaUploaderAdapter) Upload(ctx context.Context, in httpcontroller.Med
 		Size:        in.Size,
 		Filename:    in.Filename,
 	})
-	if err != nil {
+	switch {
+	case errors.Is(err, storage.ErrUploadRejected):
+		return nil, fmt.Errorf("%w: %v", httpcontroller.ErrUploadRejected, err)
+	case errors.Is(err, storage.ErrUploadUnavailable):
+		return nil, fmt.Errorf("%w: %v", httpcontroller.ErrStorageUnavailable, err)
+	case err != nil:
 		return nil, err
 	}
 	return &httpcontroller.MediaUploadOutput{
@@ -548,15 +1200,22 @@ type instagramCommentAdapter struct {
 	client *instagram.Client
 }
 
-func (a *instagramCommentAdapter) GetComments(ctx context.Context, mediaID, accessToken string, limit int, after string) (*commentService.CommentsResult, error) {
-	out, err := a.client.GetComments(ctx, instagram.GetCommentsInput{
+func (a *instagramCommentAdapter) GetComments(ctx context.Context, mediaID, accessToken string, limit int, after string, since, until *time.Time) (*commentService.CommentsResult, error) {
+	in := instagram.GetCommentsInput{
 		MediaID:     mediaID,
 		AccessToken: accessToken,
 		Limit:       limit,
 		After:       after,
-	})
+	}
+	if since != nil {
+		in.Since = *since
+	}
+	if until != nil {
+		in.Until = *until
+	}
+	out, err := a.client.GetComments(ctx, in)
 	if err != nil {
-		return nil, err
+		return nil, mapCommentAPIError(err)
 	}
 
 	comments := make([]commentEntity.Comment, len(out.Data))
@@ -579,12 +1238,13 @@ func (a *instagramCommentAdapter) GetComments(ctx context.Context, mediaID, acce
 		comments[i] = commentEntity.Comment{
 			ID:           c.ID,
 			MediaID:      mediaID,
+			AuthorID:     c.AuthorID(),
 			Username:     c.Username,
 			Text:         c.Text,
 			Timestamp:    timestamp,
 			LikeCount:    c.LikeCount,
 			IsHidden:     c.Hidden,
-			RepliesCount: c.RepliesCount,
+			RepliesCount: c.ReplyCount(),
 		}
 	}
 
@@ -632,6 +1292,7 @@ func (a *instagramCommentAdapter) GetCommentReplies(ctx context.Context, comment
 		comments[i] = commentEntity.Comment{
 			ID:        c.ID,
 			ParentID:  commentID,
+			AuthorID:  c.AuthorID(),
 			Username:  c.Username,
 			Text:      c.Text,
 			Timestamp: timestamp,
@@ -654,6 +1315,26 @@ func (a *instagramCommentAdapter) GetCommentReplies(ctx context.Context, comment
 	}, nil
 }
 
+// MediaOwnerID returns the Instagram user that owns the media, or an empty string if the token may not see it
+func (a *instagramCommentAdapter) MediaOwnerID(ctx context.Context, mediaID, accessToken string) (string, error) {
+	media, err := a.client.GetMedia(ctx, instagram.GetMediaInput{
+		MediaID:     mediaID,
+		AccessToken: accessToken,
+		Fields:      []string{"id", "owner"},
+	})
+	var apiErr *instagram.APIError
+	if errors.As(err, &apiErr) && apiErr.IsObjectUnavailable() {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if media.Owner == nil {
+		return "", nil
+	}
+	return media.Owner.ID, nil
+}
+
 func (a *instagramCommentAdapter) CreateComment(ctx context.Context, mediaID, accessToken, message string) (string, error) {
 	out, err := a.client.CreateComment(ctx, instagram.CreateCommentInput{
 		MediaID:     mediaID,
@@ -679,18 +1360,118 @@ func (a *instagramCommentAdapter) ReplyToComment(ctx context.Context, commentID,
 }
 
 func (a *instagramCommentAdapter) DeleteComment(ctx context.Context, commentID, accessToken string) error {
-	return a.client.DeleteComment(ctx, instagram.DeleteCommentInput{
+	err := a.client.DeleteComment(ctx, instagram.DeleteCommentInput{
 		CommentID:   commentID,
 		AccessToken: accessToken,
 	})
+	return mapCommentAPIError(err)
 }
 
 func (a *instagramCommentAdapter) HideComment(ctx context.Context, commentID, accessToken string, hide bool) error {
-	return a.client.HideComment(ctx, instagram.HideCommentInput{
+	err := a.client.HideComment(ctx, instagram.HideCommentInput{
 		CommentID:   commentID,
 		AccessToken: accessToken,
 		Hide:        hide,
 	})
+	return mapCommentAPIError(err)
+}
+
+// mapCommentAPIError translates Instagram throttling and deleted-media errors to the comment domain errors
+func mapCommentAPIError(err error) error {
+	var apiErr *instagram.APIError
+	switch {
+	case errors.As(err, &apiErr) && apiErr.IsRateLimited():
+		return fmt.Errorf("%w: %v", commentEntity.ErrRateLimited, err)
+	case errors.As(err, &apiErr) && apiErr.IsObjectDeleted():
+		return fmt.Errorf("%w: %v", commentEntity.ErrMediaNotFound, err)
+	}
+	return err
+}
+
+// accountSyncStatusAdapter aggregates the comment and direct message sync tables for httpcontroller.AccountSyncStatusProvider
+// and resets failed syncs through the comment and direct policies
+type accountSyncStatusAdapter struct {
+	comments      *commentDao.SyncStatusPostgres
+	conversations *directDao.ConversationSyncPostgres
+	accounts      *directDao.AccountSyncPostgres
+
+	commentPolicy *commentPolicy.Policy
+	directPolicy  *directPolicy.Policy
+}
+
+func (a *accountSyncStatusAdapter) ResetFailedSyncs(ctx context.Context, accountID string, mediaIDs, conversationIDs []string) (*httpcontroller.SyncResetResult, error) {
+	result := &httpcontroller.SyncResetResult{AccountID: accountID}
+
+	// IDs of one kind limit the reset to those items and leave the other kind alone
+	scoped := len(mediaIDs) > 0 || len(conversationIDs) > 0
+	if !scoped || len(mediaIDs) > 0 {
+		reset, err := a.commentPolicy.ResetFailedSyncs(ctx, commentPolicy.ResetFailedSyncsInput{
+			AccountID: accountID,
+			MediaIDs:  mediaIDs,
+		})
+		if err != nil {
+			return nil, err
+		}
+		result.MediaIDs = reset
+	}
+	if !scoped || len(conversationIDs) > 0 {
+		reset, err := a.directPolicy.ResetFailedSyncs(ctx, directPolicy.ResetFailedSyncsInput{
+			AccountID:       accountID,
+			ConversationIDs: conversationIDs,
+		})
+		if err != nil {
+			return nil, err
+		}
+		result.ConversationIDs = reset.ConversationIDs
+		result.ConversationList = reset.ConversationList
+	}
+
+	return result, nil
+}
+
+func (a *accountSyncStatusAdapter) GetAccountSyncStatus(ctx context.Context, accountID string) (*httpcontroller.AccountSyncStatus, error) {
+	comments, err := a.comments.GetAccountSummary(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	conversations, err := a.conversations.GetAccountSummary(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	list, err := a.accounts.GetSyncStatus(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &httpcontroller.AccountSyncStatus{
+		AccountID: accountID,
+		Comments: httpcontroller.SyncSummary{
+			Total:          comments.Total,
+			NeverSynced:    comments.NeverSynced,
+			Retrying:       comments.Retrying,
+			Failed:         comments.Failed,
+			LastSyncedAt:   comments.LastSyncedAt,
+			OldestSyncedAt: comments.OldestSyncedAt,
+		},
+		Conversations: httpcontroller.SyncSummary{
+			Total:          conversations.Total,
+			NeverSynced:    conversations.NeverSynced,
+			Retrying:       conversations.Retrying,
+			Failed:         conversations.Failed,
+			LastSyncedAt:   conversations.LastSyncedAt,
+			OldestSyncedAt: conversations.OldestSyncedAt,
+		},
+	}
+	if list != nil {
+		status.ConversationList = &httpcontroller.ConversationListSync{
+			LastSyncedAt: list.LastSyncedAt,
+			SyncComplete: list.SyncComplete,
+			RetryCount:   list.RetryCount,
+			Failed:       list.Failed,
+			LastError:    list.LastError,
+		}
+	}
+	return status, nil
 }
 
 // commentRepoAdapter adapts commentDao.CommentPostgres to commentService.CommentRepository
@@ -710,8 +1491,12 @@ func (a *commentRepoAdapter) GetByID(ctx context.Context, id string) (*commentEn
 	return a.repo.GetByID(ctx, id)
 }
 
-func (a *commentRepoAdapter) GetByMediaID(ctx context.Context, mediaID string, limit int, offset int) ([]commentEntity.Comment, error) {
-	return a.repo.GetByMediaID(ctx, mediaID, limit, offset)
+func (a *commentRepoAdapter) GetRepliesForParents(ctx context.Context, parentIDs []string, maxPerParent int) ([]commentEntity.Comment, error) {
+	return a.repo.GetRepliesForParents(ctx, parentIDs, maxPerParent)
+}
+
+func (a *commentRepoAdapter) GetByMediaID(ctx context.Context, mediaID string, filter commentEntity.CommentFilter, limit int, offset int) ([]commentEntity.Comment, error) {
+	return a.repo.GetByMediaID(ctx, mediaID, filter, limit, offset)
 }
 
 func (a *commentRepoAdapter) GetReplies(ctx context.Context, parentID string, limit int, offset int) ([]commentEntity.Comment, error) {
@@ -722,6 +1507,10 @@ func (a *commentRepoAdapter) Delete(ctx context.Context, id string) error {
 	return a.repo.Delete(ctx, id)
 }
 
+func (a *commentRepoAdapter) DeleteMissing(ctx context.Context, mediaID string, keepIDs []string, before time.Time) (int64, error) {
+	return a.repo.DeleteMissing(ctx, mediaID, keepIDs, before)
+}
+
 func (a *commentRepoAdapter) UpdateHidden(ctx context.Context, id string, hidden bool) error {
 	return a.repo.UpdateHidden(ctx, id, hidden)
 }
@@ -734,8 +1523,24 @@ func (a *commentRepoAdapter) CountReplies(ctx context.Context, parentID string)
 	return a.repo.CountReplies(ctx, parentID)
 }
 
-func (a *commentRepoAdapter) GetStatistics(ctx context.Context, accountID string, topPostsLimit int) (*commentEntity.CommentStatistics, error) {
-	return a.repo.GetStatistics(ctx, accountID, topPostsLimit)
+func (a *commentRepoAdapter) GetUnclassified(ctx context.Context, mediaID string, limit int) ([]commentEntity.Comment, error) {
+	return a.repo.GetUnclassified(ctx, mediaID, limit)
+}
+
+func (a *commentRepoAdapter) UpdateSentiments(ctx context.Context, sentiments map[string]commentEntity.Sentiment) error {
+	return a.repo.UpdateSentiments(ctx, sentiments)
+}
+
+func (a *commentRepoAdapter) GetStatistics(ctx context.Context, filter commentEntity.StatisticsFilter) (*commentEntity.CommentStatistics, error) {
+	return a.repo.GetStatistics(ctx, filter)
+}
+
+func (a *commentRepoAdapter) GetTopPosts(ctx context.Context, filter commentEntity.StatisticsFilter, q commentEntity.TopPostsQuery) ([]commentEntity.TopPost, error) {
+	return a.repo.GetTopPosts(ctx, filter, q)
+}
+
+func (a *commentRepoAdapter) GetRecentByAccount(ctx context.Context, q commentEntity.RecentCommentsQuery) ([]commentEntity.RecentComment, error) {
+	return a.repo.GetRecentByAccount(ctx, q)
 }
 
 // commentSyncRepoAdapter adapts commentDao.SyncStatusPostgres to commentService.SyncStatusRepository
@@ -756,6 +1561,10 @@ func (a *commentSyncRepoAdapter) GetSyncStatus(ctx context.Context, mediaID stri
 		LastSyncedAt:     status.LastSyncedAt,
 		NextCursor:       status.NextCursor,
 		SyncComplete:     status.SyncComplete,
+		RetryCount:       status.RetryCount,
+		Failed:           status.Failed,
+		LastError:        status.LastError,
+		LastFullSyncAt:   status.LastFullSyncAt,
 	}, nil
 }
 
@@ -765,11 +1574,12 @@ func (a *commentSyncRepoAdapter) UpdateSyncStatus(ctx context.Context, status *c
 		LastSyncedAt:     status.LastSyncedAt,
 		NextCursor:       status.NextCursor,
 		SyncComplete:     status.SyncComplete,
+		LastFullSyncAt:   status.LastFullSyncAt,
 	})
 }
 
-func (a *commentSyncRepoAdapter) GetMediaIDsNeedingSync(ctx context.Context, olderThan time.Duration, limit int) ([]string, error) {
-	return a.repo.GetMediaIDsNeedingSync(ctx, olderThan, limit)
+func (a *commentSyncRepoAdapter) GetMediaIDsNeedingSync(ctx context.Context, olderThan, maxPostAge time.Duration, limit int) ([]string, error) {
+	return a.repo.GetMediaIDsNeedingSync(ctx, olderThan, maxPostAge, limit)
 }
 
 func (a *commentSyncRepoAdapter) IncrementRetryCount(ctx context.Context, mediaID string, lastError string, maxRetries int) error {
@@ -780,6 +1590,10 @@ func (a *commentSyncRepoAdapter) ResetRetryCount(ctx context.Context, mediaID st
 	return a.repo.ResetRetryCount(ctx, mediaID)
 }
 
+func (a *commentSyncRepoAdapter) GetFailedMediaIDs(ctx context.Context, accountID string) ([]string, error) {
+	return a.repo.GetFailedMediaIDs(ctx, accountID)
+}
+
 // publicationRepoAdapter adapts dao.PublicationRepository for comment sync scheduler
 type publicationRepoAdapter struct {
 	repo dao.PublicationRepository
@@ -789,6 +1603,15 @@ func (a *publicationRepoAdapter) GetAccountIDByMediaID(ctx context.Context, medi
 	return a.repo.GetAccountIDByMediaID(ctx, mediaID)
 }
 
+// MediaAccountID adapts the lookup for the comment policy, which treats media without a publication as unknown
+func (a *publicationRepoAdapter) MediaAccountID(ctx context.Context, mediaID string) (string, error) {
+	accountID, err := a.repo.GetAccountIDByMediaID(ctx, mediaID)
+	if errors.Is(err, pubEntity.ErrPublicationNotFound) {
+		return "", nil
+	}
+	return accountID, err
+}
+
 // instagramDirectAdapter adapts instagram.Client to directService.InstagramClient
 type instagramDirectAdapter struct {
 	client *instagram.Client
@@ -978,13 +1801,14 @@ func (a *instagramDirectAdapter) SendMessage(ctx context.Context, userID, recipi
 	return &directService.SendMessageResult{MessageID: out.MessageID}, nil
 }
 
-func (a *instagramDirectAdapter) SendMediaMessage(ctx context.Context, userID, recipientID, accessToken, mediaURL, mediaType string) (*directService.SendMessageResult, error) {
+func (a *instagramDirectAdapter) SendMediaMessage(ctx context.Context, userID, recipientID, accessToken, mediaURL, mediaType, caption string) (*directService.SendMessageResult, error) {
 	out, err := a.client.SendDMMediaMessage(ctx, instagram.SendDMMediaMessageInput{
 		UserID:      userID,
 		RecipientID: recipientID,
 		AccessToken: accessToken,
 		MediaURL:    mediaURL,
 		MediaType:   mediaType,
+		Text:        caption,
 	})
 	if err != nil {
 		return nil, err
@@ -992,18 +1816,35 @@ func (a *instagramDirectAdapter) SendMediaMessage(ctx context.Context, userID, r
 	return &directService.SendMessageResult{MessageID: out.MessageID}, nil
 }
 
+func (a *instagramDirectAdapter) SendReaction(ctx context.Context, userID, recipientID, accessToken, messageID, reaction string) error {
+	return a.client.SendReaction(ctx, instagram.SendReactionInput{
+		UserID:      userID,
+		RecipientID: recipientID,
+		MessageID:   messageID,
+		Reaction:    reaction,
+		AccessToken: accessToken,
+	})
+}
+
 func (a *instagramDirectAdapter) GetParticipant(ctx context.Context, userID, accessToken string) (*directService.ParticipantResult, error) {
 	out, err := a.client.GetDMParticipant(ctx, instagram.GetDMParticipantInput{
 		UserID:      userID,
 		AccessToken: accessToken,
 	})
-	if err != nil {
+	var apiErr *instagram.APIError
+	switch {
+	case errors.As(err, &apiErr) && apiErr.IsRateLimited():
+		return nil, fmt.Errorf("%w: %v", directEntity.ErrRateLimited, err)
+	case errors.As(err, &apiErr) && apiErr.IsObjectUnavailable():
+		return nil, fmt.Errorf("%w: %v", directEntity.ErrProfileUnavailable, err)
+	case err != nil:
 		return nil, err
 	}
 	return &directService.ParticipantResult{
 		ID:             out.ID,
 		Username:       out.Username,
 		Name:           out.Name,
+		AvatarURL:      out.ProfilePicURL,
 		FollowersCount: out.FollowersCount,
 	}, nil
 }
@@ -1025,8 +1866,16 @@ func (a *directConvRepoAdapter) GetByID(ctx context.Context, id string) (*direct
 	return a.repo.GetByID(ctx, id)
 }
 
-func (a *directConvRepoAdapter) GetByAccountID(ctx context.Context, accountID string, limit, offset int) ([]directEntity.Conversation, error) {
-	return a.repo.GetByAccountID(ctx, accountID, limit, offset)
+func (a *directConvRepoAdapter) GetByAccountID(ctx context.Context, accountID string, labels []string, limit, offset int) ([]directEntity.Conversation, error) {
+	return a.repo.GetByAccountID(ctx, accountID, labels, limit, offset)
+}
+
+func (a *directConvRepoAdapter) GetByParticipant(ctx context.Context, accountID, participantID string) ([]directEntity.Conversation, error) {
+	return a.repo.GetByParticipant(ctx, accountID, participantID)
+}
+
+func (a *directConvRepoAdapter) UpdateParticipant(ctx context.Context, accountID string, p *directEntity.Participant) error {
+	return a.repo.UpdateParticipant(ctx, accountID, p)
 }
 
 func (a *directConvRepoAdapter) Search(ctx context.Context, accountID, query string, limit, offset int) ([]directEntity.Conversation, error) {
@@ -1037,8 +1886,24 @@ func (a *directConvRepoAdapter) Delete(ctx context.Context, id string) error {
 	return a.repo.Delete(ctx, id)
 }
 
-func (a *directConvRepoAdapter) Count(ctx context.Context, accountID string) (int64, error) {
-	return a.repo.Count(ctx, accountID)
+func (a *directConvRepoAdapter) MarkRead(ctx context.Context, id string, readAt time.Time) error {
+	return a.repo.MarkRead(ctx, id, readAt)
+}
+
+func (a *directConvRepoAdapter) Count(ctx context.Context, accountID string, labels []string) (int64, error) {
+	return a.repo.Count(ctx, accountID, labels)
+}
+
+func (a *directConvRepoAdapter) AddLabels(ctx context.Context, accountID, conversationID string, labels []string) error {
+	return a.repo.AddLabels(ctx, accountID, conversationID, labels)
+}
+
+func (a *directConvRepoAdapter) RemoveLabels(ctx context.Context, conversationID string, labels []string) error {
+	return a.repo.RemoveLabels(ctx, conversationID, labels)
+}
+
+func (a *directConvRepoAdapter) GetLabels(ctx context.Context, conversationID string) ([]string, error) {
+	return a.repo.GetLabels(ctx, conversationID)
 }
 
 // directMsgRepoAdapter adapts directDao.MessagePostgres to directService.MessageRepository
@@ -1062,6 +1927,10 @@ func (a *directMsgRepoAdapter) GetByConversationID(ctx context.Context, conversa
 	return a.repo.GetByConversationID(ctx, conversationID, limit, offset)
 }
 
+func (a *directMsgRepoAdapter) UpdateReaction(ctx context.Context, id, reaction string) error {
+	return a.repo.UpdateReaction(ctx, id, reaction)
+}
+
 func (a *directMsgRepoAdapter) Delete(ctx context.Context, id string) error {
 	return a.repo.Delete(ctx, id)
 }
@@ -1097,6 +1966,9 @@ func (a *directConvSyncRepoAdapter) GetSyncStatus(ctx context.Context, conversat
 		NextCursor:             status.NextCursor,
 		SyncComplete:           status.SyncComplete,
 		OldestMessageTimestamp: status.OldestMessageTimestamp,
+		RetryCount:             status.RetryCount,
+		Failed:                 status.Failed,
+		LastError:              status.LastError,
 	}, nil
 }
 
@@ -1122,6 +1994,10 @@ func (a *directConvSyncRepoAdapter) ResetRetryCount(ctx context.Context, convers
 	return a.repo.ResetRetryCount(ctx, conversationID)
 }
 
+func (a *directConvSyncRepoAdapter) GetFailedConversationIDs(ctx context.Context, accountID string) ([]string, error) {
+	return a.repo.GetFailedConversationIDs(ctx, accountID)
+}
+
 // directAccountSyncRepoAdapter adapts directDao.AccountSyncPostgres to directService.AccountSyncRepository
 type directAccountSyncRepoAdapter struct {
 	repo *directDao.AccountSyncPostgres
@@ -1136,19 +2012,24 @@ func (a *directAccountSyncRepoAdapter) GetSyncStatus(ctx context.Context, accoun
 		return nil, nil
 	}
 	return &directService.AccountSyncStatus{
-		AccountID:    status.AccountID,
-		LastSyncedAt: status.LastSyncedAt,
-		NextCursor:   status.NextCursor,
-		SyncComplete: status.SyncComplete,
+		AccountID:      status.AccountID,
+		LastSyncedAt:   status.LastSyncedAt,
+		NextCursor:     status.NextCursor,
+		SyncComplete:   status.SyncComplete,
+		RetryCount:     status.RetryCount,
+		Failed:         status.Failed,
+		LastError:      status.LastError,
+		LastFullSyncAt: status.LastFullSyncAt,
 	}, nil
 }
 
 func (a *directAccountSyncRepoAdapter) UpdateSyncStatus(ctx context.Context, status *directService.AccountSyncStatus) error {
 	return a.repo.UpdateSyncStatus(ctx, &directDao.AccountSyncStatus{
-		AccountID:    status.AccountID,
-		LastSyncedAt: status.LastSyncedAt,
-		NextCursor:   status.NextCursor,
-		SyncComplete: status.SyncComplete,
+		AccountID:      status.AccountID,
+		LastSyncedAt:   status.LastSyncedAt,
+		NextCursor:     status.NextCursor,
+		SyncComplete:   status.SyncComplete,
+		LastFullSyncAt: status.LastFullSyncAt,
 	})
 }
 
@@ -1189,6 +2070,8 @@ func (a *templateRepoAdapter) List(ctx context.Context, filter templateService.L
 	return a.repo.List(ctx, templateDao.ListFilter{
 		AccountID: filter.AccountID,
 		Type:      filter.Type,
+		Types:     filter.Types,
+		Query:     filter.Query,
 	}, templateDao.ListOptions{
 		Limit:  opts.Limit,
 		Offset: opts.Offset,
@@ -1201,6 +2084,8 @@ func (a *templateRepoAdapter) Count(ctx context.Context, filter templateService.
 	return a.repo.Count(ctx, templateDao.ListFilter{
 		AccountID: filter.AccountID,
 		Type:      filter.Type,
+		Types:     filter.Types,
+		Query:     filter.Query,
 	})
 }
 
@@ -1208,6 +2093,15 @@ func (a *templateRepoAdapter) IncrementUsageCount(ctx context.Context, id string
 	return a.repo.IncrementUsageCount(ctx, id)
 }
 
+func (a *templateRepoAdapter) GetUsageAnalytics(ctx context.Context, filter templateService.AnalyticsFilter) ([]templateEntity.TemplateUsage, error) {
+	return a.repo.GetUsageAnalytics(ctx, templateDao.AnalyticsFilter{
+		AccountID: filter.AccountID,
+		Type:      filter.Type,
+		From:      filter.From,
+		To:        filter.To,
+	})
+}
+
 // directSenderAdapter adapts directService to commentPolicy.DirectSender
 type directSenderAdapter struct {
 	directSvc *directService.Service
@@ -1216,14 +2110,9 @@ type directSenderAdapter struct {
 
 func (a *directSenderAdapter) SendMessage(ctx context.Context, accountID, recipientID, message string) error {
 	// Get access token and Instagram user ID for this account
-	accessToken, err := a.accounts.GetAccessToken(ctx, accountID)
-	if err != nil {
-		return fmt.Errorf("getting access token: %w", err)
-	}
-
-	userID, err := a.accounts.GetInstagramUserID(ctx, accountID)
+	accessToken, userID, err := a.accounts.GetAccountCredentials(ctx, accountID)
 	if err != nil {
-		return fmt.Errorf("getting Instagram user ID: %w", err)
+		return fmt.Errorf("getting account credentials: %w", err)
 	}
 
 	// Send the DM
@@ -1235,3 +2124,190 @@ func (a *directSenderAdapter) SendMessage(ctx context.Context, accountID, recipi
 	})
 	return err
 }
+
+// directTemplateAdapter adapts templatePolicy.Policy to directPolicy.TemplateRenderer
+type directTemplateAdapter struct {
+	policy *templatePolicy.Policy
+}
+
+// quickReplyLimit caps how many templates are offered as quick replies
+const quickReplyLimit = 100
+
+func (a *directTemplateAdapter) ListQuickReplies(ctx context.Context, accountID string) ([]directPolicy.QuickReply, error) {
+	out, err := a.policy.List(ctx, templatePolicy.ListInput{
+		AccountID: accountID,
+		Types:     directTemplateTypes,
+		Limit:     quickReplyLimit,
+		SortBy:    "usage_count",
+		Desc:      true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	replies := make([]directPolicy.QuickReply, 0, len(out.Templates))
+	for _, t := range out.Templates {
+		replies = append(replies, directPolicy.QuickReply{
+			TemplateID: t.ID,
+			Title:      t.Title,
+			Content:    t.Content,
+			Icon:       t.Icon,
+			Images:     t.Images,
+		})
+	}
+	return replies, nil
+}
+
+func (a *directTemplateAdapter) Render(ctx context.Context, accountID, templateID string) (string, error) {
+	tmpl, err := a.policy.GetByID(ctx, templateID, accountID)
+	if errors.Is(err, templateEntity.ErrTemplateNotFound) {
+		return "", directEntity.ErrTemplateNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if !isDirectTemplate(tmpl.Type) {
+		return "", directEntity.ErrTemplateNotDirect
+	}
+	return tmpl.Content, nil
+}
+
+func (a *directTemplateAdapter) MarkUsed(ctx context.Context, accountID, templateID string) error {
+	return a.policy.IncrementUsage(ctx, templateID, accountID)
+}
+
+// directTemplateTypes are the template types usable in direct messages
+var directTemplateTypes = []templateEntity.TemplateType{templateEntity.TemplateTypeDirect, templateEntity.TemplateTypeBoth}
+
+func isDirectTemplate(t templateEntity.TemplateType) bool {
+	return slices.Contains(directTemplateTypes, t)
+}
+
+// hashtagSetAdapter adapts templatePolicy.Policy to policy.HashtagSetProvider
+type hashtagSetAdapter struct {
+	policy *templatePolicy.Policy
+}
+
+func (a *hashtagSetAdapter) GetHashtagSet(ctx context.Context, accountID, id string) (string, error) {
+	tmpl, err := a.policy.GetByID(ctx, id, accountID)
+	if errors.Is(err, templateEntity.ErrTemplateNotFound) {
+		return "", pubEntity.ErrHashtagSetNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if tmpl.Type != templateEntity.TemplateTypeHashtags {
+		return "", pubEntity.ErrHashtagSetNotFound
+	}
+	return tmpl.Content, nil
+}
+
+// auditRepoAdapter adapts auditDao.AuditPostgres to auditService.AuditRepository
+type auditRepoAdapter struct {
+	repo *auditDao.AuditPostgres
+}
+
+func (a *auditRepoAdapter) Create(ctx context.Context, e *auditEntity.Entry) error {
+	return a.repo.Create(ctx, e)
+}
+
+func (a *auditRepoAdapter) List(ctx context.Context, filter auditService.ListFilter, limit, offset int) ([]auditEntity.Entry, error) {
+	return a.repo.List(ctx, auditDao.ListFilter(filter), limit, offset)
+}
+
+func (a *auditRepoAdapter) Count(ctx context.Context, filter auditService.ListFilter) (int64, error) {
+	return a.repo.Count(ctx, auditDao.ListFilter(filter))
+}
+
+// auditWriteTimeout bounds an audit write so it cannot hold up the audited action
+const auditWriteTimeout = 5 * time.Second
+
+// auditRecorderAdapter adapts the audit service to the policies' AuditRecorder.
+// The principal is taken from the authenticated request; failures are logged, never returned.
+type auditRecorderAdapter struct {
+	svc    *auditService.Service
+	logger *slog.Logger
+}
+
+func (a *auditRecorderAdapter) Record(ctx context.Context, action, accountID, targetID string, err error) {
+	// The entry is written even if the request was cancelled after the action
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), auditWriteTimeout)
+	defer cancel()
+
+	_, recErr := a.svc.Record(ctx, auditService.RecordInput{
+		AccountID: accountID,
+		Principal: httpmw.Principal(ctx),
+		Action:    action,
+		TargetID:  targetID,
+		Err:       err,
+	})
+	if recErr != nil {
+		a.logger.Warn("failed to record audit entry",
+			"action", action,
+			"account_id", accountID,
+			"target_id", targetID,
+			"error", recErr,
+		)
+	}
+}
+
+// apiKeyScopeAdapter limits policy operations to the accounts the request's API key is scoped to
+type apiKeyScopeAdapter struct{}
+
+func (apiKeyScopeAdapter) AccountAllowed(ctx context.Context, accountID string) bool {
+	return httpmw.AccountAllowed(ctx, accountID)
+}
+
+// mediaFitterAdapter fits images stored in S3 to Instagram aspect ratios, uploading the result as a new object
+type mediaFitterAdapter struct {
+	storage *storage.S3Storage
+}
+
+func (a *mediaFitterAdapter) Fit(ctx context.Context, in httpcontroller.MediaFitInput) (*httpcontroller.MediaFitOutput, error) {
+	key := in.Key
+	if in.URL != "" {
+		var err error
+		if key, err = a.storage.KeyFromURL(in.URL); err != nil {
+			return nil, fmt.Errorf("%w: %v", httpcontroller.ErrFitSourceInvalid, err)
+		}
+	}
+
+	data, err := a.storage.Download(ctx, key, httpcontroller.MaxUploadSize)
+	switch {
+	case errors.Is(err, storage.ErrObjectNotFound):
+		return nil, fmt.Errorf("%w: %v", httpcontroller.ErrFitSourceNotFound, err)
+	case errors.Is(err, storage.ErrObjectTooLarge):
+		return nil, fmt.Errorf("%w: %v", httpcontroller.ErrFitSourceInvalid, err)
+	case err != nil:
+		return nil, err
+	}
+
+	img, err := imagefit.Decode(data, imagefit.DefaultMaxPixels)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", httpcontroller.ErrFitSourceInvalid, err)
+	}
+	fitted, err := imagefit.Fit(img, in.Aspect, in.Mode, color.White, imagefit.DefaultMaxPixels)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", httpcontroller.ErrFitSourceInvalid, err)
+	}
+	encoded, err := imagefit.EncodeJPEG(fitted)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := (&mediaUploaderAdapter{a.storage}).Upload(ctx, httpcontroller.MediaUploadInput{
+		Reader:      bytes.NewReader(encoded),
+		ContentType: "image/jpeg",
+		Size:        int64(len(encoded)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &httpcontroller.MediaFitOutput{
+		URL:    out.URL,
+		Key:    out.Key,
+		Size:   out.Size,
+		Width:  fitted.Bounds().Dx(),
+		Height: fitted.Bounds().Dy(),
+	}, nil
+}
@@ -16,6 +16,42 @@ type Config struct {
 	Database  Database  `yaml:"database"`
 	Scheduler Scheduler `yaml:"scheduler"`
 	S3        S3        `yaml:"s3"`
+	Auth      Auth      `yaml:"auth"`
+	RateLimit RateLimit `yaml:"rate_limit"`
+	CORS      CORS      `yaml:"cors"`
+	Webhook   Webhook   `yaml:"webhook"`
+}
+
+// Webhook holds outbound notification configuration
+type Webhook struct {
+	URL        string        `yaml:"url" env:"WEBHOOK_URL"`       // Receives publication status changes; empty disables webhooks
+	Secret     string        `yaml:"secret" env:"WEBHOOK_SECRET"` // HMAC key for the X-Webhook-Signature header
+	Timeout    time.Duration `yaml:"timeout" env:"WEBHOOK_TIMEOUT" env-default:"10s"`
+	MaxRetries int           `yaml:"max_retries" env:"WEBHOOK_MAX_RETRIES" env-default:"3"`
+}
+
+// CORS holds cross-origin request configuration
+type CORS struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"` // Empty denies all cross-origin requests
+	AllowedMethods   []string      `yaml:"allowed_methods" env:"CORS_ALLOWED_METHODS" env-default:"GET,POST,PUT,DELETE,OPTIONS"`
+	AllowedHeaders   []string      `yaml:"allowed_headers" env:"CORS_ALLOWED_HEADERS" env-default:"Authorization,Content-Type,X-API-Key,X-Response-Envelope,If-None-Match"`
+	ExposedHeaders   []string      `yaml:"exposed_headers" env:"CORS_EXPOSED_HEADERS" env-default:"ETag,Retry-After,Content-Disposition"` // Response headers browsers may read
+	AllowCredentials bool          `yaml:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS" env-default:"false"`
+	MaxAge           time.Duration `yaml:"max_age" env:"CORS_MAX_AGE" env-default:"10m"`
+}
+
+// Auth holds API key authentication configuration
+type Auth struct {
+	Enabled bool     `yaml:"enabled" env:"AUTH_ENABLED" env-default:"false"`
+	APIKeys []string `yaml:"api_keys" env:"AUTH_API_KEYS"` // Comma-separated; "key" or "key:acc1|acc2" to restrict accounts; "key:acc1|acc2:tier" or "key::tier" for a rate limit tier
+}
+
+// RateLimit holds limits on API requests per API key, or per client IP without one
+type RateLimit struct {
+	Enabled bool     `yaml:"enabled" env:"RATE_LIMIT_ENABLED" env-default:"false"`
+	Rate    float64  `yaml:"rate" env:"RATE_LIMIT_RATE" env-default:"10"`   // Requests per second on average
+	Burst   int      `yaml:"burst" env:"RATE_LIMIT_BURST" env-default:"20"` // Requests allowed at once
+	Tiers   []string `yaml:"tiers" env:"RATE_LIMIT_TIERS"`                  // Comma-separated "name=rate/burst" for keys with that tier
 }
 
 // Logger holds logging configuration
@@ -31,6 +67,10 @@ type S3 struct {
 	Bucket          string `yaml:"bucket" env:"S3_BUCKET" env-default:"media"`
 	Region          string `yaml:"region" env:"S3_REGION" env-default:"us-east-1"`
 	PublicURL       string `yaml:"public_url" env:"S3_PUBLIC_URL" env-default:"http://localhost:9000/media"`
+	UploadAttempts  int    `yaml:"upload_attempts" env:"S3_UPLOAD_ATTEMPTS" env-default:"3"` // Tries per upload on transient errors
+
+	MultipartThreshold int64 `yaml:"multipart_threshold" env:"S3_MULTIPART_THRESHOLD" env-default:"16777216"` // Uploads above this many bytes go in parts
+	PartSize           int64 `yaml:"part_size" env:"S3_PART_SIZE" env-default:"8388608"`                      // Bytes per multipart part, at least 5 MiB
 }
 
 // Server holds HTTP server configuration
@@ -40,6 +80,24 @@ type Server struct {
 	ReadTimeout  time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT" env-default:"15s"`
 	WriteTimeout time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT" env-default:"15s"`
 	IdleTimeout  time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" env-default:"60s"`
+	MaxBodySize  int64         `yaml:"max_body_size" env:"SERVER_MAX_BODY_SIZE" env-default:"1048576"` // Max JSON request body in bytes
+
+	// Gzip API responses for clients that accept it; bodies below CompressMinSize bytes are sent as is
+	Compress        bool `yaml:"compress" env:"SERVER_COMPRESS" env-default:"false"`
+	CompressMinSize int  `yaml:"compress_min_size" env:"SERVER_COMPRESS_MIN_SIZE" env-default:"1024"`
+
+	// Per-route request timeouts (0 = no limit)
+	ReadRouteTimeout  time.Duration `yaml:"read_route_timeout" env:"SERVER_READ_ROUTE_TIMEOUT" env-default:"10s"`   // GET requests
+	WriteRouteTimeout time.Duration `yaml:"write_route_timeout" env:"SERVER_WRITE_ROUTE_TIMEOUT" env-default:"30s"` // Other requests
+	LongRouteTimeout  time.Duration `yaml:"long_route_timeout" env:"SERVER_LONG_ROUTE_TIMEOUT" env-default:"10m"`   // Publish and sync requests that poll Instagram
+
+	// Page sizes of list endpoints: used when the client sends no limit, and the cap for larger ones
+	PageDefaultLimit int `yaml:"page_default_limit" env:"SERVER_PAGE_DEFAULT_LIMIT" env-default:"50"`
+	PageMaxLimit     int `yaml:"page_max_limit" env:"SERVER_PAGE_MAX_LIMIT" env-default:"100"`
+
+	// Order of GET /publications when the client sends no sort_by or order
+	PublicationsDefaultSort  string `yaml:"publications_default_sort" env:"PUBLICATIONS_DEFAULT_SORT" env-default:"scheduled_at"`
+	PublicationsDefaultOrder string `yaml:"publications_default_order" env:"PUBLICATIONS_DEFAULT_ORDER" env-default:"desc"`
 }
 
 // Address returns the full server address
@@ -51,6 +109,23 @@ func (s Server) Address() string {
 type Instagram struct {
 	BaseURL    string `yaml:"base_url" env:"INSTAGRAM_BASE_URL" env-default:"https://graph.instagram.com"`
 	APIVersion string `yaml:"api_version" env:"INSTAGRAM_API_VERSION" env-default:"v21.0"`
+	UserAgent  string `yaml:"user_agent" env:"INSTAGRAM_USER_AGENT"` // Identifies our Graph API traffic; empty uses the client default
+
+	// How long account access tokens and user IDs are cached in memory (0 = no caching)
+	CredentialsCacheTTL time.Duration `yaml:"credentials_cache_ttl" env:"INSTAGRAM_CREDENTIALS_CACHE_TTL" env-default:"30s"`
+
+	// Check every account's access token in the background on startup and record the result
+	VerifyTokensOnStart bool `yaml:"verify_tokens_on_start" env:"INSTAGRAM_VERIFY_TOKENS_ON_START" env-default:"false"`
+
+	// Pause comment and DM sync when the rate limit usage reported in X-App-Usage or
+	// X-Business-Use-Case-Usage reaches this percent (0 = never pause); a report at or over it
+	// pauses syncs for the cooldown, after which the next run checks the usage again
+	UsagePauseThreshold int           `yaml:"usage_pause_threshold" env:"INSTAGRAM_USAGE_PAUSE_THRESHOLD" env-default:"90"`
+	UsagePauseCooldown  time.Duration `yaml:"usage_pause_cooldown" env:"INSTAGRAM_USAGE_PAUSE_COOLDOWN" env-default:"5m"`
+
+	// Reuse the carousel item container created for the same account and media URL within this
+	// time (0 = always create); keep it below Instagram's ~24h container validity
+	CarouselItemCacheTTL time.Duration `yaml:"carousel_item_cache_ttl" env:"INSTAGRAM_CAROUSEL_ITEM_CACHE_TTL" env-default:"0"`
 }
 
 // Database holds database configuration
@@ -58,10 +133,18 @@ type Database struct {
 	// PostgreSQL
 	PostgresDSN string `yaml:"postgres_dsn" env:"DATABASE_URL"`
 
-	// Connection pool settings
-	MaxOpenConns int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS" env-default:"25"`
-	MaxIdleConns int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" env-default:"5"`
-	ConnLifetime time.Duration `yaml:"conn_lifetime" env:"DB_CONN_LIFETIME" env-default:"5m"`
+	// Connection pool settings; MaxConns must stay below the server's max_connections
+	// divided by the number of app instances
+	MaxConns        int32         `yaml:"max_conns" env:"DB_MAX_CONNS" env-default:"25"`
+	MinConns        int32         `yaml:"min_conns" env:"DB_MIN_CONNS" env-default:"5"`
+	MaxConnLifetime time.Duration `yaml:"max_conn_lifetime" env:"DB_MAX_CONN_LIFETIME" env-default:"1h"`
+	MaxConnIdleTime time.Duration `yaml:"max_conn_idle_time" env:"DB_MAX_CONN_IDLE_TIME" env-default:"30m"`
+
+	// Queries running at least this long are logged with their SQL; 0 disables the log
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold" env:"DB_SLOW_QUERY_THRESHOLD" env-default:"500ms"`
+
+	// Most rows a bulk upsert (comment and direct sync) sends in one round-trip
+	UpsertBatchSize int `yaml:"upsert_batch_size" env:"DB_UPSERT_BATCH_SIZE" env-default:"500"`
 }
 
 // Scheduler holds scheduler configuration
@@ -69,6 +152,43 @@ type Scheduler struct {
 	Enabled  bool          `yaml:"enabled" env:"SCHEDULER_ENABLED" env-default:"false"`
 	Interval time.Duration `yaml:"interval" env:"SCHEDULER_INTERVAL" env-default:"1m"`
 
+	// Jitter settings (spread scheduler runs so they don't hit Instagram simultaneously)
+	Jitter        float64       `yaml:"jitter" env:"SCHEDULER_JITTER" env-default:"0.1"`                 // Fraction of interval, e.g. 0.1 = ±10%
+	StartupJitter time.Duration `yaml:"startup_jitter" env:"SCHEDULER_STARTUP_JITTER" env-default:"30s"` // Max random delay before first run
+
+	// How long shutdown waits for an in-flight scheduler run before cancelling it
+	StopTimeout time.Duration `yaml:"stop_timeout" env:"SCHEDULER_STOP_TIMEOUT" env-default:"30s"`
+
+	// Retry of scheduled publications after temporary Instagram failures (rate limit, 5xx)
+	PublishMaxAttempts   int           `yaml:"publish_max_attempts" env:"PUBLISH_MAX_ATTEMPTS" env-default:"5"`
+	PublishRetryDelay    time.Duration `yaml:"publish_retry_delay" env:"PUBLISH_RETRY_DELAY" env-default:"1m"`         // Doubled after each failed attempt
+	PublishRetryMaxDelay time.Duration `yaml:"publish_retry_max_delay" env:"PUBLISH_RETRY_MAX_DELAY" env-default:"1h"` // Upper bound for the delay
+
+	// Publications per account in the trailing 24h (Instagram's limit is 25, 0 = unlimited);
+	// scheduled publications beyond it are deferred until a slot frees up
+	PublishDailyLimit int `yaml:"publish_daily_limit" env:"PUBLISH_DAILY_LIMIT" env-default:"25"`
+
+	// Create and validate the Instagram container when a publication is scheduled within the window;
+	// the scheduler then only publishes it. Instagram expires containers after about 24 hours.
+	PublishPrecreateContainers bool          `yaml:"publish_precreate_containers" env:"PUBLISH_PRECREATE_CONTAINERS" env-default:"false"`
+	PublishPrecreateWindow     time.Duration `yaml:"publish_precreate_window" env:"PUBLISH_PRECREATE_WINDOW" env-default:"12h"`
+
+	// Reject scheduling when a HEAD request to a media URL fails with a non-2xx status or
+	// returns a content type other than image or video; unreachable URLs are only logged
+	PublishCheckMediaURLs    bool          `yaml:"publish_check_media_urls" env:"PUBLISH_CHECK_MEDIA_URLS" env-default:"false"`
+	PublishMediaCheckTimeout time.Duration `yaml:"publish_media_check_timeout" env:"PUBLISH_MEDIA_CHECK_TIMEOUT" env-default:"5s"`
+
+	// Publish endpoint queues the publication and returns 202 instead of waiting for Instagram;
+	// a worker polls the queue every PublishQueueInterval, independently of SCHEDULER_ENABLED
+	PublishAsync         bool          `yaml:"publish_async" env:"PUBLISH_ASYNC" env-default:"false"`
+	PublishQueueInterval time.Duration `yaml:"publish_queue_interval" env:"PUBLISH_QUEUE_INTERVAL" env-default:"5s"`
+
+	// Check publications left in publishing or error with a container against Instagram and mark
+	// the ones it did publish as published, e.g. when recording the publish failed;
+	// runs independently of SCHEDULER_ENABLED
+	PublishReconcileEnabled  bool          `yaml:"publish_reconcile_enabled" env:"PUBLISH_RECONCILE_ENABLED" env-default:"false"`
+	PublishReconcileInterval time.Duration `yaml:"publish_reconcile_interval" env:"PUBLISH_RECONCILE_INTERVAL" env-default:"10m"`
+
 	// Comment sync settings
 	CommentSyncInterval   time.Duration `yaml:"comment_sync_interval" env:"COMMENT_SYNC_INTERVAL" env-default:"5m"`
 	CommentSyncAge        time.Duration `yaml:"comment_sync_age" env:"COMMENT_SYNC_AGE" env-default:"10m"`
@@ -76,11 +196,31 @@ type Scheduler struct {
 	CommentSyncMaxRetries int           `yaml:"comment_sync_max_retries" env:"COMMENT_SYNC_MAX_RETRIES" env-default:"5"`
 	CommentCacheMaxAge    time.Duration `yaml:"comment_cache_max_age" env:"COMMENT_CACHE_MAX_AGE" env-default:"5m"` // How old cache can be before API refresh
 
+	// Skip posts published longer ago than this when syncing comments (0 = no limit)
+	CommentSyncMaxPostAge time.Duration `yaml:"comment_sync_max_post_age" env:"COMMENT_SYNC_MAX_POST_AGE" env-default:"0"`
+
+	// How often comments of a media are fetched in full to drop the ones deleted on Instagram (0 = never)
+	CommentSyncReconcileInterval time.Duration `yaml:"comment_sync_reconcile_interval" env:"COMMENT_SYNC_RECONCILE_INTERVAL" env-default:"24h"`
+
+	// Tag synced comments as positive/neutral/negative
+	CommentSentimentEnabled bool `yaml:"comment_sentiment_enabled" env:"COMMENT_SENTIMENT_ENABLED" env-default:"true"`
+
 	// Direct message sync settings
 	DirectSyncInterval   time.Duration `yaml:"direct_sync_interval" env:"DIRECT_SYNC_INTERVAL" env-default:"10m"`
 	DirectSyncAge        time.Duration `yaml:"direct_sync_age" env:"DIRECT_SYNC_AGE" env-default:"30m"`
 	DirectSyncBatchSize  int           `yaml:"direct_sync_batch_size" env:"DIRECT_SYNC_BATCH_SIZE" env-default:"5"`
 	DirectSyncMaxRetries int           `yaml:"direct_sync_max_retries" env:"DIRECT_SYNC_MAX_RETRIES" env-default:"5"`
+
+	// Stop a conversation's message sync after this many messages (0 = whole history).
+	// Later syncs only fetch messages newer than the previous one either way.
+	DirectMaxMessagesPerConversation int `yaml:"direct_max_messages_per_conversation" env:"DIRECT_MAX_MESSAGES_PER_CONVERSATION" env-default:"0"`
+
+	// Stop a conversation's message sync after this many API pages, in case pagination never ends (0 = no limit)
+	DirectMaxMessagePages int `yaml:"direct_max_message_pages" env:"DIRECT_MAX_MESSAGE_PAGES" env-default:"1000"`
+
+	// How often an account's whole conversation list is re-fetched (0 = on every sync).
+	// Syncs in between stop at conversations not updated since the previous one.
+	DirectSyncFullInterval time.Duration `yaml:"direct_sync_full_interval" env:"DIRECT_SYNC_FULL_INTERVAL" env-default:"24h"`
 }
 
 // MustLoad loads configuration from environment and panics on error
@@ -0,0 +1,240 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// validLogLevels lists the accepted LOG_LEVEL values
+var validLogLevels = []string{"debug", "info", "warn", "warning", "error"}
+
+// validPublicationSorts and validSortOrders list the accepted PUBLICATIONS_DEFAULT_SORT and
+// PUBLICATIONS_DEFAULT_ORDER values
+var (
+	validPublicationSorts = []string{"scheduled_at", "created_at", "published_at", "updated_at"}
+	validSortOrders       = []string{"asc", "desc"}
+)
+
+// Validate checks cross-field invariants and reports every problem found
+func (c Config) Validate() error {
+	var errs []error
+
+	// Logger
+	level := strings.ToLower(c.Logger.Level)
+	validLevel := false
+	for _, l := range validLogLevels {
+		if level == l {
+			validLevel = true
+			break
+		}
+	}
+	if !validLevel {
+		errs = append(errs, fmt.Errorf("LOG_LEVEL %q is invalid, expected one of: %s", c.Logger.Level, strings.Join(validLogLevels, ", ")))
+	}
+
+	// Server
+	if c.Server.MaxBodySize <= 0 {
+		errs = append(errs, fmt.Errorf("SERVER_MAX_BODY_SIZE must be positive, got %d", c.Server.MaxBodySize))
+	}
+	if c.Server.CompressMinSize < 0 {
+		errs = append(errs, fmt.Errorf("SERVER_COMPRESS_MIN_SIZE must not be negative, got %d", c.Server.CompressMinSize))
+	}
+	if c.Server.PageDefaultLimit <= 0 {
+		errs = append(errs, fmt.Errorf("SERVER_PAGE_DEFAULT_LIMIT must be positive, got %d", c.Server.PageDefaultLimit))
+	}
+	if c.Server.PageMaxLimit < c.Server.PageDefaultLimit {
+		errs = append(errs, fmt.Errorf("SERVER_PAGE_MAX_LIMIT must not be below SERVER_PAGE_DEFAULT_LIMIT, got %d", c.Server.PageMaxLimit))
+	}
+	if !slices.Contains(validPublicationSorts, c.Server.PublicationsDefaultSort) {
+		errs = append(errs, fmt.Errorf("PUBLICATIONS_DEFAULT_SORT %q is invalid, expected one of: %s", c.Server.PublicationsDefaultSort, strings.Join(validPublicationSorts, ", ")))
+	}
+	if !slices.Contains(validSortOrders, c.Server.PublicationsDefaultOrder) {
+		errs = append(errs, fmt.Errorf("PUBLICATIONS_DEFAULT_ORDER %q is invalid, expected one of: %s", c.Server.PublicationsDefaultOrder, strings.Join(validSortOrders, ", ")))
+	}
+	notNegative := func(name string, d time.Duration) {
+		if d < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %s", name, d))
+		}
+	}
+	notNegative("SERVER_READ_ROUTE_TIMEOUT", c.Server.ReadRouteTimeout)
+	notNegative("SERVER_WRITE_ROUTE_TIMEOUT", c.Server.WriteRouteTimeout)
+	notNegative("SERVER_LONG_ROUTE_TIMEOUT", c.Server.LongRouteTimeout)
+
+	// S3: credentials and bucket are required once an endpoint is configured
+	if c.S3.Endpoint != "" {
+		if c.S3.Bucket == "" {
+			errs = append(errs, errors.New("S3_BUCKET is required when S3_ENDPOINT is set"))
+		}
+		if c.S3.AccessKeyID == "" {
+			errs = append(errs, errors.New("S3_ACCESS_KEY_ID is required when S3_ENDPOINT is set"))
+		}
+		if c.S3.SecretAccessKey == "" {
+			errs = append(errs, errors.New("S3_SECRET_ACCESS_KEY is required when S3_ENDPOINT is set"))
+		}
+	}
+
+	// Database
+	if c.Database.PostgresDSN != "" {
+		if _, err := pgxpool.ParseConfig(c.Database.PostgresDSN); err != nil {
+			errs = append(errs, fmt.Errorf("DATABASE_URL is invalid: %w", err))
+		}
+	}
+	if c.Database.MaxConns <= 0 {
+		errs = append(errs, fmt.Errorf("DB_MAX_CONNS must be positive, got %d", c.Database.MaxConns))
+	}
+	if c.Database.MinConns < 0 {
+		errs = append(errs, fmt.Errorf("DB_MIN_CONNS must not be negative, got %d", c.Database.MinConns))
+	}
+	if c.Database.MinConns > c.Database.MaxConns {
+		errs = append(errs, fmt.Errorf("DB_MIN_CONNS (%d) must not exceed DB_MAX_CONNS (%d)", c.Database.MinConns, c.Database.MaxConns))
+	}
+	notNegative("DB_MAX_CONN_LIFETIME", c.Database.MaxConnLifetime)
+	notNegative("DB_MAX_CONN_IDLE_TIME", c.Database.MaxConnIdleTime)
+	notNegative("DB_SLOW_QUERY_THRESHOLD", c.Database.SlowQueryThreshold)
+	if c.Database.UpsertBatchSize <= 0 {
+		errs = append(errs, fmt.Errorf("DB_UPSERT_BATCH_SIZE must be positive, got %d", c.Database.UpsertBatchSize))
+	}
+
+	// Instagram
+	notNegative("INSTAGRAM_CREDENTIALS_CACHE_TTL", c.Instagram.CredentialsCacheTTL)
+	notNegative("INSTAGRAM_USAGE_PAUSE_COOLDOWN", c.Instagram.UsagePauseCooldown)
+	if c.Instagram.UsagePauseThreshold < 0 || c.Instagram.UsagePauseThreshold > 100 {
+		errs = append(errs, fmt.Errorf("INSTAGRAM_USAGE_PAUSE_THRESHOLD must be between 0 and 100, got %d", c.Instagram.UsagePauseThreshold))
+	}
+
+	// S3
+	if c.S3.UploadAttempts <= 0 {
+		errs = append(errs, fmt.Errorf("S3_UPLOAD_ATTEMPTS must be positive, got %d", c.S3.UploadAttempts))
+	}
+	if c.S3.MultipartThreshold <= 0 {
+		errs = append(errs, fmt.Errorf("S3_MULTIPART_THRESHOLD must be positive, got %d", c.S3.MultipartThreshold))
+	}
+	if c.S3.PartSize < 5<<20 {
+		errs = append(errs, fmt.Errorf("S3_PART_SIZE must be at least 5 MiB (5242880 bytes), got %d", c.S3.PartSize))
+	}
+
+	// Auth
+	if c.Auth.Enabled {
+		if len(c.Auth.APIKeys) == 0 {
+			errs = append(errs, errors.New("AUTH_API_KEYS is required when AUTH_ENABLED is true"))
+		}
+		for i, entry := range c.Auth.APIKeys {
+			if key, _, _ := strings.Cut(strings.TrimSpace(entry), ":"); key == "" {
+				errs = append(errs, fmt.Errorf("AUTH_API_KEYS entry %d has an empty key", i))
+			}
+		}
+	}
+
+	// Rate limit
+	if c.RateLimit.Enabled {
+		if c.RateLimit.Rate <= 0 {
+			errs = append(errs, fmt.Errorf("RATE_LIMIT_RATE must be positive, got %g", c.RateLimit.Rate))
+		}
+		if c.RateLimit.Burst < 1 {
+			errs = append(errs, fmt.Errorf("RATE_LIMIT_BURST must be at least 1, got %d", c.RateLimit.Burst))
+		}
+	}
+
+	// CORS: the spec forbids a wildcard origin together with credentials
+	if c.CORS.AllowCredentials {
+		for _, o := range c.CORS.AllowedOrigins {
+			if strings.TrimSpace(o) == "*" {
+				errs = append(errs, errors.New(`CORS_ALLOWED_ORIGINS cannot contain "*" when CORS_ALLOW_CREDENTIALS is true`))
+				break
+			}
+		}
+	}
+
+	// Webhook: deliveries are signed, so a secret is required once a URL is set
+	if c.Webhook.URL != "" {
+		if u, err := url.Parse(c.Webhook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("WEBHOOK_URL %q must be an absolute http(s) URL", c.Webhook.URL))
+		}
+		if c.Webhook.Secret == "" {
+			errs = append(errs, errors.New("WEBHOOK_SECRET is required when WEBHOOK_URL is set"))
+		}
+		if c.Webhook.Timeout <= 0 {
+			errs = append(errs, fmt.Errorf("WEBHOOK_TIMEOUT must be positive, got %s", c.Webhook.Timeout))
+		}
+		if c.Webhook.MaxRetries < 0 {
+			errs = append(errs, fmt.Errorf("WEBHOOK_MAX_RETRIES must not be negative, got %d", c.Webhook.MaxRetries))
+		}
+	}
+
+	// Scheduler
+	if c.Scheduler.Enabled {
+		errs = append(errs, c.Scheduler.validate()...)
+	}
+	if c.Scheduler.PublishAsync && c.Scheduler.PublishQueueInterval <= 0 {
+		errs = append(errs, fmt.Errorf("PUBLISH_QUEUE_INTERVAL must be positive, got %s", c.Scheduler.PublishQueueInterval))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks scheduler settings that only matter when schedulers run
+func (s Scheduler) validate() []error {
+	var errs []error
+
+	positive := func(name string, d time.Duration) {
+		if d <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %s", name, d))
+		}
+	}
+	positive("SCHEDULER_INTERVAL", s.Interval)
+	positive("SCHEDULER_STOP_TIMEOUT", s.StopTimeout)
+	positive("PUBLISH_RETRY_DELAY", s.PublishRetryDelay)
+	positive("PUBLISH_RETRY_MAX_DELAY", s.PublishRetryMaxDelay)
+	positive("COMMENT_SYNC_INTERVAL", s.CommentSyncInterval)
+	positive("COMMENT_SYNC_AGE", s.CommentSyncAge)
+	positive("DIRECT_SYNC_INTERVAL", s.DirectSyncInterval)
+	positive("DIRECT_SYNC_AGE", s.DirectSyncAge)
+
+	if s.StartupJitter < 0 {
+		errs = append(errs, fmt.Errorf("SCHEDULER_STARTUP_JITTER must not be negative, got %s", s.StartupJitter))
+	}
+	if s.Jitter < 0 || s.Jitter >= 1 {
+		errs = append(errs, fmt.Errorf("SCHEDULER_JITTER must be in [0, 1), got %g", s.Jitter))
+	}
+	if s.CommentSyncMaxPostAge < 0 {
+		errs = append(errs, fmt.Errorf("COMMENT_SYNC_MAX_POST_AGE must not be negative, got %s", s.CommentSyncMaxPostAge))
+	}
+	if s.CommentSyncReconcileInterval < 0 {
+		errs = append(errs, fmt.Errorf("COMMENT_SYNC_RECONCILE_INTERVAL must not be negative, got %s", s.CommentSyncReconcileInterval))
+	}
+	if s.PublishPrecreateContainers && (s.PublishPrecreateWindow <= 0 || s.PublishPrecreateWindow >= 24*time.Hour) {
+		errs = append(errs, fmt.Errorf("PUBLISH_PRECREATE_WINDOW must be between 0 and 24h (containers expire), got %s", s.PublishPrecreateWindow))
+	}
+	if s.PublishCheckMediaURLs && s.PublishMediaCheckTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("PUBLISH_MEDIA_CHECK_TIMEOUT must be positive, got %s", s.PublishMediaCheckTimeout))
+	}
+	if s.PublishMaxAttempts <= 0 {
+		errs = append(errs, fmt.Errorf("PUBLISH_MAX_ATTEMPTS must be positive, got %d", s.PublishMaxAttempts))
+	}
+	if s.PublishDailyLimit < 0 {
+		errs = append(errs, fmt.Errorf("PUBLISH_DAILY_LIMIT must not be negative, got %d", s.PublishDailyLimit))
+	}
+	if s.CommentSyncBatchSize <= 0 {
+		errs = append(errs, fmt.Errorf("COMMENT_SYNC_BATCH_SIZE must be positive, got %d", s.CommentSyncBatchSize))
+	}
+	if s.DirectSyncBatchSize <= 0 {
+		errs = append(errs, fmt.Errorf("DIRECT_SYNC_BATCH_SIZE must be positive, got %d", s.DirectSyncBatchSize))
+	}
+	if s.DirectMaxMessagesPerConversation < 0 {
+		errs = append(errs, fmt.Errorf("DIRECT_MAX_MESSAGES_PER_CONVERSATION must not be negative, got %d", s.DirectMaxMessagesPerConversation))
+	}
+	if s.DirectMaxMessagePages < 0 {
+		errs = append(errs, fmt.Errorf("DIRECT_MAX_MESSAGE_PAGES must not be negative, got %d", s.DirectMaxMessagePages))
+	}
+	if s.DirectSyncFullInterval < 0 {
+		errs = append(errs, fmt.Errorf("DIRECT_SYNC_FULL_INTERVAL must not be negative, got %s", s.DirectSyncFullInterval))
+	}
+
+	return errs
+}
@@ -3,53 +3,244 @@ package http
 import (
 	"context"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	httpmw "github.com/vadim/neo-metric/internal/httpx/middleware"
 	"github.com/vadim/neo-metric/internal/httpx/response"
 )
 
 // AccountInfo represents an Instagram account
 type AccountInfo struct {
-	ID              string `json:"id"`
-	InstagramUserID string `json:"instagram_user_id"`
-	Username        string `json:"username"`
-	HasAccessToken  bool   `json:"has_access_token"`
+	ID                 string     `json:"id"`
+	InstagramUserID    string     `json:"instagram_user_id"`
+	Username           string     `json:"username"`
+	HasAccessToken     bool       `json:"has_access_token"`
+	Name               string     `json:"name,omitempty"`
+	ProfilePictureURL  string     `json:"profile_picture_url,omitempty"`
+	FollowersCount     int        `json:"followers_count"`
+	MediaCount         int        `json:"media_count"`
+	ProfileRefreshedAt *time.Time `json:"profile_refreshed_at,omitempty"`
+	TokenStatus        string     `json:"token_status,omitempty"`
+	TokenCheckedAt     *time.Time `json:"token_checked_at,omitempty"`
+}
+
+// AccountTokenStatus represents the result of the last access token check for an account
+type AccountTokenStatus struct {
+	AccountID string     `json:"account_id"`
+	Username  string     `json:"username"`
+	Status    string     `json:"status"` // valid, unauthorized, missing, error or unchecked
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+}
+
+// SyncSummary aggregates the sync state of an account's media comments or conversations
+type SyncSummary struct {
+	Total          int64      `json:"total"`
+	NeverSynced    int64      `json:"never_synced"`
+	Retrying       int64      `json:"retrying"`
+	Failed         int64      `json:"failed"`
+	LastSyncedAt   *time.Time `json:"last_synced_at,omitempty"`   // Most recent successful sync
+	OldestSyncedAt *time.Time `json:"oldest_synced_at,omitempty"` // Least recent sync among items that are not failed
+}
+
+// ConversationListSync represents the sync state of an account's conversation list
+type ConversationListSync struct {
+	LastSyncedAt time.Time `json:"last_synced_at"`
+	SyncComplete bool      `json:"sync_complete"`
+	RetryCount   int       `json:"retry_count"`
+	Failed       bool      `json:"failed"`
+	LastError    string    `json:"last_error,omitempty"`
+}
+
+// AccountSyncStatus represents the comment and direct message sync health of an account
+type AccountSyncStatus struct {
+	AccountID        string                `json:"account_id"`
+	Healthy          bool                  `json:"healthy"` // Nothing is excluded from sync after repeated failures
+	Comments         SyncSummary           `json:"comments"`
+	Conversations    SyncSummary           `json:"conversations"`
+	ConversationList *ConversationListSync `json:"conversation_list,omitempty"` // Absent until the first conversation list sync
+}
+
+// SyncResetResult lists the syncs put back into rotation by a sync status reset
+type SyncResetResult struct {
+	AccountID        string   `json:"account_id"`
+	Reset            int      `json:"reset"`
+	MediaIDs         []string `json:"media_ids"`
+	ConversationIDs  []string `json:"conversation_ids"`
+	ConversationList bool     `json:"conversation_list"` // The conversation list sync was reset
+}
+
+// Account capabilities exercised by a connection test, in the order they are checked
+const (
+	CapabilityProfile           = "profile"            // Read the account profile
+	CapabilityContentPublishing = "content_publishing" // Publish posts and stories
+	CapabilityComments          = "comments"           // Read and moderate comments
+	CapabilityMessaging         = "messaging"          // Read and send direct messages
+)
+
+var testedCapabilities = []string{CapabilityProfile, CapabilityContentPublishing, CapabilityComments, CapabilityMessaging}
+
+// CapabilityCheck is the outcome of exercising one capability of an account's token
+type CapabilityCheck struct {
+	Capability string `json:"capability"`
+	OK         bool   `json:"ok"`
+	Error      string `json:"error,omitempty"` // Instagram's error when the check failed
+}
+
+// AccountConnectionTest reports which capabilities an account's token grants
+type AccountConnectionTest struct {
+	AccountID string            `json:"account_id"`
+	OK        bool              `json:"ok"` // Every capability works
+	Checks    []CapabilityCheck `json:"checks"`
+	TestedAt  time.Time         `json:"tested_at"`
+}
+
+// AccountListFilter narrows and pages an account listing; the zero value lists every account
+type AccountListFilter struct {
+	Query  string   // Case-insensitive substring of the username
+	IDs    []string // Only these accounts; nil lists all
+	Limit  int      // 0 lists all
+	Offset int
 }
 
 // AccountLister defines the interface for listing accounts
 type AccountLister interface {
-	ListAccounts(ctx context.Context) ([]AccountInfo, error)
+	// ListAccounts returns a page of the accounts matching the filter and the number of all matches
+	ListAccounts(ctx context.Context, filter AccountListFilter) ([]AccountInfo, int64, error)
+}
+
+// AccountProfileRefresher defines the interface for refreshing cached account profiles
+type AccountProfileRefresher interface {
+	RefreshProfile(ctx context.Context, accountID string) error
+}
+
+// AccountSyncStatusProvider defines the interface for reading an account's sync status
+type AccountSyncStatusProvider interface {
+	GetAccountSyncStatus(ctx context.Context, accountID string) (*AccountSyncStatus, error)
+	// ResetFailedSyncs re-enables the account's failed syncs; non-empty ID lists limit the reset to those items
+	ResetFailedSyncs(ctx context.Context, accountID string, mediaIDs, conversationIDs []string) (*SyncResetResult, error)
+}
+
+// AccountProber makes a read-only Instagram request that needs the capability of the account's token
+type AccountProber interface {
+	Probe(ctx context.Context, accountID, capability string) error
 }
 
 // AccountHandler handles HTTP requests for Instagram accounts
 type AccountHandler struct {
-	lister AccountLister
+	lister     AccountLister
+	refresher  AccountProfileRefresher
+	syncStatus AccountSyncStatusProvider
+	prober     AccountProber
+	pages      response.PageLimits
 }
 
 // NewAccountHandler creates a new account handler
-func NewAccountHandler(lister AccountLister) *AccountHandler {
-	return &AccountHandler{lister: lister}
+func NewAccountHandler(lister AccountLister, refresher AccountProfileRefresher, syncStatus AccountSyncStatusProvider) *AccountHandler {
+	return &AccountHandler{lister: lister, refresher: refresher, syncStatus: syncStatus, pages: response.DefaultPageLimits}
+}
+
+// WithPageLimits sets the page sizes of the account listing
+func (h *AccountHandler) WithPageLimits(l response.PageLimits) *AccountHandler {
+	h.pages = l
+	return h
+}
+
+// WithProber enables connection tests of accounts
+func (h *AccountHandler) WithProber(p AccountProber) *AccountHandler {
+	h.prober = p
+	return h
 }
 
 // RegisterRoutes registers account routes
 func (h *AccountHandler) RegisterRoutes(r chi.Router) {
 	r.Get("/accounts", h.List())
+	r.Get("/accounts/token-status", h.TokenStatus())
 	r.Get("/accounts/{id}", h.Get())
+	r.Post("/accounts/{id}/refresh-profile", h.RefreshProfile())
+	r.Get("/accounts/{id}/sync-status", h.SyncStatus())
+	r.Post("/accounts/{id}/sync-status/reset", h.ResetSyncStatus())
+	if h.prober != nil {
+		r.Post("/accounts/{id}/test", h.TestConnection())
+	}
 }
 
 // List handles GET /accounts
+// Query params: q (username search), limit, offset. Total counts all matching accounts.
 func (h *AccountHandler) List() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		accounts, err := h.lister.ListAccounts(r.Context())
+		limit, offset, err := h.pages.Parse(r)
+		if err != nil {
+			response.BadRequest(w, err.Error())
+			return
+		}
+
+		// Only show accounts the API key may act on
+		accounts, total, err := h.lister.ListAccounts(r.Context(), AccountListFilter{
+			Query:  strings.TrimSpace(r.URL.Query().Get("q")),
+			IDs:    httpmw.AllowedAccounts(r.Context()),
+			Limit:  limit,
+			Offset: offset,
+		})
 		if err != nil {
 			response.InternalError(w, "failed to list accounts")
 			return
 		}
+		if accounts == nil {
+			accounts = []AccountInfo{}
+		}
+
+		if response.WantsEnvelope(r) {
+			response.Paginated(w, accounts, response.Pagination{
+				Total:   response.Total(total),
+				Limit:   limit,
+				Offset:  offset,
+				HasMore: int64(offset+len(accounts)) < total,
+			})
+			return
+		}
 
 		response.OK(w, map[string]interface{}{
 			"accounts": accounts,
-			"total":    len(accounts),
+			"total":    total,
+		})
+	}
+}
+
+// TokenStatus handles GET /accounts/token-status
+func (h *AccountHandler) TokenStatus() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		all, _, err := h.lister.ListAccounts(r.Context(), AccountListFilter{IDs: httpmw.AllowedAccounts(r.Context())})
+		if err != nil {
+			response.InternalError(w, "failed to list accounts")
+			return
+		}
+
+		statuses := make([]AccountTokenStatus, 0, len(all))
+		unauthorized := 0
+		for _, acc := range all {
+			status := acc.TokenStatus
+			if status == "" {
+				status = "unchecked"
+			}
+			if status == "unauthorized" || status == "missing" {
+				unauthorized++
+			}
+
+			statuses = append(statuses, AccountTokenStatus{
+				AccountID: acc.ID,
+				Username:  acc.Username,
+				Status:    status,
+				CheckedAt: acc.TokenCheckedAt,
+			})
+		}
+
+		response.OK(w, map[string]interface{}{
+			"accounts":     statuses,
+			"total":        len(statuses),
+			"unauthorized": unauthorized,
 		})
 	}
 }
@@ -58,20 +249,197 @@ func (h *AccountHandler) List() http.HandlerFunc {
 func (h *AccountHandler) Get() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id := chi.URLParam(r, "id")
+		if !httpmw.AccountAllowed(r.Context(), id) {
+			response.NotFound(w, "account not found")
+			return
+		}
+
+		acc, err := h.find(r.Context(), id)
+		if err != nil {
+			response.InternalError(w, "failed to get account")
+			return
+		}
+		if acc == nil {
+			response.NotFound(w, "account not found")
+			return
+		}
+
+		response.OK(w, acc)
+	}
+}
+
+// RefreshProfile handles POST /accounts/{id}/refresh-profile
+func (h *AccountHandler) RefreshProfile() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if !httpmw.AccountAllowed(r.Context(), id) {
+			response.NotFound(w, "account not found")
+			return
+		}
+
+		acc, err := h.find(r.Context(), id)
+		if err != nil {
+			response.InternalError(w, "failed to get account")
+			return
+		}
+		if acc == nil {
+			response.NotFound(w, "account not found")
+			return
+		}
+		if !acc.HasAccessToken {
+			response.BadRequest(w, "account has no access token")
+			return
+		}
+
+		if err := h.refresher.RefreshProfile(r.Context(), id); err != nil {
+			response.Error(w, http.StatusBadGateway, "failed to fetch profile from Instagram")
+			return
+		}
+
+		acc, err = h.find(r.Context(), id)
+		if err != nil || acc == nil {
+			response.InternalError(w, "failed to get account")
+			return
+		}
+
+		response.OK(w, acc)
+	}
+}
+
+// TestConnection handles POST /accounts/{id}/test
+// It checks every capability with Instagram and stores nothing; failed checks still answer 200.
+func (h *AccountHandler) TestConnection() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if !httpmw.AccountAllowed(r.Context(), id) {
+			response.NotFound(w, "account not found")
+			return
+		}
 
-		accounts, err := h.lister.ListAccounts(r.Context())
+		acc, err := h.find(r.Context(), id)
 		if err != nil {
 			response.InternalError(w, "failed to get account")
 			return
 		}
+		if acc == nil {
+			response.NotFound(w, "account not found")
+			return
+		}
+		if !acc.HasAccessToken {
+			response.BadRequest(w, "account has no access token")
+			return
+		}
 
-		for _, acc := range accounts {
-			if acc.ID == id {
-				response.OK(w, acc)
-				return
+		result := AccountConnectionTest{
+			AccountID: id,
+			OK:        true,
+			Checks:    make([]CapabilityCheck, 0, len(testedCapabilities)),
+		}
+		for _, capability := range testedCapabilities {
+			check := CapabilityCheck{Capability: capability, OK: true}
+			if err := h.prober.Probe(r.Context(), id, capability); err != nil {
+				check.OK = false
+				check.Error = err.Error()
+				result.OK = false
 			}
+			result.Checks = append(result.Checks, check)
+		}
+		result.TestedAt = time.Now().UTC()
+
+		response.OK(w, result)
+	}
+}
+
+// SyncStatus handles GET /accounts/{id}/sync-status
+func (h *AccountHandler) SyncStatus() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if !httpmw.AccountAllowed(r.Context(), id) {
+			response.NotFound(w, "account not found")
+			return
+		}
+
+		acc, err := h.find(r.Context(), id)
+		if err != nil {
+			response.InternalError(w, "failed to get account")
+			return
+		}
+		if acc == nil {
+			response.NotFound(w, "account not found")
+			return
+		}
+
+		status, err := h.syncStatus.GetAccountSyncStatus(r.Context(), id)
+		if err != nil {
+			response.InternalError(w, "failed to get sync status")
+			return
+		}
+		status.Healthy = status.Comments.Failed == 0 && status.Conversations.Failed == 0 &&
+			(status.ConversationList == nil || !status.ConversationList.Failed)
+
+		response.OK(w, status)
+	}
+}
+
+// ResetSyncStatusRequest represents the optional body of a sync status reset
+type ResetSyncStatusRequest struct {
+	MediaIDs        []string `json:"media_ids,omitempty"`
+	ConversationIDs []string `json:"conversation_ids,omitempty"`
+}
+
+// ResetSyncStatus handles POST /accounts/{id}/sync-status/reset
+func (h *AccountHandler) ResetSyncStatus() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if !httpmw.AccountAllowed(r.Context(), id) {
+			response.NotFound(w, "account not found")
+			return
+		}
+
+		var req ResetSyncStatusRequest
+		if r.ContentLength != 0 && !decodeJSON(w, r, &req) {
+			return
 		}
 
-		response.NotFound(w, "account not found")
+		acc, err := h.find(r.Context(), id)
+		if err != nil {
+			response.InternalError(w, "failed to get account")
+			return
+		}
+		if acc == nil {
+			response.NotFound(w, "account not found")
+			return
+		}
+
+		result, err := h.syncStatus.ResetFailedSyncs(r.Context(), id, req.MediaIDs, req.ConversationIDs)
+		if err != nil {
+			response.InternalError(w, "failed to reset sync status")
+			return
+		}
+		if result.MediaIDs == nil {
+			result.MediaIDs = []string{}
+		}
+		if result.ConversationIDs == nil {
+			result.ConversationIDs = []string{}
+		}
+		result.Reset = len(result.MediaIDs) + len(result.ConversationIDs)
+		if result.ConversationList {
+			result.Reset++
+		}
+
+		response.OK(w, result)
 	}
 }
+
+// find returns the account with the given ID or nil if it does not exist
+func (h *AccountHandler) find(ctx context.Context, id string) (*AccountInfo, error) {
+	accounts, _, err := h.lister.ListAccounts(ctx, AccountListFilter{IDs: []string{id}})
+	if err != nil {
+		return nil, err
+	}
+	if len(accounts) == 0 {
+		return nil, nil
+	}
+
+	return &accounts[0], nil
+}
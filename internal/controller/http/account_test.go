@@ -0,0 +1,290 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	httpmw "github.com/vadim/neo-metric/internal/httpx/middleware"
+)
+
+// staticAccounts filters and pages a fixed set of accounts
+type staticAccounts []AccountInfo
+
+func (a staticAccounts) ListAccounts(_ context.Context, f AccountListFilter) ([]AccountInfo, int64, error) {
+	var matched []AccountInfo
+	for _, acc := range a {
+		if f.IDs != nil && !slices.Contains(f.IDs, acc.ID) {
+			continue
+		}
+		if !strings.Contains(strings.ToLower(acc.Username), strings.ToLower(f.Query)) {
+			continue
+		}
+		matched = append(matched, acc)
+	}
+	page := matched[min(f.Offset, len(matched)):]
+	if f.Limit > 0 && len(page) > f.Limit {
+		page = page[:f.Limit]
+	}
+	return page, int64(len(matched)), nil
+}
+
+// staticSyncStatus returns a copy of the same status for every account and
+// records the scope of resets
+type staticSyncStatus struct {
+	status AccountSyncStatus
+	reset  SyncResetResult
+
+	gotMediaIDs, gotConversationIDs []string
+}
+
+func (s *staticSyncStatus) GetAccountSyncStatus(_ context.Context, accountID string) (*AccountSyncStatus, error) {
+	status := s.status
+	status.AccountID = accountID
+	return &status, nil
+}
+
+func (s *staticSyncStatus) ResetFailedSyncs(_ context.Context, accountID string, mediaIDs, conversationIDs []string) (*SyncResetResult, error) {
+	s.gotMediaIDs, s.gotConversationIDs = mediaIDs, conversationIDs
+	result := s.reset
+	result.AccountID = accountID
+	return &result, nil
+}
+
+func serveAccounts(t *testing.T, p AccountSyncStatusProvider, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	r := chi.NewRouter()
+	NewAccountHandler(staticAccounts{{ID: "1"}}, nil, p).RegisterRoutes(r)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func listAccounts(t *testing.T, keys []httpmw.APIKey, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := chi.NewRouter()
+	if keys != nil {
+		r.Use(httpmw.APIKeyAuth(keys))
+	}
+	NewAccountHandler(staticAccounts{
+		{ID: "1", Username: "alpha_shop", HasAccessToken: true},
+		{ID: "2", Username: "beta"},
+		{ID: "3", Username: "Alpha_Cafe"},
+		{ID: "4", Username: "gamma.alpha"},
+	}, nil, nil).RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/accounts?"+query, nil)
+	req.Header.Set("X-API-Key", "secret")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func accountIDs(t *testing.T, rec *httptest.ResponseRecorder) ([]string, int64) {
+	t.Helper()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Accounts []AccountInfo `json:"accounts"`
+		Total    int64         `json:"total"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding body: %v", err)
+	}
+	var ids []string
+	for _, acc := range resp.Accounts {
+		ids = append(ids, acc.ID)
+	}
+	return ids, resp.Total
+}
+
+func TestListAccountsSearchAndPagination(t *testing.T) {
+	rec := listAccounts(t, nil, "q=ALPHA&limit=2&offset=1")
+	if strings.Contains(rec.Body.String(), `"access_token"`) {
+		t.Errorf("response exposes access tokens: %s", rec.Body)
+	}
+	ids, total := accountIDs(t, rec)
+	if !reflect.DeepEqual(ids, []string{"3", "4"}) || total != 3 {
+		t.Errorf("got %v of %d, want [3 4] of 3 matching accounts", ids, total)
+	}
+
+	if rec := listAccounts(t, nil, "limit=0"); rec.Code != http.StatusBadRequest {
+		t.Errorf("limit=0: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestListAccountsScopedToAPIKey(t *testing.T) {
+	keys := []httpmw.APIKey{{Key: "secret", AccountIDs: []string{"2", "3"}}}
+
+	ids, total := accountIDs(t, listAccounts(t, keys, "limit=1"))
+	if !reflect.DeepEqual(ids, []string{"2"}) || total != 2 {
+		t.Errorf("got %v of %d, want [2] of the key's 2 accounts", ids, total)
+	}
+}
+
+func getSyncStatus(t *testing.T, status AccountSyncStatus, id string) *httptest.ResponseRecorder {
+	t.Helper()
+	return serveAccounts(t, &staticSyncStatus{status: status}, httptest.NewRequest(http.MethodGet, "/accounts/"+id+"/sync-status", nil))
+}
+
+func TestSyncStatusReportsFailedSyncs(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  AccountSyncStatus
+		healthy bool
+	}{
+		{"all synced", AccountSyncStatus{Comments: SyncSummary{Total: 5}}, true},
+		{"retrying media", AccountSyncStatus{Comments: SyncSummary{Total: 5, Retrying: 2}}, true},
+		{"failed media", AccountSyncStatus{Comments: SyncSummary{Total: 5, Failed: 1}}, false},
+		{"failed conversation", AccountSyncStatus{Conversations: SyncSummary{Total: 3, Failed: 1}}, false},
+		{"failed conversation list", AccountSyncStatus{ConversationList: &ConversationListSync{Failed: true}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := getSyncStatus(t, tt.status, "1")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+			}
+
+			var got AccountSyncStatus
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("decoding body: %v", err)
+			}
+			if got.AccountID != "1" || got.Healthy != tt.healthy {
+				t.Errorf("got account %q healthy=%v, want 1 healthy=%v", got.AccountID, got.Healthy, tt.healthy)
+			}
+			if got.Comments.Failed != tt.status.Comments.Failed || got.Conversations.Failed != tt.status.Conversations.Failed {
+				t.Errorf("failed counts = %d/%d, want %d/%d", got.Comments.Failed, got.Conversations.Failed,
+					tt.status.Comments.Failed, tt.status.Conversations.Failed)
+			}
+		})
+	}
+}
+
+func TestSyncStatusUnknownAccount(t *testing.T) {
+	rec := getSyncStatus(t, AccountSyncStatus{}, "2")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+}
+
+func TestResetSyncStatusCountsResetItems(t *testing.T) {
+	p := &staticSyncStatus{reset: SyncResetResult{
+		MediaIDs:         []string{"m1", "m2"},
+		ConversationList: true,
+	}}
+	rec := serveAccounts(t, p, httptest.NewRequest(http.MethodPost, "/accounts/1/sync-status/reset", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if p.gotMediaIDs != nil || p.gotConversationIDs != nil {
+		t.Errorf("reset without a body scoped to %v/%v", p.gotMediaIDs, p.gotConversationIDs)
+	}
+
+	var got SyncResetResult
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decoding body: %v", err)
+	}
+	if got.Reset != 3 || got.AccountID != "1" || got.ConversationIDs == nil {
+		t.Errorf("got %+v, want 3 reset for account 1 with empty conversation_ids", got)
+	}
+}
+
+func TestResetSyncStatusScopedToIDs(t *testing.T) {
+	p := &staticSyncStatus{}
+	req := httptest.NewRequest(http.MethodPost, "/accounts/1/sync-status/reset",
+		strings.NewReader(`{"conversation_ids": ["c1"]}`))
+	rec := serveAccounts(t, p, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if p.gotMediaIDs != nil || !reflect.DeepEqual(p.gotConversationIDs, []string{"c1"}) {
+		t.Errorf("reset scoped to %v/%v, want []/[c1]", p.gotMediaIDs, p.gotConversationIDs)
+	}
+}
+
+// capabilityProber fails the capabilities in errs and records the probes made
+type capabilityProber struct {
+	errs   map[string]error
+	probed []string
+}
+
+func (p *capabilityProber) Probe(_ context.Context, accountID, capability string) error {
+	p.probed = append(p.probed, accountID+":"+capability)
+	return p.errs[capability]
+}
+
+func testConnection(t *testing.T, p AccountProber, id string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := chi.NewRouter()
+	NewAccountHandler(staticAccounts{{ID: "1", HasAccessToken: true}, {ID: "2"}}, nil, nil).
+		WithProber(p).
+		RegisterRoutes(r)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/accounts/"+id+"/test", nil))
+	return rec
+}
+
+func TestConnectionTestReportsEachCapability(t *testing.T) {
+	p := &capabilityProber{errs: map[string]error{
+		CapabilityContentPublishing: errors.New("instagram API error: Application does not have permission for this action (code: 10, subcode: 0)"),
+		CapabilityMessaging:         errors.New("instagram API error: (#3) Application does not have the capability to make this API call. (code: 3, subcode: 0)"),
+	}}
+	rec := testConnection(t, p, "1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+
+	var got AccountConnectionTest
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decoding body: %v", err)
+	}
+	if got.AccountID != "1" || got.OK || got.TestedAt.IsZero() {
+		t.Errorf("got account %q ok=%v tested_at=%v, want account 1 not ok with a test time", got.AccountID, got.OK, got.TestedAt)
+	}
+
+	want := map[string]bool{
+		CapabilityProfile:           true,
+		CapabilityContentPublishing: false,
+		CapabilityComments:          true,
+		CapabilityMessaging:         false,
+	}
+	if len(got.Checks) != len(want) {
+		t.Fatalf("got %d checks, want %d: %+v", len(got.Checks), len(want), got.Checks)
+	}
+	for _, check := range got.Checks {
+		if check.OK != want[check.Capability] {
+			t.Errorf("%s ok = %v, want %v", check.Capability, check.OK, want[check.Capability])
+		}
+		if wantErr := p.errs[check.Capability]; wantErr != nil && check.Error != wantErr.Error() {
+			t.Errorf("%s error = %q, want %q", check.Capability, check.Error, wantErr)
+		}
+		if check.OK && check.Error != "" {
+			t.Errorf("%s passed with error %q", check.Capability, check.Error)
+		}
+	}
+}
+
+func TestConnectionTestNeedsToken(t *testing.T) {
+	p := &capabilityProber{}
+	if rec := testConnection(t, p, "2"); rec.Code != http.StatusBadRequest {
+		t.Errorf("without token: status = %d, want 400", rec.Code)
+	}
+	if rec := testConnection(t, p, "3"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown account: status = %d, want 404", rec.Code)
+	}
+	if len(p.probed) != 0 {
+		t.Errorf("probed %v, want nothing", p.probed)
+	}
+}
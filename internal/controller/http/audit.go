@@ -0,0 +1,125 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/vadim/neo-metric/internal/domain/audit/entity"
+	"github.com/vadim/neo-metric/internal/domain/audit/service"
+	"github.com/vadim/neo-metric/internal/httpx/response"
+)
+
+// AuditLister defines the interface for reading the audit log
+type AuditLister interface {
+	List(ctx context.Context, in service.ListInput) (*service.ListOutput, error)
+}
+
+// AuditHandler handles HTTP requests for the audit log
+type AuditHandler struct {
+	audit AuditLister
+	pages response.PageLimits
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(a AuditLister) *AuditHandler {
+	return &AuditHandler{audit: a, pages: response.DefaultPageLimits}
+}
+
+// WithPageLimits sets the page sizes of the audit log listing
+func (h *AuditHandler) WithPageLimits(l response.PageLimits) *AuditHandler {
+	h.pages = l
+	return h
+}
+
+// RegisterRoutes registers audit routes
+func (h *AuditHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/audit", h.List())
+}
+
+// ListAuditResponse represents the response for listing audit entries
+type ListAuditResponse struct {
+	Entries []entity.Entry `json:"entries"`
+	Total   int64          `json:"total"`
+}
+
+// List handles GET /audit
+func (h *AuditHandler) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID := r.URL.Query().Get("account_id")
+		if accountID == "" {
+			response.AccountIDRequired(w)
+			return
+		}
+
+		in := service.ListInput{
+			ListFilter: service.ListFilter{
+				AccountID: accountID,
+				Action:    r.URL.Query().Get("action"),
+			},
+		}
+
+		var err error
+		if in.Limit, in.Offset, err = h.pages.Parse(r); err != nil {
+			response.BadRequest(w, err.Error())
+			return
+		}
+
+		// Optional date range (inclusive, YYYY-MM-DD)
+		if s := r.URL.Query().Get("start_date"); s != "" {
+			parsed, err := time.Parse("2006-01-02", s)
+			if err != nil {
+				response.BadRequest(w, "invalid start_date format, expected YYYY-MM-DD")
+				return
+			}
+			in.From = &parsed
+		}
+		if e := r.URL.Query().Get("end_date"); e != "" {
+			parsed, err := time.Parse("2006-01-02", e)
+			if err != nil {
+				response.BadRequest(w, "invalid end_date format, expected YYYY-MM-DD")
+				return
+			}
+			endOfDay := parsed.Add(24*time.Hour - time.Nanosecond)
+			in.To = &endOfDay
+		}
+		if in.From != nil && in.To != nil && in.From.After(*in.To) {
+			response.BadRequest(w, "start_date must not be after end_date")
+			return
+		}
+
+		result, err := h.audit.List(r.Context(), in)
+		if err != nil {
+			handleAuditError(w, err)
+			return
+		}
+
+		if response.WantsEnvelope(r) {
+			response.Paginated(w, result.Entries, response.Pagination{
+				Total:   response.Total(result.Total),
+				Limit:   in.Limit,
+				Offset:  in.Offset,
+				HasMore: int64(in.Offset+len(result.Entries)) < result.Total,
+			})
+			return
+		}
+
+		response.OK(w, ListAuditResponse{
+			Entries: result.Entries,
+			Total:   result.Total,
+		})
+	}
+}
+
+func handleAuditError(w http.ResponseWriter, err error) {
+	switch err {
+	case entity.ErrEmptyAccountID:
+		response.AccountIDRequired(w)
+	case entity.ErrEmptyAction:
+		response.BadRequest(w, err.Error())
+	default:
+		response.InternalError(w, "internal server error")
+	}
+}
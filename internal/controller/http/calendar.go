@@ -0,0 +1,227 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+	"github.com/vadim/neo-metric/internal/domain/publication/policy"
+	"github.com/vadim/neo-metric/internal/httpx/response"
+)
+
+// calendarSummaryLength is the maximum length in runes of an event title taken from the caption
+const calendarSummaryLength = 60
+
+// calendarStatuses are the publications put on the content calendar
+var calendarStatuses = []entity.PublicationStatus{
+	entity.PublicationStatusScheduled,
+	entity.PublicationStatusPublished,
+}
+
+// CalendarEvent is a publication placed on the content calendar
+type CalendarEvent struct {
+	PublicationID string                   `json:"publication_id"`
+	Type          entity.PublicationType   `json:"type"`
+	Status        entity.PublicationStatus `json:"status"`
+	Summary       string                   `json:"summary"`
+	Caption       string                   `json:"caption"`
+	Start         time.Time                `json:"start"` // Scheduled time, or publish time once published
+	UpdatedAt     time.Time                `json:"updated_at"`
+}
+
+// CalendarResponse represents the JSON content calendar
+type CalendarResponse struct {
+	AccountID string          `json:"account_id"`
+	Events    []CalendarEvent `json:"events"`
+}
+
+// Calendar handles GET /publications/calendar?account_id=...&format=ics|json
+// Returns the account's scheduled and published publications as an iCalendar feed
+// (the default) or as JSON, so calendar tools can subscribe to the content plan.
+func (h *PublicationHandler) Calendar() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID := r.URL.Query().Get("account_id")
+		if accountID == "" {
+			response.AccountIDRequired(w)
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "ics"
+		}
+		if format != "ics" && format != "json" {
+			response.BadRequest(w, "invalid format, use ics or json")
+			return
+		}
+
+		var events []CalendarEvent
+		for _, status := range calendarStatuses {
+			pubs, err := h.listAll(r, accountID, status)
+			if err != nil {
+				handleDomainError(w, err)
+				return
+			}
+			for _, pub := range pubs {
+				if ev, ok := calendarEvent(pub); ok {
+					events = append(events, ev)
+				}
+			}
+		}
+		sort.SliceStable(events, func(i, j int) bool { return events[i].Start.Before(events[j].Start) })
+
+		filename := "publications-" + accountID + "." + format
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+		if format == "json" {
+			if events == nil {
+				events = []CalendarEvent{}
+			}
+			response.OK(w, CalendarResponse{AccountID: accountID, Events: events})
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_ = writeICS(w, events, time.Now()) // Fails only if the client has gone away
+	}
+}
+
+// listAll reads every page of the account's publications with the given status
+func (h *PublicationHandler) listAll(r *http.Request, accountID string, status entity.PublicationStatus) ([]entity.Publication, error) {
+	var pubs []entity.Publication
+	after := ""
+	for {
+		out, err := h.policy.ListPublications(r.Context(), policy.ListPublicationsInput{
+			AccountID: accountID,
+			Status:    &status,
+			Limit:     h.pages.Max,
+			After:     &after,
+		})
+		if err != nil {
+			return nil, err
+		}
+		pubs = append(pubs, out.Publications...)
+		if out.NextCursor == "" {
+			return pubs, nil
+		}
+		after = out.NextCursor
+	}
+}
+
+// calendarEvent places a publication on the calendar; publications without a time are skipped
+func calendarEvent(pub entity.Publication) (CalendarEvent, bool) {
+	start := pub.ScheduledAt
+	if pub.Status == entity.PublicationStatusPublished && pub.PublishedAt != nil {
+		start = pub.PublishedAt
+	}
+	if start == nil {
+		return CalendarEvent{}, false
+	}
+	return CalendarEvent{
+		PublicationID: pub.ID,
+		Type:          pub.Type,
+		Status:        pub.Status,
+		Summary:       calendarSummary(pub),
+		Caption:       pub.Caption,
+		Start:         start.UTC(),
+		UpdatedAt:     pub.UpdatedAt.UTC(),
+	}, true
+}
+
+// calendarSummary titles an event with the first line of the caption, or the publication type
+func calendarSummary(pub entity.Publication) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(pub.Caption), "\n")
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return "Instagram " + string(pub.Type)
+	}
+	if utf8.RuneCountInString(line) > calendarSummaryLength {
+		line = string([]rune(line)[:calendarSummaryLength-1]) + "…"
+	}
+	return line
+}
+
+// icsTimeLayout is the UTC date-time format of iCalendar (RFC 5545)
+const icsTimeLayout = "20060102T150405Z"
+
+// writeICS encodes the events as an iCalendar feed
+func writeICS(w io.Writer, events []CalendarEvent, now time.Time) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//neo-metric//Content Calendar//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+	}
+	for _, ev := range events {
+		status := "TENTATIVE"
+		if ev.Status == entity.PublicationStatusPublished {
+			status = "CONFIRMED"
+		}
+		stamp := ev.UpdatedAt
+		if stamp.IsZero() {
+			stamp = now
+		}
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+ev.PublicationID+"@neo-metric",
+			"DTSTAMP:"+stamp.UTC().Format(icsTimeLayout),
+			"DTSTART:"+ev.Start.UTC().Format(icsTimeLayout),
+			"SUMMARY:"+escapeICSText(ev.Summary),
+			"DESCRIPTION:"+escapeICSText(ev.Caption),
+			"STATUS:"+status,
+			"CATEGORIES:"+escapeICSText(string(ev.Type)),
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(foldICSLine(line))
+		b.WriteString("\r\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// escapeICSText escapes a TEXT value as required by RFC 5545
+func escapeICSText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+// foldICSLine splits a content line into lines of at most 75 octets, continued with a
+// leading space, without breaking a UTF-8 sequence
+func foldICSLine(line string) string {
+	const maxOctets = 75
+	if len(line) <= maxOctets {
+		return line
+	}
+	var b strings.Builder
+	limit := maxOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = maxOctets - 1 // The leading space counts towards the continuation line
+	}
+	b.WriteString(line)
+	return b.String()
+}
@@ -0,0 +1,166 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	pubEntity "github.com/vadim/neo-metric/internal/domain/publication/entity"
+	"github.com/vadim/neo-metric/internal/domain/publication/policy"
+)
+
+// calendarPolicy lists fixed publications by status, one publication per page
+type calendarPolicy struct {
+	PublicationPolicy
+	pubs []pubEntity.Publication
+}
+
+func (p *calendarPolicy) ListPublications(_ context.Context, in policy.ListPublicationsInput) (*policy.ListPublicationsOutput, error) {
+	var matched []pubEntity.Publication
+	for _, pub := range p.pubs {
+		if pub.AccountID == in.AccountID && pub.Status == *in.Status {
+			matched = append(matched, pub)
+		}
+	}
+	// The cursor is the ID of the previous page's publication
+	for len(matched) > 0 && *in.After != "" && matched[0].ID != *in.After {
+		matched = matched[1:]
+	}
+	if *in.After != "" && len(matched) > 0 {
+		matched = matched[1:]
+	}
+	if len(matched) == 0 {
+		return &policy.ListPublicationsOutput{}, nil
+	}
+	out := &policy.ListPublicationsOutput{Publications: matched[:1]}
+	if len(matched) > 1 {
+		out.NextCursor = matched[0].ID
+	}
+	return out, nil
+}
+
+func getCalendar(t *testing.T, p PublicationPolicy, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := chi.NewRouter()
+	NewPublicationHandler(p).RegisterRoutes(r)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/publications/calendar?"+query, nil))
+	return rec
+}
+
+func newCalendarPolicy() *calendarPolicy {
+	at := func(day int) *time.Time {
+		t := time.Date(2025, 7, day, 9, 30, 0, 0, time.UTC)
+		return &t
+	}
+	return &calendarPolicy{pubs: []pubEntity.Publication{
+		{ID: "p1", AccountID: "acc", Type: pubEntity.PublicationTypePost, Status: pubEntity.PublicationStatusScheduled,
+			Caption: "Summer sale; 20% off, all week\nDetails in bio", ScheduledAt: at(10)},
+		{ID: "p2", AccountID: "acc", Type: pubEntity.PublicationTypeReel, Status: pubEntity.PublicationStatusScheduled,
+			ScheduledAt: at(3)},
+		{ID: "p3", AccountID: "acc", Type: pubEntity.PublicationTypePost, Status: pubEntity.PublicationStatusPublished,
+			Caption: "Launch day", ScheduledAt: at(1), PublishedAt: at(2)},
+		{ID: "p4", AccountID: "acc", Type: pubEntity.PublicationTypePost, Status: pubEntity.PublicationStatusDraft,
+			Caption: "Not planned yet", ScheduledAt: at(5)},
+		{ID: "p5", AccountID: "other", Type: pubEntity.PublicationTypePost, Status: pubEntity.PublicationStatusScheduled,
+			Caption: "Other account", ScheduledAt: at(6)},
+	}}
+}
+
+func TestCalendarICS(t *testing.T) {
+	rec := getCalendar(t, newCalendarPolicy(), "account_id=acc")
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != "text/calendar; charset=utf-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="publications-acc.ics"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+
+	body := rec.Body.String()
+	if !strings.HasPrefix(body, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n") || !strings.HasSuffix(body, "END:VCALENDAR\r\n") {
+		t.Errorf("body is not a CRLF-delimited calendar:\n%s", body)
+	}
+	if n := strings.Count(body, "BEGIN:VEVENT"); n != 3 {
+		t.Errorf("got %d events, want 3 (scheduled and published of acc):\n%s", n, body)
+	}
+	for _, want := range []string{
+		"UID:p1@neo-metric\r\n",
+		"DTSTART:20250710T093000Z\r\n",
+		`SUMMARY:Summer sale\; 20% off\, all week` + "\r\n",
+		`DESCRIPTION:Summer sale\; 20% off\, all week\nDetails in bio` + "\r\n",
+		"STATUS:TENTATIVE\r\n",
+		"SUMMARY:Instagram reel\r\n",
+		"UID:p3@neo-metric\r\nDTSTAMP:",
+		"DTSTART:20250702T093000Z\r\n", // Published at, not scheduled at
+		"STATUS:CONFIRMED\r\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("calendar does not contain %q:\n%s", want, body)
+		}
+	}
+	for _, unwanted := range []string{"p4@", "p5@"} {
+		if strings.Contains(body, unwanted) {
+			t.Errorf("calendar contains %q:\n%s", unwanted, body)
+		}
+	}
+	// Events are ordered by time
+	if strings.Index(body, "UID:p3@") > strings.Index(body, "UID:p2@") || strings.Index(body, "UID:p2@") > strings.Index(body, "UID:p1@") {
+		t.Errorf("events are not in time order:\n%s", body)
+	}
+}
+
+func TestCalendarJSON(t *testing.T) {
+	rec := getCalendar(t, newCalendarPolicy(), "account_id=acc&format=json")
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="publications-acc.json"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+	var resp CalendarResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	var ids []string
+	for _, ev := range resp.Events {
+		ids = append(ids, ev.PublicationID)
+	}
+	if strings.Join(ids, ",") != "p3,p2,p1" {
+		t.Errorf("events = %v, want [p3 p2 p1]", ids)
+	}
+}
+
+func TestCalendarRejectsUnknownFormat(t *testing.T) {
+	if rec := getCalendar(t, newCalendarPolicy(), "account_id=acc&format=csv"); rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+	if rec := getCalendar(t, newCalendarPolicy(), "format=ics"); rec.Code != http.StatusBadRequest {
+		t.Errorf("missing account_id: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestFoldICSLine(t *testing.T) {
+	line := "DESCRIPTION:" + strings.Repeat("привет ", 20)
+	folded := foldICSLine(line)
+
+	for _, part := range strings.Split(folded, "\r\n") {
+		if len(part) > 75 {
+			t.Errorf("line of %d octets exceeds 75: %q", len(part), part)
+		}
+	}
+	if unfolded := strings.ReplaceAll(folded, "\r\n ", ""); unfolded != line {
+		t.Errorf("unfolded = %q, want %q", unfolded, line)
+	}
+}
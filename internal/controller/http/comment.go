@@ -2,37 +2,52 @@ package http
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/vadim/neo-metric/internal/domain/comment/entity"
 	"github.com/vadim/neo-metric/internal/domain/comment/policy"
+	"github.com/vadim/neo-metric/internal/domain/comment/service"
 	"github.com/vadim/neo-metric/internal/httpx/response"
+	"github.com/vadim/neo-metric/internal/mention"
 )
 
 // CommentPolicy defines the interface for comment operations
 type CommentPolicy interface {
 	GetComments(ctx context.Context, in policy.GetCommentsInput) (*policy.GetCommentsOutput, error)
+	GetComment(ctx context.Context, in policy.GetCommentInput) (*entity.Comment, error)
 	GetReplies(ctx context.Context, in policy.GetRepliesInput) (*policy.GetCommentsOutput, error)
+	GetCommentTree(ctx context.Context, in policy.GetCommentTreeInput) (*policy.GetCommentsOutput, error)
 	CreateComment(ctx context.Context, in policy.CreateCommentInput) (*policy.CreateCommentOutput, error)
 	Reply(ctx context.Context, in policy.ReplyInput) (*policy.ReplyOutput, error)
 	Delete(ctx context.Context, in policy.DeleteInput) error
 	Hide(ctx context.Context, in policy.HideInput) error
+	BulkHide(ctx context.Context, in policy.BulkInput) ([]policy.BulkResult, error)
+	BulkDelete(ctx context.Context, in policy.BulkInput) ([]policy.BulkResult, error)
 	GetStatistics(ctx context.Context, in policy.GetStatisticsInput) (*entity.CommentStatistics, error)
-	SyncComments(ctx context.Context, in policy.SyncCommentsInput) error
+	GetRecentComments(ctx context.Context, in policy.GetRecentCommentsInput) (*service.GetRecentCommentsOutput, error)
+	SyncComments(ctx context.Context, in policy.SyncCommentsInput) (*policy.SyncCommentsOutput, error)
 }
 
 // CommentHandler handles HTTP requests for comments
 type CommentHandler struct {
 	policy CommentPolicy
+	pages  response.PageLimits
 }
 
 // NewCommentHandler creates a new comment handler
 func NewCommentHandler(p CommentPolicy) *CommentHandler {
-	return &CommentHandler{policy: p}
+	return &CommentHandler{policy: p, pages: response.DefaultPageLimits}
+}
+
+// WithPageLimits sets the page sizes of comment listings
+func (h *CommentHandler) WithPageLimits(l response.PageLimits) *CommentHandler {
+	h.pages = l
+	return h
 }
 
 // RegisterRoutes registers comment routes
@@ -41,12 +56,28 @@ func (h *CommentHandler) RegisterRoutes(r chi.Router) {
 		// Get comments for a media
 		r.Get("/media/{mediaId}", h.GetComments())
 
+		// Get comments with replies nested inline
+		r.Get("/media/{mediaId}/tree", h.GetCommentTree())
+
 		// Sync comments for a media
 		r.Post("/media/{mediaId}/sync", h.SyncComments())
 
 		// Get statistics
 		r.Get("/statistics", h.GetStatistics())
 
+		// Newest comments across all of an account's posts
+		r.Get("/recent", h.GetRecentComments())
+
+		// Newest comments mentioning a user
+		r.Get("/mentions", h.GetMentions())
+
+		// Bulk moderation
+		r.Post("/bulk-hide", h.BulkHide())
+		r.Post("/bulk-delete", h.BulkDelete())
+
+		// Get a single comment
+		r.Get("/{commentId}", h.GetComment())
+
 		// Get replies to a comment
 		r.Get("/{commentId}/replies", h.GetReplies())
 
@@ -78,33 +109,74 @@ func (h *CommentHandler) GetComments() http.HandlerFunc {
 		accountID := r.URL.Query().Get("account_id")
 
 		if accountID == "" {
-			response.BadRequest(w, "account_id is required")
+			response.AccountIDRequired(w)
 			return
 		}
 
-		limit := 50
-		if l := r.URL.Query().Get("limit"); l != "" {
-			if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
-				limit = parsed
-				if limit > 100 {
-					limit = 100
-				}
-			}
+		limit, err := h.pages.ParseLimit(r)
+		if err != nil {
+			response.BadRequest(w, err.Error())
+			return
 		}
 
 		after := r.URL.Query().Get("after")
 
+		sentiment := entity.Sentiment(r.URL.Query().Get("sentiment"))
+		if sentiment != "" && !sentiment.IsValid() {
+			handleCommentError(w, entity.ErrInvalidSentiment)
+			return
+		}
+
+		var hidden *bool
+		if v := r.URL.Query().Get("hidden"); v != "" {
+			hb, err := strconv.ParseBool(v)
+			if err != nil {
+				response.BadRequest(w, "invalid hidden")
+				return
+			}
+			hidden = &hb
+		}
+
+		since, err := parseTimeQuery(r, "since")
+		if err != nil {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		until, err := parseTimeQuery(r, "until")
+		if err != nil {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		if since != nil && until != nil && since.After(*until) {
+			response.BadRequest(w, "since must not be after until")
+			return
+		}
+
 		result, err := h.policy.GetComments(r.Context(), policy.GetCommentsInput{
 			AccountID: accountID,
 			MediaID:   mediaID,
 			Limit:     limit,
 			After:     after,
+			Sentiment: sentiment,
+			Hidden:    hidden,
+			AuthorID:  r.URL.Query().Get("author_id"),
+			Since:     since,
+			Until:     until,
 		})
 		if err != nil {
 			handleCommentError(w, err)
 			return
 		}
 
+		if response.WantsEnvelope(r) {
+			response.Paginated(w, result.Comments, response.Pagination{
+				Limit:      limit,
+				NextCursor: result.NextCursor,
+				HasMore:    result.HasMore,
+			})
+			return
+		}
+
 		response.OK(w, GetCommentsResponse{
 			Comments:   result.Comments,
 			NextCursor: result.NextCursor,
@@ -113,27 +185,115 @@ func (h *CommentHandler) GetComments() http.HandlerFunc {
 	}
 }
 
-// GetReplies handles GET /comments/{commentId}/replies
-func (h *CommentHandler) GetReplies() http.HandlerFunc {
+// GetComment handles GET /comments/{commentId}
+func (h *CommentHandler) GetComment() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		commentID := chi.URLParam(r, "commentId")
 		accountID := r.URL.Query().Get("account_id")
 
 		if accountID == "" {
-			response.BadRequest(w, "account_id is required")
+			response.AccountIDRequired(w)
 			return
 		}
 
-		limit := 50
-		if l := r.URL.Query().Get("limit"); l != "" {
-			if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
-				limit = parsed
-				if limit > 100 {
-					limit = 100
+		comment, err := h.policy.GetComment(r.Context(), policy.GetCommentInput{
+			AccountID: accountID,
+			CommentID: commentID,
+		})
+		if err != nil {
+			handleCommentError(w, err)
+			return
+		}
+
+		response.OK(w, comment)
+	}
+}
+
+// GetCommentTree handles GET /comments/media/{mediaId}/tree
+func (h *CommentHandler) GetCommentTree() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		mediaID := chi.URLParam(r, "mediaId")
+		accountID := r.URL.Query().Get("account_id")
+
+		if accountID == "" {
+			response.AccountIDRequired(w)
+			return
+		}
+
+		limit, err := h.pages.ParseLimit(r)
+		if err != nil {
+			response.BadRequest(w, err.Error())
+			return
+		}
+
+		// Zero is reserved for the default depth, so an explicit zero is rejected
+		var depth int
+		if d := r.URL.Query().Get("depth"); d != "" {
+			parsed, err := strconv.Atoi(d)
+			if err != nil || parsed == 0 {
+				handleCommentError(w, entity.ErrInvalidTreeDepth)
+				return
+			}
+			depth = parsed
+		}
+
+		var maxReplies int
+		if m := r.URL.Query().Get("max_replies"); m != "" {
+			if parsed, err := strconv.Atoi(m); err == nil && parsed > 0 {
+				maxReplies = parsed
+				if maxReplies > 100 {
+					maxReplies = 100
 				}
 			}
 		}
 
+		result, err := h.policy.GetCommentTree(r.Context(), policy.GetCommentTreeInput{
+			AccountID:  accountID,
+			MediaID:    mediaID,
+			Limit:      limit,
+			After:      r.URL.Query().Get("after"),
+			Depth:      depth,
+			MaxReplies: maxReplies,
+		})
+		if err != nil {
+			handleCommentError(w, err)
+			return
+		}
+
+		if response.WantsEnvelope(r) {
+			response.Paginated(w, result.Comments, response.Pagination{
+				Limit:      limit,
+				NextCursor: result.NextCursor,
+				HasMore:    result.HasMore,
+			})
+			return
+		}
+
+		response.OK(w, GetCommentsResponse{
+			Comments:   result.Comments,
+			NextCursor: result.NextCursor,
+			HasMore:    result.HasMore,
+		})
+	}
+}
+
+// GetReplies handles GET /comments/{commentId}/replies
+func (h *CommentHandler) GetReplies() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		commentID := chi.URLParam(r, "commentId")
+		accountID := r.URL.Query().Get("account_id")
+
+		if accountID == "" {
+			response.AccountIDRequired(w)
+			return
+		}
+
+		limit, err := h.pages.ParseLimit(r)
+		if err != nil {
+			response.BadRequest(w, err.Error())
+			return
+		}
+
 		after := r.URL.Query().Get("after")
 
 		result, err := h.policy.GetReplies(r.Context(), policy.GetRepliesInput{
@@ -147,6 +307,15 @@ func (h *CommentHandler) GetReplies() http.HandlerFunc {
 			return
 		}
 
+		if response.WantsEnvelope(r) {
+			response.Paginated(w, result.Comments, response.Pagination{
+				Limit:      limit,
+				NextCursor: result.NextCursor,
+				HasMore:    result.HasMore,
+			})
+			return
+		}
+
 		response.OK(w, GetCommentsResponse{
 			Comments:   result.Comments,
 			NextCursor: result.NextCursor,
@@ -172,13 +341,12 @@ func (h *CommentHandler) CreateComment() http.HandlerFunc {
 		mediaID := chi.URLParam(r, "mediaId")
 
 		var req CreateCommentRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			response.BadRequest(w, "invalid JSON")
+		if !decodeJSON(w, r, &req) {
 			return
 		}
 
 		if req.AccountID == "" {
-			response.BadRequest(w, "account_id is required")
+			response.AccountIDRequired(w)
 			return
 		}
 		if req.Message == "" {
@@ -220,13 +388,12 @@ func (h *CommentHandler) Reply() http.HandlerFunc {
 		commentID := chi.URLParam(r, "commentId")
 
 		var req ReplyRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			response.BadRequest(w, "invalid JSON")
+		if !decodeJSON(w, r, &req) {
 			return
 		}
 
 		if req.AccountID == "" {
-			response.BadRequest(w, "account_id is required")
+			response.AccountIDRequired(w)
 			return
 		}
 		if req.Message == "" {
@@ -265,7 +432,7 @@ func (h *CommentHandler) Delete() http.HandlerFunc {
 		accountID := r.URL.Query().Get("account_id")
 
 		if accountID == "" {
-			response.BadRequest(w, "account_id is required")
+			response.AccountIDRequired(w)
 			return
 		}
 
@@ -294,13 +461,12 @@ func (h *CommentHandler) Hide() http.HandlerFunc {
 		commentID := chi.URLParam(r, "commentId")
 
 		var req HideRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			response.BadRequest(w, "invalid JSON")
+		if !decodeJSON(w, r, &req) {
 			return
 		}
 
 		if req.AccountID == "" {
-			response.BadRequest(w, "account_id is required")
+			response.AccountIDRequired(w)
 			return
 		}
 
@@ -318,12 +484,100 @@ func (h *CommentHandler) Hide() http.HandlerFunc {
 	}
 }
 
+// BulkRequest represents the request body for bulk comment actions
+type BulkRequest struct {
+	AccountID  string   `json:"account_id"`
+	CommentIDs []string `json:"comment_ids"`
+	Hide       *bool    `json:"hide,omitempty"` // bulk-hide only, defaults to true
+}
+
+// BulkResponse represents the response for bulk comment actions
+type BulkResponse struct {
+	Results   []policy.BulkResult `json:"results"`
+	Succeeded int                 `json:"succeeded"`
+	Failed    int                 `json:"failed"`
+}
+
+// BulkHide handles POST /comments/bulk-hide
+func (h *CommentHandler) BulkHide() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decodeBulkRequest(w, r)
+		if !ok {
+			return
+		}
+
+		hide := true
+		if req.Hide != nil {
+			hide = *req.Hide
+		}
+
+		results, err := h.policy.BulkHide(r.Context(), policy.BulkInput{
+			AccountID:  req.AccountID,
+			CommentIDs: req.CommentIDs,
+			Hide:       hide,
+		})
+		if err != nil {
+			handleCommentError(w, err)
+			return
+		}
+
+		response.OK(w, newBulkResponse(results))
+	}
+}
+
+// BulkDelete handles POST /comments/bulk-delete
+func (h *CommentHandler) BulkDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decodeBulkRequest(w, r)
+		if !ok {
+			return
+		}
+
+		results, err := h.policy.BulkDelete(r.Context(), policy.BulkInput{
+			AccountID:  req.AccountID,
+			CommentIDs: req.CommentIDs,
+		})
+		if err != nil {
+			handleCommentError(w, err)
+			return
+		}
+
+		response.OK(w, newBulkResponse(results))
+	}
+}
+
+func decodeBulkRequest(w http.ResponseWriter, r *http.Request) (*BulkRequest, bool) {
+	var req BulkRequest
+	if !decodeJSON(w, r, &req) {
+		return nil, false
+	}
+
+	if req.AccountID == "" {
+		response.AccountIDRequired(w)
+		return nil, false
+	}
+
+	return &req, true
+}
+
+func newBulkResponse(results []policy.BulkResult) BulkResponse {
+	resp := BulkResponse{Results: results}
+	for _, res := range results {
+		if res.Success {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+		}
+	}
+	return resp
+}
+
 // GetStatistics handles GET /comments/statistics
 func (h *CommentHandler) GetStatistics() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		accountID := r.URL.Query().Get("account_id")
 		if accountID == "" {
-			response.BadRequest(w, "account_id is required")
+			response.AccountIDRequired(w)
 			return
 		}
 
@@ -337,9 +591,35 @@ func (h *CommentHandler) GetStatistics() http.HandlerFunc {
 			}
 		}
 
+		// Paging through ranked top posts
+		topPostsOffset := 0
+		if o := r.URL.Query().Get("offset"); o != "" {
+			if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+				topPostsOffset = parsed
+			}
+		}
+
+		// Optional date range (all-time when absent)
+		var startDate, endDate *time.Time
+		if s := r.URL.Query().Get("start_date"); s != "" {
+			if parsed, err := time.Parse("2006-01-02", s); err == nil {
+				startDate = &parsed
+			}
+		}
+		if e := r.URL.Query().Get("end_date"); e != "" {
+			if parsed, err := time.Parse("2006-01-02", e); err == nil {
+				endOfDay := parsed.Add(24*time.Hour - time.Second)
+				endDate = &endOfDay
+			}
+		}
+
 		stats, err := h.policy.GetStatistics(r.Context(), policy.GetStatisticsInput{
-			AccountID:     accountID,
-			TopPostsLimit: topPostsLimit,
+			AccountID:      accountID,
+			StartDate:      startDate,
+			EndDate:        endDate,
+			TopPostsSort:   entity.TopPostsSort(r.URL.Query().Get("sort")),
+			TopPostsLimit:  topPostsLimit,
+			TopPostsOffset: topPostsOffset,
 		})
 		if err != nil {
 			handleCommentError(w, err)
@@ -350,29 +630,115 @@ func (h *CommentHandler) GetStatistics() http.HandlerFunc {
 	}
 }
 
-// SyncCommentsRequest represents the request body for syncing comments
-type SyncCommentsRequest struct {
-	AccountID string `json:"account_id"`
+// GetRecentComments handles GET /comments/recent
+func (h *CommentHandler) GetRecentComments() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID := r.URL.Query().Get("account_id")
+		if accountID == "" {
+			response.AccountIDRequired(w)
+			return
+		}
+
+		limit, err := h.pages.ParseLimit(r)
+		if err != nil {
+			response.BadRequest(w, err.Error())
+			return
+		}
+
+		since, err := parseTimeQuery(r, "since")
+		if err != nil {
+			response.BadRequest(w, err.Error())
+			return
+		}
+
+		result, err := h.policy.GetRecentComments(r.Context(), policy.GetRecentCommentsInput{
+			AccountID: accountID,
+			Since:     since,
+			Limit:     limit,
+			After:     r.URL.Query().Get("after"),
+		})
+		if err != nil {
+			handleCommentError(w, err)
+			return
+		}
+
+		if response.WantsEnvelope(r) {
+			response.Paginated(w, result.Comments, response.Pagination{
+				Limit:      limit,
+				NextCursor: result.NextCursor,
+				HasMore:    result.HasMore,
+			})
+			return
+		}
+
+		response.OK(w, result)
+	}
 }
 
-// SyncComments handles POST /comments/media/{mediaId}/sync
-func (h *CommentHandler) SyncComments() http.HandlerFunc {
+// GetMentions handles GET /comments/mentions?account_id=...&username=...
+func (h *CommentHandler) GetMentions() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		mediaID := chi.URLParam(r, "mediaId")
+		accountID := r.URL.Query().Get("account_id")
+		if accountID == "" {
+			response.AccountIDRequired(w)
+			return
+		}
 
-		var req SyncCommentsRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			response.BadRequest(w, "invalid JSON")
+		username := mention.Normalize(r.URL.Query().Get("username"))
+		if username == "" {
+			response.BadRequest(w, "username is required and must be a valid Instagram username")
 			return
 		}
 
-		if req.AccountID == "" {
-			response.BadRequest(w, "account_id is required")
+		limit, err := h.pages.ParseLimit(r)
+		if err != nil {
+			response.BadRequest(w, err.Error())
 			return
 		}
 
-		err := h.policy.SyncComments(r.Context(), policy.SyncCommentsInput{
-			AccountID: req.AccountID,
+		since, err := parseTimeQuery(r, "since")
+		if err != nil {
+			response.BadRequest(w, err.Error())
+			return
+		}
+
+		result, err := h.policy.GetRecentComments(r.Context(), policy.GetRecentCommentsInput{
+			AccountID: accountID,
+			Since:     since,
+			Mention:   username,
+			Limit:     limit,
+			After:     r.URL.Query().Get("after"),
+		})
+		if err != nil {
+			handleCommentError(w, err)
+			return
+		}
+
+		if response.WantsEnvelope(r) {
+			response.Paginated(w, result.Comments, response.Pagination{
+				Limit:      limit,
+				NextCursor: result.NextCursor,
+				HasMore:    result.HasMore,
+			})
+			return
+		}
+
+		response.OK(w, result)
+	}
+}
+
+// SyncComments handles POST /comments/media/{mediaId}/sync?account_id=...
+func (h *CommentHandler) SyncComments() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		mediaID := chi.URLParam(r, "mediaId")
+
+		accountID, ok := syncAccountID(w, r)
+		if !ok {
+			return
+		}
+
+		out, err := h.policy.SyncComments(r.Context(), policy.SyncCommentsInput{
+			AccountID: accountID,
 			MediaID:   mediaID,
 		})
 		if err != nil {
@@ -380,22 +746,57 @@ func (h *CommentHandler) SyncComments() http.HandlerFunc {
 			return
 		}
 
-		response.OK(w, map[string]string{"status": "synced"})
+		response.OK(w, out)
+	}
+}
+
+// commentErrorCodes maps comment domain errors to their API error codes
+var commentErrorCodes = map[error]response.Code{
+	entity.ErrCommentNotFound:     response.CodeCommentNotFound,
+	entity.ErrMediaNotFound:       response.CodeMediaNotFound,
+	entity.ErrEmptyReplyText:      response.CodeEmptyReplyText,
+	entity.ErrReplyTextTooLong:    response.CodeReplyTextTooLong,
+	entity.ErrNoCommentIDs:        response.CodeNoCommentIDs,
+	entity.ErrTooManyCommentIDs:   response.CodeTooManyCommentIDs,
+	entity.ErrInvalidSentiment:    response.CodeInvalidSentiment,
+	entity.ErrInvalidTopPostsSort: response.CodeInvalidTopPostsSort,
+	entity.ErrInvalidTreeDepth:    response.CodeInvalidTreeDepth,
+	entity.ErrUnauthorized:        response.CodeUnauthorized,
+	entity.ErrAccountForbidden:    response.CodeForbidden,
+	entity.ErrCommentingDisabled:  response.CodeCommentingDisabled,
+	entity.ErrSyncInProgress:      response.CodeSyncInProgress,
+}
+
+// parseTimeQuery parses an optional RFC3339 query parameter
+func parseTimeQuery(r *http.Request, name string) (*time.Time, error) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s format, use RFC3339", name)
 	}
+	return &t, nil
 }
 
 func handleCommentError(w http.ResponseWriter, err error) {
+	code := commentErrorCodes[err]
 	switch err {
 	case entity.ErrCommentNotFound:
-		response.NotFound(w, err.Error())
+		response.CodedError(w, http.StatusNotFound, code, err.Error())
 	case entity.ErrMediaNotFound:
-		response.NotFound(w, err.Error())
-	case entity.ErrEmptyReplyText, entity.ErrReplyTextTooLong:
-		response.BadRequest(w, err.Error())
+		response.CodedError(w, http.StatusNotFound, code, err.Error())
+	case entity.ErrEmptyReplyText, entity.ErrReplyTextTooLong,
+		entity.ErrNoCommentIDs, entity.ErrTooManyCommentIDs, entity.ErrInvalidSentiment,
+		entity.ErrInvalidTopPostsSort, entity.ErrInvalidTreeDepth:
+		response.CodedError(w, http.StatusBadRequest, code, err.Error())
 	case entity.ErrUnauthorized:
-		response.Unauthorized(w, err.Error())
-	case entity.ErrCommentingDisabled:
-		response.Error(w, http.StatusForbidden, err.Error())
+		response.CodedError(w, http.StatusUnauthorized, code, err.Error())
+	case entity.ErrAccountForbidden, entity.ErrCommentingDisabled:
+		response.CodedError(w, http.StatusForbidden, code, err.Error())
+	case entity.ErrSyncInProgress:
+		response.CodedError(w, http.StatusConflict, code, err.Error())
 	default:
 		response.InternalError(w, "internal server error")
 	}
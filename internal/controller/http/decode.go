@@ -0,0 +1,65 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/vadim/neo-metric/internal/httpx/response"
+)
+
+// decodeJSON decodes the request body into dst, rejecting unknown fields.
+// On failure it writes a 400 response with a specific message and returns false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(dst)
+	if err == nil {
+		return true
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		response.CodedError(w, http.StatusBadRequest, response.CodeInvalidJSON, fmt.Sprintf("request body exceeds %d bytes", maxBytesErr.Limit))
+	case errors.Is(err, io.EOF):
+		response.CodedError(w, http.StatusBadRequest, response.CodeInvalidJSON, "request body is empty")
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		response.CodedError(w, http.StatusBadRequest, response.CodeInvalidJSON, "invalid JSON")
+	case errors.As(err, &typeErr):
+		response.CodedError(w, http.StatusBadRequest, response.CodeInvalidJSON, fmt.Sprintf("invalid value for field %q", typeErr.Field))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		response.CodedError(w, http.StatusBadRequest, response.CodeInvalidJSON, strings.TrimPrefix(err.Error(), "json: "))
+	default:
+		response.CodedError(w, http.StatusBadRequest, response.CodeInvalidJSON, "invalid JSON")
+	}
+	return false
+}
+
+// syncAccountID reads account_id for the manual sync endpoints from the query,
+// falling back to a legacy {"account_id": ...} JSON body. It writes a 400 and
+// returns false when neither is present.
+func syncAccountID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	accountID := r.URL.Query().Get("account_id")
+	if accountID == "" && r.ContentLength != 0 {
+		var req struct {
+			AccountID string `json:"account_id"`
+		}
+		if !decodeJSON(w, r, &req) {
+			return "", false
+		}
+		accountID = req.AccountID
+	}
+
+	if accountID == "" {
+		response.AccountIDRequired(w)
+		return "", false
+	}
+	return accountID, true
+}
@@ -3,8 +3,10 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
-	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -12,6 +14,7 @@ import (
 	"github.com/vadim/neo-metric/internal/domain/direct/entity"
 	"github.com/vadim/neo-metric/internal/domain/direct/policy"
 	"github.com/vadim/neo-metric/internal/httpx/response"
+	"github.com/vadim/neo-metric/internal/httpx/validate"
 )
 
 // DirectPolicy defines the interface for direct message operations
@@ -21,8 +24,17 @@ type DirectPolicy interface {
 	GetMessages(ctx context.Context, in policy.GetMessagesInput) (*policy.GetMessagesOutput, error)
 	SendMessage(ctx context.Context, in policy.SendMessageInput) (*policy.SendMessageOutput, error)
 	SendMediaMessage(ctx context.Context, in policy.SendMediaMessageInput) (*policy.SendMessageOutput, error)
-	SyncConversations(ctx context.Context, in policy.SyncConversationsInput) error
-	SyncMessages(ctx context.Context, in policy.SyncMessagesInput) error
+	SendReaction(ctx context.Context, in policy.SendReactionInput) error
+	GetQuickReplies(ctx context.Context, accountID string) ([]policy.QuickReply, error)
+	AddConversationLabels(ctx context.Context, in policy.LabelsInput) ([]string, error)
+	RemoveConversationLabels(ctx context.Context, in policy.LabelsInput) ([]string, error)
+	DeleteConversation(ctx context.Context, in policy.DeleteConversationInput) error
+	MarkConversationRead(ctx context.Context, in policy.MarkConversationReadInput) (*entity.Conversation, error)
+	ExportParticipant(ctx context.Context, in policy.ParticipantInput, sink policy.ExportSink) error
+	DeleteParticipant(ctx context.Context, in policy.ParticipantInput) (int, error)
+	GetParticipant(ctx context.Context, in policy.ParticipantInput) (*entity.Participant, error)
+	SyncConversations(ctx context.Context, in policy.SyncConversationsInput) (*policy.SyncConversationsOutput, error)
+	SyncMessages(ctx context.Context, in policy.SyncMessagesInput) (*policy.SyncMessagesOutput, error)
 	GetStatistics(ctx context.Context, in policy.GetStatisticsInput) (*entity.Statistics, error)
 	GetHeatmap(ctx context.Context, in policy.GetHeatmapInput) (*entity.Heatmap, error)
 }
@@ -30,11 +42,18 @@ type DirectPolicy interface {
 // DirectHandler handles HTTP requests for direct messages
 type DirectHandler struct {
 	policy DirectPolicy
+	pages  response.PageLimits
 }
 
 // NewDirectHandler creates a new direct message handler
 func NewDirectHandler(p DirectPolicy) *DirectHandler {
-	return &DirectHandler{policy: p}
+	return &DirectHandler{policy: p, pages: response.DefaultPageLimits}
+}
+
+// WithPageLimits sets the page sizes of conversation and message listings
+func (h *DirectHandler) WithPageLimits(l response.PageLimits) *DirectHandler {
+	h.pages = l
+	return h
 }
 
 // RegisterRoutes registers direct message routes
@@ -47,13 +66,32 @@ func (h *DirectHandler) RegisterRoutes(r chi.Router) {
 		r.Get("/conversations/search", h.SearchConversations())
 
 		// Manually sync conversations
-		r.Post("/conversations/sync", h.SyncConversations())
+		r.Post("/sync", h.SyncConversations())
+		r.Post("/conversations/sync", h.SyncConversations()) // legacy path
+
+		// Delete a conversation with its messages
+		r.Delete("/conversations/{conversationId}", h.DeleteConversation())
+
+		// Fetch a participant's profile from Instagram
+		r.Get("/participants/{participantId}", h.GetParticipant())
+
+		// Export / delete everything stored about a participant (data subject requests)
+		r.Get("/participants/{participantId}/export", h.ExportParticipant())
+		r.Delete("/participants/{participantId}", h.DeleteParticipant())
+
+		// Mark a conversation as read
+		r.Post("/conversations/{conversationId}/read", h.MarkConversationRead())
 
 		// Get messages in a conversation
 		r.Get("/conversations/{conversationId}/messages", h.GetMessages())
 
+		// Add / remove conversation labels
+		r.Post("/conversations/{conversationId}/labels", h.AddLabels())
+		r.Delete("/conversations/{conversationId}/labels", h.RemoveLabels())
+
 		// Manually sync messages for a conversation
-		r.Post("/conversations/{conversationId}/messages/sync", h.SyncMessages())
+		r.Post("/conversations/{conversationId}/sync", h.SyncMessages())
+		r.Post("/conversations/{conversationId}/messages/sync", h.SyncMessages()) // legacy path
 
 		// Send text message
 		r.Post("/conversations/{conversationId}/messages", h.SendMessage())
@@ -61,6 +99,13 @@ func (h *DirectHandler) RegisterRoutes(r chi.Router) {
 		// Send media message
 		r.Post("/conversations/{conversationId}/media", h.SendMediaMessage())
 
+		// React to a message / remove reaction
+		r.Post("/conversations/{conversationId}/messages/{messageId}/reaction", h.SendReaction())
+		r.Delete("/conversations/{conversationId}/messages/{messageId}/reaction", h.RemoveReaction())
+
+		// Quick replies derived from DM templates
+		r.Get("/quick-replies", h.GetQuickReplies())
+
 		// Get statistics
 		r.Get("/statistics", h.GetStatistics())
 
@@ -81,29 +126,19 @@ func (h *DirectHandler) GetConversations() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		accountID := r.URL.Query().Get("account_id")
 		if accountID == "" {
-			response.BadRequest(w, "account_id is required")
+			response.AccountIDRequired(w)
 			return
 		}
 
-		limit := 50
-		if l := r.URL.Query().Get("limit"); l != "" {
-			if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
-				limit = parsed
-				if limit > 100 {
-					limit = 100
-				}
-			}
-		}
-
-		offset := 0
-		if o := r.URL.Query().Get("offset"); o != "" {
-			if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
-				offset = parsed
-			}
+		limit, offset, err := h.pages.Parse(r)
+		if err != nil {
+			response.BadRequest(w, err.Error())
+			return
 		}
 
 		result, err := h.policy.GetConversations(r.Context(), policy.GetConversationsInput{
 			AccountID: accountID,
+			Labels:    labelParams(r),
 			Limit:     limit,
 			Offset:    offset,
 		})
@@ -112,6 +147,16 @@ func (h *DirectHandler) GetConversations() http.HandlerFunc {
 			return
 		}
 
+		if response.WantsEnvelope(r) {
+			response.Paginated(w, result.Conversations, response.Pagination{
+				Total:   response.Total(result.Total),
+				Limit:   limit,
+				Offset:  offset,
+				HasMore: result.HasMore,
+			})
+			return
+		}
+
 		response.OK(w, GetConversationsResponse{
 			Conversations: result.Conversations,
 			Total:         result.Total,
@@ -125,7 +170,7 @@ func (h *DirectHandler) SearchConversations() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		accountID := r.URL.Query().Get("account_id")
 		if accountID == "" {
-			response.BadRequest(w, "account_id is required")
+			response.AccountIDRequired(w)
 			return
 		}
 
@@ -135,21 +180,10 @@ func (h *DirectHandler) SearchConversations() http.HandlerFunc {
 			return
 		}
 
-		limit := 50
-		if l := r.URL.Query().Get("limit"); l != "" {
-			if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
-				limit = parsed
-				if limit > 100 {
-					limit = 100
-				}
-			}
-		}
-
-		offset := 0
-		if o := r.URL.Query().Get("offset"); o != "" {
-			if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
-				offset = parsed
-			}
+		limit, offset, err := h.pages.Parse(r)
+		if err != nil {
+			response.BadRequest(w, err.Error())
+			return
 		}
 
 		result, err := h.policy.SearchConversations(r.Context(), policy.SearchConversationsInput{
@@ -163,6 +197,16 @@ func (h *DirectHandler) SearchConversations() http.HandlerFunc {
 			return
 		}
 
+		if response.WantsEnvelope(r) {
+			response.Paginated(w, result.Conversations, response.Pagination{
+				Total:   response.Total(result.Total),
+				Limit:   limit,
+				Offset:  offset,
+				HasMore: result.HasMore,
+			})
+			return
+		}
+
 		response.OK(w, GetConversationsResponse{
 			Conversations: result.Conversations,
 			Total:         result.Total,
@@ -185,25 +229,14 @@ func (h *DirectHandler) GetMessages() http.HandlerFunc {
 		accountID := r.URL.Query().Get("account_id")
 
 		if accountID == "" {
-			response.BadRequest(w, "account_id is required")
+			response.AccountIDRequired(w)
 			return
 		}
 
-		limit := 50
-		if l := r.URL.Query().Get("limit"); l != "" {
-			if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
-				limit = parsed
-				if limit > 100 {
-					limit = 100
-				}
-			}
-		}
-
-		offset := 0
-		if o := r.URL.Query().Get("offset"); o != "" {
-			if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
-				offset = parsed
-			}
+		limit, offset, err := h.pages.Parse(r)
+		if err != nil {
+			response.BadRequest(w, err.Error())
+			return
 		}
 
 		result, err := h.policy.GetMessages(r.Context(), policy.GetMessagesInput{
@@ -217,6 +250,16 @@ func (h *DirectHandler) GetMessages() http.HandlerFunc {
 			return
 		}
 
+		if response.WantsEnvelope(r) {
+			response.Paginated(w, result.Messages, response.Pagination{
+				Total:   response.Total(result.Total),
+				Limit:   limit,
+				Offset:  offset,
+				HasMore: result.HasMore,
+			})
+			return
+		}
+
 		response.OK(w, GetMessagesResponse{
 			Messages: result.Messages,
 			Total:    result.Total,
@@ -229,7 +272,8 @@ func (h *DirectHandler) GetMessages() http.HandlerFunc {
 type SendMessageRequest struct {
 	AccountID   string `json:"account_id"`
 	RecipientID string `json:"recipient_id"`
-	Message     string `json:"message"`
+	Message     string `json:"message,omitempty"`
+	TemplateID  string `json:"template_id,omitempty"` // Send a DM template instead of message
 }
 
 // SendMessageResponse represents the response for sending a message
@@ -243,21 +287,20 @@ func (h *DirectHandler) SendMessage() http.HandlerFunc {
 		conversationID := chi.URLParam(r, "conversationId")
 
 		var req SendMessageRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			response.BadRequest(w, "invalid JSON")
+		if !decodeJSON(w, r, &req) {
 			return
 		}
 
-		if req.AccountID == "" {
-			response.BadRequest(w, "account_id is required")
-			return
+		var errs validate.Errors
+		errs.Required("account_id", req.AccountID != "")
+		errs.Required("recipient_id", req.RecipientID != "")
+		if req.Message == "" && req.TemplateID == "" {
+			errs.Add("message", response.CodeRequired, "message or template_id is required")
 		}
-		if req.RecipientID == "" {
-			response.BadRequest(w, "recipient_id is required")
-			return
+		if req.Message != "" && req.TemplateID != "" {
+			errs.Invalid("template_id", "message and template_id are mutually exclusive")
 		}
-		if req.Message == "" {
-			response.BadRequest(w, "message is required")
+		if errs.Respond(w) {
 			return
 		}
 
@@ -266,6 +309,7 @@ func (h *DirectHandler) SendMessage() http.HandlerFunc {
 			ConversationID: conversationID,
 			RecipientID:    req.RecipientID,
 			Message:        req.Message,
+			TemplateID:     req.TemplateID,
 		})
 		if err != nil {
 			handleDirectError(w, err)
@@ -282,6 +326,7 @@ type SendMediaMessageRequest struct {
 	RecipientID string `json:"recipient_id"`
 	MediaURL    string `json:"media_url"`
 	MediaType   string `json:"media_type"` // image, video, audio
+	Caption     string `json:"caption"`    // Optional text sent in the same message (image and video only)
 }
 
 // SendMediaMessage handles POST /direct/conversations/{conversationId}/media
@@ -290,25 +335,16 @@ func (h *DirectHandler) SendMediaMessage() http.HandlerFunc {
 		conversationID := chi.URLParam(r, "conversationId")
 
 		var req SendMediaMessageRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			response.BadRequest(w, "invalid JSON")
+		if !decodeJSON(w, r, &req) {
 			return
 		}
 
-		if req.AccountID == "" {
-			response.BadRequest(w, "account_id is required")
-			return
-		}
-		if req.RecipientID == "" {
-			response.BadRequest(w, "recipient_id is required")
-			return
-		}
-		if req.MediaURL == "" {
-			response.BadRequest(w, "media_url is required")
-			return
-		}
-		if req.MediaType == "" {
-			response.BadRequest(w, "media_type is required")
+		var errs validate.Errors
+		errs.Required("account_id", req.AccountID != "")
+		errs.Required("recipient_id", req.RecipientID != "")
+		errs.Required("media_url", req.MediaURL != "")
+		errs.Required("media_type", req.MediaType != "")
+		if errs.Respond(w) {
 			return
 		}
 
@@ -318,6 +354,7 @@ func (h *DirectHandler) SendMediaMessage() http.HandlerFunc {
 			RecipientID:    req.RecipientID,
 			MediaURL:       req.MediaURL,
 			MediaType:      req.MediaType,
+			Caption:        req.Caption,
 		})
 		if err != nil {
 			handleDirectError(w, err)
@@ -328,68 +365,462 @@ func (h *DirectHandler) SendMediaMessage() http.HandlerFunc {
 	}
 }
 
-// SyncConversationsRequest represents the request body for syncing conversations
-type SyncConversationsRequest struct {
-	AccountID string `json:"account_id"`
+// labelParams collects ?label= values; both repeated params and comma-separated lists are accepted
+func labelParams(r *http.Request) []string {
+	var labels []string
+	for _, v := range r.URL.Query()["label"] {
+		for _, l := range strings.Split(v, ",") {
+			if l = strings.TrimSpace(l); l != "" {
+				labels = append(labels, l)
+			}
+		}
+	}
+	return labels
 }
 
-// SyncConversations handles POST /direct/conversations/sync
-func (h *DirectHandler) SyncConversations() http.HandlerFunc {
+// DeleteConversation handles DELETE /direct/conversations/{conversationId}?account_id=...
+func (h *DirectHandler) DeleteConversation() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conversationID := chi.URLParam(r, "conversationId")
+
+		accountID := r.URL.Query().Get("account_id")
+		if accountID == "" {
+			response.AccountIDRequired(w)
+			return
+		}
+
+		err := h.policy.DeleteConversation(r.Context(), policy.DeleteConversationInput{
+			AccountID:      accountID,
+			ConversationID: conversationID,
+		})
+		if err != nil {
+			handleDirectError(w, err)
+			return
+		}
+
+		response.NoContent(w)
+	}
+}
+
+// MarkConversationRead handles POST /direct/conversations/{conversationId}/read?account_id=...
+func (h *DirectHandler) MarkConversationRead() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conversationID := chi.URLParam(r, "conversationId")
+
+		accountID := r.URL.Query().Get("account_id")
+		if accountID == "" {
+			response.AccountIDRequired(w)
+			return
+		}
+
+		conv, err := h.policy.MarkConversationRead(r.Context(), policy.MarkConversationReadInput{
+			AccountID:      accountID,
+			ConversationID: conversationID,
+		})
+		if err != nil {
+			handleDirectError(w, err)
+			return
+		}
+
+		response.OK(w, conv)
+	}
+}
+
+// GetParticipant handles GET /direct/participants/{participantId}?account_id=...
+func (h *DirectHandler) GetParticipant() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var req SyncConversationsRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			response.BadRequest(w, "invalid JSON")
+		participantID := chi.URLParam(r, "participantId")
+
+		accountID := r.URL.Query().Get("account_id")
+		if accountID == "" {
+			response.AccountIDRequired(w)
+			return
+		}
+
+		participant, err := h.policy.GetParticipant(r.Context(), policy.ParticipantInput{
+			AccountID:     accountID,
+			ParticipantID: participantID,
+		})
+		if err != nil {
+			handleDirectError(w, err)
+			return
+		}
+
+		response.OK(w, participant)
+	}
+}
+
+// ExportParticipant handles GET /direct/participants/{participantId}/export?account_id=...
+// The bundle is streamed one page of messages at a time, so the response is only
+// complete JSON if the export finishes; a failure midway truncates it.
+func (h *DirectHandler) ExportParticipant() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		participantID := chi.URLParam(r, "participantId")
+
+		accountID := r.URL.Query().Get("account_id")
+		if accountID == "" {
+			response.AccountIDRequired(w)
+			return
+		}
+
+		ew := &exportWriter{
+			w:             w,
+			rc:            http.NewResponseController(w),
+			accountID:     accountID,
+			participantID: participantID,
+		}
+		err := h.policy.ExportParticipant(r.Context(), policy.ParticipantInput{
+			AccountID:     accountID,
+			ParticipantID: participantID,
+		}, ew)
+		if err != nil {
+			// Once streaming has started the status is sent; leaving the bundle unterminated
+			// is the only way left to tell the client the export is incomplete
+			if !ew.started {
+				handleDirectError(w, err)
+			}
+			return
+		}
+
+		_ = ew.finish() // Fails only if the client has gone away
+	}
+}
+
+// exportWriter encodes a participant export as a JSON bundle while it is read:
+// {"account_id", "participant_id", "exported_at", "conversations": [{"conversation", "messages"}]}
+type exportWriter struct {
+	w             http.ResponseWriter
+	rc            *http.ResponseController
+	accountID     string
+	participantID string
+
+	started  bool // Header and opening of the bundle written
+	convs    int  // Conversations written so far
+	messages int  // Messages written for the current conversation
+}
+
+func (e *exportWriter) start() error {
+	if e.started {
+		return nil
+	}
+	e.started = true
+
+	e.w.Header().Set("Content-Type", "application/json")
+	e.w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="participant-%s.json"`, e.participantID))
+	e.w.WriteHeader(http.StatusOK)
+
+	head, err := json.Marshal(map[string]any{
+		"account_id":     e.accountID,
+		"participant_id": e.participantID,
+		"exported_at":    time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	// Reopen the header object to append the conversations array
+	_, err = fmt.Fprintf(e.w, `%s,"conversations":[`, head[:len(head)-1])
+	return err
+}
+
+// Conversation implements policy.ExportSink
+func (e *exportWriter) Conversation(conv *entity.Conversation) error {
+	if err := e.start(); err != nil {
+		return err
+	}
+
+	sep := ""
+	if e.convs > 0 {
+		sep = "]},"
+	}
+	data, err := json.Marshal(conv)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(e.w, `%s{"conversation":%s,"messages":[`, sep, data); err != nil {
+		return err
+	}
+
+	e.convs++
+	e.messages = 0
+	return nil
+}
+
+// Messages implements policy.ExportSink
+func (e *exportWriter) Messages(msgs []entity.Message) error {
+	for i := range msgs {
+		data, err := json.Marshal(&msgs[i])
+		if err != nil {
+			return err
+		}
+		if e.messages > 0 {
+			data = append([]byte{','}, data...)
+		}
+		if _, err := e.w.Write(data); err != nil {
+			return err
+		}
+		e.messages++
+	}
+
+	// Not every ResponseWriter can flush; the data is then sent when the handler returns
+	_ = e.rc.Flush()
+	return nil
+}
+
+// finish closes the open conversation and the bundle
+func (e *exportWriter) finish() error {
+	if err := e.start(); err != nil {
+		return err
+	}
+
+	tail := "]}"
+	if e.convs > 0 {
+		tail = "]}]}"
+	}
+	_, err := io.WriteString(e.w, tail)
+	return err
+}
+
+// DeleteParticipantResponse reports how many conversations were removed
+type DeleteParticipantResponse struct {
+	ParticipantID        string `json:"participant_id"`
+	DeletedConversations int    `json:"deleted_conversations"`
+}
+
+// DeleteParticipant handles DELETE /direct/participants/{participantId}?account_id=...
+func (h *DirectHandler) DeleteParticipant() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		participantID := chi.URLParam(r, "participantId")
+
+		accountID := r.URL.Query().Get("account_id")
+		if accountID == "" {
+			response.AccountIDRequired(w)
+			return
+		}
+
+		deleted, err := h.policy.DeleteParticipant(r.Context(), policy.ParticipantInput{
+			AccountID:     accountID,
+			ParticipantID: participantID,
+		})
+		if err != nil {
+			handleDirectError(w, err)
+			return
+		}
+
+		response.OK(w, DeleteParticipantResponse{ParticipantID: participantID, DeletedConversations: deleted})
+	}
+}
+
+// LabelsRequest represents the request body for adding conversation labels
+type LabelsRequest struct {
+	AccountID string   `json:"account_id"`
+	Labels    []string `json:"labels"`
+}
+
+// LabelsResponse represents the current labels of a conversation
+type LabelsResponse struct {
+	ConversationID string   `json:"conversation_id"`
+	Labels         []string `json:"labels"`
+}
+
+// AddLabels handles POST /direct/conversations/{conversationId}/labels
+func (h *DirectHandler) AddLabels() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conversationID := chi.URLParam(r, "conversationId")
+
+		var req LabelsRequest
+		if !decodeJSON(w, r, &req) {
 			return
 		}
 
 		if req.AccountID == "" {
-			response.BadRequest(w, "account_id is required")
+			response.AccountIDRequired(w)
 			return
 		}
 
-		err := h.policy.SyncConversations(r.Context(), policy.SyncConversationsInput{
-			AccountID: req.AccountID,
+		labels, err := h.policy.AddConversationLabels(r.Context(), policy.LabelsInput{
+			AccountID:      req.AccountID,
+			ConversationID: conversationID,
+			Labels:         req.Labels,
 		})
 		if err != nil {
 			handleDirectError(w, err)
 			return
 		}
 
-		response.OK(w, map[string]string{"status": "synced"})
+		response.OK(w, LabelsResponse{ConversationID: conversationID, Labels: labels})
 	}
 }
 
-// SyncMessagesRequest represents the request body for syncing messages
-type SyncMessagesRequest struct {
-	AccountID string `json:"account_id"`
+// RemoveLabels handles DELETE /direct/conversations/{conversationId}/labels?account_id=...&label=...
+func (h *DirectHandler) RemoveLabels() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conversationID := chi.URLParam(r, "conversationId")
+
+		accountID := r.URL.Query().Get("account_id")
+		if accountID == "" {
+			response.AccountIDRequired(w)
+			return
+		}
+
+		labels, err := h.policy.RemoveConversationLabels(r.Context(), policy.LabelsInput{
+			AccountID:      accountID,
+			ConversationID: conversationID,
+			Labels:         labelParams(r),
+		})
+		if err != nil {
+			handleDirectError(w, err)
+			return
+		}
+
+		response.OK(w, LabelsResponse{ConversationID: conversationID, Labels: labels})
+	}
 }
 
-// SyncMessages handles POST /direct/conversations/{conversationId}/messages/sync
-func (h *DirectHandler) SyncMessages() http.HandlerFunc {
+// SendReactionRequest represents the request body for reacting to a message
+type SendReactionRequest struct {
+	AccountID   string `json:"account_id"`
+	RecipientID string `json:"recipient_id"`
+	Reaction    string `json:"reaction"` // Empty removes the reaction
+}
+
+// SendReactionResponse represents the response for reacting to a message
+type SendReactionResponse struct {
+	MessageID string `json:"message_id"`
+	Reaction  string `json:"reaction"`
+}
+
+// SendReaction handles POST /direct/conversations/{conversationId}/messages/{messageId}/reaction
+func (h *DirectHandler) SendReaction() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		conversationID := chi.URLParam(r, "conversationId")
+		messageID := chi.URLParam(r, "messageId")
 
-		var req SyncMessagesRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			response.BadRequest(w, "invalid JSON")
+		var req SendReactionRequest
+		if !decodeJSON(w, r, &req) {
 			return
 		}
 
 		if req.AccountID == "" {
-			response.BadRequest(w, "account_id is required")
+			response.AccountIDRequired(w)
+			return
+		}
+		if req.RecipientID == "" {
+			response.BadRequest(w, "recipient_id is required")
 			return
 		}
 
-		err := h.policy.SyncMessages(r.Context(), policy.SyncMessagesInput{
+		err := h.policy.SendReaction(r.Context(), policy.SendReactionInput{
 			AccountID:      req.AccountID,
 			ConversationID: conversationID,
+			MessageID:      messageID,
+			RecipientID:    req.RecipientID,
+			Reaction:       req.Reaction,
 		})
 		if err != nil {
 			handleDirectError(w, err)
 			return
 		}
 
-		response.OK(w, map[string]string{"status": "synced"})
+		response.OK(w, SendReactionResponse{MessageID: messageID, Reaction: req.Reaction})
+	}
+}
+
+// RemoveReaction handles DELETE /direct/conversations/{conversationId}/messages/{messageId}/reaction
+func (h *DirectHandler) RemoveReaction() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conversationID := chi.URLParam(r, "conversationId")
+		messageID := chi.URLParam(r, "messageId")
+
+		accountID := r.URL.Query().Get("account_id")
+		if accountID == "" {
+			response.AccountIDRequired(w)
+			return
+		}
+		recipientID := r.URL.Query().Get("recipient_id")
+		if recipientID == "" {
+			response.BadRequest(w, "recipient_id is required")
+			return
+		}
+
+		err := h.policy.SendReaction(r.Context(), policy.SendReactionInput{
+			AccountID:      accountID,
+			ConversationID: conversationID,
+			MessageID:      messageID,
+			RecipientID:    recipientID,
+		})
+		if err != nil {
+			handleDirectError(w, err)
+			return
+		}
+
+		response.NoContent(w)
+	}
+}
+
+// SyncConversations handles POST /direct/sync?account_id=...
+func (h *DirectHandler) SyncConversations() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID, ok := syncAccountID(w, r)
+		if !ok {
+			return
+		}
+
+		out, err := h.policy.SyncConversations(r.Context(), policy.SyncConversationsInput{
+			AccountID: accountID,
+		})
+		if err != nil {
+			handleDirectError(w, err)
+			return
+		}
+
+		response.OK(w, out)
+	}
+}
+
+// SyncMessages handles POST /direct/conversations/{conversationId}/sync?account_id=...
+func (h *DirectHandler) SyncMessages() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conversationID := chi.URLParam(r, "conversationId")
+
+		accountID, ok := syncAccountID(w, r)
+		if !ok {
+			return
+		}
+
+		out, err := h.policy.SyncMessages(r.Context(), policy.SyncMessagesInput{
+			AccountID:      accountID,
+			ConversationID: conversationID,
+		})
+		if err != nil {
+			handleDirectError(w, err)
+			return
+		}
+
+		response.OK(w, out)
+	}
+}
+
+// QuickRepliesResponse represents the response for listing quick replies
+type QuickRepliesResponse struct {
+	QuickReplies []policy.QuickReply `json:"quick_replies"`
+}
+
+// GetQuickReplies handles GET /direct/quick-replies
+func (h *DirectHandler) GetQuickReplies() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID := r.URL.Query().Get("account_id")
+		if accountID == "" {
+			response.AccountIDRequired(w)
+			return
+		}
+
+		replies, err := h.policy.GetQuickReplies(r.Context(), accountID)
+		if err != nil {
+			handleDirectError(w, err)
+			return
+		}
+
+		response.OK(w, QuickRepliesResponse{QuickReplies: replies})
 	}
 }
 
@@ -398,7 +829,7 @@ func (h *DirectHandler) GetStatistics() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		accountID := r.URL.Query().Get("account_id")
 		if accountID == "" {
-			response.BadRequest(w, "account_id is required")
+			response.AccountIDRequired(w)
 			return
 		}
 
@@ -437,7 +868,7 @@ func (h *DirectHandler) GetHeatmap() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		accountID := r.URL.Query().Get("account_id")
 		if accountID == "" {
-			response.BadRequest(w, "account_id is required")
+			response.AccountIDRequired(w)
 			return
 		}
 
@@ -471,22 +902,47 @@ func (h *DirectHandler) GetHeatmap() http.HandlerFunc {
 	}
 }
 
+// directErrorCodes maps direct domain errors to their API error codes
+var directErrorCodes = map[error]response.Code{
+	entity.ErrConversationNotFound: response.CodeConversationNotFound,
+	entity.ErrTemplateNotFound:     response.CodeTemplateNotFound,
+	entity.ErrMessageNotFound:      response.CodeMessageNotFound,
+	entity.ErrEmptyMessage:         response.CodeEmptyMessage,
+	entity.ErrMessageTooLong:       response.CodeMessageTooLong,
+	entity.ErrInvalidMediaType:     response.CodeInvalidMediaType,
+	entity.ErrInvalidReaction:      response.CodeInvalidReaction,
+	entity.ErrTemplateNotDirect:    response.CodeTemplateNotDirect,
+	entity.ErrNoLabels:             response.CodeNoLabels,
+	entity.ErrInvalidLabel:         response.CodeInvalidLabel,
+	entity.ErrTooManyLabels:        response.CodeTooManyLabels,
+	entity.ErrUnauthorized:         response.CodeUnauthorized,
+	entity.ErrAccountForbidden:     response.CodeForbidden,
+	entity.ErrRateLimited:          response.CodeRateLimited,
+	entity.ErrSyncInProgress:       response.CodeSyncInProgress,
+}
+
 func handleDirectError(w http.ResponseWriter, err error) {
+	code := directErrorCodes[err]
 	switch err {
-	case entity.ErrConversationNotFound:
-		response.NotFound(w, err.Error())
+	case entity.ErrConversationNotFound, entity.ErrTemplateNotFound:
+		response.CodedError(w, http.StatusNotFound, code, err.Error())
 	case entity.ErrMessageNotFound:
-		response.NotFound(w, err.Error())
+		response.CodedError(w, http.StatusNotFound, code, err.Error())
 	case entity.ErrEmptyMessage:
-		response.BadRequest(w, err.Error())
+		response.CodedError(w, http.StatusBadRequest, code, err.Error())
 	case entity.ErrMessageTooLong:
-		response.BadRequest(w, err.Error())
-	case entity.ErrInvalidMediaType:
-		response.BadRequest(w, err.Error())
+		response.CodedError(w, http.StatusBadRequest, code, err.Error())
+	case entity.ErrInvalidMediaType, entity.ErrInvalidReaction, entity.ErrTemplateNotDirect,
+		entity.ErrNoLabels, entity.ErrInvalidLabel, entity.ErrTooManyLabels:
+		response.CodedError(w, http.StatusBadRequest, code, err.Error())
 	case entity.ErrUnauthorized:
-		response.Unauthorized(w, err.Error())
+		response.CodedError(w, http.StatusUnauthorized, code, err.Error())
+	case entity.ErrAccountForbidden:
+		response.CodedError(w, http.StatusForbidden, code, err.Error())
 	case entity.ErrRateLimited:
-		response.Error(w, http.StatusTooManyRequests, err.Error())
+		response.CodedError(w, http.StatusTooManyRequests, code, err.Error())
+	case entity.ErrSyncInProgress:
+		response.CodedError(w, http.StatusConflict, code, err.Error())
 	default:
 		response.InternalError(w, "internal server error")
 	}
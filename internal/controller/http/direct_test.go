@@ -0,0 +1,63 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/vadim/neo-metric/internal/httpx/response"
+)
+
+func postDirect(t *testing.T, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := chi.NewRouter()
+	// Invalid requests are rejected before the policy is called
+	NewDirectHandler(nil).RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestSendMessageListsAllInvalidFields(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		body string
+		want []string
+	}{
+		{"empty message", "/direct/conversations/c1/messages", `{}`, []string{"account_id", "recipient_id", "message"}},
+		{"message and template", "/direct/conversations/c1/messages",
+			`{"account_id": "acc", "message": "hi", "template_id": "t1"}`, []string{"recipient_id", "template_id"}},
+		{"empty media message", "/direct/conversations/c1/media", `{"account_id": "acc"}`,
+			[]string{"recipient_id", "media_url", "media_type"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := postDirect(t, tt.path, tt.body)
+
+			var body struct {
+				Error response.ErrorBody `json:"error"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("decoding body: %v", err)
+			}
+			if rec.Code != http.StatusUnprocessableEntity || body.Error.Code != response.CodeValidationFailed {
+				t.Fatalf("got %d %s, want 422 %s", rec.Code, body.Error.Code, response.CodeValidationFailed)
+			}
+			var fields []string
+			for _, f := range body.Error.Fields {
+				fields = append(fields, f.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("fields = %v, want %v", fields, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	commentEntity "github.com/vadim/neo-metric/internal/domain/comment/entity"
+	directEntity "github.com/vadim/neo-metric/internal/domain/direct/entity"
+	pubEntity "github.com/vadim/neo-metric/internal/domain/publication/entity"
+	templateEntity "github.com/vadim/neo-metric/internal/domain/template/entity"
+	"github.com/vadim/neo-metric/internal/httpx/response"
+)
+
+func TestDomainErrorCodes(t *testing.T) {
+	tests := []struct {
+		name       string
+		handle     func(http.ResponseWriter, error)
+		err        error
+		wantStatus int
+		wantCode   response.Code
+	}{
+		{"publication not found", handleDomainError, pubEntity.ErrPublicationNotFound, http.StatusNotFound, response.CodePublicationNotFound},
+		{"caption too long", handleDomainError, pubEntity.ErrCaptionTooLong, http.StatusBadRequest, response.CodeCaptionTooLong},
+		{"invalid publication sort", handleDomainError, pubEntity.ErrInvalidSort, http.StatusBadRequest, response.CodeInvalidSort},
+		{"hashtag set not found", handleDomainError, pubEntity.ErrHashtagSetNotFound, http.StatusBadRequest, response.CodeHashtagSetNotFound},
+		{"invalid story link", handleDomainError, pubEntity.ErrInvalidStoryLink, http.StatusBadRequest, response.CodeInvalidStoryLink},
+		{"story stickers rejected", handleDomainError, fmt.Errorf("%w: code 100", pubEntity.ErrStoryStickersRejected), http.StatusUnprocessableEntity, response.CodeStoryStickersRejected},
+		{"wrapped instagram rate limit", handleDomainError, fmt.Errorf("%w: code 4", pubEntity.ErrInstagramRateLimited), http.StatusTooManyRequests, response.CodeInstagramRateLimited},
+		{"wrapped container failure", handleDomainError, fmt.Errorf("%w: invalid image", pubEntity.ErrContainerFailed), http.StatusUnprocessableEntity, response.CodeContainerFailed},
+		{"wrapped container expired", handleDomainError, fmt.Errorf("waiting for container: %w", pubEntity.ErrContainerExpired), http.StatusUnprocessableEntity, response.CodeContainerExpired},
+		{"container not ready", handleDomainError, fmt.Errorf("waiting for container: %w", pubEntity.ErrContainerNotReady), http.StatusConflict, response.CodeContainerNotReady},
+		{"container already published", handleDomainError, pubEntity.ErrContainerPublished, http.StatusConflict, response.CodeContainerPublished},
+		{"comment sync in progress", handleCommentError, commentEntity.ErrSyncInProgress, http.StatusConflict, response.CodeSyncInProgress},
+		{"invalid sentiment", handleCommentError, commentEntity.ErrInvalidSentiment, http.StatusBadRequest, response.CodeInvalidSentiment},
+		{"comment account forbidden", handleCommentError, commentEntity.ErrAccountForbidden, http.StatusForbidden, response.CodeForbidden},
+		{"direct account forbidden", handleDirectError, directEntity.ErrAccountForbidden, http.StatusForbidden, response.CodeForbidden},
+		{"conversation not found", handleDirectError, directEntity.ErrConversationNotFound, http.StatusNotFound, response.CodeConversationNotFound},
+		{"invalid label", handleDirectError, directEntity.ErrInvalidLabel, http.StatusBadRequest, response.CodeInvalidLabel},
+		{"template title too long", handleTemplateError, templateEntity.ErrTitleTooLong, http.StatusBadRequest, response.CodeTitleTooLong},
+		{"hashtag set without hashtags", handleTemplateError, templateEntity.ErrNoHashtags, http.StatusBadRequest, response.CodeNoHashtags},
+		{"upload rejected", handleUploadError, fmt.Errorf("%w: EntityTooLarge", ErrUploadRejected), http.StatusBadRequest, response.CodeUploadRejected},
+		{"storage unavailable", handleUploadError, fmt.Errorf("%w: status 503", ErrStorageUnavailable), http.StatusServiceUnavailable, response.CodeStorageUnavailable},
+		{"unknown error", handleTemplateError, errors.New("connection reset"), http.StatusInternalServerError, response.CodeInternal},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tc.handle(rec, tc.err)
+
+			var body struct {
+				Error response.ErrorBody `json:"error"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("decoding body: %v", err)
+			}
+			if rec.Code != tc.wantStatus || body.Error.Code != tc.wantCode {
+				t.Errorf("got %d %s, want %d %s", rec.Code, body.Error.Code, tc.wantStatus, tc.wantCode)
+			}
+			if body.Error.Message == "" {
+				t.Error("message is empty")
+			}
+		})
+	}
+}
+
+func TestContainerFailureKeepsInstagramReason(t *testing.T) {
+	rec := httptest.NewRecorder()
+	handleDomainError(rec, fmt.Errorf("waiting for container: %w: %s", pubEntity.ErrContainerFailed, "video duration exceeds 90 seconds"))
+
+	var body struct {
+		Error response.ErrorBody `json:"error"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding body: %v", err)
+	}
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(body.Error.Message, "video duration exceeds 90 seconds") {
+		t.Errorf("got %d %q, want 422 with Instagram's reason", rec.Code, body.Error.Message)
+	}
+}
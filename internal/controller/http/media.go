@@ -2,18 +2,32 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/vadim/neo-metric/internal/httpx/response"
+	"github.com/vadim/neo-metric/internal/imagefit"
 )
 
 // MaxUploadSize is the maximum allowed upload size (50MB)
 const MaxUploadSize = 50 << 20
 
+// sniffLen is how much of the file is inspected to detect its real type, as in http.DetectContentType
+const sniffLen = 512
+
+// Upload errors a MediaUploader wraps to tell the client whether to fix the file or retry later
+var (
+	ErrUploadRejected     = errors.New("storage rejected the file")
+	ErrStorageUnavailable = errors.New("media storage is temporarily unavailable")
+)
+
 // MediaUploader defines the interface for uploading media
 type MediaUploader interface {
 	Upload(ctx context.Context, in MediaUploadInput) (*MediaUploadOutput, error)
@@ -78,11 +92,33 @@ func (h *MediaHandler) Upload() http.HandlerFunc {
 
 		// Validate content type
 		contentType := header.Header.Get("Content-Type")
+		if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+			contentType = mediaType
+		}
 		if !isAllowedMediaType(contentType) {
 			response.BadRequest(w, fmt.Sprintf("unsupported media type: %s", contentType))
 			return
 		}
 
+		// Check the file really is what it claims to be, Instagram rejects it later otherwise
+		head := make([]byte, sniffLen)
+		n, err := file.ReadAt(head, 0)
+		if err != nil && err != io.EOF {
+			response.BadRequest(w, "failed to read uploaded file")
+			return
+		}
+		detected := sniffMediaType(head[:n])
+		if !isAllowedMediaType(detected) {
+			response.CodedError(w, http.StatusBadRequest, response.CodeMediaTypeNotAllowed,
+				fmt.Sprintf("file content is not a supported image or video (detected %s)", detected))
+			return
+		}
+		if detected != contentType {
+			response.CodedError(w, http.StatusBadRequest, response.CodeMediaTypeMismatch,
+				fmt.Sprintf("file content is %s but was declared as %s", detected, contentType))
+			return
+		}
+
 		// Upload to storage
 		result, err := h.uploader.Upload(r.Context(), MediaUploadInput{
 			Reader:      file,
@@ -93,7 +129,7 @@ func (h *MediaHandler) Upload() http.HandlerFunc {
 		if err != nil {
 			// Log error for debugging (in production, use proper logger)
 			fmt.Printf("upload error: %v\n", err)
-			response.InternalError(w, fmt.Sprintf("failed to upload file: %v", err))
+			handleUploadError(w, err)
 			return
 		}
 
@@ -105,6 +141,19 @@ func (h *MediaHandler) Upload() http.HandlerFunc {
 	}
 }
 
+// handleUploadError maps upload errors: rejected files are the client's to fix,
+// unavailable storage is worth retrying later
+func handleUploadError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrUploadRejected):
+		response.CodedError(w, http.StatusBadRequest, response.CodeUploadRejected, ErrUploadRejected.Error())
+	case errors.Is(err, ErrStorageUnavailable):
+		response.CodedError(w, http.StatusServiceUnavailable, response.CodeStorageUnavailable, ErrStorageUnavailable.Error())
+	default:
+		response.InternalError(w, fmt.Sprintf("failed to upload file: %v", err))
+	}
+}
+
 // isAllowedMediaType checks if the content type is allowed for upload
 func isAllowedMediaType(contentType string) bool {
 	allowed := []string{
@@ -123,3 +172,180 @@ func isAllowedMediaType(contentType string) bool {
 	}
 	return false
 }
+
+// Video containers are told apart by the ISO BMFF "ftyp" brand, which http.DetectContentType
+// only partly understands (it misses QuickTime entirely)
+var videoBrands = map[string]string{
+	"isom": "video/mp4",
+	"iso2": "video/mp4",
+	"iso4": "video/mp4",
+	"iso5": "video/mp4",
+	"iso6": "video/mp4",
+	"mp41": "video/mp4",
+	"mp42": "video/mp4",
+	"avc1": "video/mp4",
+	"M4V ": "video/mp4",
+	"dash": "video/mp4",
+	"qt  ": "video/quicktime",
+}
+
+// sniffMediaType returns the MIME type of a file from its first bytes
+func sniffMediaType(head []byte) string {
+	if len(head) >= 12 && string(head[4:8]) == "ftyp" {
+		// Major brand first, then the compatible brands listed after the minor version
+		if t, ok := videoBrands[string(head[8:12])]; ok {
+			return t
+		}
+		size := min(int(head[0])<<24|int(head[1])<<16|int(head[2])<<8|int(head[3]), len(head))
+		for i := 16; i+4 <= size; i += 4 {
+			if t, ok := videoBrands[string(head[i:i+4])]; ok {
+				return t
+			}
+		}
+		return "application/octet-stream"
+	}
+
+	detected, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	return detected
+}
+
+// Fit source errors a MediaFitter wraps; storage failures use the upload errors
+var (
+	ErrFitSourceNotFound = errors.New("source media not found")
+	ErrFitSourceInvalid  = errors.New("source media cannot be fitted")
+)
+
+// MediaFitter re-crops stored images to an Instagram aspect ratio
+type MediaFitter interface {
+	Fit(ctx context.Context, in MediaFitInput) (*MediaFitOutput, error)
+}
+
+// MediaFitInput identifies the stored image by URL or key and the shape to bring it to
+type MediaFitInput struct {
+	URL    string
+	Key    string
+	Aspect imagefit.Aspect
+	Mode   imagefit.Mode
+}
+
+// MediaFitOutput is the newly uploaded image
+type MediaFitOutput struct {
+	URL    string
+	Key    string
+	Size   int64
+	Width  int
+	Height int
+}
+
+// fitAspects lists the aspect ratios allowed per target; the first is the default
+var fitAspects = map[string][]string{
+	"feed":  {"1:1", "4:5", "1.91:1"},
+	"story": {"9:16"},
+	"reel":  {"9:16"},
+}
+
+var aspectsByName = map[string]imagefit.Aspect{
+	"1:1":    imagefit.AspectSquare,
+	"4:5":    imagefit.AspectPortrait,
+	"1.91:1": imagefit.AspectLandscape,
+	"9:16":   imagefit.AspectVertical,
+}
+
+// MediaFitHandler handles media fitting HTTP requests
+type MediaFitHandler struct {
+	fitter MediaFitter
+}
+
+// NewMediaFitHandler creates a new media fit handler
+func NewMediaFitHandler(fitter MediaFitter) *MediaFitHandler {
+	return &MediaFitHandler{fitter: fitter}
+}
+
+// RegisterRoutes registers media fit routes
+func (h *MediaFitHandler) RegisterRoutes(r chi.Router) {
+	r.Post("/media/fit", h.Fit())
+}
+
+// FitMediaRequest represents the request body for fitting media
+type FitMediaRequest struct {
+	URL    string `json:"url,omitempty"`
+	Key    string `json:"key,omitempty"`
+	Target string `json:"target"`           // feed, story or reel
+	Aspect string `json:"aspect,omitempty"` // For feed: 1:1 (default), 4:5 or 1.91:1
+	Mode   string `json:"mode,omitempty"`   // pad (default) or crop
+}
+
+// FitMediaResponse represents the response from fit endpoint
+type FitMediaResponse struct {
+	URL    string `json:"url"`
+	Key    string `json:"key"`
+	Size   int64  `json:"size"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// Fit handles POST /media/fit
+func (h *MediaFitHandler) Fit() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req FitMediaRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+
+		if (req.URL == "") == (req.Key == "") {
+			response.BadRequest(w, "exactly one of url or key is required")
+			return
+		}
+		aspects, ok := fitAspects[req.Target]
+		if !ok {
+			response.BadRequest(w, "target must be one of: feed, story, reel")
+			return
+		}
+		if req.Aspect == "" {
+			req.Aspect = aspects[0]
+		} else if !slices.Contains(aspects, req.Aspect) {
+			response.BadRequest(w, fmt.Sprintf("aspect for %s must be one of: %s", req.Target, strings.Join(aspects, ", ")))
+			return
+		}
+		mode := imagefit.Mode(req.Mode)
+		switch mode {
+		case "":
+			mode = imagefit.ModePad
+		case imagefit.ModePad, imagefit.ModeCrop:
+		default:
+			response.BadRequest(w, "mode must be one of: pad, crop")
+			return
+		}
+
+		result, err := h.fitter.Fit(r.Context(), MediaFitInput{
+			URL:    req.URL,
+			Key:    req.Key,
+			Aspect: aspectsByName[req.Aspect],
+			Mode:   mode,
+		})
+		if err != nil {
+			handleFitError(w, err)
+			return
+		}
+
+		response.Created(w, FitMediaResponse{
+			URL:    result.URL,
+			Key:    result.Key,
+			Size:   result.Size,
+			Width:  result.Width,
+			Height: result.Height,
+		})
+	}
+}
+
+// handleFitError maps fit errors, falling back to upload errors for storing the result
+func handleFitError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrFitSourceNotFound):
+		response.CodedError(w, http.StatusNotFound, response.CodeMediaNotFound, err.Error())
+	case errors.Is(err, ErrFitSourceInvalid):
+		response.CodedError(w, http.StatusBadRequest, response.CodeFitSourceInvalid, err.Error())
+	default:
+		handleUploadError(w, err)
+	}
+}
@@ -0,0 +1,211 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/vadim/neo-metric/internal/httpx/response"
+	"github.com/vadim/neo-metric/internal/imagefit"
+)
+
+// recordingUploader keeps what it was asked to upload
+type recordingUploader struct {
+	got  *MediaUploadInput
+	body []byte
+}
+
+func (u *recordingUploader) Upload(_ context.Context, in MediaUploadInput) (*MediaUploadOutput, error) {
+	u.got = &in
+	u.body, _ = io.ReadAll(in.Reader)
+	return &MediaUploadOutput{URL: "https://cdn.example.com/" + in.Filename, Key: in.Filename, Size: in.Size}, nil
+}
+
+func postMedia(t *testing.T, u MediaUploader, filename, contentType string, content []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Disposition", `form-data; name="file"; filename="`+filename+`"`)
+	h.Set("Content-Type", contentType)
+	part, err := mw.CreatePart(h)
+	if err != nil {
+		t.Fatalf("creating part: %v", err)
+	}
+	part.Write(content)
+	mw.Close()
+
+	r := chi.NewRouter()
+	NewMediaHandler(u).RegisterRoutes(r)
+	req := httptest.NewRequest(http.MethodPost, "/media/upload", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func errorCode(t *testing.T, rec *httptest.ResponseRecorder) response.Code {
+	t.Helper()
+	var body struct {
+		Error response.ErrorBody `json:"error"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding body: %v", err)
+	}
+	return body.Error.Code
+}
+
+var (
+	jpegData = append([]byte("\xFF\xD8\xFF\xE0\x00\x10JFIF\x00"), make([]byte, 64)...)
+	pngData  = append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64)...)
+	exeData  = append([]byte("MZ\x90\x00\x03\x00\x00\x00"), make([]byte, 64)...)
+	movData  = append([]byte("\x00\x00\x00\x14ftypqt  \x00\x00\x02\x00qt  "), make([]byte, 64)...)
+	// Major brand unknown to http.DetectContentType's shortlist, mp42 only among compatible brands
+	mp4Data = append([]byte("\x00\x00\x00\x18ftypXAVC\x00\x00\x00\x00XAVCmp42"), make([]byte, 64)...)
+)
+
+func TestUploadAcceptsMatchingContent(t *testing.T) {
+	tests := []struct {
+		name        string
+		filename    string
+		contentType string
+		content     []byte
+	}{
+		{"jpeg", "photo.jpg", "image/jpeg", jpegData},
+		{"quicktime", "clip.mov", "video/quicktime", movData},
+		{"mp4 by compatible brand", "reel.mp4", "video/mp4", mp4Data},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			u := &recordingUploader{}
+			rec := postMedia(t, u, tc.filename, tc.contentType, tc.content)
+
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("status = %d, want 201: %s", rec.Code, rec.Body)
+			}
+			if u.got.ContentType != tc.contentType {
+				t.Errorf("uploaded as %s, want %s", u.got.ContentType, tc.contentType)
+			}
+			// Sniffing must not consume the start of the file
+			if !bytes.Equal(u.body, tc.content) {
+				t.Errorf("uploaded %d bytes, want the whole %d byte file", len(u.body), len(tc.content))
+			}
+		})
+	}
+}
+
+func TestUploadRejectsWrongContent(t *testing.T) {
+	tests := []struct {
+		name        string
+		filename    string
+		contentType string
+		content     []byte
+		wantCode    response.Code
+	}{
+		{"png labeled as jpeg", "photo.jpg", "image/jpeg", pngData, response.CodeMediaTypeMismatch},
+		{"quicktime labeled as mp4", "reel.mp4", "video/mp4", movData, response.CodeMediaTypeMismatch},
+		{"executable renamed to jpeg", "photo.jpg", "image/jpeg", exeData, response.CodeMediaTypeNotAllowed},
+		{"disallowed declared type", "setup.exe", "application/x-msdownload", exeData, response.CodeBadRequest},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			u := &recordingUploader{}
+			rec := postMedia(t, u, tc.filename, tc.contentType, tc.content)
+
+			if code := errorCode(t, rec); rec.Code != http.StatusBadRequest || code != tc.wantCode {
+				t.Errorf("got %d %s, want 400 %s", rec.Code, code, tc.wantCode)
+			}
+			if u.got != nil {
+				t.Error("rejected file was uploaded")
+			}
+		})
+	}
+}
+
+// recordingFitter keeps the fit input and returns err if set
+type recordingFitter struct {
+	got *MediaFitInput
+	err error
+}
+
+func (f *recordingFitter) Fit(_ context.Context, in MediaFitInput) (*MediaFitOutput, error) {
+	f.got = &in
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &MediaFitOutput{URL: "https://cdn.example.com/fit.jpg", Key: "fit.jpg", Width: 100, Height: 100}, nil
+}
+
+func postFit(t *testing.T, f MediaFitter, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := chi.NewRouter()
+	NewMediaFitHandler(f).RegisterRoutes(r)
+	req := httptest.NewRequest(http.MethodPost, "/media/fit", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestFitResolvesTargetAspect(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantAspect imagefit.Aspect
+		wantMode   imagefit.Mode
+	}{
+		{"feed defaults to square", `{"key": "a.jpg", "target": "feed"}`, imagefit.AspectSquare, imagefit.ModePad},
+		{"feed portrait crop", `{"key": "a.jpg", "target": "feed", "aspect": "4:5", "mode": "crop"}`, imagefit.AspectPortrait, imagefit.ModeCrop},
+		{"reel", `{"url": "https://cdn.example.com/a.jpg", "target": "reel"}`, imagefit.AspectVertical, imagefit.ModePad},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &recordingFitter{}
+			rec := postFit(t, f, tc.body)
+
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("status = %d, want 201: %s", rec.Code, rec.Body)
+			}
+			if f.got.Aspect != tc.wantAspect || f.got.Mode != tc.wantMode {
+				t.Errorf("fit with %v %s, want %v %s", f.got.Aspect, f.got.Mode, tc.wantAspect, tc.wantMode)
+			}
+		})
+	}
+}
+
+func TestFitRejectsInvalidRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+		wantCode   response.Code
+	}{
+		{"no source", `{"target": "feed"}`, nil, http.StatusBadRequest, response.CodeBadRequest},
+		{"both sources", `{"url": "https://cdn.example.com/a.jpg", "key": "a.jpg", "target": "feed"}`, nil, http.StatusBadRequest, response.CodeBadRequest},
+		{"unknown target", `{"key": "a.jpg", "target": "igtv"}`, nil, http.StatusBadRequest, response.CodeBadRequest},
+		{"story aspect for feed", `{"key": "a.jpg", "target": "feed", "aspect": "9:16"}`, nil, http.StatusBadRequest, response.CodeBadRequest},
+		{"unknown mode", `{"key": "a.jpg", "target": "feed", "mode": "stretch"}`, nil, http.StatusBadRequest, response.CodeBadRequest},
+		{"missing source", `{"key": "a.jpg", "target": "feed"}`, fmt.Errorf("%w: a.jpg", ErrFitSourceNotFound), http.StatusNotFound, response.CodeMediaNotFound},
+		{"not an image", `{"key": "a.mp4", "target": "feed"}`, fmt.Errorf("%w: unsupported image format", ErrFitSourceInvalid), http.StatusBadRequest, response.CodeFitSourceInvalid},
+		{"storage down", `{"key": "a.jpg", "target": "feed"}`, fmt.Errorf("%w: status 503", ErrStorageUnavailable), http.StatusServiceUnavailable, response.CodeStorageUnavailable},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := postFit(t, &recordingFitter{err: tc.err}, tc.body)
+
+			if code := errorCode(t, rec); rec.Code != tc.wantStatus || code != tc.wantCode {
+				t.Errorf("got %d %s, want %d %s", rec.Code, code, tc.wantStatus, tc.wantCode)
+			}
+		})
+	}
+}
@@ -2,7 +2,7 @@ package http
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -12,6 +12,7 @@ import (
 	"github.com/vadim/neo-metric/internal/domain/publication/entity"
 	"github.com/vadim/neo-metric/internal/domain/publication/policy"
 	"github.com/vadim/neo-metric/internal/httpx/response"
+	"github.com/vadim/neo-metric/internal/httpx/validate"
 )
 
 // PublicationPolicy defines the interface for publication operations
@@ -19,23 +20,47 @@ import (
 type PublicationPolicy interface {
 	CreatePublication(ctx context.Context, in policy.CreatePublicationInput) (*policy.CreatePublicationOutput, error)
 	UpdatePublication(ctx context.Context, in policy.UpdatePublicationInput) (*policy.UpdatePublicationOutput, error)
+	ReorderMedia(ctx context.Context, id string, order []policy.MediaOrderInput) (*entity.Publication, error)
 	GetPublication(ctx context.Context, id string) (*entity.Publication, error)
+	GetPublicationByMediaID(ctx context.Context, instagramMediaID string) (*entity.PublicationWithComments, error)
 	DeletePublication(ctx context.Context, in policy.DeletePublicationInput) error
+	RestorePublication(ctx context.Context, id string) (*entity.Publication, error)
 	ListPublications(ctx context.Context, in policy.ListPublicationsInput) (*policy.ListPublicationsOutput, error)
 	PublishNow(ctx context.Context, id string) (*entity.Publication, error)
+	EnqueuePublish(ctx context.Context, id string) (*entity.Publication, error)
 	SchedulePublication(ctx context.Context, id string, scheduledAt time.Time) (*entity.Publication, error)
 	SaveAsDraft(ctx context.Context, id string) (*entity.Publication, error)
+	PausePublication(ctx context.Context, id string) (*entity.Publication, error)
+	ResumePublication(ctx context.Context, id string) (*entity.Publication, error)
 	GetStatistics(ctx context.Context, accountID string) (*entity.PublicationStatistics, error)
+	PreviewScheduledPublications(ctx context.Context) ([]policy.ScheduledPreview, error)
+	SetCommentsEnabled(ctx context.Context, id string, enabled bool) (*entity.Publication, error)
+	BackfillPermalinks(ctx context.Context, limit, offset int) (*policy.BackfillPermalinksOutput, error)
 }
 
 // PublicationHandler handles HTTP requests for publications
 type PublicationHandler struct {
-	policy PublicationPolicy
+	policy       PublicationPolicy
+	pages        response.PageLimits
+	publishAsync bool // Publish endpoint queues the publication and answers 202
 }
 
 // NewPublicationHandler creates a new publication handler
 func NewPublicationHandler(p PublicationPolicy) *PublicationHandler {
-	return &PublicationHandler{policy: p}
+	return &PublicationHandler{policy: p, pages: response.DefaultPageLimits}
+}
+
+// WithPageLimits sets the page sizes of the publication listing
+func (h *PublicationHandler) WithPageLimits(l response.PageLimits) *PublicationHandler {
+	h.pages = l
+	return h
+}
+
+// WithAsyncPublish makes POST /publications/{id}/publish queue the publication and return
+// 202 with status publishing instead of waiting for Instagram
+func (h *PublicationHandler) WithAsyncPublish(enabled bool) *PublicationHandler {
+	h.publishAsync = enabled
+	return h
 }
 
 // RegisterRoutes registers publication routes
@@ -44,31 +69,45 @@ func (h *PublicationHandler) RegisterRoutes(r chi.Router) {
 		r.Post("/", h.Create())
 		r.Get("/", h.List())
 		r.Get("/statistics", h.GetStatistics())
+		r.Get("/scheduled/preview", h.PreviewScheduled())
+		r.Post("/permalinks/backfill", h.BackfillPermalinks())
+		r.Get("/calendar", h.Calendar())
+		r.Get("/by-media/{instagramMediaId}", h.GetByMediaID())
 		r.Get("/{id}", h.Get())
 		r.Put("/{id}", h.Update())
+		r.Put("/{id}/media/order", h.ReorderMedia())
 		r.Delete("/{id}", h.Delete())
+		r.Post("/{id}/restore", h.Restore())
 		r.Post("/{id}/publish", h.PublishNow())
 		r.Post("/{id}/schedule", h.Schedule())
 		r.Post("/{id}/draft", h.SaveAsDraft())
+		r.Post("/{id}/pause", h.Pause())
+		r.Post("/{id}/resume", h.Resume())
+		r.Post("/{id}/comments-enabled", h.SetCommentsEnabled())
 	})
 }
 
 // CreateRequest represents the request body for creating a publication
+
 type CreateRequest struct {
-	AccountID   string              `json:"account_id"`
-	Type        string              `json:"type"` // post, story, reel
-	Caption     string              `json:"caption"`
-	Media       []MediaRequest      `json:"media"`
-	ReelOptions *ReelOptionsRequest `json:"reel_options,omitempty"` // Optional settings for Reels
-	ScheduledAt *string             `json:"scheduled_at,omitempty"` // RFC3339 format
-	PublishNow  bool                `json:"publish_now,omitempty"`  // Publish immediately after creation
+	AccountID     string               `json:"account_id"`
+	Type          string               `json:"type"` // post, story, reel
+	Caption       string               `json:"caption"`
+	Media         []MediaRequest       `json:"media"`
+	ReelOptions   *ReelOptionsRequest  `json:"reel_options,omitempty"`   // Optional settings for Reels
+	StoryOptions  *StoryOptionsRequest `json:"story_options,omitempty"`  // Link sticker and mentions (stories only)
+	Collaborators []string             `json:"collaborators,omitempty"`  // Usernames to invite as collaborators (feed posts)
+	ScheduledAt   *string              `json:"scheduled_at,omitempty"`   // RFC3339 format
+	HashtagSetID  *string              `json:"hashtag_set_id,omitempty"` // Hashtag set posted as the first comment after publishing
+	PublishNow    bool                 `json:"publish_now,omitempty"`    // Publish immediately after creation
 }
 
 // MediaRequest represents a media item in requests
 type MediaRequest struct {
-	URL   string `json:"url"`
-	Type  string `json:"type"` // image, video
-	Order int    `json:"order"`
+	URL     string `json:"url"`
+	Type    string `json:"type"` // image, video
+	Order   int    `json:"order"`
+	AltText string `json:"alt_text,omitempty"` // Accessibility description (images only)
 }
 
 // ReelOptionsRequest represents optional settings for Reel publishing
@@ -81,36 +120,54 @@ type ReelOptionsRequest struct {
 	CollaboratorUsernames []string `json:"collaborator_usernames,omitempty"` // Usernames to invite as collaborators
 }
 
+// toEntity converts the request options, nil when they were not provided
+func (o *ReelOptionsRequest) toEntity() *entity.ReelOptions {
+	if o == nil {
+		return nil
+	}
+	return &entity.ReelOptions{
+		ShareToFeed:           o.ShareToFeed,
+		CoverURL:              o.CoverURL,
+		ThumbOffset:           o.ThumbOffset,
+		AudioName:             o.AudioName,
+		LocationID:            o.LocationID,
+		CollaboratorUsernames: o.CollaboratorUsernames,
+	}
+}
+
+// StoryOptionsRequest represents the link sticker and mentions of a story
+type StoryOptionsRequest struct {
+	LinkURL  string   `json:"link_url,omitempty"` // Target of the link sticker, https only
+	Mentions []string `json:"mentions,omitempty"` // Usernames mentioned in the story
+}
+
+// toEntity converts the request options, nil when they were not provided
+func (o *StoryOptionsRequest) toEntity() *entity.StoryOptions {
+	if o == nil {
+		return nil
+	}
+	return &entity.StoryOptions{LinkURL: o.LinkURL, Mentions: o.Mentions}
+}
+
 // Create handles POST /publications
 func (h *PublicationHandler) Create() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req CreateRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			response.BadRequest(w, "invalid JSON")
+		if !decodeJSON(w, r, &req) {
 			return
 		}
 
-		// Validate required fields
-		if req.AccountID == "" {
-			response.BadRequest(w, "account_id is required")
-			return
-		}
-		if len(req.Media) == 0 {
-			response.BadRequest(w, "at least one media item is required")
-			return
-		}
+		// Collect every field problem so the client can fix them in one go
+		var errs validate.Errors
+		errs.Required("account_id", req.AccountID != "")
+		errs.Required("media", len(req.Media) > 0)
 
 		// Parse publication type
 		pubType, err := parsePublicationType(req.Type)
-		if err != nil {
-			response.BadRequest(w, err.Error())
-			return
-		}
-
-		// Validate that publish_now and scheduled_at are mutually exclusive
-		if req.PublishNow && req.ScheduledAt != nil && *req.ScheduledAt != "" {
-			response.BadRequest(w, "publish_now and scheduled_at cannot be used together")
-			return
+		if req.Type == "" {
+			errs.Required("type", false)
+		} else if err != nil {
+			errs.Invalid("type", err.Error())
 		}
 
 		// Parse scheduled time
@@ -118,10 +175,10 @@ func (h *PublicationHandler) Create() http.HandlerFunc {
 		if req.ScheduledAt != nil && *req.ScheduledAt != "" {
 			t, err := time.Parse(time.RFC3339, *req.ScheduledAt)
 			if err != nil {
-				response.BadRequest(w, "invalid scheduled_at format, use RFC3339")
-				return
+				errs.Invalid("scheduled_at", "invalid scheduled_at format, use RFC3339")
+			} else {
+				scheduledAt = &t
 			}
-			scheduledAt = &t
 		}
 
 		// Build media input
@@ -129,37 +186,47 @@ func (h *PublicationHandler) Create() http.HandlerFunc {
 		for i, m := range req.Media {
 			mediaType, err := parseMediaType(m.Type)
 			if err != nil {
-				response.BadRequest(w, err.Error())
-				return
+				errs.Invalid(validate.Item("media", i, "type"), err.Error())
 			}
 			mediaInput[i] = policy.MediaInput{
-				URL:   m.URL,
-				Type:  mediaType,
-				Order: m.Order,
+				URL:     m.URL,
+				Type:    mediaType,
+				Order:   m.Order,
+				AltText: m.AltText,
 			}
 		}
 
-		// Build reel options if provided
-		var reelOptions *entity.ReelOptions
-		if req.ReelOptions != nil {
-			reelOptions = &entity.ReelOptions{
-				ShareToFeed:           req.ReelOptions.ShareToFeed,
-				CoverURL:              req.ReelOptions.CoverURL,
-				ThumbOffset:           req.ReelOptions.ThumbOffset,
-				AudioName:             req.ReelOptions.AudioName,
-				LocationID:            req.ReelOptions.LocationID,
-				CollaboratorUsernames: req.ReelOptions.CollaboratorUsernames,
-			}
+		if errs.Respond(w) {
+			return
+		}
+
+		if req.ReelOptions != nil && pubType != entity.PublicationTypeReel {
+			handleDomainError(w, entity.ErrReelOptionsNotReel)
+			return
+		}
+		if req.StoryOptions != nil && pubType != entity.PublicationTypeStory {
+			handleDomainError(w, entity.ErrStoryOptionsNotStory)
+			return
 		}
 
+		// Validate that publish_now and scheduled_at are mutually exclusive
+		if req.PublishNow && req.ScheduledAt != nil && *req.ScheduledAt != "" {
+			handleDomainError(w, entity.ErrPublishNowScheduled)
+			return
+		}
+
+
 		out, err := h.policy.CreatePublication(r.Context(), policy.CreatePublicationInput{
-			AccountID:   req.AccountID,
-			Type:        pubType,
-			Caption:     req.Caption,
-			Media:       mediaInput,
-			ReelOptions: reelOptions,
-			ScheduledAt: scheduledAt,
-			PublishNow:  req.PublishNow,
+			AccountID:     req.AccountID,
+			Type:          pubType,
+			Caption:       req.Caption,
+			Media:         mediaInput,
+			ReelOptions:   req.ReelOptions.toEntity(),
+			StoryOptions:  req.StoryOptions.toEntity(),
+			Collaborators: req.Collaborators,
+			ScheduledAt:   scheduledAt,
+			HashtagSetID:  req.HashtagSetID,
+			PublishNow:    req.PublishNow,
 		})
 		if err != nil {
 			handleDomainError(w, err)
@@ -171,11 +238,17 @@ func (h *PublicationHandler) Create() http.HandlerFunc {
 }
 
 // UpdateRequest represents the request body for updating a publication
+
 type UpdateRequest struct {
-	Caption       *string        `json:"caption,omitempty"`
-	Media         []MediaRequest `json:"media,omitempty"`
-	ScheduledAt   *string        `json:"scheduled_at,omitempty"`
-	ClearSchedule bool           `json:"clear_schedule,omitempty"`
+	Caption         *string              `json:"caption,omitempty"`
+	Media           []MediaRequest       `json:"media,omitempty"`
+	ReelOptions     *ReelOptionsRequest  `json:"reel_options,omitempty"`  // Replaces the Reel settings (reels only)
+	StoryOptions    *StoryOptionsRequest `json:"story_options,omitempty"` // Replaces the story link and mentions (stories only)
+	Collaborators   []string             `json:"collaborators,omitempty"` // Replaces the collaborators, [] removes them
+	ScheduledAt     *string              `json:"scheduled_at,omitempty"`
+	ClearSchedule   bool                 `json:"clear_schedule,omitempty"`
+	HashtagSetID    *string              `json:"hashtag_set_id,omitempty"` // Replaces the hashtag set
+	ClearHashtagSet bool                 `json:"clear_hashtag_set,omitempty"`
 }
 
 // Update handles PUT /publications/{id}
@@ -184,8 +257,7 @@ func (h *PublicationHandler) Update() http.HandlerFunc {
 		id := chi.URLParam(r, "id")
 
 		var req UpdateRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			response.BadRequest(w, "invalid JSON")
+		if !decodeJSON(w, r, &req) {
 			return
 		}
 
@@ -211,19 +283,25 @@ func (h *PublicationHandler) Update() http.HandlerFunc {
 					return
 				}
 				mediaInput[i] = policy.MediaInput{
-					URL:   m.URL,
-					Type:  mediaType,
-					Order: m.Order,
+					URL:     m.URL,
+					Type:    mediaType,
+					Order:   m.Order,
+					AltText: m.AltText,
 				}
 			}
 		}
 
 		out, err := h.policy.UpdatePublication(r.Context(), policy.UpdatePublicationInput{
-			ID:            id,
-			Caption:       req.Caption,
-			Media:         mediaInput,
-			ScheduledAt:   scheduledAt,
-			ClearSchedule: req.ClearSchedule,
+			ID:              id,
+			Caption:         req.Caption,
+			Media:           mediaInput,
+			ReelOptions:     req.ReelOptions.toEntity(),
+			StoryOptions:    req.StoryOptions.toEntity(),
+			Collaborators:   req.Collaborators,
+			ScheduledAt:     scheduledAt,
+			ClearSchedule:   req.ClearSchedule,
+			HashtagSetID:    req.HashtagSetID,
+			ClearHashtagSet: req.ClearHashtagSet,
 		})
 		if err != nil {
 			handleDomainError(w, err)
@@ -234,7 +312,49 @@ func (h *PublicationHandler) Update() http.HandlerFunc {
 	}
 }
 
+// ReorderMediaRequest represents the request body for reordering media
+type ReorderMediaRequest struct {
+	Media []MediaOrderRequest `json:"media"`
+}
+
+// MediaOrderRequest assigns a position to an existing media item
+type MediaOrderRequest struct {
+	ID    string `json:"id"`
+	Order int    `json:"order"`
+}
+
+// ReorderMedia handles PUT /publications/{id}/media/order
+func (h *PublicationHandler) ReorderMedia() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+
+		var req ReorderMediaRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+
+		if len(req.Media) == 0 {
+			response.BadRequest(w, "media is required")
+			return
+		}
+
+		order := make([]policy.MediaOrderInput, len(req.Media))
+		for i, m := range req.Media {
+			order[i] = policy.MediaOrderInput{ID: m.ID, Order: m.Order}
+		}
+
+		pub, err := h.policy.ReorderMedia(r.Context(), id, order)
+		if err != nil {
+			handleDomainError(w, err)
+			return
+		}
+
+		response.OK(w, pub)
+	}
+}
+
 // Get handles GET /publications/{id}
+// Answers 304 Not Modified when If-None-Match carries the current ETag
 func (h *PublicationHandler) Get() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id := chi.URLParam(r, "id")
@@ -245,6 +365,24 @@ func (h *PublicationHandler) Get() http.HandlerFunc {
 			return
 		}
 
+		if response.NotModified(w, r, response.WeakETag(pub.ID, pub.UpdatedAt)) {
+			return
+		}
+		response.OK(w, pub)
+	}
+}
+
+// GetByMediaID handles GET /publications/by-media/{instagramMediaId}
+func (h *PublicationHandler) GetByMediaID() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		mediaID := chi.URLParam(r, "instagramMediaId")
+
+		pub, err := h.policy.GetPublicationByMediaID(r.Context(), mediaID)
+		if err != nil {
+			handleDomainError(w, err)
+			return
+		}
+
 		response.OK(w, pub)
 	}
 }
@@ -254,8 +392,19 @@ func (h *PublicationHandler) Delete() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id := chi.URLParam(r, "id")
 
+		permanent := false
+		if p := r.URL.Query().Get("permanent"); p != "" {
+			pb, err := strconv.ParseBool(p)
+			if err != nil {
+				response.BadRequest(w, "invalid permanent")
+				return
+			}
+			permanent = pb
+		}
+
 		err := h.policy.DeletePublication(r.Context(), policy.DeletePublicationInput{
-			ID: id,
+			ID:        id,
+			Permanent: permanent,
 		})
 		if err != nil {
 			handleDomainError(w, err)
@@ -266,12 +415,28 @@ func (h *PublicationHandler) Delete() http.HandlerFunc {
 	}
 }
 
+// Restore handles POST /publications/{id}/restore
+func (h *PublicationHandler) Restore() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+
+		pub, err := h.policy.RestorePublication(r.Context(), id)
+		if err != nil {
+			handleDomainError(w, err)
+			return
+		}
+
+		response.OK(w, pub)
+	}
+}
+
 // ListResponse represents the response for listing publications
 type ListResponse struct {
 	Publications []entity.Publication `json:"publications"`
 	Total        int64                `json:"total"`
 	Limit        int                  `json:"limit"`
 	Offset       int                  `json:"offset"`
+	NextCursor   string               `json:"next_cursor,omitempty"`
 }
 
 // List handles GET /publications
@@ -320,27 +485,29 @@ func (h *PublicationHandler) List() http.HandlerFunc {
 			month = &mi
 		}
 
-		// Parse pagination
-		limit := 50
-		offset := 0
-		if l := q.Get("limit"); l != "" {
-			li, err := strconv.Atoi(l)
-			if err != nil || li < 1 {
-				response.BadRequest(w, "invalid limit")
+		trashed := false
+		if t := q.Get("trashed"); t != "" {
+			tb, err := strconv.ParseBool(t)
+			if err != nil {
+				response.BadRequest(w, "invalid trashed")
 				return
 			}
-			if li > 100 {
-				li = 100
-			}
-			limit = li
+			trashed = tb
 		}
-		if o := q.Get("offset"); o != "" {
-			oi, err := strconv.Atoi(o)
-			if err != nil || oi < 0 {
-				response.BadRequest(w, "invalid offset")
-				return
-			}
-			offset = oi
+
+		// Parse pagination
+		limit, offset, err := h.pages.Parse(r)
+		if err != nil {
+			response.BadRequest(w, err.Error())
+			return
+		}
+
+		// Keyset pagination takes precedence over offset when "after" is present
+		var after *string
+		if q.Has("after") {
+			a := q.Get("after")
+			after = &a
+			offset = 0
 		}
 
 		out, err := h.policy.ListPublications(r.Context(), policy.ListPublicationsInput{
@@ -349,19 +516,35 @@ func (h *PublicationHandler) List() http.HandlerFunc {
 			Status:    status,
 			Year:      year,
 			Month:     month,
+			Trashed:   trashed,
 			Limit:     limit,
 			Offset:    offset,
+			After:     after,
+			SortBy:    entity.PublicationSort(q.Get("sort_by")),
+			Order:     entity.SortOrder(q.Get("order")),
 		})
 		if err != nil {
 			handleDomainError(w, err)
 			return
 		}
 
+		if response.WantsEnvelope(r) {
+			response.Paginated(w, out.Publications, response.Pagination{
+				Total:      response.Total(out.Total),
+				Limit:      limit,
+				Offset:     offset,
+				NextCursor: out.NextCursor,
+				HasMore:    out.NextCursor != "" || int64(offset+len(out.Publications)) < out.Total,
+			})
+			return
+		}
+
 		response.OK(w, ListResponse{
 			Publications: out.Publications,
 			Total:        out.Total,
 			Limit:        limit,
 			Offset:       offset,
+			NextCursor:   out.NextCursor,
 		})
 	}
 }
@@ -371,6 +554,21 @@ func (h *PublicationHandler) PublishNow() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id := chi.URLParam(r, "id")
 
+		if h.publishAsync {
+			pub, err := h.policy.EnqueuePublish(r.Context(), id)
+			if err != nil {
+				handleDomainError(w, err)
+				return
+			}
+			// An already published publication has nothing left to wait for
+			if pub.Status == entity.PublicationStatusPublished {
+				response.OK(w, pub)
+				return
+			}
+			response.Accepted(w, pub)
+			return
+		}
+
 		pub, err := h.policy.PublishNow(r.Context(), id)
 		if err != nil {
 			handleDomainError(w, err)
@@ -392,8 +590,7 @@ func (h *PublicationHandler) Schedule() http.HandlerFunc {
 		id := chi.URLParam(r, "id")
 
 		var req ScheduleRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			response.BadRequest(w, "invalid JSON")
+		if !decodeJSON(w, r, &req) {
 			return
 		}
 
@@ -428,12 +625,71 @@ func (h *PublicationHandler) SaveAsDraft() http.HandlerFunc {
 	}
 }
 
+// Pause handles POST /publications/{id}/pause
+func (h *PublicationHandler) Pause() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+
+		pub, err := h.policy.PausePublication(r.Context(), id)
+		if err != nil {
+			handleDomainError(w, err)
+			return
+		}
+
+		response.OK(w, pub)
+	}
+}
+
+// Resume handles POST /publications/{id}/resume
+func (h *PublicationHandler) Resume() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+
+		pub, err := h.policy.ResumePublication(r.Context(), id)
+		if err != nil {
+			handleDomainError(w, err)
+			return
+		}
+
+		response.OK(w, pub)
+	}
+}
+
+// SetCommentsEnabledRequest represents the request body for turning comments on or off
+type SetCommentsEnabledRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// SetCommentsEnabled handles POST /publications/{id}/comments-enabled
+func (h *PublicationHandler) SetCommentsEnabled() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+
+		var req SetCommentsEnabledRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+		if req.Enabled == nil {
+			response.BadRequest(w, "enabled is required")
+			return
+		}
+
+		pub, err := h.policy.SetCommentsEnabled(r.Context(), id, *req.Enabled)
+		if err != nil {
+			handleDomainError(w, err)
+			return
+		}
+
+		response.OK(w, pub)
+	}
+}
+
 // GetStatistics handles GET /publications/statistics
 func (h *PublicationHandler) GetStatistics() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		accountID := r.URL.Query().Get("account_id")
 		if accountID == "" {
-			response.BadRequest(w, "account_id is required")
+			response.AccountIDRequired(w)
 			return
 		}
 
@@ -447,6 +703,50 @@ func (h *PublicationHandler) GetStatistics() http.HandlerFunc {
 	}
 }
 
+// ScheduledPreviewResponse represents the response for the scheduler dry run
+type ScheduledPreviewResponse struct {
+	Publications []policy.ScheduledPreview `json:"publications"`
+	Total        int                       `json:"total"`
+}
+
+// PreviewScheduled handles GET /publications/scheduled/preview
+// Returns what the next scheduler run would publish without publishing anything
+func (h *PublicationHandler) PreviewScheduled() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		previews, err := h.policy.PreviewScheduledPublications(r.Context())
+		if err != nil {
+			handleDomainError(w, err)
+			return
+		}
+
+		response.OK(w, ScheduledPreviewResponse{
+			Publications: previews,
+			Total:        len(previews),
+		})
+	}
+}
+
+// BackfillPermalinks handles POST /publications/permalinks/backfill
+// Fetches missing permalinks of published publications from Instagram, up to limit per call;
+// offset skips the publications earlier calls failed on
+func (h *PublicationHandler) BackfillPermalinks() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, offset, err := h.pages.Parse(r)
+		if err != nil {
+			response.BadRequest(w, err.Error())
+			return
+		}
+
+		result, err := h.policy.BackfillPermalinks(r.Context(), limit, offset)
+		if err != nil {
+			handleDomainError(w, err)
+			return
+		}
+
+		response.OK(w, result)
+	}
+}
+
 // Helper functions
 
 func parsePublicationType(s string) (entity.PublicationType, error) {
@@ -468,6 +768,10 @@ func parsePublicationStatus(s string) (entity.PublicationStatus, error) {
 		return entity.PublicationStatusDraft, nil
 	case "scheduled":
 		return entity.PublicationStatusScheduled, nil
+	case "paused":
+		return entity.PublicationStatusPaused, nil
+	case "publishing":
+		return entity.PublicationStatusPublishing, nil
 	case "published":
 		return entity.PublicationStatusPublished, nil
 	case "error":
@@ -488,20 +792,104 @@ func parseMediaType(s string) (entity.MediaType, error) {
 	}
 }
 
+// publicationErrorCodes maps publication domain errors to their API error codes
+var publicationErrorCodes = map[error]response.Code{
+	entity.ErrPublicationNotFound:     response.CodePublicationNotFound,
+	entity.ErrPublicationNotEditable:  response.CodePublicationNotEditable,
+	entity.ErrPublicationNotDeletable: response.CodePublicationNotDeletable,
+	entity.ErrPublicationNotPublished: response.CodePublicationNotPublished,
+	entity.ErrPublicationNotScheduled: response.CodePublicationNotScheduled,
+	entity.ErrPublicationNotPaused:    response.CodePublicationNotPaused,
+	entity.ErrEmptyAccountID:          response.CodeAccountIDRequired,
+	entity.ErrNoMedia:                 response.CodeNoMedia,
+	entity.ErrTooManyMediaItems:       response.CodeTooManyMediaItems,
+	entity.ErrTooFewCarouselItems:     response.CodeTooFewCarouselItems,
+	entity.ErrSingleMediaRequired:     response.CodeSingleMediaRequired,
+	entity.ErrCaptionTooLong:          response.CodeCaptionTooLong,
+	entity.ErrScheduledTimeInPast:     response.CodeScheduledTimeInPast,
+	entity.ErrInvalidPublicationType:  response.CodeInvalidPublicationType,
+	entity.ErrInvalidStatus:           response.CodeInvalidStatus,
+	entity.ErrAltTextTooLong:          response.CodeAltTextTooLong,
+	entity.ErrAltTextNotSupported:     response.CodeAltTextNotSupported,
+	entity.ErrInvalidCursor:           response.CodeInvalidCursor,
+	entity.ErrInvalidSort:             response.CodeInvalidSort,
+	entity.ErrInvalidSortOrder:        response.CodeInvalidSortOrder,
+	entity.ErrSortWithCursor:          response.CodeSortWithCursor,
+	entity.ErrMediaOrderMismatch:      response.CodeMediaOrderMismatch,
+	entity.ErrPublishNowScheduled:     response.CodePublishNowScheduled,
+	entity.ErrReelOptionsNotReel:      response.CodeReelOptionsNotReel,
+	entity.ErrStoryOptionsNotStory:    response.CodeStoryOptionsNotStory,
+	entity.ErrInvalidStoryLink:        response.CodeInvalidStoryLink,
+	entity.ErrTooManyMentions:         response.CodeTooManyMentions,
+	entity.ErrCollaboratorsNotPost:    response.CodeCollaboratorsNotPost,
+	entity.ErrTooManyCollaborators:    response.CodeTooManyCollaborators,
+	entity.ErrDailyPublishingLimit:    response.CodeDailyPublishingLimit,
+	entity.ErrTooManyHashtags:         response.CodeTooManyHashtags,
+	entity.ErrHashtagSetNotFound:      response.CodeHashtagSetNotFound,
+	entity.ErrAccountForbidden:        response.CodeForbidden,
+}
+
 func handleDomainError(w http.ResponseWriter, err error) {
+	// Carry the reason, so they are matched through the wrapping
+	switch {
+	case errors.Is(err, entity.ErrContainerFailed):
+		response.CodedError(w, http.StatusUnprocessableEntity, response.CodeContainerFailed, err.Error())
+		return
+	case errors.Is(err, entity.ErrContainerExpired):
+		// The container was dropped; publishing again creates a new one
+		response.CodedError(w, http.StatusUnprocessableEntity, response.CodeContainerExpired, err.Error())
+		return
+	case errors.Is(err, entity.ErrContainerNotReady):
+		response.CodedError(w, http.StatusConflict, response.CodeContainerNotReady, err.Error())
+		return
+	case errors.Is(err, entity.ErrContainerPublished):
+		response.CodedError(w, http.StatusConflict, response.CodeContainerPublished, err.Error())
+		return
+	case errors.Is(err, entity.ErrMediaURLUnusable):
+		response.CodedError(w, http.StatusUnprocessableEntity, response.CodeMediaURLUnusable, err.Error())
+		return
+	case errors.Is(err, entity.ErrStoryStickersRejected):
+		response.CodedError(w, http.StatusUnprocessableEntity, response.CodeStoryStickersRejected, err.Error())
+		return
+	}
+
+	// Instagram failures are wrapped with the upstream error
+	switch {
+	case errors.Is(err, entity.ErrInstagramUnauthorized):
+		response.CodedError(w, http.StatusUnauthorized, response.CodeInstagramUnauthorized, entity.ErrInstagramUnauthorized.Error())
+		return
+	case errors.Is(err, entity.ErrInstagramRateLimited):
+		response.CodedError(w, http.StatusTooManyRequests, response.CodeInstagramRateLimited, entity.ErrInstagramRateLimited.Error())
+		return
+	case errors.Is(err, entity.ErrInstagramUnavailable):
+		response.CodedError(w, http.StatusServiceUnavailable, response.CodeInstagramUnavailable, entity.ErrInstagramUnavailable.Error())
+		return
+	case errors.Is(err, entity.ErrInstagramAPIFailure):
+		response.CodedError(w, http.StatusBadGateway, response.CodeInstagramAPIFailure, err.Error())
+		return
+	}
+
+	code := publicationErrorCodes[err]
 	switch err {
 	case entity.ErrPublicationNotFound:
-		response.NotFound(w, err.Error())
-	case entity.ErrPublicationNotEditable, entity.ErrPublicationNotDeletable:
-		response.Error(w, http.StatusConflict, err.Error())
-	case entity.ErrEmptyAccountID, entity.ErrNoMedia, entity.ErrTooManyMediaItems,
+		response.CodedError(w, http.StatusNotFound, code, err.Error())
+	case entity.ErrPublicationNotEditable, entity.ErrPublicationNotDeletable, entity.ErrPublicationNotPublished,
+		entity.ErrPublicationNotScheduled, entity.ErrPublicationNotPaused:
+		response.CodedError(w, http.StatusConflict, code, err.Error())
+	case entity.ErrEmptyAccountID, entity.ErrNoMedia, entity.ErrTooManyMediaItems, entity.ErrTooFewCarouselItems,
 		entity.ErrSingleMediaRequired, entity.ErrCaptionTooLong, entity.ErrScheduledTimeInPast,
-		entity.ErrInvalidPublicationType, entity.ErrInvalidStatus:
-		response.BadRequest(w, err.Error())
-	case entity.ErrInstagramUnauthorized:
-		response.Unauthorized(w, err.Error())
-	case entity.ErrInstagramRateLimited, entity.ErrDailyPublishingLimit:
-		response.Error(w, http.StatusTooManyRequests, err.Error())
+		entity.ErrInvalidPublicationType, entity.ErrInvalidStatus,
+		entity.ErrAltTextTooLong, entity.ErrAltTextNotSupported, entity.ErrInvalidCursor,
+		entity.ErrInvalidSort, entity.ErrInvalidSortOrder, entity.ErrSortWithCursor,
+		entity.ErrMediaOrderMismatch, entity.ErrPublishNowScheduled, entity.ErrReelOptionsNotReel,
+		entity.ErrCollaboratorsNotPost, entity.ErrTooManyCollaborators,
+		entity.ErrTooManyHashtags, entity.ErrHashtagSetNotFound,
+		entity.ErrStoryOptionsNotStory, entity.ErrInvalidStoryLink, entity.ErrTooManyMentions:
+		response.CodedError(w, http.StatusBadRequest, code, err.Error())
+	case entity.ErrDailyPublishingLimit:
+		response.CodedError(w, http.StatusTooManyRequests, code, err.Error())
+	case entity.ErrAccountForbidden:
+		response.CodedError(w, http.StatusForbidden, code, err.Error())
 	default:
 		response.InternalError(w, "internal server error")
 	}
@@ -0,0 +1,235 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	pubEntity "github.com/vadim/neo-metric/internal/domain/publication/entity"
+	"github.com/vadim/neo-metric/internal/domain/publication/policy"
+	"github.com/vadim/neo-metric/internal/httpx/response"
+)
+
+// creatingPolicy echoes the created publication back, recording the input
+type creatingPolicy struct {
+	PublicationPolicy
+	got *policy.CreatePublicationInput
+}
+
+func (p *creatingPolicy) CreatePublication(_ context.Context, in policy.CreatePublicationInput) (*policy.CreatePublicationOutput, error) {
+	p.got = &in
+	return &policy.CreatePublicationOutput{Publication: &pubEntity.Publication{
+		ID:          "pub-1",
+		AccountID:   in.AccountID,
+		Type:        in.Type,
+		Status:      pubEntity.PublicationStatusDraft,
+		ReelOptions: in.ReelOptions,
+	}}, nil
+}
+
+func postPublication(t *testing.T, p PublicationPolicy, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := chi.NewRouter()
+	NewPublicationHandler(p).RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodPost, "/publications/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCreateReelWithOptions(t *testing.T) {
+	p := &creatingPolicy{}
+	rec := postPublication(t, p, `{
+		"account_id": "acc-1",
+		"type": "reel",
+		"media": [{"url": "https://cdn.example.com/reel.mp4", "type": "video", "order": 0}],
+		"reel_options": {
+			"share_to_feed": false,
+			"cover_url": "https://cdn.example.com/cover.jpg",
+			"collaborator_usernames": ["alice", "bob"]
+		}
+	}`)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", rec.Code, rec.Body)
+	}
+	opts := p.got.ReelOptions
+	if opts == nil || opts.CoverURL != "https://cdn.example.com/cover.jpg" ||
+		!reflect.DeepEqual(opts.CollaboratorUsernames, []string{"alice", "bob"}) ||
+		opts.ShareToFeed == nil || *opts.ShareToFeed {
+		t.Fatalf("policy got reel options %+v", opts)
+	}
+
+	var pub pubEntity.Publication
+	if err := json.NewDecoder(rec.Body).Decode(&pub); err != nil {
+		t.Fatalf("decoding body: %v", err)
+	}
+	if pub.ReelOptions == nil || pub.ReelOptions.CoverURL != opts.CoverURL ||
+		!reflect.DeepEqual(pub.ReelOptions.CollaboratorUsernames, opts.CollaboratorUsernames) {
+		t.Errorf("response reel_options = %+v", pub.ReelOptions)
+	}
+}
+
+func TestCreateRejectsReelOptionsForPost(t *testing.T) {
+	p := &creatingPolicy{}
+	rec := postPublication(t, p, `{
+		"account_id": "acc-1",
+		"type": "post",
+		"media": [{"url": "https://cdn.example.com/a.jpg", "type": "image", "order": 0}],
+		"reel_options": {"cover_url": "https://cdn.example.com/cover.jpg"}
+	}`)
+
+	var body struct {
+		Error response.ErrorBody `json:"error"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding body: %v", err)
+	}
+	if rec.Code != http.StatusBadRequest || body.Error.Code != response.CodeReelOptionsNotReel {
+		t.Errorf("got %d %s, want 400 %s", rec.Code, body.Error.Code, response.CodeReelOptionsNotReel)
+	}
+	if p.got != nil {
+		t.Error("policy was called for an invalid request")
+	}
+}
+
+// queueingPolicy queues publications, failing the test if anything publishes synchronously
+type queueingPolicy struct {
+	PublicationPolicy
+	t      *testing.T
+	status pubEntity.PublicationStatus
+}
+
+func (p *queueingPolicy) EnqueuePublish(_ context.Context, id string) (*pubEntity.Publication, error) {
+	return &pubEntity.Publication{ID: id, Status: p.status}, nil
+}
+
+func (p *queueingPolicy) PublishNow(context.Context, string) (*pubEntity.Publication, error) {
+	p.t.Fatal("async handler published synchronously")
+	return nil, nil
+}
+
+func TestPublishAsyncReturnsAccepted(t *testing.T) {
+	tests := []struct {
+		status     pubEntity.PublicationStatus
+		wantStatus int
+	}{
+		{pubEntity.PublicationStatusPublishing, http.StatusAccepted},
+		{pubEntity.PublicationStatusPublished, http.StatusOK}, // Nothing left to wait for
+	}
+	for _, tc := range tests {
+		t.Run(string(tc.status), func(t *testing.T) {
+			r := chi.NewRouter()
+			NewPublicationHandler(&queueingPolicy{t: t, status: tc.status}).WithAsyncPublish(true).RegisterRoutes(r)
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/publications/pub-1/publish", nil))
+
+			var pub pubEntity.Publication
+			if err := json.NewDecoder(rec.Body).Decode(&pub); err != nil {
+				t.Fatalf("decoding body: %v", err)
+			}
+			if rec.Code != tc.wantStatus || pub.Status != tc.status {
+				t.Errorf("got %d %s, want %d %s", rec.Code, pub.Status, tc.wantStatus, tc.status)
+			}
+		})
+	}
+}
+
+func TestCreateListsAllInvalidFields(t *testing.T) {
+	p := &creatingPolicy{}
+	rec := postPublication(t, p, `{
+		"type": "album",
+		"scheduled_at": "tomorrow",
+		"media": [
+			{"url": "https://cdn.example.com/a.jpg", "type": "image"},
+			{"url": "https://cdn.example.com/b.gif", "type": "gif"}
+		]
+	}`)
+
+	var body struct {
+		Error response.ErrorBody `json:"error"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding body: %v", err)
+	}
+	if rec.Code != http.StatusUnprocessableEntity || body.Error.Code != response.CodeValidationFailed {
+		t.Fatalf("got %d %s, want 422 %s", rec.Code, body.Error.Code, response.CodeValidationFailed)
+	}
+
+	got := make(map[string]response.Code)
+	for _, f := range body.Error.Fields {
+		got[f.Field] = f.Code
+	}
+	want := map[string]response.Code{
+		"account_id":    response.CodeRequired,
+		"type":          response.CodeInvalid,
+		"scheduled_at":  response.CodeInvalid,
+		"media[1].type": response.CodeInvalid,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fields = %v, want %v", got, want)
+	}
+	if p.got != nil {
+		t.Error("policy was called for an invalid request")
+	}
+}
+
+// storedPolicy returns one stored publication
+type storedPolicy struct {
+	PublicationPolicy
+	pub pubEntity.Publication
+}
+
+func (p *storedPolicy) GetPublication(_ context.Context, id string) (*pubEntity.Publication, error) {
+	if id != p.pub.ID {
+		return nil, pubEntity.ErrPublicationNotFound
+	}
+	pub := p.pub
+	return &pub, nil
+}
+
+func TestGetHonorsIfNoneMatch(t *testing.T) {
+	p := &storedPolicy{pub: pubEntity.Publication{
+		ID:        "pub-1",
+		Status:    pubEntity.PublicationStatusDraft,
+		UpdatedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+	}}
+	r := chi.NewRouter()
+	NewPublicationHandler(p).RegisterRoutes(r)
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/publications/pub-1", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("first GET = %d with ETag %q, want 200 with an ETag", first.Code, etag)
+	}
+
+	second := get(etag)
+	if second.Code != http.StatusNotModified || second.Body.Len() != 0 {
+		t.Fatalf("GET with the ETag = %d with %d body bytes, want an empty 304", second.Code, second.Body.Len())
+	}
+
+	p.pub.UpdatedAt = p.pub.UpdatedAt.Add(time.Second)
+	third := get(etag)
+	if third.Code != http.StatusOK || third.Header().Get("ETag") == etag {
+		t.Errorf("GET after an update = %d with ETag %q, want 200 with a new ETag", third.Code, third.Header().Get("ETag"))
+	}
+}
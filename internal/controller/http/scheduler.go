@@ -0,0 +1,75 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/vadim/neo-metric/internal/httpx/response"
+)
+
+// SchedulerStatus represents the state of a background scheduler
+type SchedulerStatus struct {
+	Name              string     `json:"name"`
+	Enabled           bool       `json:"enabled"`
+	Interval          string     `json:"interval"` // Go duration, e.g. "5m0s"
+	Running           bool       `json:"running"`  // A run is in progress
+	LastRunAt         *time.Time `json:"last_run_at,omitempty"`
+	LastRunDurationMs int64      `json:"last_run_duration_ms"`
+	Stalled           bool       `json:"stalled"`   // No run started for more than two intervals
+	Throttled         bool       `json:"throttled"` // Runs are paused because Instagram API usage is high
+}
+
+// InstagramUsage is the latest Instagram rate limit usage report
+type InstagramUsage struct {
+	Percent   int        `json:"percent"`   // Highest usage across the reported limits
+	Throttled bool       `json:"throttled"` // Sync schedulers are paused
+	RegainAt  *time.Time `json:"regain_access_at,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// SchedulerStatusResponse lists the background schedulers
+type SchedulerStatusResponse struct {
+	Healthy        bool              `json:"healthy"` // No scheduler is stalled
+	Schedulers     []SchedulerStatus `json:"schedulers"`
+	InstagramUsage *InstagramUsage   `json:"instagram_usage,omitempty"` // Absent until a response reported it
+}
+
+// SchedulerStatusProvider defines the interface for reporting background schedulers
+type SchedulerStatusProvider interface {
+	SchedulerStatuses() []SchedulerStatus
+	InstagramUsage() *InstagramUsage
+}
+
+// SchedulerHandler handles HTTP requests for background scheduler state
+type SchedulerHandler struct {
+	provider SchedulerStatusProvider
+}
+
+// NewSchedulerHandler creates a new scheduler handler
+func NewSchedulerHandler(provider SchedulerStatusProvider) *SchedulerHandler {
+	return &SchedulerHandler{provider: provider}
+}
+
+// RegisterRoutes registers scheduler routes
+func (h *SchedulerHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/schedulers/status", h.GetStatus())
+}
+
+// GetStatus handles GET /schedulers/status
+func (h *SchedulerHandler) GetStatus() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp := SchedulerStatusResponse{
+			Healthy:        true,
+			Schedulers:     h.provider.SchedulerStatuses(),
+			InstagramUsage: h.provider.InstagramUsage(),
+		}
+		for _, s := range resp.Schedulers {
+			if s.Stalled {
+				resp.Healthy = false
+			}
+		}
+		response.OK(w, resp)
+	}
+}
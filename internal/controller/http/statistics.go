@@ -0,0 +1,196 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	commentEntity "github.com/vadim/neo-metric/internal/domain/comment/entity"
+	commentPolicy "github.com/vadim/neo-metric/internal/domain/comment/policy"
+	directEntity "github.com/vadim/neo-metric/internal/domain/direct/entity"
+	directPolicy "github.com/vadim/neo-metric/internal/domain/direct/policy"
+	"github.com/vadim/neo-metric/internal/httpx/response"
+)
+
+const (
+	// maxBatchStatisticsAccounts is the maximum number of accounts in a single batch request
+	maxBatchStatisticsAccounts = 50
+	// batchStatisticsConcurrency is how many accounts are read at the same time
+	batchStatisticsConcurrency = 5
+	// batchStatisticsTopPosts is the number of top posts in each account's comment statistics
+	batchStatisticsTopPosts = 5
+)
+
+// CommentStatisticsGetter defines the interface for reading comment statistics
+type CommentStatisticsGetter interface {
+	GetStatistics(ctx context.Context, in commentPolicy.GetStatisticsInput) (*commentEntity.CommentStatistics, error)
+}
+
+// DirectStatisticsGetter defines the interface for reading direct message statistics
+type DirectStatisticsGetter interface {
+	GetStatistics(ctx context.Context, in directPolicy.GetStatisticsInput) (*directEntity.Statistics, error)
+}
+
+// StatisticsHandler handles HTTP requests for statistics across several accounts
+type StatisticsHandler struct {
+	comments CommentStatisticsGetter
+	direct   DirectStatisticsGetter // nil when direct messages are disabled
+}
+
+// NewStatisticsHandler creates a new statistics handler; direct may be nil
+func NewStatisticsHandler(comments CommentStatisticsGetter, direct DirectStatisticsGetter) *StatisticsHandler {
+	return &StatisticsHandler{comments: comments, direct: direct}
+}
+
+// RegisterRoutes registers statistics routes
+func (h *StatisticsHandler) RegisterRoutes(r chi.Router) {
+	r.Post("/statistics/batch", h.Batch())
+}
+
+// BatchStatisticsRequest represents the request body for batch statistics
+type BatchStatisticsRequest struct {
+	AccountIDs []string `json:"account_ids"`
+	StartDate  string   `json:"start_date,omitempty"` // YYYY-MM-DD, defaults to 30 days before end_date
+	EndDate    string   `json:"end_date,omitempty"`   // YYYY-MM-DD, defaults to today
+}
+
+// AccountStatistics is the combined statistics of one account.
+// A section that could not be read is omitted and its error is set instead.
+type AccountStatistics struct {
+	Comments      *commentEntity.CommentStatistics `json:"comments,omitempty"`
+	Direct        *directEntity.Statistics         `json:"direct,omitempty"`
+	CommentsError string                           `json:"comments_error,omitempty"`
+	DirectError   string                           `json:"direct_error,omitempty"`
+}
+
+// failed reports whether a section of the account could not be read
+func (s AccountStatistics) failed() bool {
+	return s.CommentsError != "" || s.DirectError != ""
+}
+
+// BatchStatisticsResponse represents the response for batch statistics
+type BatchStatisticsResponse struct {
+	Accounts  map[string]AccountStatistics `json:"accounts"`
+	Succeeded int                          `json:"succeeded"`
+	Failed    int                          `json:"failed"` // Accounts with at least one section missing
+}
+
+// Batch handles POST /statistics/batch
+// Reads comment and direct statistics of every account concurrently. An account that
+// fails is reported in the response instead of failing the whole request.
+func (h *StatisticsHandler) Batch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req BatchStatisticsRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+
+		accountIDs := uniqueNonEmpty(req.AccountIDs)
+		if len(accountIDs) == 0 {
+			response.BadRequest(w, "account_ids is required")
+			return
+		}
+		if len(accountIDs) > maxBatchStatisticsAccounts {
+			response.BadRequest(w, fmt.Sprintf("too many account_ids, at most %d per request", maxBatchStatisticsAccounts))
+			return
+		}
+
+		endDate := time.Now()
+		if req.EndDate != "" {
+			parsed, err := time.Parse("2006-01-02", req.EndDate)
+			if err != nil {
+				response.BadRequest(w, "invalid end_date format, expected YYYY-MM-DD")
+				return
+			}
+			endDate = parsed.Add(24*time.Hour - time.Second) // End of day
+		}
+		startDate := endDate.AddDate(0, 0, -30)
+		if req.StartDate != "" {
+			parsed, err := time.Parse("2006-01-02", req.StartDate)
+			if err != nil {
+				response.BadRequest(w, "invalid start_date format, expected YYYY-MM-DD")
+				return
+			}
+			startDate = parsed
+		}
+		if startDate.After(endDate) {
+			response.BadRequest(w, "start_date must not be after end_date")
+			return
+		}
+
+		results := make([]AccountStatistics, len(accountIDs))
+		sem := make(chan struct{}, batchStatisticsConcurrency)
+		var wg sync.WaitGroup
+		for i, accountID := range accountIDs {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				results[i] = h.accountStatistics(r.Context(), accountID, startDate, endDate)
+			}()
+		}
+		wg.Wait()
+
+		resp := BatchStatisticsResponse{Accounts: make(map[string]AccountStatistics, len(accountIDs))}
+		for i, accountID := range accountIDs {
+			resp.Accounts[accountID] = results[i]
+			if results[i].failed() {
+				resp.Failed++
+			} else {
+				resp.Succeeded++
+			}
+		}
+		response.OK(w, resp)
+	}
+}
+
+// accountStatistics reads the statistics sections of one account
+func (h *StatisticsHandler) accountStatistics(ctx context.Context, accountID string, startDate, endDate time.Time) AccountStatistics {
+	var stats AccountStatistics
+
+	comments, err := h.comments.GetStatistics(ctx, commentPolicy.GetStatisticsInput{
+		AccountID:     accountID,
+		StartDate:     &startDate,
+		EndDate:       &endDate,
+		TopPostsLimit: batchStatisticsTopPosts,
+	})
+	if err != nil {
+		stats.CommentsError = err.Error()
+	} else {
+		stats.Comments = comments
+	}
+
+	if h.direct != nil {
+		direct, err := h.direct.GetStatistics(ctx, directPolicy.GetStatisticsInput{
+			AccountID: accountID,
+			StartDate: startDate,
+			EndDate:   endDate,
+		})
+		if err != nil {
+			stats.DirectError = err.Error()
+		} else {
+			stats.Direct = direct
+		}
+	}
+
+	return stats
+}
+
+// uniqueNonEmpty returns the non-empty values in order of first appearance
+func uniqueNonEmpty(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var out []string
+	for _, v := range values {
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
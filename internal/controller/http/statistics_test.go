@@ -0,0 +1,151 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	commentEntity "github.com/vadim/neo-metric/internal/domain/comment/entity"
+	commentPolicy "github.com/vadim/neo-metric/internal/domain/comment/policy"
+	directEntity "github.com/vadim/neo-metric/internal/domain/direct/entity"
+	directPolicy "github.com/vadim/neo-metric/internal/domain/direct/policy"
+)
+
+// fakeStatistics serves fixed per-account statistics and tracks how many reads run at once
+type fakeStatistics struct {
+	comments map[string]int64 // Total comments by account
+	failing  map[string]error // Accounts whose direct statistics fail
+
+	mu                sync.Mutex
+	active, maxActive int
+}
+
+func (f *fakeStatistics) enter() func() {
+	f.mu.Lock()
+	f.active++
+	f.maxActive = max(f.maxActive, f.active)
+	f.mu.Unlock()
+	return func() {
+		f.mu.Lock()
+		f.active--
+		f.mu.Unlock()
+	}
+}
+
+type fakeCommentStatistics struct{ *fakeStatistics }
+
+func (f fakeCommentStatistics) GetStatistics(_ context.Context, in commentPolicy.GetStatisticsInput) (*commentEntity.CommentStatistics, error) {
+	defer f.enter()()
+	total, ok := f.comments[in.AccountID]
+	if !ok {
+		return nil, commentEntity.ErrAccountForbidden
+	}
+	return &commentEntity.CommentStatistics{TotalComments: total}, nil
+}
+
+type fakeDirectStatistics struct{ *fakeStatistics }
+
+func (f fakeDirectStatistics) GetStatistics(_ context.Context, in directPolicy.GetStatisticsInput) (*directEntity.Statistics, error) {
+	defer f.enter()()
+	if err := f.failing[in.AccountID]; err != nil {
+		return nil, err
+	}
+	return &directEntity.Statistics{TotalDialogs: int(f.comments[in.AccountID])}, nil
+}
+
+func postBatchStatistics(t *testing.T, h *StatisticsHandler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := chi.NewRouter()
+	h.RegisterRoutes(r)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/statistics/batch", strings.NewReader(body)))
+	return rec
+}
+
+func TestBatchStatistics(t *testing.T) {
+	fake := &fakeStatistics{
+		comments: map[string]int64{"acc1": 10, "acc2": 20, "acc3": 30},
+		failing:  map[string]error{"acc2": errors.New("database unavailable")},
+	}
+	h := NewStatisticsHandler(fakeCommentStatistics{fake}, fakeDirectStatistics{fake})
+
+	rec := postBatchStatistics(t, h, `{"account_ids":["acc1","acc2","acc3","unknown","acc1"],"start_date":"2025-06-01","end_date":"2025-06-30"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var resp BatchStatisticsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if len(resp.Accounts) != 4 || resp.Succeeded != 2 || resp.Failed != 2 {
+		t.Errorf("got %d accounts, %d succeeded, %d failed; want 4, 2, 2", len(resp.Accounts), resp.Succeeded, resp.Failed)
+	}
+	if acc := resp.Accounts["acc3"]; acc.Comments == nil || acc.Comments.TotalComments != 30 || acc.Direct == nil || acc.Direct.TotalDialogs != 30 {
+		t.Errorf("acc3 = %+v, want both sections", acc)
+	}
+	// A failing section leaves the other one in place
+	if acc := resp.Accounts["acc2"]; acc.Comments == nil || acc.Comments.TotalComments != 20 || acc.Direct != nil || acc.DirectError != "database unavailable" {
+		t.Errorf("acc2 = %+v, want comments and the direct error", acc)
+	}
+	if acc := resp.Accounts["unknown"]; acc.Comments != nil || acc.CommentsError == "" {
+		t.Errorf("unknown = %+v, want a comments error", acc)
+	}
+}
+
+func TestBatchStatisticsBoundsConcurrency(t *testing.T) {
+	fake := &fakeStatistics{comments: map[string]int64{}}
+	ids := make([]string, maxBatchStatisticsAccounts)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("acc%d", i)
+		fake.comments[ids[i]] = int64(i)
+	}
+	body, _ := json.Marshal(BatchStatisticsRequest{AccountIDs: ids})
+
+	rec := postBatchStatistics(t, NewStatisticsHandler(fakeCommentStatistics{fake}, nil), string(body))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if fake.maxActive > batchStatisticsConcurrency {
+		t.Errorf("%d accounts read at once, want at most %d", fake.maxActive, batchStatisticsConcurrency)
+	}
+	var resp BatchStatisticsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	// Without direct messages the account has only comment statistics
+	if resp.Succeeded != maxBatchStatisticsAccounts || resp.Accounts[ids[0]].Direct != nil {
+		t.Errorf("succeeded = %d, first account = %+v", resp.Succeeded, resp.Accounts[ids[0]])
+	}
+}
+
+func TestBatchStatisticsRejectsInvalidRequest(t *testing.T) {
+	fake := &fakeStatistics{}
+	h := NewStatisticsHandler(fakeCommentStatistics{fake}, fakeDirectStatistics{fake})
+	ids := make([]string, maxBatchStatisticsAccounts+1)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("acc%d", i)
+	}
+	tooMany, _ := json.Marshal(BatchStatisticsRequest{AccountIDs: ids})
+
+	for _, body := range []string{
+		`{"account_ids":[]}`,
+		`{"account_ids":["", ""]}`,
+		`{"account_ids":["acc1"],"start_date":"01.06.2025"}`,
+		`{"account_ids":["acc1"],"start_date":"2025-07-01","end_date":"2025-06-01"}`,
+		string(tooMany),
+	} {
+		if rec := postBatchStatistics(t, h, body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, rec.Code)
+		}
+	}
+}
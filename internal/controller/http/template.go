@@ -2,15 +2,15 @@ package http
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
-	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/vadim/neo-metric/internal/domain/template/entity"
 	"github.com/vadim/neo-metric/internal/domain/template/policy"
 	"github.com/vadim/neo-metric/internal/httpx/response"
+	"github.com/vadim/neo-metric/internal/httpx/validate"
 )
 
 // TemplatePolicy defines the interface for template operations
@@ -21,16 +21,24 @@ type TemplatePolicy interface {
 	Delete(ctx context.Context, id, accountID string) error
 	List(ctx context.Context, in policy.ListInput) (*policy.ListOutput, error)
 	IncrementUsage(ctx context.Context, id, accountID string) error
+	GetAnalytics(ctx context.Context, in policy.AnalyticsInput) (*entity.TemplateAnalytics, error)
 }
 
 // TemplateHandler handles HTTP requests for templates
 type TemplateHandler struct {
 	policy TemplatePolicy
+	pages  response.PageLimits
 }
 
 // NewTemplateHandler creates a new template handler
 func NewTemplateHandler(p TemplatePolicy) *TemplateHandler {
-	return &TemplateHandler{policy: p}
+	return &TemplateHandler{policy: p, pages: response.DefaultPageLimits}
+}
+
+// WithPageLimits sets the page sizes of the template listing
+func (h *TemplateHandler) WithPageLimits(l response.PageLimits) *TemplateHandler {
+	h.pages = l
+	return h
 }
 
 // RegisterRoutes registers template routes
@@ -42,6 +50,9 @@ func (h *TemplateHandler) RegisterRoutes(r chi.Router) {
 		// Create template
 		r.Post("/", h.Create())
 
+		// Usage analytics
+		r.Get("/analytics", h.GetAnalytics())
+
 		// Get template by ID
 		r.Get("/{templateId}", h.GetByID())
 
@@ -70,21 +81,15 @@ type CreateTemplateRequest struct {
 func (h *TemplateHandler) Create() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req CreateTemplateRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			response.BadRequest(w, "invalid JSON")
+		if !decodeJSON(w, r, &req) {
 			return
 		}
 
-		if req.AccountID == "" {
-			response.BadRequest(w, "account_id is required")
-			return
-		}
-		if req.Title == "" {
-			response.BadRequest(w, "title is required")
-			return
-		}
-		if req.Content == "" {
-			response.BadRequest(w, "content is required")
+		var errs validate.Errors
+		errs.Required("account_id", req.AccountID != "")
+		errs.Required("title", req.Title != "")
+		errs.Required("content", req.Content != "")
+		if errs.Respond(w) {
 			return
 		}
 		if req.Type == "" {
@@ -109,13 +114,14 @@ func (h *TemplateHandler) Create() http.HandlerFunc {
 }
 
 // GetByID handles GET /templates/{templateId}
+// Answers 304 Not Modified when If-None-Match carries the current ETag
 func (h *TemplateHandler) GetByID() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		templateID := chi.URLParam(r, "templateId")
 		accountID := r.URL.Query().Get("account_id")
 
 		if accountID == "" {
-			response.BadRequest(w, "account_id is required")
+			response.AccountIDRequired(w)
 			return
 		}
 
@@ -125,6 +131,9 @@ func (h *TemplateHandler) GetByID() http.HandlerFunc {
 			return
 		}
 
+		if response.NotModified(w, r, response.WeakETag(tmpl.ID, tmpl.UpdatedAt)) {
+			return
+		}
 		response.OK(w, tmpl)
 	}
 }
@@ -145,13 +154,12 @@ func (h *TemplateHandler) Update() http.HandlerFunc {
 		templateID := chi.URLParam(r, "templateId")
 
 		var req UpdateTemplateRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			response.BadRequest(w, "invalid JSON")
+		if !decodeJSON(w, r, &req) {
 			return
 		}
 
 		if req.AccountID == "" {
-			response.BadRequest(w, "account_id is required")
+			response.AccountIDRequired(w)
 			return
 		}
 
@@ -180,7 +188,7 @@ func (h *TemplateHandler) Delete() http.HandlerFunc {
 		accountID := r.URL.Query().Get("account_id")
 
 		if accountID == "" {
-			response.BadRequest(w, "account_id is required")
+			response.AccountIDRequired(w)
 			return
 		}
 
@@ -205,7 +213,7 @@ func (h *TemplateHandler) List() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		accountID := r.URL.Query().Get("account_id")
 		if accountID == "" {
-			response.BadRequest(w, "account_id is required")
+			response.AccountIDRequired(w)
 			return
 		}
 
@@ -216,21 +224,10 @@ func (h *TemplateHandler) List() http.HandlerFunc {
 			templateType = &tt
 		}
 
-		limit := 50
-		if l := r.URL.Query().Get("limit"); l != "" {
-			if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
-				limit = parsed
-				if limit > 100 {
-					limit = 100
-				}
-			}
-		}
-
-		offset := 0
-		if o := r.URL.Query().Get("offset"); o != "" {
-			if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
-				offset = parsed
-			}
+		limit, offset, err := h.pages.Parse(r)
+		if err != nil {
+			response.BadRequest(w, err.Error())
+			return
 		}
 
 		sortBy := r.URL.Query().Get("sort_by")
@@ -243,6 +240,7 @@ func (h *TemplateHandler) List() http.HandlerFunc {
 		result, err := h.policy.List(r.Context(), policy.ListInput{
 			AccountID: accountID,
 			Type:      templateType,
+			Query:     r.URL.Query().Get("q"),
 			Limit:     limit,
 			Offset:    offset,
 			SortBy:    sortBy,
@@ -253,6 +251,16 @@ func (h *TemplateHandler) List() http.HandlerFunc {
 			return
 		}
 
+		if response.WantsEnvelope(r) {
+			response.Paginated(w, result.Templates, response.Pagination{
+				Total:   response.Total(result.Total),
+				Limit:   limit,
+				Offset:  offset,
+				HasMore: int64(offset+len(result.Templates)) < result.Total,
+			})
+			return
+		}
+
 		response.OK(w, ListTemplatesResponse{
 			Templates: result.Templates,
 			Total:     result.Total,
@@ -271,13 +279,12 @@ func (h *TemplateHandler) IncrementUsage() http.HandlerFunc {
 		templateID := chi.URLParam(r, "templateId")
 
 		var req IncrementUsageRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			response.BadRequest(w, "invalid JSON")
+		if !decodeJSON(w, r, &req) {
 			return
 		}
 
 		if req.AccountID == "" {
-			response.BadRequest(w, "account_id is required")
+			response.AccountIDRequired(w)
 			return
 		}
 
@@ -291,22 +298,77 @@ func (h *TemplateHandler) IncrementUsage() http.HandlerFunc {
 	}
 }
 
+// GetAnalytics handles GET /templates/analytics
+func (h *TemplateHandler) GetAnalytics() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accountID := r.URL.Query().Get("account_id")
+		if accountID == "" {
+			response.AccountIDRequired(w)
+			return
+		}
+
+		in := policy.AnalyticsInput{AccountID: accountID}
+
+		if t := r.URL.Query().Get("type"); t != "" {
+			tt := entity.TemplateType(t)
+			in.Type = &tt
+		}
+
+		// Optional date range on last use (inclusive, YYYY-MM-DD)
+		if s := r.URL.Query().Get("start_date"); s != "" {
+			parsed, err := time.Parse("2006-01-02", s)
+			if err != nil {
+				response.BadRequest(w, "invalid start_date format, expected YYYY-MM-DD")
+				return
+			}
+			in.From = &parsed
+		}
+		if e := r.URL.Query().Get("end_date"); e != "" {
+			parsed, err := time.Parse("2006-01-02", e)
+			if err != nil {
+				response.BadRequest(w, "invalid end_date format, expected YYYY-MM-DD")
+				return
+			}
+			endOfDay := parsed.Add(24*time.Hour - time.Second)
+			in.To = &endOfDay
+		}
+
+		analytics, err := h.policy.GetAnalytics(r.Context(), in)
+		if err != nil {
+			handleTemplateError(w, err)
+			return
+		}
+
+		response.OK(w, analytics)
+	}
+}
+
+// templateErrorCodes maps template domain errors to their API error codes
+var templateErrorCodes = map[error]response.Code{
+	entity.ErrTemplateNotFound:    response.CodeTemplateNotFound,
+	entity.ErrEmptyTitle:          response.CodeEmptyTitle,
+	entity.ErrEmptyContent:        response.CodeEmptyContent,
+	entity.ErrInvalidTemplateType: response.CodeInvalidTemplateType,
+	entity.ErrTitleTooLong:        response.CodeTitleTooLong,
+	entity.ErrContentTooLong:      response.CodeContentTooLong,
+	entity.ErrTooManyImages:       response.CodeTooManyImages,
+	entity.ErrInvalidDateRange:    response.CodeInvalidDateRange,
+	entity.ErrNoHashtags:          response.CodeNoHashtags,
+	entity.ErrTooManyHashtags:     response.CodeTooManyHashtags,
+	entity.ErrAccountForbidden:    response.CodeForbidden,
+}
+
 func handleTemplateError(w http.ResponseWriter, err error) {
+	code := templateErrorCodes[err]
 	switch err {
 	case entity.ErrTemplateNotFound:
-		response.NotFound(w, err.Error())
-	case entity.ErrEmptyTitle:
-		response.BadRequest(w, err.Error())
-	case entity.ErrEmptyContent:
-		response.BadRequest(w, err.Error())
-	case entity.ErrInvalidTemplateType:
-		response.BadRequest(w, err.Error())
-	case entity.ErrTitleTooLong:
-		response.BadRequest(w, err.Error())
-	case entity.ErrContentTooLong:
-		response.BadRequest(w, err.Error())
-	case entity.ErrTooManyImages:
-		response.BadRequest(w, err.Error())
+		response.CodedError(w, http.StatusNotFound, code, err.Error())
+	case entity.ErrEmptyTitle, entity.ErrEmptyContent, entity.ErrInvalidTemplateType,
+		entity.ErrTitleTooLong, entity.ErrContentTooLong, entity.ErrTooManyImages,
+		entity.ErrInvalidDateRange, entity.ErrNoHashtags, entity.ErrTooManyHashtags:
+		response.CodedError(w, http.StatusBadRequest, code, err.Error())
+	case entity.ErrAccountForbidden:
+		response.CodedError(w, http.StatusForbidden, code, err.Error())
 	default:
 		response.InternalError(w, "internal server error")
 	}
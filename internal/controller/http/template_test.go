@@ -0,0 +1,63 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/vadim/neo-metric/internal/domain/template/entity"
+)
+
+// storedTemplatePolicy returns one stored template
+type storedTemplatePolicy struct {
+	TemplatePolicy
+	tmpl entity.Template
+}
+
+func (p *storedTemplatePolicy) GetByID(_ context.Context, id, accountID string) (*entity.Template, error) {
+	if id != p.tmpl.ID || accountID != p.tmpl.AccountID {
+		return nil, entity.ErrTemplateNotFound
+	}
+	tmpl := p.tmpl
+	return &tmpl, nil
+}
+
+func TestGetTemplateHonorsIfNoneMatch(t *testing.T) {
+	p := &storedTemplatePolicy{tmpl: entity.Template{
+		ID:        "tmpl-1",
+		AccountID: "acc-1",
+		Title:     "Greeting",
+		UpdatedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+	}}
+	r := chi.NewRouter()
+	NewTemplateHandler(p).RegisterRoutes(r)
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/templates/tmpl-1?account_id=acc-1", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("first GET = %d with ETag %q, want 200 with an ETag", first.Code, etag)
+	}
+
+	second := get(etag)
+	if second.Code != http.StatusNotModified || second.Body.Len() != 0 {
+		t.Fatalf("GET with the ETag = %d with %d body bytes, want an empty 304", second.Code, second.Body.Len())
+	}
+
+	p.tmpl.UpdatedAt = p.tmpl.UpdatedAt.Add(time.Second)
+	if third := get(etag); third.Code != http.StatusOK {
+		t.Errorf("GET after an update = %d, want 200", third.Code)
+	}
+}
@@ -0,0 +1,19 @@
+package database
+
+// DefaultBatchSize bounds how many rows a repository sends to PostgreSQL in one batch
+const DefaultBatchSize = 500
+
+// Chunks calls fn with consecutive [start, end) ranges of at most size items covering n items,
+// stopping at the first error. A non-positive size falls back to DefaultBatchSize.
+func Chunks(n, size int, fn func(start, end int) error) error {
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+	for start := 0; start < n; start += size {
+		end := min(start+size, n)
+		if err := fn(start, end); err != nil {
+			return err
+		}
+	}
+	return nil
+}
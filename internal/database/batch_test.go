@@ -0,0 +1,50 @@
+package database
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestChunksCoversEveryItemOnce(t *testing.T) {
+	const n = 1234
+	seen := make([]int, n)
+	var sizes []int
+
+	err := Chunks(n, 500, func(start, end int) error {
+		sizes = append(sizes, end-start)
+		for i := start; i < end; i++ {
+			seen[i]++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Chunks: %v", err)
+	}
+
+	for i, count := range seen {
+		if count != 1 {
+			t.Fatalf("item %d visited %d times, want 1", i, count)
+		}
+	}
+	if len(sizes) != 3 || sizes[0] != 500 || sizes[1] != 500 || sizes[2] != 234 {
+		t.Errorf("chunk sizes = %v, want [500 500 234]", sizes)
+	}
+}
+
+func TestChunksDefaultsAndStopsOnError(t *testing.T) {
+	var calls int
+	err := Chunks(DefaultBatchSize*3, 0, func(start, end int) error {
+		calls++
+		if end-start != DefaultBatchSize {
+			t.Errorf("chunk [%d, %d) has %d items, want %d", start, end, end-start, DefaultBatchSize)
+		}
+		return errors.New("batch failed")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("err = %v after %d calls, want the first chunk's error", err, calls)
+	}
+
+	if err := Chunks(0, 10, func(int, int) error { t.Error("called for no items"); return nil }); err != nil {
+		t.Errorf("Chunks(0) = %v", err)
+	}
+}
@@ -3,19 +3,47 @@ package database
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// PoolConfig tunes the PostgreSQL connection pool
+// Zero values keep the DSN's pool_* parameters or the pgx defaults.
+type PoolConfig struct {
+	MaxConns        int32
+	MinConns        int32
+	MaxConnLifetime time.Duration
+	MaxConnIdleTime time.Duration
+
+	// SlowQueryThreshold logs queries running at least this long to Logger; zero disables it
+	SlowQueryThreshold time.Duration
+	Logger             *slog.Logger
+}
+
 // NewPostgresPool creates a new PostgreSQL connection pool
-func NewPostgresPool(ctx context.Context, dsn string) (*pgxpool.Pool, error) {
+func NewPostgresPool(ctx context.Context, dsn string, poolCfg PoolConfig) (*pgxpool.Pool, error) {
 	config, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("parsing dsn: %w", err)
 	}
 
-	config.MaxConns = 25
-	config.MinConns = 5
+	if poolCfg.MaxConns > 0 {
+		config.MaxConns = poolCfg.MaxConns
+	}
+	if poolCfg.MinConns > 0 {
+		config.MinConns = poolCfg.MinConns
+	}
+	if poolCfg.MaxConnLifetime > 0 {
+		config.MaxConnLifetime = poolCfg.MaxConnLifetime
+	}
+	if poolCfg.MaxConnIdleTime > 0 {
+		config.MaxConnIdleTime = poolCfg.MaxConnIdleTime
+	}
+	if poolCfg.SlowQueryThreshold > 0 {
+		config.ConnConfig.Tracer = NewSlowQueryTracer(poolCfg.SlowQueryThreshold, poolCfg.Logger)
+	}
 
 	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// maxLoggedSQLLength bounds the SQL text written to the log
+const maxLoggedSQLLength = 500
+
+// SlowQueryTracer logs queries that run longer than a threshold
+// Only the SQL text is logged: arguments may carry access tokens or message text.
+type SlowQueryTracer struct {
+	threshold time.Duration
+	logger    *slog.Logger
+}
+
+// NewSlowQueryTracer creates a tracer logging queries slower than threshold
+func NewSlowQueryTracer(threshold time.Duration, logger *slog.Logger) *SlowQueryTracer {
+	return &SlowQueryTracer{threshold: threshold, logger: logger}
+}
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	sql string
+	at  time.Time
+}
+
+// TraceQueryStart remembers when the query started
+func (t *SlowQueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, queryStart{sql: data.SQL, at: time.Now()})
+}
+
+// TraceQueryEnd logs the query if it took longer than the threshold
+func (t *SlowQueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+
+	elapsed := time.Since(start.at)
+	if elapsed < t.threshold {
+		return
+	}
+
+	attrs := []any{
+		"duration", elapsed,
+		"sql", truncateSQL(start.sql),
+		"rows", data.CommandTag.RowsAffected(),
+	}
+	if data.Err != nil {
+		attrs = append(attrs, "error", data.Err)
+	}
+	t.logger.Warn("slow query", attrs...)
+}
+
+// truncateSQL collapses whitespace and cuts the SQL to maxLoggedSQLLength bytes
+func truncateSQL(sql string) string {
+	sql = strings.Join(strings.Fields(sql), " ")
+	if len(sql) <= maxLoggedSQLLength {
+		return sql
+	}
+
+	// Don't cut a multi-byte character in half
+	cut := maxLoggedSQLLength
+	for cut > 0 && !utf8.RuneStart(sql[cut]) {
+		cut--
+	}
+	return sql[:cut] + "..."
+}
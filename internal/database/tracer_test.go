@@ -0,0 +1,104 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func newTestTracer(threshold time.Duration) (*SlowQueryTracer, *bytes.Buffer) {
+	var buf bytes.Buffer
+	return NewSlowQueryTracer(threshold, slog.New(slog.NewJSONHandler(&buf, nil))), &buf
+}
+
+func TestSlowQueryTracerLogsSlowQueryWithoutArgs(t *testing.T) {
+	tracer, buf := newTestTracer(10 * time.Millisecond)
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{
+		SQL:  "SELECT id\n\t\tFROM accounts\n\t\tWHERE access_token = $1",
+		Args: []any{"secret-token"},
+	})
+	time.Sleep(20 * time.Millisecond)
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("decoding log entry %q: %v", buf.String(), err)
+	}
+	if entry["msg"] != "slow query" {
+		t.Errorf("msg = %v, want slow query", entry["msg"])
+	}
+	if entry["sql"] != "SELECT id FROM accounts WHERE access_token = $1" {
+		t.Errorf("sql = %q", entry["sql"])
+	}
+	if d, _ := entry["duration"].(float64); time.Duration(d) < 20*time.Millisecond {
+		t.Errorf("duration = %v, want at least 20ms", entry["duration"])
+	}
+	if strings.Contains(buf.String(), "secret-token") {
+		t.Errorf("query arguments were logged: %s", buf.String())
+	}
+}
+
+func TestSlowQueryTracerSkipsFastQuery(t *testing.T) {
+	tracer, buf := newTestTracer(time.Hour)
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+
+	if buf.Len() != 0 {
+		t.Errorf("fast query logged: %s", buf.String())
+	}
+}
+
+func TestTruncateSQL(t *testing.T) {
+	long := "SELECT '" + strings.Repeat("ж", maxLoggedSQLLength) + "'"
+	got := truncateSQL(long)
+
+	if !strings.HasSuffix(got, "...") || len(got) > maxLoggedSQLLength+len("...") {
+		t.Fatalf("truncateSQL returned %d bytes: %q", len(got), got)
+	}
+	if !strings.HasPrefix(long, strings.TrimSuffix(got, "...")) || strings.ContainsRune(got, '�') {
+		t.Errorf("truncateSQL cut a character in half: %q", got)
+	}
+}
+
+// TestSlowQueryTracerWithPostgres runs a deliberately slow query against TEST_DATABASE_URL
+func TestSlowQueryTracerWithPostgres(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	var buf bytes.Buffer
+	ctx := context.Background()
+	pool, err := NewPostgresPool(ctx, dsn, PoolConfig{
+		MaxConns:           2,
+		SlowQueryThreshold: 100 * time.Millisecond,
+		Logger:             slog.New(slog.NewJSONHandler(&buf, nil)),
+	})
+	if err != nil {
+		t.Fatalf("NewPostgresPool: %v", err)
+	}
+	defer pool.Close()
+
+	if _, err := pool.Exec(ctx, "SELECT 1"); err != nil {
+		t.Fatalf("fast query: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("fast query logged: %s", buf.String())
+	}
+
+	if _, err := pool.Exec(ctx, "SELECT pg_sleep($1)", 0.2); err != nil {
+		t.Fatalf("slow query: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"sql":"SELECT pg_sleep($1)"`) {
+		t.Errorf("slow query not logged: %s", buf.String())
+	}
+}
@@ -0,0 +1,115 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vadim/neo-metric/internal/domain/audit/entity"
+)
+
+// AuditPostgres implements the audit repository for PostgreSQL
+type AuditPostgres struct {
+	pool *pgxpool.Pool
+}
+
+// NewAuditPostgres creates a new PostgreSQL audit repository
+func NewAuditPostgres(pool *pgxpool.Pool) *AuditPostgres {
+	return &AuditPostgres{pool: pool}
+}
+
+// Create inserts an audit entry and sets its ID
+func (r *AuditPostgres) Create(ctx context.Context, e *entity.Entry) error {
+	query := `
+		INSERT INTO audit_log (account_id, principal, action, target_id, success, error_message, created_at)
+		VALUES (NULLIF($1, ''), NULLIF($2, ''), $3, NULLIF($4, ''), $5, NULLIF($6, ''), $7)
+		RETURNING id
+	`
+
+	err := r.pool.QueryRow(ctx, query,
+		e.AccountID,
+		e.Principal,
+		e.Action,
+		e.TargetID,
+		e.Success,
+		e.ErrorMessage,
+		e.CreatedAt,
+	).Scan(&e.ID)
+	if err != nil {
+		return fmt.Errorf("inserting audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// ListFilter contains filters for listing audit entries
+type ListFilter struct {
+	AccountID string
+	Action    string
+	From      *time.Time
+	To        *time.Time
+}
+
+// List retrieves audit entries matching the filter, newest first
+func (r *AuditPostgres) List(ctx context.Context, filter ListFilter, limit, offset int) ([]entity.Entry, error) {
+	where, args := auditWhere(filter)
+	query := `
+		SELECT id, COALESCE(account_id, ''), COALESCE(principal, ''), action, COALESCE(target_id, ''),
+		       success, COALESCE(error_message, ''), created_at
+		FROM audit_log` + where +
+		fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying audit log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []entity.Entry{}
+	for rows.Next() {
+		var e entity.Entry
+		if err := rows.Scan(&e.ID, &e.AccountID, &e.Principal, &e.Action, &e.TargetID,
+			&e.Success, &e.ErrorMessage, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning audit entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// Count returns the number of audit entries matching the filter
+func (r *AuditPostgres) Count(ctx context.Context, filter ListFilter) (int64, error) {
+	where, args := auditWhere(filter)
+
+	var count int64
+	if err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM audit_log"+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("counting audit entries: %w", err)
+	}
+
+	return count, nil
+}
+
+// auditWhere builds the WHERE clause and arguments for a list filter
+func auditWhere(filter ListFilter) (string, []interface{}) {
+	where := " WHERE account_id = $1"
+	args := []interface{}{filter.AccountID}
+
+	if filter.Action != "" {
+		args = append(args, filter.Action)
+		where += fmt.Sprintf(" AND action = $%d", len(args))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		where += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		where += fmt.Sprintf(" AND created_at <= $%d", len(args))
+	}
+
+	return where, args
+}
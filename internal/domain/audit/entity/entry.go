@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// Entry is a single audited action
+type Entry struct {
+	ID           int64     `json:"id"`
+	AccountID    string    `json:"account_id,omitempty"`
+	Principal    string    `json:"principal,omitempty"` // API key fingerprint; empty for scheduler actions or without auth
+	Action       string    `json:"action"`              // e.g. "publication.publish", "comment.hide", "direct.message.send"
+	TargetID     string    `json:"target_id,omitempty"` // Publication, comment, conversation or participant ID
+	Success      bool      `json:"success"`
+	ErrorMessage string    `json:"error_message,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Domain errors for the audit log
+var (
+	ErrEmptyAction    = errors.New("audit action is required")
+	ErrEmptyAccountID = errors.New("account ID is required")
+)
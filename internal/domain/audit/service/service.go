@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/audit/entity"
+)
+
+// AuditRepository defines the interface for audit log storage
+type AuditRepository interface {
+	Create(ctx context.Context, e *entity.Entry) error
+	List(ctx context.Context, filter ListFilter, limit, offset int) ([]entity.Entry, error)
+	Count(ctx context.Context, filter ListFilter) (int64, error)
+}
+
+// ListFilter contains filters for listing audit entries
+type ListFilter struct {
+	AccountID string
+	Action    string     // Optional exact action, e.g. "publication.publish"
+	From      *time.Time // Inclusive
+	To        *time.Time // Inclusive
+}
+
+// Service handles the audit log
+type Service struct {
+	repo AuditRepository
+}
+
+// New creates a new audit service
+func New(repo AuditRepository) *Service {
+	return &Service{repo: repo}
+}
+
+// RecordInput describes an action to audit
+type RecordInput struct {
+	AccountID string
+	Principal string
+	Action    string
+	TargetID  string
+	Err       error // Outcome of the action; nil means it succeeded
+}
+
+// Record stores an audit entry for an action
+func (s *Service) Record(ctx context.Context, in RecordInput) (*entity.Entry, error) {
+	if in.Action == "" {
+		return nil, entity.ErrEmptyAction
+	}
+
+	e := &entity.Entry{
+		AccountID: in.AccountID,
+		Principal: in.Principal,
+		Action:    in.Action,
+		TargetID:  in.TargetID,
+		Success:   in.Err == nil,
+		CreatedAt: time.Now(),
+	}
+	if in.Err != nil {
+		e.ErrorMessage = in.Err.Error()
+	}
+
+	if err := s.repo.Create(ctx, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// ListInput represents input for listing audit entries
+type ListInput struct {
+	ListFilter
+	Limit  int
+	Offset int
+}
+
+// ListOutput represents a page of audit entries
+type ListOutput struct {
+	Entries []entity.Entry
+	Total   int64
+}
+
+// List retrieves an account's audit entries, newest first
+func (s *Service) List(ctx context.Context, in ListInput) (*ListOutput, error) {
+	if in.AccountID == "" {
+		return nil, entity.ErrEmptyAccountID
+	}
+
+	entries, err := s.repo.List(ctx, in.ListFilter, in.Limit, in.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.repo.Count(ctx, in.ListFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListOutput{Entries: entries, Total: total}, nil
+}
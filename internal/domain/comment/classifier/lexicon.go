@@ -0,0 +1,87 @@
+package classifier
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/vadim/neo-metric/internal/domain/comment/entity"
+)
+
+// Lexicon is a keyword-based sentiment classifier.
+// It counts positive and negative words/emoji and picks the dominant tone.
+type Lexicon struct {
+	positive map[string]struct{}
+	negative map[string]struct{}
+	posEmoji []string
+	negEmoji []string
+}
+
+// NewLexicon creates a lexicon classifier with the built-in English and Russian word lists
+func NewLexicon() *Lexicon {
+	return &Lexicon{
+		positive: toSet(defaultPositive),
+		negative: toSet(defaultNegative),
+		posEmoji: defaultPositiveEmoji,
+		negEmoji: defaultNegativeEmoji,
+	}
+}
+
+// Classify returns the sentiment of a comment text
+func (l *Lexicon) Classify(_ context.Context, text string) (entity.Sentiment, error) {
+	score := 0
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		if _, ok := l.positive[w]; ok {
+			score++
+		}
+		if _, ok := l.negative[w]; ok {
+			score--
+		}
+	}
+
+	for _, e := range l.posEmoji {
+		score += strings.Count(text, e)
+	}
+	for _, e := range l.negEmoji {
+		score -= strings.Count(text, e)
+	}
+
+	switch {
+	case score > 0:
+		return entity.SentimentPositive, nil
+	case score < 0:
+		return entity.SentimentNegative, nil
+	default:
+		return entity.SentimentNeutral, nil
+	}
+}
+
+func toSet(words []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		set[w] = struct{}{}
+	}
+	return set
+}
+
+var defaultPositive = []string{
+	"good", "great", "awesome", "amazing", "love", "loved", "beautiful", "nice", "perfect",
+	"excellent", "best", "thanks", "thank", "wonderful", "cool", "fantastic", "recommend",
+	"хорошо", "хороший", "отлично", "отличный", "супер", "класс", "классно", "круто", "люблю",
+	"спасибо", "красиво", "красота", "прекрасно", "лучший", "лучшие", "рекомендую", "восторг",
+}
+
+var defaultNegative = []string{
+	"bad", "terrible", "awful", "hate", "worst", "scam", "fake", "disappointed", "broken",
+	"refund", "horrible", "poor", "useless", "never", "spam", "rude",
+	"плохо", "плохой", "ужасно", "ужас", "отстой", "обман", "мошенники", "развод", "верните",
+	"худший", "разочарован", "разочарована", "кошмар", "брак", "спам", "никогда",
+}
+
+var defaultPositiveEmoji = []string{"❤", "😍", "🔥", "👍", "😊", "🥰", "👏", "💯"}
+
+var defaultNegativeEmoji = []string{"👎", "😡", "🤬", "😠", "💩", "😞", "🤮"}
@@ -0,0 +1,45 @@
+package classifier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vadim/neo-metric/internal/domain/comment/entity"
+)
+
+func TestLexiconClassify(t *testing.T) {
+	tests := []struct {
+		text string
+		want entity.Sentiment
+	}{
+		// Positive
+		{"Love it, great job!", entity.SentimentPositive},
+		{"Спасибо, очень КРАСИВО", entity.SentimentPositive},
+		{"😍😍", entity.SentimentPositive},
+		{"ok 👍", entity.SentimentPositive},
+
+		// Negative
+		{"This is a scam, I want a refund", entity.SentimentNegative},
+		{"Ужасно. Верните деньги!", entity.SentimentNegative},
+		{"👎", entity.SentimentNegative},
+		{"good idea but terrible, awful quality", entity.SentimentNegative},
+
+		// Neutral
+		{"", entity.SentimentNeutral},
+		{"What time do you open?", entity.SentimentNeutral},
+		{"Сколько стоит доставка?", entity.SentimentNeutral},
+		{"good but bad", entity.SentimentNeutral},
+		{"goodness gracious", entity.SentimentNeutral}, // Whole words only
+	}
+
+	l := NewLexicon()
+	for _, tt := range tests {
+		got, err := l.Classify(context.Background(), tt.text)
+		if err != nil {
+			t.Fatalf("Classify(%q): %v", tt.text, err)
+		}
+		if got != tt.want {
+			t.Errorf("Classify(%q) = %s, want %s", tt.text, got, tt.want)
+		}
+	}
+}
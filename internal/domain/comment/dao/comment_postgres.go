@@ -8,7 +8,9 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/vadim/neo-metric/internal/database"
 	"github.com/vadim/neo-metric/internal/domain/comment/entity"
+	"github.com/vadim/neo-metric/internal/mention"
 )
 
 // CommentRepository defines the interface for comment storage
@@ -19,10 +21,12 @@ type CommentRepository interface {
 	UpsertBatch(ctx context.Context, comments []entity.Comment) error
 	// GetByID retrieves a comment by ID
 	GetByID(ctx context.Context, id string) (*entity.Comment, error)
-	// GetByMediaID retrieves comments for a media
-	GetByMediaID(ctx context.Context, mediaID string, limit int, offset int) ([]entity.Comment, error)
+	// GetByMediaID retrieves comments for a media, optionally filtered by sentiment, hidden status and author
+	GetByMediaID(ctx context.Context, mediaID string, filter entity.CommentFilter, limit int, offset int) ([]entity.Comment, error)
 	// GetReplies retrieves replies to a comment
 	GetReplies(ctx context.Context, parentID string, limit int, offset int) ([]entity.Comment, error)
+	// GetRepliesForParents retrieves the oldest replies of each parent in a single query
+	GetRepliesForParents(ctx context.Context, parentIDs []string, maxPerParent int) ([]entity.Comment, error)
 	// Delete removes a comment
 	Delete(ctx context.Context, id string) error
 	// UpdateHidden updates the hidden status
@@ -31,8 +35,17 @@ type CommentRepository interface {
 	Count(ctx context.Context, mediaID string) (int64, error)
 	// CountReplies returns the total count of replies to a comment
 	CountReplies(ctx context.Context, parentID string) (int64, error)
-	// GetStatistics retrieves aggregated comment statistics for an account
-	GetStatistics(ctx context.Context, accountID string, topPostsLimit int) (*entity.CommentStatistics, error)
+	// GetUnclassified retrieves comments for a media that have no sentiment yet
+	GetUnclassified(ctx context.Context, mediaID string, limit int) ([]entity.Comment, error)
+	// UpdateSentiments sets the sentiment of several comments, keyed by comment ID
+	UpdateSentiments(ctx context.Context, sentiments map[string]entity.Sentiment) error
+	// GetStatistics retrieves aggregated comment statistics for an account (without top posts)
+	GetStatistics(ctx context.Context, filter entity.StatisticsFilter) (*entity.CommentStatistics, error)
+
+	// GetTopPosts retrieves a page of the account's posts ranked by comment engagement
+	GetTopPosts(ctx context.Context, filter entity.StatisticsFilter, q entity.TopPostsQuery) ([]entity.TopPost, error)
+	// GetRecentByAccount retrieves a page of the newest comments across the account's published posts
+	GetRecentByAccount(ctx context.Context, q entity.RecentCommentsQuery) ([]entity.RecentComment, error)
 }
 
 // SyncStatusRepository defines the interface for sync status tracking
@@ -42,7 +55,7 @@ type SyncStatusRepository interface {
 	// UpdateSyncStatus updates sync status for a media
 	UpdateSyncStatus(ctx context.Context, status *SyncStatus) error
 	// GetMediaIDsNeedingSync retrieves media IDs that need synchronization
-	GetMediaIDsNeedingSync(ctx context.Context, olderThan time.Duration, limit int) ([]string, error)
+	GetMediaIDsNeedingSync(ctx context.Context, olderThan, maxPostAge time.Duration, limit int) ([]string, error)
 	// IncrementRetryCount increments the retry count and optionally marks as failed
 	IncrementRetryCount(ctx context.Context, mediaID string, lastError string, maxRetries int) error
 	// ResetRetryCount resets the retry count after a successful sync
@@ -58,19 +71,54 @@ type SyncStatus struct {
 	RetryCount       int
 	Failed           bool
 	LastError        string
+	LastFullSyncAt   *time.Time // nil until the first full sync
+}
+
+// SyncSummary aggregates the comment sync state of an account's media
+type SyncSummary struct {
+	Total          int64      // Media whose comments are synced
+	NeverSynced    int64      // Media without a sync status yet
+	Retrying       int64      // Media whose last sync failed but will be retried
+	Failed         int64      // Media excluded from sync after too many failures
+	LastSyncedAt   *time.Time // Most recent successful sync
+	OldestSyncedAt *time.Time // Least recent sync of a media that is not failed
 }
 
 // CommentPostgres implements CommentRepository for PostgreSQL
 type CommentPostgres struct {
-	pool *pgxpool.Pool
+	pool      *pgxpool.Pool
+	batchSize int
 }
 
+// repliesCountColumn is the larger of the replies count Instagram reported at the last sync
+// and the replies stored locally, so it is right both before replies are synced and after
+// new replies arrive through webhooks
+const repliesCountColumn = `GREATEST(comments.replies_count, (SELECT COUNT(*) FROM comments c2 WHERE c2.parent_id = comments.id))`
+
 // NewCommentPostgres creates a new PostgreSQL comment repository
 func NewCommentPostgres(pool *pgxpool.Pool) *CommentPostgres {
-	return &CommentPostgres{pool: pool}
+	return &CommentPostgres{pool: pool, batchSize: database.DefaultBatchSize}
 }
 
-// Upsert inserts or updates a comment
+// WithBatchSize limits how many comments UpsertBatch sends in one round-trip
+func (r *CommentPostgres) WithBatchSize(size int) *CommentPostgres {
+	r.batchSize = size
+	return r
+}
+
+// replaceMentionsQuery sets the usernames mentioned by comment $1 to $2
+const replaceMentionsQuery = `
+	WITH removed AS (
+		DELETE FROM comment_mentions WHERE comment_id = $1 AND NOT (username = ANY($2::text[]))
+	)
+	INSERT INTO comment_mentions (comment_id, username)
+	SELECT $1, unnest($2::text[])
+	ON CONFLICT DO NOTHING
+`
+
+// Upsert inserts or updates a comment and the usernames its text mentions
+// Unlike UpsertBatch it keeps the stored replies count: single comments come from our own
+// posts and replies rather than from a sync, so they carry no count.
 func (r *CommentPostgres) Upsert(ctx context.Context, comment *entity.Comment) error {
 	query := `
 		INSERT INTO comments (id, instagram_media_id, parent_id, author_id, username, text, like_count, is_hidden, timestamp, updated_at)
@@ -78,6 +126,7 @@ func (r *CommentPostgres) Upsert(ctx context.Context, comment *entity.Comment) e
 		ON CONFLICT (id) DO UPDATE SET
 			like_count = EXCLUDED.like_count,
 			is_hidden = EXCLUDED.is_hidden,
+			sentiment = CASE WHEN comments.text IS DISTINCT FROM EXCLUDED.text THEN NULL ELSE comments.sentiment END,
 			text = EXCLUDED.text,
 			author_id = COALESCE(EXCLUDED.author_id, comments.author_id),
 			updated_at = NOW()
@@ -108,22 +157,41 @@ func (r *CommentPostgres) Upsert(ctx context.Context, comment *entity.Comment) e
 		return fmt.Errorf("upserting comment: %w", err)
 	}
 
+	if _, err := r.pool.Exec(ctx, replaceMentionsQuery, comment.ID, mentionsOf(comment)); err != nil {
+		return fmt.Errorf("storing comment mentions: %w", err)
+	}
+
 	return nil
 }
 
-// UpsertBatch inserts or updates multiple comments
-func (r *CommentPostgres) UpsertBatch(ctx context.Context, comments []entity.Comment) error {
-	if len(comments) == 0 {
-		return nil
+// mentionsOf returns the usernames a comment mentions, never nil
+func mentionsOf(comment *entity.Comment) []string {
+	names := mention.Extract(comment.Text)
+	if names == nil {
+		names = []string{}
 	}
+	return names
+}
+
+// UpsertBatch inserts or updates multiple comments and the usernames their texts mention,
+// sending at most batchSize comments per round-trip
+func (r *CommentPostgres) UpsertBatch(ctx context.Context, comments []entity.Comment) error {
+	return database.Chunks(len(comments), r.batchSize, func(start, end int) error {
+		return r.upsertChunk(ctx, comments[start:end], start)
+	})
+}
 
+// upsertChunk upserts comments in a single batch; first is the index of comments[0] for errors
+func (r *CommentPostgres) upsertChunk(ctx context.Context, comments []entity.Comment, first int) error {
 	batch := &pgx.Batch{}
 	query := `
-		INSERT INTO comments (id, instagram_media_id, parent_id, author_id, username, text, like_count, is_hidden, timestamp, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		INSERT INTO comments (id, instagram_media_id, parent_id, author_id, username, text, like_count, is_hidden, timestamp, replies_count, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
 		ON CONFLICT (id) DO UPDATE SET
 			like_count = EXCLUDED.like_count,
+			replies_count = EXCLUDED.replies_count,
 			is_hidden = EXCLUDED.is_hidden,
+			sentiment = CASE WHEN comments.text IS DISTINCT FROM EXCLUDED.text THEN NULL ELSE comments.sentiment END,
 			text = EXCLUDED.text,
 			author_id = COALESCE(EXCLUDED.author_id, comments.author_id),
 			updated_at = NOW()
@@ -148,7 +216,9 @@ func (r *CommentPostgres) UpsertBatch(ctx context.Context, comments []entity.Com
 			comment.LikeCount,
 			comment.IsHidden,
 			comment.Timestamp,
+			comment.RepliesCount,
 		)
+		batch.Queue(replaceMentionsQuery, comment.ID, mentionsOf(&comment))
 	}
 
 	br := r.pool.SendBatch(ctx, batch)
@@ -156,7 +226,10 @@ func (r *CommentPostgres) UpsertBatch(ctx context.Context, comments []entity.Com
 
 	for i := 0; i < len(comments); i++ {
 		if _, err := br.Exec(); err != nil {
-			return fmt.Errorf("upserting comment %d: %w", i, err)
+			return fmt.Errorf("upserting comment %d: %w", first+i, err)
+		}
+		if _, err := br.Exec(); err != nil {
+			return fmt.Errorf("storing mentions of comment %d: %w", first+i, err)
 		}
 	}
 
@@ -166,7 +239,9 @@ func (r *CommentPostgres) UpsertBatch(ctx context.Context, comments []entity.Com
 // GetByID retrieves a comment by ID
 func (r *CommentPostgres) GetByID(ctx context.Context, id string) (*entity.Comment, error) {
 	query := `
-		SELECT id, instagram_media_id, parent_id, author_id, username, text, like_count, is_hidden, timestamp
+		SELECT id, instagram_media_id, parent_id, author_id, username, text, like_count, is_hidden, timestamp,
+		       ` + repliesCountColumn + ` as replies_count,
+		       COALESCE(sentiment, '')
 		FROM comments
 		WHERE id = $1
 	`
@@ -186,6 +261,8 @@ func (r *CommentPostgres) GetByID(ctx context.Context, id string) (*entity.Comme
 		&comment.LikeCount,
 		&comment.IsHidden,
 		&comment.Timestamp,
+		&comment.RepliesCount,
+		&comment.Sentiment,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -204,18 +281,55 @@ func (r *CommentPostgres) GetByID(ctx context.Context, id string) (*entity.Comme
 	return &comment, nil
 }
 
-// GetByMediaID retrieves comments for a media (excluding replies)
-func (r *CommentPostgres) GetByMediaID(ctx context.Context, mediaID string, limit int, offset int) ([]entity.Comment, error) {
+// mediaCommentsQuery builds the query listing top-level comments of a media matching the filter
+func mediaCommentsQuery(mediaID string, filter entity.CommentFilter, limit, offset int) (string, []interface{}) {
 	query := `
 		SELECT id, instagram_media_id, parent_id, author_id, username, text, like_count, is_hidden, timestamp,
-		       (SELECT COUNT(*) FROM comments c2 WHERE c2.parent_id = comments.id) as replies_count
+		       ` + repliesCountColumn + ` as replies_count,
+		       COALESCE(sentiment, '')
 		FROM comments
 		WHERE instagram_media_id = $1 AND parent_id IS NULL
-		ORDER BY timestamp DESC
-		LIMIT $2 OFFSET $3
 	`
+	args := []interface{}{mediaID}
+	argNum := 2
+
+	if filter.Sentiment != "" {
+		query += fmt.Sprintf(" AND sentiment = $%d", argNum)
+		args = append(args, filter.Sentiment)
+		argNum++
+	}
+	if filter.Hidden != nil {
+		query += fmt.Sprintf(" AND is_hidden = $%d", argNum)
+		args = append(args, *filter.Hidden)
+		argNum++
+	}
+	if filter.AuthorID != "" {
+		query += fmt.Sprintf(" AND author_id = $%d", argNum)
+		args = append(args, filter.AuthorID)
+		argNum++
+	}
+	if filter.Since != nil {
+		query += fmt.Sprintf(" AND timestamp >= $%d", argNum)
+		args = append(args, *filter.Since)
+		argNum++
+	}
+	if filter.Until != nil {
+		query += fmt.Sprintf(" AND timestamp <= $%d", argNum)
+		args = append(args, *filter.Until)
+		argNum++
+	}
 
-	rows, err := r.pool.Query(ctx, query, mediaID, limit, offset)
+	query += fmt.Sprintf(" ORDER BY timestamp DESC LIMIT $%d OFFSET $%d", argNum, argNum+1)
+	args = append(args, limit, offset)
+
+	return query, args
+}
+
+// GetByMediaID retrieves comments for a media (excluding replies)
+func (r *CommentPostgres) GetByMediaID(ctx context.Context, mediaID string, filter entity.CommentFilter, limit int, offset int) ([]entity.Comment, error) {
+	query, args := mediaCommentsQuery(mediaID, filter, limit, offset)
+
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("querying comments: %w", err)
 	}
@@ -237,6 +351,7 @@ func (r *CommentPostgres) GetByMediaID(ctx context.Context, mediaID string, limi
 			&comment.IsHidden,
 			&comment.Timestamp,
 			&comment.RepliesCount,
+			&comment.Sentiment,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning row: %w", err)
@@ -258,7 +373,8 @@ func (r *CommentPostgres) GetByMediaID(ctx context.Context, mediaID string, limi
 // GetReplies retrieves replies to a comment
 func (r *CommentPostgres) GetReplies(ctx context.Context, parentID string, limit int, offset int) ([]entity.Comment, error) {
 	query := `
-		SELECT id, instagram_media_id, parent_id, author_id, username, text, like_count, is_hidden, timestamp
+		SELECT id, instagram_media_id, parent_id, author_id, username, text, like_count, is_hidden, timestamp,
+		       COALESCE(sentiment, '')
 		FROM comments
 		WHERE parent_id = $1
 		ORDER BY timestamp ASC
@@ -286,6 +402,69 @@ func (r *CommentPostgres) GetReplies(ctx context.Context, parentID string, limit
 			&comment.LikeCount,
 			&comment.IsHidden,
 			&comment.Timestamp,
+			&comment.Sentiment,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning row: %w", err)
+		}
+
+		if pID != nil {
+			comment.ParentID = *pID
+		}
+		if authorID != nil {
+			comment.AuthorID = *authorID
+		}
+
+		comments = append(comments, comment)
+	}
+
+	return comments, nil
+}
+
+// GetRepliesForParents retrieves up to maxPerParent oldest replies of each parent comment
+func (r *CommentPostgres) GetRepliesForParents(ctx context.Context, parentIDs []string, maxPerParent int) ([]entity.Comment, error) {
+	if len(parentIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT id, instagram_media_id, parent_id, author_id, username, text, like_count, is_hidden, timestamp,
+		       replies_count, sentiment
+		FROM (
+			SELECT id, instagram_media_id, parent_id, author_id, username, text, like_count, is_hidden, timestamp,
+			       ` + repliesCountColumn + ` as replies_count,
+			       COALESCE(sentiment, '') as sentiment,
+			       ROW_NUMBER() OVER (PARTITION BY parent_id ORDER BY timestamp ASC) as rn
+			FROM comments
+			WHERE parent_id = ANY($1)
+		) r
+		WHERE rn <= $2
+		ORDER BY parent_id, timestamp ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, parentIDs, maxPerParent)
+	if err != nil {
+		return nil, fmt.Errorf("querying replies for parents: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []entity.Comment
+	for rows.Next() {
+		var comment entity.Comment
+		var pID, authorID *string
+
+		err := rows.Scan(
+			&comment.ID,
+			&comment.MediaID,
+			&pID,
+			&authorID,
+			&comment.Username,
+			&comment.Text,
+			&comment.LikeCount,
+			&comment.IsHidden,
+			&comment.Timestamp,
+			&comment.RepliesCount,
+			&comment.Sentiment,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning row: %w", err)
@@ -313,6 +492,23 @@ func (r *CommentPostgres) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// DeleteMissing removes top-level comments of a media that are not in keepIDs and were
+// stored before the given time; their replies are removed with them
+func (r *CommentPostgres) DeleteMissing(ctx context.Context, mediaID string, keepIDs []string, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM comments
+		WHERE instagram_media_id = $1
+		  AND parent_id IS NULL
+		  AND id <> ALL($2)
+		  AND created_at < $3
+	`
+	tag, err := r.pool.Exec(ctx, query, mediaID, keepIDs, before)
+	if err != nil {
+		return 0, fmt.Errorf("deleting missing comments: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
 // UpdateHidden updates the hidden status
 func (r *CommentPostgres) UpdateHidden(ctx context.Context, id string, hidden bool) error {
 	query := "UPDATE comments SET is_hidden = $2, updated_at = NOW() WHERE id = $1"
@@ -323,6 +519,61 @@ func (r *CommentPostgres) UpdateHidden(ctx context.Context, id string, hidden bo
 	return nil
 }
 
+// GetUnclassified retrieves comments for a media that have no sentiment yet
+func (r *CommentPostgres) GetUnclassified(ctx context.Context, mediaID string, limit int) ([]entity.Comment, error) {
+	query := `
+		SELECT id, text
+		FROM comments
+		WHERE instagram_media_id = $1 AND sentiment IS NULL
+		ORDER BY timestamp DESC
+		LIMIT $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, mediaID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying unclassified comments: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []entity.Comment
+	for rows.Next() {
+		comment := entity.Comment{MediaID: mediaID}
+		if err := rows.Scan(&comment.ID, &comment.Text); err != nil {
+			return nil, fmt.Errorf("scanning row: %w", err)
+		}
+		comments = append(comments, comment)
+	}
+
+	return comments, nil
+}
+
+// UpdateSentiments sets the sentiment of several comments in one statement
+func (r *CommentPostgres) UpdateSentiments(ctx context.Context, sentiments map[string]entity.Sentiment) error {
+	if len(sentiments) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(sentiments))
+	values := make([]string, 0, len(sentiments))
+	for id, sentiment := range sentiments {
+		ids = append(ids, id)
+		values = append(values, string(sentiment))
+	}
+
+	query := `
+		UPDATE comments c
+		SET sentiment = v.sentiment
+		FROM unnest($1::text[], $2::text[]) AS v(id, sentiment)
+		WHERE c.id = v.id
+	`
+
+	_, err := r.pool.Exec(ctx, query, ids, values)
+	if err != nil {
+		return fmt.Errorf("updating sentiments: %w", err)
+	}
+	return nil
+}
+
 // Count returns the total count of comments for a media (excluding replies)
 func (r *CommentPostgres) Count(ctx context.Context, mediaID string) (int64, error) {
 	var count int64
@@ -363,7 +614,8 @@ func NewSyncStatusPostgres(pool *pgxpool.Pool) *SyncStatusPostgres {
 func (r *SyncStatusPostgres) GetSyncStatus(ctx context.Context, mediaID string) (*SyncStatus, error) {
 	query := `
 		SELECT instagram_media_id, last_synced_at, next_cursor, sync_complete,
-		       COALESCE(retry_count, 0), COALESCE(failed, false), COALESCE(last_error, '')
+		       COALESCE(retry_count, 0), COALESCE(failed, false), COALESCE(last_error, ''),
+		       last_full_sync_at
 		FROM comment_sync_status
 		WHERE instagram_media_id = $1
 	`
@@ -381,6 +633,7 @@ func (r *SyncStatusPostgres) GetSyncStatus(ctx context.Context, mediaID string)
 		&status.RetryCount,
 		&status.Failed,
 		&status.LastError,
+		&status.LastFullSyncAt,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -399,12 +652,13 @@ func (r *SyncStatusPostgres) GetSyncStatus(ctx context.Context, mediaID string)
 // UpdateSyncStatus updates sync status for a media
 func (r *SyncStatusPostgres) UpdateSyncStatus(ctx context.Context, status *SyncStatus) error {
 	query := `
-		INSERT INTO comment_sync_status (instagram_media_id, last_synced_at, next_cursor, sync_complete)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO comment_sync_status (instagram_media_id, last_synced_at, next_cursor, sync_complete, last_full_sync_at)
+		VALUES ($1, $2, $3, $4, $5)
 		ON CONFLICT (instagram_media_id) DO UPDATE SET
 			last_synced_at = EXCLUDED.last_synced_at,
 			next_cursor = EXCLUDED.next_cursor,
-			sync_complete = EXCLUDED.sync_complete
+			sync_complete = EXCLUDED.sync_complete,
+			last_full_sync_at = EXCLUDED.last_full_sync_at
 	`
 
 	var nextCursor *string
@@ -417,6 +671,7 @@ func (r *SyncStatusPostgres) UpdateSyncStatus(ctx context.Context, status *SyncS
 		status.LastSyncedAt,
 		nextCursor,
 		status.SyncComplete,
+		status.LastFullSyncAt,
 	)
 	if err != nil {
 		return fmt.Errorf("updating sync status: %w", err)
@@ -428,24 +683,100 @@ func (r *SyncStatusPostgres) UpdateSyncStatus(ctx context.Context, status *SyncS
 // GetMediaIDsNeedingSync retrieves media IDs that need synchronization
 // Note: Stories are excluded because Instagram API doesn't support comments endpoint for them
 // Media marked as failed are excluded from sync
-func (r *SyncStatusPostgres) GetMediaIDsNeedingSync(ctx context.Context, olderThan time.Duration, limit int) ([]string, error) {
+// If maxPostAge is positive, media published longer ago than that are excluded as well
+func (r *SyncStatusPostgres) GetMediaIDsNeedingSync(ctx context.Context, olderThan, maxPostAge time.Duration, limit int) ([]string, error) {
+	query, args := mediaIDsNeedingSyncQuery(time.Now(), olderThan, maxPostAge, limit)
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying media ids: %w", err)
+	}
+	defer rows.Close()
+
+	var mediaIDs []string
+	for rows.Next() {
+		var mediaID string
+		if err := rows.Scan(&mediaID); err != nil {
+			return nil, fmt.Errorf("scanning media id: %w", err)
+		}
+		mediaIDs = append(mediaIDs, mediaID)
+	}
+
+	return mediaIDs, nil
+}
+
+// syncableMediaCondition selects the publications whose comments are synced
+const syncableMediaCondition = `p.instagram_media_id IS NOT NULL
+		  AND p.status = 'published'
+		  AND p.type != 'story'
+		  AND COALESCE(p.media_product_type, '') != 'STORY'
+		  AND p.comments_enabled IS NOT FALSE`
+
+// mediaIDsNeedingSyncQuery builds the query and arguments for GetMediaIDsNeedingSync
+func mediaIDsNeedingSyncQuery(now time.Time, olderThan, maxPostAge time.Duration, limit int) (string, []interface{}) {
 	query := `
 		SELECT p.instagram_media_id
 		FROM publications p
 		LEFT JOIN comment_sync_status css ON p.instagram_media_id = css.instagram_media_id
-		WHERE p.instagram_media_id IS NOT NULL
-		  AND p.status = 'published'
-		  AND p.type != 'story'
+		WHERE ` + syncableMediaCondition + `
 		  AND (css.failed IS NULL OR css.failed = false)
 		  AND (css.last_synced_at IS NULL OR css.last_synced_at < $1)
-		ORDER BY COALESCE(css.last_synced_at, '1970-01-01'::timestamp) ASC
-		LIMIT $2
 	`
+	args := []interface{}{now.Add(-olderThan)}
+	argNum := 2
+
+	if maxPostAge > 0 {
+		query += fmt.Sprintf(" AND p.published_at >= $%d", argNum)
+		args = append(args, now.Add(-maxPostAge))
+		argNum++
+	}
+
+	query += fmt.Sprintf(" ORDER BY COALESCE(css.last_synced_at, '1970-01-01'::timestamp) ASC LIMIT $%d", argNum)
+	args = append(args, limit)
+
+	return query, args
+}
 
-	cutoff := time.Now().Add(-olderThan)
-	rows, err := r.pool.Query(ctx, query, cutoff, limit)
+// GetAccountSummary aggregates the comment sync status of an account's published media
+func (r *SyncStatusPostgres) GetAccountSummary(ctx context.Context, accountID string) (*SyncSummary, error) {
+	query := `
+		SELECT COUNT(*),
+		       COUNT(*) FILTER (WHERE css.instagram_media_id IS NULL),
+		       COUNT(*) FILTER (WHERE NOT css.failed AND css.retry_count > 0),
+		       COUNT(*) FILTER (WHERE css.failed),
+		       MAX(css.last_synced_at) FILTER (WHERE NOT css.failed AND css.retry_count = 0),
+		       MIN(css.last_synced_at) FILTER (WHERE NOT css.failed)
+		FROM publications p
+		LEFT JOIN comment_sync_status css ON p.instagram_media_id = css.instagram_media_id
+		WHERE p.account_id = $1
+		  AND ` + syncableMediaCondition
+
+	var s SyncSummary
+	err := r.pool.QueryRow(ctx, query, accountID).Scan(
+		&s.Total,
+		&s.NeverSynced,
+		&s.Retrying,
+		&s.Failed,
+		&s.LastSyncedAt,
+		&s.OldestSyncedAt,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("querying media ids: %w", err)
+		return nil, fmt.Errorf("summarizing comment sync status: %w", err)
+	}
+
+	return &s, nil
+}
+
+// GetFailedMediaIDs returns the account's media excluded from sync after too many failures
+func (r *SyncStatusPostgres) GetFailedMediaIDs(ctx context.Context, accountID string) ([]string, error) {
+	query := `
+		SELECT DISTINCT css.instagram_media_id
+		FROM comment_sync_status css
+		JOIN publications p ON p.instagram_media_id = css.instagram_media_id
+		WHERE p.account_id = $1 AND css.failed
+	`
+	rows, err := r.pool.Query(ctx, query, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("querying failed media ids: %w", err)
 	}
 	defer rows.Close()
 
@@ -458,7 +789,7 @@ func (r *SyncStatusPostgres) GetMediaIDsNeedingSync(ctx context.Context, olderTh
 		mediaIDs = append(mediaIDs, mediaID)
 	}
 
-	return mediaIDs, nil
+	return mediaIDs, rows.Err()
 }
 
 // IncrementRetryCount increments the retry count and marks as failed if max retries exceeded
@@ -497,18 +828,21 @@ func (r *SyncStatusPostgres) ResetRetryCount(ctx context.Context, mediaID string
 	return nil
 }
 
-// GetStatistics retrieves aggregated comment statistics for an account
-func (r *CommentPostgres) GetStatistics(ctx context.Context, accountID string, topPostsLimit int) (*entity.CommentStatistics, error) {
+// GetStatistics retrieves aggregated comment statistics for an account (without top posts)
+func (r *CommentPostgres) GetStatistics(ctx context.Context, filter entity.StatisticsFilter) (*entity.CommentStatistics, error) {
 	stats := &entity.CommentStatistics{}
 
+	// All queries take account_id as $1 followed by the date range arguments
+	dateCond, dateArgs := timestampRange("c", filter, 2)
+	args := append([]interface{}{filter.AccountID}, dateArgs...)
+
 	// Get total comments count for account's publications
 	totalQuery := `
 		SELECT COUNT(*)
 		FROM comments c
 		JOIN publications p ON p.instagram_media_id = c.instagram_media_id
-		WHERE p.account_id = $1 AND p.status = 'published'
-	`
-	if err := r.pool.QueryRow(ctx, totalQuery, accountID).Scan(&stats.TotalComments); err != nil {
+		WHERE p.account_id = $1 AND p.status = 'published'` + dateCond
+	if err := r.pool.QueryRow(ctx, totalQuery, args...).Scan(&stats.TotalComments); err != nil {
 		return nil, fmt.Errorf("counting total comments: %w", err)
 	}
 
@@ -534,57 +868,167 @@ func (r *CommentPostgres) GetStatistics(ctx context.Context, accountID string, t
 				JOIN publications p ON p.instagram_media_id = c2.instagram_media_id
 				WHERE p.account_id = $1 AND p.status = 'published'
 			) AND (c.username = ia.username OR c.username = '' OR c.username IS NULL))
-		)
-	`
-	if err := r.pool.QueryRow(ctx, repliedQuery, accountID).Scan(&stats.RepliedComments); err != nil {
+		)` + dateCond
+	if err := r.pool.QueryRow(ctx, repliedQuery, args...).Scan(&stats.RepliedComments); err != nil {
 		return nil, fmt.Errorf("counting replied comments: %w", err)
 	}
 
 	// Get average comments per post
+	// The range is part of the join so posts without comments in it still count as zero
 	avgQuery := `
 		SELECT COALESCE(AVG(comment_count), 0)
 		FROM (
-			SELECT COUNT(*) as comment_count
+			SELECT COUNT(c.id) as comment_count
 			FROM publications p
-			LEFT JOIN comments c ON c.instagram_media_id = p.instagram_media_id
+			LEFT JOIN comments c ON c.instagram_media_id = p.instagram_media_id` + dateCond + `
 			WHERE p.account_id = $1
 			  AND p.status = 'published'
 			  AND p.instagram_media_id IS NOT NULL
 			GROUP BY p.id
 		) subq
 	`
-	if err := r.pool.QueryRow(ctx, avgQuery, accountID).Scan(&stats.AvgCommentsPerPost); err != nil {
+	if err := r.pool.QueryRow(ctx, avgQuery, args...).Scan(&stats.AvgCommentsPerPost); err != nil {
 		return nil, fmt.Errorf("calculating avg comments: %w", err)
 	}
 
-	// Get top posts by comment count
-	if topPostsLimit <= 0 {
-		topPostsLimit = 5
+	return stats, nil
+}
+
+// GetTopPosts retrieves a page of the account's posts ranked by comment count or comment likes
+func (r *CommentPostgres) GetTopPosts(ctx context.Context, filter entity.StatisticsFilter, q entity.TopPostsQuery) ([]entity.TopPost, error) {
+	if q.Limit <= 0 {
+		q.Limit = 5
 	}
+
+	// Media ID breaks ties so pages don't overlap
+	orderBy := "comments_count DESC, likes_count DESC, p.instagram_media_id"
+	if q.Sort == entity.TopPostsSortLikes {
+		orderBy = "likes_count DESC, comments_count DESC, p.instagram_media_id"
+	}
+
+	dateCond, dateArgs := timestampRange("c", filter, 4)
+	args := append([]interface{}{filter.AccountID, q.Limit, q.Offset}, dateArgs...)
+
 	topQuery := `
-		SELECT p.instagram_media_id, COALESCE(p.caption, ''), COUNT(c.id) as comments_count
+		SELECT p.instagram_media_id, COALESCE(p.caption, ''),
+		       COUNT(c.id) as comments_count, COALESCE(SUM(c.like_count), 0) as likes_count
 		FROM publications p
-		LEFT JOIN comments c ON c.instagram_media_id = p.instagram_media_id
+		LEFT JOIN comments c ON c.instagram_media_id = p.instagram_media_id` + dateCond + `
 		WHERE p.account_id = $1
 		  AND p.status = 'published'
 		  AND p.instagram_media_id IS NOT NULL
 		GROUP BY p.id, p.instagram_media_id, p.caption
-		ORDER BY comments_count DESC
-		LIMIT $2
+		ORDER BY ` + orderBy + `
+		LIMIT $2 OFFSET $3
 	`
-	rows, err := r.pool.Query(ctx, topQuery, accountID, topPostsLimit)
+	rows, err := r.pool.Query(ctx, topQuery, args...)
 	if err != nil {
 		return nil, fmt.Errorf("querying top posts: %w", err)
 	}
 	defer rows.Close()
 
+	var posts []entity.TopPost
 	for rows.Next() {
 		var post entity.TopPost
-		if err := rows.Scan(&post.MediaID, &post.Caption, &post.CommentsCount); err != nil {
+		if err := rows.Scan(&post.MediaID, &post.Caption, &post.CommentsCount, &post.LikesCount); err != nil {
 			return nil, fmt.Errorf("scanning top post: %w", err)
 		}
-		stats.TopPosts = append(stats.TopPosts, post)
+		posts = append(posts, post)
 	}
 
-	return stats, nil
+	return posts, nil
+}
+
+// recentCommentsQuery builds the query listing the newest comments on the account's published posts
+func recentCommentsQuery(q entity.RecentCommentsQuery) (string, []interface{}) {
+	query := `
+		SELECT c.id, c.instagram_media_id, c.parent_id, c.author_id, c.username, c.text, c.like_count, c.is_hidden, c.timestamp,
+		       COALESCE(c.sentiment, ''), LEFT(COALESCE(p.caption, ''), $2)
+		FROM comments c
+		JOIN publications p ON p.instagram_media_id = c.instagram_media_id
+		WHERE p.account_id = $1 AND p.status = 'published'
+	`
+	args := []interface{}{q.AccountID, entity.CaptionSnippetLength}
+	argNum := 3
+
+	if q.Since != nil {
+		query += fmt.Sprintf(" AND c.timestamp >= $%d", argNum)
+		args = append(args, *q.Since)
+		argNum++
+	}
+	if q.Mention != "" {
+		query += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM comment_mentions m WHERE m.comment_id = c.id AND m.username = $%d)", argNum)
+		args = append(args, q.Mention)
+		argNum++
+	}
+
+	// ID breaks ties so pages don't overlap
+	query += fmt.Sprintf(" ORDER BY c.timestamp DESC, c.id DESC LIMIT $%d OFFSET $%d", argNum, argNum+1)
+	args = append(args, q.Limit, q.Offset)
+
+	return query, args
+}
+
+// GetRecentByAccount retrieves a page of the newest comments across the account's published posts
+func (r *CommentPostgres) GetRecentByAccount(ctx context.Context, q entity.RecentCommentsQuery) ([]entity.RecentComment, error) {
+	query, args := recentCommentsQuery(q)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying recent comments: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []entity.RecentComment
+	for rows.Next() {
+		var comment entity.RecentComment
+		var parentID, authorID *string
+
+		err := rows.Scan(
+			&comment.ID,
+			&comment.MediaID,
+			&parentID,
+			&authorID,
+			&comment.Username,
+			&comment.Text,
+			&comment.LikeCount,
+			&comment.IsHidden,
+			&comment.Timestamp,
+			&comment.Sentiment,
+			&comment.Caption,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scanning recent comment: %w", err)
+		}
+
+		if parentID != nil {
+			comment.ParentID = *parentID
+		}
+		if authorID != nil {
+			comment.AuthorID = *authorID
+		}
+
+		comments = append(comments, comment)
+	}
+
+	return comments, nil
+}
+
+// timestampRange builds " AND <alias>.timestamp >= $n AND <alias>.timestamp <= $n+1" for the
+// filter's date range, numbering placeholders from argNum. Empty when the range is open.
+func timestampRange(alias string, filter entity.StatisticsFilter, argNum int) (string, []interface{}) {
+	var cond string
+	var args []interface{}
+
+	if filter.StartDate != nil {
+		cond += fmt.Sprintf(" AND %s.timestamp >= $%d", alias, argNum)
+		args = append(args, *filter.StartDate)
+		argNum++
+	}
+	if filter.EndDate != nil {
+		cond += fmt.Sprintf(" AND %s.timestamp <= $%d", alias, argNum)
+		args = append(args, *filter.EndDate)
+	}
+
+	return cond, args
 }
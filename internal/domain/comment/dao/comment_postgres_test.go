@@ -0,0 +1,162 @@
+package dao
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/comment/entity"
+)
+
+func TestMediaIDsNeedingSyncQueryExcludesOldMedia(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	query, args := mediaIDsNeedingSyncQuery(now, 10*time.Minute, 90*24*time.Hour, 10)
+
+	if !strings.Contains(query, "p.published_at >= $2") {
+		t.Fatalf("query does not filter by published_at:\n%s", query)
+	}
+	if !strings.Contains(query, "LIMIT $3") {
+		t.Fatalf("limit placeholder not shifted:\n%s", query)
+	}
+	if len(args) != 3 {
+		t.Fatalf("got %d args, want 3", len(args))
+	}
+	if got, want := args[1].(time.Time), now.Add(-90*24*time.Hour); !got.Equal(want) {
+		t.Errorf("published_at cutoff = %s, want %s", got, want)
+	}
+	if args[2] != 10 {
+		t.Errorf("limit arg = %v, want 10", args[2])
+	}
+}
+
+func TestMediaIDsNeedingSyncQueryNoPostAgeLimit(t *testing.T) {
+	query, args := mediaIDsNeedingSyncQuery(time.Now(), 10*time.Minute, 0, 10)
+
+	if strings.Contains(query, "published_at") {
+		t.Fatalf("zero max post age must not filter by published_at:\n%s", query)
+	}
+	if len(args) != 2 || args[1] != 10 {
+		t.Fatalf("args = %v, want [cutoff 10]", args)
+	}
+}
+
+func TestMediaIDsNeedingSyncQuerySkipsDisabledComments(t *testing.T) {
+	query, _ := mediaIDsNeedingSyncQuery(time.Now(), 10*time.Minute, 0, 10)
+
+	// Unknown (NULL) comment settings are still synced
+	if !strings.Contains(query, "p.comments_enabled IS NOT FALSE") {
+		t.Fatalf("query does not skip media with comments disabled:\n%s", query)
+	}
+}
+
+func TestTimestampRange(t *testing.T) {
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 6, 30, 23, 59, 59, 0, time.UTC)
+
+	cond, args := timestampRange("c", entity.StatisticsFilter{}, 2)
+	if cond != "" || len(args) != 0 {
+		t.Errorf("all-time range = %q %v, want no condition", cond, args)
+	}
+
+	cond, args = timestampRange("c", entity.StatisticsFilter{StartDate: &start, EndDate: &end}, 2)
+	if want := " AND c.timestamp >= $2 AND c.timestamp <= $3"; cond != want {
+		t.Errorf("cond = %q, want %q", cond, want)
+	}
+	if len(args) != 2 || args[0] != start || args[1] != end {
+		t.Errorf("args = %v, want [start end]", args)
+	}
+
+	cond, args = timestampRange("c", entity.StatisticsFilter{EndDate: &end}, 4)
+	if want := " AND c.timestamp <= $4"; cond != want || len(args) != 1 {
+		t.Errorf("end-only range = %q %v, want %q", cond, args, want)
+	}
+}
+
+func TestMediaCommentsQueryFilters(t *testing.T) {
+	hidden := true
+	query, args := mediaCommentsQuery("m1", entity.CommentFilter{Hidden: &hidden, AuthorID: "u1"}, 51, 50)
+
+	if !strings.Contains(query, "is_hidden = $2") {
+		t.Errorf("query does not filter by hidden status:\n%s", query)
+	}
+	if !strings.Contains(query, "author_id = $3") {
+		t.Errorf("query does not filter by author:\n%s", query)
+	}
+	if !strings.Contains(query, "LIMIT $4 OFFSET $5") {
+		t.Errorf("pagination placeholders not shifted:\n%s", query)
+	}
+	if len(args) != 5 || args[1] != true || args[2] != "u1" || args[3] != 51 || args[4] != 50 {
+		t.Errorf("args = %v, want [m1 true u1 51 50]", args)
+	}
+}
+
+func TestMediaCommentsQueryTimeRange(t *testing.T) {
+	since := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(24 * time.Hour)
+	query, args := mediaCommentsQuery("m1", entity.CommentFilter{Since: &since, Until: &until}, 51, 0)
+
+	for _, want := range []string{"timestamp >= $2", "timestamp <= $3", "LIMIT $4 OFFSET $5"} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query does not contain %q:\n%s", want, query)
+		}
+	}
+	if len(args) != 5 || args[1] != since || args[2] != until {
+		t.Errorf("args = %v, want [m1 %v %v 51 0]", args, since, until)
+	}
+}
+
+func TestMediaCommentsQueryNoFilters(t *testing.T) {
+	query, args := mediaCommentsQuery("m1", entity.CommentFilter{}, 51, 0)
+
+	if strings.Contains(query, "is_hidden =") || strings.Contains(query, "author_id =") {
+		t.Errorf("empty filter must not restrict the listing:\n%s", query)
+	}
+	if len(args) != 3 {
+		t.Errorf("args = %v, want [m1 51 0]", args)
+	}
+}
+
+func TestRepliesCountColumnReconcilesSyncedAndLocalCounts(t *testing.T) {
+	for _, want := range []string{"GREATEST(comments.replies_count", "c2.parent_id = comments.id"} {
+		if !strings.Contains(repliesCountColumn, want) {
+			t.Errorf("repliesCountColumn does not contain %q: %s", want, repliesCountColumn)
+		}
+	}
+
+	query, _ := mediaCommentsQuery("m1", entity.CommentFilter{}, 10, 0)
+	if !strings.Contains(query, repliesCountColumn) {
+		t.Errorf("media comments query does not read the reconciled count:\n%s", query)
+	}
+}
+
+func TestRecentCommentsQuery(t *testing.T) {
+	since := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	query, args := recentCommentsQuery(entity.RecentCommentsQuery{AccountID: "acc", Since: &since, Limit: 21, Offset: 20})
+
+	for _, want := range []string{
+		"JOIN publications p ON p.instagram_media_id = c.instagram_media_id",
+		"p.account_id = $1 AND p.status = 'published'",
+		"LEFT(COALESCE(p.caption, ''), $2)",
+		"c.timestamp >= $3",
+		"ORDER BY c.timestamp DESC, c.id DESC LIMIT $4 OFFSET $5",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query does not contain %q:\n%s", want, query)
+		}
+	}
+	if len(args) != 5 || args[0] != "acc" || args[1] != entity.CaptionSnippetLength || args[2] != since || args[3] != 21 || args[4] != 20 {
+		t.Errorf("args = %v, want [acc %d since 21 20]", args, entity.CaptionSnippetLength)
+	}
+
+	query, args = recentCommentsQuery(entity.RecentCommentsQuery{AccountID: "acc", Limit: 21})
+	if strings.Contains(query, "c.timestamp >=") || !strings.Contains(query, "LIMIT $3 OFFSET $4") || len(args) != 4 {
+		t.Errorf("query without since = %s %v", query, args)
+	}
+
+	query, args = recentCommentsQuery(entity.RecentCommentsQuery{AccountID: "acc", Mention: "brand", Limit: 21})
+	if !strings.Contains(query, "m.comment_id = c.id AND m.username = $3") || !strings.Contains(query, "LIMIT $4 OFFSET $5") ||
+		len(args) != 5 || args[2] != "brand" {
+		t.Errorf("query with mention = %s %v", query, args)
+	}
+}
@@ -18,6 +18,56 @@ type Comment struct {
 	ParentID        string    `json:"parent_id,omitempty"`         // For replies
 	RepliesCount    int       `json:"replies_count,omitempty"`
 	ReplyToUsername string    `json:"reply_to_username,omitempty"` // Who this is replying to
+	Sentiment       Sentiment `json:"sentiment,omitempty"`         // Empty until classified
+	Replies         []Comment `json:"replies,omitempty"`           // Nested replies in a comment tree
+}
+
+// Sentiment represents the tone of a comment
+type Sentiment string
+
+const (
+	SentimentPositive Sentiment = "positive"
+	SentimentNeutral  Sentiment = "neutral"
+	SentimentNegative Sentiment = "negative"
+)
+
+// IsValid returns true if the sentiment is one of the known values
+func (s Sentiment) IsValid() bool {
+	switch s {
+	case SentimentPositive, SentimentNeutral, SentimentNegative:
+		return true
+	default:
+		return false
+	}
+}
+
+// CommentFilter narrows the comments listed for a media; zero fields match everything
+type CommentFilter struct {
+	Sentiment Sentiment
+	Hidden    *bool
+	AuthorID  string
+	Since     *time.Time // Created at or after
+	Until     *time.Time // Created at or before
+}
+
+// Matches reports whether the comment passes the filter
+func (f CommentFilter) Matches(c Comment) bool {
+	if f.Sentiment != "" && c.Sentiment != f.Sentiment {
+		return false
+	}
+	if f.Hidden != nil && c.IsHidden != *f.Hidden {
+		return false
+	}
+	if f.AuthorID != "" && c.AuthorID != f.AuthorID {
+		return false
+	}
+	if f.Since != nil && c.Timestamp.Before(*f.Since) {
+		return false
+	}
+	if f.Until != nil && c.Timestamp.After(*f.Until) {
+		return false
+	}
+	return true
 }
 
 // Author represents the author of a comment
@@ -33,9 +83,20 @@ var (
 	ErrEmptyReplyText     = errors.New("reply text cannot be empty")
 	ErrReplyTextTooLong   = errors.New("reply text exceeds maximum length")
 	ErrUnauthorized       = errors.New("unauthorized to perform this action")
+	ErrAccountForbidden   = errors.New("not allowed to access this account")
 	ErrCommentingDisabled = errors.New("commenting is disabled for this media")
+	ErrRateLimited        = errors.New("instagram API rate limit exceeded")
+	ErrNoCommentIDs       = errors.New("comment_ids cannot be empty")
+	ErrTooManyCommentIDs  = errors.New("too many comment_ids in a single request")
+	ErrInvalidSentiment   = errors.New("invalid sentiment: must be positive, neutral or negative")
+	ErrSyncInProgress     = errors.New("comment sync is already running for this media")
+	ErrInvalidTreeDepth   = errors.New("invalid depth: must be between 1 and 5")
+	ErrInvalidTopPostsSort = errors.New("invalid sort: must be comments or likes")
 )
 
+// MaxBulkCommentIDs is the maximum number of comments in a single bulk action
+const MaxBulkCommentIDs = 100
+
 // MaxReplyLength is the maximum length of a comment reply
 const MaxReplyLength = 2200
 
@@ -1,17 +1,68 @@
 package entity
 
+import "time"
+
 // CommentStatistics represents aggregated comment statistics for an account
 type CommentStatistics struct {
-	TotalComments      int64     `json:"total_comments"`        // Total count of comments
-	RepliedComments    int64     `json:"replied_comments"`      // Count of replies from account
-	AvgCommentsPerPost float64   `json:"avg_comments_per_post"` // Average comments per post
-	TopPosts           []TopPost `json:"top_posts"`             // Top posts by comment count
+	TotalComments      int64     `json:"total_comments"`                  // Total count of comments
+	RepliedComments    int64     `json:"replied_comments"`                // Count of replies from account
+	AvgCommentsPerPost float64   `json:"avg_comments_per_post"`           // Average comments per post
+	TopPosts           []TopPost `json:"top_posts"`                       // Page of posts ranked by TopPostsSort
+	TopPostsHasMore    bool      `json:"top_posts_has_more"`              // More ranked posts exist after this page
+	TopPostsNextOffset *int      `json:"top_posts_next_offset,omitempty"` // Offset of the next page when TopPostsHasMore
+}
+
+// StatisticsFilter contains filters for comment statistics
+// Nil dates leave that side of the range open (all-time when both are nil).
+type StatisticsFilter struct {
+	AccountID string
+	StartDate *time.Time // Only comments posted at or after StartDate
+	EndDate   *time.Time // Only comments posted at or before EndDate
 }
 
-// TopPost represents a post with its comment count
+// TopPost represents a post with its engagement counts
 type TopPost struct {
 	MediaID       string `json:"media_id"`
 	Caption       string `json:"caption,omitempty"`
 	Thumbnail     string `json:"thumbnail,omitempty"`
 	CommentsCount int64  `json:"comments_count"`
+	LikesCount    int64  `json:"likes_count"` // Sum of likes on the post's comments
+}
+
+// CaptionSnippetLength is the number of post caption characters returned with recent comments
+const CaptionSnippetLength = 100
+
+// RecentComment is a comment listed across an account's posts, with the post it was left on
+type RecentComment struct {
+	Comment
+	Caption string `json:"caption,omitempty"` // Start of the post caption, up to CaptionSnippetLength characters
+}
+
+// RecentCommentsQuery selects a page of the comments on an account's published posts, newest first
+type RecentCommentsQuery struct {
+	AccountID string
+	Since     *time.Time // Only comments posted at or after Since
+	Mention   string     // Only comments mentioning this username, as returned by mention.Normalize
+	Limit     int
+	Offset    int
+}
+
+// TopPostsSort defines how top posts are ranked
+type TopPostsSort string
+
+const (
+	TopPostsSortComments TopPostsSort = "comments"
+	TopPostsSortLikes    TopPostsSort = "likes"
+)
+
+// IsValid returns true if the sort is a known value
+func (s TopPostsSort) IsValid() bool {
+	return s == TopPostsSortComments || s == TopPostsSortLikes
+}
+
+// TopPostsQuery selects a page of ranked posts
+type TopPostsQuery struct {
+	Sort   TopPostsSort
+	Limit  int
+	Offset int
 }
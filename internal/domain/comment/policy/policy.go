@@ -2,10 +2,14 @@ package policy
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/vadim/neo-metric/internal/domain/comment/entity"
 	"github.com/vadim/neo-metric/internal/domain/comment/service"
+	"github.com/vadim/neo-metric/internal/logctx"
 )
 
 // AccountProvider provides access token and user ID for an account
@@ -15,51 +19,232 @@ type AccountProvider interface {
 	GetUsername(ctx context.Context, accountID string) (string, error)
 }
 
+// AccountAuthorizer reports whether the caller may act on an account
+type AccountAuthorizer interface {
+	AccountAllowed(ctx context.Context, accountID string) bool
+}
+
+// MediaOwners looks up which account an Instagram media belongs to
+type MediaOwners interface {
+	// MediaAccountID returns the account that published the media, or an empty string if it is unknown
+	MediaAccountID(ctx context.Context, mediaID string) (string, error)
+}
+
+// InstagramMediaOwners looks up the owner of a media on Instagram, for media not published through the app
+type InstagramMediaOwners interface {
+	// MediaOwnerID returns the Instagram user ID that owns the media, or an empty string if the
+	// access token may not see it
+	MediaOwnerID(ctx context.Context, mediaID, accessToken string) (string, error)
+}
+
 // DirectSender sends direct messages
 type DirectSender interface {
 	SendMessage(ctx context.Context, accountID, recipientID, message string) error
 }
 
+// AuditRecorder records mutating actions for the audit log.
+// Recording is best-effort: implementations handle their own failures.
+type AuditRecorder interface {
+	Record(ctx context.Context, action, accountID, targetID string, err error)
+}
+
+// Audited actions
+const (
+	auditActionHide   = "comment.hide"
+	auditActionUnhide = "comment.unhide"
+	auditActionDelete = "comment.delete"
+)
+
 // CommentService defines the interface for comment operations
 type CommentService interface {
 	GetComments(ctx context.Context, in service.GetCommentsInput) (*service.GetCommentsOutput, error)
 	GetReplies(ctx context.Context, in service.GetRepliesInput) (*service.GetCommentsOutput, error)
+	GetCommentTree(ctx context.Context, in service.GetCommentTreeInput) (*service.GetCommentsOutput, error)
 	CreateComment(ctx context.Context, in service.CreateCommentInput) (string, error)
 	Reply(ctx context.Context, in service.ReplyInput) (string, error)
 	Delete(ctx context.Context, in service.DeleteInput) error
 	Hide(ctx context.Context, in service.HideInput) error
-	GetStatistics(ctx context.Context, accountID string, topPostsLimit int) (*entity.CommentStatistics, error)
+	GetStatistics(ctx context.Context, in service.GetStatisticsInput) (*entity.CommentStatistics, error)
+	GetRecentComments(ctx context.Context, in service.GetRecentCommentsInput) (*service.GetRecentCommentsOutput, error)
 	GetComment(ctx context.Context, commentID string) (*entity.Comment, error)
-	SyncMediaComments(ctx context.Context, mediaID, accessToken string) error
+	SyncMediaComments(ctx context.Context, mediaID, accessToken string) (int, error)
+	GetSyncStatus(ctx context.Context, mediaID string) (*service.SyncStatus, error)
+	ResetFailedSyncs(ctx context.Context, accountID string, mediaIDs []string) ([]string, error)
 }
 
 // Policy handles business policies for comments
 type Policy struct {
-	svc      CommentService
-	accounts AccountProvider
-	direct   DirectSender // optional, for send_to_direct
+	svc       CommentService
+	accounts  AccountProvider
+	authz     AccountAuthorizer    // optional, all accounts allowed if unset
+	owners    MediaOwners          // optional, media published through the app
+	igOwners  InstagramMediaOwners // optional, other media; media ownership is not checked without either lookup
+	direct    DirectSender         // optional, for send_to_direct
+	audit     AuditRecorder        // optional
+	bulkDelay time.Duration        // pause between Instagram calls in bulk actions
+
+	confirmed sync.Map // "accountID/mediaID" of media confirmed as the account's on Instagram
 }
 
 // New creates a new comment policy
 func New(svc CommentService, accounts AccountProvider) *Policy {
 	return &Policy{
-		svc:      svc,
-		accounts: accounts,
+		svc:       svc,
+		accounts:  accounts,
+		bulkDelay: 200 * time.Millisecond,
 	}
 }
 
+// WithBulkDelay sets the pause between Instagram calls in bulk actions
+func (p *Policy) WithBulkDelay(d time.Duration) *Policy {
+	p.bulkDelay = d
+	return p
+}
+
 // WithDirectSender sets the DirectSender for send_to_direct functionality
 func (p *Policy) WithDirectSender(ds DirectSender) *Policy {
 	p.direct = ds
 	return p
 }
 
+// WithAuditRecorder records comment hiding and deletion in the audit log
+func (p *Policy) WithAuditRecorder(r AuditRecorder) *Policy {
+	p.audit = r
+	return p
+}
+
+// WithAccountAuthorizer restricts every operation to the accounts the caller may act on
+func (p *Policy) WithAccountAuthorizer(a AccountAuthorizer) *Policy {
+	p.authz = a
+	return p
+}
+
+// WithMediaOwners checks that media published through the app belong to the account acted on
+func (p *Policy) WithMediaOwners(o MediaOwners) *Policy {
+	p.owners = o
+	return p
+}
+
+// WithInstagramMediaOwners checks that media unknown to the app belong to the account acted on by asking Instagram
+func (p *Policy) WithInstagramMediaOwners(o InstagramMediaOwners) *Policy {
+	p.igOwners = o
+	return p
+}
+
+// authorize returns ErrAccountForbidden if the caller may not act on the account
+func (p *Policy) authorize(ctx context.Context, accountID string) error {
+	if p.authz != nil && !p.authz.AccountAllowed(ctx, accountID) {
+		return entity.ErrAccountForbidden
+	}
+	return nil
+}
+
+// accessToken returns the account's access token once the caller is authorized for it
+func (p *Policy) accessToken(ctx context.Context, accountID string) (string, error) {
+	if err := p.authorize(ctx, accountID); err != nil {
+		return "", err
+	}
+	return p.accounts.GetAccessToken(ctx, accountID)
+}
+
+// checkMedia returns ErrMediaNotFound if the media does not belong to the account. Media published
+// through the app are looked up locally and others on Instagram; an error means ownership could not
+// be resolved, and the request must be refused.
+func (p *Policy) checkMedia(ctx context.Context, accountID, accessToken, mediaID string) error {
+	if p.owners == nil && p.igOwners == nil {
+		return nil
+	}
+
+	if p.owners != nil {
+		owner, err := p.owners.MediaAccountID(ctx, mediaID)
+		if err != nil {
+			return fmt.Errorf("getting media owner: %w", err)
+		}
+		if owner != "" {
+			if owner != accountID {
+				return entity.ErrMediaNotFound
+			}
+			return nil
+		}
+	}
+	if p.igOwners == nil {
+		return entity.ErrMediaNotFound
+	}
+
+	// A media never changes owner, so a confirmed one is not looked up again
+	key := accountID + "/" + mediaID
+	if _, ok := p.confirmed.Load(key); ok {
+		return nil
+	}
+	ownerID, err := p.igOwners.MediaOwnerID(ctx, mediaID, accessToken)
+	if err != nil {
+		return fmt.Errorf("getting media owner: %w", err)
+	}
+	userID, err := p.accounts.GetInstagramUserID(ctx, accountID)
+	if err != nil {
+		return fmt.Errorf("getting instagram user id: %w", err)
+	}
+	if ownerID == "" || ownerID != userID {
+		return entity.ErrMediaNotFound
+	}
+	p.confirmed.Store(key, struct{}{})
+	return nil
+}
+
+// checkComment returns ErrCommentNotFound unless the comment is stored and on media of the account.
+// Without a media owner lookup it checks nothing.
+func (p *Policy) checkComment(ctx context.Context, accountID, accessToken, commentID string) error {
+	if p.owners == nil && p.igOwners == nil {
+		return nil
+	}
+	_, err := p.ownComment(ctx, accountID, accessToken, commentID)
+	return err
+}
+
+// ownComment returns the stored comment if it is on media of the account, or ErrCommentNotFound
+func (p *Policy) ownComment(ctx context.Context, accountID, accessToken, commentID string) (*entity.Comment, error) {
+	comment, err := p.svc.GetComment(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment == nil {
+		return nil, entity.ErrCommentNotFound
+	}
+	if err := p.checkMedia(ctx, accountID, accessToken, comment.MediaID); err != nil {
+		if errors.Is(err, entity.ErrMediaNotFound) {
+			return nil, entity.ErrCommentNotFound
+		}
+		return nil, err
+	}
+	return comment, nil
+}
+
+// record writes an audit entry if an AuditRecorder is set
+func (p *Policy) record(ctx context.Context, action, accountID, targetID string, err error) {
+	if p.audit != nil {
+		p.audit.Record(ctx, action, accountID, targetID, err)
+	}
+}
+
+// hideAction returns the audited action for hiding or unhiding a comment
+func hideAction(hide bool) string {
+	if hide {
+		return auditActionHide
+	}
+	return auditActionUnhide
+}
+
 // GetCommentsInput represents input for getting comments
 type GetCommentsInput struct {
 	AccountID string
 	MediaID   string
 	Limit     int
 	After     string
+	Sentiment entity.Sentiment
+	Hidden    *bool
+	AuthorID  string
+	Since     *time.Time
+	Until     *time.Time
 }
 
 // GetCommentsOutput represents output from getting comments
@@ -71,16 +256,26 @@ type GetCommentsOutput struct {
 
 // GetComments retrieves comments for a media
 func (p *Policy) GetComments(ctx context.Context, in GetCommentsInput) (*GetCommentsOutput, error) {
-	accessToken, err := p.accounts.GetAccessToken(ctx, in.AccountID)
+	accessToken, err := p.accessToken(ctx, in.AccountID)
 	if err != nil {
 		return nil, err
 	}
+	// Checked first: the service serves stored comments when Instagram fails
+	if err := p.checkMedia(ctx, in.AccountID, accessToken, in.MediaID); err != nil {
+		return nil, err
+	}
 
 	result, err := p.svc.GetComments(ctx, service.GetCommentsInput{
+		AccountID:   in.AccountID,
 		MediaID:     in.MediaID,
 		AccessToken: accessToken,
 		Limit:       in.Limit,
 		After:       in.After,
+		Sentiment:   in.Sentiment,
+		Hidden:      in.Hidden,
+		AuthorID:    in.AuthorID,
+		Since:       in.Since,
+		Until:       in.Until,
 	})
 	if err != nil {
 		return nil, err
@@ -93,6 +288,22 @@ func (p *Policy) GetComments(ctx context.Context, in GetCommentsInput) (*GetComm
 	}, nil
 }
 
+// GetCommentInput represents input for getting a single comment
+type GetCommentInput struct {
+	AccountID string
+	CommentID string
+}
+
+// GetComment retrieves a single comment with its replies count.
+// Comments on media of another account are reported as not found.
+func (p *Policy) GetComment(ctx context.Context, in GetCommentInput) (*entity.Comment, error) {
+	accessToken, err := p.accessToken(ctx, in.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	return p.ownComment(ctx, in.AccountID, accessToken, in.CommentID)
+}
+
 // GetRepliesInput represents input for getting replies
 type GetRepliesInput struct {
 	AccountID string
@@ -103,10 +314,13 @@ type GetRepliesInput struct {
 
 // GetReplies retrieves replies to a comment
 func (p *Policy) GetReplies(ctx context.Context, in GetRepliesInput) (*GetCommentsOutput, error) {
-	accessToken, err := p.accounts.GetAccessToken(ctx, in.AccountID)
+	accessToken, err := p.accessToken(ctx, in.AccountID)
 	if err != nil {
 		return nil, err
 	}
+	if err := p.checkComment(ctx, in.AccountID, accessToken, in.CommentID); err != nil {
+		return nil, err
+	}
 
 	result, err := p.svc.GetReplies(ctx, service.GetRepliesInput{
 		CommentID:   in.CommentID,
@@ -125,6 +339,46 @@ func (p *Policy) GetReplies(ctx context.Context, in GetRepliesInput) (*GetCommen
 	}, nil
 }
 
+// GetCommentTreeInput represents input for getting a threaded comment tree
+type GetCommentTreeInput struct {
+	AccountID  string
+	MediaID    string
+	Limit      int
+	After      string
+	Depth      int
+	MaxReplies int
+}
+
+// GetCommentTree retrieves top-level comments of a media with their replies nested inline
+func (p *Policy) GetCommentTree(ctx context.Context, in GetCommentTreeInput) (*GetCommentsOutput, error) {
+	accessToken, err := p.accessToken(ctx, in.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkMedia(ctx, in.AccountID, accessToken, in.MediaID); err != nil {
+		return nil, err
+	}
+
+	result, err := p.svc.GetCommentTree(ctx, service.GetCommentTreeInput{
+		AccountID:   in.AccountID,
+		MediaID:     in.MediaID,
+		AccessToken: accessToken,
+		Limit:       in.Limit,
+		After:       in.After,
+		Depth:       in.Depth,
+		MaxReplies:  in.MaxReplies,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetCommentsOutput{
+		Comments:   result.Comments,
+		NextCursor: result.NextCursor,
+		HasMore:    result.HasMore,
+	}, nil
+}
+
 // CreateCommentInput represents input for creating a comment
 type CreateCommentInput struct {
 	AccountID string
@@ -139,10 +393,13 @@ type CreateCommentOutput struct {
 
 // CreateComment creates a new comment on a media
 func (p *Policy) CreateComment(ctx context.Context, in CreateCommentInput) (*CreateCommentOutput, error) {
-	accessToken, err := p.accounts.GetAccessToken(ctx, in.AccountID)
+	accessToken, err := p.accessToken(ctx, in.AccountID)
 	if err != nil {
 		return nil, err
 	}
+	if err := p.checkMedia(ctx, in.AccountID, accessToken, in.MediaID); err != nil {
+		return nil, err
+	}
 
 	id, err := p.svc.CreateComment(ctx, service.CreateCommentInput{
 		MediaID:     in.MediaID,
@@ -173,10 +430,13 @@ type ReplyOutput struct {
 
 // Reply posts a reply to a comment
 func (p *Policy) Reply(ctx context.Context, in ReplyInput) (*ReplyOutput, error) {
-	accessToken, err := p.accounts.GetAccessToken(ctx, in.AccountID)
+	accessToken, err := p.accessToken(ctx, in.AccountID)
 	if err != nil {
 		return nil, err
 	}
+	if err := p.checkComment(ctx, in.AccountID, accessToken, in.CommentID); err != nil {
+		return nil, err
+	}
 
 	// Get username for the account (to store with the reply)
 	username, err := p.accounts.GetUsername(ctx, in.AccountID)
@@ -228,15 +488,19 @@ type DeleteInput struct {
 
 // Delete removes a comment
 func (p *Policy) Delete(ctx context.Context, in DeleteInput) error {
-	accessToken, err := p.accounts.GetAccessToken(ctx, in.AccountID)
-	if err != nil {
-		return err
+	accessToken, err := p.accessToken(ctx, in.AccountID)
+	if err == nil {
+		err = p.checkComment(ctx, in.AccountID, accessToken, in.CommentID)
+	}
+	if err == nil {
+		err = p.svc.Delete(ctx, service.DeleteInput{
+			CommentID:   in.CommentID,
+			AccessToken: accessToken,
+		})
 	}
 
-	return p.svc.Delete(ctx, service.DeleteInput{
-		CommentID:   in.CommentID,
-		AccessToken: accessToken,
-	})
+	p.record(ctx, auditActionDelete, in.AccountID, in.CommentID, err)
+	return err
 }
 
 // HideInput represents input for hiding a comment
@@ -248,27 +512,153 @@ type HideInput struct {
 
 // Hide hides or unhides a comment
 func (p *Policy) Hide(ctx context.Context, in HideInput) error {
-	accessToken, err := p.accounts.GetAccessToken(ctx, in.AccountID)
-	if err != nil {
-		return err
+	accessToken, err := p.accessToken(ctx, in.AccountID)
+	if err == nil {
+		err = p.checkComment(ctx, in.AccountID, accessToken, in.CommentID)
+	}
+	if err == nil {
+		err = p.svc.Hide(ctx, service.HideInput{
+			CommentID:   in.CommentID,
+			AccessToken: accessToken,
+			Hide:        in.Hide,
+		})
 	}
 
-	return p.svc.Hide(ctx, service.HideInput{
-		CommentID:   in.CommentID,
-		AccessToken: accessToken,
-		Hide:        in.Hide,
+	p.record(ctx, hideAction(in.Hide), in.AccountID, in.CommentID, err)
+	return err
+}
+
+// BulkInput represents input for a bulk hide or delete
+type BulkInput struct {
+	AccountID  string
+	CommentIDs []string
+	Hide       bool // Only used by BulkHide
+}
+
+// BulkResult represents the outcome of a bulk action for a single comment
+type BulkResult struct {
+	CommentID string `json:"comment_id"`
+	Success   bool   `json:"success"`
+	Error     string `json:"error,omitempty"`
+}
+
+// BulkHide hides or unhides several comments, continuing past individual failures
+func (p *Policy) BulkHide(ctx context.Context, in BulkInput) ([]BulkResult, error) {
+	return p.runBulk(ctx, in, hideAction(in.Hide), func(ctx context.Context, accessToken, commentID string) error {
+		return p.svc.Hide(ctx, service.HideInput{
+			CommentID:   commentID,
+			AccessToken: accessToken,
+			Hide:        in.Hide,
+		})
+	})
+}
+
+// BulkDelete deletes several comments, continuing past individual failures
+func (p *Policy) BulkDelete(ctx context.Context, in BulkInput) ([]BulkResult, error) {
+	return p.runBulk(ctx, in, auditActionDelete, func(ctx context.Context, accessToken, commentID string) error {
+		return p.svc.Delete(ctx, service.DeleteInput{
+			CommentID:   commentID,
+			AccessToken: accessToken,
+		})
 	})
 }
 
+// runBulk applies fn to each comment ID with a pause between calls, auditing each attempt as action.
+// Once Instagram reports throttling, the remaining IDs are not attempted.
+func (p *Policy) runBulk(ctx context.Context, in BulkInput, action string, fn func(ctx context.Context, accessToken, commentID string) error) ([]BulkResult, error) {
+	if len(in.CommentIDs) == 0 {
+		return nil, entity.ErrNoCommentIDs
+	}
+	if len(in.CommentIDs) > entity.MaxBulkCommentIDs {
+		return nil, entity.ErrTooManyCommentIDs
+	}
+
+	accessToken, err := p.accessToken(ctx, in.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BulkResult, len(in.CommentIDs))
+	var stopErr error
+	for i, id := range in.CommentIDs {
+		results[i].CommentID = id
+
+		if stopErr == nil && i > 0 && p.bulkDelay > 0 {
+			select {
+			case <-ctx.Done():
+				stopErr = ctx.Err()
+			case <-time.After(p.bulkDelay):
+			}
+		}
+		if stopErr != nil {
+			results[i].Error = stopErr.Error()
+			continue
+		}
+
+		err := p.checkComment(ctx, in.AccountID, accessToken, id)
+		if err == nil {
+			err = fn(ctx, accessToken, id)
+		}
+		p.record(ctx, action, in.AccountID, id, err)
+		if err != nil {
+			results[i].Error = err.Error()
+			if errors.Is(err, entity.ErrRateLimited) {
+				stopErr = entity.ErrRateLimited
+			}
+			continue
+		}
+		results[i].Success = true
+	}
+
+	return results, nil
+}
+
 // GetStatisticsInput represents input for getting comment statistics
 type GetStatisticsInput struct {
-	AccountID     string
-	TopPostsLimit int
+	AccountID      string
+	StartDate      *time.Time
+	EndDate        *time.Time
+	TopPostsSort   entity.TopPostsSort
+	TopPostsLimit  int
+	TopPostsOffset int
 }
 
 // GetStatistics retrieves aggregated comment statistics for an account
 func (p *Policy) GetStatistics(ctx context.Context, in GetStatisticsInput) (*entity.CommentStatistics, error) {
-	return p.svc.GetStatistics(ctx, in.AccountID, in.TopPostsLimit)
+	if err := p.authorize(ctx, in.AccountID); err != nil {
+		return nil, err
+	}
+	return p.svc.GetStatistics(ctx, service.GetStatisticsInput{
+		AccountID:      in.AccountID,
+		StartDate:      in.StartDate,
+		EndDate:        in.EndDate,
+		TopPostsSort:   in.TopPostsSort,
+		TopPostsLimit:  in.TopPostsLimit,
+		TopPostsOffset: in.TopPostsOffset,
+	})
+}
+
+// GetRecentCommentsInput represents input for listing comments across an account's posts
+type GetRecentCommentsInput struct {
+	AccountID string
+	Since     *time.Time
+	Mention   string // Only comments mentioning this username, lowercased without the @
+	Limit     int
+	After     string
+}
+
+// GetRecentComments lists the newest stored comments across the account's published posts
+func (p *Policy) GetRecentComments(ctx context.Context, in GetRecentCommentsInput) (*service.GetRecentCommentsOutput, error) {
+	if err := p.authorize(ctx, in.AccountID); err != nil {
+		return nil, err
+	}
+	return p.svc.GetRecentComments(ctx, service.GetRecentCommentsInput{
+		AccountID: in.AccountID,
+		Since:     in.Since,
+		Mention:   in.Mention,
+		Limit:     in.Limit,
+		After:     in.After,
+	})
 }
 
 // SyncCommentsInput represents input for syncing comments
@@ -277,12 +667,55 @@ type SyncCommentsInput struct {
 	MediaID   string
 }
 
+// SyncCommentsOutput represents the result of a manual comment sync
+type SyncCommentsOutput struct {
+	MediaID      string     `json:"media_id"`
+	Synced       int        `json:"synced"`
+	LastSyncedAt *time.Time `json:"last_synced_at,omitempty"`
+	SyncComplete bool       `json:"sync_complete"`
+}
+
 // SyncComments manually syncs comments for a specific media
-func (p *Policy) SyncComments(ctx context.Context, in SyncCommentsInput) error {
-	accessToken, err := p.accounts.GetAccessToken(ctx, in.AccountID)
+func (p *Policy) SyncComments(ctx context.Context, in SyncCommentsInput) (*SyncCommentsOutput, error) {
+	ctx = logctx.With(ctx, "account_id", in.AccountID)
+	accessToken, err := p.accessToken(ctx, in.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkMedia(ctx, in.AccountID, accessToken, in.MediaID); err != nil {
+		return nil, err
+	}
+
+	synced, err := p.svc.SyncMediaComments(ctx, in.MediaID, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &SyncCommentsOutput{MediaID: in.MediaID, Synced: synced}
+
+	status, err := p.svc.GetSyncStatus(ctx, in.MediaID)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	if status != nil {
+		out.LastSyncedAt = &status.LastSyncedAt
+		out.SyncComplete = status.SyncComplete
+	}
+
+	return out, nil
+}
 
-	return p.svc.SyncMediaComments(ctx, in.MediaID, accessToken)
+// ResetFailedSyncsInput represents input for re-enabling failed comment syncs
+type ResetFailedSyncsInput struct {
+	AccountID string
+	MediaIDs  []string // Optional, limits the reset to these media
+}
+
+// ResetFailedSyncs puts the account's media excluded from comment sync after repeated
+// failures back into the sync rotation and returns the media that were reset
+func (p *Policy) ResetFailedSyncs(ctx context.Context, in ResetFailedSyncsInput) ([]string, error) {
+	if err := p.authorize(ctx, in.AccountID); err != nil {
+		return nil, err
+	}
+	return p.svc.ResetFailedSyncs(ctx, in.AccountID, in.MediaIDs)
 }
@@ -0,0 +1,356 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/vadim/neo-metric/internal/domain/comment/entity"
+	"github.com/vadim/neo-metric/internal/domain/comment/service"
+)
+
+// fakeInstagram records hide/delete calls and fails for configured IDs
+type fakeInstagram struct {
+	service.InstagramClient
+
+	mu      sync.Mutex
+	hidden  map[string]bool
+	deleted []string
+	fail    map[string]error
+}
+
+func newFakeInstagram() *fakeInstagram {
+	return &fakeInstagram{hidden: make(map[string]bool), fail: make(map[string]error)}
+}
+
+func (f *fakeInstagram) HideComment(_ context.Context, commentID, _ string, hide bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail[commentID]; err != nil {
+		return err
+	}
+	f.hidden[commentID] = hide
+	return nil
+}
+
+func (f *fakeInstagram) DeleteComment(_ context.Context, commentID, _ string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.fail[commentID]; err != nil {
+		return err
+	}
+	f.deleted = append(f.deleted, commentID)
+	return nil
+}
+
+type fakeAccounts struct{}
+
+func (fakeAccounts) GetAccessToken(context.Context, string) (string, error)     { return "token", nil }
+func (fakeAccounts) GetInstagramUserID(context.Context, string) (string, error) { return "ig_1", nil }
+func (fakeAccounts) GetUsername(context.Context, string) (string, error)        { return "user", nil }
+
+func TestBulkHideProcessesEveryID(t *testing.T) {
+	ig := newFakeInstagram()
+	ig.fail["c2"] = errors.New("boom")
+	p := New(service.New(ig), fakeAccounts{}).WithBulkDelay(0)
+
+	ids := []string{"c1", "c2", "c3"}
+	results, err := p.BulkHide(context.Background(), BulkInput{AccountID: "acc", CommentIDs: ids, Hide: true})
+	if err != nil {
+		t.Fatalf("BulkHide: %v", err)
+	}
+
+	if len(results) != len(ids) {
+		t.Fatalf("got %d results, want %d", len(results), len(ids))
+	}
+	for i, res := range results {
+		if res.CommentID != ids[i] {
+			t.Errorf("result %d is for %s, want %s", i, res.CommentID, ids[i])
+		}
+		wantSuccess := ids[i] != "c2"
+		if res.Success != wantSuccess {
+			t.Errorf("%s: success = %v, want %v", res.CommentID, res.Success, wantSuccess)
+		}
+	}
+	if !ig.hidden["c1"] || !ig.hidden["c3"] {
+		t.Errorf("expected c1 and c3 hidden, got %v", ig.hidden)
+	}
+}
+
+func TestBulkDeleteStopsOnRateLimit(t *testing.T) {
+	ig := newFakeInstagram()
+	ig.fail["c2"] = fmt.Errorf("%w: throttled", entity.ErrRateLimited)
+	p := New(service.New(ig), fakeAccounts{}).WithBulkDelay(0)
+
+	results, err := p.BulkDelete(context.Background(), BulkInput{AccountID: "acc", CommentIDs: []string{"c1", "c2", "c3"}})
+	if err != nil {
+		t.Fatalf("BulkDelete: %v", err)
+	}
+
+	if len(ig.deleted) != 1 || ig.deleted[0] != "c1" {
+		t.Fatalf("deleted = %v, want [c1]", ig.deleted)
+	}
+	if results[2].Success || results[2].Error != entity.ErrRateLimited.Error() {
+		t.Errorf("c3 result = %+v, want skipped with rate limit error", results[2])
+	}
+}
+
+func TestBulkRejectsEmptyAndOversizedInput(t *testing.T) {
+	p := New(service.New(newFakeInstagram()), fakeAccounts{})
+
+	if _, err := p.BulkHide(context.Background(), BulkInput{AccountID: "acc"}); err != entity.ErrNoCommentIDs {
+		t.Errorf("empty: err = %v, want ErrNoCommentIDs", err)
+	}
+
+	ids := make([]string, entity.MaxBulkCommentIDs+1)
+	if _, err := p.BulkDelete(context.Background(), BulkInput{AccountID: "acc", CommentIDs: ids}); err != entity.ErrTooManyCommentIDs {
+		t.Errorf("oversized: err = %v, want ErrTooManyCommentIDs", err)
+	}
+}
+
+// scopedAuthorizer allows only the listed accounts
+type scopedAuthorizer map[string]bool
+
+func (s scopedAuthorizer) AccountAllowed(_ context.Context, accountID string) bool {
+	return s[accountID]
+}
+
+func TestHideChecksAccountScope(t *testing.T) {
+	ig := newFakeInstagram()
+	p := New(service.New(ig), fakeAccounts{}).WithAccountAuthorizer(scopedAuthorizer{"acc": true})
+
+	if err := p.Hide(context.Background(), HideInput{AccountID: "acc", CommentID: "c1", Hide: true}); err != nil {
+		t.Fatalf("allowed account: %v", err)
+	}
+	if err := p.Hide(context.Background(), HideInput{AccountID: "other", CommentID: "c2", Hide: true}); err != entity.ErrAccountForbidden {
+		t.Fatalf("other account: err = %v, want ErrAccountForbidden", err)
+	}
+	if !ig.hidden["c1"] || ig.hidden["c2"] {
+		t.Errorf("hidden = %v, want only c1", ig.hidden)
+	}
+
+	if _, err := p.BulkDelete(context.Background(), BulkInput{AccountID: "other", CommentIDs: []string{"c3"}}); err != entity.ErrAccountForbidden {
+		t.Errorf("bulk on other account: err = %v, want ErrAccountForbidden", err)
+	}
+	if len(ig.deleted) != 0 {
+		t.Errorf("deleted = %v, want none", ig.deleted)
+	}
+}
+
+// storedComments serves comments by ID
+type storedComments struct {
+	CommentService
+	comments map[string]*entity.Comment
+}
+
+func (s storedComments) GetComment(_ context.Context, commentID string) (*entity.Comment, error) {
+	return s.comments[commentID], nil
+}
+
+// mediaOwners maps media IDs to their accounts
+type mediaOwners map[string]string
+
+func (m mediaOwners) MediaAccountID(_ context.Context, mediaID string) (string, error) {
+	return m[mediaID], nil
+}
+
+func TestGetCommentChecksMediaOwner(t *testing.T) {
+	svc := storedComments{comments: map[string]*entity.Comment{
+		"c1": {ID: "c1", MediaID: "m1"},
+		"c2": {ID: "c2", MediaID: "m2"},
+		"c3": {ID: "c3", MediaID: "unknown"},
+	}}
+	p := New(svc, fakeAccounts{}).WithMediaOwners(mediaOwners{"m1": "acc", "m2": "other"})
+
+	got, err := p.GetComment(context.Background(), GetCommentInput{AccountID: "acc", CommentID: "c1"})
+	if err != nil || got.ID != "c1" {
+		t.Fatalf("own comment: got %v, err %v", got, err)
+	}
+
+	for _, id := range []string{"c2", "c3", "c4"} {
+		if _, err := p.GetComment(context.Background(), GetCommentInput{AccountID: "acc", CommentID: id}); err != entity.ErrCommentNotFound {
+			t.Errorf("%s: err = %v, want ErrCommentNotFound", id, err)
+		}
+	}
+}
+
+// instagramOwners maps media IDs to the Instagram users that own them and counts lookups
+type instagramOwners struct {
+	owners map[string]string
+	err    error
+	calls  int
+}
+
+func (o *instagramOwners) MediaOwnerID(_ context.Context, mediaID, _ string) (string, error) {
+	o.calls++
+	return o.owners[mediaID], o.err
+}
+
+func TestGetCommentResolvesOwnerOnInstagram(t *testing.T) {
+	svc := storedComments{comments: map[string]*entity.Comment{
+		"c1": {ID: "c1", MediaID: "ext1"},
+		"c2": {ID: "c2", MediaID: "ext2"},
+		"c3": {ID: "c3", MediaID: "m2"},
+	}}
+	ig := &instagramOwners{owners: map[string]string{"ext1": "ig_1", "ext2": "ig_other"}}
+	p := New(svc, fakeAccounts{}).WithMediaOwners(mediaOwners{"m2": "other"}).WithInstagramMediaOwners(ig)
+	ctx := context.Background()
+
+	// Media published outside the app is served once Instagram confirms the owner
+	for i := 0; i < 2; i++ {
+		if got, err := p.GetComment(ctx, GetCommentInput{AccountID: "acc", CommentID: "c1"}); err != nil || got.ID != "c1" {
+			t.Fatalf("comment on own external media: got %v, err %v", got, err)
+		}
+	}
+	if ig.calls != 1 {
+		t.Errorf("instagram lookups = %d, want 1 once the owner is confirmed", ig.calls)
+	}
+
+	for _, id := range []string{"c2", "c3"} {
+		if _, err := p.GetComment(ctx, GetCommentInput{AccountID: "acc", CommentID: id}); err != entity.ErrCommentNotFound {
+			t.Errorf("%s: err = %v, want ErrCommentNotFound", id, err)
+		}
+	}
+}
+
+func TestGetCommentRefusedWhenOwnerUnresolved(t *testing.T) {
+	svc := storedComments{comments: map[string]*entity.Comment{"c1": {ID: "c1", MediaID: "ext1"}}}
+	lookupErr := errors.New("instagram unavailable")
+	p := New(svc, fakeAccounts{}).WithInstagramMediaOwners(&instagramOwners{err: lookupErr})
+
+	if _, err := p.GetComment(context.Background(), GetCommentInput{AccountID: "acc", CommentID: "c1"}); !errors.Is(err, lookupErr) {
+		t.Errorf("err = %v, want the lookup error", err)
+	}
+}
+
+func TestGetCommentWithoutOwnerLookup(t *testing.T) {
+	svc := storedComments{comments: map[string]*entity.Comment{"c1": {ID: "c1", MediaID: "m1"}}}
+	p := New(svc, fakeAccounts{})
+
+	if got, err := p.GetComment(context.Background(), GetCommentInput{AccountID: "acc", CommentID: "c1"}); err != nil || got.ID != "c1" {
+		t.Errorf("got %v, err %v, want the stored comment", got, err)
+	}
+}
+
+// moderatedComments serves stored comments and records moderation calls
+type moderatedComments struct {
+	storedComments
+	hidden, deleted []string
+	trees, replies  int
+}
+
+func (s *moderatedComments) Hide(_ context.Context, in service.HideInput) error {
+	s.hidden = append(s.hidden, in.CommentID)
+	return nil
+}
+
+func (s *moderatedComments) Delete(_ context.Context, in service.DeleteInput) error {
+	s.deleted = append(s.deleted, in.CommentID)
+	return nil
+}
+
+func (s *moderatedComments) GetCommentTree(context.Context, service.GetCommentTreeInput) (*service.GetCommentsOutput, error) {
+	s.trees++
+	return &service.GetCommentsOutput{}, nil
+}
+
+func (s *moderatedComments) GetReplies(context.Context, service.GetRepliesInput) (*service.GetCommentsOutput, error) {
+	s.replies++
+	return &service.GetCommentsOutput{}, nil
+}
+
+func TestCommentEndpointsCheckMediaOwner(t *testing.T) {
+	svc := &moderatedComments{storedComments: storedComments{comments: map[string]*entity.Comment{
+		"own":   {ID: "own", MediaID: "m1"},
+		"other": {ID: "other", MediaID: "m2"},
+	}}}
+	p := New(svc, fakeAccounts{}).WithMediaOwners(mediaOwners{"m1": "acc", "m2": "other"}).WithBulkDelay(0)
+	ctx := context.Background()
+
+	if _, err := p.GetCommentTree(ctx, GetCommentTreeInput{AccountID: "acc", MediaID: "m2"}); err != entity.ErrMediaNotFound {
+		t.Errorf("tree of other media: err = %v, want ErrMediaNotFound", err)
+	}
+	if _, err := p.GetReplies(ctx, GetRepliesInput{AccountID: "acc", CommentID: "other"}); err != entity.ErrCommentNotFound {
+		t.Errorf("replies of other comment: err = %v, want ErrCommentNotFound", err)
+	}
+	if err := p.Hide(ctx, HideInput{AccountID: "acc", CommentID: "other", Hide: true}); err != entity.ErrCommentNotFound {
+		t.Errorf("hide other comment: err = %v, want ErrCommentNotFound", err)
+	}
+	if err := p.Delete(ctx, DeleteInput{AccountID: "acc", CommentID: "unsynced"}); err != entity.ErrCommentNotFound {
+		t.Errorf("delete unknown comment: err = %v, want ErrCommentNotFound", err)
+	}
+	results, err := p.BulkHide(ctx, BulkInput{AccountID: "acc", CommentIDs: []string{"own", "other"}, Hide: true})
+	if err != nil {
+		t.Fatalf("BulkHide: %v", err)
+	}
+	if !results[0].Success || results[1].Success {
+		t.Errorf("bulk results = %+v, want only own hidden", results)
+	}
+
+	if svc.trees != 0 || svc.replies != 0 || len(svc.deleted) != 0 {
+		t.Errorf("service reached for other media: trees %d, replies %d, deleted %v", svc.trees, svc.replies, svc.deleted)
+	}
+	if len(svc.hidden) != 1 || svc.hidden[0] != "own" {
+		t.Errorf("hidden = %v, want [own]", svc.hidden)
+	}
+
+	if _, err := p.GetCommentTree(ctx, GetCommentTreeInput{AccountID: "acc", MediaID: "m1"}); err != nil {
+		t.Errorf("tree of own media: %v", err)
+	}
+}
+
+// writtenComments serves stored comments and counts calls that read or write comments of a media
+type writtenComments struct {
+	storedComments
+	calls int
+}
+
+func (s *writtenComments) GetComments(context.Context, service.GetCommentsInput) (*service.GetCommentsOutput, error) {
+	s.calls++
+	return &service.GetCommentsOutput{}, nil
+}
+
+func (s *writtenComments) CreateComment(context.Context, service.CreateCommentInput) (string, error) {
+	s.calls++
+	return "new", nil
+}
+
+func (s *writtenComments) Reply(context.Context, service.ReplyInput) (string, error) {
+	s.calls++
+	return "reply", nil
+}
+
+func (s *writtenComments) SyncMediaComments(context.Context, string, string) (int, error) {
+	s.calls++
+	return 0, nil
+}
+
+func TestMediaEndpointsCheckMediaOwner(t *testing.T) {
+	svc := &writtenComments{storedComments: storedComments{comments: map[string]*entity.Comment{
+		"other": {ID: "other", MediaID: "m2"},
+	}}}
+	p := New(svc, fakeAccounts{}).WithMediaOwners(mediaOwners{"m1": "acc", "m2": "other"})
+	ctx := context.Background()
+
+	if _, err := p.GetComments(ctx, GetCommentsInput{AccountID: "acc", MediaID: "m2"}); err != entity.ErrMediaNotFound {
+		t.Errorf("comments of other media: err = %v, want ErrMediaNotFound", err)
+	}
+	if _, err := p.CreateComment(ctx, CreateCommentInput{AccountID: "acc", MediaID: "m2", Message: "hi"}); err != entity.ErrMediaNotFound {
+		t.Errorf("comment on other media: err = %v, want ErrMediaNotFound", err)
+	}
+	if _, err := p.SyncComments(ctx, SyncCommentsInput{AccountID: "acc", MediaID: "m2"}); err != entity.ErrMediaNotFound {
+		t.Errorf("sync of other media: err = %v, want ErrMediaNotFound", err)
+	}
+	if _, err := p.Reply(ctx, ReplyInput{AccountID: "acc", CommentID: "other", Message: "hi"}); err != entity.ErrCommentNotFound {
+		t.Errorf("reply to other comment: err = %v, want ErrCommentNotFound", err)
+	}
+	if svc.calls != 0 {
+		t.Errorf("service reached %d times for other media, want 0", svc.calls)
+	}
+
+	if _, err := p.GetComments(ctx, GetCommentsInput{AccountID: "acc", MediaID: "m1"}); err != nil {
+		t.Errorf("comments of own media: %v", err)
+	}
+}
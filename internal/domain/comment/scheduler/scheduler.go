@@ -2,15 +2,21 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"sync"
 	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/comment/entity"
+	"github.com/vadim/neo-metric/internal/jitter"
+	"github.com/vadim/neo-metric/internal/liveness"
+	"github.com/vadim/neo-metric/internal/logctx"
 )
 
 // CommentSyncer defines the interface for syncing comments
 type CommentSyncer interface {
-	SyncMediaComments(ctx context.Context, mediaID, accessToken string) error
-	GetMediaIDsNeedingSync(ctx context.Context, olderThan time.Duration, limit int) ([]string, error)
+	SyncMediaComments(ctx context.Context, mediaID, accessToken string) (int, error)
+	GetMediaIDsNeedingSync(ctx context.Context, olderThan, maxPostAge time.Duration, limit int) ([]string, error)
 	IncrementSyncRetryCount(ctx context.Context, mediaID string, lastError string, maxRetries int) error
 	ResetSyncRetryCount(ctx context.Context, mediaID string) error
 }
@@ -25,15 +31,26 @@ type AccountProvider interface {
 	GetAccessToken(ctx context.Context, accountID string) (string, error)
 }
 
+// Throttle reports whether Instagram API usage is close enough to the rate limit to pause syncs
+type Throttle interface {
+	Throttled(now time.Time) bool
+}
+
 // Scheduler handles periodic synchronization of comments
 type Scheduler struct {
 	syncer          CommentSyncer
 	pubProvider     PublicationAccountProvider
 	accountProvider AccountProvider
 	interval        time.Duration
-	syncAge         time.Duration // How old sync status can be before refreshing
-	batchSize       int           // How many media to sync per run
-	maxRetries      int           // Max retries before marking sync as permanently failed
+	syncAge         time.Duration    // How old sync status can be before refreshing
+	maxPostAge      time.Duration    // Skip media published longer ago than this (0 = no limit)
+	batchSize       int              // How many media to sync per run
+	maxRetries      int              // Max retries before marking sync as permanently failed
+	jitter          float64          // Fraction of interval to randomize each tick by (e.g. 0.1 = ±10%)
+	startupJitter   time.Duration    // Max random delay added to the initial startup delay
+	stopTimeout     time.Duration    // How long Stop waits for an in-flight batch before cancelling it
+	runs            liveness.Tracker // Run times reported by Status
+	throttle        Throttle         // Pauses syncs while Instagram API usage is high; optional
 	logger          *slog.Logger
 	stopCh          chan struct{}
 	cancel          context.CancelFunc // Cancel function to stop in-flight operations
@@ -44,10 +61,14 @@ type Scheduler struct {
 
 // Config holds configuration for comment sync scheduler
 type Config struct {
-	Interval   time.Duration
-	SyncAge    time.Duration
-	BatchSize  int
-	MaxRetries int
+	Interval      time.Duration
+	SyncAge       time.Duration
+	BatchSize     int
+	MaxRetries    int
+	MaxPostAge    time.Duration // Skip media published longer ago than this (0 = no limit)
+	Jitter        float64       // Fraction of interval to randomize each tick by
+	StartupJitter time.Duration // Max random delay added before the first run
+	StopTimeout   time.Duration // How long Stop waits for an in-flight batch before cancelling it
 }
 
 // New creates a new comment sync scheduler
@@ -70,6 +91,9 @@ func New(
 	if cfg.MaxRetries == 0 {
 		cfg.MaxRetries = 5
 	}
+	if cfg.StopTimeout == 0 {
+		cfg.StopTimeout = 30 * time.Second
+	}
 
 	return &Scheduler{
 		syncer:          syncer,
@@ -77,13 +101,28 @@ func New(
 		accountProvider: accountProvider,
 		interval:        cfg.Interval,
 		syncAge:         cfg.SyncAge,
+		maxPostAge:      cfg.MaxPostAge,
 		batchSize:       cfg.BatchSize,
 		maxRetries:      cfg.MaxRetries,
+		jitter:          cfg.Jitter,
+		startupJitter:   cfg.StartupJitter,
+		stopTimeout:     cfg.StopTimeout,
 		logger:          logger,
 		stopCh:          make(chan struct{}),
 	}
 }
 
+// WithThrottle pauses syncs while t reports that Instagram API usage is high
+func (s *Scheduler) WithThrottle(t Throttle) *Scheduler {
+	s.throttle = t
+	return s
+}
+
+// throttled reports whether syncs should pause now
+func (s *Scheduler) throttled() bool {
+	return s.throttle != nil && s.throttle.Throttled(time.Now())
+}
+
 // Start starts the scheduler
 func (s *Scheduler) Start(ctx context.Context) {
 	s.mu.Lock()
@@ -92,18 +131,20 @@ func (s *Scheduler) Start(ctx context.Context) {
 		return
 	}
 	s.running = true
+	s.runs.Started(time.Now())
 
 	// Create a cancellable context for in-flight operations
 	ctx, s.cancel = context.WithCancel(ctx)
 	s.mu.Unlock()
 
-	s.logger.Info("comment sync scheduler started", "interval", s.interval, "sync_age", s.syncAge)
+	s.logger.Info("comment sync scheduler started", "interval", s.interval, "sync_age", s.syncAge, "max_post_age", s.maxPostAge)
 
 	s.wg.Add(1)
 	go s.run(ctx)
 }
 
-// Stop stops the scheduler
+// Stop stops the scheduler and waits for the in-flight iteration to drain.
+// If it doesn't finish within the stop timeout, its context is cancelled.
 func (s *Scheduler) Stop() {
 	s.mu.Lock()
 	if !s.running {
@@ -111,29 +152,43 @@ func (s *Scheduler) Stop() {
 		return
 	}
 	s.running = false
+	s.runs.Stopped()
 	cancel := s.cancel
 	s.mu.Unlock()
 
-	// Cancel in-flight operations (HTTP requests, etc.)
-	if cancel != nil {
+	close(s.stopCh)
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(s.stopTimeout):
+		s.logger.Warn("comment sync scheduler did not drain in time, cancelling in-flight work", "timeout", s.stopTimeout)
+		// Cancel in-flight operations (HTTP requests, etc.) and wait for them to return
 		cancel()
+		<-done
 	}
 
-	close(s.stopCh)
-	s.wg.Wait()
+	cancel()
 	s.logger.Info("comment sync scheduler stopped")
 }
 
+// Status reports the scheduler's interval and its latest run
+func (s *Scheduler) Status() liveness.Status {
+	return s.runs.Status(s.interval)
+}
+
 // run is the main scheduler loop
 func (s *Scheduler) run(ctx context.Context) {
 	defer s.wg.Done()
 
-	ticker := time.NewTicker(s.interval)
-	defer ticker.Stop()
-
 	// Run after a short delay on start (to let the app initialize)
 	select {
-	case <-time.After(10 * time.Second):
+	case <-time.After(10*time.Second + jitter.UpTo(s.startupJitter)):
 		s.process(ctx)
 	case <-s.stopCh:
 		return
@@ -141,10 +196,14 @@ func (s *Scheduler) run(ctx context.Context) {
 		return
 	}
 
+	timer := time.NewTimer(jitter.Apply(s.interval, s.jitter))
+	defer timer.Stop()
+
 	for {
 		select {
-		case <-ticker.C:
+		case <-timer.C:
 			s.process(ctx)
+			timer.Reset(jitter.Apply(s.interval, s.jitter))
 		case <-s.stopCh:
 			return
 		case <-ctx.Done():
@@ -155,9 +214,16 @@ func (s *Scheduler) run(ctx context.Context) {
 
 // process syncs comments for media that need it
 func (s *Scheduler) process(ctx context.Context) {
+	defer s.runs.RunStarted(time.Now())()
+
+	if s.throttled() {
+		s.logger.Warn("instagram API usage is high, skipping comment sync run")
+		return
+	}
+
 	s.logger.Debug("checking for media needing comment sync")
 
-	mediaIDs, err := s.syncer.GetMediaIDsNeedingSync(ctx, s.syncAge, s.batchSize)
+	mediaIDs, err := s.syncer.GetMediaIDsNeedingSync(ctx, s.syncAge, s.maxPostAge, s.batchSize)
 	if err != nil {
 		s.logger.Error("failed to get media ids needing sync", "error", err)
 		return
@@ -171,12 +237,18 @@ func (s *Scheduler) process(ctx context.Context) {
 	s.logger.Info("syncing comments for media", "count", len(mediaIDs))
 
 	for _, mediaID := range mediaIDs {
-		// Check if context is cancelled
+		// Stop between items on shutdown so only the current one is drained
 		select {
+		case <-s.stopCh:
+			return
 		case <-ctx.Done():
 			return
 		default:
 		}
+		if s.throttled() {
+			s.logger.Warn("instagram API usage is high, pausing comment sync until it recovers")
+			return
+		}
 
 		if err := s.syncMedia(ctx, mediaID); err != nil {
 			s.logger.Error("failed to sync comments", "media_id", mediaID, "error", err)
@@ -195,6 +267,7 @@ func (s *Scheduler) syncMedia(ctx context.Context, mediaID string) error {
 		_ = s.syncer.IncrementSyncRetryCount(ctx, mediaID, err.Error(), s.maxRetries)
 		return err
 	}
+	ctx = logctx.With(ctx, "account_id", accountID)
 
 	// Get access token for the account
 	accessToken, err := s.accountProvider.GetAccessToken(ctx, accountID)
@@ -205,7 +278,17 @@ func (s *Scheduler) syncMedia(ctx context.Context, mediaID string) error {
 	}
 
 	// Sync comments
-	err = s.syncer.SyncMediaComments(ctx, mediaID, accessToken)
+	_, err = s.syncer.SyncMediaComments(ctx, mediaID, accessToken)
+	if errors.Is(err, entity.ErrSyncInProgress) {
+		// A manual sync is already running for this media; nothing to do
+		return nil
+	}
+	if errors.Is(err, entity.ErrMediaNotFound) {
+		// The media was deleted on Instagram; retrying cannot succeed, so a single
+		// failure marks the sync as permanently failed and drops the media from sync
+		_ = s.syncer.IncrementSyncRetryCount(ctx, mediaID, err.Error(), 1)
+		return err
+	}
 	if err != nil {
 		// Increment retry count on error
 		_ = s.syncer.IncrementSyncRetryCount(ctx, mediaID, err.Error(), s.maxRetries)
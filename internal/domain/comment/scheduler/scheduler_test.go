@@ -0,0 +1,131 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/comment/entity"
+)
+
+// failingSyncer fails every sync with err and records the retry limit it was given
+type failingSyncer struct {
+	err        error
+	maxRetries []int
+}
+
+func (f *failingSyncer) SyncMediaComments(context.Context, string, string) (int, error) {
+	return 0, f.err
+}
+
+func (f *failingSyncer) GetMediaIDsNeedingSync(context.Context, time.Duration, time.Duration, int) ([]string, error) {
+	return nil, nil
+}
+
+func (f *failingSyncer) IncrementSyncRetryCount(_ context.Context, _ string, _ string, maxRetries int) error {
+	f.maxRetries = append(f.maxRetries, maxRetries)
+	return nil
+}
+
+func (f *failingSyncer) ResetSyncRetryCount(context.Context, string) error {
+	return nil
+}
+
+type staticAccounts struct{}
+
+func (staticAccounts) GetAccountIDByMediaID(context.Context, string) (string, error) {
+	return "acc", nil
+}
+
+func (staticAccounts) GetAccessToken(context.Context, string) (string, error) {
+	return "token", nil
+}
+
+func TestSyncMediaFailsDeletedMediaImmediately(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"deleted media", fmt.Errorf("%w: object does not exist", entity.ErrMediaNotFound), 1},
+		{"other error", errors.New("connection reset"), 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			syncer := &failingSyncer{err: tt.err}
+			s := New(syncer, staticAccounts{}, staticAccounts{}, Config{MaxRetries: 5}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			if err := s.syncMedia(context.Background(), "m1"); !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if len(syncer.maxRetries) != 1 || syncer.maxRetries[0] != tt.want {
+				t.Errorf("retry limits = %v, want [%d]", syncer.maxRetries, tt.want)
+			}
+		})
+	}
+}
+
+// recordingSyncer lists fixed media and records the ones it syncs
+type recordingSyncer struct {
+	failingSyncer
+	mediaIDs []string
+	synced   []string
+	onSync   func()
+}
+
+func (r *recordingSyncer) GetMediaIDsNeedingSync(context.Context, time.Duration, time.Duration, int) ([]string, error) {
+	return r.mediaIDs, nil
+}
+
+func (r *recordingSyncer) SyncMediaComments(_ context.Context, mediaID, _ string) (int, error) {
+	r.synced = append(r.synced, mediaID)
+	if r.onSync != nil {
+		r.onSync()
+	}
+	return 0, nil
+}
+
+// switchThrottle reports the throttling state it is set to
+type switchThrottle struct {
+	on bool
+}
+
+func (s *switchThrottle) Throttled(time.Time) bool {
+	return s.on
+}
+
+func TestProcessPausesWhileThrottled(t *testing.T) {
+	throttle := &switchThrottle{on: true}
+	syncer := &recordingSyncer{mediaIDs: []string{"m1", "m2", "m3"}}
+	s := New(syncer, staticAccounts{}, staticAccounts{}, Config{}, slog.New(slog.NewTextHandler(io.Discard, nil))).
+		WithThrottle(throttle)
+
+	s.process(context.Background())
+	if len(syncer.synced) != 0 {
+		t.Fatalf("synced %v while throttled, want nothing", syncer.synced)
+	}
+	if s.Status().LastRunAt == nil {
+		t.Error("skipped run is not recorded, the scheduler would look stalled")
+	}
+
+	// Usage crossing the threshold midway stops the batch after the current media
+	throttle.on = false
+	syncer.onSync = func() { throttle.on = true }
+	s.process(context.Background())
+	if len(syncer.synced) != 1 || syncer.synced[0] != "m1" {
+		t.Errorf("synced %v, want [m1] before pausing", syncer.synced)
+	}
+
+	// The run after usage recovered syncs the whole batch
+	throttle.on = false
+	syncer.onSync = nil
+	syncer.synced = nil
+	s.process(context.Background())
+	if len(syncer.synced) != 3 {
+		t.Errorf("synced %v after recovery, want all three media", syncer.synced)
+	}
+}
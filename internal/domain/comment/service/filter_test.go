@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/comment/entity"
+)
+
+// filterRepo lists stored comments through the filter like the DAO query does
+type filterRepo struct {
+	CommentRepository
+	comments []entity.Comment
+}
+
+func (r *filterRepo) GetByMediaID(_ context.Context, mediaID string, filter entity.CommentFilter, limit int, offset int) ([]entity.Comment, error) {
+	var matched []entity.Comment
+	for _, c := range r.comments {
+		if c.MediaID == mediaID && filter.Matches(c) {
+			matched = append(matched, c)
+		}
+	}
+	if offset >= len(matched) {
+		return nil, nil
+	}
+	matched = matched[offset:]
+	if len(matched) > limit {
+		matched = matched[:limit]
+	}
+	return matched, nil
+}
+
+// filterDay is the day the filter fixtures were posted, one comment per hour from midnight
+var filterDay = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func newFilterService() *Service {
+	repo := &filterRepo{comments: []entity.Comment{
+		{ID: "1", MediaID: "m1", AuthorID: "u1", Timestamp: filterDay},
+		{ID: "2", MediaID: "m1", AuthorID: "u2", IsHidden: true, Timestamp: filterDay.Add(time.Hour)},
+		{ID: "3", MediaID: "m1", AuthorID: "u1", IsHidden: true, Timestamp: filterDay.Add(2 * time.Hour)},
+		{ID: "4", MediaID: "m1", AuthorID: "u1", Timestamp: filterDay.Add(3 * time.Hour)},
+		{ID: "5", MediaID: "m1", AuthorID: "u2", Timestamp: filterDay.Add(4 * time.Hour)},
+	}}
+	// A fresh sync status keeps the listing on stored comments
+	syncRepo := &memSyncRepo{status: &SyncStatus{InstagramMediaID: "m1", LastSyncedAt: time.Now()}}
+	return NewWithRepo(nil, repo, syncRepo)
+}
+
+func commentIDs(comments []entity.Comment) []string {
+	ids := make([]string, 0, len(comments))
+	for _, c := range comments {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
+
+func TestGetCommentsFilters(t *testing.T) {
+	hidden, visible := true, false
+	since, until := filterDay.Add(time.Hour), filterDay.Add(3*time.Hour)
+	tests := []struct {
+		name string
+		in   GetCommentsInput
+		want string
+	}{
+		{"hidden", GetCommentsInput{Hidden: &hidden}, "[2 3]"},
+		{"visible", GetCommentsInput{Hidden: &visible}, "[1 4 5]"},
+		{"author", GetCommentsInput{AuthorID: "u2"}, "[2 5]"},
+		{"hidden author", GetCommentsInput{Hidden: &hidden, AuthorID: "u1"}, "[3]"},
+		{"since", GetCommentsInput{Since: &since}, "[2 3 4 5]"},
+		{"until", GetCommentsInput{Until: &until}, "[1 2 3 4]"},
+		{"time range", GetCommentsInput{Since: &since, Until: &until}, "[2 3 4]"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.in.MediaID = "m1"
+			out, err := newFilterService().GetComments(context.Background(), tc.in)
+			if err != nil {
+				t.Fatalf("GetComments: %v", err)
+			}
+			if got := fmt.Sprint(commentIDs(out.Comments)); got != tc.want {
+				t.Errorf("comments = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGetCommentsFilterPagination(t *testing.T) {
+	svc := newFilterService()
+	in := GetCommentsInput{MediaID: "m1", AuthorID: "u1", Limit: 2}
+
+	first, err := svc.GetComments(context.Background(), in)
+	if err != nil {
+		t.Fatalf("first page: %v", err)
+	}
+	if got := fmt.Sprint(commentIDs(first.Comments)); got != "[1 3]" || !first.HasMore || first.NextCursor != "2" {
+		t.Fatalf("first page = %s, has_more %v, cursor %q; want [1 3], true, \"2\"", got, first.HasMore, first.NextCursor)
+	}
+
+	in.After = first.NextCursor
+	second, err := svc.GetComments(context.Background(), in)
+	if err != nil {
+		t.Fatalf("second page: %v", err)
+	}
+	if got := fmt.Sprint(commentIDs(second.Comments)); got != "[4]" || second.HasMore {
+		t.Errorf("second page = %s, has_more %v; want [4], false", got, second.HasMore)
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/comment/entity"
+)
+
+// accountWindows holds per-account sync max ages; unknown accounts fail the lookup
+type accountWindows map[string]time.Duration
+
+func (w accountWindows) CommentCacheMaxAge(_ context.Context, accountID string) (time.Duration, error) {
+	d, ok := w[accountID]
+	if !ok {
+		return 0, errors.New("account not found")
+	}
+	return d, nil
+}
+
+// cachedRepo serves no stored comments; only whether Instagram is called matters
+type cachedRepo struct {
+	memCommentRepo
+}
+
+func (r *cachedRepo) GetByMediaID(context.Context, string, entity.CommentFilter, int, int) ([]entity.Comment, error) {
+	return nil, nil
+}
+
+// refreshed reports whether GetComments for the account went to Instagram
+// when the media was last synced ten minutes ago
+func refreshed(t *testing.T, accounts AccountSettings, accountID string) bool {
+	t.Helper()
+	ig := &listingClient{base: time.Now().Add(-time.Hour), total: 1}
+	repo := &cachedRepo{memCommentRepo{comments: map[string]entity.Comment{}}}
+	syncRepo := &memSyncRepo{status: &SyncStatus{
+		InstagramMediaID: "m1",
+		LastSyncedAt:     time.Now().Add(-10 * time.Minute),
+		SyncComplete:     true,
+	}}
+	svc := NewWithRepo(ig, repo, syncRepo).
+		WithSyncMaxAge(5 * time.Minute).
+		WithReconcileInterval(0).
+		WithAccountSettings(accounts)
+
+	if _, err := svc.GetComments(context.Background(), GetCommentsInput{AccountID: accountID, MediaID: "m1"}); err != nil {
+		t.Fatalf("GetComments: %v", err)
+	}
+	return len(ig.calls) > 0
+}
+
+func TestGetCommentsUsesAccountSyncMaxAge(t *testing.T) {
+	accounts := accountWindows{
+		"busy":    time.Minute, // Tighter than the default
+		"dormant": time.Hour,   // Looser than the default
+		"default": 0,           // No override
+	}
+
+	if !refreshed(t, accounts, "busy") {
+		t.Error("busy account: comments synced 10 minutes ago were not refreshed with a 1 minute window")
+	}
+	if refreshed(t, accounts, "dormant") {
+		t.Error("dormant account: comments synced 10 minutes ago were refreshed with a 1 hour window")
+	}
+	if !refreshed(t, accounts, "default") {
+		t.Error("account without override: the 5 minute default was not applied")
+	}
+	if !refreshed(t, accounts, "unknown") {
+		t.Error("failed lookup: the 5 minute default was not applied")
+	}
+}
+
+func TestGetCommentsSkipsSyncInProgress(t *testing.T) {
+	for _, status := range []*SyncStatus{
+		{InstagramMediaID: "m1", LastSyncedAt: time.Now().Add(-time.Hour), SyncComplete: true},
+		nil, // never synced: the running sync is the first one
+	} {
+		ig := &listingClient{base: time.Now().Add(-time.Hour), total: 1}
+		repo := &cachedRepo{memCommentRepo{comments: map[string]entity.Comment{}}}
+		svc := NewWithRepo(ig, repo, &memSyncRepo{status: status})
+		svc.inFlight.Store("m1", struct{}{}) // A manual or scheduled sync of the media is running
+
+		if _, err := svc.GetComments(context.Background(), GetCommentsInput{MediaID: "m1"}); err != nil {
+			t.Errorf("GetComments: %v", err)
+		}
+		if len(ig.calls) != 0 {
+			t.Errorf("Instagram called %d times during a running sync, want 0", len(ig.calls))
+		}
+	}
+}
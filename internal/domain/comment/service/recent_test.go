@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/comment/entity"
+)
+
+// recentRepo stores comments on several posts and lists them like the DAO join does
+type recentRepo struct {
+	CommentRepository
+	posts    map[string]recentPost // by media ID
+	comments []entity.Comment
+}
+
+type recentPost struct {
+	accountID string
+	caption   string
+}
+
+func (r *recentRepo) GetRecentByAccount(_ context.Context, q entity.RecentCommentsQuery) ([]entity.RecentComment, error) {
+	var matched []entity.RecentComment
+	for _, c := range r.comments {
+		post, ok := r.posts[c.MediaID]
+		if !ok || post.accountID != q.AccountID || (q.Since != nil && c.Timestamp.Before(*q.Since)) {
+			continue
+		}
+		matched = append(matched, entity.RecentComment{Comment: c, Caption: post.caption})
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Timestamp.After(matched[j].Timestamp) })
+
+	if q.Offset >= len(matched) {
+		return nil, nil
+	}
+	matched = matched[q.Offset:]
+	if len(matched) > q.Limit {
+		matched = matched[:q.Limit]
+	}
+	return matched, nil
+}
+
+func newRecentService() (*Service, time.Time) {
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return base.Add(time.Duration(minutes) * time.Minute) }
+
+	repo := &recentRepo{
+		posts: map[string]recentPost{
+			"m1": {accountID: "acc", caption: "First post"},
+			"m2": {accountID: "acc", caption: "Second post"},
+			"m3": {accountID: "acc", caption: "Third post"},
+			"x1": {accountID: "other", caption: "Someone else's post"},
+		},
+		comments: []entity.Comment{
+			{ID: "c1", MediaID: "m1", Timestamp: at(1)},
+			{ID: "c2", MediaID: "m2", Timestamp: at(5)},
+			{ID: "c3", MediaID: "m1", Timestamp: at(3)},
+			{ID: "c4", MediaID: "x1", Timestamp: at(4)},
+			{ID: "c5", MediaID: "m3", Timestamp: at(2)},
+			{ID: "c6", MediaID: "m2", Timestamp: at(6)},
+		},
+	}
+	return NewWithRepo(nil, repo, nil), base
+}
+
+func recentIDs(comments []entity.RecentComment) string {
+	ids := make([]string, 0, len(comments))
+	for _, c := range comments {
+		ids = append(ids, c.MediaID+"/"+c.ID)
+	}
+	return fmt.Sprint(ids)
+}
+
+func TestGetRecentCommentsAcrossMedia(t *testing.T) {
+	svc, _ := newRecentService()
+
+	first, err := svc.GetRecentComments(context.Background(), GetRecentCommentsInput{AccountID: "acc", Limit: 3})
+	if err != nil {
+		t.Fatalf("GetRecentComments: %v", err)
+	}
+	if got, want := recentIDs(first.Comments), "[m2/c6 m2/c2 m1/c3]"; got != want {
+		t.Errorf("first page = %s, want %s", got, want)
+	}
+	if !first.HasMore || first.NextCursor != "3" {
+		t.Fatalf("first page has_more = %v cursor = %q, want true 3", first.HasMore, first.NextCursor)
+	}
+	if first.Comments[0].Caption != "Second post" {
+		t.Errorf("caption = %q, want the post's caption", first.Comments[0].Caption)
+	}
+
+	second, err := svc.GetRecentComments(context.Background(), GetRecentCommentsInput{AccountID: "acc", Limit: 3, After: first.NextCursor})
+	if err != nil {
+		t.Fatalf("GetRecentComments: %v", err)
+	}
+	// c4 is on another account's post
+	if got, want := recentIDs(second.Comments), "[m3/c5 m1/c1]"; got != want {
+		t.Errorf("second page = %s, want %s", got, want)
+	}
+	if second.HasMore || second.NextCursor != "" {
+		t.Errorf("second page has_more = %v cursor = %q, want last page", second.HasMore, second.NextCursor)
+	}
+}
+
+func TestGetRecentCommentsSince(t *testing.T) {
+	svc, base := newRecentService()
+	since := base.Add(3 * time.Minute)
+
+	out, err := svc.GetRecentComments(context.Background(), GetRecentCommentsInput{AccountID: "acc", Since: &since})
+	if err != nil {
+		t.Fatalf("GetRecentComments: %v", err)
+	}
+	if got, want := recentIDs(out.Comments), "[m2/c6 m2/c2 m1/c3]"; got != want {
+		t.Errorf("comments = %s, want %s", got, want)
+	}
+}
+
+func TestGetRecentCommentsWithoutStorage(t *testing.T) {
+	out, err := New(nil).GetRecentComments(context.Background(), GetRecentCommentsInput{AccountID: "acc"})
+	if err != nil {
+		t.Fatalf("GetRecentComments: %v", err)
+	}
+	if out.Comments == nil || len(out.Comments) != 0 {
+		t.Errorf("comments = %v, want empty list", out.Comments)
+	}
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// failedSyncRepo reports a fixed set of failed media and records resets
+type failedSyncRepo struct {
+	SyncStatusRepository
+	failed []string
+	reset  []string
+}
+
+func (r *failedSyncRepo) GetFailedMediaIDs(_ context.Context, _ string) ([]string, error) {
+	return r.failed, nil
+}
+
+func (r *failedSyncRepo) ResetRetryCount(_ context.Context, mediaID string) error {
+	r.reset = append(r.reset, mediaID)
+	return nil
+}
+
+func TestResetFailedSyncs(t *testing.T) {
+	tests := []struct {
+		name     string
+		mediaIDs []string
+		want     []string
+	}{
+		{"all failed media", nil, []string{"m1", "m2", "m3"}},
+		{"scoped to media", []string{"m2", "m9"}, []string{"m2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			syncRepo := &failedSyncRepo{failed: []string{"m1", "m2", "m3"}}
+			svc := NewWithRepo(nil, nil, syncRepo)
+
+			got, err := svc.ResetFailedSyncs(context.Background(), "acc-1", tt.mediaIDs)
+			if err != nil {
+				t.Fatalf("ResetFailedSyncs: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) || !reflect.DeepEqual(syncRepo.reset, tt.want) {
+				t.Errorf("reset %v (returned %v), want %v", syncRepo.reset, got, tt.want)
+			}
+		})
+	}
+}
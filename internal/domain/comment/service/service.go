@@ -2,6 +2,10 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
 	"sync"
 	"time"
 
@@ -10,7 +14,8 @@ import (
 
 // InstagramClient defines the interface for Instagram API operations
 type InstagramClient interface {
-	GetComments(ctx context.Context, mediaID, accessToken string, limit int, after string) (*CommentsResult, error)
+	// GetComments lists a page of a media's comments; since and until, when set, bound their creation time
+	GetComments(ctx context.Context, mediaID, accessToken string, limit int, after string, since, until *time.Time) (*CommentsResult, error)
 	GetCommentReplies(ctx context.Context, commentID, accessToken string, limit int, after string) (*CommentsResult, error)
 	CreateComment(ctx context.Context, mediaID, accessToken, message string) (string, error)
 	ReplyToComment(ctx context.Context, commentID, accessToken, message string) (string, error)
@@ -23,13 +28,31 @@ type CommentRepository interface {
 	Upsert(ctx context.Context, comment *entity.Comment) error
 	UpsertBatch(ctx context.Context, comments []entity.Comment) error
 	GetByID(ctx context.Context, id string) (*entity.Comment, error)
-	GetByMediaID(ctx context.Context, mediaID string, limit int, offset int) ([]entity.Comment, error)
+	GetByMediaID(ctx context.Context, mediaID string, filter entity.CommentFilter, limit int, offset int) ([]entity.Comment, error)
 	GetReplies(ctx context.Context, parentID string, limit int, offset int) ([]entity.Comment, error)
+	GetRepliesForParents(ctx context.Context, parentIDs []string, maxPerParent int) ([]entity.Comment, error)
 	Delete(ctx context.Context, id string) error
+	DeleteMissing(ctx context.Context, mediaID string, keepIDs []string, before time.Time) (int64, error)
 	UpdateHidden(ctx context.Context, id string, hidden bool) error
 	Count(ctx context.Context, mediaID string) (int64, error)
 	CountReplies(ctx context.Context, parentID string) (int64, error)
-	GetStatistics(ctx context.Context, accountID string, topPostsLimit int) (*entity.CommentStatistics, error)
+	GetUnclassified(ctx context.Context, mediaID string, limit int) ([]entity.Comment, error)
+	UpdateSentiments(ctx context.Context, sentiments map[string]entity.Sentiment) error
+	GetStatistics(ctx context.Context, filter entity.StatisticsFilter) (*entity.CommentStatistics, error)
+	GetTopPosts(ctx context.Context, filter entity.StatisticsFilter, q entity.TopPostsQuery) ([]entity.TopPost, error)
+	GetRecentByAccount(ctx context.Context, q entity.RecentCommentsQuery) ([]entity.RecentComment, error)
+}
+
+// AccountSettings provides per-account overrides of the service defaults
+type AccountSettings interface {
+	// CommentCacheMaxAge returns how long the account's stored comments are served before
+	// refreshing them, or 0 to use the default
+	CommentCacheMaxAge(ctx context.Context, accountID string) (time.Duration, error)
+}
+
+// CommentClassifier assigns a sentiment to comment text
+type CommentClassifier interface {
+	Classify(ctx context.Context, text string) (entity.Sentiment, error)
 }
 
 // SyncStatus represents the synchronization status for a media's comments
@@ -41,15 +64,17 @@ type SyncStatus struct {
 	RetryCount       int
 	Failed           bool
 	LastError        string
+	LastFullSyncAt   *time.Time // nil until the first full sync
 }
 
 // SyncStatusRepository defines the interface for sync status tracking
 type SyncStatusRepository interface {
 	GetSyncStatus(ctx context.Context, mediaID string) (*SyncStatus, error)
 	UpdateSyncStatus(ctx context.Context, status *SyncStatus) error
-	GetMediaIDsNeedingSync(ctx context.Context, olderThan time.Duration, limit int) ([]string, error)
+	GetMediaIDsNeedingSync(ctx context.Context, olderThan, maxPostAge time.Duration, limit int) ([]string, error)
 	IncrementRetryCount(ctx context.Context, mediaID string, lastError string, maxRetries int) error
 	ResetRetryCount(ctx context.Context, mediaID string) error
+	GetFailedMediaIDs(ctx context.Context, accountID string) ([]string, error)
 }
 
 // CommentsResult represents the result of fetching comments
@@ -61,10 +86,24 @@ type CommentsResult struct {
 
 // Service handles business logic for comments
 type Service struct {
-	ig         InstagramClient
-	repo       CommentRepository
-	syncRepo   SyncStatusRepository
-	syncMaxAge time.Duration // How old sync status can be before refreshing
+	ig          InstagramClient
+	repo        CommentRepository
+	syncRepo    SyncStatusRepository
+	syncMaxAge  time.Duration     // How old sync status can be before refreshing
+	classifier  CommentClassifier // optional, tags synced comments with sentiment
+	accounts    AccountSettings   // optional, per-account overrides of syncMaxAge
+	inFlight    sync.Map          // media IDs with a SyncMediaComments call in progress
+	classifying sync.Map          // media IDs queued for or being classified
+
+	// Background classification; classifyQueue is nil without a classifier
+	classifyQueue  chan string
+	classifyWG     sync.WaitGroup
+	classifyMu     sync.Mutex // guards sends on classifyQueue against Close
+	classifyClosed bool
+	classifyCtx    context.Context
+	classifyCancel context.CancelFunc
+
+	reconcileInterval time.Duration // How often a media is synced in full to drop deleted comments
 }
 
 // New creates a new comment service
@@ -82,6 +121,8 @@ func NewWithRepo(ig InstagramClient, repo CommentRepository, syncRepo SyncStatus
 		repo:       repo,
 		syncRepo:   syncRepo,
 		syncMaxAge: 5 * time.Minute,
+
+		reconcileInterval: 24 * time.Hour,
 	}
 }
 
@@ -91,12 +132,111 @@ func (s *Service) WithSyncMaxAge(d time.Duration) *Service {
 	return s
 }
 
+// WithAccountSettings lets accounts override the sync max age
+func (s *Service) WithAccountSettings(a AccountSettings) *Service {
+	s.accounts = a
+	return s
+}
+
+// syncMaxAgeFor returns how old the account's sync status can be before refreshing,
+// falling back to the default when the account has no override or it can't be read
+func (s *Service) syncMaxAgeFor(ctx context.Context, accountID string) time.Duration {
+	if s.accounts == nil || accountID == "" {
+		return s.syncMaxAge
+	}
+	if d, err := s.accounts.CommentCacheMaxAge(ctx, accountID); err == nil && d > 0 {
+		return d
+	}
+	return s.syncMaxAge
+}
+
+// WithReconcileInterval sets how often comments of a media are fetched in full so that
+// comments deleted on Instagram are removed locally (0 disables periodic full syncs)
+func (s *Service) WithReconcileInterval(d time.Duration) *Service {
+	s.reconcileInterval = d
+	return s
+}
+
+// Background classification bounds
+const (
+	classifyWorkers   = 2   // media classified at the same time
+	classifyQueueSize = 100 // media waiting for a worker; more are left to their next sync
+)
+
+// WithClassifier sets the classifier used to tag synced comments (nil disables classification)
+// and starts the workers that classify them in the background; stop them with Close
+func (s *Service) WithClassifier(c CommentClassifier) *Service {
+	s.classifier = c
+	if c == nil || s.classifyQueue != nil {
+		return s
+	}
+
+	s.classifyQueue = make(chan string, classifyQueueSize)
+	s.classifyCtx, s.classifyCancel = context.WithCancel(context.Background())
+	for i := 0; i < classifyWorkers; i++ {
+		s.classifyWG.Add(1)
+		go func() {
+			defer s.classifyWG.Done()
+			for mediaID := range s.classifyQueue {
+				s.classifyPending(s.classifyCtx, mediaID)
+				s.classifying.Delete(mediaID)
+			}
+		}()
+	}
+	return s
+}
+
+// Close stops queueing classification work and waits until the queued media are classified.
+// If ctx is done first, the remaining classification is cancelled; the comments it did not
+// reach stay unclassified until their media's next sync.
+func (s *Service) Close(ctx context.Context) {
+	s.classifyMu.Lock()
+	if s.classifyQueue == nil || s.classifyClosed {
+		s.classifyMu.Unlock()
+		return
+	}
+	s.classifyClosed = true
+	close(s.classifyQueue)
+	s.classifyMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.classifyWG.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.classifyCancel()
+		<-done
+	}
+	s.classifyCancel()
+}
+
 // GetCommentsInput represents input for getting comments
 type GetCommentsInput struct {
+	AccountID   string // Optional, selects the account's sync max age
 	MediaID     string
 	AccessToken string
 	Limit       int
 	After       string
+	Sentiment   entity.Sentiment // Optional filter
+	Hidden      *bool            // Optional filter
+	AuthorID    string           // Optional filter
+	Since       *time.Time       // Optional filter, created at or after
+	Until       *time.Time       // Optional filter, created at or before
+}
+
+// filter returns the listing filter described by the input
+func (in GetCommentsInput) filter() entity.CommentFilter {
+	return entity.CommentFilter{
+		Sentiment: in.Sentiment,
+		Hidden:    in.Hidden,
+		AuthorID:  in.AuthorID,
+		Since:     in.Since,
+		Until:     in.Until,
+	}
 }
 
 // GetCommentsOutput represents output from getting comments
@@ -123,11 +263,29 @@ func (s *Service) GetComments(ctx context.Context, in GetCommentsInput) (*GetCom
 
 // getCommentsFromInstagram fetches comments directly from Instagram
 func (s *Service) getCommentsFromInstagram(ctx context.Context, in GetCommentsInput) (*GetCommentsOutput, error) {
-	result, err := s.ig.GetComments(ctx, in.MediaID, in.AccessToken, in.Limit, in.After)
+	result, err := s.ig.GetComments(ctx, in.MediaID, in.AccessToken, in.Limit, in.After, in.Since, in.Until)
 	if err != nil {
 		return nil, err
 	}
 
+	// Without storage, classify on the fly so the sentiment filter still works
+	filter := in.filter()
+	if s.classifier == nil {
+		filter.Sentiment = ""
+	}
+	filtered := result.Comments[:0]
+	for _, c := range result.Comments {
+		if s.classifier != nil {
+			if sentiment, err := s.classifier.Classify(ctx, c.Text); err == nil {
+				c.Sentiment = sentiment
+			}
+		}
+		if filter.Matches(c) {
+			filtered = append(filtered, c)
+		}
+	}
+	result.Comments = filtered
+
 	return &GetCommentsOutput{
 		Comments:   result.Comments,
 		NextCursor: result.NextCursor,
@@ -144,11 +302,12 @@ func (s *Service) getCommentsWithCache(ctx context.Context, in GetCommentsInput)
 	}
 
 	// If never synced or sync is stale, fetch from Instagram first
-	needsSync := syncStatus == nil || time.Since(syncStatus.LastSyncedAt) > s.syncMaxAge
+	needsSync := syncStatus == nil || time.Since(syncStatus.LastSyncedAt) > s.syncMaxAgeFor(ctx, in.AccountID)
 
 	if needsSync {
-		// Fetch from Instagram and save to DB
-		if err := s.syncCommentsFromInstagram(ctx, in.MediaID, in.AccessToken); err != nil {
+		// Fetch from Instagram and save to DB; a sync already running for the media
+		// is refreshing the same comments, so serve what it has stored so far
+		if _, err := s.SyncMediaComments(ctx, in.MediaID, in.AccessToken); err != nil && !errors.Is(err, entity.ErrSyncInProgress) {
 			// If sync fails but we have cached data, return that
 			if syncStatus != nil {
 				// Log error but continue with cached data
@@ -158,14 +317,10 @@ func (s *Service) getCommentsWithCache(ctx context.Context, in GetCommentsInput)
 		}
 	}
 
-	// Fetch from database
-	offset := 0
-	if in.After != "" {
-		// For simplicity, treat After as offset (could be improved with cursor-based pagination)
-		// In production, you'd parse the cursor properly
-	}
+	// Fetch from database; the cursor is the offset into the filtered listing
+	offset := offsetCursor(in.After)
 
-	comments, err := s.repo.GetByMediaID(ctx, in.MediaID, in.Limit+1, offset)
+	comments, err := s.repo.GetByMediaID(ctx, in.MediaID, in.filter(), in.Limit+1, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -177,8 +332,7 @@ func (s *Service) getCommentsWithCache(ctx context.Context, in GetCommentsInput)
 
 	var nextCursor string
 	if hasMore {
-		// Simple offset-based cursor
-		nextCursor = "" // Could implement proper cursor here
+		nextCursor = strconv.Itoa(offset + len(comments))
 	}
 
 	return &GetCommentsOutput{
@@ -188,19 +342,57 @@ func (s *Service) getCommentsWithCache(ctx context.Context, in GetCommentsInput)
 	}, nil
 }
 
-// syncCommentsFromInstagram fetches all comments from Instagram and saves to DB
-// Saves each page incrementally and asynchronously
-func (s *Service) syncCommentsFromInstagram(ctx context.Context, mediaID, accessToken string) error {
-	var cursor string
+// offsetCursor parses a listing cursor holding an offset; invalid cursors start from the beginning
+func offsetCursor(after string) int {
+	if n, err := strconv.Atoi(after); err == nil && n > 0 {
+		return n
+	}
+	return 0
+}
+
+// commentPageSize is the number of comments requested per Instagram API call during sync
+const commentPageSize = 100
+
+// commentSyncOverlap re-fetches comments slightly older than the previous sync to absorb
+// clock differences between Instagram timestamps and ours
+const commentSyncOverlap = 5 * time.Minute
+
+// syncCommentsFromInstagram fetches comments from Instagram and saves them to DB page by page.
+// After a complete sync only new comments are fetched where the listing order allows it:
+// newest-first listings stop at comments older than the previous sync, oldest-first
+// listings continue from the cursor stored at the end of the previous sync.
+// Other comments are not refreshed (like counts, hidden state) by an incremental sync.
+// A full sync runs when there is no complete sync yet or the reconcile interval has passed;
+// it also removes stored comments that Instagram no longer lists.
+func (s *Service) syncCommentsFromInstagram(ctx context.Context, mediaID, accessToken string) (int, error) {
+	startedAt := time.Now()
+
+	prev, err := s.syncRepo.GetSyncStatus(ctx, mediaID)
+	if err != nil {
+		return 0, err
+	}
+	full := s.needsFullSync(prev, startedAt)
+	var since time.Time
+	resume := ""
+	if !full {
+		since = prev.LastSyncedAt.Add(-commentSyncOverlap)
+		resume = prev.NextCursor
+	}
+
+	var cursor, headNext, tailCursor string
+	var synced int
+	var seen []string // IDs listed by Instagram during a full sync
 	var wg sync.WaitGroup
 	errCh := make(chan error, 1)
+	order := listingUnknown
+	reachedEnd := false
 
-	for {
+	for page := 0; ; page++ {
 		// Check if context is cancelled
 		select {
 		case <-ctx.Done():
 			wg.Wait()
-			return ctx.Err()
+			return 0, ctx.Err()
 		default:
 		}
 
@@ -208,14 +400,26 @@ func (s *Service) syncCommentsFromInstagram(ctx context.Context, mediaID, access
 		select {
 		case err := <-errCh:
 			wg.Wait()
-			return err
+			return 0, err
 		default:
 		}
 
-		result, err := s.ig.GetComments(ctx, mediaID, accessToken, 100, cursor)
+		result, err := s.ig.GetComments(ctx, mediaID, accessToken, commentPageSize, cursor, nil, nil)
 		if err != nil {
+			if resume != "" && cursor == resume {
+				// The stored cursor may have expired; fall back to a full sync
+				resume, cursor = "", headNext
+				continue
+			}
 			wg.Wait()
-			return err
+			return 0, err
+		}
+
+		synced += len(result.Comments)
+		if full {
+			for _, c := range result.Comments {
+				seen = append(seen, c.ID)
+			}
 		}
 
 		// Save page asynchronously
@@ -236,8 +440,24 @@ func (s *Service) syncCommentsFromInstagram(ctx context.Context, mediaID, access
 		}
 
 		if !result.HasMore || result.NextCursor == "" {
+			reachedEnd = true
+			tailCursor = result.NextCursor
 			break
 		}
+
+		if page == 0 {
+			order = listingOrder(result.Comments)
+			headNext = result.NextCursor
+		}
+		if !since.IsZero() {
+			if order == listingNewestFirst && result.Comments[len(result.Comments)-1].Timestamp.Before(since) {
+				break // The rest was stored by the previous sync
+			}
+			if order == listingOldestFirst && page == 0 && resume != "" {
+				cursor = resume // Skip to where the previous sync ended
+				continue
+			}
+		}
 		cursor = result.NextCursor
 	}
 
@@ -247,16 +467,219 @@ func (s *Service) syncCommentsFromInstagram(ctx context.Context, mediaID, access
 	// Check for errors
 	select {
 	case err := <-errCh:
-		return err
+		return 0, err
 	default:
 	}
 
+	// Stopping early only happens after a complete sync, so the stored comments are complete
+	// either way; keep the previous end cursor unless this sync reached the end itself
+	nextCursor := tailCursor
+	if !reachedEnd && prev != nil {
+		nextCursor = prev.NextCursor
+	}
+
+	// Every page was fetched and saved, so comments missing from the listing were deleted on
+	// Instagram. An empty listing is not trusted: it would wipe the media's comments.
+	// Comments stored after the sync started (e.g. just posted through us) are kept.
+	if full && len(seen) > 0 {
+		if _, err := s.repo.DeleteMissing(ctx, mediaID, seen, startedAt); err != nil {
+			return 0, err
+		}
+	}
+
 	// Update sync status
-	return s.syncRepo.UpdateSyncStatus(ctx, &SyncStatus{
+	status := &SyncStatus{
 		InstagramMediaID: mediaID,
-		LastSyncedAt:     time.Now(),
+		LastSyncedAt:     startedAt,
+		NextCursor:       nextCursor,
 		SyncComplete:     true,
+	}
+	if full {
+		status.LastFullSyncAt = &startedAt
+	} else {
+		status.LastFullSyncAt = prev.LastFullSyncAt
+	}
+	if err := s.syncRepo.UpdateSyncStatus(ctx, status); err != nil {
+		return 0, err
+	}
+
+	// Classify new comments in the background so the sync doesn't wait for it
+	if s.classifier != nil {
+		s.enqueueClassify(mediaID)
+	}
+
+	return synced, nil
+}
+
+// needsFullSync reports whether the next sync of a media must fetch all of its comments
+func (s *Service) needsFullSync(prev *SyncStatus, now time.Time) bool {
+	if prev == nil || !prev.SyncComplete {
+		return true
+	}
+	if s.reconcileInterval <= 0 {
+		return false
+	}
+	return prev.LastFullSyncAt == nil || now.Sub(*prev.LastFullSyncAt) >= s.reconcileInterval
+}
+
+// Comment listing orders, detected from the first page of a sync
+const (
+	listingUnknown = iota
+	listingNewestFirst
+	listingOldestFirst
+)
+
+// listingOrder reports the order of a page of comments by comparing its first and last timestamps
+func listingOrder(comments []entity.Comment) int {
+	if len(comments) < 2 {
+		return listingUnknown
+	}
+	first, last := comments[0].Timestamp, comments[len(comments)-1].Timestamp
+	switch {
+	case first.After(last):
+		return listingNewestFirst
+	case first.Before(last):
+		return listingOldestFirst
+	default:
+		return listingUnknown
+	}
+}
+
+// enqueueClassify queues a media for background classification of its new comments.
+// If the media is already queued or being classified, that run picks the comments up.
+// When the queue is full or closed, they stay unclassified until the media's next sync.
+func (s *Service) enqueueClassify(mediaID string) {
+	s.classifyMu.Lock()
+	defer s.classifyMu.Unlock()
+	if s.classifyQueue == nil || s.classifyClosed {
+		return
+	}
+	if _, queued := s.classifying.LoadOrStore(mediaID, struct{}{}); queued {
+		return
+	}
+
+	select {
+	case s.classifyQueue <- mediaID:
+	default:
+		s.classifying.Delete(mediaID)
+	}
+}
+
+// classifyPending tags comments of a media that have no sentiment yet, one UPDATE per batch.
+// Best effort: failures leave comments unclassified until the next sync.
+func (s *Service) classifyPending(ctx context.Context, mediaID string) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	const batchSize = 200
+	for {
+		comments, err := s.repo.GetUnclassified(ctx, mediaID, batchSize)
+		if err != nil || len(comments) == 0 {
+			return
+		}
+
+		sentiments := make(map[string]entity.Sentiment, len(comments))
+		for _, c := range comments {
+			sentiment, err := s.classifier.Classify(ctx, c.Text)
+			if err != nil {
+				continue
+			}
+			sentiments[c.ID] = sentiment
+		}
+
+		// Stop if nothing could be classified or stored, or this was the last page
+		if len(sentiments) == 0 {
+			return
+		}
+		if err := s.repo.UpdateSentiments(ctx, sentiments); err != nil {
+			return
+		}
+		if len(comments) < batchSize {
+			return
+		}
+	}
+}
+
+// Comment tree bounds
+const (
+	DefaultTreeDepth      = 2
+	MaxTreeDepth          = 5
+	DefaultTreeMaxReplies = 10
+)
+
+// GetCommentTreeInput represents input for getting a threaded comment tree
+type GetCommentTreeInput struct {
+	AccountID   string // Optional, selects the account's sync max age
+	MediaID     string
+	AccessToken string
+	Limit       int
+	After       string
+	Depth       int // Levels including top-level comments; 0 means DefaultTreeDepth
+	MaxReplies  int // Replies kept per parent; 0 means DefaultTreeMaxReplies
+}
+
+// GetCommentTree retrieves a page of top-level comments with their replies nested inline.
+// Replies are loaded with one query per level, not per comment.
+func (s *Service) GetCommentTree(ctx context.Context, in GetCommentTreeInput) (*GetCommentsOutput, error) {
+	if in.Depth == 0 {
+		in.Depth = DefaultTreeDepth
+	}
+	if in.Depth < 1 || in.Depth > MaxTreeDepth {
+		return nil, entity.ErrInvalidTreeDepth
+	}
+	if in.MaxReplies <= 0 {
+		in.MaxReplies = DefaultTreeMaxReplies
+	}
+	if s.repo == nil {
+		return nil, fmt.Errorf("comment tree requires repository")
+	}
+
+	result, err := s.GetComments(ctx, GetCommentsInput{
+		AccountID:   in.AccountID,
+		MediaID:     in.MediaID,
+		AccessToken: in.AccessToken,
+		Limit:       in.Limit,
+		After:       in.After,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attachReplies(ctx, result.Comments, in.Depth-1, in.MaxReplies); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// attachReplies nests up to maxReplies replies under each comment, levels deep
+func (s *Service) attachReplies(ctx context.Context, comments []entity.Comment, levels, maxReplies int) error {
+	if levels <= 0 || len(comments) == 0 {
+		return nil
+	}
+
+	parentIDs := make([]string, len(comments))
+	for i, c := range comments {
+		parentIDs[i] = c.ID
+	}
+
+	replies, err := s.repo.GetRepliesForParents(ctx, parentIDs, maxReplies)
+	if err != nil {
+		return fmt.Errorf("getting replies: %w", err)
+	}
+
+	// Nest the next level first so the copies below carry their own replies
+	if err := s.attachReplies(ctx, replies, levels-1, maxReplies); err != nil {
+		return err
+	}
+
+	byParent := make(map[string][]entity.Comment, len(comments))
+	for _, r := range replies {
+		byParent[r.ParentID] = append(byParent[r.ParentID], r)
+	}
+	for i := range comments {
+		comments[i].Replies = byParent[comments[i].ID]
+	}
+	return nil
 }
 
 // GetRepliesInput represents input for getting comment replies
@@ -433,28 +856,155 @@ func (s *Service) Hide(ctx context.Context, in HideInput) error {
 	return nil
 }
 
-// SyncMediaComments syncs comments for a specific media (for scheduler use)
-func (s *Service) SyncMediaComments(ctx context.Context, mediaID, accessToken string) error {
+// SyncMediaComments syncs comments for a specific media and returns how many were fetched.
+// Returns ErrSyncInProgress if a sync for the same media is already running.
+func (s *Service) SyncMediaComments(ctx context.Context, mediaID, accessToken string) (int, error) {
 	if s.repo == nil || s.syncRepo == nil {
-		return nil
+		return 0, nil
+	}
+
+	if _, running := s.inFlight.LoadOrStore(mediaID, struct{}{}); running {
+		return 0, entity.ErrSyncInProgress
 	}
+	defer s.inFlight.Delete(mediaID)
+
 	return s.syncCommentsFromInstagram(ctx, mediaID, accessToken)
 }
 
+// GetSyncStatus returns the sync status for a media (nil if never synced)
+func (s *Service) GetSyncStatus(ctx context.Context, mediaID string) (*SyncStatus, error) {
+	if s.syncRepo == nil {
+		return nil, nil
+	}
+	return s.syncRepo.GetSyncStatus(ctx, mediaID)
+}
+
 // GetMediaIDsNeedingSync returns media IDs that need comment synchronization
-func (s *Service) GetMediaIDsNeedingSync(ctx context.Context, olderThan time.Duration, limit int) ([]string, error) {
+func (s *Service) GetMediaIDsNeedingSync(ctx context.Context, olderThan, maxPostAge time.Duration, limit int) ([]string, error) {
 	if s.syncRepo == nil {
 		return nil, nil
 	}
-	return s.syncRepo.GetMediaIDsNeedingSync(ctx, olderThan, limit)
+	return s.syncRepo.GetMediaIDsNeedingSync(ctx, olderThan, maxPostAge, limit)
+}
+
+// GetStatisticsInput represents input for getting comment statistics
+type GetStatisticsInput struct {
+	AccountID      string
+	StartDate      *time.Time // Optional; all-time when both dates are nil
+	EndDate        *time.Time
+	TopPostsSort   entity.TopPostsSort // Defaults to comments
+	TopPostsLimit  int                 // Defaults to 5
+	TopPostsOffset int
 }
 
 // GetStatistics retrieves aggregated comment statistics for an account
-func (s *Service) GetStatistics(ctx context.Context, accountID string, topPostsLimit int) (*entity.CommentStatistics, error) {
+// with one page of ranked top posts
+func (s *Service) GetStatistics(ctx context.Context, in GetStatisticsInput) (*entity.CommentStatistics, error) {
 	if s.repo == nil {
 		return nil, nil
 	}
-	return s.repo.GetStatistics(ctx, accountID, topPostsLimit)
+
+	q := entity.TopPostsQuery{
+		Sort:   in.TopPostsSort,
+		Limit:  in.TopPostsLimit,
+		Offset: in.TopPostsOffset,
+	}
+	if q.Sort == "" {
+		q.Sort = entity.TopPostsSortComments
+	}
+	if !q.Sort.IsValid() {
+		return nil, entity.ErrInvalidTopPostsSort
+	}
+	if q.Limit <= 0 {
+		q.Limit = 5
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+
+	filter := entity.StatisticsFilter{
+		AccountID: in.AccountID,
+		StartDate: in.StartDate,
+		EndDate:   in.EndDate,
+	}
+
+	stats, err := s.repo.GetStatistics(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra post to know whether another page exists
+	page := q
+	page.Limit = q.Limit + 1
+	posts, err := s.repo.GetTopPosts(ctx, filter, page)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(posts) > q.Limit {
+		posts = posts[:q.Limit]
+		next := q.Offset + q.Limit
+		stats.TopPostsHasMore = true
+		stats.TopPostsNextOffset = &next
+	}
+	stats.TopPosts = posts
+	if stats.TopPosts == nil {
+		stats.TopPosts = []entity.TopPost{}
+	}
+
+	return stats, nil
+}
+
+// GetRecentCommentsInput represents input for listing comments across an account's posts
+type GetRecentCommentsInput struct {
+	AccountID string
+	Since     *time.Time // Optional, only comments posted at or after Since
+	Mention   string     // Optional, only comments mentioning this username (see mention.Normalize)
+	Limit     int
+	After     string
+}
+
+// GetRecentCommentsOutput represents a page of comments across an account's posts
+type GetRecentCommentsOutput struct {
+	Comments   []entity.RecentComment `json:"comments"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+	HasMore    bool                   `json:"has_more"`
+}
+
+// GetRecentComments lists the stored comments on the account's published posts, newest first.
+// Only synced comments are listed: without storage the result is empty.
+func (s *Service) GetRecentComments(ctx context.Context, in GetRecentCommentsInput) (*GetRecentCommentsOutput, error) {
+	if s.repo == nil {
+		return &GetRecentCommentsOutput{Comments: []entity.RecentComment{}}, nil
+	}
+	if in.Limit <= 0 {
+		in.Limit = 50
+	}
+	offset := offsetCursor(in.After)
+
+	// Fetch one extra comment to know whether another page exists
+	comments, err := s.repo.GetRecentByAccount(ctx, entity.RecentCommentsQuery{
+		AccountID: in.AccountID,
+		Since:     in.Since,
+		Mention:   in.Mention,
+		Limit:     in.Limit + 1,
+		Offset:    offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := &GetRecentCommentsOutput{Comments: comments}
+	if len(comments) > in.Limit {
+		out.Comments = comments[:in.Limit]
+		out.HasMore = true
+		out.NextCursor = strconv.Itoa(offset + in.Limit)
+	}
+	if out.Comments == nil {
+		out.Comments = []entity.RecentComment{}
+	}
+
+	return out, nil
 }
 
 // GetComment retrieves a comment by ID
@@ -480,3 +1030,30 @@ func (s *Service) ResetSyncRetryCount(ctx context.Context, mediaID string) error
 	}
 	return s.syncRepo.ResetRetryCount(ctx, mediaID)
 }
+
+// ResetFailedSyncs puts an account's media that were excluded from sync after too many
+// failures back into the sync rotation. If mediaIDs is not empty only those media are reset.
+// It returns the media that were reset.
+func (s *Service) ResetFailedSyncs(ctx context.Context, accountID string, mediaIDs []string) ([]string, error) {
+	if s.syncRepo == nil {
+		return nil, nil
+	}
+
+	failed, err := s.syncRepo.GetFailedMediaIDs(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	reset := make([]string, 0, len(failed))
+	for _, id := range failed {
+		if len(mediaIDs) > 0 && !slices.Contains(mediaIDs, id) {
+			continue
+		}
+		if err := s.syncRepo.ResetRetryCount(ctx, id); err != nil {
+			return nil, err
+		}
+		reset = append(reset, id)
+	}
+
+	return reset, nil
+}
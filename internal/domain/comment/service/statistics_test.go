@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/comment/entity"
+)
+
+// rankedRepo emulates CommentPostgres.GetTopPosts over an in-memory set of posts
+type rankedRepo struct {
+	CommentRepository
+	posts   []entity.TopPost
+	filters []entity.StatisticsFilter // filters received by GetStatistics and GetTopPosts
+}
+
+func (r *rankedRepo) GetStatistics(_ context.Context, filter entity.StatisticsFilter) (*entity.CommentStatistics, error) {
+	r.filters = append(r.filters, filter)
+	return &entity.CommentStatistics{}, nil
+}
+
+func (r *rankedRepo) GetTopPosts(_ context.Context, filter entity.StatisticsFilter, q entity.TopPostsQuery) ([]entity.TopPost, error) {
+	r.filters = append(r.filters, filter)
+	sorted := append([]entity.TopPost(nil), r.posts...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if q.Sort == entity.TopPostsSortLikes {
+			return sorted[i].LikesCount > sorted[j].LikesCount
+		}
+		return sorted[i].CommentsCount > sorted[j].CommentsCount
+	})
+	if q.Offset >= len(sorted) {
+		return nil, nil
+	}
+	sorted = sorted[q.Offset:]
+	if len(sorted) > q.Limit {
+		sorted = sorted[:q.Limit]
+	}
+	return sorted, nil
+}
+
+func TestGetStatisticsPagesTopPosts(t *testing.T) {
+	repo := &rankedRepo{}
+	for i := 0; i < 7; i++ {
+		repo.posts = append(repo.posts, entity.TopPost{
+			MediaID:       fmt.Sprintf("media_%d", i),
+			CommentsCount: int64(100 - i),
+			LikesCount:    int64(i),
+		})
+	}
+	svc := NewWithRepo(nil, repo, nil)
+
+	seen := make(map[string]bool)
+	offset := 0
+	for pages := 1; ; pages++ {
+		stats, err := svc.GetStatistics(context.Background(), GetStatisticsInput{
+			AccountID:      "acc_1",
+			TopPostsLimit:  3,
+			TopPostsOffset: offset,
+		})
+		if err != nil {
+			t.Fatalf("GetStatistics: %v", err)
+		}
+		if len(stats.TopPosts) > 3 {
+			t.Fatalf("page %d has %d posts, want at most 3", pages, len(stats.TopPosts))
+		}
+		for _, p := range stats.TopPosts {
+			if seen[p.MediaID] {
+				t.Fatalf("post %s returned twice", p.MediaID)
+			}
+			seen[p.MediaID] = true
+		}
+
+		if !stats.TopPostsHasMore {
+			if stats.TopPostsNextOffset != nil {
+				t.Errorf("next offset = %d on last page, want nil", *stats.TopPostsNextOffset)
+			}
+			if pages != 3 {
+				t.Errorf("walked %d pages, want 3", pages)
+			}
+			break
+		}
+		if stats.TopPostsNextOffset == nil || *stats.TopPostsNextOffset != offset+3 {
+			t.Fatalf("next offset = %v, want %d", stats.TopPostsNextOffset, offset+3)
+		}
+		offset = *stats.TopPostsNextOffset
+	}
+
+	if len(seen) != len(repo.posts) {
+		t.Errorf("saw %d posts, want %d", len(seen), len(repo.posts))
+	}
+}
+
+func TestGetStatisticsSortsByLikes(t *testing.T) {
+	repo := &rankedRepo{posts: []entity.TopPost{
+		{MediaID: "many_comments", CommentsCount: 50, LikesCount: 1},
+		{MediaID: "many_likes", CommentsCount: 2, LikesCount: 90},
+	}}
+	svc := NewWithRepo(nil, repo, nil)
+
+	stats, err := svc.GetStatistics(context.Background(), GetStatisticsInput{
+		AccountID:     "acc_1",
+		TopPostsSort:  entity.TopPostsSortLikes,
+		TopPostsLimit: 1,
+	})
+	if err != nil {
+		t.Fatalf("GetStatistics: %v", err)
+	}
+	if len(stats.TopPosts) != 1 || stats.TopPosts[0].MediaID != "many_likes" {
+		t.Fatalf("top posts = %+v, want many_likes first", stats.TopPosts)
+	}
+	if !stats.TopPostsHasMore {
+		t.Error("has_more = false, want true")
+	}
+}
+
+func TestGetStatisticsRejectsUnknownSort(t *testing.T) {
+	svc := NewWithRepo(nil, &rankedRepo{}, nil)
+
+	_, err := svc.GetStatistics(context.Background(), GetStatisticsInput{AccountID: "acc_1", TopPostsSort: "shares"})
+	if err != entity.ErrInvalidTopPostsSort {
+		t.Fatalf("err = %v, want ErrInvalidTopPostsSort", err)
+	}
+}
+
+func TestGetStatisticsPassesDateRange(t *testing.T) {
+	repo := &rankedRepo{}
+	svc := NewWithRepo(nil, repo, nil)
+
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 6, 30, 23, 59, 59, 0, time.UTC)
+	if _, err := svc.GetStatistics(context.Background(), GetStatisticsInput{
+		AccountID: "acc_1",
+		StartDate: &start,
+		EndDate:   &end,
+	}); err != nil {
+		t.Fatalf("GetStatistics: %v", err)
+	}
+
+	if len(repo.filters) != 2 {
+		t.Fatalf("repo called %d times, want 2", len(repo.filters))
+	}
+	for _, f := range repo.filters {
+		if f.AccountID != "acc_1" || f.StartDate == nil || !f.StartDate.Equal(start) || f.EndDate == nil || !f.EndDate.Equal(end) {
+			t.Errorf("filter = %+v, want acc_1 with June range", f)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/comment/entity"
+)
+
+// listingClient serves a media's comments in a fixed order with index cursors.
+// Comment i was posted i minutes after base.
+type listingClient struct {
+	InstagramClient
+	base        time.Time
+	total       int
+	newestFirst bool
+	badCursor   string // Cursor rejected as expired
+	calls       []string
+}
+
+func (c *listingClient) GetComments(_ context.Context, mediaID, _ string, limit int, after string, _, _ *time.Time) (*CommentsResult, error) {
+	c.calls = append(c.calls, after)
+	if after != "" && after == c.badCursor {
+		return nil, errors.New("invalid cursor")
+	}
+
+	start := 0
+	if after != "" {
+		start, _ = strconv.Atoi(after)
+	}
+
+	res := &CommentsResult{}
+	for pos := start; pos < c.total && len(res.Comments) < limit; pos++ {
+		i := pos
+		if c.newestFirst {
+			i = c.total - 1 - pos
+		}
+		res.Comments = append(res.Comments, entity.Comment{
+			ID:        fmt.Sprintf("c%d", i),
+			MediaID:   mediaID,
+			Timestamp: c.base.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	// Like the Graph API, the after cursor is set on the last page too
+	res.NextCursor = strconv.Itoa(start + len(res.Comments))
+	res.HasMore = start+len(res.Comments) < c.total
+	return res, nil
+}
+
+// memCommentRepo stores upserted comments by ID
+type memCommentRepo struct {
+	CommentRepository
+	mu       sync.Mutex
+	comments map[string]entity.Comment
+}
+
+func (r *memCommentRepo) UpsertBatch(_ context.Context, comments []entity.Comment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range comments {
+		r.comments[c.ID] = c
+	}
+	return nil
+}
+
+func (r *memCommentRepo) DeleteMissing(_ context.Context, mediaID string, keepIDs []string, _ time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	keep := make(map[string]bool, len(keepIDs))
+	for _, id := range keepIDs {
+		keep[id] = true
+	}
+	var n int64
+	for id, c := range r.comments {
+		if c.MediaID == mediaID && c.ParentID == "" && !keep[id] {
+			delete(r.comments, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// memSyncRepo keeps the last stored status
+type memSyncRepo struct {
+	SyncStatusRepository
+	status *SyncStatus
+}
+
+func (r *memSyncRepo) GetSyncStatus(_ context.Context, _ string) (*SyncStatus, error) {
+	return r.status, nil
+}
+
+func (r *memSyncRepo) UpdateSyncStatus(_ context.Context, status *SyncStatus) error {
+	r.status = status
+	return nil
+}
+
+// syncTwice runs a full sync, adds three comments and syncs again
+func syncTwice(t *testing.T, ig *listingClient) (*memCommentRepo, *memSyncRepo, int) {
+	t.Helper()
+	repo := &memCommentRepo{comments: map[string]entity.Comment{}}
+	syncRepo := &memSyncRepo{}
+	svc := NewWithRepo(ig, repo, syncRepo)
+
+	if _, err := svc.SyncMediaComments(context.Background(), "m1", "token"); err != nil {
+		t.Fatalf("first sync: %v", err)
+	}
+	if !syncRepo.status.SyncComplete {
+		t.Fatal("first sync not marked complete")
+	}
+
+	ig.calls = nil
+	ig.total += 3
+	n, err := svc.SyncMediaComments(context.Background(), "m1", "token")
+	if err != nil {
+		t.Fatalf("second sync: %v", err)
+	}
+	if len(repo.comments) != ig.total {
+		t.Errorf("stored %d comments, want %d", len(repo.comments), ig.total)
+	}
+	if !syncRepo.status.SyncComplete {
+		t.Error("incremental sync not marked complete")
+	}
+	return repo, syncRepo, n
+}
+
+func TestSyncCommentsResumesFromStoredCursor(t *testing.T) {
+	ig := &listingClient{base: time.Now().Add(-24 * time.Hour), total: 450}
+	_, syncRepo, n := syncTwice(t, ig)
+
+	// The head page is read to detect the order, then the sync continues at the old end
+	if fmt.Sprint(ig.calls) != "[ 450]" {
+		t.Errorf("second sync cursors = %q, want [\"\" 450]", ig.calls)
+	}
+	if n != 103 {
+		t.Errorf("second sync fetched %d comments, want 103", n)
+	}
+	if syncRepo.status.NextCursor != "453" {
+		t.Errorf("stored cursor = %q, want 453", syncRepo.status.NextCursor)
+	}
+}
+
+func TestSyncCommentsStopsAtPreviousSyncWhenNewestFirst(t *testing.T) {
+	ig := &listingClient{base: time.Now().Add(-24 * time.Hour), total: 450, newestFirst: true}
+	repo := &memCommentRepo{comments: map[string]entity.Comment{}}
+	syncRepo := &memSyncRepo{}
+	svc := NewWithRepo(ig, repo, syncRepo)
+
+	if _, err := svc.SyncMediaComments(context.Background(), "m1", "token"); err != nil {
+		t.Fatalf("first sync: %v", err)
+	}
+
+	// The first sync ran just before three new comments were posted
+	syncRepo.status.LastSyncedAt = ig.base.Add(450 * time.Minute)
+	ig.calls = nil
+	ig.total += 3
+
+	if _, err := svc.SyncMediaComments(context.Background(), "m1", "token"); err != nil {
+		t.Fatalf("second sync: %v", err)
+	}
+	if len(ig.calls) != 1 {
+		t.Errorf("second sync made %d calls, want 1", len(ig.calls))
+	}
+	if len(repo.comments) != ig.total {
+		t.Errorf("stored %d comments, want %d", len(repo.comments), ig.total)
+	}
+}
+
+func TestSyncCommentsFallsBackWhenCursorExpired(t *testing.T) {
+	ig := &listingClient{base: time.Now().Add(-24 * time.Hour), total: 450, badCursor: "450"}
+	_, _, n := syncTwice(t, ig)
+
+	if n != 453 {
+		t.Errorf("second sync fetched %d comments, want a full re-sync of 453", n)
+	}
+}
+
+func TestFullSyncRemovesCommentsDeletedOnInstagram(t *testing.T) {
+	ig := &listingClient{base: time.Now().Add(-24 * time.Hour), total: 150}
+	repo := &memCommentRepo{comments: map[string]entity.Comment{}}
+	syncRepo := &memSyncRepo{}
+	svc := NewWithRepo(ig, repo, syncRepo).WithReconcileInterval(time.Hour)
+
+	if _, err := svc.SyncMediaComments(context.Background(), "m1", "token"); err != nil {
+		t.Fatalf("first sync: %v", err)
+	}
+	if syncRepo.status.LastFullSyncAt == nil {
+		t.Fatal("first sync not recorded as full")
+	}
+
+	// The author of c149 deletes it; an incremental sync does not notice
+	ig.total--
+	if _, err := svc.SyncMediaComments(context.Background(), "m1", "token"); err != nil {
+		t.Fatalf("incremental sync: %v", err)
+	}
+	if _, ok := repo.comments["c149"]; !ok {
+		t.Fatal("incremental sync removed a comment")
+	}
+
+	// Once the reconcile interval has passed the media is synced in full
+	stale := time.Now().Add(-2 * time.Hour)
+	syncRepo.status.LastFullSyncAt = &stale
+	if _, err := svc.SyncMediaComments(context.Background(), "m1", "token"); err != nil {
+		t.Fatalf("full sync: %v", err)
+	}
+	if _, ok := repo.comments["c149"]; ok {
+		t.Error("deleted comment still stored after full sync")
+	}
+	if len(repo.comments) != 149 {
+		t.Errorf("stored %d comments, want 149", len(repo.comments))
+	}
+	if !syncRepo.status.LastFullSyncAt.After(stale) {
+		t.Error("full sync time not updated")
+	}
+}
+
+func TestFailedFullSyncKeepsComments(t *testing.T) {
+	ig := &listingClient{base: time.Now().Add(-24 * time.Hour), total: 250}
+	repo := &memCommentRepo{comments: map[string]entity.Comment{}}
+	syncRepo := &memSyncRepo{}
+	svc := NewWithRepo(ig, repo, syncRepo).WithReconcileInterval(time.Hour)
+
+	if _, err := svc.SyncMediaComments(context.Background(), "m1", "token"); err != nil {
+		t.Fatalf("first sync: %v", err)
+	}
+
+	// The full sync lists the first page, then fails
+	ig.badCursor = "100"
+	syncRepo.status.LastFullSyncAt = nil
+	if _, err := svc.SyncMediaComments(context.Background(), "m1", "token"); err == nil {
+		t.Fatal("expected the failed fetch to fail the sync")
+	}
+	if len(repo.comments) != 250 {
+		t.Errorf("stored %d comments after a failed sync, want 250", len(repo.comments))
+	}
+}
+
+// classifyingRepo also serves unclassified comments and stores sentiments, counting the updates
+type classifyingRepo struct {
+	memCommentRepo
+	updates int
+}
+
+func (r *classifyingRepo) GetUnclassified(_ context.Context, mediaID string, limit int) ([]entity.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []entity.Comment
+	for _, c := range r.comments {
+		if c.MediaID == mediaID && c.Sentiment == "" && len(out) < limit {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (r *classifyingRepo) UpdateSentiments(_ context.Context, sentiments map[string]entity.Sentiment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updates++
+	for id, sentiment := range sentiments {
+		c := r.comments[id]
+		c.Sentiment = sentiment
+		r.comments[id] = c
+	}
+	return nil
+}
+
+// constClassifier tags every comment the same
+type constClassifier entity.Sentiment
+
+func (c constClassifier) Classify(context.Context, string) (entity.Sentiment, error) {
+	return entity.Sentiment(c), nil
+}
+
+func TestSyncClassifiesInBackground(t *testing.T) {
+	ig := &listingClient{base: time.Now().Add(-time.Hour), total: 450}
+	repo := &classifyingRepo{memCommentRepo: memCommentRepo{comments: map[string]entity.Comment{}}}
+	svc := NewWithRepo(ig, repo, &memSyncRepo{}).WithClassifier(constClassifier(entity.SentimentPositive))
+
+	if _, err := svc.SyncMediaComments(context.Background(), "m1", "token"); err != nil {
+		t.Fatalf("SyncMediaComments: %v", err)
+	}
+
+	// Close drains the queue, so every comment is tagged once it returns
+	svc.Close(context.Background())
+	for id, c := range repo.comments {
+		if c.Sentiment != entity.SentimentPositive {
+			t.Fatalf("comment %s sentiment = %q, want positive", id, c.Sentiment)
+		}
+	}
+	if len(repo.comments) != 450 {
+		t.Errorf("stored %d comments, want 450", len(repo.comments))
+	}
+	// One UPDATE per batch of 200: 200 + 200 + 50
+	if repo.updates != 3 {
+		t.Errorf("sentiment updates = %d, want 3", repo.updates)
+	}
+
+	// Syncs after Close are not classified and don't block
+	ig.total += 5
+	if _, err := svc.SyncMediaComments(context.Background(), "m1", "token"); err != nil {
+		t.Fatalf("SyncMediaComments after Close: %v", err)
+	}
+	if repo.updates != 3 {
+		t.Errorf("sentiment updates after Close = %d, want 3", repo.updates)
+	}
+}
+
+// blockingClassifier never finishes until its context is cancelled
+type blockingClassifier struct{}
+
+func (blockingClassifier) Classify(ctx context.Context, _ string) (entity.Sentiment, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestCloseCancelsClassificationWhenContextDone(t *testing.T) {
+	ig := &listingClient{base: time.Now().Add(-time.Hour), total: 10}
+	repo := &classifyingRepo{memCommentRepo: memCommentRepo{comments: map[string]entity.Comment{}}}
+	svc := NewWithRepo(ig, repo, &memSyncRepo{}).WithClassifier(blockingClassifier{})
+
+	if _, err := svc.SyncMediaComments(context.Background(), "m1", "token"); err != nil {
+		t.Fatalf("SyncMediaComments: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		svc.Close(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not return after its context was done")
+	}
+	if repo.updates != 0 {
+		t.Errorf("sentiment updates = %d, want 0", repo.updates)
+	}
+}
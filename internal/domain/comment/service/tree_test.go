@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/comment/entity"
+)
+
+// treeRepo serves top-level comments and replies, counting the batched reply queries
+type treeRepo struct {
+	filterRepo
+	replies      []entity.Comment
+	replyQueries int
+}
+
+func (r *treeRepo) GetRepliesForParents(_ context.Context, parentIDs []string, maxPerParent int) ([]entity.Comment, error) {
+	r.replyQueries++
+	perParent := map[string]int{}
+	var replies []entity.Comment
+	for _, id := range parentIDs {
+		for _, c := range r.replies {
+			if c.ParentID == id && perParent[id] < maxPerParent {
+				perParent[id]++
+				replies = append(replies, c)
+			}
+		}
+	}
+	return replies, nil
+}
+
+func newTreeService() (*Service, *treeRepo) {
+	repo := &treeRepo{
+		filterRepo: filterRepo{comments: []entity.Comment{
+			{ID: "a", MediaID: "m1"},
+			{ID: "b", MediaID: "m1"},
+		}},
+		replies: []entity.Comment{
+			{ID: "a1", MediaID: "m1", ParentID: "a"},
+			{ID: "a2", MediaID: "m1", ParentID: "a"},
+			{ID: "a3", MediaID: "m1", ParentID: "a"},
+			{ID: "b1", MediaID: "m1", ParentID: "b"},
+			{ID: "a1x", MediaID: "m1", ParentID: "a1"},
+		},
+	}
+	syncRepo := &memSyncRepo{status: &SyncStatus{InstagramMediaID: "m1", LastSyncedAt: time.Now()}}
+	return NewWithRepo(nil, repo, syncRepo), repo
+}
+
+func TestGetCommentTreeNestsReplies(t *testing.T) {
+	svc, repo := newTreeService()
+
+	out, err := svc.GetCommentTree(context.Background(), GetCommentTreeInput{MediaID: "m1", MaxReplies: 2})
+	if err != nil {
+		t.Fatalf("GetCommentTree: %v", err)
+	}
+
+	if got := fmt.Sprint(commentIDs(out.Comments)); got != "[a b]" {
+		t.Fatalf("top-level = %s, want [a b]", got)
+	}
+	// max_replies bounds each parent's replies
+	if got := fmt.Sprint(commentIDs(out.Comments[0].Replies)); got != "[a1 a2]" {
+		t.Errorf("replies of a = %s, want [a1 a2]", got)
+	}
+	if got := fmt.Sprint(commentIDs(out.Comments[1].Replies)); got != "[b1]" {
+		t.Errorf("replies of b = %s, want [b1]", got)
+	}
+	// The default depth of 2 stops below the first reply level
+	if len(out.Comments[0].Replies[0].Replies) != 0 {
+		t.Errorf("depth 2 nested a third level: %v", out.Comments[0].Replies[0].Replies)
+	}
+	if repo.replyQueries != 1 {
+		t.Errorf("reply queries = %d, want 1 for all parents", repo.replyQueries)
+	}
+}
+
+func TestGetCommentTreeDepth(t *testing.T) {
+	svc, repo := newTreeService()
+
+	out, err := svc.GetCommentTree(context.Background(), GetCommentTreeInput{MediaID: "m1", Depth: 3})
+	if err != nil {
+		t.Fatalf("GetCommentTree: %v", err)
+	}
+	if got := fmt.Sprint(commentIDs(out.Comments[0].Replies[0].Replies)); got != "[a1x]" {
+		t.Errorf("replies of a1 = %s, want [a1x]", got)
+	}
+	if repo.replyQueries != 2 {
+		t.Errorf("reply queries = %d, want one per level", repo.replyQueries)
+	}
+
+	flat, err := svc.GetCommentTree(context.Background(), GetCommentTreeInput{MediaID: "m1", Depth: 1})
+	if err != nil {
+		t.Fatalf("GetCommentTree depth 1: %v", err)
+	}
+	if len(flat.Comments[0].Replies) != 0 {
+		t.Errorf("depth 1 returned replies: %v", flat.Comments[0].Replies)
+	}
+
+	for _, depth := range []int{-1, MaxTreeDepth + 1} {
+		_, err := svc.GetCommentTree(context.Background(), GetCommentTreeInput{MediaID: "m1", Depth: depth})
+		if !errors.Is(err, entity.ErrInvalidTreeDepth) {
+			t.Errorf("depth %d: err = %v, want ErrInvalidTreeDepth", depth, err)
+		}
+	}
+}
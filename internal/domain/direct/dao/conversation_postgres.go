@@ -8,17 +8,25 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/vadim/neo-metric/internal/database"
 	"github.com/vadim/neo-metric/internal/domain/direct/entity"
 )
 
 // ConversationPostgres implements conversation repository for PostgreSQL
 type ConversationPostgres struct {
-	pool *pgxpool.Pool
+	pool      *pgxpool.Pool
+	batchSize int
 }
 
 // NewConversationPostgres creates a new PostgreSQL conversation repository
 func NewConversationPostgres(pool *pgxpool.Pool) *ConversationPostgres {
-	return &ConversationPostgres{pool: pool}
+	return &ConversationPostgres{pool: pool, batchSize: database.DefaultBatchSize}
+}
+
+// WithBatchSize limits how many conversations UpsertBatch sends in one round-trip
+func (r *ConversationPostgres) WithBatchSize(size int) *ConversationPostgres {
+	r.batchSize = size
+	return r
 }
 
 // Upsert inserts or updates a conversation
@@ -27,8 +35,8 @@ func (r *ConversationPostgres) Upsert(ctx context.Context, conv *entity.Conversa
 		INSERT INTO dm_conversations (
 			id, account_id, participant_id, participant_username, participant_name,
 			participant_avatar_url, participant_followers_count, last_message_text,
-			last_message_at, last_message_is_from_me, unread_count, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			last_message_at, last_message_is_from_me, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		ON CONFLICT (id) DO UPDATE SET
 			participant_username = EXCLUDED.participant_username,
 			participant_name = EXCLUDED.participant_name,
@@ -37,7 +45,6 @@ func (r *ConversationPostgres) Upsert(ctx context.Context, conv *entity.Conversa
 			last_message_text = EXCLUDED.last_message_text,
 			last_message_at = EXCLUDED.last_message_at,
 			last_message_is_from_me = EXCLUDED.last_message_is_from_me,
-			unread_count = EXCLUDED.unread_count,
 			updated_at = EXCLUDED.updated_at
 	`
 
@@ -53,7 +60,6 @@ func (r *ConversationPostgres) Upsert(ctx context.Context, conv *entity.Conversa
 		conv.LastMessageText,
 		conv.LastMessageAt,
 		conv.LastMessageIsFromMe,
-		conv.UnreadCount,
 		now,
 		now,
 	)
@@ -64,19 +70,22 @@ func (r *ConversationPostgres) Upsert(ctx context.Context, conv *entity.Conversa
 	return nil
 }
 
-// UpsertBatch inserts or updates multiple conversations
+// UpsertBatch inserts or updates multiple conversations, sending at most batchSize per round-trip
 func (r *ConversationPostgres) UpsertBatch(ctx context.Context, convs []entity.Conversation) error {
-	if len(convs) == 0 {
-		return nil
-	}
+	return database.Chunks(len(convs), r.batchSize, func(start, end int) error {
+		return r.upsertChunk(ctx, convs[start:end])
+	})
+}
 
+// upsertChunk upserts conversations in a single batch
+func (r *ConversationPostgres) upsertChunk(ctx context.Context, convs []entity.Conversation) error {
 	batch := &pgx.Batch{}
 	query := `
 		INSERT INTO dm_conversations (
 			id, account_id, participant_id, participant_username, participant_name,
 			participant_avatar_url, participant_followers_count, last_message_text,
-			last_message_at, last_message_is_from_me, unread_count, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			last_message_at, last_message_is_from_me, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		ON CONFLICT (id) DO UPDATE SET
 			participant_username = EXCLUDED.participant_username,
 			participant_name = EXCLUDED.participant_name,
@@ -85,7 +94,6 @@ func (r *ConversationPostgres) UpsertBatch(ctx context.Context, convs []entity.C
 			last_message_text = EXCLUDED.last_message_text,
 			last_message_at = EXCLUDED.last_message_at,
 			last_message_is_from_me = EXCLUDED.last_message_is_from_me,
-			unread_count = EXCLUDED.unread_count,
 			updated_at = EXCLUDED.updated_at
 	`
 
@@ -102,7 +110,6 @@ func (r *ConversationPostgres) UpsertBatch(ctx context.Context, convs []entity.C
 			conv.LastMessageText,
 			conv.LastMessageAt,
 			conv.LastMessageIsFromMe,
-			conv.UnreadCount,
 			now,
 			now,
 		)
@@ -120,39 +127,118 @@ func (r *ConversationPostgres) UpsertBatch(ctx context.Context, convs []entity.C
 	return nil
 }
 
+// unreadCountColumn computes the unread count of a conversation (aliased c): incoming
+// messages newer than last_read_at, or all incoming messages if it was never read
+const unreadCountColumn = `(
+		           SELECT COUNT(*) FROM dm_messages um
+		           WHERE um.conversation_id = c.id AND NOT um.is_from_me
+		             AND (c.last_read_at IS NULL OR um.timestamp > c.last_read_at)
+		       ) AS unread_count`
+
 // GetByID retrieves a conversation by ID
 func (r *ConversationPostgres) GetByID(ctx context.Context, id string) (*entity.Conversation, error) {
 	query := `
-		SELECT id, account_id, participant_id, participant_username, participant_name,
-		       participant_avatar_url, participant_followers_count, last_message_text,
-		       last_message_at, last_message_is_from_me, unread_count, created_at, updated_at
-		FROM dm_conversations
-		WHERE id = $1
+		SELECT c.id, c.account_id, c.participant_id, c.participant_username, c.participant_name,
+		       c.participant_avatar_url, c.participant_followers_count, c.last_message_text,
+		       c.last_message_at, c.last_message_is_from_me, ` + unreadCountColumn + `, c.last_read_at,
+		       c.created_at, c.updated_at
+		FROM dm_conversations c
+		WHERE c.id = $1
 	`
 
 	row := r.pool.QueryRow(ctx, query, id)
-	return r.scanConversation(row)
+	conv, err := r.scanConversation(row)
+	if err != nil || conv == nil {
+		return conv, err
+	}
+
+	labels, err := r.GetLabels(ctx, conv.ID)
+	if err != nil {
+		return nil, err
+	}
+	conv.Labels = labels
+	return conv, nil
 }
 
 // GetByAccountID retrieves conversations for an account with pagination
-func (r *ConversationPostgres) GetByAccountID(ctx context.Context, accountID string, limit, offset int) ([]entity.Conversation, error) {
+// If labels is non-empty, only conversations carrying all of them are returned.
+func (r *ConversationPostgres) GetByAccountID(ctx context.Context, accountID string, labels []string, limit, offset int) ([]entity.Conversation, error) {
+	labelClause, labelArgs := labelFilter(labels, 4)
 	query := `
-		SELECT id, account_id, participant_id, participant_username, participant_name,
-		       participant_avatar_url, participant_followers_count, last_message_text,
-		       last_message_at, last_message_is_from_me, unread_count, created_at, updated_at
-		FROM dm_conversations
-		WHERE account_id = $1
-		ORDER BY last_message_at DESC NULLS LAST, updated_at DESC
+		SELECT c.id, c.account_id, c.participant_id, c.participant_username, c.participant_name,
+		       c.participant_avatar_url, c.participant_followers_count, c.last_message_text,
+		       c.last_message_at, c.last_message_is_from_me, ` + unreadCountColumn + `, c.last_read_at,
+		       c.created_at, c.updated_at
+		FROM dm_conversations c
+		WHERE c.account_id = $1` + labelClause + `
+		ORDER BY c.last_message_at DESC NULLS LAST, c.updated_at DESC
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.pool.Query(ctx, query, accountID, limit, offset)
+	args := append([]interface{}{accountID, limit, offset}, labelArgs...)
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("querying conversations: %w", err)
 	}
 	defer rows.Close()
 
-	return r.scanConversations(rows)
+	conversations, err := r.scanConversations(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.attachLabels(ctx, conversations); err != nil {
+		return nil, err
+	}
+	return conversations, nil
+}
+
+// GetByParticipant retrieves all conversations of an account with the given participant
+func (r *ConversationPostgres) GetByParticipant(ctx context.Context, accountID, participantID string) ([]entity.Conversation, error) {
+	query := `
+		SELECT c.id, c.account_id, c.participant_id, c.participant_username, c.participant_name,
+		       c.participant_avatar_url, c.participant_followers_count, c.last_message_text,
+		       c.last_message_at, c.last_message_is_from_me, ` + unreadCountColumn + `, c.last_read_at,
+		       c.created_at, c.updated_at
+		FROM dm_conversations c
+		WHERE c.account_id = $1 AND c.participant_id = $2
+		ORDER BY c.created_at
+	`
+
+	rows, err := r.pool.Query(ctx, query, accountID, participantID)
+	if err != nil {
+		return nil, fmt.Errorf("querying participant conversations: %w", err)
+	}
+	defer rows.Close()
+
+	conversations, err := r.scanConversations(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.attachLabels(ctx, conversations); err != nil {
+		return nil, err
+	}
+	return conversations, nil
+}
+
+// UpdateParticipant caches a participant's profile on all of the account's conversations with them
+func (r *ConversationPostgres) UpdateParticipant(ctx context.Context, accountID string, p *entity.Participant) error {
+	query := `
+		UPDATE dm_conversations
+		SET participant_username = COALESCE(NULLIF($3, ''), participant_username),
+		    participant_name = $4,
+		    participant_avatar_url = $5,
+		    participant_followers_count = $6,
+		    updated_at = NOW()
+		WHERE account_id = $1 AND participant_id = $2
+	`
+
+	_, err := r.pool.Exec(ctx, query, accountID, p.ID, p.Username, p.Name, p.AvatarURL, p.FollowersCount)
+	if err != nil {
+		return fmt.Errorf("updating participant profile: %w", err)
+	}
+	return nil
 }
 
 // Search searches conversations by participant username, name, or message text
@@ -160,7 +246,8 @@ func (r *ConversationPostgres) Search(ctx context.Context, accountID, query stri
 	sqlQuery := `
 		SELECT DISTINCT c.id, c.account_id, c.participant_id, c.participant_username, c.participant_name,
 		       c.participant_avatar_url, c.participant_followers_count, c.last_message_text,
-		       c.last_message_at, c.last_message_is_from_me, c.unread_count, c.created_at, c.updated_at
+		       c.last_message_at, c.last_message_is_from_me, ` + unreadCountColumn + `, c.last_read_at,
+		       c.created_at, c.updated_at
 		FROM dm_conversations c
 		LEFT JOIN dm_messages m ON m.conversation_id = c.id
 		WHERE c.account_id = $1
@@ -180,7 +267,24 @@ func (r *ConversationPostgres) Search(ctx context.Context, accountID, query stri
 	}
 	defer rows.Close()
 
-	return r.scanConversations(rows)
+	conversations, err := r.scanConversations(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.attachLabels(ctx, conversations); err != nil {
+		return nil, err
+	}
+	return conversations, nil
+}
+
+// MarkRead sets when a conversation was last read; incoming messages up to then no longer count as unread
+func (r *ConversationPostgres) MarkRead(ctx context.Context, id string, readAt time.Time) error {
+	_, err := r.pool.Exec(ctx, "UPDATE dm_conversations SET last_read_at = $2 WHERE id = $1", id, readAt)
+	if err != nil {
+		return fmt.Errorf("marking conversation read: %w", err)
+	}
+	return nil
 }
 
 // Delete removes a conversation
@@ -193,15 +297,125 @@ func (r *ConversationPostgres) Delete(ctx context.Context, id string) error {
 }
 
 // Count returns the total count of conversations for an account
-func (r *ConversationPostgres) Count(ctx context.Context, accountID string) (int64, error) {
+// If labels is non-empty, only conversations carrying all of them are counted.
+func (r *ConversationPostgres) Count(ctx context.Context, accountID string, labels []string) (int64, error) {
+	labelClause, labelArgs := labelFilter(labels, 2)
+	query := "SELECT COUNT(*) FROM dm_conversations c WHERE c.account_id = $1" + labelClause
+
 	var count int64
-	err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM dm_conversations WHERE account_id = $1", accountID).Scan(&count)
+	err := r.pool.QueryRow(ctx, query, append([]interface{}{accountID}, labelArgs...)...).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("counting conversations: %w", err)
 	}
 	return count, nil
 }
 
+// AddLabels attaches labels to a conversation (labels it already has are ignored)
+func (r *ConversationPostgres) AddLabels(ctx context.Context, accountID, conversationID string, labels []string) error {
+	query := `
+		INSERT INTO conversation_labels (conversation_id, account_id, label, created_at)
+		SELECT $1, $2, unnest($3::text[]), $4
+		ON CONFLICT (conversation_id, label) DO NOTHING
+	`
+
+	_, err := r.pool.Exec(ctx, query, conversationID, accountID, labels, time.Now())
+	if err != nil {
+		return fmt.Errorf("adding conversation labels: %w", err)
+	}
+	return nil
+}
+
+// RemoveLabels detaches labels from a conversation
+func (r *ConversationPostgres) RemoveLabels(ctx context.Context, conversationID string, labels []string) error {
+	_, err := r.pool.Exec(ctx,
+		"DELETE FROM conversation_labels WHERE conversation_id = $1 AND label = ANY($2)",
+		conversationID, labels,
+	)
+	if err != nil {
+		return fmt.Errorf("removing conversation labels: %w", err)
+	}
+	return nil
+}
+
+// GetLabels returns the labels of a conversation in alphabetical order
+func (r *ConversationPostgres) GetLabels(ctx context.Context, conversationID string) ([]string, error) {
+	rows, err := r.pool.Query(ctx,
+		"SELECT label FROM conversation_labels WHERE conversation_id = $1 ORDER BY label",
+		conversationID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying conversation labels: %w", err)
+	}
+	defer rows.Close()
+
+	labels := []string{}
+	for rows.Next() {
+		var label string
+		if err := rows.Scan(&label); err != nil {
+			return nil, fmt.Errorf("scanning conversation label: %w", err)
+		}
+		labels = append(labels, label)
+	}
+	return labels, rows.Err()
+}
+
+// attachLabels loads labels for a page of conversations in a single query
+func (r *ConversationPostgres) attachLabels(ctx context.Context, conversations []entity.Conversation) error {
+	if len(conversations) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(conversations))
+	for i := range conversations {
+		ids[i] = conversations[i].ID
+		conversations[i].Labels = []string{}
+	}
+
+	rows, err := r.pool.Query(ctx,
+		"SELECT conversation_id, label FROM conversation_labels WHERE conversation_id = ANY($1) ORDER BY label",
+		ids,
+	)
+	if err != nil {
+		return fmt.Errorf("querying conversation labels: %w", err)
+	}
+	defer rows.Close()
+
+	byConv := make(map[string][]string, len(conversations))
+	for rows.Next() {
+		var convID, label string
+		if err := rows.Scan(&convID, &label); err != nil {
+			return fmt.Errorf("scanning conversation label: %w", err)
+		}
+		byConv[convID] = append(byConv[convID], label)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating conversation labels: %w", err)
+	}
+
+	for i := range conversations {
+		if labels, ok := byConv[conversations[i].ID]; ok {
+			conversations[i].Labels = labels
+		}
+	}
+	return nil
+}
+
+// labelFilter builds a WHERE fragment restricting conversations (aliased c) to
+// those carrying every given label (AND semantics). Labels must be de-duplicated.
+// Placeholders start at $argNum. Returns an empty clause when labels is empty.
+func labelFilter(labels []string, argNum int) (string, []interface{}) {
+	if len(labels) == 0 {
+		return "", nil
+	}
+
+	clause := fmt.Sprintf(`
+		  AND (
+		    SELECT COUNT(DISTINCT l.label) FROM conversation_labels l
+		    WHERE l.conversation_id = c.id AND l.label = ANY($%d)
+		  ) = $%d`, argNum, argNum+1)
+	return clause, []interface{}{labels, len(labels)}
+}
+
 // scanConversation scans a single conversation row
 func (r *ConversationPostgres) scanConversation(row pgx.Row) (*entity.Conversation, error) {
 	var conv entity.Conversation
@@ -219,6 +433,7 @@ func (r *ConversationPostgres) scanConversation(row pgx.Row) (*entity.Conversati
 		&lastMessageAt,
 		&conv.LastMessageIsFromMe,
 		&conv.UnreadCount,
+		&conv.LastReadAt,
 		&conv.CreatedAt,
 		&conv.UpdatedAt,
 	)
@@ -253,6 +468,7 @@ func (r *ConversationPostgres) scanConversations(rows pgx.Rows) ([]entity.Conver
 			&lastMessageAt,
 			&conv.LastMessageIsFromMe,
 			&conv.UnreadCount,
+			&conv.LastReadAt,
 			&conv.CreatedAt,
 			&conv.UpdatedAt,
 		)
@@ -0,0 +1,40 @@
+package dao
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLabelFilterNoLabels(t *testing.T) {
+	clause, args := labelFilter(nil, 4)
+	if clause != "" || args != nil {
+		t.Fatalf("labelFilter(nil) = %q, %v; want empty", clause, args)
+	}
+}
+
+func TestLabelFilterMultipleLabelsRequiresAll(t *testing.T) {
+	labels := []string{"lead", "complaint"}
+	clause, args := labelFilter(labels, 4)
+
+	// AND semantics: the number of matching labels must equal the number requested
+	if !strings.Contains(clause, "l.label = ANY($4)") {
+		t.Errorf("clause does not match labels with $4: %s", clause)
+	}
+	if !strings.Contains(clause, ") = $5") {
+		t.Errorf("clause does not compare the match count with $5: %s", clause)
+	}
+
+	want := []interface{}{labels, 2}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+}
+
+func TestUnreadCountColumnCountsIncomingAfterLastRead(t *testing.T) {
+	for _, want := range []string{"NOT um.is_from_me", "c.last_read_at IS NULL", "um.timestamp > c.last_read_at"} {
+		if !strings.Contains(unreadCountColumn, want) {
+			t.Errorf("unreadCountColumn does not contain %q: %s", want, unreadCountColumn)
+		}
+	}
+}
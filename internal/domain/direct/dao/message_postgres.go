@@ -8,17 +8,25 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/vadim/neo-metric/internal/database"
 	"github.com/vadim/neo-metric/internal/domain/direct/entity"
 )
 
 // MessagePostgres implements message repository for PostgreSQL
 type MessagePostgres struct {
-	pool *pgxpool.Pool
+	pool      *pgxpool.Pool
+	batchSize int
 }
 
 // NewMessagePostgres creates a new PostgreSQL message repository
 func NewMessagePostgres(pool *pgxpool.Pool) *MessagePostgres {
-	return &MessagePostgres{pool: pool}
+	return &MessagePostgres{pool: pool, batchSize: database.DefaultBatchSize}
+}
+
+// WithBatchSize limits how many messages UpsertBatch sends in one round-trip
+func (r *MessagePostgres) WithBatchSize(size int) *MessagePostgres {
+	r.batchSize = size
+	return r
 }
 
 // Upsert inserts or updates a message
@@ -53,12 +61,15 @@ func (r *MessagePostgres) Upsert(ctx context.Context, msg *entity.Message) error
 	return nil
 }
 
-// UpsertBatch inserts or updates multiple messages
+// UpsertBatch inserts or updates multiple messages, sending at most batchSize per round-trip
 func (r *MessagePostgres) UpsertBatch(ctx context.Context, msgs []entity.Message) error {
-	if len(msgs) == 0 {
-		return nil
-	}
+	return database.Chunks(len(msgs), r.batchSize, func(start, end int) error {
+		return r.upsertChunk(ctx, msgs[start:end])
+	})
+}
 
+// upsertChunk upserts messages in a single batch
+func (r *MessagePostgres) upsertChunk(ctx context.Context, msgs []entity.Message) error {
 	batch := &pgx.Batch{}
 	query := `
 		INSERT INTO dm_messages (
@@ -103,7 +114,7 @@ func (r *MessagePostgres) UpsertBatch(ctx context.Context, msgs []entity.Message
 func (r *MessagePostgres) GetByID(ctx context.Context, id string) (*entity.Message, error) {
 	query := `
 		SELECT id, conversation_id, sender_id, message_type, text,
-		       media_url, media_type, is_unsent, is_from_me, timestamp, created_at
+		       media_url, media_type, is_unsent, is_from_me, COALESCE(reaction, ''), timestamp, created_at
 		FROM dm_messages
 		WHERE id = $1
 	`
@@ -119,6 +130,7 @@ func (r *MessagePostgres) GetByID(ctx context.Context, id string) (*entity.Messa
 		&msg.MediaType,
 		&msg.IsUnsent,
 		&msg.IsFromMe,
+		&msg.Reaction,
 		&msg.Timestamp,
 		&msg.CreatedAt,
 	)
@@ -136,7 +148,7 @@ func (r *MessagePostgres) GetByID(ctx context.Context, id string) (*entity.Messa
 func (r *MessagePostgres) GetByConversationID(ctx context.Context, conversationID string, limit, offset int) ([]entity.Message, error) {
 	query := `
 		SELECT id, conversation_id, sender_id, message_type, text,
-		       media_url, media_type, is_unsent, is_from_me, timestamp, created_at
+		       media_url, media_type, is_unsent, is_from_me, COALESCE(reaction, ''), timestamp, created_at
 		FROM dm_messages
 		WHERE conversation_id = $1
 		ORDER BY timestamp DESC
@@ -162,6 +174,7 @@ func (r *MessagePostgres) GetByConversationID(ctx context.Context, conversationI
 			&msg.MediaType,
 			&msg.IsUnsent,
 			&msg.IsFromMe,
+			&msg.Reaction,
 			&msg.Timestamp,
 			&msg.CreatedAt,
 		)
@@ -174,6 +187,15 @@ func (r *MessagePostgres) GetByConversationID(ctx context.Context, conversationI
 	return messages, nil
 }
 
+// UpdateReaction sets our reaction on a message (empty string clears it)
+func (r *MessagePostgres) UpdateReaction(ctx context.Context, id, reaction string) error {
+	_, err := r.pool.Exec(ctx, "UPDATE dm_messages SET reaction = NULLIF($2, '') WHERE id = $1", id, reaction)
+	if err != nil {
+		return fmt.Errorf("updating message reaction: %w", err)
+	}
+	return nil
+}
+
 // Delete removes a message
 func (r *MessagePostgres) Delete(ctx context.Context, id string) error {
 	_, err := r.pool.Exec(ctx, "DELETE FROM dm_messages WHERE id = $1", id)
@@ -23,13 +23,24 @@ type ConversationSyncStatus struct {
 
 // AccountSyncStatus represents sync status for an account's conversations list
 type AccountSyncStatus struct {
-	AccountID    string
-	LastSyncedAt time.Time
-	NextCursor   string
-	SyncComplete bool
-	RetryCount   int
-	Failed       bool
-	LastError    string
+	AccountID      string
+	LastSyncedAt   time.Time
+	NextCursor     string
+	SyncComplete   bool
+	RetryCount     int
+	Failed         bool
+	LastError      string
+	LastFullSyncAt *time.Time // nil until the first complete full pass
+}
+
+// SyncSummary aggregates the message sync state of an account's conversations
+type SyncSummary struct {
+	Total          int64      // Conversations of the account
+	NeverSynced    int64      // Conversations without a sync status yet
+	Retrying       int64      // Conversations whose last sync failed but will be retried
+	Failed         int64      // Conversations excluded from sync after too many failures
+	LastSyncedAt   *time.Time // Most recent successful sync
+	OldestSyncedAt *time.Time // Least recent sync of a conversation that is not failed
 }
 
 // ConversationSyncPostgres implements conversation sync status repository
@@ -125,7 +136,8 @@ func NewAccountSyncPostgres(pool *pgxpool.Pool) *AccountSyncPostgres {
 func (r *AccountSyncPostgres) GetSyncStatus(ctx context.Context, accountID string) (*AccountSyncStatus, error) {
 	query := `
 		SELECT account_id, last_synced_at, next_cursor, sync_complete,
-		       COALESCE(retry_count, 0), COALESCE(failed, false), COALESCE(last_error, '')
+		       COALESCE(retry_count, 0), COALESCE(failed, false), COALESCE(last_error, ''),
+		       last_full_sync_at
 		FROM dm_account_sync_status
 		WHERE account_id = $1
 	`
@@ -141,6 +153,7 @@ func (r *AccountSyncPostgres) GetSyncStatus(ctx context.Context, accountID strin
 		&status.RetryCount,
 		&status.Failed,
 		&status.LastError,
+		&status.LastFullSyncAt,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -159,12 +172,13 @@ func (r *AccountSyncPostgres) GetSyncStatus(ctx context.Context, accountID strin
 // UpdateSyncStatus updates or inserts sync status for an account
 func (r *AccountSyncPostgres) UpdateSyncStatus(ctx context.Context, status *AccountSyncStatus) error {
 	query := `
-		INSERT INTO dm_account_sync_status (account_id, last_synced_at, next_cursor, sync_complete)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO dm_account_sync_status (account_id, last_synced_at, next_cursor, sync_complete, last_full_sync_at)
+		VALUES ($1, $2, $3, $4, $5)
 		ON CONFLICT (account_id) DO UPDATE SET
 			last_synced_at = EXCLUDED.last_synced_at,
 			next_cursor = EXCLUDED.next_cursor,
-			sync_complete = EXCLUDED.sync_complete
+			sync_complete = EXCLUDED.sync_complete,
+			last_full_sync_at = EXCLUDED.last_full_sync_at
 	`
 
 	var nextCursor *string
@@ -177,6 +191,7 @@ func (r *AccountSyncPostgres) UpdateSyncStatus(ctx context.Context, status *Acco
 		status.LastSyncedAt,
 		nextCursor,
 		status.SyncComplete,
+		status.LastFullSyncAt,
 	)
 	if err != nil {
 		return fmt.Errorf("updating account sync status: %w", err)
@@ -286,6 +301,63 @@ func (r *ConversationSyncPostgres) GetConversationsNeedingSync(ctx context.Conte
 	return conversationIDs, nil
 }
 
+// GetAccountSummary aggregates the message sync status of an account's conversations
+func (r *ConversationSyncPostgres) GetAccountSummary(ctx context.Context, accountID string) (*SyncSummary, error) {
+	query := `
+		SELECT COUNT(*),
+		       COUNT(*) FILTER (WHERE s.conversation_id IS NULL),
+		       COUNT(*) FILTER (WHERE NOT s.failed AND s.retry_count > 0),
+		       COUNT(*) FILTER (WHERE s.failed),
+		       MAX(s.last_synced_at) FILTER (WHERE NOT s.failed AND s.retry_count = 0),
+		       MIN(s.last_synced_at) FILTER (WHERE NOT s.failed)
+		FROM dm_conversations c
+		LEFT JOIN dm_conversation_sync_status s ON c.id = s.conversation_id
+		WHERE c.account_id = $1
+	`
+
+	var s SyncSummary
+	err := r.pool.QueryRow(ctx, query, accountID).Scan(
+		&s.Total,
+		&s.NeverSynced,
+		&s.Retrying,
+		&s.Failed,
+		&s.LastSyncedAt,
+		&s.OldestSyncedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("summarizing conversation sync status: %w", err)
+	}
+
+	return &s, nil
+}
+
+// GetFailedConversationIDs returns the account's conversations excluded from sync after too many failures
+func (r *ConversationSyncPostgres) GetFailedConversationIDs(ctx context.Context, accountID string) ([]string, error) {
+	query := `
+		SELECT c.id
+		FROM dm_conversations c
+		JOIN dm_conversation_sync_status s ON c.id = s.conversation_id
+		WHERE c.account_id = $1 AND s.failed
+	`
+
+	rows, err := r.pool.Query(ctx, query, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("getting failed conversations: %w", err)
+	}
+	defer rows.Close()
+
+	var conversationIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scanning conversation id: %w", err)
+		}
+		conversationIDs = append(conversationIDs, id)
+	}
+
+	return conversationIDs, rows.Err()
+}
+
 // IncrementRetryCount increments the retry count and marks as failed if max retries exceeded
 func (r *ConversationSyncPostgres) IncrementRetryCount(ctx context.Context, conversationID string, lastError string, maxRetries int) error {
 	query := `
@@ -14,7 +14,9 @@ type Conversation struct {
 	LastMessageText           string     `json:"last_message_text,omitempty"`
 	LastMessageAt             *time.Time `json:"last_message_at,omitempty"`
 	LastMessageIsFromMe       bool       `json:"last_message_is_from_me,omitempty"`
-	UnreadCount               int        `json:"unread_count"`
+	UnreadCount               int        `json:"unread_count"` // Incoming messages after LastReadAt, computed on read
+	LastReadAt                *time.Time `json:"last_read_at,omitempty"`
+	Labels                    []string   `json:"labels"`
 	CreatedAt                 time.Time  `json:"created_at"`
 	UpdatedAt                 time.Time  `json:"updated_at"`
 }
@@ -26,4 +28,5 @@ type Participant struct {
 	Name           string `json:"name,omitempty"`
 	AvatarURL      string `json:"avatar_url,omitempty"`
 	FollowersCount int    `json:"followers_count,omitempty"`
+	Restricted     bool   `json:"restricted,omitempty"` // Instagram withheld the profile; fields are the last cached values
 }
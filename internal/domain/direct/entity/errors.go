@@ -9,10 +9,19 @@ var (
 	ErrEmptyMessage         = errors.New("message text cannot be empty")
 	ErrMessageTooLong       = errors.New("message exceeds maximum length")
 	ErrUnauthorized         = errors.New("unauthorized to perform this action")
+	ErrAccountForbidden     = errors.New("not allowed to access this account")
 	ErrMessagingDisabled    = errors.New("messaging is disabled for this user")
 	ErrUserNotFound         = errors.New("user not found")
+	ErrProfileUnavailable   = errors.New("participant profile is not available")
 	ErrInvalidRecipient     = errors.New("invalid recipient")
 	ErrMediaRequired        = errors.New("media is required for this message type")
 	ErrInvalidMediaType     = errors.New("invalid media type")
 	ErrRateLimited          = errors.New("rate limit exceeded")
+	ErrSyncInProgress       = errors.New("sync is already running")
+	ErrInvalidReaction      = errors.New("invalid reaction")
+	ErrTemplateNotFound     = errors.New("template not found")
+	ErrTemplateNotDirect    = errors.New("template cannot be used in direct messages")
+	ErrNoLabels             = errors.New("at least one label is required")
+	ErrInvalidLabel         = errors.New("label must be 1-64 characters")
+	ErrTooManyLabels        = errors.New("too many labels in a single request")
 )
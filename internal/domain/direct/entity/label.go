@@ -0,0 +1,32 @@
+package entity
+
+import "strings"
+
+// MaxLabelLength is the maximum length of a conversation label
+const MaxLabelLength = 64
+
+// MaxLabelsPerRequest is the maximum number of labels accepted in a single request
+const MaxLabelsPerRequest = 20
+
+// NormalizeLabels trims, lowercases and de-duplicates labels, preserving order.
+// An empty input yields an empty result; an empty or overlong label is rejected.
+func NormalizeLabels(labels []string) ([]string, error) {
+	if len(labels) > MaxLabelsPerRequest {
+		return nil, ErrTooManyLabels
+	}
+
+	seen := make(map[string]struct{}, len(labels))
+	out := make([]string, 0, len(labels))
+	for _, l := range labels {
+		l = strings.ToLower(strings.TrimSpace(l))
+		if l == "" || len(l) > MaxLabelLength {
+			return nil, ErrInvalidLabel
+		}
+		if _, dup := seen[l]; dup {
+			continue
+		}
+		seen[l] = struct{}{}
+		out = append(out, l)
+	}
+	return out, nil
+}
@@ -28,6 +28,7 @@ type Message struct {
 	MediaType      string      `json:"media_type,omitempty"` // image/video/audio for media messages
 	IsUnsent       bool        `json:"is_unsent"`
 	IsFromMe       bool        `json:"is_from_me"`
+	Reaction       string      `json:"reaction,omitempty"` // Our reaction to the message (empty if none)
 	Timestamp      time.Time   `json:"timestamp"`
 	CreatedAt      time.Time   `json:"created_at"`
 }
@@ -35,6 +36,32 @@ type Message struct {
 // MaxMessageLength is the maximum length of a DM text message
 const MaxMessageLength = 1000
 
+// MaxReactionLength is the maximum length of a reaction value in bytes
+const MaxReactionLength = 32
+
+// ValidateReaction validates a reaction value (empty means remove the reaction)
+func ValidateReaction(reaction string) error {
+	if len(reaction) > MaxReactionLength {
+		return ErrInvalidReaction
+	}
+	return nil
+}
+
+// ValidateMediaCaption validates the optional text sent along with a media attachment.
+// Only image and video attachments can carry text.
+func ValidateMediaCaption(mediaType, caption string) error {
+	if caption == "" {
+		return nil
+	}
+	if mediaType != "image" && mediaType != "video" {
+		return ErrInvalidMediaType
+	}
+	if len(caption) > MaxMessageLength {
+		return ErrMessageTooLong
+	}
+	return nil
+}
+
 // ValidateMessageText validates the text for a message
 func ValidateMessageText(text string) error {
 	if text == "" {
@@ -7,14 +7,32 @@ import (
 
 	"github.com/vadim/neo-metric/internal/domain/direct/entity"
 	"github.com/vadim/neo-metric/internal/domain/direct/service"
+	"github.com/vadim/neo-metric/internal/logctx"
 )
 
 // AccountProvider provides account information for authentication
 type AccountProvider interface {
-	GetAccessToken(ctx context.Context, accountID string) (string, error)
-	GetInstagramUserID(ctx context.Context, accountID string) (string, error)
+	GetAccountCredentials(ctx context.Context, accountID string) (token, userID string, err error)
 }
 
+// AccountAuthorizer reports whether the caller may act on an account
+type AccountAuthorizer interface {
+	AccountAllowed(ctx context.Context, accountID string) bool
+}
+
+// AuditRecorder records mutating actions for the audit log.
+// Recording is best-effort: implementations handle their own failures.
+type AuditRecorder interface {
+	Record(ctx context.Context, action, accountID, targetID string, err error)
+}
+
+// Audited actions
+const (
+	auditActionSend               = "direct.message.send"
+	auditActionDeleteConversation = "direct.conversation.delete"
+	auditActionDeleteParticipant  = "direct.participant.delete"
+)
+
 // DirectService defines the interface for the direct service
 type DirectService interface {
 	GetConversations(ctx context.Context, in service.GetConversationsInput) (*service.GetConversationsOutput, error)
@@ -22,16 +40,46 @@ type DirectService interface {
 	GetMessages(ctx context.Context, in service.GetMessagesInput) (*service.GetMessagesOutput, error)
 	SendMessage(ctx context.Context, in service.SendMessageInput) (*service.SendMessageOutput, error)
 	SendMediaMessage(ctx context.Context, in service.SendMediaMessageInput) (*service.SendMessageOutput, error)
-	SyncConversations(ctx context.Context, accountID, userID, accessToken string) error
-	SyncMessages(ctx context.Context, conversationID, userID, accessToken string) error
+	SendReaction(ctx context.Context, in service.SendReactionInput) error
+	AddLabels(ctx context.Context, accountID, conversationID string, labels []string) ([]string, error)
+	RemoveLabels(ctx context.Context, accountID, conversationID string, labels []string) ([]string, error)
+	DeleteConversation(ctx context.Context, accountID, conversationID string) error
+	MarkConversationRead(ctx context.Context, accountID, conversationID string) (*entity.Conversation, error)
+	ExportParticipant(ctx context.Context, accountID, participantID string, sink service.ExportSink) error
+	DeleteParticipant(ctx context.Context, accountID, participantID string) (int, error)
+	GetParticipant(ctx context.Context, in service.GetParticipantInput) (*entity.Participant, error)
+	SyncConversations(ctx context.Context, accountID, userID, accessToken string) (int, error)
+	SyncMessages(ctx context.Context, accountID, conversationID, userID, accessToken string) (int, error)
+	GetAccountSyncStatus(ctx context.Context, accountID string) (*service.AccountSyncStatus, error)
+	GetConversationSyncStatus(ctx context.Context, conversationID string) (*service.ConversationSyncStatus, error)
+	ResetFailedSyncs(ctx context.Context, accountID string, conversationIDs []string) (*service.ResetSyncsOutput, error)
 	GetStatistics(ctx context.Context, in service.GetStatisticsInput) (*entity.Statistics, error)
 	GetHeatmap(ctx context.Context, in service.GetHeatmapInput) (*entity.Heatmap, error)
 }
 
+// QuickReply is a canned DM response derived from a template
+type QuickReply struct {
+	TemplateID string   `json:"template_id"`
+	Title      string   `json:"title"`
+	Content    string   `json:"content"`
+	Icon       string   `json:"icon,omitempty"`
+	Images     []string `json:"images,omitempty"`
+}
+
+// TemplateRenderer resolves message templates usable in direct messages
+type TemplateRenderer interface {
+	ListQuickReplies(ctx context.Context, accountID string) ([]QuickReply, error)
+	Render(ctx context.Context, accountID, templateID string) (string, error)
+	MarkUsed(ctx context.Context, accountID, templateID string) error
+}
+
 // Policy handles direct message operations with account authorization
 type Policy struct {
-	svc      DirectService
-	accounts AccountProvider
+	svc       DirectService
+	accounts  AccountProvider
+	authz     AccountAuthorizer // optional, all accounts allowed if unset
+	templates TemplateRenderer  // optional, for quick replies and template_id
+	audit     AuditRecorder     // optional
 }
 
 // New creates a new direct policy
@@ -42,9 +90,51 @@ func New(svc DirectService, accounts AccountProvider) *Policy {
 	}
 }
 
+// WithTemplateRenderer sets the TemplateRenderer for quick replies and templated messages
+func (p *Policy) WithTemplateRenderer(r TemplateRenderer) *Policy {
+	p.templates = r
+	return p
+}
+
+// WithAuditRecorder records sent messages and deletions in the audit log
+func (p *Policy) WithAuditRecorder(r AuditRecorder) *Policy {
+	p.audit = r
+	return p
+}
+
+// WithAccountAuthorizer restricts every operation to the accounts the caller may act on
+func (p *Policy) WithAccountAuthorizer(a AccountAuthorizer) *Policy {
+	p.authz = a
+	return p
+}
+
+// authorize returns ErrAccountForbidden if the caller may not act on the account
+func (p *Policy) authorize(ctx context.Context, accountID string) error {
+	if p.authz != nil && !p.authz.AccountAllowed(ctx, accountID) {
+		return entity.ErrAccountForbidden
+	}
+	return nil
+}
+
+// record writes an audit entry if an AuditRecorder is set
+func (p *Policy) record(ctx context.Context, action, accountID, targetID string, err error) {
+	if p.audit != nil {
+		p.audit.Record(ctx, action, accountID, targetID, err)
+	}
+}
+
+// messageTarget returns the audited target of a message: the conversation if known, otherwise the recipient
+func messageTarget(conversationID, recipientID string) string {
+	if conversationID != "" {
+		return conversationID
+	}
+	return recipientID
+}
+
 // GetConversationsInput represents input for getting conversations
 type GetConversationsInput struct {
 	AccountID string
+	Labels    []string // Only conversations carrying all of these labels
 	Limit     int
 	Offset    int
 }
@@ -58,20 +148,19 @@ type GetConversationsOutput struct {
 
 // GetConversations retrieves conversations for an account
 func (p *Policy) GetConversations(ctx context.Context, in GetConversationsInput) (*GetConversationsOutput, error) {
-	accessToken, err := p.accounts.GetAccessToken(ctx, in.AccountID)
-	if err != nil {
-		return nil, fmt.Errorf("getting access token: %w", err)
+	if err := p.authorize(ctx, in.AccountID); err != nil {
+		return nil, err
 	}
-
-	userID, err := p.accounts.GetInstagramUserID(ctx, in.AccountID)
+	accessToken, userID, err := p.accounts.GetAccountCredentials(ctx, in.AccountID)
 	if err != nil {
-		return nil, fmt.Errorf("getting user ID: %w", err)
+		return nil, fmt.Errorf("getting account credentials: %w", err)
 	}
 
 	result, err := p.svc.GetConversations(ctx, service.GetConversationsInput{
 		AccountID:   in.AccountID,
 		UserID:      userID,
 		AccessToken: accessToken,
+		Labels:      in.Labels,
 		Limit:       in.Limit,
 		Offset:      in.Offset,
 	})
@@ -86,6 +175,104 @@ func (p *Policy) GetConversations(ctx context.Context, in GetConversationsInput)
 	}, nil
 }
 
+// LabelsInput represents input for adding or removing conversation labels
+type LabelsInput struct {
+	AccountID      string
+	ConversationID string
+	Labels         []string
+}
+
+// AddConversationLabels tags a conversation and returns its current labels
+func (p *Policy) AddConversationLabels(ctx context.Context, in LabelsInput) ([]string, error) {
+	if err := p.authorize(ctx, in.AccountID); err != nil {
+		return nil, err
+	}
+	return p.svc.AddLabels(ctx, in.AccountID, in.ConversationID, in.Labels)
+}
+
+// RemoveConversationLabels untags a conversation and returns its current labels
+func (p *Policy) RemoveConversationLabels(ctx context.Context, in LabelsInput) ([]string, error) {
+	if err := p.authorize(ctx, in.AccountID); err != nil {
+		return nil, err
+	}
+	return p.svc.RemoveLabels(ctx, in.AccountID, in.ConversationID, in.Labels)
+}
+
+// MarkConversationReadInput represents input for marking a conversation as read
+type MarkConversationReadInput struct {
+	AccountID      string
+	ConversationID string
+}
+
+// MarkConversationRead marks the conversation's messages as read up to now
+func (p *Policy) MarkConversationRead(ctx context.Context, in MarkConversationReadInput) (*entity.Conversation, error) {
+	if err := p.authorize(ctx, in.AccountID); err != nil {
+		return nil, err
+	}
+	return p.svc.MarkConversationRead(ctx, in.AccountID, in.ConversationID)
+}
+
+// DeleteConversationInput represents input for deleting a conversation
+type DeleteConversationInput struct {
+	AccountID      string
+	ConversationID string
+}
+
+// DeleteConversation removes a conversation together with its messages
+func (p *Policy) DeleteConversation(ctx context.Context, in DeleteConversationInput) error {
+	err := p.authorize(ctx, in.AccountID)
+	if err == nil {
+		err = p.svc.DeleteConversation(ctx, in.AccountID, in.ConversationID)
+	}
+	p.record(ctx, auditActionDeleteConversation, in.AccountID, in.ConversationID, err)
+	return err
+}
+
+// ExportSink receives a participant export as it is read
+type ExportSink = service.ExportSink
+
+// ParticipantInput identifies a participant's conversations with an account
+type ParticipantInput struct {
+	AccountID     string
+	ParticipantID string
+}
+
+// ExportParticipant streams all conversations and messages with a participant to sink
+func (p *Policy) ExportParticipant(ctx context.Context, in ParticipantInput, sink ExportSink) error {
+	if err := p.authorize(ctx, in.AccountID); err != nil {
+		return err
+	}
+	return p.svc.ExportParticipant(ctx, in.AccountID, in.ParticipantID, sink)
+}
+
+// DeleteParticipant removes all conversations with a participant and returns how many were deleted
+func (p *Policy) DeleteParticipant(ctx context.Context, in ParticipantInput) (int, error) {
+	var deleted int
+	err := p.authorize(ctx, in.AccountID)
+	if err == nil {
+		deleted, err = p.svc.DeleteParticipant(ctx, in.AccountID, in.ParticipantID)
+	}
+	p.record(ctx, auditActionDeleteParticipant, in.AccountID, in.ParticipantID, err)
+	return deleted, err
+}
+
+// GetParticipant fetches a participant's profile on behalf of the account
+func (p *Policy) GetParticipant(ctx context.Context, in ParticipantInput) (*entity.Participant, error) {
+	if err := p.authorize(ctx, in.AccountID); err != nil {
+		return nil, err
+	}
+	accessToken, _, err := p.accounts.GetAccountCredentials(ctx, in.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("getting account credentials: %w", err)
+	}
+
+	return p.svc.GetParticipant(ctx, service.GetParticipantInput{
+		AccountID:     in.AccountID,
+		ParticipantID: in.ParticipantID,
+		AccessToken:   accessToken,
+	})
+}
+
 // SearchConversationsInput represents input for searching conversations
 type SearchConversationsInput struct {
 	AccountID string
@@ -96,6 +283,9 @@ type SearchConversationsInput struct {
 
 // SearchConversations searches conversations by participant username/name
 func (p *Policy) SearchConversations(ctx context.Context, in SearchConversationsInput) (*GetConversationsOutput, error) {
+	if err := p.authorize(ctx, in.AccountID); err != nil {
+		return nil, err
+	}
 	result, err := p.svc.SearchConversations(ctx, service.SearchConversationsInput{
 		AccountID: in.AccountID,
 		Query:     in.Query,
@@ -130,14 +320,12 @@ type GetMessagesOutput struct {
 
 // GetMessages retrieves messages for a conversation
 func (p *Policy) GetMessages(ctx context.Context, in GetMessagesInput) (*GetMessagesOutput, error) {
-	accessToken, err := p.accounts.GetAccessToken(ctx, in.AccountID)
-	if err != nil {
-		return nil, fmt.Errorf("getting access token: %w", err)
+	if err := p.authorize(ctx, in.AccountID); err != nil {
+		return nil, err
 	}
-
-	userID, err := p.accounts.GetInstagramUserID(ctx, in.AccountID)
+	accessToken, userID, err := p.accounts.GetAccountCredentials(ctx, in.AccountID)
 	if err != nil {
-		return nil, fmt.Errorf("getting user ID: %w", err)
+		return nil, fmt.Errorf("getting account credentials: %w", err)
 	}
 
 	result, err := p.svc.GetMessages(ctx, service.GetMessagesInput{
@@ -165,6 +353,7 @@ type SendMessageInput struct {
 	ConversationID string
 	RecipientID    string
 	Message        string
+	TemplateID     string // If set, the rendered template is sent instead of Message
 }
 
 // SendMessageOutput represents output from sending a message
@@ -174,14 +363,30 @@ type SendMessageOutput struct {
 
 // SendMessage sends a text message
 func (p *Policy) SendMessage(ctx context.Context, in SendMessageInput) (*SendMessageOutput, error) {
-	accessToken, err := p.accounts.GetAccessToken(ctx, in.AccountID)
-	if err != nil {
-		return nil, fmt.Errorf("getting access token: %w", err)
+	out, err := p.sendMessage(ctx, in)
+	p.record(ctx, auditActionSend, in.AccountID, messageTarget(in.ConversationID, in.RecipientID), err)
+	return out, err
+}
+
+// sendMessage renders the template if set and sends the message
+func (p *Policy) sendMessage(ctx context.Context, in SendMessageInput) (*SendMessageOutput, error) {
+	if err := p.authorize(ctx, in.AccountID); err != nil {
+		return nil, err
+	}
+	if in.TemplateID != "" {
+		if p.templates == nil {
+			return nil, entity.ErrTemplateNotFound
+		}
+		text, err := p.templates.Render(ctx, in.AccountID, in.TemplateID)
+		if err != nil {
+			return nil, err
+		}
+		in.Message = text
 	}
 
-	userID, err := p.accounts.GetInstagramUserID(ctx, in.AccountID)
+	accessToken, userID, err := p.accounts.GetAccountCredentials(ctx, in.AccountID)
 	if err != nil {
-		return nil, fmt.Errorf("getting user ID: %w", err)
+		return nil, fmt.Errorf("getting account credentials: %w", err)
 	}
 
 	result, err := p.svc.SendMessage(ctx, service.SendMessageInput{
@@ -196,9 +401,25 @@ func (p *Policy) SendMessage(ctx context.Context, in SendMessageInput) (*SendMes
 		return nil, err
 	}
 
+	// Best-effort: count the template as used
+	if in.TemplateID != "" {
+		_ = p.templates.MarkUsed(ctx, in.AccountID, in.TemplateID)
+	}
+
 	return &SendMessageOutput{MessageID: result.MessageID}, nil
 }
 
+// GetQuickReplies returns the account's DM templates as quick-reply options
+func (p *Policy) GetQuickReplies(ctx context.Context, accountID string) ([]QuickReply, error) {
+	if err := p.authorize(ctx, accountID); err != nil {
+		return nil, err
+	}
+	if p.templates == nil {
+		return []QuickReply{}, nil
+	}
+	return p.templates.ListQuickReplies(ctx, accountID)
+}
+
 // SendMediaMessageInput represents input for sending a media message
 type SendMediaMessageInput struct {
 	AccountID      string
@@ -206,18 +427,24 @@ type SendMediaMessageInput struct {
 	RecipientID    string
 	MediaURL       string
 	MediaType      string
+	Caption        string // Optional text sent in the same message
 }
 
 // SendMediaMessage sends a media message
 func (p *Policy) SendMediaMessage(ctx context.Context, in SendMediaMessageInput) (*SendMessageOutput, error) {
-	accessToken, err := p.accounts.GetAccessToken(ctx, in.AccountID)
-	if err != nil {
-		return nil, fmt.Errorf("getting access token: %w", err)
-	}
+	out, err := p.sendMediaMessage(ctx, in)
+	p.record(ctx, auditActionSend, in.AccountID, messageTarget(in.ConversationID, in.RecipientID), err)
+	return out, err
+}
 
-	userID, err := p.accounts.GetInstagramUserID(ctx, in.AccountID)
+// sendMediaMessage sends a media message on behalf of the account
+func (p *Policy) sendMediaMessage(ctx context.Context, in SendMediaMessageInput) (*SendMessageOutput, error) {
+	if err := p.authorize(ctx, in.AccountID); err != nil {
+		return nil, err
+	}
+	accessToken, userID, err := p.accounts.GetAccountCredentials(ctx, in.AccountID)
 	if err != nil {
-		return nil, fmt.Errorf("getting user ID: %w", err)
+		return nil, fmt.Errorf("getting account credentials: %w", err)
 	}
 
 	result, err := p.svc.SendMediaMessage(ctx, service.SendMediaMessageInput{
@@ -228,6 +455,7 @@ func (p *Policy) SendMediaMessage(ctx context.Context, in SendMediaMessageInput)
 		AccessToken:    accessToken,
 		MediaURL:       in.MediaURL,
 		MediaType:      in.MediaType,
+		Caption:        in.Caption,
 	})
 	if err != nil {
 		return nil, err
@@ -245,6 +473,9 @@ type GetStatisticsInput struct {
 
 // GetStatistics returns DM statistics for an account
 func (p *Policy) GetStatistics(ctx context.Context, in GetStatisticsInput) (*entity.Statistics, error) {
+	if err := p.authorize(ctx, in.AccountID); err != nil {
+		return nil, err
+	}
 	return p.svc.GetStatistics(ctx, service.GetStatisticsInput{
 		AccountID: in.AccountID,
 		StartDate: in.StartDate,
@@ -261,6 +492,9 @@ type GetHeatmapInput struct {
 
 // GetHeatmap returns activity heatmap for an account
 func (p *Policy) GetHeatmap(ctx context.Context, in GetHeatmapInput) (*entity.Heatmap, error) {
+	if err := p.authorize(ctx, in.AccountID); err != nil {
+		return nil, err
+	}
 	return p.svc.GetHeatmap(ctx, service.GetHeatmapInput{
 		AccountID: in.AccountID,
 		StartDate: in.StartDate,
@@ -268,24 +502,91 @@ func (p *Policy) GetHeatmap(ctx context.Context, in GetHeatmapInput) (*entity.He
 	})
 }
 
+// SendReactionInput represents input for reacting to a message
+type SendReactionInput struct {
+	AccountID      string
+	ConversationID string
+	MessageID      string
+	RecipientID    string
+	Reaction       string // Empty removes the reaction
+}
+
+// SendReaction reacts to a message, or removes the reaction if Reaction is empty
+func (p *Policy) SendReaction(ctx context.Context, in SendReactionInput) error {
+	if err := p.authorize(ctx, in.AccountID); err != nil {
+		return err
+	}
+	accessToken, userID, err := p.accounts.GetAccountCredentials(ctx, in.AccountID)
+	if err != nil {
+		return fmt.Errorf("getting account credentials: %w", err)
+	}
+
+	return p.svc.SendReaction(ctx, service.SendReactionInput{
+		ConversationID: in.ConversationID,
+		MessageID:      in.MessageID,
+		UserID:         userID,
+		RecipientID:    in.RecipientID,
+		AccessToken:    accessToken,
+		Reaction:       in.Reaction,
+	})
+}
+
 // SyncConversationsInput represents input for syncing conversations
 type SyncConversationsInput struct {
 	AccountID string
 }
 
+// SyncConversationsOutput represents the result of a manual conversation sync
+type SyncConversationsOutput struct {
+	AccountID    string     `json:"account_id"`
+	Synced       int        `json:"synced"`
+	LastSyncedAt *time.Time `json:"last_synced_at,omitempty"`
+	SyncComplete bool       `json:"sync_complete"`
+}
+
 // SyncConversations manually triggers conversation sync for an account
-func (p *Policy) SyncConversations(ctx context.Context, in SyncConversationsInput) error {
-	accessToken, err := p.accounts.GetAccessToken(ctx, in.AccountID)
+func (p *Policy) SyncConversations(ctx context.Context, in SyncConversationsInput) (*SyncConversationsOutput, error) {
+	if err := p.authorize(ctx, in.AccountID); err != nil {
+		return nil, err
+	}
+	ctx = logctx.With(ctx, "account_id", in.AccountID)
+	accessToken, userID, err := p.accounts.GetAccountCredentials(ctx, in.AccountID)
 	if err != nil {
-		return fmt.Errorf("getting access token: %w", err)
+		return nil, fmt.Errorf("getting account credentials: %w", err)
 	}
 
-	userID, err := p.accounts.GetInstagramUserID(ctx, in.AccountID)
+	synced, err := p.svc.SyncConversations(ctx, in.AccountID, userID, accessToken)
 	if err != nil {
-		return fmt.Errorf("getting user ID: %w", err)
+		return nil, err
 	}
 
-	return p.svc.SyncConversations(ctx, in.AccountID, userID, accessToken)
+	out := &SyncConversationsOutput{AccountID: in.AccountID, Synced: synced}
+
+	status, err := p.svc.GetAccountSyncStatus(ctx, in.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("getting sync status: %w", err)
+	}
+	if status != nil {
+		out.LastSyncedAt = &status.LastSyncedAt
+		out.SyncComplete = status.SyncComplete
+	}
+
+	return out, nil
+}
+
+// ResetFailedSyncsInput represents input for re-enabling failed direct message syncs
+type ResetFailedSyncsInput struct {
+	AccountID       string
+	ConversationIDs []string // Optional, limits the reset to these conversations
+}
+
+// ResetFailedSyncs puts the account's conversations, and its conversation list, excluded from
+// sync after repeated failures back into the sync rotation
+func (p *Policy) ResetFailedSyncs(ctx context.Context, in ResetFailedSyncsInput) (*service.ResetSyncsOutput, error) {
+	if err := p.authorize(ctx, in.AccountID); err != nil {
+		return nil, err
+	}
+	return p.svc.ResetFailedSyncs(ctx, in.AccountID, in.ConversationIDs)
 }
 
 // SyncMessagesInput represents input for syncing messages
@@ -294,17 +595,42 @@ type SyncMessagesInput struct {
 	ConversationID string
 }
 
+// SyncMessagesOutput represents the result of a manual message sync
+type SyncMessagesOutput struct {
+	ConversationID         string     `json:"conversation_id"`
+	Synced                 int        `json:"synced"`
+	LastSyncedAt           *time.Time `json:"last_synced_at,omitempty"`
+	SyncComplete           bool       `json:"sync_complete"`
+	OldestMessageTimestamp *time.Time `json:"oldest_message_timestamp,omitempty"`
+}
+
 // SyncMessages manually triggers message sync for a specific conversation
-func (p *Policy) SyncMessages(ctx context.Context, in SyncMessagesInput) error {
-	accessToken, err := p.accounts.GetAccessToken(ctx, in.AccountID)
+func (p *Policy) SyncMessages(ctx context.Context, in SyncMessagesInput) (*SyncMessagesOutput, error) {
+	if err := p.authorize(ctx, in.AccountID); err != nil {
+		return nil, err
+	}
+	ctx = logctx.With(ctx, "account_id", in.AccountID)
+	accessToken, userID, err := p.accounts.GetAccountCredentials(ctx, in.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("getting account credentials: %w", err)
+	}
+
+	synced, err := p.svc.SyncMessages(ctx, in.AccountID, in.ConversationID, userID, accessToken)
 	if err != nil {
-		return fmt.Errorf("getting access token: %w", err)
+		return nil, err
 	}
 
-	userID, err := p.accounts.GetInstagramUserID(ctx, in.AccountID)
+	out := &SyncMessagesOutput{ConversationID: in.ConversationID, Synced: synced}
+
+	status, err := p.svc.GetConversationSyncStatus(ctx, in.ConversationID)
 	if err != nil {
-		return fmt.Errorf("getting user ID: %w", err)
+		return nil, fmt.Errorf("getting sync status: %w", err)
+	}
+	if status != nil {
+		out.LastSyncedAt = &status.LastSyncedAt
+		out.SyncComplete = status.SyncComplete
+		out.OldestMessageTimestamp = status.OldestMessageTimestamp
 	}
 
-	return p.svc.SyncMessages(ctx, in.ConversationID, userID, accessToken)
+	return out, nil
 }
@@ -0,0 +1,77 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vadim/neo-metric/internal/domain/direct/entity"
+	"github.com/vadim/neo-metric/internal/domain/direct/service"
+)
+
+// fakeService records sent messages
+type fakeService struct {
+	DirectService
+	sent []string
+}
+
+func (f *fakeService) SendMessage(_ context.Context, in service.SendMessageInput) (*service.SendMessageOutput, error) {
+	f.sent = append(f.sent, in.AccountID)
+	return &service.SendMessageOutput{MessageID: "m1"}, nil
+}
+
+type fakeAccounts struct{}
+
+func (fakeAccounts) GetAccountCredentials(context.Context, string) (string, string, error) {
+	return "token", "ig_1", nil
+}
+
+// scopedAuthorizer allows only the listed accounts
+type scopedAuthorizer map[string]bool
+
+func (s scopedAuthorizer) AccountAllowed(_ context.Context, accountID string) bool {
+	return s[accountID]
+}
+
+// auditLog records the error of each audited action
+type auditLog struct {
+	errs []error
+}
+
+func (a *auditLog) Record(_ context.Context, _, _, _ string, err error) {
+	a.errs = append(a.errs, err)
+}
+
+func TestSendMessageChecksAccountScope(t *testing.T) {
+	svc := &fakeService{}
+	audit := &auditLog{}
+	p := New(svc, fakeAccounts{}).
+		WithAccountAuthorizer(scopedAuthorizer{"acc": true}).
+		WithAuditRecorder(audit)
+
+	if _, err := p.SendMessage(context.Background(), SendMessageInput{AccountID: "acc", RecipientID: "r1", Message: "hi"}); err != nil {
+		t.Fatalf("allowed account: %v", err)
+	}
+	if _, err := p.SendMessage(context.Background(), SendMessageInput{AccountID: "other", RecipientID: "r1", Message: "hi"}); err != entity.ErrAccountForbidden {
+		t.Fatalf("other account: err = %v, want ErrAccountForbidden", err)
+	}
+
+	if len(svc.sent) != 1 || svc.sent[0] != "acc" {
+		t.Errorf("sent for %v, want [acc]", svc.sent)
+	}
+	// The denied attempt is still audited
+	if len(audit.errs) != 2 || audit.errs[1] != entity.ErrAccountForbidden {
+		t.Errorf("audited errors = %v, want [<nil> ErrAccountForbidden]", audit.errs)
+	}
+}
+
+func TestNoAuthorizerAllowsAllAccounts(t *testing.T) {
+	svc := &fakeService{}
+	p := New(svc, fakeAccounts{})
+
+	if _, err := p.SendMessage(context.Background(), SendMessageInput{AccountID: "any", RecipientID: "r1", Message: "hi"}); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	if len(svc.sent) != 1 {
+		t.Errorf("sent %d messages, want 1", len(svc.sent))
+	}
+}
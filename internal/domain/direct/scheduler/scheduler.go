@@ -2,14 +2,20 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"sync"
 	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/direct/entity"
+	"github.com/vadim/neo-metric/internal/jitter"
+	"github.com/vadim/neo-metric/internal/liveness"
+	"github.com/vadim/neo-metric/internal/logctx"
 )
 
 // DirectSyncer defines the interface for syncing conversations
 type DirectSyncer interface {
-	SyncConversations(ctx context.Context, accountID, userID, accessToken string) error
+	SyncConversations(ctx context.Context, accountID, userID, accessToken string) (int, error)
 	GetAccountsNeedingSync(ctx context.Context, olderThan time.Duration, limit int) ([]string, error)
 	IncrementAccountSyncRetryCount(ctx context.Context, accountID string, lastError string, maxRetries int) error
 	ResetAccountSyncRetryCount(ctx context.Context, accountID string) error
@@ -17,8 +23,12 @@ type DirectSyncer interface {
 
 // AccountProvider provides access token and user ID for an account
 type AccountProvider interface {
-	GetAccessToken(ctx context.Context, accountID string) (string, error)
-	GetInstagramUserID(ctx context.Context, accountID string) (string, error)
+	GetAccountCredentials(ctx context.Context, accountID string) (token, userID string, err error)
+}
+
+// Throttle reports whether Instagram API usage is close enough to the rate limit to pause syncs
+type Throttle interface {
+	Throttled(now time.Time) bool
 }
 
 // Scheduler handles periodic synchronization of conversations
@@ -26,9 +36,14 @@ type Scheduler struct {
 	syncer          DirectSyncer
 	accountProvider AccountProvider
 	interval        time.Duration
-	syncAge         time.Duration // How old sync status can be before refreshing
-	batchSize       int           // How many accounts to sync per run
-	maxRetries      int           // Max retries before marking sync as permanently failed
+	syncAge         time.Duration    // How old sync status can be before refreshing
+	batchSize       int              // How many accounts to sync per run
+	maxRetries      int              // Max retries before marking sync as permanently failed
+	jitter          float64          // Fraction of interval to randomize each tick by (e.g. 0.1 = ±10%)
+	startupJitter   time.Duration    // Max random delay added to the initial startup delay
+	stopTimeout     time.Duration    // How long Stop waits for an in-flight batch before cancelling it
+	runs            liveness.Tracker // Run times reported by Status
+	throttle        Throttle         // Pauses syncs while Instagram API usage is high; optional
 	logger          *slog.Logger
 	stopCh          chan struct{}
 	cancel          context.CancelFunc // Cancel function to stop in-flight operations
@@ -39,10 +54,13 @@ type Scheduler struct {
 
 // Config holds configuration for direct sync scheduler
 type Config struct {
-	Interval   time.Duration
-	SyncAge    time.Duration
-	BatchSize  int
-	MaxRetries int
+	Interval      time.Duration
+	SyncAge       time.Duration
+	BatchSize     int
+	MaxRetries    int
+	Jitter        float64       // Fraction of interval to randomize each tick by
+	StartupJitter time.Duration // Max random delay added before the first run
+	StopTimeout   time.Duration // How long Stop waits for an in-flight batch before cancelling it
 }
 
 // New creates a new direct sync scheduler
@@ -64,6 +82,9 @@ func New(
 	if cfg.MaxRetries == 0 {
 		cfg.MaxRetries = 5
 	}
+	if cfg.StopTimeout == 0 {
+		cfg.StopTimeout = 30 * time.Second
+	}
 
 	return &Scheduler{
 		syncer:          syncer,
@@ -72,11 +93,25 @@ func New(
 		syncAge:         cfg.SyncAge,
 		batchSize:       cfg.BatchSize,
 		maxRetries:      cfg.MaxRetries,
+		jitter:          cfg.Jitter,
+		startupJitter:   cfg.StartupJitter,
+		stopTimeout:     cfg.StopTimeout,
 		logger:          logger,
 		stopCh:          make(chan struct{}),
 	}
 }
 
+// WithThrottle pauses syncs while t reports that Instagram API usage is high
+func (s *Scheduler) WithThrottle(t Throttle) *Scheduler {
+	s.throttle = t
+	return s
+}
+
+// throttled reports whether syncs should pause now
+func (s *Scheduler) throttled() bool {
+	return s.throttle != nil && s.throttle.Throttled(time.Now())
+}
+
 // Start starts the scheduler
 func (s *Scheduler) Start(ctx context.Context) {
 	s.mu.Lock()
@@ -85,6 +120,7 @@ func (s *Scheduler) Start(ctx context.Context) {
 		return
 	}
 	s.running = true
+	s.runs.Started(time.Now())
 
 	// Create a cancellable context for in-flight operations
 	ctx, s.cancel = context.WithCancel(ctx)
@@ -96,7 +132,8 @@ func (s *Scheduler) Start(ctx context.Context) {
 	go s.run(ctx)
 }
 
-// Stop stops the scheduler
+// Stop stops the scheduler and waits for the in-flight iteration to drain.
+// If it doesn't finish within the stop timeout, its context is cancelled.
 func (s *Scheduler) Stop() {
 	s.mu.Lock()
 	if !s.running {
@@ -104,29 +141,43 @@ func (s *Scheduler) Stop() {
 		return
 	}
 	s.running = false
+	s.runs.Stopped()
 	cancel := s.cancel
 	s.mu.Unlock()
 
-	// Cancel in-flight operations (HTTP requests, etc.)
-	if cancel != nil {
+	close(s.stopCh)
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(s.stopTimeout):
+		s.logger.Warn("direct sync scheduler did not drain in time, cancelling in-flight work", "timeout", s.stopTimeout)
+		// Cancel in-flight operations (HTTP requests, etc.) and wait for them to return
 		cancel()
+		<-done
 	}
 
-	close(s.stopCh)
-	s.wg.Wait()
+	cancel()
 	s.logger.Info("direct sync scheduler stopped")
 }
 
+// Status reports the scheduler's interval and its latest run
+func (s *Scheduler) Status() liveness.Status {
+	return s.runs.Status(s.interval)
+}
+
 // run is the main scheduler loop
 func (s *Scheduler) run(ctx context.Context) {
 	defer s.wg.Done()
 
-	ticker := time.NewTicker(s.interval)
-	defer ticker.Stop()
-
 	// Run after a short delay on start (to let the app initialize)
 	select {
-	case <-time.After(15 * time.Second):
+	case <-time.After(15*time.Second + jitter.UpTo(s.startupJitter)):
 		s.process(ctx)
 	case <-s.stopCh:
 		return
@@ -134,10 +185,14 @@ func (s *Scheduler) run(ctx context.Context) {
 		return
 	}
 
+	timer := time.NewTimer(jitter.Apply(s.interval, s.jitter))
+	defer timer.Stop()
+
 	for {
 		select {
-		case <-ticker.C:
+		case <-timer.C:
 			s.process(ctx)
+			timer.Reset(jitter.Apply(s.interval, s.jitter))
 		case <-s.stopCh:
 			return
 		case <-ctx.Done():
@@ -148,6 +203,13 @@ func (s *Scheduler) run(ctx context.Context) {
 
 // process syncs conversations for accounts that need it
 func (s *Scheduler) process(ctx context.Context) {
+	defer s.runs.RunStarted(time.Now())()
+
+	if s.throttled() {
+		s.logger.Warn("instagram API usage is high, skipping DM sync run")
+		return
+	}
+
 	s.logger.Debug("checking for accounts needing DM sync")
 
 	accountIDs, err := s.syncer.GetAccountsNeedingSync(ctx, s.syncAge, s.batchSize)
@@ -164,12 +226,18 @@ func (s *Scheduler) process(ctx context.Context) {
 	s.logger.Info("syncing conversations for accounts", "count", len(accountIDs))
 
 	for _, accountID := range accountIDs {
-		// Check if context is cancelled
+		// Stop between items on shutdown so only the current one is drained
 		select {
+		case <-s.stopCh:
+			return
 		case <-ctx.Done():
 			return
 		default:
 		}
+		if s.throttled() {
+			s.logger.Warn("instagram API usage is high, pausing DM sync until it recovers")
+			return
+		}
 
 		if err := s.syncAccount(ctx, accountID); err != nil {
 			s.logger.Error("failed to sync conversations", "account_id", accountID, "error", err)
@@ -181,16 +249,10 @@ func (s *Scheduler) process(ctx context.Context) {
 
 // syncAccount syncs conversations for a single account
 func (s *Scheduler) syncAccount(ctx context.Context, accountID string) error {
-	// Get access token for the account
-	accessToken, err := s.accountProvider.GetAccessToken(ctx, accountID)
-	if err != nil {
-		// Increment retry count on error
-		_ = s.syncer.IncrementAccountSyncRetryCount(ctx, accountID, err.Error(), s.maxRetries)
-		return err
-	}
+	ctx = logctx.With(ctx, "account_id", accountID)
 
-	// Get Instagram user ID
-	userID, err := s.accountProvider.GetInstagramUserID(ctx, accountID)
+	// Get access token and Instagram user ID for the account
+	accessToken, userID, err := s.accountProvider.GetAccountCredentials(ctx, accountID)
 	if err != nil {
 		// Increment retry count on error
 		_ = s.syncer.IncrementAccountSyncRetryCount(ctx, accountID, err.Error(), s.maxRetries)
@@ -198,7 +260,11 @@ func (s *Scheduler) syncAccount(ctx context.Context, accountID string) error {
 	}
 
 	// Sync conversations
-	err = s.syncer.SyncConversations(ctx, accountID, userID, accessToken)
+	_, err = s.syncer.SyncConversations(ctx, accountID, userID, accessToken)
+	if errors.Is(err, entity.ErrSyncInProgress) {
+		// A manual sync is already running for this account; nothing to do
+		return nil
+	}
 	if err != nil {
 		// Increment retry count on error
 		_ = s.syncer.IncrementAccountSyncRetryCount(ctx, accountID, err.Error(), s.maxRetries)
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vadim/neo-metric/internal/domain/direct/entity"
+)
+
+// deleteRepo serves a single conversation and records deletions
+type deleteRepo struct {
+	ConversationRepository
+	conv    *entity.Conversation
+	deleted []string
+}
+
+func (r *deleteRepo) GetByID(_ context.Context, id string) (*entity.Conversation, error) {
+	if r.conv == nil || r.conv.ID != id {
+		return nil, nil
+	}
+	return r.conv, nil
+}
+
+func (r *deleteRepo) Delete(_ context.Context, id string) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+func TestDeleteConversation(t *testing.T) {
+	repo := &deleteRepo{conv: &entity.Conversation{ID: "c1", AccountID: "7"}}
+	svc := NewWithRepo(nil, repo, nil, nil, nil)
+
+	if err := svc.DeleteConversation(context.Background(), "7", "c1"); err != nil {
+		t.Fatalf("DeleteConversation: %v", err)
+	}
+	if len(repo.deleted) != 1 || repo.deleted[0] != "c1" {
+		t.Errorf("deleted = %v, want [c1]", repo.deleted)
+	}
+}
+
+func TestDeleteConversationChecksOwnership(t *testing.T) {
+	repo := &deleteRepo{conv: &entity.Conversation{ID: "c1", AccountID: "7"}}
+	svc := NewWithRepo(nil, repo, nil, nil, nil)
+
+	for _, tc := range []struct{ account, conv string }{
+		{"8", "c1"}, // another account's conversation
+		{"7", "c2"}, // missing conversation
+	} {
+		err := svc.DeleteConversation(context.Background(), tc.account, tc.conv)
+		if !errors.Is(err, entity.ErrConversationNotFound) {
+			t.Errorf("DeleteConversation(%s, %s) err = %v, want ErrConversationNotFound", tc.account, tc.conv, err)
+		}
+	}
+	if len(repo.deleted) != 0 {
+		t.Errorf("deleted = %v, want none", repo.deleted)
+	}
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/vadim/neo-metric/internal/domain/direct/entity"
+)
+
+// participantRepo serves the conversations of one participant
+type participantRepo struct {
+	ConversationRepository
+	convs   []entity.Conversation
+	deleted []string
+}
+
+func (r *participantRepo) GetByParticipant(_ context.Context, accountID, participantID string) ([]entity.Conversation, error) {
+	var out []entity.Conversation
+	for _, c := range r.convs {
+		if c.AccountID == accountID && c.ParticipantID == participantID {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (r *participantRepo) Delete(_ context.Context, id string) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+// pagedMessageRepo returns n messages per conversation, honoring limit and offset
+type pagedMessageRepo struct {
+	MessageRepository
+	n     map[string]int
+	pages int
+}
+
+func (r *pagedMessageRepo) GetByConversationID(_ context.Context, conversationID string, limit, offset int) ([]entity.Message, error) {
+	r.pages++
+	var out []entity.Message
+	for i := offset; i < r.n[conversationID] && len(out) < limit; i++ {
+		out = append(out, entity.Message{ID: fmt.Sprintf("%s-%d", conversationID, i), ConversationID: conversationID})
+	}
+	return out, nil
+}
+
+// recordingSink counts exported messages per conversation
+type recordingSink struct {
+	order  []string
+	counts map[string]int
+}
+
+func (s *recordingSink) Conversation(conv *entity.Conversation) error {
+	s.order = append(s.order, conv.ID)
+	return nil
+}
+
+func (s *recordingSink) Messages(msgs []entity.Message) error {
+	s.counts[s.order[len(s.order)-1]] += len(msgs)
+	return nil
+}
+
+func TestExportParticipantPagesThroughMessages(t *testing.T) {
+	convs := &participantRepo{convs: []entity.Conversation{
+		{ID: "c1", AccountID: "7", ParticipantID: "p"},
+		{ID: "c2", AccountID: "7", ParticipantID: "p"},
+		{ID: "c3", AccountID: "7", ParticipantID: "other"},
+	}}
+	msgs := &pagedMessageRepo{n: map[string]int{"c1": exportPageSize*2 + 3, "c2": exportPageSize}}
+	svc := NewWithRepo(nil, convs, msgs, nil, nil)
+
+	sink := &recordingSink{counts: map[string]int{}}
+	if err := svc.ExportParticipant(context.Background(), "7", "p", sink); err != nil {
+		t.Fatalf("ExportParticipant: %v", err)
+	}
+
+	if fmt.Sprint(sink.order) != "[c1 c2]" {
+		t.Errorf("conversations = %v, want [c1 c2]", sink.order)
+	}
+	if sink.counts["c1"] != exportPageSize*2+3 || sink.counts["c2"] != exportPageSize {
+		t.Errorf("message counts = %v", sink.counts)
+	}
+	// c1 needs 3 pages; c2 fills exactly one page, so one more empty page confirms the end
+	if msgs.pages != 5 {
+		t.Errorf("pages loaded = %d, want 5", msgs.pages)
+	}
+}
+
+func TestExportParticipantWithoutConversations(t *testing.T) {
+	svc := NewWithRepo(nil, &participantRepo{}, &pagedMessageRepo{}, nil, nil)
+
+	sink := &recordingSink{counts: map[string]int{}}
+	err := svc.ExportParticipant(context.Background(), "7", "p", sink)
+	if !errors.Is(err, entity.ErrConversationNotFound) {
+		t.Fatalf("err = %v, want ErrConversationNotFound", err)
+	}
+	if len(sink.order) != 0 {
+		t.Errorf("sink received %v, want nothing", sink.order)
+	}
+}
+
+func TestDeleteParticipant(t *testing.T) {
+	convs := &participantRepo{convs: []entity.Conversation{
+		{ID: "c1", AccountID: "7", ParticipantID: "p"},
+		{ID: "c2", AccountID: "8", ParticipantID: "p"},
+	}}
+	svc := NewWithRepo(nil, convs, nil, nil, nil)
+
+	n, err := svc.DeleteParticipant(context.Background(), "7", "p")
+	if err != nil {
+		t.Fatalf("DeleteParticipant: %v", err)
+	}
+	if n != 1 || fmt.Sprint(convs.deleted) != "[c1]" {
+		t.Errorf("deleted %d %v, want 1 [c1]", n, convs.deleted)
+	}
+}
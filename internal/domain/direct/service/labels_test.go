@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/vadim/neo-metric/internal/domain/direct/entity"
+)
+
+// labelRepo records the label filter passed to the conversation repository
+type labelRepo struct {
+	ConversationRepository
+	gotLabels [][]string
+}
+
+func (r *labelRepo) GetByAccountID(_ context.Context, _ string, labels []string, _, _ int) ([]entity.Conversation, error) {
+	r.gotLabels = append(r.gotLabels, labels)
+	return nil, nil
+}
+
+func (r *labelRepo) Count(_ context.Context, _ string, labels []string) (int64, error) {
+	r.gotLabels = append(r.gotLabels, labels)
+	return 0, nil
+}
+
+func TestGetConversationsNormalizesLabelFilter(t *testing.T) {
+	repo := &labelRepo{}
+	svc := NewWithRepo(nil, repo, nil, nil, nil)
+
+	_, err := svc.GetConversations(context.Background(), GetConversationsInput{
+		AccountID: "7",
+		Labels:    []string{"Lead", " complaint ", "lead"},
+	})
+	if err != nil {
+		t.Fatalf("GetConversations: %v", err)
+	}
+
+	// Duplicates must be collapsed so the DAO's "has all N labels" count is exact
+	want := []string{"lead", "complaint"}
+	for _, got := range repo.gotLabels {
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("repo labels = %v, want %v", got, want)
+		}
+	}
+	if len(repo.gotLabels) != 2 {
+		t.Errorf("repo called %d times, want 2 (list and count)", len(repo.gotLabels))
+	}
+}
+
+func TestGetConversationsRejectsInvalidLabel(t *testing.T) {
+	svc := NewWithRepo(nil, &labelRepo{}, nil, nil, nil)
+
+	_, err := svc.GetConversations(context.Background(), GetConversationsInput{
+		AccountID: "7",
+		Labels:    []string{"lead", "  "},
+	})
+	if !errors.Is(err, entity.ErrInvalidLabel) {
+		t.Fatalf("err = %v, want ErrInvalidLabel", err)
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/vadim/neo-metric/internal/domain/direct/entity"
+)
+
+// profileClient serves one participant profile, or fails with err
+type profileClient struct {
+	InstagramClient
+	profile *ParticipantResult
+	err     error
+}
+
+func (c *profileClient) GetParticipant(_ context.Context, _, _ string) (*ParticipantResult, error) {
+	return c.profile, c.err
+}
+
+// profileRepo serves an account's conversations with participant p1 and records profile updates
+type profileRepo struct {
+	ConversationRepository
+	convs   []entity.Conversation
+	updated *entity.Participant
+}
+
+func (r *profileRepo) GetByParticipant(_ context.Context, accountID, participantID string) ([]entity.Conversation, error) {
+	if accountID != "7" || participantID != "p1" {
+		return nil, nil
+	}
+	return r.convs, nil
+}
+
+func (r *profileRepo) UpdateParticipant(_ context.Context, _ string, p *entity.Participant) error {
+	r.updated = p
+	return nil
+}
+
+func newProfileRepo() *profileRepo {
+	return &profileRepo{convs: []entity.Conversation{{
+		ID:                        "c1",
+		AccountID:                 "7",
+		ParticipantID:             "p1",
+		ParticipantUsername:       "alice",
+		ParticipantFollowersCount: 10,
+	}}}
+}
+
+func TestGetParticipantCachesProfile(t *testing.T) {
+	ig := &profileClient{profile: &ParticipantResult{ID: "p1", Username: "alice", Name: "Alice", AvatarURL: "https://cdn.example.com/a.jpg", FollowersCount: 1500}}
+	repo := newProfileRepo()
+	svc := NewWithRepo(ig, repo, nil, nil, nil)
+
+	p, err := svc.GetParticipant(context.Background(), GetParticipantInput{AccountID: "7", ParticipantID: "p1", AccessToken: "token"})
+	if err != nil {
+		t.Fatalf("GetParticipant: %v", err)
+	}
+	if p.FollowersCount != 1500 || p.Name != "Alice" || p.Restricted {
+		t.Errorf("participant = %+v, want the fetched profile", p)
+	}
+	if repo.updated == nil || repo.updated.AvatarURL != "https://cdn.example.com/a.jpg" {
+		t.Errorf("cached profile = %+v, want the fetched one", repo.updated)
+	}
+}
+
+func TestGetParticipantFallsBackForRestrictedProfile(t *testing.T) {
+	ig := &profileClient{err: fmt.Errorf("%w: code 100", entity.ErrProfileUnavailable)}
+	repo := newProfileRepo()
+	svc := NewWithRepo(ig, repo, nil, nil, nil)
+
+	p, err := svc.GetParticipant(context.Background(), GetParticipantInput{AccountID: "7", ParticipantID: "p1", AccessToken: "token"})
+	if err != nil {
+		t.Fatalf("GetParticipant: %v", err)
+	}
+	if !p.Restricted || p.Username != "alice" || p.FollowersCount != 10 {
+		t.Errorf("participant = %+v, want the cached profile marked restricted", p)
+	}
+	if repo.updated != nil {
+		t.Errorf("cached profile overwritten with %+v", repo.updated)
+	}
+}
+
+func TestGetParticipantRequiresConversation(t *testing.T) {
+	ig := &profileClient{profile: &ParticipantResult{ID: "p2"}}
+	svc := NewWithRepo(ig, newProfileRepo(), nil, nil, nil)
+
+	_, err := svc.GetParticipant(context.Background(), GetParticipantInput{AccountID: "7", ParticipantID: "p2", AccessToken: "token"})
+	if !errors.Is(err, entity.ErrConversationNotFound) {
+		t.Errorf("err = %v, want ErrConversationNotFound", err)
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/direct/entity"
+)
+
+// readRepo computes the unread count from stored incoming message times like the DAO does
+type readRepo struct {
+	ConversationRepository
+	conv     entity.Conversation
+	incoming []time.Time
+}
+
+func (r *readRepo) GetByID(_ context.Context, id string) (*entity.Conversation, error) {
+	if r.conv.ID != id {
+		return nil, nil
+	}
+	conv := r.conv
+	conv.UnreadCount = 0
+	for _, ts := range r.incoming {
+		if conv.LastReadAt == nil || ts.After(*conv.LastReadAt) {
+			conv.UnreadCount++
+		}
+	}
+	return &conv, nil
+}
+
+func (r *readRepo) MarkRead(_ context.Context, id string, readAt time.Time) error {
+	r.conv.LastReadAt = &readAt
+	return nil
+}
+
+func TestMarkConversationRead(t *testing.T) {
+	repo := &readRepo{
+		conv:     entity.Conversation{ID: "c1", AccountID: "7"},
+		incoming: []time.Time{time.Now().Add(-2 * time.Hour), time.Now().Add(-time.Hour)},
+	}
+	svc := NewWithRepo(nil, repo, nil, nil, nil)
+	ctx := context.Background()
+
+	before, _ := repo.GetByID(ctx, "c1")
+	if before.UnreadCount != 2 {
+		t.Fatalf("unread before = %d, want 2", before.UnreadCount)
+	}
+
+	conv, err := svc.MarkConversationRead(ctx, "7", "c1")
+	if err != nil {
+		t.Fatalf("MarkConversationRead: %v", err)
+	}
+	if conv.UnreadCount != 0 || conv.LastReadAt == nil {
+		t.Errorf("after read: unread = %d, last_read_at = %v; want 0 and set", conv.UnreadCount, conv.LastReadAt)
+	}
+
+	// A message arriving after the read marker counts as unread again
+	repo.incoming = append(repo.incoming, time.Now().Add(time.Minute))
+	after, _ := repo.GetByID(ctx, "c1")
+	if after.UnreadCount != 1 {
+		t.Errorf("unread after new message = %d, want 1", after.UnreadCount)
+	}
+}
+
+func TestMarkConversationReadChecksOwnership(t *testing.T) {
+	repo := &readRepo{conv: entity.Conversation{ID: "c1", AccountID: "7"}}
+	svc := NewWithRepo(nil, repo, nil, nil, nil)
+
+	_, err := svc.MarkConversationRead(context.Background(), "8", "c1")
+	if !errors.Is(err, entity.ErrConversationNotFound) {
+		t.Errorf("err = %v, want ErrConversationNotFound", err)
+	}
+	if repo.conv.LastReadAt != nil {
+		t.Error("another account's conversation was marked read")
+	}
+}
@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"slices"
 	"sync"
 	"time"
 
@@ -15,7 +17,8 @@ type InstagramClient interface {
 	GetConversations(ctx context.Context, userID, accessToken string, limit int, after string) (*ConversationsResult, error)
 	GetMessages(ctx context.Context, conversationID, userID, accessToken string, limit int, after string) (*MessagesResult, error)
 	SendMessage(ctx context.Context, userID, recipientID, accessToken, message string) (*SendMessageResult, error)
-	SendMediaMessage(ctx context.Context, userID, recipientID, accessToken, mediaURL, mediaType string) (*SendMessageResult, error)
+	SendMediaMessage(ctx context.Context, userID, recipientID, accessToken, mediaURL, mediaType, caption string) (*SendMessageResult, error)
+	SendReaction(ctx context.Context, userID, recipientID, accessToken, messageID, reaction string) error
 	GetParticipant(ctx context.Context, userID, accessToken string) (*ParticipantResult, error)
 }
 
@@ -24,10 +27,16 @@ type ConversationRepository interface {
 	Upsert(ctx context.Context, conv *entity.Conversation) error
 	UpsertBatch(ctx context.Context, convs []entity.Conversation) error
 	GetByID(ctx context.Context, id string) (*entity.Conversation, error)
-	GetByAccountID(ctx context.Context, accountID string, limit, offset int) ([]entity.Conversation, error)
+	GetByAccountID(ctx context.Context, accountID string, labels []string, limit, offset int) ([]entity.Conversation, error)
+	GetByParticipant(ctx context.Context, accountID, participantID string) ([]entity.Conversation, error)
+	UpdateParticipant(ctx context.Context, accountID string, p *entity.Participant) error
 	Search(ctx context.Context, accountID, query string, limit, offset int) ([]entity.Conversation, error)
 	Delete(ctx context.Context, id string) error
-	Count(ctx context.Context, accountID string) (int64, error)
+	MarkRead(ctx context.Context, id string, readAt time.Time) error
+	Count(ctx context.Context, accountID string, labels []string) (int64, error)
+	AddLabels(ctx context.Context, accountID, conversationID string, labels []string) error
+	RemoveLabels(ctx context.Context, conversationID string, labels []string) error
+	GetLabels(ctx context.Context, conversationID string) ([]string, error)
 }
 
 // MessageRepository defines the interface for message storage
@@ -36,6 +45,7 @@ type MessageRepository interface {
 	UpsertBatch(ctx context.Context, msgs []entity.Message) error
 	GetByID(ctx context.Context, id string) (*entity.Message, error)
 	GetByConversationID(ctx context.Context, conversationID string, limit, offset int) ([]entity.Message, error)
+	UpdateReaction(ctx context.Context, id, reaction string) error
 	Delete(ctx context.Context, id string) error
 	Count(ctx context.Context, conversationID string) (int64, error)
 	GetStatistics(ctx context.Context, filter entity.StatisticsFilter) (*entity.Statistics, error)
@@ -49,6 +59,7 @@ type ConversationSyncRepository interface {
 	GetConversationsNeedingSync(ctx context.Context, accountID string, olderThan time.Duration, limit int) ([]string, error)
 	IncrementRetryCount(ctx context.Context, conversationID string, lastError string, maxRetries int) error
 	ResetRetryCount(ctx context.Context, conversationID string) error
+	GetFailedConversationIDs(ctx context.Context, accountID string) ([]string, error)
 }
 
 // AccountSyncRepository defines sync status tracking for accounts
@@ -102,13 +113,14 @@ type ConversationSyncStatus struct {
 
 // AccountSyncStatus tracks sync state per account
 type AccountSyncStatus struct {
-	AccountID    string
-	LastSyncedAt time.Time
-	NextCursor   string
-	SyncComplete bool
-	RetryCount   int
-	Failed       bool
-	LastError    string
+	AccountID      string
+	LastSyncedAt   time.Time
+	NextCursor     string // Where an interrupted full pass resumes
+	SyncComplete   bool
+	RetryCount     int
+	Failed         bool
+	LastError      string
+	LastFullSyncAt *time.Time // nil until the first complete full pass
 }
 
 // Service handles DM business logic
@@ -119,6 +131,10 @@ type Service struct {
 	convSyncRepo    ConversationSyncRepository
 	accountSyncRepo AccountSyncRepository
 	syncMaxAge      time.Duration
+	maxMessages     int           // Sync depth per conversation, 0 = whole history
+	maxMessagePages int           // Pages one message sync may request, 0 = no limit
+	fullSyncEvery   time.Duration // How often the whole conversation list is re-fetched, 0 = every sync
+	inFlight        sync.Map      // "account:<id>" / "conversation:<id>" keys of running manual or scheduled syncs
 }
 
 // New creates a new direct message service (API only, no repository)
@@ -144,14 +160,37 @@ func NewWithRepo(
 		convSyncRepo:    convSyncRepo,
 		accountSyncRepo: accountSyncRepo,
 		syncMaxAge:      5 * time.Minute,
+		fullSyncEvery:   24 * time.Hour,
+		maxMessagePages: 1000,
 	}
 }
 
+// WithMaxMessages limits how many messages one sync fetches per conversation (0 = no limit)
+func (s *Service) WithMaxMessages(n int) *Service {
+	s.maxMessages = n
+	return s
+}
+
+// WithMaxMessagePages caps how many pages one message sync requests, guarding against
+// a pagination that never ends (0 = no limit)
+func (s *Service) WithMaxMessagePages(n int) *Service {
+	s.maxMessagePages = n
+	return s
+}
+
+// WithFullSyncInterval sets how often the whole conversation list of an account is re-fetched;
+// syncs in between stop at conversations not updated since the previous sync (0 = always full)
+func (s *Service) WithFullSyncInterval(d time.Duration) *Service {
+	s.fullSyncEvery = d
+	return s
+}
+
 // GetConversationsInput represents input for getting conversations
 type GetConversationsInput struct {
 	AccountID   string
 	UserID      string
 	AccessToken string
+	Labels      []string // Only conversations carrying all of these labels
 	Limit       int
 	Offset      int
 }
@@ -170,14 +209,19 @@ func (s *Service) GetConversations(ctx context.Context, in GetConversationsInput
 		limit = 50
 	}
 
+	labels, err := entity.NormalizeLabels(in.Labels)
+	if err != nil {
+		return nil, err
+	}
+
 	// If we have a repository, get from local cache
 	if s.convRepo != nil {
-		conversations, err := s.convRepo.GetByAccountID(ctx, in.AccountID, limit, in.Offset)
+		conversations, err := s.convRepo.GetByAccountID(ctx, in.AccountID, labels, limit, in.Offset)
 		if err != nil {
 			return nil, fmt.Errorf("getting conversations from cache: %w", err)
 		}
 
-		total, _ := s.convRepo.Count(ctx, in.AccountID)
+		total, _ := s.convRepo.Count(ctx, in.AccountID, labels)
 
 		return &GetConversationsOutput{
 			Conversations: conversations,
@@ -186,6 +230,10 @@ func (s *Service) GetConversations(ctx context.Context, in GetConversationsInput
 		}, nil
 	}
 
+	if len(labels) > 0 {
+		return nil, fmt.Errorf("label filter requires repository")
+	}
+
 	// Fallback to direct API call
 	result, err := s.ig.GetConversations(ctx, in.UserID, in.AccessToken, limit, "")
 	if err != nil {
@@ -230,6 +278,239 @@ func (s *Service) SearchConversations(ctx context.Context, in SearchConversation
 	}, nil
 }
 
+// AddLabels tags a conversation of the account and returns its current labels
+func (s *Service) AddLabels(ctx context.Context, accountID, conversationID string, labels []string) ([]string, error) {
+	labels, err := s.checkLabelInput(ctx, accountID, conversationID, labels)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.convRepo.AddLabels(ctx, accountID, conversationID, labels); err != nil {
+		return nil, err
+	}
+	return s.convRepo.GetLabels(ctx, conversationID)
+}
+
+// RemoveLabels untags a conversation of the account and returns its current labels
+func (s *Service) RemoveLabels(ctx context.Context, accountID, conversationID string, labels []string) ([]string, error) {
+	labels, err := s.checkLabelInput(ctx, accountID, conversationID, labels)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.convRepo.RemoveLabels(ctx, conversationID, labels); err != nil {
+		return nil, err
+	}
+	return s.convRepo.GetLabels(ctx, conversationID)
+}
+
+// DeleteConversation removes a conversation of the account from local storage.
+// Its messages, labels and sync status are removed by the database cascade.
+func (s *Service) DeleteConversation(ctx context.Context, accountID, conversationID string) error {
+	if s.convRepo == nil {
+		return fmt.Errorf("deleting conversations requires repository")
+	}
+
+	conv, err := s.convRepo.GetByID(ctx, conversationID)
+	if err != nil {
+		return fmt.Errorf("getting conversation: %w", err)
+	}
+	if conv == nil || conv.AccountID != accountID {
+		return entity.ErrConversationNotFound
+	}
+
+	return s.convRepo.Delete(ctx, conversationID)
+}
+
+// MarkConversationRead marks all messages in a conversation as read and returns it with the updated unread count
+func (s *Service) MarkConversationRead(ctx context.Context, accountID, conversationID string) (*entity.Conversation, error) {
+	if s.convRepo == nil {
+		return nil, fmt.Errorf("marking conversations read requires repository")
+	}
+
+	conv, err := s.convRepo.GetByID(ctx, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("getting conversation: %w", err)
+	}
+	if conv == nil || conv.AccountID != accountID {
+		return nil, entity.ErrConversationNotFound
+	}
+
+	if err := s.convRepo.MarkRead(ctx, conversationID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	// Reload so the unread count reflects the new marker
+	conv, err = s.convRepo.GetByID(ctx, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("getting conversation: %w", err)
+	}
+	if conv == nil {
+		return nil, entity.ErrConversationNotFound
+	}
+	return conv, nil
+}
+
+// exportPageSize is the number of messages loaded at a time during a participant export
+const exportPageSize = 500
+
+// ExportSink receives a participant export as it is read, so large histories
+// never have to be held in memory at once
+type ExportSink interface {
+	// Conversation starts a conversation; the Messages calls that follow belong to it
+	Conversation(conv *entity.Conversation) error
+	// Messages receives the next page of the current conversation's messages, newest first
+	Messages(msgs []entity.Message) error
+}
+
+// ExportParticipant writes every conversation the account has with the participant,
+// with all of their messages, to sink. Returns ErrConversationNotFound before
+// writing anything if there are no such conversations.
+func (s *Service) ExportParticipant(ctx context.Context, accountID, participantID string, sink ExportSink) error {
+	if s.convRepo == nil || s.msgRepo == nil {
+		return fmt.Errorf("export requires repository")
+	}
+
+	convs, err := s.convRepo.GetByParticipant(ctx, accountID, participantID)
+	if err != nil {
+		return fmt.Errorf("getting participant conversations: %w", err)
+	}
+	if len(convs) == 0 {
+		return entity.ErrConversationNotFound
+	}
+
+	for i := range convs {
+		if err := sink.Conversation(&convs[i]); err != nil {
+			return err
+		}
+
+		for offset := 0; ; offset += exportPageSize {
+			msgs, err := s.msgRepo.GetByConversationID(ctx, convs[i].ID, exportPageSize, offset)
+			if err != nil {
+				return fmt.Errorf("getting messages: %w", err)
+			}
+			if len(msgs) > 0 {
+				if err := sink.Messages(msgs); err != nil {
+					return err
+				}
+			}
+			if len(msgs) < exportPageSize {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteParticipant removes every conversation the account has with the participant
+// and returns how many were deleted
+func (s *Service) DeleteParticipant(ctx context.Context, accountID, participantID string) (int, error) {
+	if s.convRepo == nil {
+		return 0, fmt.Errorf("deleting conversations requires repository")
+	}
+
+	convs, err := s.convRepo.GetByParticipant(ctx, accountID, participantID)
+	if err != nil {
+		return 0, fmt.Errorf("getting participant conversations: %w", err)
+	}
+	if len(convs) == 0 {
+		return 0, entity.ErrConversationNotFound
+	}
+
+	for i, conv := range convs {
+		if err := s.convRepo.Delete(ctx, conv.ID); err != nil {
+			return i, err
+		}
+	}
+	return len(convs), nil
+}
+
+// GetParticipantInput represents input for fetching a participant's profile
+type GetParticipantInput struct {
+	AccountID     string
+	ParticipantID string
+	AccessToken   string
+}
+
+// GetParticipant fetches a participant's profile from Instagram and caches it on the account's
+// conversations with them. If Instagram withholds the profile, the cached one is returned
+// marked as restricted.
+func (s *Service) GetParticipant(ctx context.Context, in GetParticipantInput) (*entity.Participant, error) {
+	if s.convRepo == nil {
+		return nil, fmt.Errorf("participant lookup requires repository")
+	}
+
+	// Only participants the account has talked to may be looked up
+	convs, err := s.convRepo.GetByParticipant(ctx, in.AccountID, in.ParticipantID)
+	if err != nil {
+		return nil, fmt.Errorf("getting participant conversations: %w", err)
+	}
+	if len(convs) == 0 {
+		return nil, entity.ErrConversationNotFound
+	}
+
+	result, err := s.ig.GetParticipant(ctx, in.ParticipantID, in.AccessToken)
+	switch {
+	case errors.Is(err, entity.ErrProfileUnavailable):
+		latest := convs[len(convs)-1]
+		return &entity.Participant{
+			ID:             in.ParticipantID,
+			Username:       latest.ParticipantUsername,
+			Name:           latest.ParticipantName,
+			AvatarURL:      latest.ParticipantAvatarURL,
+			FollowersCount: latest.ParticipantFollowersCount,
+			Restricted:     true,
+		}, nil
+	case errors.Is(err, entity.ErrRateLimited):
+		return nil, entity.ErrRateLimited
+	case err != nil:
+		return nil, fmt.Errorf("getting participant profile: %w", err)
+	}
+
+	participant := &entity.Participant{
+		ID:             in.ParticipantID,
+		Username:       result.Username,
+		Name:           result.Name,
+		AvatarURL:      result.AvatarURL,
+		FollowersCount: result.FollowersCount,
+	}
+	if participant.Username == "" {
+		participant.Username = convs[len(convs)-1].ParticipantUsername
+	}
+
+	// Best-effort: cache the profile for conversation lists
+	if err := s.convRepo.UpdateParticipant(ctx, in.AccountID, participant); err != nil {
+		log.Printf("[WARN] GetParticipant: caching profile of %s: %v", in.ParticipantID, err)
+	}
+
+	return participant, nil
+}
+
+// checkLabelInput normalizes labels and verifies the conversation belongs to the account
+func (s *Service) checkLabelInput(ctx context.Context, accountID, conversationID string, labels []string) ([]string, error) {
+	if s.convRepo == nil {
+		return nil, fmt.Errorf("labels require repository")
+	}
+
+	labels, err := entity.NormalizeLabels(labels)
+	if err != nil {
+		return nil, err
+	}
+	if len(labels) == 0 {
+		return nil, entity.ErrNoLabels
+	}
+
+	conv, err := s.convRepo.GetByID(ctx, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("getting conversation: %w", err)
+	}
+	if conv == nil || conv.AccountID != accountID {
+		return nil, entity.ErrConversationNotFound
+	}
+
+	return labels, nil
+}
+
 // GetMessagesInput represents input for getting messages
 type GetMessagesInput struct {
 	AccountID      string
@@ -248,6 +529,7 @@ type GetMessagesOutput struct {
 }
 
 // GetMessages retrieves messages for a conversation (triggers on-demand sync)
+// Returns ErrConversationNotFound if the account has no such conversation.
 func (s *Service) GetMessages(ctx context.Context, in GetMessagesInput) (*GetMessagesOutput, error) {
 	limit := in.Limit
 	if limit <= 0 {
@@ -255,7 +537,16 @@ func (s *Service) GetMessages(ctx context.Context, in GetMessagesInput) (*GetMes
 	}
 
 	// If we have repositories, check if we need to sync
-	if s.msgRepo != nil && s.convSyncRepo != nil {
+	if s.convRepo != nil && s.msgRepo != nil && s.convSyncRepo != nil {
+		// Cached messages are keyed by conversation only, so confirm the account owns it first
+		conv, err := s.convRepo.GetByID(ctx, in.ConversationID)
+		if err != nil {
+			return nil, fmt.Errorf("getting conversation: %w", err)
+		}
+		if conv == nil || conv.AccountID != in.AccountID {
+			return nil, entity.ErrConversationNotFound
+		}
+
 		// Check sync status
 		syncStatus, err := s.convSyncRepo.GetSyncStatus(ctx, in.ConversationID)
 		if err != nil {
@@ -265,7 +556,7 @@ func (s *Service) GetMessages(ctx context.Context, in GetMessagesInput) (*GetMes
 		// Sync if never synced or stale
 		needsSync := syncStatus == nil || time.Since(syncStatus.LastSyncedAt) > s.syncMaxAge
 		if needsSync {
-			if err := s.syncMessagesFromInstagram(ctx, in.ConversationID, in.UserID, in.AccessToken); err != nil {
+			if _, err := s.syncMessagesFromInstagram(ctx, in.ConversationID, in.UserID, in.AccessToken); err != nil {
 				// Log error but continue with cached data if available
 				fmt.Printf("sync error (continuing with cache): %v\n", err)
 			}
@@ -299,21 +590,46 @@ func (s *Service) GetMessages(ctx context.Context, in GetMessagesInput) (*GetMes
 	}, nil
 }
 
-// syncMessagesFromInstagram syncs messages from Instagram API to local database
-// Saves each page incrementally and asynchronously
-func (s *Service) syncMessagesFromInstagram(ctx context.Context, conversationID, userID, accessToken string) error {
+// messagePageSize is the number of messages requested per Instagram API call during sync
+const messagePageSize = 100
+
+// syncOverlap re-fetches messages slightly older than the previous sync to absorb
+// clock differences between Instagram timestamps and ours
+const syncOverlap = 5 * time.Minute
+
+// syncMessagesFromInstagram syncs messages from Instagram API to local database.
+// Messages arrive newest first. A sync stops when it reaches messages stored by the
+// previous sync, after maxMessages messages (if set), or at the start of the history.
+func (s *Service) syncMessagesFromInstagram(ctx context.Context, conversationID, userID, accessToken string) (int, error) {
+	startedAt := time.Now()
+
+	// After a successful sync everything from the stored oldest message up to that
+	// sync's start is in the database, so older pages need not be fetched again
+	prev, err := s.convSyncRepo.GetSyncStatus(ctx, conversationID)
+	if err != nil {
+		return 0, fmt.Errorf("getting sync status: %w", err)
+	}
+	var since time.Time
+	if prev != nil && prev.OldestMessageTimestamp != nil && !prev.LastSyncedAt.IsZero() {
+		since = prev.LastSyncedAt.Add(-syncOverlap)
+	}
+
 	cursor := ""
+	synced := 0
 	var wg sync.WaitGroup
 	errCh := make(chan error, 1)
 	var oldestTimestamp *time.Time
 	var mu sync.Mutex
+	reachedSynced, reachedEnd := false, false
+	pages, emptyPages := 0, 0 // Requested pages, consecutive empty pages
+	const maxEmptyPages = 3   // Stop after this many consecutive empty pages
 
 	for {
 		// Check context cancellation
 		select {
 		case <-ctx.Done():
 			wg.Wait()
-			return ctx.Err()
+			return 0, ctx.Err()
 		default:
 		}
 
@@ -321,16 +637,38 @@ func (s *Service) syncMessagesFromInstagram(ctx context.Context, conversationID,
 		select {
 		case err := <-errCh:
 			wg.Wait()
-			return fmt.Errorf("async save failed: %w", err)
+			return 0, fmt.Errorf("async save failed: %w", err)
 		default:
 		}
 
-		result, err := s.ig.GetMessages(ctx, conversationID, userID, accessToken, 100, cursor)
+		pageSize := messagePageSize
+		if s.maxMessages > 0 && s.maxMessages-synced < pageSize {
+			pageSize = s.maxMessages - synced
+		}
+
+		result, err := s.ig.GetMessages(ctx, conversationID, userID, accessToken, pageSize, cursor)
 		if err != nil {
 			wg.Wait()
-			return fmt.Errorf("fetching messages: %w", err)
+			return 0, fmt.Errorf("fetching messages: %w", err)
+		}
+		pages++
+		if len(result.Messages) > pageSize {
+			result.Messages = result.Messages[:pageSize]
 		}
 
+		// Track consecutive empty pages to prevent infinite loops
+		if len(result.Messages) == 0 {
+			emptyPages++
+			if emptyPages >= maxEmptyPages && result.HasMore {
+				log.Printf("[WARN] syncMessagesFromInstagram: stopping conversation %s after %d consecutive empty pages", conversationID, emptyPages)
+				break
+			}
+		} else {
+			emptyPages = 0
+		}
+
+		synced += len(result.Messages)
+
 		// Save page asynchronously
 		if len(result.Messages) > 0 {
 			messages := make([]entity.Message, len(result.Messages))
@@ -354,9 +692,22 @@ func (s *Service) syncMessagesFromInstagram(ctx context.Context, conversationID,
 					}
 				}
 			}(messages)
+
+			if !since.IsZero() && lastMsg.Timestamp.Before(since) {
+				reachedSynced = true
+				break
+			}
 		}
 
 		if !result.HasMore || result.NextCursor == "" {
+			reachedEnd = true
+			break
+		}
+		if s.maxMessages > 0 && synced >= s.maxMessages {
+			break
+		}
+		if s.maxMessagePages > 0 && pages >= s.maxMessagePages {
+			log.Printf("[WARN] syncMessagesFromInstagram: stopping conversation %s at the limit of %d pages", conversationID, pages)
 			break
 		}
 		cursor = result.NextCursor
@@ -368,22 +719,32 @@ func (s *Service) syncMessagesFromInstagram(ctx context.Context, conversationID,
 	// Check for errors
 	select {
 	case err := <-errCh:
-		return fmt.Errorf("async save failed: %w", err)
+		return 0, fmt.Errorf("async save failed: %w", err)
 	default:
 	}
 
+	// Joining the previous sync keeps its oldest message and completeness;
+	// stopping at the depth limit starts a new, incomplete range
+	complete := reachedEnd
+	if reachedSynced {
+		complete = prev.SyncComplete
+		if prev.OldestMessageTimestamp.Before(*oldestTimestamp) {
+			oldestTimestamp = prev.OldestMessageTimestamp
+		}
+	}
+
 	// Update sync status
 	if err := s.convSyncRepo.UpdateSyncStatus(ctx, &ConversationSyncStatus{
 		ConversationID:         conversationID,
-		LastSyncedAt:           time.Now(),
+		LastSyncedAt:           startedAt,
 		NextCursor:             "",
-		SyncComplete:           true,
+		SyncComplete:           complete,
 		OldestMessageTimestamp: oldestTimestamp,
 	}); err != nil {
-		return fmt.Errorf("updating sync status: %w", err)
+		return 0, fmt.Errorf("updating sync status: %w", err)
 	}
 
-	return nil
+	return synced, nil
 }
 
 // SendMessageInput represents input for sending a message
@@ -438,15 +799,19 @@ type SendMediaMessageInput struct {
 	AccessToken    string
 	MediaURL       string
 	MediaType      string // "image" or "video"
+	Caption        string // Optional text sent in the same message
 }
 
-// SendMediaMessage sends a media message
+// SendMediaMessage sends a media message, with an optional caption
 func (s *Service) SendMediaMessage(ctx context.Context, in SendMediaMessageInput) (*SendMessageOutput, error) {
 	if in.MediaURL == "" {
 		return nil, entity.ErrMediaRequired
 	}
+	if err := entity.ValidateMediaCaption(in.MediaType, in.Caption); err != nil {
+		return nil, err
+	}
 
-	result, err := s.ig.SendMediaMessage(ctx, in.UserID, in.RecipientID, in.AccessToken, in.MediaURL, in.MediaType)
+	result, err := s.ig.SendMediaMessage(ctx, in.UserID, in.RecipientID, in.AccessToken, in.MediaURL, in.MediaType, in.Caption)
 	if err != nil {
 		return nil, fmt.Errorf("sending media message: %w", err)
 	}
@@ -462,6 +827,7 @@ func (s *Service) SendMediaMessage(ctx context.Context, in SendMediaMessageInput
 			ConversationID: in.ConversationID,
 			SenderID:       in.UserID,
 			Type:           msgType,
+			Text:           in.Caption,
 			MediaURL:       in.MediaURL,
 			MediaType:      in.MediaType,
 			IsFromMe:       true,
@@ -473,14 +839,87 @@ func (s *Service) SendMediaMessage(ctx context.Context, in SendMediaMessageInput
 	return &SendMessageOutput{MessageID: result.MessageID}, nil
 }
 
-// SyncConversations syncs conversations list from Instagram (for scheduler)
-// Saves each page incrementally and asynchronously to avoid memory buildup
-func (s *Service) SyncConversations(ctx context.Context, accountID, userID, accessToken string) error {
+// SendReactionInput represents input for reacting to a message
+type SendReactionInput struct {
+	ConversationID string
+	MessageID      string
+	UserID         string
+	RecipientID    string
+	AccessToken    string
+	Reaction       string // Empty removes the reaction
+}
+
+// SendReaction reacts to a message (or removes the reaction) and stores it on the cached message
+func (s *Service) SendReaction(ctx context.Context, in SendReactionInput) error {
+	if err := entity.ValidateReaction(in.Reaction); err != nil {
+		return err
+	}
+
+	// Reject messages known to belong to another conversation
+	if s.msgRepo != nil {
+		msg, err := s.msgRepo.GetByID(ctx, in.MessageID)
+		if err != nil {
+			return fmt.Errorf("getting message: %w", err)
+		}
+		if msg != nil && msg.ConversationID != in.ConversationID {
+			return entity.ErrMessageNotFound
+		}
+	}
+
+	if err := s.ig.SendReaction(ctx, in.UserID, in.RecipientID, in.AccessToken, in.MessageID, in.Reaction); err != nil {
+		return fmt.Errorf("sending reaction: %w", err)
+	}
+
+	// Best-effort: save to local database
+	if s.msgRepo != nil {
+		_ = s.msgRepo.UpdateReaction(ctx, in.MessageID, in.Reaction)
+	}
+
+	return nil
+}
+
+// conversationSyncOverlap re-fetches conversations updated slightly before the previous sync
+// to absorb clock differences between Instagram timestamps and ours
+const conversationSyncOverlap = 5 * time.Minute
+
+// SyncConversations syncs conversations list from Instagram and returns how many were fetched
+// Saves each page incrementally and asynchronously to avoid memory buildup.
+// The list is ordered by last activity. A full pass pages through all of it and stores its
+// cursor when interrupted, so the next sync resumes there. Between full passes a sync stops
+// at conversations not updated since the previous one.
+// Returns ErrSyncInProgress if a sync for the same account is already running.
+func (s *Service) SyncConversations(ctx context.Context, accountID, userID, accessToken string) (int, error) {
 	if s.convRepo == nil {
-		return fmt.Errorf("repository required for sync")
+		return 0, fmt.Errorf("repository required for sync")
 	}
 
-	cursor := ""
+	key := "account:" + accountID
+	if _, running := s.inFlight.LoadOrStore(key, struct{}{}); running {
+		return 0, entity.ErrSyncInProgress
+	}
+	defer s.inFlight.Delete(key)
+
+	startedAt := time.Now()
+	var prev *AccountSyncStatus
+	if s.accountSyncRepo != nil {
+		var err error
+		if prev, err = s.accountSyncRepo.GetSyncStatus(ctx, accountID); err != nil {
+			return 0, fmt.Errorf("getting account sync status: %w", err)
+		}
+	}
+
+	full := s.needsFullConversationSync(prev, startedAt)
+	cursor, resume := "", ""
+	var since time.Time
+	switch {
+	case prev != nil && !prev.SyncComplete && prev.NextCursor != "":
+		cursor, resume = prev.NextCursor, prev.NextCursor // Continue the interrupted full pass
+		full = true
+	case !full:
+		since = prev.LastSyncedAt.Add(-conversationSyncOverlap)
+	}
+
+	synced := 0
 	var wg sync.WaitGroup
 	errCh := make(chan error, 1) // Buffer for first error
 	emptyPages := 0              // Counter for consecutive empty pages
@@ -491,7 +930,10 @@ func (s *Service) SyncConversations(ctx context.Context, accountID, userID, acce
 		select {
 		case <-ctx.Done():
 			wg.Wait()
-			return ctx.Err()
+			if full && cursor != "" {
+				s.checkpointConversationSync(ctx, accountID, prev, cursor, errCh)
+			}
+			return 0, ctx.Err()
 		default:
 		}
 
@@ -499,14 +941,22 @@ func (s *Service) SyncConversations(ctx context.Context, accountID, userID, acce
 		select {
 		case err := <-errCh:
 			wg.Wait()
-			return fmt.Errorf("async save failed: %w", err)
+			return 0, fmt.Errorf("async save failed: %w", err)
 		default:
 		}
 
 		result, err := s.ig.GetConversations(ctx, userID, accessToken, 100, cursor)
 		if err != nil {
+			if resume != "" && cursor == resume {
+				// The stored cursor may have expired; start the pass over
+				resume, cursor = "", ""
+				continue
+			}
 			wg.Wait()
-			return fmt.Errorf("fetching conversations: %w", err)
+			if full && cursor != "" {
+				s.checkpointConversationSync(ctx, accountID, prev, cursor, errCh)
+			}
+			return 0, fmt.Errorf("fetching conversations: %w", err)
 		}
 
 		// log.Printf("[DEBUG] SyncConversations: got %d conversations, hasMore=%v, cursor=%s", len(result.Conversations), result.HasMore, cursor)
@@ -522,6 +972,8 @@ func (s *Service) SyncConversations(ctx context.Context, accountID, userID, acce
 			emptyPages = 0 // Reset counter on non-empty page
 		}
 
+		synced += len(result.Conversations)
+
 		// Save page asynchronously
 		if len(result.Conversations) > 0 {
 			// Set account ID for all conversations
@@ -550,6 +1002,9 @@ func (s *Service) SyncConversations(ctx context.Context, accountID, userID, acce
 		if !result.HasMore || result.NextCursor == "" {
 			break
 		}
+		if !since.IsZero() && updatedBefore(result.Conversations, since) {
+			break // The rest has not changed since the previous sync
+		}
 		cursor = result.NextCursor
 	}
 
@@ -559,22 +1014,68 @@ func (s *Service) SyncConversations(ctx context.Context, accountID, userID, acce
 	// Check for any errors from async saves
 	select {
 	case err := <-errCh:
-		return fmt.Errorf("async save failed: %w", err)
+		return 0, fmt.Errorf("async save failed: %w", err)
 	default:
 	}
 
 	// Update account sync status
 	if s.accountSyncRepo != nil {
-		if err := s.accountSyncRepo.UpdateSyncStatus(ctx, &AccountSyncStatus{
+		status := &AccountSyncStatus{
 			AccountID:    accountID,
 			LastSyncedAt: time.Now(),
 			SyncComplete: true,
-		}); err != nil {
-			return fmt.Errorf("updating account sync status: %w", err)
+		}
+		if full {
+			status.LastFullSyncAt = &startedAt
+		} else {
+			status.LastFullSyncAt = prev.LastFullSyncAt
+		}
+		if err := s.accountSyncRepo.UpdateSyncStatus(ctx, status); err != nil {
+			return 0, fmt.Errorf("updating account sync status: %w", err)
 		}
 	}
 
-	return nil
+	return synced, nil
+}
+
+// needsFullConversationSync reports whether the next conversation sync of an account must page
+// through the whole list
+func (s *Service) needsFullConversationSync(prev *AccountSyncStatus, now time.Time) bool {
+	if prev == nil || !prev.SyncComplete || prev.LastFullSyncAt == nil || s.fullSyncEvery <= 0 {
+		return true
+	}
+	return now.Sub(*prev.LastFullSyncAt) >= s.fullSyncEvery
+}
+
+// checkpointConversationSync stores where an interrupted full pass stopped, so the next sync
+// resumes there. Nothing is stored if a page before the cursor failed to save.
+func (s *Service) checkpointConversationSync(ctx context.Context, accountID string, prev *AccountSyncStatus, cursor string, errCh <-chan error) {
+	if s.accountSyncRepo == nil || len(errCh) > 0 {
+		return
+	}
+
+	status := &AccountSyncStatus{
+		AccountID:    accountID,
+		LastSyncedAt: time.Now(),
+		NextCursor:   cursor,
+	}
+	if prev != nil {
+		status.LastFullSyncAt = prev.LastFullSyncAt
+	}
+	// The sync may have been interrupted by cancellation; store the cursor regardless
+	if err := s.accountSyncRepo.UpdateSyncStatus(context.WithoutCancel(ctx), status); err != nil {
+		log.Printf("[WARN] SyncConversations: storing resume cursor for account %s: %v", accountID, err)
+	}
+}
+
+// updatedBefore reports whether the last conversation of a page, the least recently
+// active one, was last updated before t
+func updatedBefore(convs []entity.Conversation, t time.Time) bool {
+	if len(convs) == 0 {
+		return false
+	}
+	last := convs[len(convs)-1].LastMessageAt
+	return last != nil && last.Before(t)
 }
 
 // GetAccountsNeedingSync returns accounts that need conversation sync (for scheduler)
@@ -585,15 +1086,47 @@ func (s *Service) GetAccountsNeedingSync(ctx context.Context, olderThan time.Dur
 	return s.accountSyncRepo.GetAccountsNeedingSync(ctx, olderThan, limit)
 }
 
-// SyncMessages manually syncs messages for a specific conversation
-func (s *Service) SyncMessages(ctx context.Context, conversationID, userID, accessToken string) error {
-	if s.msgRepo == nil {
-		return fmt.Errorf("repository required for sync")
+// SyncMessages manually syncs messages for a conversation of the account and returns how many were fetched
+// Returns ErrConversationNotFound if the account has no such conversation,
+// and ErrSyncInProgress if a sync for the same conversation is already running.
+func (s *Service) SyncMessages(ctx context.Context, accountID, conversationID, userID, accessToken string) (int, error) {
+	if s.convRepo == nil || s.msgRepo == nil || s.convSyncRepo == nil {
+		return 0, fmt.Errorf("repository required for sync")
+	}
+
+	conv, err := s.convRepo.GetByID(ctx, conversationID)
+	if err != nil {
+		return 0, fmt.Errorf("getting conversation: %w", err)
+	}
+	if conv == nil || conv.AccountID != accountID {
+		return 0, entity.ErrConversationNotFound
+	}
+
+	key := "conversation:" + conversationID
+	if _, running := s.inFlight.LoadOrStore(key, struct{}{}); running {
+		return 0, entity.ErrSyncInProgress
 	}
+	defer s.inFlight.Delete(key)
 
 	return s.syncMessagesFromInstagram(ctx, conversationID, userID, accessToken)
 }
 
+// GetAccountSyncStatus returns the conversation sync status for an account (nil if never synced)
+func (s *Service) GetAccountSyncStatus(ctx context.Context, accountID string) (*AccountSyncStatus, error) {
+	if s.accountSyncRepo == nil {
+		return nil, nil
+	}
+	return s.accountSyncRepo.GetSyncStatus(ctx, accountID)
+}
+
+// GetConversationSyncStatus returns the message sync status for a conversation (nil if never synced)
+func (s *Service) GetConversationSyncStatus(ctx context.Context, conversationID string) (*ConversationSyncStatus, error) {
+	if s.convSyncRepo == nil {
+		return nil, nil
+	}
+	return s.convSyncRepo.GetSyncStatus(ctx, conversationID)
+}
+
 // GetStatisticsInput represents input for getting statistics
 type GetStatisticsInput struct {
 	AccountID string
@@ -665,3 +1198,49 @@ func (s *Service) ResetConversationSyncRetryCount(ctx context.Context, conversat
 	}
 	return s.convSyncRepo.ResetRetryCount(ctx, conversationID)
 }
+
+// ResetSyncsOutput lists the syncs put back into rotation by ResetFailedSyncs
+type ResetSyncsOutput struct {
+	ConversationIDs  []string
+	ConversationList bool // The account's conversation list sync was reset
+}
+
+// ResetFailedSyncs puts an account's conversations that were excluded from sync after too many
+// failures back into the sync rotation, along with the conversation list sync if it failed.
+// If conversationIDs is not empty only those conversations are reset.
+func (s *Service) ResetFailedSyncs(ctx context.Context, accountID string, conversationIDs []string) (*ResetSyncsOutput, error) {
+	out := &ResetSyncsOutput{ConversationIDs: []string{}}
+
+	if s.accountSyncRepo != nil && len(conversationIDs) == 0 {
+		status, err := s.accountSyncRepo.GetSyncStatus(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+		if status != nil && status.Failed {
+			if err := s.accountSyncRepo.ResetRetryCount(ctx, accountID); err != nil {
+				return nil, err
+			}
+			out.ConversationList = true
+		}
+	}
+
+	if s.convSyncRepo == nil {
+		return out, nil
+	}
+
+	failed, err := s.convSyncRepo.GetFailedConversationIDs(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range failed {
+		if len(conversationIDs) > 0 && !slices.Contains(conversationIDs, id) {
+			continue
+		}
+		if err := s.convSyncRepo.ResetRetryCount(ctx, id); err != nil {
+			return nil, err
+		}
+		out.ConversationIDs = append(out.ConversationIDs, id)
+	}
+
+	return out, nil
+}
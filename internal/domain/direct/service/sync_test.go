@@ -0,0 +1,354 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/direct/entity"
+)
+
+// historyClient serves a conversation history, newest first, one minute apart
+type historyClient struct {
+	InstagramClient
+	newest time.Time
+	total  int
+	calls  int
+}
+
+func (c *historyClient) GetMessages(_ context.Context, conversationID, _, _ string, limit int, after string) (*MessagesResult, error) {
+	c.calls++
+	start := 0
+	if after != "" {
+		start, _ = strconv.Atoi(after)
+	}
+
+	res := &MessagesResult{}
+	for i := start; i < c.total && len(res.Messages) < limit; i++ {
+		res.Messages = append(res.Messages, entity.Message{
+			ID:             fmt.Sprintf("m%d", i),
+			ConversationID: conversationID,
+			Timestamp:      c.newest.Add(-time.Duration(i) * time.Minute),
+		})
+	}
+	if next := start + len(res.Messages); next < c.total {
+		res.HasMore = true
+		res.NextCursor = strconv.Itoa(next)
+	}
+	return res, nil
+}
+
+// memMessageRepo stores upserted messages by ID
+type memMessageRepo struct {
+	MessageRepository
+	mu   sync.Mutex
+	msgs map[string]entity.Message
+}
+
+func (r *memMessageRepo) UpsertBatch(_ context.Context, msgs []entity.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range msgs {
+		r.msgs[m.ID] = m
+	}
+	return nil
+}
+
+// memConvSyncRepo keeps the last stored status
+type memConvSyncRepo struct {
+	ConversationSyncRepository
+	status *ConversationSyncStatus
+}
+
+func (r *memConvSyncRepo) GetSyncStatus(_ context.Context, _ string) (*ConversationSyncStatus, error) {
+	return r.status, nil
+}
+
+func (r *memConvSyncRepo) UpdateSyncStatus(_ context.Context, status *ConversationSyncStatus) error {
+	r.status = status
+	return nil
+}
+
+// ownConv serves conversation c1 of account 7
+func ownConv() *deleteRepo {
+	return &deleteRepo{conv: &entity.Conversation{ID: "c1", AccountID: "7"}}
+}
+
+func TestSyncMessagesChecksOwnership(t *testing.T) {
+	ig := &historyClient{newest: time.Now(), total: 10}
+	msgs := &memMessageRepo{msgs: map[string]entity.Message{}}
+	svc := NewWithRepo(ig, ownConv(), msgs, &memConvSyncRepo{}, nil)
+
+	for _, tc := range []struct{ account, conv string }{
+		{"8", "c1"}, // another account's conversation
+		{"7", "c2"}, // missing conversation
+	} {
+		_, err := svc.SyncMessages(context.Background(), tc.account, tc.conv, "u", "token")
+		if !errors.Is(err, entity.ErrConversationNotFound) {
+			t.Errorf("SyncMessages(%s, %s) err = %v, want ErrConversationNotFound", tc.account, tc.conv, err)
+		}
+	}
+	if ig.calls != 0 || len(msgs.msgs) != 0 {
+		t.Errorf("made %d API calls and stored %d messages, want none", ig.calls, len(msgs.msgs))
+	}
+}
+
+func TestSyncMessagesStopsAtMaxMessages(t *testing.T) {
+	ig := &historyClient{newest: time.Now().Add(-time.Hour), total: 1000}
+	msgs := &memMessageRepo{msgs: map[string]entity.Message{}}
+	syncRepo := &memConvSyncRepo{}
+	svc := NewWithRepo(ig, ownConv(), msgs, syncRepo, nil).WithMaxMessages(250)
+
+	n, err := svc.SyncMessages(context.Background(), "7", "c1", "u", "token")
+	if err != nil {
+		t.Fatalf("SyncMessages: %v", err)
+	}
+	if n != 250 || len(msgs.msgs) != 250 {
+		t.Errorf("synced %d, stored %d, want 250", n, len(msgs.msgs))
+	}
+	if ig.calls != 3 {
+		t.Errorf("API calls = %d, want 3 (100 + 100 + 50)", ig.calls)
+	}
+	if syncRepo.status.SyncComplete {
+		t.Error("sync marked complete, but older history was not fetched")
+	}
+	wantOldest := ig.newest.Add(-249 * time.Minute)
+	if got := syncRepo.status.OldestMessageTimestamp; got == nil || !got.Equal(wantOldest) {
+		t.Errorf("oldest = %v, want %v", got, wantOldest)
+	}
+}
+
+func TestSyncMessagesStopsAtPreviouslySynced(t *testing.T) {
+	ig := &historyClient{newest: time.Now().Add(-time.Hour), total: 1000}
+	msgs := &memMessageRepo{msgs: map[string]entity.Message{}}
+	svc := NewWithRepo(ig, ownConv(), msgs, &memConvSyncRepo{}, nil)
+
+	// First sync pulls the whole history
+	if _, err := svc.SyncMessages(context.Background(), "7", "c1", "u", "token"); err != nil {
+		t.Fatalf("first sync: %v", err)
+	}
+	if ig.calls != 10 {
+		t.Fatalf("first sync API calls = %d, want 10", ig.calls)
+	}
+
+	// Three new messages arrive; the next sync only needs the first page
+	ig.calls = 0
+	ig.newest = time.Now()
+	ig.total = 1003
+
+	n, err := svc.SyncMessages(context.Background(), "7", "c1", "u", "token")
+	if err != nil {
+		t.Fatalf("second sync: %v", err)
+	}
+	if ig.calls != 1 || n != 100 {
+		t.Errorf("second sync made %d calls for %d messages, want 1 call for 100", ig.calls, n)
+	}
+	if status := svc.convSyncRepo.(*memConvSyncRepo).status; !status.SyncComplete {
+		t.Error("joining a complete sync should keep it complete")
+	}
+}
+
+// inboxClient serves a conversation list, most recently active first, one hour apart, in pages
+// addressed by offset. Fetching the page at failAt fails.
+type inboxClient struct {
+	InstagramClient
+	newest  time.Time
+	total   int
+	failAt  string
+	cursors []string
+}
+
+func (c *inboxClient) GetConversations(_ context.Context, _, _ string, limit int, after string) (*ConversationsResult, error) {
+	c.cursors = append(c.cursors, after)
+	if after != "" && after == c.failAt {
+		return nil, fmt.Errorf("rate limited")
+	}
+	start := 0
+	if after != "" {
+		start, _ = strconv.Atoi(after)
+	}
+
+	res := &ConversationsResult{}
+	for i := start; i < c.total && len(res.Conversations) < limit; i++ {
+		last := c.newest.Add(-time.Duration(i) * time.Hour)
+		res.Conversations = append(res.Conversations, entity.Conversation{
+			ID:            fmt.Sprintf("c%d", i),
+			LastMessageAt: &last,
+		})
+	}
+	if next := start + len(res.Conversations); next < c.total {
+		res.HasMore = true
+		res.NextCursor = strconv.Itoa(next)
+	}
+	return res, nil
+}
+
+// memConvRepo stores upserted conversations by ID
+type memConvRepo struct {
+	ConversationRepository
+	mu    sync.Mutex
+	convs map[string]entity.Conversation
+}
+
+func (r *memConvRepo) UpsertBatch(_ context.Context, convs []entity.Conversation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range convs {
+		r.convs[c.ID] = c
+	}
+	return nil
+}
+
+// memAccountSyncRepo keeps the last stored status
+type memAccountSyncRepo struct {
+	AccountSyncRepository
+	status *AccountSyncStatus
+}
+
+func (r *memAccountSyncRepo) GetSyncStatus(_ context.Context, _ string) (*AccountSyncStatus, error) {
+	return r.status, nil
+}
+
+func (r *memAccountSyncRepo) UpdateSyncStatus(_ context.Context, status *AccountSyncStatus) error {
+	r.status = status
+	return nil
+}
+
+func TestSyncConversationsResumesInterruptedPass(t *testing.T) {
+	ig := &inboxClient{newest: time.Now(), total: 350, failAt: "200"}
+	convs := &memConvRepo{convs: map[string]entity.Conversation{}}
+	syncRepo := &memAccountSyncRepo{}
+	svc := NewWithRepo(ig, convs, nil, nil, syncRepo)
+
+	if _, err := svc.SyncConversations(context.Background(), "a1", "u", "token"); err == nil {
+		t.Fatal("expected the interrupted sync to fail")
+	}
+	if s := syncRepo.status; s == nil || s.SyncComplete || s.NextCursor != "200" {
+		t.Fatalf("status after interruption = %+v, want incomplete with cursor 200", s)
+	}
+
+	ig.failAt = ""
+	ig.cursors = nil
+	n, err := svc.SyncConversations(context.Background(), "a1", "u", "token")
+	if err != nil {
+		t.Fatalf("resumed sync: %v", err)
+	}
+	if n != 150 || ig.cursors[0] != "200" {
+		t.Errorf("resumed sync fetched %d conversations starting at %q, want 150 from 200", n, ig.cursors[0])
+	}
+	if len(convs.convs) != 350 {
+		t.Errorf("stored %d conversations, want 350", len(convs.convs))
+	}
+	if s := syncRepo.status; !s.SyncComplete || s.NextCursor != "" || s.LastFullSyncAt == nil {
+		t.Errorf("status after resumed sync = %+v, want a complete full sync", s)
+	}
+}
+
+func TestSyncConversationsStopsAtUnchangedBetweenFullPasses(t *testing.T) {
+	ig := &inboxClient{newest: time.Now(), total: 1000}
+	convs := &memConvRepo{convs: map[string]entity.Conversation{}}
+	svc := NewWithRepo(ig, convs, nil, nil, &memAccountSyncRepo{})
+
+	// First sync pages through the whole list
+	if _, err := svc.SyncConversations(context.Background(), "a1", "u", "token"); err != nil {
+		t.Fatalf("first sync: %v", err)
+	}
+	if len(ig.cursors) != 10 {
+		t.Fatalf("first sync API calls = %d, want 10", len(ig.cursors))
+	}
+
+	// Recently active conversations all fit on the first page
+	ig.cursors = nil
+	if _, err := svc.SyncConversations(context.Background(), "a1", "u", "token"); err != nil {
+		t.Fatalf("second sync: %v", err)
+	}
+	if len(ig.cursors) != 1 {
+		t.Errorf("second sync API calls = %d, want 1", len(ig.cursors))
+	}
+
+	// Once the full sync interval is over the whole list is fetched again
+	svc.WithFullSyncInterval(0)
+	ig.cursors = nil
+	if _, err := svc.SyncConversations(context.Background(), "a1", "u", "token"); err != nil {
+		t.Fatalf("third sync: %v", err)
+	}
+	if len(ig.cursors) != 10 {
+		t.Errorf("third sync API calls = %d, want 10", len(ig.cursors))
+	}
+}
+
+// endlessClient always reports another page of messages, optionally empty ones
+type endlessClient struct {
+	InstagramClient
+	empty bool
+	calls int
+}
+
+func (c *endlessClient) GetMessages(_ context.Context, conversationID, _, _ string, limit int, _ string) (*MessagesResult, error) {
+	c.calls++
+	res := &MessagesResult{HasMore: true, NextCursor: strconv.Itoa(c.calls)}
+	if c.empty {
+		return res, nil
+	}
+	newest := time.Now().Add(-time.Duration(c.calls*limit) * time.Minute)
+	for i := 0; i < limit; i++ {
+		res.Messages = append(res.Messages, entity.Message{
+			ID:             fmt.Sprintf("m%d-%d", c.calls, i),
+			ConversationID: conversationID,
+			Timestamp:      newest.Add(-time.Duration(i) * time.Minute),
+		})
+	}
+	return res, nil
+}
+
+func TestSyncMessagesStopsAtMaxPages(t *testing.T) {
+	ig := &endlessClient{}
+	syncRepo := &memConvSyncRepo{}
+	svc := NewWithRepo(ig, ownConv(), &memMessageRepo{msgs: map[string]entity.Message{}}, syncRepo, nil).WithMaxMessagePages(7)
+
+	n, err := svc.SyncMessages(context.Background(), "7", "c1", "u", "token")
+	if err != nil {
+		t.Fatalf("SyncMessages: %v", err)
+	}
+	if ig.calls != 7 || n != 700 {
+		t.Errorf("made %d calls for %d messages, want 7 calls for 700", ig.calls, n)
+	}
+	if syncRepo.status.SyncComplete {
+		t.Error("sync marked complete, but pagination did not end")
+	}
+}
+
+func TestSyncMessagesStopsAfterEmptyPages(t *testing.T) {
+	ig := &endlessClient{empty: true}
+	svc := NewWithRepo(ig, ownConv(), &memMessageRepo{msgs: map[string]entity.Message{}}, &memConvSyncRepo{}, nil).WithMaxMessagePages(0)
+
+	if _, err := svc.SyncMessages(context.Background(), "7", "c1", "u", "token"); err != nil {
+		t.Fatalf("SyncMessages: %v", err)
+	}
+	if ig.calls != 3 {
+		t.Errorf("API calls = %d, want 3", ig.calls)
+	}
+}
+
+func TestGetMessagesChecksOwnership(t *testing.T) {
+	ig := &historyClient{newest: time.Now(), total: 10}
+	msgs := &memMessageRepo{msgs: map[string]entity.Message{}}
+	svc := NewWithRepo(ig, ownConv(), msgs, &memConvSyncRepo{}, nil)
+
+	for _, tc := range []struct{ account, conv string }{
+		{"8", "c1"}, // another account's conversation
+		{"7", "c2"}, // missing conversation
+	} {
+		_, err := svc.GetMessages(context.Background(), GetMessagesInput{AccountID: tc.account, ConversationID: tc.conv, AccessToken: "token"})
+		if !errors.Is(err, entity.ErrConversationNotFound) {
+			t.Errorf("GetMessages(%s, %s) err = %v, want ErrConversationNotFound", tc.account, tc.conv, err)
+		}
+	}
+	if ig.calls != 0 || len(msgs.msgs) != 0 {
+		t.Errorf("made %d API calls and stored %d messages, want none", ig.calls, len(msgs.msgs))
+	}
+}
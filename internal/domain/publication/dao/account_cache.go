@@ -0,0 +1,84 @@
+package dao
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CredentialsLoader loads the access token and Instagram user ID of an account
+type CredentialsLoader interface {
+	GetAccountCredentials(ctx context.Context, accountID string) (token, userID string, err error)
+}
+
+// CredentialsCache keeps account credentials in memory for a short time, so bursts of
+// calls for the same account (a scheduler run, a bulk action) read the database once.
+// Failed lookups are not cached. Invalidate drops an account whose token changed or stopped working.
+type CredentialsCache struct {
+	loader CredentialsLoader
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]credentialsEntry
+}
+
+type credentialsEntry struct {
+	token     string
+	userID    string
+	expiresAt time.Time
+}
+
+// NewCredentialsCache creates a cache in front of loader; a non-positive ttl disables caching
+func NewCredentialsCache(loader CredentialsLoader, ttl time.Duration) *CredentialsCache {
+	return &CredentialsCache{
+		loader:  loader,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]credentialsEntry),
+	}
+}
+
+// GetAccountCredentials returns the cached credentials of an account, loading them when missing or expired
+func (c *CredentialsCache) GetAccountCredentials(ctx context.Context, accountID string) (string, string, error) {
+	if c.ttl <= 0 {
+		return c.loader.GetAccountCredentials(ctx, accountID)
+	}
+
+	c.mu.Lock()
+	e, ok := c.entries[accountID]
+	c.mu.Unlock()
+	if ok && c.now().Before(e.expiresAt) {
+		return e.token, e.userID, nil
+	}
+
+	token, userID, err := c.loader.GetAccountCredentials(ctx, accountID)
+	if err != nil {
+		return "", "", err
+	}
+
+	c.mu.Lock()
+	c.entries[accountID] = credentialsEntry{token: token, userID: userID, expiresAt: c.now().Add(c.ttl)}
+	c.mu.Unlock()
+
+	return token, userID, nil
+}
+
+// GetAccessToken returns the access token of an account
+func (c *CredentialsCache) GetAccessToken(ctx context.Context, accountID string) (string, error) {
+	token, _, err := c.GetAccountCredentials(ctx, accountID)
+	return token, err
+}
+
+// GetInstagramUserID returns the Instagram user ID of an account
+func (c *CredentialsCache) GetInstagramUserID(ctx context.Context, accountID string) (string, error) {
+	_, userID, err := c.GetAccountCredentials(ctx, accountID)
+	return userID, err
+}
+
+// Invalidate drops the cached credentials of an account, e.g. after its token was refreshed or revoked
+func (c *CredentialsCache) Invalidate(accountID string) {
+	c.mu.Lock()
+	delete(c.entries, accountID)
+	c.mu.Unlock()
+}
@@ -0,0 +1,119 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// countingLoader hands out the current token and counts the lookups
+type countingLoader struct {
+	token string
+	err   error
+	calls int
+}
+
+func (l *countingLoader) GetAccountCredentials(_ context.Context, _ string) (string, string, error) {
+	l.calls++
+	if l.err != nil {
+		return "", "", l.err
+	}
+	return l.token, "ig_1", nil
+}
+
+func newTestCache(loader CredentialsLoader, ttl time.Duration) (*CredentialsCache, *time.Time) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	c := NewCredentialsCache(loader, ttl)
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func TestCredentialsCacheHitAndMiss(t *testing.T) {
+	loader := &countingLoader{token: "t1"}
+	c, _ := newTestCache(loader, time.Minute)
+	ctx := context.Background()
+
+	token, userID, err := c.GetAccountCredentials(ctx, "1")
+	if err != nil || token != "t1" || userID != "ig_1" {
+		t.Fatalf("GetAccountCredentials = %q, %q, %v", token, userID, err)
+	}
+	if _, err := c.GetAccessToken(ctx, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetInstagramUserID(ctx, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if loader.calls != 1 {
+		t.Errorf("loader called %d times for one account, want 1", loader.calls)
+	}
+
+	if _, err := c.GetAccessToken(ctx, "2"); err != nil {
+		t.Fatal(err)
+	}
+	if loader.calls != 2 {
+		t.Errorf("loader called %d times for two accounts, want 2", loader.calls)
+	}
+}
+
+func TestCredentialsCacheExpiry(t *testing.T) {
+	loader := &countingLoader{token: "t1"}
+	c, now := newTestCache(loader, time.Minute)
+	ctx := context.Background()
+
+	c.GetAccessToken(ctx, "1")
+	loader.token = "t2"
+
+	*now = now.Add(59 * time.Second)
+	if token, _ := c.GetAccessToken(ctx, "1"); token != "t1" {
+		t.Errorf("token before expiry = %q, want cached t1", token)
+	}
+
+	*now = now.Add(time.Second)
+	if token, _ := c.GetAccessToken(ctx, "1"); token != "t2" {
+		t.Errorf("token after expiry = %q, want reloaded t2", token)
+	}
+	if loader.calls != 2 {
+		t.Errorf("loader called %d times, want 2", loader.calls)
+	}
+}
+
+func TestCredentialsCacheInvalidate(t *testing.T) {
+	loader := &countingLoader{token: "t1"}
+	c, _ := newTestCache(loader, time.Hour)
+	ctx := context.Background()
+
+	c.GetAccessToken(ctx, "1")
+	loader.token = "t2" // Refreshed
+	c.Invalidate("1")
+
+	if token, _ := c.GetAccessToken(ctx, "1"); token != "t2" {
+		t.Errorf("token after invalidation = %q, want t2", token)
+	}
+}
+
+func TestCredentialsCacheDoesNotCacheErrors(t *testing.T) {
+	loader := &countingLoader{err: errors.New("no access token")}
+	c, _ := newTestCache(loader, time.Hour)
+	ctx := context.Background()
+
+	if _, err := c.GetAccessToken(ctx, "1"); err == nil {
+		t.Fatal("expected the lookup error")
+	}
+	loader.err, loader.token = nil, "t1"
+	if token, err := c.GetAccessToken(ctx, "1"); err != nil || token != "t1" {
+		t.Errorf("GetAccessToken after a failed lookup = %q, %v", token, err)
+	}
+}
+
+func TestCredentialsCacheDisabled(t *testing.T) {
+	loader := &countingLoader{token: "t1"}
+	c, _ := newTestCache(loader, 0)
+	ctx := context.Background()
+
+	c.GetAccessToken(ctx, "1")
+	c.GetAccessToken(ctx, "1")
+	if loader.calls != 2 {
+		t.Errorf("loader called %d times with caching disabled, want 2", loader.calls)
+	}
+}
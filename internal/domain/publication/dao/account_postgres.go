@@ -3,6 +3,7 @@ package dao
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -61,6 +62,37 @@ func (r *AccountPostgres) GetInstagramUserID(ctx context.Context, accountID stri
 	return userID, nil
 }
 
+// GetAccountCredentials retrieves the access token and Instagram user ID for an account in one query
+func (r *AccountPostgres) GetAccountCredentials(ctx context.Context, accountID string) (string, string, error) {
+	query := `
+		SELECT iat.access_token, ia.instagram_user_id
+		FROM instagram_accounts ia
+		LEFT JOIN LATERAL (
+			SELECT access_token
+			FROM instagram_access_tokens
+			WHERE instagram_account_id = ia.id
+			ORDER BY updated_at DESC
+			LIMIT 1
+		) iat ON true
+		WHERE ia.id = $1 AND ia.deleted_at IS NULL
+	`
+
+	var token *string
+	var userID string
+	err := r.pool.QueryRow(ctx, query, accountID).Scan(&token, &userID)
+	if err == pgx.ErrNoRows {
+		return "", "", fmt.Errorf("account %s not found", accountID)
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("querying account credentials: %w", err)
+	}
+	if token == nil {
+		return "", "", fmt.Errorf("no access token found for account %s", accountID)
+	}
+
+	return *token, userID, nil
+}
+
 // GetUsername retrieves the Instagram username for an account
 func (r *AccountPostgres) GetUsername(ctx context.Context, accountID string) (string, error) {
 	query := `
@@ -81,6 +113,30 @@ func (r *AccountPostgres) GetUsername(ctx context.Context, accountID string) (st
 	return username, nil
 }
 
+// CommentCacheMaxAge returns the account's override of how long stored comments are served
+// before refreshing them, or 0 when the account uses the global default
+func (r *AccountPostgres) CommentCacheMaxAge(ctx context.Context, accountID string) (time.Duration, error) {
+	query := `
+		SELECT comment_cache_max_age_seconds
+		FROM instagram_accounts
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	var seconds *int
+	err := r.pool.QueryRow(ctx, query, accountID).Scan(&seconds)
+	if err == pgx.ErrNoRows {
+		return 0, fmt.Errorf("account %s not found", accountID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("querying comment cache max age: %w", err)
+	}
+	if seconds == nil {
+		return 0, nil
+	}
+
+	return time.Duration(*seconds) * time.Second, nil
+}
+
 // GetAccountByInstagramID retrieves account info by Instagram ID
 func (r *AccountPostgres) GetAccountByInstagramID(ctx context.Context, instagramID string) (*AccountInfo, error) {
 	query := `
@@ -111,24 +167,84 @@ func (r *AccountPostgres) GetAccountByInstagramID(ctx context.Context, instagram
 
 // AccountInfo represents Instagram account information
 type AccountInfo struct {
-	ID              string
-	InstagramUserID string
-	Username        string
-	AccessToken     string
+	ID                 string
+	InstagramUserID    string
+	Username           string
+	AccessToken        string
+	Name               string
+	ProfilePictureURL  string
+	FollowersCount     int
+	MediaCount         int
+	ProfileRefreshedAt *time.Time
+	TokenStatus        string // Empty until the token has been checked
+	TokenCheckedAt     *time.Time
+}
+
+// Access token check results stored in instagram_accounts.token_status
+const (
+	TokenStatusValid        = "valid"
+	TokenStatusUnauthorized = "unauthorized"
+	TokenStatusMissing      = "missing"
+	TokenStatusError        = "error" // Check failed for a reason unrelated to the token
+)
+
+// AccountProfile represents profile fields fetched from Instagram
+type AccountProfile struct {
+	Username          string
+	Name              string
+	ProfilePictureURL string
+	FollowersCount    int
+	MediaCount        int
+}
+
+// AccountFilter narrows and pages ListAccounts; the zero value lists every active account
+type AccountFilter struct {
+	Query  string   // Case-insensitive substring of the username
+	IDs    []string // Only these accounts; nil means all
+	Limit  int      // 0 means no limit
+	Offset int
+}
+
+// where returns the conditions on instagram_accounts ia matching the filter and their arguments
+func (f AccountFilter) where() (string, []any) {
+	where := "ia.deleted_at IS NULL"
+	var args []any
+	if f.Query != "" {
+		args = append(args, f.Query)
+		where += fmt.Sprintf(" AND position(lower($%d) in lower(ia.username)) > 0", len(args))
+	}
+	if f.IDs != nil {
+		args = append(args, f.IDs)
+		where += fmt.Sprintf(" AND ia.id::text = ANY($%d)", len(args))
+	}
+	return where, args
 }
 
-// ListAccounts returns all active Instagram accounts
-func (r *AccountPostgres) ListAccounts(ctx context.Context) ([]AccountInfo, error) {
+// ListAccounts returns the active Instagram accounts matching the filter, ordered by ID
+func (r *AccountPostgres) ListAccounts(ctx context.Context, filter AccountFilter) ([]AccountInfo, error) {
+	where, args := filter.where()
 	query := `
 		SELECT DISTINCT ON (ia.id)
-			ia.id, ia.instagram_user_id, ia.username, iat.access_token
+			ia.id, ia.instagram_user_id, ia.username, iat.access_token,
+			COALESCE(ia.name, ''), COALESCE(ia.profile_picture_url, ''),
+			COALESCE(ia.followers_count, 0), COALESCE(ia.media_count, 0),
+			ia.profile_refreshed_at,
+			COALESCE(ia.token_status, ''), ia.token_checked_at
 		FROM instagram_accounts ia
 		LEFT JOIN instagram_access_tokens iat ON ia.id = iat.instagram_account_id
-		WHERE ia.deleted_at IS NULL
+		WHERE ` + where + `
 		ORDER BY ia.id, iat.updated_at DESC
 	`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
 
-	rows, err := r.pool.Query(ctx, query)
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("querying accounts: %w", err)
 	}
@@ -138,7 +254,19 @@ func (r *AccountPostgres) ListAccounts(ctx context.Context) ([]AccountInfo, erro
 	for rows.Next() {
 		var info AccountInfo
 		var token *string
-		err := rows.Scan(&info.ID, &info.InstagramUserID, &info.Username, &token)
+		err := rows.Scan(
+			&info.ID,
+			&info.InstagramUserID,
+			&info.Username,
+			&token,
+			&info.Name,
+			&info.ProfilePictureURL,
+			&info.FollowersCount,
+			&info.MediaCount,
+			&info.ProfileRefreshedAt,
+			&info.TokenStatus,
+			&info.TokenCheckedAt,
+		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning account: %w", err)
 		}
@@ -150,3 +278,62 @@ func (r *AccountPostgres) ListAccounts(ctx context.Context) ([]AccountInfo, erro
 
 	return accounts, nil
 }
+
+// CountAccounts returns the number of active accounts matching the filter, ignoring Limit and Offset
+func (r *AccountPostgres) CountAccounts(ctx context.Context, filter AccountFilter) (int64, error) {
+	where, args := filter.where()
+
+	var count int64
+	err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM instagram_accounts ia WHERE "+where, args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("counting accounts: %w", err)
+	}
+
+	return count, nil
+}
+
+// UpdateProfile stores profile fields fetched from Instagram on the account row
+func (r *AccountPostgres) UpdateProfile(ctx context.Context, accountID string, p AccountProfile) error {
+	query := `
+		UPDATE instagram_accounts
+		SET username = COALESCE(NULLIF($2, ''), username),
+			name = $3,
+			profile_picture_url = $4,
+			followers_count = $5,
+			media_count = $6,
+			profile_refreshed_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	tag, err := r.pool.Exec(ctx, query,
+		accountID,
+		p.Username,
+		p.Name,
+		p.ProfilePictureURL,
+		p.FollowersCount,
+		p.MediaCount,
+	)
+	if err != nil {
+		return fmt.Errorf("updating account profile: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("account %s not found", accountID)
+	}
+
+	return nil
+}
+
+// SetTokenStatus records the result of an access token check on the account row
+func (r *AccountPostgres) SetTokenStatus(ctx context.Context, accountID, status string) error {
+	query := `
+		UPDATE instagram_accounts
+		SET token_status = $2, token_checked_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := r.pool.Exec(ctx, query, accountID, status); err != nil {
+		return fmt.Errorf("updating token status: %w", err)
+	}
+
+	return nil
+}
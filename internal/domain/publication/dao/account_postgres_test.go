@@ -0,0 +1,28 @@
+package dao
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAccountFilterWhere(t *testing.T) {
+	tests := []struct {
+		filter AccountFilter
+		want   string
+		args   []any
+	}{
+		{AccountFilter{Limit: 10, Offset: 20}, "ia.deleted_at IS NULL", nil},
+		{AccountFilter{Query: "shop"}, "ia.deleted_at IS NULL AND position(lower($1) in lower(ia.username)) > 0", []any{"shop"}},
+		{
+			AccountFilter{Query: "shop", IDs: []string{"1", "2"}},
+			"ia.deleted_at IS NULL AND position(lower($1) in lower(ia.username)) > 0 AND ia.id::text = ANY($2)",
+			[]any{"shop", []string{"1", "2"}},
+		},
+	}
+	for _, tt := range tests {
+		got, args := tt.filter.where()
+		if got != tt.want || !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("where(%+v) = %q %v, want %q %v", tt.filter, got, args, tt.want, tt.args)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package dao
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/vadim/neo-metric/internal/domain/publication/entity"
@@ -14,14 +15,26 @@ type PublicationFilter struct {
 	Status    *entity.PublicationStatus
 	Year      *int
 	Month     *int
+	Trashed   bool // If true, list only soft-deleted publications instead of excluding them
 }
 
+// ErrInvalidSortColumn is returned by List for a SortBy outside the known columns
+var ErrInvalidSortColumn = errors.New("invalid sort column")
+
 // ListOptions contains pagination and sorting options
 type ListOptions struct {
 	Limit  int
 	Offset int
-	SortBy string // "scheduled_at", "created_at", "updated_at"
+	SortBy string // "scheduled_at", "created_at", "published_at", "updated_at"
 	Desc   bool
+	Keyset bool    // Order by (created_at, id) DESC; SortBy/Desc/Offset are ignored
+	After  *Cursor // Keyset position to continue after (nil for the first page)
+}
+
+// Cursor identifies a position in the (created_at, id) keyset ordering
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
 }
 
 // PublicationRepository defines the interface for publication data access
@@ -30,15 +43,30 @@ type PublicationRepository interface {
 	// Create inserts a new publication into the database
 	Create(ctx context.Context, pub *entity.Publication) error
 
-	// GetByID retrieves a publication by its ID
+	// GetByID retrieves a publication by its ID (trashed publications are excluded)
 	GetByID(ctx context.Context, id string) (*entity.Publication, error)
 
+	// GetTrashedByID retrieves a soft-deleted publication by its ID
+	GetTrashedByID(ctx context.Context, id string) (*entity.Publication, error)
+
+	// GetByInstagramMediaID retrieves a publication by the ID Instagram assigned on publishing
+	GetByInstagramMediaID(ctx context.Context, instagramMediaID string) (*entity.Publication, error)
+
+	// CountComments returns the number of synced comments on an Instagram media
+	CountComments(ctx context.Context, instagramMediaID string) (int64, error)
+
 	// Update updates an existing publication
 	Update(ctx context.Context, pub *entity.Publication) error
 
 	// Delete removes a publication by ID
 	Delete(ctx context.Context, id string) error
 
+	// SoftDelete moves a publication to trash
+	SoftDelete(ctx context.Context, id string, deletedAt time.Time) error
+
+	// Restore moves a publication out of trash
+	Restore(ctx context.Context, id string) error
+
 	// List retrieves publications with optional filtering and pagination
 	List(ctx context.Context, filter PublicationFilter, opts ListOptions) ([]entity.Publication, error)
 
@@ -46,14 +74,53 @@ type PublicationRepository interface {
 	Count(ctx context.Context, filter PublicationFilter) (int64, error)
 
 	// GetScheduledForPublishing retrieves all scheduled publications that are due
-	// (scheduled_at <= now and status = 'scheduled')
+	// (scheduled_at <= now, status = 'scheduled' and any retry delay has passed)
 	GetScheduledForPublishing(ctx context.Context, now time.Time) ([]entity.Publication, error)
 
-	// UpdateStatus updates only the status and related fields
+	// ClaimScheduledForPublishing atomically claims up to limit due publications until claimUntil;
+	// concurrent callers never receive the same publication while the claim holds.
+	// UpdateStatus and SetRetry release the claim.
+	ClaimScheduledForPublishing(ctx context.Context, now, claimUntil time.Time, limit int) ([]entity.Publication, error)
+
+	// EnqueuePublish moves a draft, scheduled or failed publication to 'publishing' for the async
+	// publish worker. It returns false if the publication is in any other state or trashed.
+	EnqueuePublish(ctx context.Context, id string) (bool, error)
+
+	// ClaimQueuedForPublishing claims up to limit publications in 'publishing' until claimUntil,
+	// oldest first, with the same guarantees as ClaimScheduledForPublishing
+	ClaimQueuedForPublishing(ctx context.Context, now, claimUntil time.Time, limit int) ([]entity.Publication, error)
+
+	// ClaimForReconciliation claims up to limit publications in 'publishing' or 'error' that still
+	// hold a container and last changed between changedAfter and changedBefore, oldest first,
+	// with the same guarantees as ClaimScheduledForPublishing
+	ClaimForReconciliation(ctx context.Context, now, claimUntil time.Time, limit int, changedBefore, changedAfter time.Time) ([]entity.Publication, error)
+
+	// GetPublishedTimesSince returns publish times of an account's publications published at or after since, oldest first
+	GetPublishedTimesSince(ctx context.Context, accountID string, since time.Time) ([]time.Time, error)
+
+	// UpdateStatus updates only the status and related fields, clearing pending retry state
 	UpdateStatus(ctx context.Context, id string, status entity.PublicationStatus, errorMsg string) error
 
-	// SetPublished marks a publication as published with Instagram media ID
-	SetPublished(ctx context.Context, id string, instagramMediaID string, publishedAt time.Time) error
+	// SetPublished marks a publication as published with Instagram media ID and permalink and clears its container ID
+	SetPublished(ctx context.Context, id string, instagramMediaID, permalink string, publishedAt time.Time) error
+
+	// GetPublishedWithoutPermalink returns a page of the published publications whose permalink is unknown
+	GetPublishedWithoutPermalink(ctx context.Context, limit, offset int) ([]entity.Publication, error)
+
+	// SetPermalink stores the link to a published publication on Instagram
+	SetPermalink(ctx context.Context, id string, permalink string) error
+
+	// SetMediaInfo stores the media product type and comment setting reported by Instagram
+	SetMediaInfo(ctx context.Context, id string, productType string, commentsEnabled *bool) error
+
+	// SetCommentsEnabled stores whether comments are enabled for a published media
+	SetCommentsEnabled(ctx context.Context, id string, enabled bool) error
+
+	// SetRetry records a failed publish attempt and the time of the next one, keeping the status
+	SetRetry(ctx context.Context, id string, attempts int, nextAttemptAt time.Time, errorMsg string) error
+
+	// SetContainerID stores the Instagram container created for a publication (empty clears it)
+	SetContainerID(ctx context.Context, id string, containerID string) error
 
 	// GetAccountIDByMediaID retrieves the account ID for a publication by its Instagram media ID
 	GetAccountIDByMediaID(ctx context.Context, instagramMediaID string) (string, error)
@@ -76,7 +143,7 @@ type MediaRepository interface {
 	// DeleteByPublicationID removes all media items for a publication
 	DeleteByPublicationID(ctx context.Context, publicationID string) error
 
-	// UpdateOrder updates the order of media items
+	// UpdateOrder sets sort_order of the given media items to their position in mediaIDs
 	UpdateOrder(ctx context.Context, publicationID string, mediaIDs []string) error
 }
 
@@ -89,6 +156,9 @@ type AccountRepository interface {
 	// GetInstagramUserID retrieves the Instagram user ID for an account
 	GetInstagramUserID(ctx context.Context, accountID string) (string, error)
 
+	// GetAccountCredentials retrieves the access token and Instagram user ID for an account
+	GetAccountCredentials(ctx context.Context, accountID string) (token, userID string, err error)
+
 	// GetUsername retrieves the Instagram username for an account
 	GetUsername(ctx context.Context, accountID string) (string, error)
 }
@@ -31,8 +31,8 @@ func (r *MediaPostgres) Create(ctx context.Context, publicationID string, media
 	}
 
 	query := `
-		INSERT INTO publication_media (id, publication_id, url, type, sort_order, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO publication_media (id, publication_id, url, type, sort_order, alt_text, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
 
 	_, err := r.pool.Exec(ctx, query,
@@ -41,6 +41,7 @@ func (r *MediaPostgres) Create(ctx context.Context, publicationID string, media
 		media.URL,
 		media.Type,
 		media.Order,
+		media.AltText,
 		media.CreatedAt,
 	)
 	if err != nil {
@@ -53,7 +54,7 @@ func (r *MediaPostgres) Create(ctx context.Context, publicationID string, media
 // GetByPublicationID retrieves all media items for a publication
 func (r *MediaPostgres) GetByPublicationID(ctx context.Context, publicationID string) ([]entity.MediaItem, error) {
 	query := `
-		SELECT id, url, type, sort_order, created_at
+		SELECT id, url, type, sort_order, COALESCE(alt_text, ''), created_at
 		FROM publication_media
 		WHERE publication_id = $1
 		ORDER BY sort_order ASC
@@ -68,7 +69,7 @@ func (r *MediaPostgres) GetByPublicationID(ctx context.Context, publicationID st
 	var items []entity.MediaItem
 	for rows.Next() {
 		var item entity.MediaItem
-		err := rows.Scan(&item.ID, &item.URL, &item.Type, &item.Order, &item.CreatedAt)
+		err := rows.Scan(&item.ID, &item.URL, &item.Type, &item.Order, &item.AltText, &item.CreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("scanning media row: %w", err)
 		}
@@ -96,10 +97,18 @@ func (r *MediaPostgres) DeleteByPublicationID(ctx context.Context, publicationID
 	return nil
 }
 
-// UpdateOrder updates the order of media items
+// UpdateOrder sets sort_order of the given media items to their position in mediaIDs
+// All items are updated in a single transaction so a failure never leaves a half-applied order.
+// The publication's updated_at moves too, so its ETag changes with the order.
 func (r *MediaPostgres) UpdateOrder(ctx context.Context, publicationID string, mediaIDs []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	for i, id := range mediaIDs {
-		_, err := r.pool.Exec(ctx,
+		_, err := tx.Exec(ctx,
 			"UPDATE publication_media SET sort_order = $1 WHERE id = $2 AND publication_id = $3",
 			i, id, publicationID,
 		)
@@ -107,5 +116,12 @@ func (r *MediaPostgres) UpdateOrder(ctx context.Context, publicationID string, m
 			return fmt.Errorf("updating media order: %w", err)
 		}
 	}
+	if _, err := tx.Exec(ctx, "UPDATE publications SET updated_at = $2 WHERE id = $1", publicationID, time.Now()); err != nil {
+		return fmt.Errorf("touching publication: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing media order: %w", err)
+	}
 	return nil
 }
@@ -25,8 +25,9 @@ func NewPublicationPostgres(pool *pgxpool.Pool) *PublicationPostgres {
 // Create inserts a new publication
 func (r *PublicationPostgres) Create(ctx context.Context, pub *entity.Publication) error {
 	query := `
-		INSERT INTO publications (id, account_id, type, status, caption, reel_options, scheduled_at, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO publications (id, account_id, type, status, caption, reel_options, collaborators, scheduled_at, created_at, updated_at, hashtag_set_id,
+		                          story_options)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	`
 
 	var reelOptionsJSON []byte
@@ -37,6 +38,14 @@ func (r *PublicationPostgres) Create(ctx context.Context, pub *entity.Publicatio
 			return fmt.Errorf("marshaling reel_options: %w", err)
 		}
 	}
+	var storyOptionsJSON []byte
+	if pub.StoryOptions != nil {
+		var err error
+		storyOptionsJSON, err = json.Marshal(pub.StoryOptions)
+		if err != nil {
+			return fmt.Errorf("marshaling story_options: %w", err)
+		}
+	}
 
 	_, err := r.pool.Exec(ctx, query,
 		pub.ID,
@@ -45,9 +54,12 @@ func (r *PublicationPostgres) Create(ctx context.Context, pub *entity.Publicatio
 		pub.Status,
 		pub.Caption,
 		reelOptionsJSON,
+		pub.Collaborators,
 		pub.ScheduledAt,
 		pub.CreatedAt,
 		pub.UpdatedAt,
+		pub.HashtagSetID,
+		storyOptionsJSON,
 	)
 	if err != nil {
 		return fmt.Errorf("inserting publication: %w", err)
@@ -58,33 +70,69 @@ func (r *PublicationPostgres) Create(ctx context.Context, pub *entity.Publicatio
 
 // GetByID retrieves a publication by ID
 func (r *PublicationPostgres) GetByID(ctx context.Context, id string) (*entity.Publication, error) {
+	return r.getOne(ctx, "id = $1 AND deleted_at IS NULL", id)
+}
+
+// GetTrashedByID retrieves a soft-deleted publication by ID
+func (r *PublicationPostgres) GetTrashedByID(ctx context.Context, id string) (*entity.Publication, error) {
+	return r.getOne(ctx, "id = $1 AND deleted_at IS NOT NULL", id)
+}
+
+// GetByInstagramMediaID retrieves a publication by its Instagram media ID
+func (r *PublicationPostgres) GetByInstagramMediaID(ctx context.Context, instagramMediaID string) (*entity.Publication, error) {
+	return r.getOne(ctx, "instagram_media_id = $1 AND deleted_at IS NULL", instagramMediaID)
+}
+
+// CountComments returns the number of synced comments on an Instagram media
+func (r *PublicationPostgres) CountComments(ctx context.Context, instagramMediaID string) (int64, error) {
+	var count int64
+	err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM comments WHERE instagram_media_id = $1", instagramMediaID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("counting comments: %w", err)
+	}
+	return count, nil
+}
+
+// getOne retrieves a single publication matching cond, which refers to arg as $1
+func (r *PublicationPostgres) getOne(ctx context.Context, cond string, arg string) (*entity.Publication, error) {
 	query := `
-		SELECT id, account_id, instagram_media_id, type, status, caption, reel_options,
-		       scheduled_at, published_at, error_message, created_at, updated_at
+		SELECT id, account_id, instagram_media_id, COALESCE(container_id, ''), type, status, caption, reel_options, collaborators,
+		       scheduled_at, published_at, error_message, publish_attempts, next_attempt_at,
+		       created_at, updated_at, deleted_at, COALESCE(media_product_type, ''), comments_enabled, hashtag_set_id,
+		       story_options, COALESCE(permalink, '')
 		FROM publications
-		WHERE id = $1
-	`
+		WHERE ` + cond
 
-	row := r.pool.QueryRow(ctx, query, id)
+	row := r.pool.QueryRow(ctx, query, arg)
 
 	var pub entity.Publication
 	var instagramMediaID, errorMessage *string
-	var reelOptionsJSON []byte
+	var reelOptionsJSON, storyOptionsJSON []byte
 	var scheduledAt, publishedAt *time.Time
 
 	err := row.Scan(
 		&pub.ID,
 		&pub.AccountID,
 		&instagramMediaID,
+		&pub.ContainerID,
 		&pub.Type,
 		&pub.Status,
 		&pub.Caption,
 		&reelOptionsJSON,
+		&pub.Collaborators,
 		&scheduledAt,
 		&publishedAt,
 		&errorMessage,
+		&pub.PublishAttempts,
+		&pub.NextAttemptAt,
 		&pub.CreatedAt,
 		&pub.UpdatedAt,
+		&pub.DeletedAt,
+		&pub.MediaProductType,
+		&pub.CommentsEnabled,
+		&pub.HashtagSetID,
+		&storyOptionsJSON,
+		&pub.Permalink,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -105,6 +153,12 @@ func (r *PublicationPostgres) GetByID(ctx context.Context, id string) (*entity.P
 			return nil, fmt.Errorf("unmarshaling reel_options: %w", err)
 		}
 	}
+	if len(storyOptionsJSON) > 0 {
+		pub.StoryOptions = &entity.StoryOptions{}
+		if err := json.Unmarshal(storyOptionsJSON, pub.StoryOptions); err != nil {
+			return nil, fmt.Errorf("unmarshaling story_options: %w", err)
+		}
+	}
 	pub.ScheduledAt = scheduledAt
 	pub.PublishedAt = publishedAt
 
@@ -115,16 +169,38 @@ func (r *PublicationPostgres) GetByID(ctx context.Context, id string) (*entity.P
 func (r *PublicationPostgres) Update(ctx context.Context, pub *entity.Publication) error {
 	query := `
 		UPDATE publications
-		SET caption = $2, status = $3, scheduled_at = $4, updated_at = $5
+		SET caption = $2, status = $3, scheduled_at = $4, reel_options = $5, collaborators = $6, updated_at = $7,
+		    hashtag_set_id = $8, story_options = $9
 		WHERE id = $1
 	`
 
+	var reelOptionsJSON []byte
+	if pub.ReelOptions != nil {
+		var err error
+		reelOptionsJSON, err = json.Marshal(pub.ReelOptions)
+		if err != nil {
+			return fmt.Errorf("marshaling reel_options: %w", err)
+		}
+	}
+	var storyOptionsJSON []byte
+	if pub.StoryOptions != nil {
+		var err error
+		storyOptionsJSON, err = json.Marshal(pub.StoryOptions)
+		if err != nil {
+			return fmt.Errorf("marshaling story_options: %w", err)
+		}
+	}
+
 	_, err := r.pool.Exec(ctx, query,
 		pub.ID,
 		pub.Caption,
 		pub.Status,
 		pub.ScheduledAt,
+		reelOptionsJSON,
+		pub.Collaborators,
 		time.Now(),
+		pub.HashtagSetID,
+		storyOptionsJSON,
 	)
 	if err != nil {
 		return fmt.Errorf("updating publication: %w", err)
@@ -142,17 +218,49 @@ func (r *PublicationPostgres) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// SoftDelete moves a publication to trash
+func (r *PublicationPostgres) SoftDelete(ctx context.Context, id string, deletedAt time.Time) error {
+	_, err := r.pool.Exec(ctx,
+		"UPDATE publications SET deleted_at = $2, updated_at = $2 WHERE id = $1 AND deleted_at IS NULL",
+		id, deletedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("soft-deleting publication: %w", err)
+	}
+	return nil
+}
+
+// Restore moves a publication out of trash
+func (r *PublicationPostgres) Restore(ctx context.Context, id string) error {
+	_, err := r.pool.Exec(ctx,
+		"UPDATE publications SET deleted_at = NULL, updated_at = $2 WHERE id = $1 AND deleted_at IS NOT NULL",
+		id, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("restoring publication: %w", err)
+	}
+	return nil
+}
+
 // List retrieves publications with filtering
 func (r *PublicationPostgres) List(ctx context.Context, filter PublicationFilter, opts ListOptions) ([]entity.Publication, error) {
 	query := `
-		SELECT id, account_id, instagram_media_id, type, status, caption, reel_options,
-		       scheduled_at, published_at, error_message, created_at, updated_at
+		SELECT id, account_id, instagram_media_id, type, status, caption, reel_options, collaborators,
+		       scheduled_at, published_at, error_message, created_at, updated_at, deleted_at,
+		       COALESCE(media_product_type, ''), comments_enabled, hashtag_set_id, story_options,
+		       COALESCE(permalink, '')
 		FROM publications
 		WHERE 1=1
 	`
 	args := []interface{}{}
 	argNum := 1
 
+	if filter.Trashed {
+		query += " AND deleted_at IS NOT NULL"
+	} else {
+		query += " AND deleted_at IS NULL"
+	}
+
 	if filter.AccountID != "" {
 		query += fmt.Sprintf(" AND account_id = $%d", argNum)
 		args = append(args, filter.AccountID)
@@ -180,16 +288,26 @@ func (r *PublicationPostgres) List(ctx context.Context, filter PublicationFilter
 		argNum++
 	}
 
-	// Sorting
-	sortCol := "created_at"
-	if opts.SortBy != "" {
-		sortCol = opts.SortBy
+	// Keyset pagination: newest first, id breaks ties between equal timestamps
+	if opts.Keyset {
+		if opts.After != nil {
+			query += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", argNum, argNum+1)
+			args = append(args, opts.After.CreatedAt, opts.After.ID)
+			argNum += 2
+		}
+		query += " ORDER BY created_at DESC, id DESC"
+		if opts.Limit > 0 {
+			query += fmt.Sprintf(" LIMIT $%d", argNum)
+			args = append(args, opts.Limit)
+		}
+		return r.queryList(ctx, query, args)
 	}
-	order := "DESC"
-	if !opts.Desc {
-		order = "ASC"
+
+	orderBy, err := listOrderBy(opts)
+	if err != nil {
+		return nil, err
 	}
-	query += fmt.Sprintf(" ORDER BY %s %s", sortCol, order)
+	query += orderBy
 
 	// Pagination
 	if opts.Limit > 0 {
@@ -202,6 +320,37 @@ func (r *PublicationPostgres) List(ctx context.Context, filter PublicationFilter
 		args = append(args, opts.Offset)
 	}
 
+	return r.queryList(ctx, query, args)
+}
+
+// publicationSortColumns are the columns List may sort by; SortBy is interpolated into
+// the query, so anything else is rejected rather than passed to the database
+var publicationSortColumns = map[string]bool{
+	"scheduled_at": true,
+	"created_at":   true,
+	"published_at": true,
+	"updated_at":   true,
+}
+
+// listOrderBy builds the ORDER BY clause of an offset-paginated List
+func listOrderBy(opts ListOptions) (string, error) {
+	sortCol := "created_at"
+	if opts.SortBy != "" {
+		if !publicationSortColumns[opts.SortBy] {
+			return "", fmt.Errorf("%w: %q", ErrInvalidSortColumn, opts.SortBy)
+		}
+		sortCol = opts.SortBy
+	}
+	order := "DESC"
+	if !opts.Desc {
+		order = "ASC"
+	}
+	// Unset scheduled and publish times go last either way; id keeps pages stable on ties
+	return fmt.Sprintf(" ORDER BY %s %s NULLS LAST, id %s", sortCol, order, order), nil
+}
+
+// queryList runs a publication list query and scans the rows
+func (r *PublicationPostgres) queryList(ctx context.Context, query string, args []interface{}) ([]entity.Publication, error) {
 	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("querying publications: %w", err)
@@ -212,7 +361,7 @@ func (r *PublicationPostgres) List(ctx context.Context, filter PublicationFilter
 	for rows.Next() {
 		var pub entity.Publication
 		var instagramMediaID, errorMessage *string
-		var reelOptionsJSON []byte
+		var reelOptionsJSON, storyOptionsJSON []byte
 		var scheduledAt, publishedAt *time.Time
 
 		err := rows.Scan(
@@ -223,11 +372,18 @@ func (r *PublicationPostgres) List(ctx context.Context, filter PublicationFilter
 			&pub.Status,
 			&pub.Caption,
 			&reelOptionsJSON,
+			&pub.Collaborators,
 			&scheduledAt,
 			&publishedAt,
 			&errorMessage,
 			&pub.CreatedAt,
 			&pub.UpdatedAt,
+			&pub.DeletedAt,
+			&pub.MediaProductType,
+			&pub.CommentsEnabled,
+			&pub.HashtagSetID,
+			&storyOptionsJSON,
+			&pub.Permalink,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning row: %w", err)
@@ -243,6 +399,10 @@ func (r *PublicationPostgres) List(ctx context.Context, filter PublicationFilter
 			pub.ReelOptions = &entity.ReelOptions{}
 			_ = json.Unmarshal(reelOptionsJSON, pub.ReelOptions)
 		}
+		if len(storyOptionsJSON) > 0 {
+			pub.StoryOptions = &entity.StoryOptions{}
+			_ = json.Unmarshal(storyOptionsJSON, pub.StoryOptions)
+		}
 		pub.ScheduledAt = scheduledAt
 		pub.PublishedAt = publishedAt
 
@@ -258,6 +418,12 @@ func (r *PublicationPostgres) Count(ctx context.Context, filter PublicationFilte
 	args := []interface{}{}
 	argNum := 1
 
+	if filter.Trashed {
+		query += " AND deleted_at IS NOT NULL"
+	} else {
+		query += " AND deleted_at IS NULL"
+	}
+
 	if filter.AccountID != "" {
 		query += fmt.Sprintf(" AND account_id = $%d", argNum)
 		args = append(args, filter.AccountID)
@@ -285,13 +451,22 @@ func (r *PublicationPostgres) Count(ctx context.Context, filter PublicationFilte
 	return count, nil
 }
 
+// scheduledColumns are the columns read for publications due for publishing
+const scheduledColumns = `id, account_id, instagram_media_id, type, status, caption, reel_options, collaborators,
+		       scheduled_at, published_at, error_message, publish_attempts, created_at, updated_at`
+
+// dueForPublishing matches scheduled publications due at $1 whose retry delay has passed and
+// that no scheduler instance holds a claim on
+const dueForPublishing = `status = 'scheduled' AND scheduled_at <= $1 AND deleted_at IS NULL
+		  AND (next_attempt_at IS NULL OR next_attempt_at <= $1)
+		  AND (claimed_until IS NULL OR claimed_until <= $1)`
+
 // GetScheduledForPublishing retrieves publications due for publishing
 func (r *PublicationPostgres) GetScheduledForPublishing(ctx context.Context, now time.Time) ([]entity.Publication, error) {
 	query := `
-		SELECT id, account_id, instagram_media_id, type, status, caption, reel_options,
-		       scheduled_at, published_at, error_message, created_at, updated_at
+		SELECT ` + scheduledColumns + `
 		FROM publications
-		WHERE status = 'scheduled' AND scheduled_at <= $1
+		WHERE ` + dueForPublishing + `
 		ORDER BY scheduled_at ASC
 	`
 
@@ -301,6 +476,102 @@ func (r *PublicationPostgres) GetScheduledForPublishing(ctx context.Context, now
 	}
 	defer rows.Close()
 
+	return scanScheduled(rows)
+}
+
+// claimQuery atomically claims up to $3 publications matching where until $2, in order
+// (a column of scheduledColumns).
+// Rows locked by a concurrent claim are skipped rather than waited for, so two
+// claimers never receive the same publication.
+func claimQuery(where, order string) string {
+	return `
+		WITH claimed AS (
+			UPDATE publications
+			SET claimed_until = $2
+			WHERE id IN (
+				SELECT id
+				FROM publications
+				WHERE ` + where + `
+				ORDER BY ` + order + `
+				LIMIT $3
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING ` + scheduledColumns + `
+		)
+		SELECT ` + scheduledColumns + `
+		FROM claimed
+		ORDER BY ` + order + `
+	`
+}
+
+var claimScheduledQuery = claimQuery(dueForPublishing, "scheduled_at")
+
+// queuedForPublishing matches publications in the async publish queue that no worker holds a claim on
+const queuedForPublishing = `status = 'publishing' AND deleted_at IS NULL
+		  AND (claimed_until IS NULL OR claimed_until <= $1)`
+
+var claimQueuedQuery = claimQuery(queuedForPublishing, "updated_at")
+
+// ClaimScheduledForPublishing claims up to limit due publications until claimUntil and returns them
+func (r *PublicationPostgres) ClaimScheduledForPublishing(ctx context.Context, now, claimUntil time.Time, limit int) ([]entity.Publication, error) {
+	rows, err := r.pool.Query(ctx, claimScheduledQuery, now, claimUntil, limit)
+	if err != nil {
+		return nil, fmt.Errorf("claiming scheduled publications: %w", err)
+	}
+	defer rows.Close()
+
+	return scanScheduled(rows)
+}
+
+// EnqueuePublish queues a publication for the async publish worker if its status allows publishing
+func (r *PublicationPostgres) EnqueuePublish(ctx context.Context, id string) (bool, error) {
+	query := `
+		UPDATE publications
+		SET status = 'publishing', error_message = NULL, updated_at = $2, publish_attempts = 0,
+		    next_attempt_at = NULL, claimed_until = NULL
+		WHERE id = $1 AND status IN ('draft', 'scheduled', 'error') AND deleted_at IS NULL
+	`
+
+	tag, err := r.pool.Exec(ctx, query, id, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("enqueuing publication: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// ClaimQueuedForPublishing claims up to limit queued publications until claimUntil and returns them
+func (r *PublicationPostgres) ClaimQueuedForPublishing(ctx context.Context, now, claimUntil time.Time, limit int) ([]entity.Publication, error) {
+	rows, err := r.pool.Query(ctx, claimQueuedQuery, now, claimUntil, limit)
+	if err != nil {
+		return nil, fmt.Errorf("claiming queued publications: %w", err)
+	}
+	defer rows.Close()
+
+	return scanScheduled(rows)
+}
+
+// unconfirmedPublishing matches unclaimed publications whose publish may have gone through on
+// Instagram without being recorded: they still hold a container and last changed between $5 and $4
+const unconfirmedPublishing = `status IN ('publishing', 'error') AND container_id IS NOT NULL AND deleted_at IS NULL
+		  AND updated_at <= $4 AND updated_at > $5
+		  AND (claimed_until IS NULL OR claimed_until <= $1)`
+
+var claimUnconfirmedQuery = claimQuery(unconfirmedPublishing, "updated_at")
+
+// ClaimForReconciliation claims up to limit unconfirmed publications until claimUntil and returns them
+func (r *PublicationPostgres) ClaimForReconciliation(ctx context.Context, now, claimUntil time.Time, limit int, changedBefore, changedAfter time.Time) ([]entity.Publication, error) {
+	rows, err := r.pool.Query(ctx, claimUnconfirmedQuery, now, claimUntil, limit, changedBefore, changedAfter)
+	if err != nil {
+		return nil, fmt.Errorf("claiming publications for reconciliation: %w", err)
+	}
+	defer rows.Close()
+
+	return scanScheduled(rows)
+}
+
+// scanScheduled reads rows selected with scheduledColumns
+func scanScheduled(rows pgx.Rows) ([]entity.Publication, error) {
 	var publications []entity.Publication
 	for rows.Next() {
 		var pub entity.Publication
@@ -316,9 +587,11 @@ func (r *PublicationPostgres) GetScheduledForPublishing(ctx context.Context, now
 			&pub.Status,
 			&pub.Caption,
 			&reelOptionsJSON,
+			&pub.Collaborators,
 			&scheduledAt,
 			&publishedAt,
 			&errorMessage,
+			&pub.PublishAttempts,
 			&pub.CreatedAt,
 			&pub.UpdatedAt,
 		)
@@ -345,11 +618,41 @@ func (r *PublicationPostgres) GetScheduledForPublishing(ctx context.Context, now
 	return publications, nil
 }
 
+// GetPublishedTimesSince returns publish times of an account's publications published at or after since, oldest first
+// Trashed publications are included: they stay on Instagram and count towards its publishing limit.
+func (r *PublicationPostgres) GetPublishedTimesSince(ctx context.Context, accountID string, since time.Time) ([]time.Time, error) {
+	query := `
+		SELECT published_at
+		FROM publications
+		WHERE account_id = $1 AND status = 'published' AND published_at >= $2
+		ORDER BY published_at ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, accountID, since)
+	if err != nil {
+		return nil, fmt.Errorf("querying published times: %w", err)
+	}
+	defer rows.Close()
+
+	var times []time.Time
+	for rows.Next() {
+		var t time.Time
+		if err := rows.Scan(&t); err != nil {
+			return nil, fmt.Errorf("scanning published time: %w", err)
+		}
+		times = append(times, t)
+	}
+
+	return times, rows.Err()
+}
+
 // UpdateStatus updates only the status and error message
+// Pending retry state is cleared, so a publication rescheduled later starts with fresh attempts.
 func (r *PublicationPostgres) UpdateStatus(ctx context.Context, id string, status entity.PublicationStatus, errorMsg string) error {
 	query := `
 		UPDATE publications
-		SET status = $2, error_message = $3, updated_at = $4
+		SET status = $2, error_message = $3, updated_at = $4, publish_attempts = 0, next_attempt_at = NULL,
+		    claimed_until = NULL
 		WHERE id = $1
 	`
 
@@ -367,14 +670,15 @@ func (r *PublicationPostgres) UpdateStatus(ctx context.Context, id string, statu
 }
 
 // SetPublished marks a publication as published
-func (r *PublicationPostgres) SetPublished(ctx context.Context, id string, instagramMediaID string, publishedAt time.Time) error {
+func (r *PublicationPostgres) SetPublished(ctx context.Context, id string, instagramMediaID, permalink string, publishedAt time.Time) error {
 	query := `
 		UPDATE publications
-		SET status = 'published', instagram_media_id = $2, published_at = $3, updated_at = $4
+		SET status = 'published', instagram_media_id = $2, permalink = NULLIF($3, ''), published_at = $4, updated_at = $5,
+		    container_id = NULL, publish_attempts = 0, next_attempt_at = NULL
 		WHERE id = $1
 	`
 
-	_, err := r.pool.Exec(ctx, query, id, instagramMediaID, publishedAt, time.Now())
+	_, err := r.pool.Exec(ctx, query, id, instagramMediaID, permalink, publishedAt, time.Now())
 	if err != nil {
 		return fmt.Errorf("setting published: %w", err)
 	}
@@ -382,6 +686,100 @@ func (r *PublicationPostgres) SetPublished(ctx context.Context, id string, insta
 	return nil
 }
 
+// GetPublishedWithoutPermalink returns a page of the published publications with a media ID but no
+// permalink, most recently published first
+func (r *PublicationPostgres) GetPublishedWithoutPermalink(ctx context.Context, limit, offset int) ([]entity.Publication, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, account_id, instagram_media_id
+		FROM publications
+		WHERE status = 'published' AND instagram_media_id IS NOT NULL AND permalink IS NULL AND deleted_at IS NULL
+		ORDER BY published_at DESC NULLS LAST, id
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("querying publications without permalink: %w", err)
+	}
+	defer rows.Close()
+
+	var pubs []entity.Publication
+	for rows.Next() {
+		pub := entity.Publication{Status: entity.PublicationStatusPublished}
+		if err := rows.Scan(&pub.ID, &pub.AccountID, &pub.InstagramMediaID); err != nil {
+			return nil, fmt.Errorf("scanning publication: %w", err)
+		}
+		pubs = append(pubs, pub)
+	}
+	return pubs, rows.Err()
+}
+
+// SetPermalink stores the link to a published publication on Instagram
+func (r *PublicationPostgres) SetPermalink(ctx context.Context, id string, permalink string) error {
+	_, err := r.pool.Exec(ctx,
+		"UPDATE publications SET permalink = NULLIF($2, ''), updated_at = $3 WHERE id = $1",
+		id, permalink, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("setting permalink: %w", err)
+	}
+
+	return nil
+}
+
+// SetMediaInfo stores the media product type and comment setting reported by Instagram
+func (r *PublicationPostgres) SetMediaInfo(ctx context.Context, id string, productType string, commentsEnabled *bool) error {
+	_, err := r.pool.Exec(ctx,
+		"UPDATE publications SET media_product_type = NULLIF($2, ''), comments_enabled = $3, updated_at = $4 WHERE id = $1",
+		id, productType, commentsEnabled, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("setting media info: %w", err)
+	}
+
+	return nil
+}
+
+// SetCommentsEnabled stores whether comments are enabled for a published media
+func (r *PublicationPostgres) SetCommentsEnabled(ctx context.Context, id string, enabled bool) error {
+	_, err := r.pool.Exec(ctx,
+		"UPDATE publications SET comments_enabled = $2, updated_at = $3 WHERE id = $1",
+		id, enabled, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("setting comments enabled: %w", err)
+	}
+
+	return nil
+}
+
+// SetContainerID stores or clears the Instagram container ID of a publication
+func (r *PublicationPostgres) SetContainerID(ctx context.Context, id string, containerID string) error {
+	_, err := r.pool.Exec(ctx,
+		"UPDATE publications SET container_id = NULLIF($2, '') WHERE id = $1",
+		id, containerID,
+	)
+	if err != nil {
+		return fmt.Errorf("setting container id: %w", err)
+	}
+
+	return nil
+}
+
+// SetRetry records a failed publish attempt and when to try again; the status is left unchanged
+func (r *PublicationPostgres) SetRetry(ctx context.Context, id string, attempts int, nextAttemptAt time.Time, errorMsg string) error {
+	query := `
+		UPDATE publications
+		SET publish_attempts = $2, next_attempt_at = $3, error_message = $4, updated_at = $5, claimed_until = NULL
+		WHERE id = $1
+	`
+
+	_, err := r.pool.Exec(ctx, query, id, attempts, nextAttemptAt, errorMsg, time.Now())
+	if err != nil {
+		return fmt.Errorf("setting retry: %w", err)
+	}
+
+	return nil
+}
+
 // GetAccountIDByMediaID retrieves the account ID for a publication by its Instagram media ID
 func (r *PublicationPostgres) GetAccountIDByMediaID(ctx context.Context, instagramMediaID string) (string, error) {
 	query := `SELECT account_id FROM publications WHERE instagram_media_id = $1`
@@ -389,7 +787,7 @@ func (r *PublicationPostgres) GetAccountIDByMediaID(ctx context.Context, instagr
 	var accountID int64
 	err := r.pool.QueryRow(ctx, query, instagramMediaID).Scan(&accountID)
 	if err == pgx.ErrNoRows {
-		return "", fmt.Errorf("publication not found for media id: %s", instagramMediaID)
+		return "", fmt.Errorf("%w: media id %s", entity.ErrPublicationNotFound, instagramMediaID)
 	}
 	if err != nil {
 		return "", fmt.Errorf("getting account id: %w", err)
@@ -406,7 +804,7 @@ func (r *PublicationPostgres) GetStatistics(ctx context.Context, accountID strin
 			status,
 			COUNT(*) as count
 		FROM publications
-		WHERE account_id = $1
+		WHERE account_id = $1 AND deleted_at IS NULL
 		GROUP BY type, status
 	`
 
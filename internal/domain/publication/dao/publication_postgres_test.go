@@ -0,0 +1,101 @@
+package dao
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestListOrderBy(t *testing.T) {
+	tests := []struct {
+		opts ListOptions
+		want string
+	}{
+		{ListOptions{}, " ORDER BY created_at ASC NULLS LAST, id ASC"},
+		{ListOptions{Desc: true}, " ORDER BY created_at DESC NULLS LAST, id DESC"},
+		{ListOptions{SortBy: "scheduled_at", Desc: true}, " ORDER BY scheduled_at DESC NULLS LAST, id DESC"},
+		{ListOptions{SortBy: "published_at"}, " ORDER BY published_at ASC NULLS LAST, id ASC"},
+	}
+	for _, tt := range tests {
+		got, err := listOrderBy(tt.opts)
+		if err != nil {
+			t.Errorf("listOrderBy(%+v): %v", tt.opts, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("listOrderBy(%+v) = %q, want %q", tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestListOrderByRejectsUnknownColumn(t *testing.T) {
+	for _, sortBy := range []string{
+		"id; DROP TABLE publications",
+		"caption",
+		"created_at DESC, (SELECT 1)",
+		"CREATED_AT",
+	} {
+		got, err := listOrderBy(ListOptions{SortBy: sortBy, Desc: true})
+		if !errors.Is(err, ErrInvalidSortColumn) {
+			t.Errorf("listOrderBy(%q) error = %v, want ErrInvalidSortColumn", sortBy, err)
+		}
+		if strings.Contains(got, sortBy) {
+			t.Errorf("listOrderBy(%q) = %q, leaks the input into the query", sortBy, got)
+		}
+	}
+}
+
+// columnRows is a single-row pgx.Rows that scans values by column name
+type columnRows struct {
+	pgx.Rows
+	columns []string
+	values  map[string]any
+	read    bool
+}
+
+func (r *columnRows) Next() bool {
+	if r.read {
+		return false
+	}
+	r.read = true
+	return true
+}
+
+func (r *columnRows) Scan(dest ...any) error {
+	if len(dest) != len(r.columns) {
+		return fmt.Errorf("scanning %d destinations from %d columns", len(dest), len(r.columns))
+	}
+	for i, col := range r.columns {
+		if v, ok := r.values[col]; ok {
+			reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(v))
+		}
+	}
+	return nil
+}
+
+func (r *columnRows) Err() error { return nil }
+func (r *columnRows) Close()     {}
+
+func TestScanScheduledReadsPublishAttempts(t *testing.T) {
+	var columns []string
+	for _, col := range strings.Split(scheduledColumns, ",") {
+		columns = append(columns, strings.TrimSpace(col))
+	}
+	rows := &columnRows{columns: columns, values: map[string]any{"id": "pub-1", "publish_attempts": 2}}
+
+	pubs, err := scanScheduled(rows)
+	if err != nil {
+		t.Fatalf("scanScheduled: %v", err)
+	}
+	if len(pubs) != 1 || pubs[0].ID != "pub-1" {
+		t.Fatalf("publications = %+v, want pub-1", pubs)
+	}
+	// Deferring a publication over the daily limit passes its attempts on to ScheduleRetry
+	if pubs[0].PublishAttempts != 2 {
+		t.Errorf("publish attempts = %d, want 2", pubs[0].PublishAttempts)
+	}
+}
@@ -8,9 +8,20 @@ var (
 	ErrEmptyAccountID      = errors.New("account ID is required")
 	ErrNoMedia             = errors.New("at least one media item is required")
 	ErrTooManyMediaItems   = errors.New("post cannot have more than 10 media items")
+	ErrTooFewCarouselItems = errors.New("carousel requires at least 2 media items")
 	ErrSingleMediaRequired = errors.New("story and reel require exactly one media item")
 	ErrCaptionTooLong      = errors.New("caption exceeds maximum length of 2200 characters")
 	ErrScheduledTimeInPast = errors.New("scheduled time must be in the future")
+	ErrAltTextTooLong      = errors.New("alt text exceeds maximum length of 1000 characters")
+	ErrAltTextNotSupported = errors.New("alt text is only supported for images")
+	ErrPublishNowScheduled = errors.New("publish_now and scheduled_at cannot be used together")
+	ErrReelOptionsNotReel  = errors.New("reel_options are only supported for reels")
+	ErrCollaboratorsNotPost = errors.New("collaborators are only supported for feed posts; reels use reel_options")
+	ErrTooManyCollaborators = errors.New("at most 3 collaborators can be invited")
+	ErrTooManyHashtags      = errors.New("caption and hashtag set exceed Instagram's limit of 30 hashtags")
+	ErrStoryOptionsNotStory = errors.New("story_options are only supported for stories")
+	ErrInvalidStoryLink     = errors.New("story link must be an absolute https URL")
+	ErrTooManyMentions      = errors.New("a story can mention at most 20 users")
 
 	// Business logic errors
 	ErrPublicationNotFound    = errors.New("publication not found")
@@ -18,11 +29,28 @@ var (
 	ErrPublicationNotDeletable = errors.New("published content cannot be deleted from our system")
 	ErrInvalidPublicationType = errors.New("invalid publication type")
 	ErrInvalidStatus          = errors.New("invalid publication status")
+	ErrInvalidCursor          = errors.New("invalid pagination cursor")
+	ErrInvalidSort            = errors.New("invalid sort_by, use scheduled_at, created_at, published_at or updated_at")
+	ErrInvalidSortOrder       = errors.New("invalid order, use asc or desc")
+	ErrSortWithCursor         = errors.New("sort_by and order cannot be combined with after; cursor pages are ordered by created_at desc")
+	ErrMediaOrderMismatch     = errors.New("media IDs must match the publication's media exactly")
+	ErrPublicationNotPublished = errors.New("publication is not published on Instagram")
+	ErrMediaURLUnusable       = errors.New("media URL cannot be fetched for publishing")
+	ErrHashtagSetNotFound     = errors.New("hashtag set not found")
+	ErrPublicationNotScheduled = errors.New("only scheduled publications can be paused")
+	ErrPublicationNotPaused   = errors.New("publication is not paused")
+	ErrAccountForbidden       = errors.New("not allowed to access this account")
 
 	// Instagram API errors
 	ErrInstagramAPIFailure    = errors.New("instagram API request failed")
 	ErrInstagramRateLimited   = errors.New("instagram API rate limit exceeded")
+	ErrInstagramUnavailable   = errors.New("instagram API is temporarily unavailable")
 	ErrInstagramUnauthorized  = errors.New("instagram access token is invalid or expired")
 	ErrContainerNotReady      = errors.New("media container is not ready for publishing")
-	ErrDailyPublishingLimit   = errors.New("daily publishing limit exceeded (max 25 per day)")
+	ErrContainerFailed        = errors.New("instagram could not process the media")
+	ErrContainerExpired       = errors.New("media container expired")
+	ErrContainerPublished     = errors.New("media container was already published; check the account on Instagram")
+	ErrDailyPublishingLimit   = errors.New("daily publishing limit exceeded for the account (24h window)")
+	ErrStoryStickersRejected  = errors.New("instagram does not allow the story's link or mentions for this account")
+	ErrPublishedMediaNotFound = errors.New("published media could not be identified on the account")
 )
@@ -1,7 +1,11 @@
 package entity
 
 import (
+	"net/url"
 	"time"
+	"unicode/utf8"
+
+	"github.com/vadim/neo-metric/internal/hashtag"
 )
 
 // PublicationType represents the type of Instagram publication
@@ -17,12 +21,47 @@ const (
 type PublicationStatus string
 
 const (
-	PublicationStatusDraft     PublicationStatus = "draft"
-	PublicationStatusScheduled PublicationStatus = "scheduled"
-	PublicationStatusPublished PublicationStatus = "published"
-	PublicationStatusError     PublicationStatus = "error"
+	PublicationStatusDraft      PublicationStatus = "draft"
+	PublicationStatusScheduled  PublicationStatus = "scheduled"
+	PublicationStatusPaused     PublicationStatus = "paused"     // Scheduled, but skipped by the scheduler until resumed; keeps its scheduled time
+	PublicationStatusPublishing PublicationStatus = "publishing" // Queued for or being published by the async publish worker
+	PublicationStatusPublished  PublicationStatus = "published"
+	PublicationStatusError      PublicationStatus = "error"
+)
+
+// PublicationSort is a column publications can be listed by
+type PublicationSort string
+
+const (
+	PublicationSortScheduledAt PublicationSort = "scheduled_at"
+	PublicationSortCreatedAt   PublicationSort = "created_at"
+	PublicationSortPublishedAt PublicationSort = "published_at"
+	PublicationSortUpdatedAt   PublicationSort = "updated_at"
+)
+
+// IsValid returns true if the sort is a known column
+func (s PublicationSort) IsValid() bool {
+	switch s {
+	case PublicationSortScheduledAt, PublicationSortCreatedAt, PublicationSortPublishedAt, PublicationSortUpdatedAt:
+		return true
+	default:
+		return false
+	}
+}
+
+// SortOrder is the direction of a listing
+type SortOrder string
+
+const (
+	SortOrderAsc  SortOrder = "asc"
+	SortOrderDesc SortOrder = "desc"
 )
 
+// IsValid returns true if the order is asc or desc
+func (o SortOrder) IsValid() bool {
+	return o == SortOrderAsc || o == SortOrderDesc
+}
+
 // MediaType represents the type of media file
 type MediaType string
 
@@ -37,9 +76,22 @@ type MediaItem struct {
 	URL       string    `json:"url"`
 	Type      MediaType `json:"type"`
 	Order     int       `json:"order"`
+	AltText   string    `json:"alt_text,omitempty"` // Accessibility description (images only)
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// MaxAltTextLength is the maximum length of alt text for an image, in characters
+const MaxAltTextLength = 1000
+
+// Carousel size limits of the Instagram Graph API; a post with one item is a single media post
+const (
+	MinCarouselItems = 2
+	MaxCarouselItems = 10
+)
+
+// MaxCollaborators is the number of collaborators Instagram lets a post or reel invite
+const MaxCollaborators = 3
+
 // ReelOptions contains optional settings for Reel publishing
 type ReelOptions struct {
 	// ShareToFeed controls whether the reel appears in the profile grid (default: true)
@@ -56,26 +108,60 @@ type ReelOptions struct {
 	CollaboratorUsernames []string `json:"collaborator_usernames,omitempty"`
 }
 
+// MaxStoryMentions is the number of users Instagram lets a single media tag
+const MaxStoryMentions = 20
+
+// StoryOptions contains the interactive elements of a story that the Graph API can set.
+// Instagram only accepts them for accounts it enables them for and rejects the story otherwise;
+// polls and other stickers cannot be added through the API at all.
+type StoryOptions struct {
+	// LinkURL is the target of the story's link sticker
+	LinkURL string `json:"link_url,omitempty"`
+	// Mentions are Instagram usernames mentioned in the story
+	Mentions []string `json:"mentions,omitempty"`
+}
+
 // Publication represents an Instagram publication (post, story, or reel)
 type Publication struct {
 	ID               string            `json:"id"`
 	AccountID        string            `json:"account_id"`
 	InstagramMediaID string            `json:"instagram_media_id,omitempty"` // ID from Instagram after publishing
+	Permalink        string            `json:"permalink,omitempty"`          // Link to the post on Instagram after publishing
+	ContainerID      string            `json:"-"`                            // Unpublished Instagram container kept for retries (loaded by ID only)
 	Type             PublicationType   `json:"type"`
 	Status           PublicationStatus `json:"status"`
 	Caption          string            `json:"caption"`
 	Media            []MediaItem       `json:"media"`
-	ReelOptions      *ReelOptions      `json:"reel_options,omitempty"` // Optional settings for Reels
+	ReelOptions      *ReelOptions      `json:"reel_options,omitempty"`  // Optional settings for Reels
+	StoryOptions     *StoryOptions     `json:"story_options,omitempty"` // Link sticker and mentions of stories
+	Collaborators    []string          `json:"collaborators,omitempty"` // Usernames invited as collaborators (feed posts)
 	ScheduledAt      *time.Time        `json:"scheduled_at,omitempty"`
 	PublishedAt      *time.Time        `json:"published_at,omitempty"`
 	ErrorMessage     string            `json:"error_message,omitempty"`
+	PublishAttempts  int               `json:"publish_attempts,omitempty"` // Failed scheduled publish attempts awaiting retry
+	NextAttemptAt    *time.Time        `json:"next_attempt_at,omitempty"`  // Scheduler retries the publication no earlier than this
 	CreatedAt        time.Time         `json:"created_at"`
 	UpdatedAt        time.Time         `json:"updated_at"`
+	DeletedAt        *time.Time        `json:"deleted_at,omitempty"`         // Set while the publication is in trash
+	MediaProductType string            `json:"media_product_type,omitempty"` // FEED, STORY or REELS as reported by Instagram
+	CommentsEnabled  *bool             `json:"comments_enabled,omitempty"`   // Comment setting reported by Instagram, nil if unknown
+	HashtagSetID     *string           `json:"hashtag_set_id,omitempty"`     // Hashtag set template posted as the first comment after publishing
+}
+
+// PublicationWithComments is a publication with the number of its synced comments
+type PublicationWithComments struct {
+	Publication
+	CommentsCount int64 `json:"comments_count"`
+}
+
+// IsTrashed returns true if the publication was soft-deleted
+func (p *Publication) IsTrashed() bool {
+	return p.DeletedAt != nil
 }
 
 // IsEditable returns true if the publication can be edited
 func (p *Publication) IsEditable() bool {
-	return p.Status == PublicationStatusDraft || p.Status == PublicationStatusScheduled
+	return p.Status == PublicationStatusDraft || p.Status == PublicationStatusScheduled || p.Status == PublicationStatusPaused
 }
 
 // IsDeletable returns true if the publication can be deleted
@@ -106,7 +192,7 @@ func (p *Publication) Validate() error {
 	// Validate media count based on publication type
 	switch p.Type {
 	case PublicationTypePost:
-		if len(p.Media) > 10 {
+		if len(p.Media) > MaxCarouselItems {
 			return ErrTooManyMediaItems
 		}
 	case PublicationTypeStory, PublicationTypeReel:
@@ -115,11 +201,57 @@ func (p *Publication) Validate() error {
 		}
 	}
 
+	if p.ReelOptions != nil && p.Type != PublicationTypeReel {
+		return ErrReelOptionsNotReel
+	}
+
+	if p.StoryOptions != nil {
+		if p.Type != PublicationTypeStory {
+			return ErrStoryOptionsNotStory
+		}
+		if link := p.StoryOptions.LinkURL; link != "" {
+			u, err := url.Parse(link)
+			if err != nil || u.Scheme != "https" || u.Host == "" {
+				return ErrInvalidStoryLink
+			}
+		}
+		if len(p.StoryOptions.Mentions) > MaxStoryMentions {
+			return ErrTooManyMentions
+		}
+	}
+
+	// Reels invite collaborators through their reel options, stories can't have any
+	if len(p.Collaborators) > 0 && p.Type != PublicationTypePost {
+		return ErrCollaboratorsNotPost
+	}
+	if len(p.Collaborators) > MaxCollaborators ||
+		(p.ReelOptions != nil && len(p.ReelOptions.CollaboratorUsernames) > MaxCollaborators) {
+		return ErrTooManyCollaborators
+	}
+
+	// Validate alt text (only supported for images)
+	for _, m := range p.Media {
+		if m.AltText == "" {
+			continue
+		}
+		if m.Type != MediaTypeImage {
+			return ErrAltTextNotSupported
+		}
+		if utf8.RuneCountInString(m.AltText) > MaxAltTextLength {
+			return ErrAltTextTooLong
+		}
+	}
+
 	// Validate caption length (Instagram limit is 2200, but spec says 1100)
 	if len(p.Caption) > 2200 {
 		return ErrCaptionTooLong
 	}
 
+	// A hashtag set adds its tags to the caption's; the policy checks the sum once it resolves the set
+	if hashtag.Count(p.Caption) > hashtag.MaxPerPost {
+		return ErrTooManyHashtags
+	}
+
 	// Validate scheduled time is in the future
 	if p.Status == PublicationStatusScheduled && p.ScheduledAt != nil {
 		if p.ScheduledAt.Before(time.Now()) {
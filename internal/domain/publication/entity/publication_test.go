@@ -0,0 +1,131 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidatePostMediaCount(t *testing.T) {
+	tests := []struct {
+		items   int
+		wantErr error
+	}{
+		{items: 0, wantErr: ErrNoMedia},
+		{items: 1},
+		{items: 2},
+		{items: MaxCarouselItems},
+		{items: MaxCarouselItems + 1, wantErr: ErrTooManyMediaItems},
+	}
+
+	for _, tt := range tests {
+		p := &Publication{
+			AccountID: "acc_1",
+			Type:      PublicationTypePost,
+			Status:    PublicationStatusDraft,
+			Media:     make([]MediaItem, tt.items),
+		}
+		for i := range p.Media {
+			p.Media[i] = MediaItem{URL: "https://cdn.example.com/a.jpg", Type: MediaTypeImage}
+		}
+
+		if err := p.Validate(); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%d items: err = %v, want %v", tt.items, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateCollaborators(t *testing.T) {
+	tests := []struct {
+		name          string
+		pubType       PublicationType
+		collaborators []string
+		reelOptions   *ReelOptions
+		wantErr       error
+	}{
+		{name: "post", pubType: PublicationTypePost, collaborators: []string{"a", "b", "c"}},
+		{name: "post over limit", pubType: PublicationTypePost, collaborators: []string{"a", "b", "c", "d"}, wantErr: ErrTooManyCollaborators},
+		{name: "story", pubType: PublicationTypeStory, collaborators: []string{"a"}, wantErr: ErrCollaboratorsNotPost},
+		{name: "reel", pubType: PublicationTypeReel, collaborators: []string{"a"}, wantErr: ErrCollaboratorsNotPost},
+		{name: "reel options over limit", pubType: PublicationTypeReel,
+			reelOptions: &ReelOptions{CollaboratorUsernames: []string{"a", "b", "c", "d"}}, wantErr: ErrTooManyCollaborators},
+	}
+
+	for _, tt := range tests {
+		p := &Publication{
+			AccountID:     "acc_1",
+			Type:          tt.pubType,
+			Status:        PublicationStatusDraft,
+			Media:         []MediaItem{{URL: "https://cdn.example.com/a.mp4", Type: MediaTypeVideo}},
+			Collaborators: tt.collaborators,
+			ReelOptions:   tt.reelOptions,
+		}
+
+		if err := p.Validate(); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateStoryOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		pubType PublicationType
+		opts    StoryOptions
+		wantErr error
+	}{
+		{name: "link and mentions", pubType: PublicationTypeStory,
+			opts: StoryOptions{LinkURL: "https://shop.example.com/sale", Mentions: []string{"brand_a"}}},
+		{name: "post", pubType: PublicationTypePost, opts: StoryOptions{LinkURL: "https://shop.example.com"}, wantErr: ErrStoryOptionsNotStory},
+		{name: "http link", pubType: PublicationTypeStory, opts: StoryOptions{LinkURL: "http://shop.example.com"}, wantErr: ErrInvalidStoryLink},
+		{name: "relative link", pubType: PublicationTypeStory, opts: StoryOptions{LinkURL: "/sale"}, wantErr: ErrInvalidStoryLink},
+		{name: "too many mentions", pubType: PublicationTypeStory,
+			opts: StoryOptions{Mentions: make([]string, MaxStoryMentions+1)}, wantErr: ErrTooManyMentions},
+	}
+
+	for _, tt := range tests {
+		opts := tt.opts
+		p := &Publication{
+			AccountID:    "acc_1",
+			Type:         tt.pubType,
+			Status:       PublicationStatusDraft,
+			Media:        []MediaItem{{URL: "https://cdn.example.com/a.jpg", Type: MediaTypeImage}},
+			StoryOptions: &opts,
+		}
+
+		if err := p.Validate(); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateAltText(t *testing.T) {
+	tests := []struct {
+		name      string
+		mediaType MediaType
+		altText   string
+		wantErr   error
+	}{
+		{name: "image", mediaType: MediaTypeImage, altText: "A red bicycle by the sea"},
+		{name: "image at limit", mediaType: MediaTypeImage, altText: strings.Repeat("a", MaxAltTextLength)},
+		{name: "image over limit", mediaType: MediaTypeImage, altText: strings.Repeat("a", MaxAltTextLength+1), wantErr: ErrAltTextTooLong},
+		{name: "cyrillic at limit", mediaType: MediaTypeImage, altText: strings.Repeat("я", MaxAltTextLength)},
+		{name: "emoji at limit", mediaType: MediaTypeImage, altText: strings.Repeat("🚲", MaxAltTextLength)},
+		{name: "cyrillic over limit", mediaType: MediaTypeImage, altText: strings.Repeat("я", MaxAltTextLength+1), wantErr: ErrAltTextTooLong},
+		{name: "video", mediaType: MediaTypeVideo, altText: "A red bicycle", wantErr: ErrAltTextNotSupported},
+		{name: "video without alt text", mediaType: MediaTypeVideo},
+	}
+
+	for _, tt := range tests {
+		p := &Publication{
+			AccountID: "acc_1",
+			Type:      PublicationTypePost,
+			Status:    PublicationStatusDraft,
+			Media:     []MediaItem{{URL: "https://cdn.example.com/a", Type: tt.mediaType, AltText: tt.altText}},
+		}
+
+		if err := p.Validate(); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
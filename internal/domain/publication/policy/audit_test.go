@@ -0,0 +1,91 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+	"github.com/vadim/neo-metric/internal/domain/publication/service"
+)
+
+type auditCall struct {
+	action    string
+	accountID string
+	targetID  string
+	err       error
+}
+
+// recordingAudit collects audit entries
+type recordingAudit struct {
+	calls []auditCall
+}
+
+func (a *recordingAudit) Record(_ context.Context, action, accountID, targetID string, err error) {
+	a.calls = append(a.calls, auditCall{action, accountID, targetID, err})
+}
+
+// publishingRepo also stores the outcome of a successful publish
+type publishingRepo struct {
+	scheduledRepo
+}
+
+func (r *publishingRepo) SetPublished(_ context.Context, _ string, mediaID, permalink string, publishedAt time.Time) error {
+	r.pub.Status = entity.PublicationStatusPublished
+	r.pub.InstagramMediaID = mediaID
+	r.pub.Permalink = permalink
+	r.pub.PublishedAt = &publishedAt
+	return nil
+}
+
+type succeedingPublisher struct {
+	InstagramPublisher
+}
+
+func (succeedingPublisher) Publish(context.Context, PublishInput) (*PublishOutput, error) {
+	return &PublishOutput{InstagramMediaID: "media_1"}, nil
+}
+
+func TestPublishNowRecordsAuditEntry(t *testing.T) {
+	repo := &publishingRepo{scheduledRepo{pub: entity.Publication{
+		ID:        "pub-1",
+		AccountID: "acc-1",
+		Type:      entity.PublicationTypePost,
+		Status:    entity.PublicationStatusDraft,
+	}}}
+	audit := &recordingAudit{}
+	p := New(service.New(repo, singleImageRepo{}), succeedingPublisher{}, staticAccounts{}).
+		WithAuditRecorder(audit)
+
+	if _, err := p.PublishNow(context.Background(), "pub-1"); err != nil {
+		t.Fatalf("PublishNow: %v", err)
+	}
+
+	want := auditCall{action: "publication.publish", accountID: "acc-1", targetID: "pub-1"}
+	if len(audit.calls) != 1 || audit.calls[0] != want {
+		t.Fatalf("audit = %+v, want [%+v]", audit.calls, want)
+	}
+}
+
+func TestScheduledPublishFailureRecordsAuditEntry(t *testing.T) {
+	publishErr := errors.New("instagram API error: invalid image (code: 9004)")
+	p, _, _ := newRetryPolicy(publishErr, 0)
+	audit := &recordingAudit{}
+	p.WithAuditRecorder(audit)
+
+	if err := p.ProcessScheduledPublications(context.Background()); err != nil {
+		t.Fatalf("ProcessScheduledPublications: %v", err)
+	}
+
+	if len(audit.calls) != 1 {
+		t.Fatalf("got %d audit entries, want 1", len(audit.calls))
+	}
+	call := audit.calls[0]
+	if call.action != "publication.publish" || call.accountID != "acc-1" || call.targetID != "pub-1" {
+		t.Errorf("audit entry = %+v", call)
+	}
+	if !errors.Is(call.err, publishErr) {
+		t.Errorf("audit err = %v, want the publish error", call.err)
+	}
+}
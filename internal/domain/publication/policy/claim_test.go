@@ -0,0 +1,152 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/publication/dao"
+	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+	"github.com/vadim/neo-metric/internal/domain/publication/service"
+)
+
+// claimRepo holds many due publications; claiming is atomic like the locked UPDATE in the DAO
+type claimRepo struct {
+	dao.PublicationRepository
+	mu      sync.Mutex
+	pubs    map[string]*entity.Publication
+	claimed map[string]time.Time
+	claims  map[string]int // Times each publication was handed out by a claim
+}
+
+func newClaimRepo(n int) *claimRepo {
+	r := &claimRepo{
+		pubs:    map[string]*entity.Publication{},
+		claimed: map[string]time.Time{},
+		claims:  map[string]int{},
+	}
+	due := time.Now().Add(-time.Minute)
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("pub-%d", i)
+		r.pubs[id] = &entity.Publication{
+			ID:          id,
+			AccountID:   "acc-1",
+			Type:        entity.PublicationTypePost,
+			Status:      entity.PublicationStatusScheduled,
+			ScheduledAt: &due,
+		}
+	}
+	return r
+}
+
+func (r *claimRepo) ClaimScheduledForPublishing(_ context.Context, now, claimUntil time.Time, limit int) ([]entity.Publication, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var out []entity.Publication
+	for id, pub := range r.pubs {
+		if len(out) == limit {
+			break
+		}
+		if pub.Status != entity.PublicationStatusScheduled || r.claimed[id].After(now) {
+			continue
+		}
+		r.claimed[id] = claimUntil
+		r.claims[id]++
+		out = append(out, *pub)
+	}
+	return out, nil
+}
+
+func (r *claimRepo) GetByID(_ context.Context, id string) (*entity.Publication, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pub := *r.pubs[id]
+	return &pub, nil
+}
+
+func (r *claimRepo) GetPublishedTimesSince(context.Context, string, time.Time) ([]time.Time, error) {
+	return nil, nil
+}
+
+func (r *claimRepo) SetPublished(_ context.Context, id string, mediaID, _ string, publishedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pubs[id].Status = entity.PublicationStatusPublished
+	r.pubs[id].InstagramMediaID = mediaID
+	delete(r.claimed, id)
+	return nil
+}
+
+// countingPublisher records how often each publication is published
+type countingPublisher struct {
+	InstagramPublisher
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (p *countingPublisher) Publish(_ context.Context, in PublishInput) (*PublishOutput, error) {
+	// Give the other scheduler time to run while this publish is in flight
+	time.Sleep(time.Millisecond)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls[in.Publication.ID]++
+	return &PublishOutput{InstagramMediaID: "media_" + in.Publication.ID}, nil
+}
+
+func TestConcurrentSchedulersClaimEachPublicationOnce(t *testing.T) {
+	repo := newClaimRepo(20)
+	ig := &countingPublisher{calls: map[string]int{}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		p := New(service.New(repo, singleImageRepo{}), ig, staticAccounts{})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := p.ProcessScheduledPublications(context.Background()); err != nil {
+				t.Errorf("ProcessScheduledPublications: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for id, pub := range repo.pubs {
+		if repo.claims[id] != 1 {
+			t.Errorf("%s claimed %d times, want 1", id, repo.claims[id])
+		}
+		if ig.calls[id] != 1 {
+			t.Errorf("%s published %d times, want 1", id, ig.calls[id])
+		}
+		if pub.Status != entity.PublicationStatusPublished {
+			t.Errorf("%s status = %s, want published", id, pub.Status)
+		}
+	}
+}
+
+func TestClaimedPublicationSkippedUntilClaimExpires(t *testing.T) {
+	repo := newClaimRepo(1)
+	ig := &countingPublisher{calls: map[string]int{}}
+	p := New(service.New(repo, singleImageRepo{}), ig, staticAccounts{})
+
+	// Another instance holds the claim
+	repo.claimed["pub-0"] = time.Now().Add(time.Minute)
+	if err := p.ProcessScheduledPublications(context.Background()); err != nil {
+		t.Fatalf("ProcessScheduledPublications: %v", err)
+	}
+	if ig.calls["pub-0"] != 0 {
+		t.Fatalf("claimed publication was published by a second scheduler")
+	}
+
+	// The claim expired, e.g. the instance holding it died
+	repo.claimed["pub-0"] = time.Now().Add(-time.Second)
+	if err := p.ProcessScheduledPublications(context.Background()); err != nil {
+		t.Fatalf("ProcessScheduledPublications: %v", err)
+	}
+	if ig.calls["pub-0"] != 1 {
+		t.Errorf("published %d times after the claim expired, want 1", ig.calls["pub-0"])
+	}
+}
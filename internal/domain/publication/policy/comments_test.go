@@ -0,0 +1,96 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+	"github.com/vadim/neo-metric/internal/domain/publication/service"
+)
+
+// commentTogglePublisher records comment setting changes sent to Instagram
+type commentTogglePublisher struct {
+	InstagramPublisher
+	err     error
+	mediaID string
+	enabled *bool
+}
+
+func (p *commentTogglePublisher) SetCommentEnabled(_ context.Context, mediaID, _ string, enabled bool) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.mediaID = mediaID
+	p.enabled = &enabled
+	return nil
+}
+
+// commentsRepo stores the comment setting on the single publication
+type commentsRepo struct {
+	scheduledRepo
+}
+
+func (r *commentsRepo) SetCommentsEnabled(_ context.Context, _ string, enabled bool) error {
+	r.pub.CommentsEnabled = &enabled
+	return nil
+}
+
+func newCommentsPolicy(status entity.PublicationStatus, ig *commentTogglePublisher) (*Policy, *commentsRepo) {
+	repo := &commentsRepo{scheduledRepo{pub: entity.Publication{
+		ID:        "pub-1",
+		AccountID: "acc-1",
+		Type:      entity.PublicationTypePost,
+		Status:    status,
+	}}}
+	if status == entity.PublicationStatusPublished {
+		repo.pub.InstagramMediaID = "media_1"
+	}
+	return New(service.New(repo, singleImageRepo{}), ig, staticAccounts{}), repo
+}
+
+func TestSetCommentsEnabledUpdatesInstagramAndStore(t *testing.T) {
+	ig := &commentTogglePublisher{}
+	p, repo := newCommentsPolicy(entity.PublicationStatusPublished, ig)
+
+	pub, err := p.SetCommentsEnabled(context.Background(), "pub-1", false)
+	if err != nil {
+		t.Fatalf("SetCommentsEnabled: %v", err)
+	}
+	if ig.mediaID != "media_1" || ig.enabled == nil || *ig.enabled {
+		t.Errorf("instagram call = %q/%v, want media_1/false", ig.mediaID, ig.enabled)
+	}
+	if repo.pub.CommentsEnabled == nil || *repo.pub.CommentsEnabled {
+		t.Errorf("stored comments_enabled = %v, want false", repo.pub.CommentsEnabled)
+	}
+	if pub.CommentsEnabled == nil || *pub.CommentsEnabled {
+		t.Errorf("returned comments_enabled = %v, want false", pub.CommentsEnabled)
+	}
+}
+
+func TestSetCommentsEnabledRequiresPublished(t *testing.T) {
+	ig := &commentTogglePublisher{}
+	p, _ := newCommentsPolicy(entity.PublicationStatusDraft, ig)
+
+	_, err := p.SetCommentsEnabled(context.Background(), "pub-1", false)
+	if !errors.Is(err, entity.ErrPublicationNotPublished) {
+		t.Fatalf("err = %v, want ErrPublicationNotPublished", err)
+	}
+	if ig.enabled != nil {
+		t.Error("Instagram must not be called for an unpublished publication")
+	}
+}
+
+func TestSetCommentsEnabledKeepsStateOnInstagramError(t *testing.T) {
+	ig := &commentTogglePublisher{err: fmt.Errorf("%w: code 4", entity.ErrInstagramRateLimited)}
+	p, repo := newCommentsPolicy(entity.PublicationStatusPublished, ig)
+
+	_, err := p.SetCommentsEnabled(context.Background(), "pub-1", false)
+	if !errors.Is(err, entity.ErrInstagramRateLimited) {
+		t.Fatalf("err = %v, want ErrInstagramRateLimited", err)
+	}
+	if repo.pub.CommentsEnabled != nil {
+		t.Errorf("stored comments_enabled = %v, want unchanged", *repo.pub.CommentsEnabled)
+	}
+}
@@ -0,0 +1,71 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+	"github.com/vadim/neo-metric/internal/domain/publication/service"
+)
+
+// creatingRepo stores the created publication so it can be published afterwards
+type creatingRepo struct {
+	publishingRepo
+}
+
+func (r *creatingRepo) Create(_ context.Context, pub *entity.Publication) error {
+	r.pub = *pub
+	return nil
+}
+
+// creatingMediaRepo accepts new media and serves the stored single image
+type creatingMediaRepo struct {
+	singleImageRepo
+}
+
+func (creatingMediaRepo) Create(context.Context, string, *entity.MediaItem) error {
+	return nil
+}
+
+func TestCreatePublicationPublishNow(t *testing.T) {
+	repo := &creatingRepo{}
+	p := New(service.New(repo, creatingMediaRepo{}), succeedingPublisher{}, staticAccounts{})
+
+	out, err := p.CreatePublication(context.Background(), CreatePublicationInput{
+		AccountID:  "acc-1",
+		Type:       entity.PublicationTypePost,
+		Media:      []MediaInput{{URL: "https://cdn.example.com/a.jpg", Type: entity.MediaTypeImage}},
+		PublishNow: true,
+	})
+	if err != nil {
+		t.Fatalf("CreatePublication: %v", err)
+	}
+	if out.Publication.Status != entity.PublicationStatusPublished {
+		t.Errorf("status = %s, want published", out.Publication.Status)
+	}
+	if out.Publication.InstagramMediaID != "media_1" {
+		t.Errorf("instagram_media_id = %q, want media_1", out.Publication.InstagramMediaID)
+	}
+}
+
+func TestCreatePublicationPublishNowRejectsSchedule(t *testing.T) {
+	repo := &creatingRepo{}
+	p := New(service.New(repo, creatingMediaRepo{}), succeedingPublisher{}, staticAccounts{})
+
+	scheduledAt := time.Now().Add(time.Hour)
+	_, err := p.CreatePublication(context.Background(), CreatePublicationInput{
+		AccountID:   "acc-1",
+		Type:        entity.PublicationTypePost,
+		Media:       []MediaInput{{URL: "https://cdn.example.com/a.jpg", Type: entity.MediaTypeImage}},
+		ScheduledAt: &scheduledAt,
+		PublishNow:  true,
+	})
+	if !errors.Is(err, entity.ErrPublishNowScheduled) {
+		t.Fatalf("err = %v, want ErrPublishNowScheduled", err)
+	}
+	if repo.pub.ID != "" {
+		t.Errorf("publication %s was created", repo.pub.ID)
+	}
+}
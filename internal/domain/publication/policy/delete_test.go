@@ -0,0 +1,73 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+	"github.com/vadim/neo-metric/internal/domain/publication/service"
+)
+
+// deletingRepo holds a trashed publication, can fail lookups and records deletes
+type deletingRepo struct {
+	scheduledRepo
+	trashed entity.Publication
+	getErr  error
+	deleted []string
+}
+
+func (r *deletingRepo) GetTrashedByID(_ context.Context, id string) (*entity.Publication, error) {
+	if id != r.trashed.ID {
+		return nil, nil
+	}
+	pub := r.trashed
+	return &pub, nil
+}
+
+func (r *deletingRepo) GetByID(ctx context.Context, id string) (*entity.Publication, error) {
+	if r.getErr != nil {
+		return nil, r.getErr
+	}
+	return r.scheduledRepo.GetByID(ctx, id)
+}
+
+func (r *deletingRepo) Delete(_ context.Context, id string) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+// deletingMediaRepo serves the stored single image and accepts deletes
+type deletingMediaRepo struct {
+	singleImageRepo
+}
+
+func (deletingMediaRepo) DeleteByPublicationID(context.Context, string) error { return nil }
+
+func TestPermanentDeleteOfTrashedPublication(t *testing.T) {
+	repo := &deletingRepo{trashed: entity.Publication{ID: "pub-9", AccountID: "acc-1", Status: entity.PublicationStatusDraft}}
+	p := New(service.New(repo, deletingMediaRepo{}), succeedingPublisher{}, staticAccounts{})
+
+	// GetByID does not find trashed publications, a permanent delete still removes them
+	if err := p.DeletePublication(context.Background(), DeletePublicationInput{ID: "pub-9", Permanent: true}); err != nil {
+		t.Fatalf("DeletePublication: %v", err)
+	}
+	if len(repo.deleted) != 1 {
+		t.Errorf("deletes = %v, want pub-9", repo.deleted)
+	}
+}
+
+func TestPermanentDeleteReturnsLookupError(t *testing.T) {
+	dbErr := errors.New("connection refused")
+	repo := &deletingRepo{getErr: dbErr}
+	p := New(service.New(repo, deletingMediaRepo{}), succeedingPublisher{}, staticAccounts{}).
+		WithAccountAuthorizer(scopedAuthorizer{"acc-1": true})
+
+	err := p.DeletePublication(context.Background(), DeletePublicationInput{ID: "pub-1", Permanent: true})
+	if !errors.Is(err, dbErr) {
+		t.Fatalf("DeletePublication error = %v, want the lookup error", err)
+	}
+	if len(repo.deleted) != 0 {
+		t.Errorf("deleted %v after a failed lookup", repo.deleted)
+	}
+}
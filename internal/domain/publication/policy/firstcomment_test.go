@@ -0,0 +1,140 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+	"github.com/vadim/neo-metric/internal/domain/publication/service"
+)
+
+type hashtagSet struct {
+	accountID string
+	tags      string
+}
+
+// staticHashtagSets serves fixed hashtag sets by ID
+type staticHashtagSets map[string]hashtagSet
+
+func (s staticHashtagSets) GetHashtagSet(_ context.Context, accountID, id string) (string, error) {
+	set, ok := s[id]
+	if !ok || set.accountID != accountID {
+		return "", entity.ErrHashtagSetNotFound
+	}
+	return set.tags, nil
+}
+
+// recordingCommenter remembers the comments posted on media
+type recordingCommenter struct {
+	err      error
+	mediaIDs []string
+	messages []string
+}
+
+func (c *recordingCommenter) CreateComment(_ context.Context, mediaID, _, message string) (string, error) {
+	if c.err != nil {
+		return "", c.err
+	}
+	c.mediaIDs = append(c.mediaIDs, mediaID)
+	c.messages = append(c.messages, message)
+	return "comment_1", nil
+}
+
+var testHashtagSets = staticHashtagSets{
+	"set-1": {accountID: "acc-1", tags: "#sunset #beach #summer"},
+	"set-2": {accountID: "acc-2", tags: "#other"},
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestPublishPostsHashtagSetAsFirstComment(t *testing.T) {
+	repo := &creatingRepo{}
+	commenter := &recordingCommenter{}
+	audit := &recordingAudit{}
+	p := New(service.New(repo, creatingMediaRepo{}), succeedingPublisher{}, staticAccounts{}).
+		WithFirstComment(testHashtagSets, commenter).
+		WithAuditRecorder(audit)
+
+	out, err := p.CreatePublication(context.Background(), CreatePublicationInput{
+		AccountID:    "acc-1",
+		Type:         entity.PublicationTypePost,
+		Caption:      "Golden hour #travel",
+		Media:        []MediaInput{{URL: "https://cdn.example.com/a.jpg", Type: entity.MediaTypeImage}},
+		HashtagSetID: strPtr("set-1"),
+		PublishNow:   true,
+	})
+	if err != nil {
+		t.Fatalf("CreatePublication: %v", err)
+	}
+	if out.Publication.HashtagSetID == nil || *out.Publication.HashtagSetID != "set-1" {
+		t.Errorf("hashtag_set_id = %v, want set-1", out.Publication.HashtagSetID)
+	}
+
+	if len(commenter.messages) != 1 || commenter.mediaIDs[0] != "media_1" || commenter.messages[0] != "#sunset #beach #summer" {
+		t.Fatalf("comments = %v on %v, want the hashtag set on media_1", commenter.messages, commenter.mediaIDs)
+	}
+	want := auditCall{action: "publication.first_comment", accountID: "acc-1", targetID: out.Publication.ID}
+	if !slices.Contains(audit.calls, want) {
+		t.Errorf("audit = %+v, want %+v", audit.calls, want)
+	}
+}
+
+func TestFirstCommentFailureKeepsPublicationPublished(t *testing.T) {
+	repo := &publishingRepo{scheduledRepo{pub: entity.Publication{
+		ID:           "pub-1",
+		AccountID:    "acc-1",
+		Type:         entity.PublicationTypePost,
+		Status:       entity.PublicationStatusDraft,
+		HashtagSetID: strPtr("set-1"),
+	}}}
+	commentErr := errors.New("instagram API error: comments are disabled (code: 10)")
+	audit := &recordingAudit{}
+	p := New(service.New(repo, singleImageRepo{}), succeedingPublisher{}, staticAccounts{}).
+		WithFirstComment(testHashtagSets, &recordingCommenter{err: commentErr}).
+		WithAuditRecorder(audit)
+
+	pub, err := p.PublishNow(context.Background(), "pub-1")
+	if err != nil {
+		t.Fatalf("PublishNow: %v", err)
+	}
+	if pub.Status != entity.PublicationStatusPublished {
+		t.Errorf("status = %s, want published", pub.Status)
+	}
+	want := auditCall{action: "publication.first_comment", accountID: "acc-1", targetID: "pub-1", err: commentErr}
+	if !slices.Contains(audit.calls, want) {
+		t.Errorf("audit = %+v, want the failed first comment", audit.calls)
+	}
+}
+
+func TestCreatePublicationChecksHashtagSet(t *testing.T) {
+	p := New(service.New(&creatingRepo{}, creatingMediaRepo{}), succeedingPublisher{}, staticAccounts{}).
+		WithFirstComment(testHashtagSets, &recordingCommenter{})
+
+	tests := []struct {
+		name    string
+		caption string
+		setID   string
+		want    error
+	}{
+		// 28 caption tags and 3 in the set are one over Instagram's limit
+		{"too many hashtags together", strings.Repeat("#tag ", 28), "set-1", entity.ErrTooManyHashtags},
+		{"set of another account", "", "set-2", entity.ErrHashtagSetNotFound},
+		{"unknown set", "", "missing", entity.ErrHashtagSetNotFound},
+		{"within the limit", strings.Repeat("#tag ", 27), "set-1", nil},
+	}
+	for _, tt := range tests {
+		_, err := p.CreatePublication(context.Background(), CreatePublicationInput{
+			AccountID:    "acc-1",
+			Type:         entity.PublicationTypePost,
+			Caption:      tt.caption,
+			Media:        []MediaInput{{URL: "https://cdn.example.com/a.jpg", Type: entity.MediaTypeImage}},
+			HashtagSetID: strPtr(tt.setID),
+		})
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
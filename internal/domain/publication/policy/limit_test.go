@@ -0,0 +1,131 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+	"github.com/vadim/neo-metric/internal/domain/publication/service"
+)
+
+// publishedInWindow returns n publish times within the last 24h, oldest first
+func publishedInWindow(now time.Time, n int) []time.Time {
+	times := make([]time.Time, n)
+	for i := range times {
+		times[i] = now.Add(-23*time.Hour + time.Duration(i)*time.Minute)
+	}
+	return times
+}
+
+func TestScheduledPublishingAtDailyLimitBoundary(t *testing.T) {
+	now := time.Now()
+
+	// 25th publication of the day still goes out
+	p, repo, ig := newRetryPolicy(errors.New("permanent"), 0)
+	repo.published = publishedInWindow(now, DefaultDailyPublishingLimit-1)
+	if err := p.ProcessScheduledPublications(context.Background()); err != nil {
+		t.Fatalf("ProcessScheduledPublications: %v", err)
+	}
+	if ig.calls != 1 {
+		t.Fatalf("publish calls under limit = %d, want 1", ig.calls)
+	}
+
+	// 26th is deferred until the oldest one leaves the 24h window
+	p, repo, ig = newRetryPolicy(errors.New("permanent"), 1)
+	repo.published = publishedInWindow(now, DefaultDailyPublishingLimit)
+	if err := p.ProcessScheduledPublications(context.Background()); err != nil {
+		t.Fatalf("ProcessScheduledPublications: %v", err)
+	}
+	if ig.calls != 0 {
+		t.Fatalf("publish calls at limit = %d, want 0", ig.calls)
+	}
+	if repo.pub.Status != entity.PublicationStatusScheduled {
+		t.Errorf("status = %s, want scheduled", repo.pub.Status)
+	}
+	if repo.pub.PublishAttempts != 1 {
+		t.Errorf("attempts = %d, want 1 (deferral is not a failed attempt)", repo.pub.PublishAttempts)
+	}
+	want := repo.published[0].Add(24 * time.Hour)
+	if repo.pub.NextAttemptAt == nil || !repo.pub.NextAttemptAt.Equal(want) {
+		t.Errorf("next attempt = %v, want %s", repo.pub.NextAttemptAt, want)
+	}
+}
+
+func TestPublishNowRefusedAtDailyLimit(t *testing.T) {
+	p, repo, ig := newRetryPolicy(errors.New("permanent"), 0)
+	repo.published = publishedInWindow(time.Now(), DefaultDailyPublishingLimit)
+
+	_, err := p.PublishNow(context.Background(), "pub-1")
+	if err != entity.ErrDailyPublishingLimit {
+		t.Fatalf("err = %v, want ErrDailyPublishingLimit", err)
+	}
+	if ig.calls != 0 {
+		t.Errorf("publish calls = %d, want 0", ig.calls)
+	}
+	if repo.pub.Status != entity.PublicationStatusScheduled {
+		t.Errorf("status = %s, want unchanged", repo.pub.Status)
+	}
+}
+
+func TestDailyLimitDisabled(t *testing.T) {
+	p, repo, ig := newRetryPolicy(errors.New("permanent"), 0)
+	p.WithDailyPublishingLimit(0)
+	repo.published = publishedInWindow(time.Now(), DefaultDailyPublishingLimit+5)
+
+	if err := p.ProcessScheduledPublications(context.Background()); err != nil {
+		t.Fatalf("ProcessScheduledPublications: %v", err)
+	}
+	if ig.calls != 1 {
+		t.Errorf("publish calls = %d, want 1", ig.calls)
+	}
+}
+
+// dueRepo serves several due publications
+type dueRepo struct {
+	scheduledRepo
+	due []entity.Publication
+}
+
+func (r *dueRepo) GetScheduledForPublishing(context.Context, time.Time) ([]entity.Publication, error) {
+	return r.due, nil
+}
+
+func TestPreviewAppliesDailyLimit(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	repo := &dueRepo{}
+	for _, id := range []string{"pub-1", "pub-2", "pub-3"} {
+		repo.due = append(repo.due, entity.Publication{
+			ID:          id,
+			AccountID:   "acc-1",
+			Type:        entity.PublicationTypePost,
+			Status:      entity.PublicationStatusScheduled,
+			ScheduledAt: &past,
+		})
+	}
+	// One slot left today: the first due publication takes it, the rest wait
+	repo.published = publishedInWindow(now, DefaultDailyPublishingLimit-1)
+	p := New(service.New(repo, singleImageRepo{}), succeedingPublisher{}, staticAccounts{})
+
+	previews, err := p.PreviewScheduledPublications(context.Background())
+	if err != nil {
+		t.Fatalf("PreviewScheduledPublications: %v", err)
+	}
+	if len(previews) != 3 {
+		t.Fatalf("previews = %d, want 3", len(previews))
+	}
+	if !previews[0].WouldPublish || previews[0].DeferredUntil != nil {
+		t.Errorf("first publication = %+v, want it published", previews[0])
+	}
+	want := repo.published[0].Add(24 * time.Hour)
+	for _, preview := range previews[1:] {
+		if preview.WouldPublish {
+			t.Errorf("%s would publish over the daily limit", preview.Publication.ID)
+		}
+		if preview.DeferredUntil == nil || !preview.DeferredUntil.Equal(want) {
+			t.Errorf("%s deferred until %v, want %s", preview.Publication.ID, preview.DeferredUntil, want)
+		}
+	}
+}
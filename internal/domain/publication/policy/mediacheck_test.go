@@ -0,0 +1,83 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+	"github.com/vadim/neo-metric/internal/domain/publication/service"
+)
+
+// urlChecker rejects the listed URLs and records every checked URL
+type urlChecker struct {
+	bad     map[string]bool
+	checked []string
+}
+
+func (c *urlChecker) Check(_ context.Context, url string) error {
+	c.checked = append(c.checked, url)
+	if c.bad[url] {
+		return fmt.Errorf("%s returned HTTP 404", url)
+	}
+	return nil
+}
+
+func newMediaCheckPolicy(checker *urlChecker) (*Policy, *scheduledRepo) {
+	repo := &scheduledRepo{pub: entity.Publication{
+		ID:        "pub-1",
+		AccountID: "acc-1",
+		Type:      entity.PublicationTypePost,
+		Status:    entity.PublicationStatusDraft,
+	}}
+	p := New(service.New(repo, singleImageRepo{}), &failingPublisher{}, staticAccounts{}).
+		WithMediaURLCheck(checker)
+	return p, repo
+}
+
+func TestScheduleChecksMediaURLs(t *testing.T) {
+	checker := &urlChecker{}
+	p, repo := newMediaCheckPolicy(checker)
+
+	if _, err := p.SchedulePublication(context.Background(), "pub-1", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("SchedulePublication: %v", err)
+	}
+	if len(checker.checked) != 1 || checker.checked[0] != "https://cdn.example.com/a.jpg" {
+		t.Errorf("checked = %v, want the publication's media URL", checker.checked)
+	}
+	if repo.pub.Status != entity.PublicationStatusScheduled {
+		t.Errorf("status = %s, want scheduled", repo.pub.Status)
+	}
+}
+
+func TestScheduleRejectsUnusableMediaURL(t *testing.T) {
+	checker := &urlChecker{bad: map[string]bool{"https://cdn.example.com/a.jpg": true}}
+	p, repo := newMediaCheckPolicy(checker)
+
+	_, err := p.SchedulePublication(context.Background(), "pub-1", time.Now().Add(time.Hour))
+	if !errors.Is(err, entity.ErrMediaURLUnusable) {
+		t.Fatalf("err = %v, want ErrMediaURLUnusable", err)
+	}
+	if repo.pub.Status != entity.PublicationStatusDraft {
+		t.Errorf("status = %s, want draft to stay unscheduled", repo.pub.Status)
+	}
+}
+
+func TestCreateScheduledChecksReelCover(t *testing.T) {
+	checker := &urlChecker{bad: map[string]bool{"https://cdn.example.com/cover.jpg": true}}
+	p, _ := newMediaCheckPolicy(checker)
+
+	scheduledAt := time.Now().Add(time.Hour)
+	_, err := p.CreatePublication(context.Background(), CreatePublicationInput{
+		AccountID:   "acc-1",
+		Type:        entity.PublicationTypeReel,
+		Media:       []MediaInput{{URL: "https://cdn.example.com/clip.mp4", Type: entity.MediaTypeVideo}},
+		ReelOptions: &entity.ReelOptions{CoverURL: "https://cdn.example.com/cover.jpg"},
+		ScheduledAt: &scheduledAt,
+	})
+	if !errors.Is(err, entity.ErrMediaURLUnusable) {
+		t.Fatalf("err = %v, want ErrMediaURLUnusable", err)
+	}
+}
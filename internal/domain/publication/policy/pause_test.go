@@ -0,0 +1,92 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+)
+
+func TestScheduledSkipsPausedPublication(t *testing.T) {
+	p, repo, ig := newRetryPolicy(entity.ErrInstagramUnavailable, 0)
+	ctx := context.Background()
+
+	if _, err := p.PausePublication(ctx, "pub-1"); err != nil {
+		t.Fatalf("PausePublication: %v", err)
+	}
+	if err := p.ProcessScheduledPublications(ctx); err != nil {
+		t.Fatalf("ProcessScheduledPublications: %v", err)
+	}
+
+	if ig.calls != 0 {
+		t.Errorf("publish calls = %d, want 0 while paused", ig.calls)
+	}
+	if repo.pub.Status != entity.PublicationStatusPaused {
+		t.Errorf("status = %s, want paused", repo.pub.Status)
+	}
+	if repo.pub.ScheduledAt == nil {
+		t.Error("pausing cleared the scheduled time")
+	}
+}
+
+func TestResumeRestoresSchedule(t *testing.T) {
+	p, repo, ig := newRetryPolicy(entity.ErrInstagramUnavailable, 0)
+	ctx := context.Background()
+	future := time.Now().Add(time.Hour)
+	repo.pub.ScheduledAt = &future
+
+	if _, err := p.PausePublication(ctx, "pub-1"); err != nil {
+		t.Fatalf("PausePublication: %v", err)
+	}
+	pub, err := p.ResumePublication(ctx, "pub-1")
+	if err != nil {
+		t.Fatalf("ResumePublication: %v", err)
+	}
+	if pub.Status != entity.PublicationStatusScheduled || repo.pub.Status != entity.PublicationStatusScheduled {
+		t.Errorf("status = %s, want scheduled", repo.pub.Status)
+	}
+	if !repo.pub.ScheduledAt.Equal(future) {
+		t.Errorf("scheduled at = %v, want %v", repo.pub.ScheduledAt, future)
+	}
+
+	// Due again once the scheduled time passes
+	past := time.Now().Add(-time.Minute)
+	repo.pub.ScheduledAt = &past
+	if err := p.ProcessScheduledPublications(ctx); err != nil {
+		t.Fatalf("ProcessScheduledPublications: %v", err)
+	}
+	if ig.calls != 1 {
+		t.Errorf("publish calls = %d, want 1 after resuming", ig.calls)
+	}
+}
+
+func TestResumeRejectsPastScheduledTime(t *testing.T) {
+	p, repo, _ := newRetryPolicy(entity.ErrInstagramUnavailable, 0)
+	ctx := context.Background()
+
+	if _, err := p.PausePublication(ctx, "pub-1"); err != nil {
+		t.Fatalf("PausePublication: %v", err)
+	}
+	if _, err := p.ResumePublication(ctx, "pub-1"); !errors.Is(err, entity.ErrScheduledTimeInPast) {
+		t.Fatalf("ResumePublication error = %v, want ErrScheduledTimeInPast", err)
+	}
+	if repo.pub.Status != entity.PublicationStatusPaused {
+		t.Errorf("status = %s, want paused", repo.pub.Status)
+	}
+}
+
+func TestPauseAndResumeRequireStatus(t *testing.T) {
+	p, repo, _ := newRetryPolicy(entity.ErrInstagramUnavailable, 0)
+	ctx := context.Background()
+
+	if _, err := p.ResumePublication(ctx, "pub-1"); !errors.Is(err, entity.ErrPublicationNotPaused) {
+		t.Errorf("ResumePublication of a scheduled publication error = %v, want ErrPublicationNotPaused", err)
+	}
+
+	repo.pub.Status = entity.PublicationStatusDraft
+	if _, err := p.PausePublication(ctx, "pub-1"); !errors.Is(err, entity.ErrPublicationNotScheduled) {
+		t.Errorf("PausePublication of a draft error = %v, want ErrPublicationNotScheduled", err)
+	}
+}
@@ -0,0 +1,111 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vadim/neo-metric/internal/domain/publication/dao"
+	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+	"github.com/vadim/neo-metric/internal/domain/publication/service"
+)
+
+// permalinkPublisher publishes as media_1 and knows the permalinks of some media
+type permalinkPublisher struct {
+	InstagramPublisher
+	permalinks map[string]string
+}
+
+func (permalinkPublisher) Publish(context.Context, PublishInput) (*PublishOutput, error) {
+	return &PublishOutput{InstagramMediaID: "media_1", Permalink: "https://www.instagram.com/p/media_1/"}, nil
+}
+
+func (p permalinkPublisher) GetPermalink(_ context.Context, mediaID, _ string) (string, error) {
+	if permalink, ok := p.permalinks[mediaID]; ok {
+		return permalink, nil
+	}
+	return "", errors.New("instagram API error: Object does not exist (code: 100, subcode: 33)")
+}
+
+func TestPublishNowStoresPermalink(t *testing.T) {
+	repo := &publishingRepo{scheduledRepo{pub: entity.Publication{
+		ID:        "pub-1",
+		AccountID: "acc-1",
+		Type:      entity.PublicationTypePost,
+		Status:    entity.PublicationStatusDraft,
+	}}}
+	p := New(service.New(repo, singleImageRepo{}), permalinkPublisher{}, staticAccounts{})
+
+	pub, err := p.PublishNow(context.Background(), "pub-1")
+	if err != nil {
+		t.Fatalf("PublishNow: %v", err)
+	}
+
+	const want = "https://www.instagram.com/p/media_1/"
+	if repo.pub.Permalink != want {
+		t.Errorf("stored permalink = %q, want %q", repo.pub.Permalink, want)
+	}
+	if pub.Permalink != want {
+		t.Errorf("returned permalink = %q, want %q", pub.Permalink, want)
+	}
+}
+
+// backfillRepo holds published publications without permalink
+type backfillRepo struct {
+	dao.PublicationRepository
+	pubs       []entity.Publication
+	permalinks map[string]string
+}
+
+func (r *backfillRepo) GetPublishedWithoutPermalink(_ context.Context, limit, offset int) ([]entity.Publication, error) {
+	var pubs []entity.Publication
+	for _, pub := range r.pubs {
+		if _, ok := r.permalinks[pub.ID]; !ok {
+			pubs = append(pubs, pub)
+		}
+	}
+	pubs = pubs[min(offset, len(pubs)):]
+	return pubs[:min(limit, len(pubs))], nil
+}
+
+func (r *backfillRepo) SetPermalink(_ context.Context, id string, permalink string) error {
+	r.permalinks[id] = permalink
+	return nil
+}
+
+func TestBackfillPermalinks(t *testing.T) {
+	repo := &backfillRepo{
+		pubs: []entity.Publication{
+			{ID: "pub-1", AccountID: "acc-1", InstagramMediaID: "media_1"},
+			{ID: "pub-2", AccountID: "acc-1", InstagramMediaID: "media_deleted"},
+			{ID: "pub-3", AccountID: "acc-1", InstagramMediaID: "media_3"},
+		},
+		permalinks: map[string]string{},
+	}
+	ig := permalinkPublisher{permalinks: map[string]string{
+		"media_1": "https://www.instagram.com/p/one/",
+		"media_3": "https://www.instagram.com/p/three/",
+	}}
+	p := New(service.New(repo, singleImageRepo{}), ig, staticAccounts{})
+
+	out, err := p.BackfillPermalinks(context.Background(), 10, 0)
+	if err != nil {
+		t.Fatalf("BackfillPermalinks: %v", err)
+	}
+
+	if *out != (BackfillPermalinksOutput{Checked: 3, Updated: 2, Failed: 1}) {
+		t.Errorf("out = %+v, want 3 checked, 2 updated, 1 failed", *out)
+	}
+	if repo.permalinks["pub-1"] != "https://www.instagram.com/p/one/" || repo.permalinks["pub-3"] != "https://www.instagram.com/p/three/" {
+		t.Errorf("stored permalinks = %v", repo.permalinks)
+	}
+	if _, ok := repo.permalinks["pub-2"]; ok {
+		t.Errorf("stored a permalink for media deleted on Instagram")
+	}
+
+	// Skipping the publication that failed leaves nothing to do
+	out, err = p.BackfillPermalinks(context.Background(), 10, out.Failed)
+	if err != nil || out.Checked != 0 {
+		t.Errorf("second run = %+v, %v; want nothing checked", out, err)
+	}
+}
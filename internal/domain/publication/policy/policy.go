@@ -2,71 +2,356 @@ package policy
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/vadim/neo-metric/internal/domain/publication/entity"
 	"github.com/vadim/neo-metric/internal/domain/publication/service"
+	"github.com/vadim/neo-metric/internal/hashtag"
 )
 
 // InstagramPublisher defines the interface for Instagram publishing operations
 // This interface is defined here (consumer) not in the upstream package (provider)
 type InstagramPublisher interface {
 	Publish(ctx context.Context, in PublishInput) (*PublishOutput, error)
+	PrepareContainer(ctx context.Context, in PublishInput) (string, error)
 	Delete(ctx context.Context, mediaID, accessToken string) error
+	SetCommentEnabled(ctx context.Context, mediaID, accessToken string, enabled bool) error
+	GetPermalink(ctx context.Context, mediaID, accessToken string) (string, error)
 }
 
 // PublishInput represents input for publishing
+// If Publication.ContainerID is set, the publisher reuses that container when it is still valid.
 type PublishInput struct {
-	UserID      string
-	AccessToken string
-	Publication *entity.Publication
+	UserID             string
+	AccessToken        string
+	Publication        *entity.Publication
+	OnContainerCreated func(containerID string) // Called when a new container is created, before publishing it
 }
 
 // PublishOutput represents output from publishing
 type PublishOutput struct {
 	InstagramMediaID string
 	Permalink        string
+	MediaProductType string
+	CommentsEnabled  *bool
 }
 
 // AccountProvider defines the interface for getting account credentials
 type AccountProvider interface {
 	GetAccessToken(ctx context.Context, accountID string) (string, error)
 	GetInstagramUserID(ctx context.Context, accountID string) (string, error)
+	GetAccountCredentials(ctx context.Context, accountID string) (token, userID string, err error)
 	GetUsername(ctx context.Context, accountID string) (string, error)
 }
 
+// AccountAuthorizer reports whether the caller may act on an account
+type AccountAuthorizer interface {
+	AccountAllowed(ctx context.Context, accountID string) bool
+}
+
+// AuditRecorder records mutating actions for the audit log.
+// Recording is best-effort: implementations handle their own failures.
+type AuditRecorder interface {
+	Record(ctx context.Context, action, accountID, targetID string, err error)
+}
+
+// MediaURLChecker verifies that a media URL serves content Instagram can fetch.
+// The returned error describes the problem; unreachable URLs are up to the implementation.
+type MediaURLChecker interface {
+	Check(ctx context.Context, url string) error
+}
+
+// HashtagSetProvider resolves the hashtag sets publications post as their first comment
+type HashtagSetProvider interface {
+	// GetHashtagSet returns the hashtags of the account's set, or ErrHashtagSetNotFound
+	GetHashtagSet(ctx context.Context, accountID, id string) (string, error)
+}
+
+// Commenter posts comments on published media
+type Commenter interface {
+	CreateComment(ctx context.Context, mediaID, accessToken, message string) (string, error)
+}
+
+// PublishedMediaFinder finds out what became of the container of a publication whose publish
+// outcome was never recorded
+type PublishedMediaFinder interface {
+	// FindPublishedMedia returns the media the container was published as, nil if it is not
+	// published yet, ErrContainerExpired if it never will be, or ErrPublishedMediaNotFound
+	FindPublishedMedia(ctx context.Context, in PublishInput) (*PublishOutput, error)
+}
+
+// Audited actions
+const (
+	auditActionPublish      = "publication.publish"
+	auditActionDelete       = "publication.delete"
+	auditActionFirstComment = "publication.first_comment"
+	auditActionReconcile    = "publication.reconcile"
+)
+
+// RetryPolicy controls how scheduled publications are retried after temporary Instagram failures
+type RetryPolicy struct {
+	MaxAttempts int           // Total publish attempts before the publication is marked as error
+	BaseDelay   time.Duration // Delay after the first failure; doubled after each further failure
+	MaxDelay    time.Duration // Upper bound for the delay (0 = no bound)
+}
+
+// DefaultRetryPolicy is used unless WithRetry is called
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   time.Minute,
+	MaxDelay:    time.Hour,
+}
+
+// Delay returns how long to wait after the given number of failed attempts
+func (r RetryPolicy) Delay(attempts int) time.Duration {
+	d := r.BaseDelay
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if r.MaxDelay > 0 && d >= r.MaxDelay {
+			return r.MaxDelay
+		}
+	}
+	if r.MaxDelay > 0 && d > r.MaxDelay {
+		return r.MaxDelay
+	}
+	return d
+}
+
+// DefaultDailyPublishingLimit is Instagram's cap on API-published media per account in 24 hours
+const DefaultDailyPublishingLimit = 25
+
+// publishingLimitWindow is the trailing window the daily publishing limit applies to
+const publishingLimitWindow = 24 * time.Hour
+
+// publishClaimLease is how long a scheduler run holds a claimed publication. It outlasts a
+// publish (video containers are polled for up to 2.5 minutes each); if the run dies, the
+// publication becomes due again once the claim expires.
+const publishClaimLease = 30 * time.Minute
+
+// Reconciliation checks publications that last changed between reconcileMinAge and reconcileMaxAge
+// ago: younger ones may still be in a publish, older ones have a container Instagram expired.
+// A publication checked without a result is claimed, and so skipped, for reconcileClaimLease.
+const (
+	reconcileMinAge     = 5 * time.Minute
+	reconcileMaxAge     = 24 * time.Hour
+	reconcileClaimLease = 15 * time.Minute
+)
+
 // Policy orchestrates publication use-cases
 type Policy struct {
-	svc      *service.Service
-	ig       InstagramPublisher
-	accounts AccountProvider
+	svc        *service.Service
+	ig         InstagramPublisher
+	accounts   AccountProvider
+	retry      RetryPolicy
+	dailyLimit int               // Publications per account in the trailing 24h (0 = unlimited)
+	audit      AuditRecorder     // optional
+	mediaCheck MediaURLChecker   // optional, checks media URLs before scheduling
+	authz      AccountAuthorizer // optional, all accounts allowed if unset
+
+	// optional, post the hashtag set of a publication as its first comment
+	hashtagSets HashtagSetProvider
+	commenter   Commenter
+
+	// Schedules closer than this get their container created right away (0 = disabled)
+	precreateWindow time.Duration
+
+	// publish_now on creation queues the publication for ProcessQueuedPublications instead of publishing inline
+	publishAsync bool
+
+	// optional, looks up containers of publications stuck in publishing or error for ReconcilePublications
+	reconciler PublishedMediaFinder
 }
 
 // New creates a new publication policy
 func New(svc *service.Service, ig InstagramPublisher, accounts AccountProvider) *Policy {
 	return &Policy{
-		svc:      svc,
-		ig:       ig,
-		accounts: accounts,
+		svc:        svc,
+		ig:         ig,
+		accounts:   accounts,
+		retry:      DefaultRetryPolicy,
+		dailyLimit: DefaultDailyPublishingLimit,
+	}
+}
+
+// WithRetry sets the retry policy for scheduled publications
+func (p *Policy) WithRetry(r RetryPolicy) *Policy {
+	p.retry = r
+	return p
+}
+
+// WithDailyPublishingLimit sets how many publications an account may publish in the trailing 24h (0 = unlimited)
+// Publishing now beyond the limit is refused; scheduled publications are deferred to the next free slot.
+func (p *Policy) WithDailyPublishingLimit(limit int) *Policy {
+	p.dailyLimit = limit
+	return p
+}
+
+// WithAuditRecorder records publishing and deletion in the audit log
+func (p *Policy) WithAuditRecorder(r AuditRecorder) *Policy {
+	p.audit = r
+	return p
+}
+
+// WithAccountAuthorizer restricts every operation to the accounts the caller may act on.
+// Publications of other accounts are reported as not found.
+func (p *Policy) WithAccountAuthorizer(a AccountAuthorizer) *Policy {
+	p.authz = a
+	return p
+}
+
+// authorize returns ErrAccountForbidden if the caller may not act on the account.
+// An empty account ID stands for all accounts, which only unrestricted callers may act on.
+func (p *Policy) authorize(ctx context.Context, accountID string) error {
+	if p.authz != nil && !p.authz.AccountAllowed(ctx, accountID) {
+		return entity.ErrAccountForbidden
+	}
+	return nil
+}
+
+// owned returns ErrPublicationNotFound for a publication of an account the caller may not act on
+func (p *Policy) owned(ctx context.Context, pub *entity.Publication) error {
+	if p.authz != nil && !p.authz.AccountAllowed(ctx, pub.AccountID) {
+		return entity.ErrPublicationNotFound
+	}
+	return nil
+}
+
+// checkOwner loads the publication to check the caller may act on it; without an authorizer it does nothing
+func (p *Policy) checkOwner(ctx context.Context, id string) error {
+	if p.authz == nil {
+		return nil
+	}
+	pub, err := p.svc.GetPublication(ctx, id)
+	if err != nil {
+		return err
 	}
+	return p.owned(ctx, pub)
+}
+
+// record writes an audit entry if an AuditRecorder is set
+func (p *Policy) record(ctx context.Context, action, accountID, targetID string, err error) {
+	if p.audit != nil {
+		p.audit.Record(ctx, action, accountID, targetID, err)
+	}
+}
+
+// WithMediaURLCheck makes scheduling reject publications whose media URLs cannot be fetched
+func (p *Policy) WithMediaURLCheck(c MediaURLChecker) *Policy {
+	p.mediaCheck = c
+	return p
+}
+
+// checkMediaURLs returns ErrMediaURLUnusable with the reason for the first URL failing the check
+func (p *Policy) checkMediaURLs(ctx context.Context, urls []string) error {
+	if p.mediaCheck == nil {
+		return nil
+	}
+	for _, url := range urls {
+		if err := p.mediaCheck.Check(ctx, url); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("%w: %v", entity.ErrMediaURLUnusable, err)
+		}
+	}
+	return nil
+}
+
+// publicationMediaURLs returns the URLs Instagram fetches when publishing pub
+func publicationMediaURLs(pub *entity.Publication) []string {
+	urls := make([]string, 0, len(pub.Media)+1)
+	for _, m := range pub.Media {
+		urls = append(urls, m.URL)
+	}
+	if pub.ReelOptions != nil && pub.ReelOptions.CoverURL != "" {
+		urls = append(urls, pub.ReelOptions.CoverURL)
+	}
+	return urls
+}
+
+// WithFirstComment lets publications reference a hashtag set, which is posted with c as
+// the first comment once the publication is published
+func (p *Policy) WithFirstComment(sets HashtagSetProvider, c Commenter) *Policy {
+	p.hashtagSets = sets
+	p.commenter = c
+	return p
+}
+
+// checkHashtags returns ErrTooManyHashtags if the caption and the hashtag set together
+// exceed Instagram's hashtag limit, or ErrHashtagSetNotFound if the set cannot be used
+func (p *Policy) checkHashtags(ctx context.Context, accountID, caption string, setID *string) error {
+	if setID == nil {
+		return nil
+	}
+	if p.hashtagSets == nil {
+		return entity.ErrHashtagSetNotFound
+	}
+	tags, err := p.hashtagSets.GetHashtagSet(ctx, accountID, *setID)
+	if err != nil {
+		return err
+	}
+	if hashtag.Count(caption)+hashtag.Count(tags) > hashtag.MaxPerPost {
+		return entity.ErrTooManyHashtags
+	}
+	return nil
+}
+
+// postFirstComment posts the publication's hashtag set as the first comment on its media.
+// The publication is live by then, so a failure is only recorded in the audit log.
+func (p *Policy) postFirstComment(ctx context.Context, pub *entity.Publication, mediaID, accessToken string) {
+	if pub.HashtagSetID == nil || p.hashtagSets == nil || p.commenter == nil {
+		return
+	}
+	tags, err := p.hashtagSets.GetHashtagSet(ctx, pub.AccountID, *pub.HashtagSetID)
+	if err == nil {
+		_, err = p.commenter.CreateComment(ctx, mediaID, accessToken, tags)
+	}
+	p.record(ctx, auditActionFirstComment, pub.AccountID, pub.ID, err)
+}
+
+// WithContainerPrecreation makes scheduling create and validate the Instagram container
+// immediately for publications due within window; the scheduler then only publishes it.
+// Keep window well below 24h, after which Instagram expires containers.
+func (p *Policy) WithContainerPrecreation(window time.Duration) *Policy {
+	p.precreateWindow = window
+	return p
+}
+
+// WithAsyncPublish makes creating a publication with publish_now enqueue it instead of
+// waiting for Instagram; ProcessQueuedPublications must then run periodically
+func (p *Policy) WithAsyncPublish(enabled bool) *Policy {
+	p.publishAsync = enabled
+	return p
+}
+
+// WithReconciliation lets ReconcilePublications check stuck publications with f
+func (p *Policy) WithReconciliation(f PublishedMediaFinder) *Policy {
+	p.reconciler = f
+	return p
 }
 
 // CreatePublicationInput represents input for creating a publication
 type CreatePublicationInput struct {
-	AccountID   string
-	Type        entity.PublicationType
-	Caption     string
-	Media       []MediaInput
-	ReelOptions *entity.ReelOptions // Optional settings for Reels
-	ScheduledAt *time.Time
-	PublishNow  bool // If true, publish immediately after creation
+	AccountID     string
+	Type          entity.PublicationType
+	Caption       string
+	Media         []MediaInput
+	ReelOptions   *entity.ReelOptions  // Optional settings for Reels
+	StoryOptions  *entity.StoryOptions // Link sticker and mentions of stories
+	Collaborators []string             // Usernames to invite as collaborators (feed posts)
+	ScheduledAt   *time.Time
+	HashtagSetID  *string // Hashtag set posted as the first comment after publishing
+	PublishNow    bool    // If true, publish immediately after creation
 }
 
 // MediaInput represents input for a media item
 type MediaInput struct {
-	URL   string
-	Type  entity.MediaType
-	Order int
+	URL     string
+	Type    entity.MediaType
+	Order   int
+	AltText string
 }
 
 // CreatePublicationOutput represents output from creating a publication
@@ -75,37 +360,66 @@ type CreatePublicationOutput struct {
 }
 
 // CreatePublication creates a new publication (draft or scheduled)
+// With PublishNow it publishes the draft right away and returns the published publication.
 func (p *Policy) CreatePublication(ctx context.Context, in CreatePublicationInput) (*CreatePublicationOutput, error) {
 	// Validate publication type
 	if !isValidPublicationType(in.Type) {
 		return nil, entity.ErrInvalidPublicationType
 	}
+	if in.PublishNow && in.ScheduledAt != nil {
+		return nil, entity.ErrPublishNowScheduled
+	}
+	if err := p.authorize(ctx, in.AccountID); err != nil {
+		return nil, err
+	}
 
 	// Convert media input
 	mediaInput := make([]service.MediaInput, len(in.Media))
 	for i, m := range in.Media {
 		mediaInput[i] = service.MediaInput{
-			URL:   m.URL,
-			Type:  m.Type,
-			Order: m.Order,
+			URL:     m.URL,
+			Type:    m.Type,
+			Order:   m.Order,
+			AltText: m.AltText,
+		}
+	}
+
+	if err := p.checkHashtags(ctx, in.AccountID, in.Caption, in.HashtagSetID); err != nil {
+		return nil, err
+	}
+
+	if in.ScheduledAt != nil {
+		draft := &entity.Publication{ReelOptions: in.ReelOptions}
+		for _, m := range in.Media {
+			draft.Media = append(draft.Media, entity.MediaItem{URL: m.URL})
+		}
+		if err := p.checkMediaURLs(ctx, publicationMediaURLs(draft)); err != nil {
+			return nil, err
 		}
 	}
 
 	pub, err := p.svc.CreatePublication(ctx, service.CreateInput{
-		AccountID:   in.AccountID,
-		Type:        in.Type,
-		Caption:     in.Caption,
-		Media:       mediaInput,
-		ReelOptions: in.ReelOptions,
-		ScheduledAt: in.ScheduledAt,
+		AccountID:     in.AccountID,
+		Type:          in.Type,
+		Caption:       in.Caption,
+		Media:         mediaInput,
+		ReelOptions:   in.ReelOptions,
+		StoryOptions:  in.StoryOptions,
+		Collaborators: in.Collaborators,
+		ScheduledAt:   in.ScheduledAt,
+		HashtagSetID:  in.HashtagSetID,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	// If publish_now is set, publish immediately
+	// If publish_now is set, publish immediately or queue it
 	if in.PublishNow {
-		pub, err = p.PublishNow(ctx, pub.ID)
+		publish := p.PublishNow
+		if p.publishAsync {
+			publish = p.EnqueuePublish
+		}
+		pub, err = publish(ctx, pub.ID)
 		if err != nil {
 			return nil, err
 		}
@@ -116,11 +430,16 @@ func (p *Policy) CreatePublication(ctx context.Context, in CreatePublicationInpu
 
 // UpdatePublicationInput represents input for updating a publication
 type UpdatePublicationInput struct {
-	ID            string
-	Caption       *string
-	Media         []MediaInput
-	ScheduledAt   *time.Time
-	ClearSchedule bool
+	ID              string
+	Caption         *string
+	Media           []MediaInput
+	ReelOptions     *entity.ReelOptions  // Replaces the Reel settings when set
+	StoryOptions    *entity.StoryOptions // Replaces the story link and mentions when set
+	Collaborators   []string             // Replaces the collaborators when non-nil; empty removes them
+	ScheduledAt     *time.Time
+	ClearSchedule   bool
+	HashtagSetID    *string // Replaces the hashtag set when set
+	ClearHashtagSet bool    // Removes the hashtag set
 }
 
 // UpdatePublicationOutput represents output from updating a publication
@@ -130,24 +449,71 @@ type UpdatePublicationOutput struct {
 
 // UpdatePublication updates an existing publication
 func (p *Policy) UpdatePublication(ctx context.Context, in UpdatePublicationInput) (*UpdatePublicationOutput, error) {
+	if err := p.checkOwner(ctx, in.ID); err != nil {
+		return nil, err
+	}
+
 	var mediaInput []service.MediaInput
 	if len(in.Media) > 0 {
 		mediaInput = make([]service.MediaInput, len(in.Media))
 		for i, m := range in.Media {
 			mediaInput[i] = service.MediaInput{
-				URL:   m.URL,
-				Type:  m.Type,
-				Order: m.Order,
+				URL:     m.URL,
+				Type:    m.Type,
+				Order:   m.Order,
+				AltText: m.AltText,
 			}
 		}
 	}
 
+	// A new caption or set must still fit Instagram's hashtag limit together
+	if (in.Caption != nil || in.HashtagSetID != nil) && !in.ClearHashtagSet {
+		current, err := p.svc.GetPublication(ctx, in.ID)
+		if err != nil {
+			return nil, err
+		}
+		caption, setID := current.Caption, current.HashtagSetID
+		if in.Caption != nil {
+			caption = *in.Caption
+		}
+		if in.HashtagSetID != nil {
+			setID = in.HashtagSetID
+		}
+		if err := p.checkHashtags(ctx, current.AccountID, caption, setID); err != nil {
+			return nil, err
+		}
+	}
+
+	if in.ScheduledAt != nil && p.mediaCheck != nil {
+		current, err := p.svc.GetPublication(ctx, in.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(in.Media) > 0 {
+			current.Media = nil
+			for _, m := range in.Media {
+				current.Media = append(current.Media, entity.MediaItem{URL: m.URL})
+			}
+		}
+		if in.ReelOptions != nil {
+			current.ReelOptions = in.ReelOptions
+		}
+		if err := p.checkMediaURLs(ctx, publicationMediaURLs(current)); err != nil {
+			return nil, err
+		}
+	}
+
 	pub, err := p.svc.UpdatePublication(ctx, service.UpdateInput{
-		ID:            in.ID,
-		Caption:       in.Caption,
-		Media:         mediaInput,
-		ScheduledAt:   in.ScheduledAt,
-		ClearSchedule: in.ClearSchedule,
+		ID:              in.ID,
+		Caption:         in.Caption,
+		Media:           mediaInput,
+		ReelOptions:     in.ReelOptions,
+		StoryOptions:    in.StoryOptions,
+		Collaborators:   in.Collaborators,
+		ScheduledAt:     in.ScheduledAt,
+		ClearSchedule:   in.ClearSchedule,
+		HashtagSetID:    in.HashtagSetID,
+		ClearHashtagSet: in.ClearHashtagSet,
 	})
 	if err != nil {
 		return nil, err
@@ -156,24 +522,97 @@ func (p *Policy) UpdatePublication(ctx context.Context, in UpdatePublicationInpu
 	return &UpdatePublicationOutput{Publication: pub}, nil
 }
 
+// MediaOrderInput assigns a position to an existing media item
+type MediaOrderInput struct {
+	ID    string
+	Order int
+}
+
+// ReorderMedia changes the order of a publication's media items
+func (p *Policy) ReorderMedia(ctx context.Context, id string, order []MediaOrderInput) (*entity.Publication, error) {
+	if err := p.checkOwner(ctx, id); err != nil {
+		return nil, err
+	}
+
+	mediaOrder := make([]service.MediaOrder, len(order))
+	for i, o := range order {
+		mediaOrder[i] = service.MediaOrder{ID: o.ID, Order: o.Order}
+	}
+
+	return p.svc.ReorderMedia(ctx, id, mediaOrder)
+}
+
 // GetPublication retrieves a publication by ID
 func (p *Policy) GetPublication(ctx context.Context, id string) (*entity.Publication, error) {
-	return p.svc.GetPublication(ctx, id)
+	pub, err := p.svc.GetPublication(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.owned(ctx, pub); err != nil {
+		return nil, err
+	}
+	return pub, nil
+}
+
+// GetPublicationByMediaID retrieves the publication behind an Instagram media ID,
+// e.g. to link a comment back to its post
+func (p *Policy) GetPublicationByMediaID(ctx context.Context, instagramMediaID string) (*entity.PublicationWithComments, error) {
+	pub, err := p.svc.GetByInstagramMediaID(ctx, instagramMediaID)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.owned(ctx, &pub.Publication); err != nil {
+		return nil, err
+	}
+	return pub, nil
 }
 
 // DeletePublicationInput represents input for deleting a publication
 type DeletePublicationInput struct {
-	ID string
+	ID        string
+	Permanent bool // Skip trash and delete immediately
 }
 
 // DeletePublication deletes a publication
+// Drafts are moved to trash unless Permanent is set; other publications are deleted immediately.
 // Note: Instagram Graph API does not support deleting published media.
 // Published posts must be deleted manually through the Instagram app.
 func (p *Policy) DeletePublication(ctx context.Context, in DeletePublicationInput) error {
-	// Verify publication exists
-	if _, err := p.svc.GetPublication(ctx, in.ID); err != nil {
+	// Trashed publications are not found here; a permanent delete still removes them
+	pub, err := p.svc.GetPublication(ctx, in.ID)
+	if err != nil && !(in.Permanent && errors.Is(err, entity.ErrPublicationNotFound)) {
 		return err
 	}
+	if pub == nil && p.authz != nil {
+		if pub, err = p.svc.GetTrashedPublication(ctx, in.ID); err != nil {
+			return err
+		}
+	}
+	if pub != nil {
+		if err := p.owned(ctx, pub); err != nil {
+			return err
+		}
+	}
+
+	var accountID string
+	if pub != nil {
+		accountID = pub.AccountID
+	}
+
+	err = p.deletePublication(ctx, in, pub)
+	p.record(ctx, auditActionDelete, accountID, in.ID, err)
+	return err
+}
+
+// deletePublication trashes or deletes a publication; pub is nil if it was not found
+func (p *Policy) deletePublication(ctx context.Context, in DeletePublicationInput, pub *entity.Publication) error {
+	if in.Permanent || pub == nil {
+		return p.svc.DeletePublication(ctx, in.ID)
+	}
+
+	if pub.Status == entity.PublicationStatusDraft {
+		return p.svc.TrashPublication(ctx, in.ID)
+	}
 
 	// Delete from local database
 	// Note: If the publication was published to Instagram, it will remain there
@@ -181,6 +620,20 @@ func (p *Policy) DeletePublication(ctx context.Context, in DeletePublicationInpu
 	return p.svc.DeletePublication(ctx, in.ID)
 }
 
+// RestorePublication moves a publication out of trash
+func (p *Policy) RestorePublication(ctx context.Context, id string) (*entity.Publication, error) {
+	if p.authz != nil {
+		pub, err := p.svc.GetTrashedPublication(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.owned(ctx, pub); err != nil {
+			return nil, err
+		}
+	}
+	return p.svc.RestorePublication(ctx, id)
+}
+
 // ListPublicationsInput represents input for listing publications
 type ListPublicationsInput struct {
 	AccountID string
@@ -188,26 +641,40 @@ type ListPublicationsInput struct {
 	Status    *entity.PublicationStatus
 	Year      *int
 	Month     *int
+	Trashed   bool
 	Limit     int
 	Offset    int
+	After     *string
+	SortBy    entity.PublicationSort
+	Order     entity.SortOrder
 }
 
 // ListPublicationsOutput represents output from listing publications
 type ListPublicationsOutput struct {
 	Publications []entity.Publication
 	Total        int64
+	NextCursor   string
 }
 
-// ListPublications retrieves publications with filtering
+// ListPublications retrieves publications with filtering.
+// Listing every account's publications, without AccountID, needs an unrestricted caller.
 func (p *Policy) ListPublications(ctx context.Context, in ListPublicationsInput) (*ListPublicationsOutput, error) {
+	if err := p.authorize(ctx, in.AccountID); err != nil {
+		return nil, err
+	}
+
 	out, err := p.svc.ListPublications(ctx, service.ListInput{
 		AccountID: in.AccountID,
 		Type:      in.Type,
 		Status:    in.Status,
 		Year:      in.Year,
 		Month:     in.Month,
+		Trashed:   in.Trashed,
 		Limit:     in.Limit,
 		Offset:    in.Offset,
+		After:     in.After,
+		SortBy:    in.SortBy,
+		Order:     in.Order,
 	})
 	if err != nil {
 		return nil, err
@@ -216,31 +683,93 @@ func (p *Policy) ListPublications(ctx context.Context, in ListPublicationsInput)
 	return &ListPublicationsOutput{
 		Publications: out.Publications,
 		Total:        out.Total,
+		NextCursor:   out.NextCursor,
 	}, nil
 }
 
 // PublishNow immediately publishes a publication to Instagram
+// Returns ErrDailyPublishingLimit without calling Instagram if the account reached the daily limit.
 func (p *Policy) PublishNow(ctx context.Context, id string) (*entity.Publication, error) {
 	pub, err := p.svc.GetPublication(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if err := p.owned(ctx, pub); err != nil {
+		return nil, err
+	}
 
-	if pub.Status == entity.PublicationStatusPublished {
-		return pub, nil // Already published
+	if pub.Status != entity.PublicationStatusPublished {
+		if err := p.checkPublishingSlot(ctx, pub); err != nil {
+			return nil, err
+		}
 	}
 
-	if !pub.CanPublish() && pub.Status != entity.PublicationStatusDraft {
-		return nil, entity.ErrPublicationNotEditable
+	published, err := p.publish(ctx, id, func(_ *entity.Publication, err error) {
+		_ = p.svc.MarkAsFailed(ctx, id, err.Error())
+	})
+	p.record(ctx, auditActionPublish, pub.AccountID, id, err)
+	return published, err
+}
+
+// EnqueuePublish queues a publication for the async publish worker and returns it in status
+// publishing; ProcessQueuedPublications then publishes it like PublishNow. Published and
+// already queued publications are returned unchanged. The daily limit is checked up front,
+// so a client over it gets ErrDailyPublishingLimit right away.
+func (p *Policy) EnqueuePublish(ctx context.Context, id string) (*entity.Publication, error) {
+	pub, err := p.svc.GetPublication(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.owned(ctx, pub); err != nil {
+		return nil, err
+	}
+	if pub.Status == entity.PublicationStatusPublished || pub.Status == entity.PublicationStatusPublishing {
+		return pub, nil
 	}
 
-	// Get account credentials
-	accessToken, err := p.accounts.GetAccessToken(ctx, pub.AccountID)
+	if err := p.checkPublishingSlot(ctx, pub); err != nil {
+		return nil, err
+	}
+	if err := p.svc.EnqueuePublish(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return p.svc.GetPublication(ctx, id)
+}
+
+// checkPublishingSlot returns ErrDailyPublishingLimit, recording it in the audit log,
+// if the publication's account may not publish right now
+func (p *Policy) checkPublishingSlot(ctx context.Context, pub *entity.Publication) error {
+	next, err := p.nextPublishingSlot(ctx, pub.AccountID, time.Now())
+	if err != nil {
+		return err
+	}
+	if !next.IsZero() {
+		p.record(ctx, auditActionPublish, pub.AccountID, pub.ID, entity.ErrDailyPublishingLimit)
+		return entity.ErrDailyPublishingLimit
+	}
+	return nil
+}
+
+// publish publishes a publication to Instagram, calling onFailure if Instagram rejects it
+func (p *Policy) publish(ctx context.Context, id string, onFailure func(pub *entity.Publication, err error)) (*entity.Publication, error) {
+	pub, err := p.svc.GetPublication(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	userID, err := p.accounts.GetInstagramUserID(ctx, pub.AccountID)
+	if pub.Status == entity.PublicationStatusPublished {
+		return pub, nil // Already published
+	}
+
+	// Failed publications may be retried; a stored container is reused by the publisher
+	if !pub.CanPublish() && pub.Status != entity.PublicationStatusDraft && pub.Status != entity.PublicationStatusError &&
+		pub.Status != entity.PublicationStatusPublishing {
+		return nil, entity.ErrPublicationNotEditable
+	}
+
+	// Get account credentials
+	accessToken, userID, err := p.accounts.GetAccountCredentials(ctx, pub.AccountID)
 	if err != nil {
 		return nil, err
 	}
@@ -250,58 +779,469 @@ func (p *Policy) PublishNow(ctx context.Context, id string) (*entity.Publication
 		UserID:      userID,
 		AccessToken: accessToken,
 		Publication: pub,
+		OnContainerCreated: func(containerID string) {
+			// Remember the container so a retry publishes it instead of creating an orphan
+			_ = p.svc.SetContainerID(ctx, id, containerID)
+		},
 	})
 	if err != nil {
-		// Mark as failed
-		_ = p.svc.MarkAsFailed(ctx, id, err.Error())
+		// An expired container cannot be reused; a published one is kept for ReconcilePublications
+		if errors.Is(err, entity.ErrContainerExpired) {
+			_ = p.svc.SetContainerID(ctx, id, "")
+		}
+		onFailure(pub, err)
 		return nil, err
 	}
 
 	// Mark as published
-	if err := p.svc.MarkAsPublished(ctx, id, result.InstagramMediaID); err != nil {
+	if err := p.svc.MarkAsPublished(ctx, id, result.InstagramMediaID, result.Permalink); err != nil {
 		return nil, err
 	}
 
+	// Media details are informational; the publication is already live
+	if result.MediaProductType != "" || result.CommentsEnabled != nil {
+		_ = p.svc.SetMediaInfo(ctx, id, result.MediaProductType, result.CommentsEnabled)
+	}
+
+	p.postFirstComment(ctx, pub, result.InstagramMediaID, accessToken)
+
 	// Refresh and return
 	return p.svc.GetPublication(ctx, id)
 }
 
+// SetCommentsEnabled turns comments on or off for a published publication on Instagram
+// and stores the new setting, so comment sync skips media with comments disabled.
+func (p *Policy) SetCommentsEnabled(ctx context.Context, id string, enabled bool) (*entity.Publication, error) {
+	pub, err := p.svc.GetPublication(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.owned(ctx, pub); err != nil {
+		return nil, err
+	}
+
+	if pub.Status != entity.PublicationStatusPublished || pub.InstagramMediaID == "" {
+		return nil, entity.ErrPublicationNotPublished
+	}
+
+	accessToken, err := p.accounts.GetAccessToken(ctx, pub.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.ig.SetCommentEnabled(ctx, pub.InstagramMediaID, accessToken, enabled); err != nil {
+		return nil, err
+	}
+
+	if err := p.svc.SetCommentsEnabled(ctx, id, enabled); err != nil {
+		return nil, err
+	}
+
+	pub.CommentsEnabled = &enabled
+	return pub, nil
+}
+
+// BackfillPermalinksOutput reports a permalink backfill run
+type BackfillPermalinksOutput struct {
+	Checked int `json:"checked"` // Publications looked up on Instagram
+	Updated int `json:"updated"`
+	Failed  int `json:"failed"` // Left without permalink, e.g. deleted on Instagram or account token invalid
+}
+
+// BackfillPermalinks fetches the permalinks of a page of published publications that have none,
+// e.g. those published before permalinks were stored. Fixed publications leave the list, so
+// offset only has to skip the ones earlier runs failed on. It spans every account, so it needs an unrestricted caller.
+func (p *Policy) BackfillPermalinks(ctx context.Context, limit, offset int) (*BackfillPermalinksOutput, error) {
+	if err := p.authorize(ctx, ""); err != nil {
+		return nil, err
+	}
+
+	pubs, err := p.svc.ListPublishedWithoutPermalink(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &BackfillPermalinksOutput{}
+	for _, pub := range pubs {
+		if ctx.Err() != nil {
+			return out, ctx.Err()
+		}
+		out.Checked++
+
+		accessToken, err := p.accounts.GetAccessToken(ctx, pub.AccountID)
+		if err != nil {
+			out.Failed++
+			continue
+		}
+		permalink, err := p.ig.GetPermalink(ctx, pub.InstagramMediaID, accessToken)
+		if err != nil || permalink == "" {
+			out.Failed++
+			continue
+		}
+		if err := p.svc.SetPermalink(ctx, pub.ID, permalink); err != nil {
+			return out, err
+		}
+		out.Updated++
+	}
+
+	return out, nil
+}
+
 // SchedulePublication schedules a publication for a specific time
+// With container precreation enabled, a near-term schedule is rejected if Instagram cannot process the media.
+// With the media URL check enabled, it is rejected if a media URL cannot be fetched.
 func (p *Policy) SchedulePublication(ctx context.Context, id string, scheduledAt time.Time) (*entity.Publication, error) {
 	if scheduledAt.Before(time.Now()) {
 		return nil, entity.ErrScheduledTimeInPast
 	}
+	if err := p.checkOwner(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if p.mediaCheck != nil {
+		pub, err := p.svc.GetPublication(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.checkMediaURLs(ctx, publicationMediaURLs(pub)); err != nil {
+			return nil, err
+		}
+	}
+
+	containerID, err := p.precreateContainer(ctx, id, scheduledAt)
+	if err != nil {
+		return nil, err
+	}
+
+	pub, err := p.svc.Schedule(ctx, id, scheduledAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if containerID != "" {
+		if err := p.svc.SetContainerID(ctx, id, containerID); err != nil {
+			return nil, err
+		}
+	}
+
+	return pub, nil
+}
+
+// precreateContainer creates the Instagram container for a publication scheduled within the
+// precreation window and returns its ID. It returns an empty ID if precreation does not apply
+// or Instagram is temporarily unavailable; the scheduler then creates the container as usual.
+func (p *Policy) precreateContainer(ctx context.Context, id string, scheduledAt time.Time) (string, error) {
+	if p.precreateWindow <= 0 || time.Until(scheduledAt) > p.precreateWindow {
+		return "", nil
+	}
+
+	pub, err := p.svc.GetPublication(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if !pub.IsEditable() {
+		return "", entity.ErrPublicationNotEditable
+	}
+
+	accessToken, userID, err := p.accounts.GetAccountCredentials(ctx, pub.AccountID)
+	if err != nil {
+		return "", err
+	}
+
+	// A fresh container: the stored one may have been created for an earlier schedule
+	pub.ContainerID = ""
+	containerID, err := p.ig.PrepareContainer(ctx, PublishInput{
+		UserID:      userID,
+		AccessToken: accessToken,
+		Publication: pub,
+	})
+	if err != nil {
+		if isRetryable(err) {
+			return "", nil
+		}
+		if errors.Is(err, entity.ErrContainerFailed) || ctx.Err() != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("%w: %v", entity.ErrContainerFailed, err)
+	}
 
-	return p.svc.Schedule(ctx, id, scheduledAt)
+	return containerID, nil
 }
 
 // SaveAsDraft saves a publication as draft (removes scheduling)
 func (p *Policy) SaveAsDraft(ctx context.Context, id string) (*entity.Publication, error) {
+	if err := p.checkOwner(ctx, id); err != nil {
+		return nil, err
+	}
 	return p.svc.SaveAsDraft(ctx, id)
 }
 
+// PausePublication keeps a scheduled publication out of publishing without losing its scheduled time
+func (p *Policy) PausePublication(ctx context.Context, id string) (*entity.Publication, error) {
+	if err := p.checkOwner(ctx, id); err != nil {
+		return nil, err
+	}
+	return p.svc.Pause(ctx, id)
+}
+
+// ResumePublication schedules a paused publication again at its scheduled time
+func (p *Policy) ResumePublication(ctx context.Context, id string) (*entity.Publication, error) {
+	if err := p.checkOwner(ctx, id); err != nil {
+		return nil, err
+	}
+	return p.svc.Resume(ctx, id)
+}
+
 // ProcessScheduledPublications processes all scheduled publications that are due
 // This should be called by a cron job or scheduler
 func (p *Policy) ProcessScheduledPublications(ctx context.Context) error {
+	// Claim one publication at a time, so other scheduler instances can take the rest
+	// and each claim only has to outlast a single publish
+	for ctx.Err() == nil {
+		pubs, err := p.svc.ClaimScheduledForPublishing(ctx, publishClaimLease, 1)
+		if err != nil {
+			return err
+		}
+		if len(pubs) == 0 {
+			return nil
+		}
+		pub := pubs[0]
+
+		// An account over the daily limit gets its publication deferred, not failed.
+		// If the slot cannot be checked, the claim keeps it out of this run until it expires.
+		next, err := p.nextPublishingSlot(ctx, pub.AccountID, time.Now())
+		if err != nil {
+			continue
+		}
+		if !next.IsZero() {
+			_ = p.svc.ScheduleRetry(ctx, pub.ID, pub.PublishAttempts, next, entity.ErrDailyPublishingLimit.Error())
+			continue
+		}
+
+		// Failures are recorded on the publication by handleScheduledFailure
+		_, err = p.publish(ctx, pub.ID, func(pub *entity.Publication, err error) {
+			p.handleScheduledFailure(ctx, pub, err)
+		})
+		p.record(ctx, auditActionPublish, pub.AccountID, pub.ID, err)
+	}
+
+	return nil
+}
+
+// ProcessQueuedPublications publishes the publications queued by EnqueuePublish, oldest first.
+// Any failure marks the publication as error, as PublishNow would; a run cancelled mid-publish
+// leaves it queued, to be picked up again once its claim expires.
+func (p *Policy) ProcessQueuedPublications(ctx context.Context) error {
+	for ctx.Err() == nil {
+		pubs, err := p.svc.ClaimQueuedForPublishing(ctx, publishClaimLease, 1)
+		if err != nil {
+			return err
+		}
+		if len(pubs) == 0 {
+			return nil
+		}
+		pub := pubs[0]
+
+		_, err = p.publish(ctx, pub.ID, func(*entity.Publication, error) {})
+		if err != nil && ctx.Err() == nil {
+			_ = p.svc.MarkAsFailed(ctx, pub.ID, err.Error())
+		}
+		p.record(ctx, auditActionPublish, pub.AccountID, pub.ID, err)
+	}
+
+	return nil
+}
+
+// ReconcilePublications corrects publications left in publishing or error although Instagram
+// published their container, e.g. because recording the publish failed. Publications that did
+// publish are marked as published; expired containers are dropped so a retry starts over.
+// Does nothing unless WithReconciliation was called.
+func (p *Policy) ReconcilePublications(ctx context.Context) error {
+	if p.reconciler == nil {
+		return nil
+	}
+
+	for ctx.Err() == nil {
+		pubs, err := p.svc.ClaimForReconciliation(ctx, reconcileClaimLease, reconcileMinAge, reconcileMaxAge, 1)
+		if err != nil {
+			return err
+		}
+		if len(pubs) == 0 {
+			return nil
+		}
+		p.reconcile(ctx, pubs[0].ID)
+	}
+
+	return nil
+}
+
+// reconcile checks the container of one publication; failures leave it for a later run
+func (p *Policy) reconcile(ctx context.Context, id string) {
+	pub, err := p.svc.GetPublication(ctx, id)
+	if err != nil || pub.ContainerID == "" || pub.Status == entity.PublicationStatusPublished {
+		return
+	}
+
+	accessToken, userID, err := p.accounts.GetAccountCredentials(ctx, pub.AccountID)
+	if err != nil {
+		return
+	}
+
+	result, err := p.reconciler.FindPublishedMedia(ctx, PublishInput{
+		UserID:      userID,
+		AccessToken: accessToken,
+		Publication: pub,
+	})
+	if errors.Is(err, entity.ErrContainerExpired) {
+		_ = p.svc.SetContainerID(ctx, id, "")
+		return
+	}
+	if err != nil || result == nil {
+		return
+	}
+
+	err = p.svc.MarkAsPublished(ctx, id, result.InstagramMediaID, result.Permalink)
+	p.record(ctx, auditActionReconcile, pub.AccountID, id, err)
+	if err != nil {
+		return
+	}
+	if result.MediaProductType != "" || result.CommentsEnabled != nil {
+		_ = p.svc.SetMediaInfo(ctx, id, result.MediaProductType, result.CommentsEnabled)
+	}
+
+	p.postFirstComment(ctx, pub, result.InstagramMediaID, accessToken)
+}
+
+// nextPublishingSlot returns the zero time if the account may publish at now, or otherwise the
+// time the oldest publication in the trailing 24h window leaves it and frees a slot
+func (p *Policy) nextPublishingSlot(ctx context.Context, accountID string, now time.Time) (time.Time, error) {
+	if p.dailyLimit <= 0 {
+		return time.Time{}, nil
+	}
+
+	published, err := p.svc.GetPublishedTimesSince(ctx, accountID, now.Add(-publishingLimitWindow))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return p.slotAfter(published), nil
+}
+
+// slotAfter returns the zero time if an account with these publish times in the trailing window,
+// oldest first, is under the daily limit, or otherwise the time the next slot frees up
+func (p *Policy) slotAfter(published []time.Time) time.Time {
+	if p.dailyLimit <= 0 || len(published) < p.dailyLimit {
+		return time.Time{}
+	}
+	return published[len(published)-p.dailyLimit].Add(publishingLimitWindow)
+}
+
+// handleScheduledFailure keeps a scheduled publication for another attempt after a temporary
+// failure, and marks it as error after a permanent failure or once attempts are used up
+func (p *Policy) handleScheduledFailure(ctx context.Context, pub *entity.Publication, err error) {
+	attempts := pub.PublishAttempts + 1
+	if !isRetryable(err) || attempts >= p.retry.MaxAttempts {
+		msg := err.Error()
+		if attempts > 1 {
+			msg = fmt.Sprintf("%s (after %d attempts)", msg, attempts)
+		}
+		_ = p.svc.MarkAsFailed(ctx, pub.ID, msg)
+		return
+	}
+
+	next := time.Now().Add(p.retry.Delay(attempts))
+	_ = p.svc.ScheduleRetry(ctx, pub.ID, attempts, next, err.Error())
+}
+
+// isRetryable reports whether a publish error may go away on its own
+func isRetryable(err error) bool {
+	return errors.Is(err, entity.ErrInstagramRateLimited) ||
+		errors.Is(err, entity.ErrInstagramUnavailable) ||
+		errors.Is(err, entity.ErrContainerNotReady) ||
+		errors.Is(err, entity.ErrContainerExpired)
+}
+
+// ScheduledPreview describes what the scheduler would do with a due publication
+type ScheduledPreview struct {
+	Publication      entity.Publication `json:"publication"`
+	InstagramUserID  string             `json:"instagram_user_id,omitempty"`
+	Username         string             `json:"username,omitempty"`
+	ValidationErrors []string           `json:"validation_errors,omitempty"`
+	DeferredUntil    *time.Time         `json:"deferred_until,omitempty"` // Set if the daily publishing limit defers it
+	WouldPublish     bool               `json:"would_publish"`
+}
+
+// PreviewScheduledPublications returns the publications the next scheduler run would publish
+// Dry run: does not call Instagram and does not change publication status
+func (p *Policy) PreviewScheduledPublications(ctx context.Context) ([]ScheduledPreview, error) {
 	pubs, err := p.svc.GetScheduledForPublishing(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	// Publish times per account, including the publications this run would publish before,
+	// so the daily limit is applied as the scheduler applies it one publication at a time
+	now := time.Now()
+	published := make(map[string][]time.Time)
+
+	previews := make([]ScheduledPreview, 0, len(pubs))
 	for _, pub := range pubs {
-		// Process each publication
-		_, err := p.PublishNow(ctx, pub.ID)
-		if err != nil {
-			// Error is already logged in PublishNow via MarkAsFailed
+		// Restricted callers only see their accounts' publications
+		if p.owned(ctx, &pub) != nil {
 			continue
 		}
+		preview := ScheduledPreview{Publication: pub}
+
+		if p.dailyLimit > 0 {
+			times, ok := published[pub.AccountID]
+			if !ok {
+				if times, err = p.svc.GetPublishedTimesSince(ctx, pub.AccountID, now.Add(-publishingLimitWindow)); err != nil {
+					preview.ValidationErrors = append(preview.ValidationErrors, fmt.Sprintf("daily limit: %v", err))
+				}
+				published[pub.AccountID] = times
+			}
+			if next := p.slotAfter(times); !next.IsZero() {
+				preview.DeferredUntil = &next
+			}
+		}
+
+		// Validate content as-is; the schedule time is due by definition, so skip that check
+		check := pub
+		check.ScheduledAt = nil
+		if err := check.Validate(); err != nil {
+			preview.ValidationErrors = append(preview.ValidationErrors, err.Error())
+		}
+		if !pub.CanPublish() {
+			preview.ValidationErrors = append(preview.ValidationErrors, entity.ErrPublicationNotEditable.Error())
+		}
+
+		// Resolve account credentials without using them
+		if _, err := p.accounts.GetAccessToken(ctx, pub.AccountID); err != nil {
+			preview.ValidationErrors = append(preview.ValidationErrors, fmt.Sprintf("access token: %v", err))
+		}
+		if userID, err := p.accounts.GetInstagramUserID(ctx, pub.AccountID); err != nil {
+			preview.ValidationErrors = append(preview.ValidationErrors, fmt.Sprintf("instagram user id: %v", err))
+		} else {
+			preview.InstagramUserID = userID
+		}
+		if username, err := p.accounts.GetUsername(ctx, pub.AccountID); err == nil {
+			preview.Username = username
+		}
+
+		preview.WouldPublish = len(preview.ValidationErrors) == 0 && preview.DeferredUntil == nil
+		if preview.WouldPublish {
+			published[pub.AccountID] = append(published[pub.AccountID], now)
+		}
+		previews = append(previews, preview)
 	}
 
-	return nil
+	return previews, nil
 }
 
 // GetStatistics retrieves publication statistics for an account
 func (p *Policy) GetStatistics(ctx context.Context, accountID string) (*entity.PublicationStatistics, error) {
+	if err := p.authorize(ctx, accountID); err != nil {
+		return nil, err
+	}
 	return p.svc.GetStatistics(ctx, accountID)
 }
 
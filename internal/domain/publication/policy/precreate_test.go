@@ -0,0 +1,91 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+	"github.com/vadim/neo-metric/internal/domain/publication/service"
+)
+
+type preparingPublisher struct {
+	InstagramPublisher
+	containerID string
+	err         error
+	calls       int
+}
+
+func (p *preparingPublisher) PrepareContainer(context.Context, PublishInput) (string, error) {
+	p.calls++
+	return p.containerID, p.err
+}
+
+func newPrecreatePolicy(ig *preparingPublisher) (*Policy, *scheduledRepo) {
+	repo := &scheduledRepo{pub: entity.Publication{
+		ID:        "pub-1",
+		AccountID: "acc-1",
+		Type:      entity.PublicationTypePost,
+		Status:    entity.PublicationStatusDraft,
+	}}
+	p := New(service.New(repo, singleImageRepo{}), ig, staticAccounts{}).
+		WithContainerPrecreation(12 * time.Hour)
+	return p, repo
+}
+
+func TestScheduleNearTermPrecreatesContainer(t *testing.T) {
+	ig := &preparingPublisher{containerID: "container_1"}
+	p, repo := newPrecreatePolicy(ig)
+
+	if _, err := p.SchedulePublication(context.Background(), "pub-1", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("SchedulePublication: %v", err)
+	}
+	if repo.pub.Status != entity.PublicationStatusScheduled {
+		t.Errorf("status = %s, want scheduled", repo.pub.Status)
+	}
+	if repo.pub.ContainerID != "container_1" {
+		t.Errorf("container = %q, want container_1", repo.pub.ContainerID)
+	}
+}
+
+func TestScheduleBeyondWindowSkipsPrecreation(t *testing.T) {
+	ig := &preparingPublisher{containerID: "container_1"}
+	p, repo := newPrecreatePolicy(ig)
+
+	if _, err := p.SchedulePublication(context.Background(), "pub-1", time.Now().Add(48*time.Hour)); err != nil {
+		t.Fatalf("SchedulePublication: %v", err)
+	}
+	if ig.calls != 0 {
+		t.Errorf("PrepareContainer called %d times, want 0", ig.calls)
+	}
+	if repo.pub.Status != entity.PublicationStatusScheduled || repo.pub.ContainerID != "" {
+		t.Errorf("status/container = %s/%q, want scheduled without container", repo.pub.Status, repo.pub.ContainerID)
+	}
+}
+
+func TestScheduleRejectsBadMedia(t *testing.T) {
+	ig := &preparingPublisher{err: errors.New("instagram API error: invalid image (code: 9004, subcode: 2207052)")}
+	p, repo := newPrecreatePolicy(ig)
+
+	_, err := p.SchedulePublication(context.Background(), "pub-1", time.Now().Add(time.Hour))
+	if !errors.Is(err, entity.ErrContainerFailed) {
+		t.Fatalf("err = %v, want ErrContainerFailed", err)
+	}
+	if repo.pub.Status != entity.PublicationStatusDraft {
+		t.Errorf("status = %s, want draft to stay unscheduled", repo.pub.Status)
+	}
+}
+
+func TestScheduleIgnoresTemporaryPrecreationFailure(t *testing.T) {
+	ig := &preparingPublisher{err: fmt.Errorf("%w: code 4", entity.ErrInstagramRateLimited)}
+	p, repo := newPrecreatePolicy(ig)
+
+	if _, err := p.SchedulePublication(context.Background(), "pub-1", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("SchedulePublication: %v", err)
+	}
+	if repo.pub.Status != entity.PublicationStatusScheduled || repo.pub.ContainerID != "" {
+		t.Errorf("status/container = %s/%q, want scheduled without container", repo.pub.Status, repo.pub.ContainerID)
+	}
+}
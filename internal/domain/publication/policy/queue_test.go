@@ -0,0 +1,133 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+	"github.com/vadim/neo-metric/internal/domain/publication/service"
+)
+
+// queueRepo adds the async publish queue to publishingRepo
+type queueRepo struct {
+	publishingRepo
+}
+
+func (r *queueRepo) EnqueuePublish(_ context.Context, _ string) (bool, error) {
+	switch r.pub.Status {
+	case entity.PublicationStatusDraft, entity.PublicationStatusScheduled, entity.PublicationStatusError:
+		r.pub.Status = entity.PublicationStatusPublishing
+		r.pub.ErrorMessage = ""
+		r.claimed = nil
+		return true, nil
+	}
+	return false, nil
+}
+
+func (r *queueRepo) ClaimQueuedForPublishing(_ context.Context, now, claimUntil time.Time, _ int) ([]entity.Publication, error) {
+	if r.pub.Status != entity.PublicationStatusPublishing || (r.claimed != nil && r.claimed.After(now)) {
+		return nil, nil
+	}
+	r.claimed = &claimUntil
+	return []entity.Publication{r.pub}, nil
+}
+
+func newQueuePolicy(status entity.PublicationStatus, ig InstagramPublisher) (*Policy, *queueRepo) {
+	repo := &queueRepo{publishingRepo{scheduledRepo{pub: entity.Publication{
+		ID:        "pub-1",
+		AccountID: "acc-1",
+		Type:      entity.PublicationTypePost,
+		Status:    status,
+	}}}}
+	return New(service.New(repo, singleImageRepo{}), ig, staticAccounts{}), repo
+}
+
+func TestEnqueuedPublicationIsPublishedByWorker(t *testing.T) {
+	ig := &countingPublisher{calls: map[string]int{}}
+	p, repo := newQueuePolicy(entity.PublicationStatusDraft, ig)
+
+	pub, err := p.EnqueuePublish(context.Background(), "pub-1")
+	if err != nil {
+		t.Fatalf("EnqueuePublish: %v", err)
+	}
+	if pub.Status != entity.PublicationStatusPublishing || ig.calls["pub-1"] != 0 {
+		t.Fatalf("after enqueue: status %s, %d publishes; want publishing and none yet", pub.Status, ig.calls["pub-1"])
+	}
+
+	// Enqueuing again while queued is a no-op
+	if _, err := p.EnqueuePublish(context.Background(), "pub-1"); err != nil {
+		t.Fatalf("second EnqueuePublish: %v", err)
+	}
+
+	if err := p.ProcessQueuedPublications(context.Background()); err != nil {
+		t.Fatalf("ProcessQueuedPublications: %v", err)
+	}
+	if repo.pub.Status != entity.PublicationStatusPublished || ig.calls["pub-1"] != 1 {
+		t.Errorf("after worker: status %s, %d publishes; want published once", repo.pub.Status, ig.calls["pub-1"])
+	}
+}
+
+func TestQueuedPublishFailureMarksError(t *testing.T) {
+	ig := &failingPublisher{err: errors.New("media type not supported")}
+	p, repo := newQueuePolicy(entity.PublicationStatusError, ig)
+
+	if _, err := p.EnqueuePublish(context.Background(), "pub-1"); err != nil {
+		t.Fatalf("EnqueuePublish: %v", err)
+	}
+	if err := p.ProcessQueuedPublications(context.Background()); err != nil {
+		t.Fatalf("ProcessQueuedPublications: %v", err)
+	}
+
+	if repo.pub.Status != entity.PublicationStatusError || repo.pub.ErrorMessage != "media type not supported" {
+		t.Errorf("status %s %q, want error with the Instagram message", repo.pub.Status, repo.pub.ErrorMessage)
+	}
+	if ig.calls != 1 {
+		t.Errorf("published %d times, want 1", ig.calls)
+	}
+}
+
+func TestEnqueueRefusedAtDailyLimit(t *testing.T) {
+	ig := &countingPublisher{calls: map[string]int{}}
+	p, repo := newQueuePolicy(entity.PublicationStatusDraft, ig)
+	p.WithDailyPublishingLimit(1)
+	repo.published = []time.Time{time.Now().Add(-time.Hour)}
+
+	if _, err := p.EnqueuePublish(context.Background(), "pub-1"); !errors.Is(err, entity.ErrDailyPublishingLimit) {
+		t.Fatalf("err = %v, want ErrDailyPublishingLimit", err)
+	}
+	if repo.pub.Status != entity.PublicationStatusDraft {
+		t.Errorf("status = %s, want draft", repo.pub.Status)
+	}
+}
+
+func TestCreatePublishNowQueuesWhenAsync(t *testing.T) {
+	ig := &countingPublisher{calls: map[string]int{}}
+	repo := &creatingQueueRepo{}
+	p := New(service.New(repo, creatingMediaRepo{}), ig, staticAccounts{}).
+		WithAsyncPublish(true)
+
+	out, err := p.CreatePublication(context.Background(), CreatePublicationInput{
+		AccountID:  "acc-1",
+		Type:       entity.PublicationTypePost,
+		Media:      []MediaInput{{URL: "https://cdn.example.com/a.jpg", Type: entity.MediaTypeImage}},
+		PublishNow: true,
+	})
+	if err != nil {
+		t.Fatalf("CreatePublication: %v", err)
+	}
+	if out.Publication.Status != entity.PublicationStatusPublishing || ig.calls[out.Publication.ID] != 0 {
+		t.Errorf("status %s, %d publishes; want publishing without waiting for Instagram", out.Publication.Status, ig.calls[out.Publication.ID])
+	}
+}
+
+// creatingQueueRepo stores the created publication so it can be queued afterwards
+type creatingQueueRepo struct {
+	queueRepo
+}
+
+func (r *creatingQueueRepo) Create(_ context.Context, pub *entity.Publication) error {
+	r.pub = *pub
+	return nil
+}
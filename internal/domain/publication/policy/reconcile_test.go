@@ -0,0 +1,154 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+	"github.com/vadim/neo-metric/internal/domain/publication/service"
+)
+
+// reconcileRepo can fail to record a publish, as during a database outage
+type reconcileRepo struct {
+	publishingRepo
+	failPublishWrite bool
+}
+
+func (r *reconcileRepo) SetPublished(ctx context.Context, id string, mediaID, permalink string, publishedAt time.Time) error {
+	if r.failPublishWrite {
+		r.failPublishWrite = false
+		return errors.New("connection reset by peer")
+	}
+	return r.publishingRepo.SetPublished(ctx, id, mediaID, permalink, publishedAt)
+}
+
+func (r *reconcileRepo) ClaimQueuedForPublishing(_ context.Context, now, claimUntil time.Time, _ int) ([]entity.Publication, error) {
+	if r.pub.Status != entity.PublicationStatusPublishing || (r.claimed != nil && r.claimed.After(now)) {
+		return nil, nil
+	}
+	r.claimed = &claimUntil
+	return []entity.Publication{r.pub}, nil
+}
+
+func (r *reconcileRepo) ClaimForReconciliation(_ context.Context, now, claimUntil time.Time, _ int, _, _ time.Time) ([]entity.Publication, error) {
+	stuck := r.pub.Status == entity.PublicationStatusPublishing || r.pub.Status == entity.PublicationStatusError
+	if !stuck || r.pub.ContainerID == "" || (r.claimed != nil && r.claimed.After(now)) {
+		return nil, nil
+	}
+	r.claimed = &claimUntil
+	return []entity.Publication{r.pub}, nil
+}
+
+// containerPublisher creates container_1 and publishes it as media_1
+type containerPublisher struct {
+	InstagramPublisher
+}
+
+func (containerPublisher) Publish(_ context.Context, in PublishInput) (*PublishOutput, error) {
+	in.OnContainerCreated("container_1")
+	return &PublishOutput{InstagramMediaID: "media_1"}, nil
+}
+
+// staticFinder reports a fixed outcome for every container
+type staticFinder struct {
+	out        *PublishOutput
+	err        error
+	containers []string // Containers looked up
+}
+
+func (f *staticFinder) FindPublishedMedia(_ context.Context, in PublishInput) (*PublishOutput, error) {
+	f.containers = append(f.containers, in.Publication.ContainerID)
+	return f.out, f.err
+}
+
+func newReconcileRepo(status entity.PublicationStatus, containerID string) *reconcileRepo {
+	return &reconcileRepo{publishingRepo: publishingRepo{scheduledRepo{pub: entity.Publication{
+		ID:          "pub-1",
+		AccountID:   "acc-1",
+		Type:        entity.PublicationTypePost,
+		Status:      status,
+		ContainerID: containerID,
+	}}}}
+}
+
+func TestReconcileMarksPublishedContainerAsPublished(t *testing.T) {
+	repo := newReconcileRepo(entity.PublicationStatusPublishing, "")
+	repo.failPublishWrite = true
+	finder := &staticFinder{out: &PublishOutput{InstagramMediaID: "media_1"}}
+	audit := &recordingAudit{}
+	p := New(service.New(repo, singleImageRepo{}), containerPublisher{}, staticAccounts{}).
+		WithReconciliation(finder).
+		WithAuditRecorder(audit)
+
+	// Instagram publishes the post, but recording it fails
+	if err := p.ProcessQueuedPublications(context.Background()); err != nil {
+		t.Fatalf("ProcessQueuedPublications: %v", err)
+	}
+	if repo.pub.Status != entity.PublicationStatusError || repo.pub.ContainerID != "container_1" {
+		t.Fatalf("after failed write: status = %s, container = %q, want error with container_1", repo.pub.Status, repo.pub.ContainerID)
+	}
+
+	if err := p.ReconcilePublications(context.Background()); err != nil {
+		t.Fatalf("ReconcilePublications: %v", err)
+	}
+
+	if repo.pub.Status != entity.PublicationStatusPublished || repo.pub.InstagramMediaID != "media_1" {
+		t.Errorf("status = %s, media = %q, want published as media_1", repo.pub.Status, repo.pub.InstagramMediaID)
+	}
+	if len(finder.containers) != 1 || finder.containers[0] != "container_1" {
+		t.Errorf("looked up containers %v, want [container_1]", finder.containers)
+	}
+	last := audit.calls[len(audit.calls)-1]
+	if last.action != "publication.reconcile" || last.err != nil {
+		t.Errorf("last audit entry = %+v, want successful publication.reconcile", last)
+	}
+}
+
+func TestReconcileDropsExpiredContainer(t *testing.T) {
+	repo := newReconcileRepo(entity.PublicationStatusError, "container_1")
+	finder := &staticFinder{err: entity.ErrContainerExpired}
+	p := New(service.New(repo, singleImageRepo{}), containerPublisher{}, staticAccounts{}).WithReconciliation(finder)
+
+	if err := p.ReconcilePublications(context.Background()); err != nil {
+		t.Fatalf("ReconcilePublications: %v", err)
+	}
+
+	if repo.pub.Status != entity.PublicationStatusError || repo.pub.ContainerID != "" {
+		t.Errorf("status = %s, container = %q, want error without container", repo.pub.Status, repo.pub.ContainerID)
+	}
+}
+
+func TestReconcileLeavesUnpublishedContainer(t *testing.T) {
+	repo := newReconcileRepo(entity.PublicationStatusError, "container_1")
+	finder := &staticFinder{}
+	p := New(service.New(repo, singleImageRepo{}), containerPublisher{}, staticAccounts{}).WithReconciliation(finder)
+
+	for range 2 {
+		if err := p.ReconcilePublications(context.Background()); err != nil {
+			t.Fatalf("ReconcilePublications: %v", err)
+		}
+	}
+
+	if repo.pub.Status != entity.PublicationStatusError || repo.pub.ContainerID != "container_1" {
+		t.Errorf("status = %s, container = %q, want unchanged", repo.pub.Status, repo.pub.ContainerID)
+	}
+	// The claim keeps the publication out of runs until it expires
+	if len(finder.containers) != 1 {
+		t.Errorf("looked up %d times, want 1", len(finder.containers))
+	}
+}
+
+func TestPublishedContainerIsKeptForReconciliation(t *testing.T) {
+	p, repo, _ := newRetryPolicy(entity.ErrContainerPublished, 0)
+	repo.pub.ContainerID = "container_1"
+
+	if err := p.ProcessScheduledPublications(context.Background()); err != nil {
+		t.Fatalf("ProcessScheduledPublications: %v", err)
+	}
+
+	if repo.pub.Status != entity.PublicationStatusError || repo.pub.ContainerID != "container_1" {
+		t.Errorf("status = %s, container = %q, want error keeping container_1", repo.pub.Status, repo.pub.ContainerID)
+	}
+}
@@ -0,0 +1,214 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/publication/dao"
+	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+	"github.com/vadim/neo-metric/internal/domain/publication/service"
+)
+
+// scheduledRepo holds a single due publication and records status changes
+type scheduledRepo struct {
+	dao.PublicationRepository
+	pub       entity.Publication
+	published []time.Time // Earlier publish times of the account, oldest first
+	claimed   *time.Time  // Claim expiry set by ClaimScheduledForPublishing
+}
+
+func (r *scheduledRepo) GetPublishedTimesSince(_ context.Context, _ string, since time.Time) ([]time.Time, error) {
+	var times []time.Time
+	for _, t := range r.published {
+		if !t.Before(since) {
+			times = append(times, t)
+		}
+	}
+	return times, nil
+}
+
+func (r *scheduledRepo) GetByID(_ context.Context, id string) (*entity.Publication, error) {
+	if id != r.pub.ID {
+		return nil, nil
+	}
+	pub := r.pub
+	return &pub, nil
+}
+
+func (r *scheduledRepo) GetScheduledForPublishing(_ context.Context, now time.Time) ([]entity.Publication, error) {
+	if r.pub.Status != entity.PublicationStatusScheduled {
+		return nil, nil
+	}
+	if r.pub.NextAttemptAt != nil && r.pub.NextAttemptAt.After(now) {
+		return nil, nil
+	}
+	return []entity.Publication{r.pub}, nil
+}
+
+func (r *scheduledRepo) ClaimScheduledForPublishing(ctx context.Context, now, claimUntil time.Time, _ int) ([]entity.Publication, error) {
+	if r.claimed != nil && r.claimed.After(now) {
+		return nil, nil
+	}
+	pubs, _ := r.GetScheduledForPublishing(ctx, now)
+	if len(pubs) > 0 {
+		r.claimed = &claimUntil
+	}
+	return pubs, nil
+}
+
+func (r *scheduledRepo) UpdateStatus(_ context.Context, _ string, status entity.PublicationStatus, errorMsg string) error {
+	r.pub.Status = status
+	r.pub.ErrorMessage = errorMsg
+	r.pub.PublishAttempts = 0
+	r.pub.NextAttemptAt = nil
+	r.claimed = nil
+	return nil
+}
+
+func (r *scheduledRepo) SetRetry(_ context.Context, _ string, attempts int, nextAttemptAt time.Time, errorMsg string) error {
+	r.pub.PublishAttempts = attempts
+	r.pub.NextAttemptAt = &nextAttemptAt
+	r.pub.ErrorMessage = errorMsg
+	r.claimed = nil
+	return nil
+}
+
+func (r *scheduledRepo) Update(_ context.Context, pub *entity.Publication) error {
+	r.pub.Caption = pub.Caption
+	r.pub.Status = pub.Status
+	r.pub.ScheduledAt = pub.ScheduledAt
+	return nil
+}
+
+func (r *scheduledRepo) SetContainerID(_ context.Context, _ string, containerID string) error {
+	r.pub.ContainerID = containerID
+	return nil
+}
+
+type singleImageRepo struct {
+	dao.MediaRepository
+}
+
+func (singleImageRepo) GetByPublicationID(context.Context, string) ([]entity.MediaItem, error) {
+	return []entity.MediaItem{{ID: "m-1", URL: "https://cdn.example.com/a.jpg", Type: entity.MediaTypeImage}}, nil
+}
+
+type failingPublisher struct {
+	InstagramPublisher
+	err   error
+	calls int
+}
+
+func (p *failingPublisher) Publish(context.Context, PublishInput) (*PublishOutput, error) {
+	p.calls++
+	return nil, p.err
+}
+
+type staticAccounts struct{}
+
+func (staticAccounts) GetAccessToken(context.Context, string) (string, error) { return "token", nil }
+func (staticAccounts) GetInstagramUserID(context.Context, string) (string, error) {
+	return "ig_user", nil
+}
+func (staticAccounts) GetAccountCredentials(context.Context, string) (string, string, error) {
+	return "token", "ig_user", nil
+}
+func (staticAccounts) GetUsername(context.Context, string) (string, error) { return "neo", nil }
+
+func newRetryPolicy(publishErr error, attempts int) (*Policy, *scheduledRepo, *failingPublisher) {
+	past := time.Now().Add(-time.Minute)
+	repo := &scheduledRepo{pub: entity.Publication{
+		ID:              "pub-1",
+		AccountID:       "acc-1",
+		Type:            entity.PublicationTypePost,
+		Status:          entity.PublicationStatusScheduled,
+		ScheduledAt:     &past,
+		PublishAttempts: attempts,
+	}}
+	ig := &failingPublisher{err: publishErr}
+	p := New(service.New(repo, singleImageRepo{}), ig, staticAccounts{}).WithRetry(RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Minute,
+		MaxDelay:    time.Hour,
+	})
+	return p, repo, ig
+}
+
+func TestScheduledRetryableFailureStaysScheduled(t *testing.T) {
+	for name, err := range map[string]error{
+		"rate limited": fmt.Errorf("%w: code 4", entity.ErrInstagramRateLimited),
+		"server error": fmt.Errorf("%w: status 503", entity.ErrInstagramUnavailable),
+		"not ready":    entity.ErrContainerNotReady,
+	} {
+		t.Run(name, func(t *testing.T) {
+			p, repo, _ := newRetryPolicy(err, 0)
+
+			start := time.Now()
+			if err := p.ProcessScheduledPublications(context.Background()); err != nil {
+				t.Fatalf("ProcessScheduledPublications: %v", err)
+			}
+
+			if repo.pub.Status != entity.PublicationStatusScheduled {
+				t.Fatalf("status = %s, want scheduled", repo.pub.Status)
+			}
+			if repo.pub.PublishAttempts != 1 {
+				t.Errorf("attempts = %d, want 1", repo.pub.PublishAttempts)
+			}
+			if repo.pub.NextAttemptAt == nil || repo.pub.NextAttemptAt.Before(start.Add(time.Minute)) {
+				t.Errorf("next attempt = %v, want at least a minute from now", repo.pub.NextAttemptAt)
+			}
+		})
+	}
+}
+
+func TestScheduledRetryWaitsForNextAttempt(t *testing.T) {
+	p, _, ig := newRetryPolicy(entity.ErrInstagramRateLimited, 0)
+
+	for i := 0; i < 2; i++ {
+		if err := p.ProcessScheduledPublications(context.Background()); err != nil {
+			t.Fatalf("ProcessScheduledPublications: %v", err)
+		}
+	}
+	if ig.calls != 1 {
+		t.Errorf("publish calls = %d, want 1 before the retry delay passes", ig.calls)
+	}
+}
+
+func TestScheduledPermanentFailureMarksError(t *testing.T) {
+	p, repo, _ := newRetryPolicy(errors.New("instagram API error: invalid image (code: 9004, subcode: 2207052)"), 0)
+
+	if err := p.ProcessScheduledPublications(context.Background()); err != nil {
+		t.Fatalf("ProcessScheduledPublications: %v", err)
+	}
+	if repo.pub.Status != entity.PublicationStatusError {
+		t.Fatalf("status = %s, want error", repo.pub.Status)
+	}
+}
+
+func TestScheduledRetryableFailureGivesUpAfterMaxAttempts(t *testing.T) {
+	p, repo, _ := newRetryPolicy(entity.ErrInstagramUnavailable, 2)
+
+	if err := p.ProcessScheduledPublications(context.Background()); err != nil {
+		t.Fatalf("ProcessScheduledPublications: %v", err)
+	}
+	if repo.pub.Status != entity.PublicationStatusError {
+		t.Fatalf("status = %s, want error after 3 attempts", repo.pub.Status)
+	}
+	if want := entity.ErrInstagramUnavailable.Error() + " (after 3 attempts)"; repo.pub.ErrorMessage != want {
+		t.Errorf("error message = %q, want %q", repo.pub.ErrorMessage, want)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	r := RetryPolicy{BaseDelay: time.Minute, MaxDelay: 5 * time.Minute}
+
+	want := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+	for i, w := range want {
+		if got := r.Delay(i + 1); got != w {
+			t.Errorf("Delay(%d) = %s, want %s", i+1, got, w)
+		}
+	}
+}
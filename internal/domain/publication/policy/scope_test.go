@@ -0,0 +1,165 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+	"github.com/vadim/neo-metric/internal/domain/publication/service"
+)
+
+// scopedAuthorizer allows only the listed accounts, like an API key restricted to them
+type scopedAuthorizer map[string]bool
+
+func (a scopedAuthorizer) AccountAllowed(_ context.Context, accountID string) bool {
+	return a[accountID]
+}
+
+// scopeRepo adds a trashed publication and media ID lookups to scheduledRepo
+type scopeRepo struct {
+	*scheduledRepo
+	trashed entity.Publication
+}
+
+func (r *scopeRepo) GetTrashedByID(_ context.Context, id string) (*entity.Publication, error) {
+	if id != r.trashed.ID {
+		return nil, nil
+	}
+	pub := r.trashed
+	return &pub, nil
+}
+
+func (r *scopeRepo) GetByInstagramMediaID(_ context.Context, instagramMediaID string) (*entity.Publication, error) {
+	if instagramMediaID != r.pub.InstagramMediaID {
+		return nil, nil
+	}
+	pub := r.pub
+	return &pub, nil
+}
+
+func (r *scopeRepo) CountComments(context.Context, string) (int64, error) { return 0, nil }
+
+// newScopedPolicy returns a policy over publications of acc-1, called by a key scoped to the given accounts
+func newScopedPolicy(allowed ...string) (*Policy, *scopeRepo, *failingPublisher) {
+	_, sched, ig := newRetryPolicy(entity.ErrInstagramUnavailable, 0)
+	sched.pub.InstagramMediaID = "media_1"
+	repo := &scopeRepo{
+		scheduledRepo: sched,
+		trashed:       entity.Publication{ID: "pub-2", AccountID: "acc-1", Status: entity.PublicationStatusDraft},
+	}
+
+	authz := scopedAuthorizer{}
+	for _, id := range allowed {
+		authz[id] = true
+	}
+	p := New(service.New(repo, singleImageRepo{}), ig, staticAccounts{}).WithAccountAuthorizer(authz)
+	return p, repo, ig
+}
+
+func TestScopedKeyCannotTouchOtherAccountsPublications(t *testing.T) {
+	p, repo, ig := newScopedPolicy("acc-2")
+	ctx := context.Background()
+	caption := "changed"
+	future := time.Now().Add(time.Hour)
+
+	ops := map[string]func() error{
+		"get": func() error { _, err := p.GetPublication(ctx, "pub-1"); return err },
+		"get by media": func() error {
+			_, err := p.GetPublicationByMediaID(ctx, "media_1")
+			return err
+		},
+		"update": func() error {
+			_, err := p.UpdatePublication(ctx, UpdatePublicationInput{ID: "pub-1", Caption: &caption})
+			return err
+		},
+		"reorder": func() error {
+			_, err := p.ReorderMedia(ctx, "pub-1", []MediaOrderInput{{ID: "m-1", Order: 0}})
+			return err
+		},
+		"delete": func() error { return p.DeletePublication(ctx, DeletePublicationInput{ID: "pub-1"}) },
+		"delete trashed": func() error {
+			return p.DeletePublication(ctx, DeletePublicationInput{ID: "pub-2", Permanent: true})
+		},
+		"restore":     func() error { _, err := p.RestorePublication(ctx, "pub-2"); return err },
+		"publish now": func() error { _, err := p.PublishNow(ctx, "pub-1"); return err },
+		"enqueue":     func() error { _, err := p.EnqueuePublish(ctx, "pub-1"); return err },
+		"comments":    func() error { _, err := p.SetCommentsEnabled(ctx, "pub-1", false); return err },
+		"schedule":    func() error { _, err := p.SchedulePublication(ctx, "pub-1", future); return err },
+		"save draft":  func() error { _, err := p.SaveAsDraft(ctx, "pub-1"); return err },
+		"pause":       func() error { _, err := p.PausePublication(ctx, "pub-1"); return err },
+		"resume":      func() error { _, err := p.ResumePublication(ctx, "pub-1"); return err },
+	}
+	for name, op := range ops {
+		t.Run(name, func(t *testing.T) {
+			if err := op(); !errors.Is(err, entity.ErrPublicationNotFound) {
+				t.Errorf("error = %v, want ErrPublicationNotFound", err)
+			}
+		})
+	}
+
+	if repo.pub.Status != entity.PublicationStatusScheduled || repo.pub.Caption != "" {
+		t.Errorf("publication changed to %+v", repo.pub)
+	}
+	if ig.calls != 0 {
+		t.Errorf("publish calls = %d, want 0", ig.calls)
+	}
+}
+
+func TestScopedKeyNeedsAllowedAccount(t *testing.T) {
+	p, _, _ := newScopedPolicy("acc-2")
+	ctx := context.Background()
+
+	ops := map[string]func() error{
+		"list all accounts": func() error {
+			_, err := p.ListPublications(ctx, ListPublicationsInput{})
+			return err
+		},
+		"list other account": func() error {
+			_, err := p.ListPublications(ctx, ListPublicationsInput{AccountID: "acc-1"})
+			return err
+		},
+		"create": func() error {
+			_, err := p.CreatePublication(ctx, CreatePublicationInput{
+				AccountID: "acc-1",
+				Type:      entity.PublicationTypePost,
+				Media:     []MediaInput{{URL: "https://cdn.example.com/a.jpg", Type: entity.MediaTypeImage}},
+			})
+			return err
+		},
+		"statistics": func() error { _, err := p.GetStatistics(ctx, "acc-1"); return err },
+		"backfill":   func() error { _, err := p.BackfillPermalinks(ctx, 10, 0); return err },
+	}
+	for name, op := range ops {
+		t.Run(name, func(t *testing.T) {
+			if err := op(); !errors.Is(err, entity.ErrAccountForbidden) {
+				t.Errorf("error = %v, want ErrAccountForbidden", err)
+			}
+		})
+	}
+}
+
+func TestScopedKeyAccessesOwnAccount(t *testing.T) {
+	p, _, _ := newScopedPolicy("acc-1")
+	ctx := context.Background()
+
+	if _, err := p.GetPublication(ctx, "pub-1"); err != nil {
+		t.Errorf("GetPublication: %v", err)
+	}
+	if _, err := p.PausePublication(ctx, "pub-1"); err != nil {
+		t.Errorf("PausePublication: %v", err)
+	}
+}
+
+func TestScopedPreviewHidesOtherAccounts(t *testing.T) {
+	p, _, _ := newScopedPolicy("acc-2")
+
+	previews, err := p.PreviewScheduledPublications(context.Background())
+	if err != nil {
+		t.Fatalf("PreviewScheduledPublications: %v", err)
+	}
+	if len(previews) != 0 {
+		t.Errorf("previews = %d, want 0 for another account's key", len(previews))
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"log/slog"
 	"sync"
 	"time"
+
+	"github.com/vadim/neo-metric/internal/jitter"
+	"github.com/vadim/neo-metric/internal/liveness"
 )
 
 // ScheduledPublicationProcessor defines the interface for processing scheduled publications
@@ -12,25 +15,62 @@ type ScheduledPublicationProcessor interface {
 	ProcessScheduledPublications(ctx context.Context) error
 }
 
+// ProcessorFunc lets another periodic publication job, such as the async publish queue, run on a Scheduler
+type ProcessorFunc func(ctx context.Context) error
+
+// ProcessScheduledPublications calls f
+func (f ProcessorFunc) ProcessScheduledPublications(ctx context.Context) error {
+	return f(ctx)
+}
+
 // Scheduler handles periodic processing of scheduled publications
 type Scheduler struct {
-	processor ScheduledPublicationProcessor
-	interval  time.Duration
-	logger    *slog.Logger
-	stopCh    chan struct{}
-	wg        sync.WaitGroup
-	running   bool
-	mu        sync.Mutex
+	name          string // Used in log messages
+	processor     ScheduledPublicationProcessor
+	interval      time.Duration
+	jitter        float64          // Fraction of interval to randomize each tick by (e.g. 0.1 = ±10%)
+	startupJitter time.Duration    // Max random delay before the first run
+	stopTimeout   time.Duration    // How long Stop waits for an in-flight run before cancelling it
+	runs          liveness.Tracker // Run times reported by Status
+	logger        *slog.Logger
+	stopCh        chan struct{}
+	cancel        context.CancelFunc // Cancel function to stop in-flight operations
+	wg            sync.WaitGroup
+	running       bool
+	mu            sync.Mutex
 }
 
 // New creates a new scheduler
 func New(processor ScheduledPublicationProcessor, interval time.Duration, logger *slog.Logger) *Scheduler {
 	return &Scheduler{
-		processor: processor,
-		interval:  interval,
-		logger:    logger,
-		stopCh:    make(chan struct{}),
+		name:        "publication scheduler",
+		processor:   processor,
+		interval:    interval,
+		stopTimeout: 30 * time.Second,
+		logger:      logger,
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// WithName sets the name the scheduler logs under
+func (s *Scheduler) WithName(name string) *Scheduler {
+	s.name = name
+	return s
+}
+
+// WithJitter sets the tick jitter fraction and the max random startup delay
+func (s *Scheduler) WithJitter(fraction float64, startup time.Duration) *Scheduler {
+	s.jitter = fraction
+	s.startupJitter = startup
+	return s
+}
+
+// WithStopTimeout sets how long Stop waits for an in-flight run before cancelling it
+func (s *Scheduler) WithStopTimeout(d time.Duration) *Scheduler {
+	if d > 0 {
+		s.stopTimeout = d
 	}
+	return s
 }
 
 // Start starts the scheduler
@@ -41,15 +81,20 @@ func (s *Scheduler) Start(ctx context.Context) {
 		return
 	}
 	s.running = true
+	s.runs.Started(time.Now())
+
+	// Create a cancellable context for in-flight operations
+	ctx, s.cancel = context.WithCancel(ctx)
 	s.mu.Unlock()
 
-	s.logger.Info("publication scheduler started", "interval", s.interval)
+	s.logger.Info(s.name+" started", "interval", s.interval)
 
 	s.wg.Add(1)
 	go s.run(ctx)
 }
 
-// Stop stops the scheduler
+// Stop stops the scheduler and waits for the in-flight iteration to drain.
+// If it doesn't finish within the stop timeout, its context is cancelled.
 func (s *Scheduler) Stop() {
 	s.mu.Lock()
 	if !s.running {
@@ -57,27 +102,58 @@ func (s *Scheduler) Stop() {
 		return
 	}
 	s.running = false
+	s.runs.Stopped()
+	cancel := s.cancel
 	s.mu.Unlock()
 
 	close(s.stopCh)
-	s.wg.Wait()
-	s.logger.Info("publication scheduler stopped")
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(s.stopTimeout):
+		s.logger.Warn(s.name+" did not drain in time, cancelling in-flight work", "timeout", s.stopTimeout)
+		// Cancel in-flight operations (HTTP requests, etc.) and wait for them to return
+		cancel()
+		<-done
+	}
+
+	cancel()
+	s.logger.Info(s.name + " stopped")
+}
+
+// Status reports the scheduler's interval and its latest run
+func (s *Scheduler) Status() liveness.Status {
+	return s.runs.Status(s.interval)
 }
 
 // run is the main scheduler loop
 func (s *Scheduler) run(ctx context.Context) {
 	defer s.wg.Done()
 
-	ticker := time.NewTicker(s.interval)
-	defer ticker.Stop()
+	// Run on start after a random delay so schedulers don't fire together
+	select {
+	case <-time.After(jitter.UpTo(s.startupJitter)):
+		s.process(ctx)
+	case <-s.stopCh:
+		return
+	case <-ctx.Done():
+		return
+	}
 
-	// Run immediately on start
-	s.process(ctx)
+	timer := time.NewTimer(jitter.Apply(s.interval, s.jitter))
+	defer timer.Stop()
 
 	for {
 		select {
-		case <-ticker.C:
+		case <-timer.C:
 			s.process(ctx)
+			timer.Reset(jitter.Apply(s.interval, s.jitter))
 		case <-s.stopCh:
 			return
 		case <-ctx.Done():
@@ -88,9 +164,11 @@ func (s *Scheduler) run(ctx context.Context) {
 
 // process runs the scheduled publication processor
 func (s *Scheduler) process(ctx context.Context) {
-	s.logger.Debug("processing scheduled publications")
+	defer s.runs.RunStarted(time.Now())()
+
+	s.logger.Debug(s.name + " processing publications")
 
 	if err := s.processor.ProcessScheduledPublications(ctx); err != nil {
-		s.logger.Error("failed to process scheduled publications", "error", err)
+		s.logger.Error(s.name+" failed to process publications", "error", err)
 	}
 }
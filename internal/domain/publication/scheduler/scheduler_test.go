@@ -0,0 +1,94 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingProcessor simulates a long-running batch
+type blockingProcessor struct {
+	started  chan struct{}
+	duration time.Duration
+	finished atomic.Bool
+}
+
+func (p *blockingProcessor) ProcessScheduledPublications(ctx context.Context) error {
+	select {
+	case p.started <- struct{}{}:
+	default:
+	}
+	select {
+	case <-time.After(p.duration):
+		p.finished.Store(true)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+func TestStopWaitsForInFlightRun(t *testing.T) {
+	proc := &blockingProcessor{started: make(chan struct{}, 1), duration: 200 * time.Millisecond}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := New(proc, time.Hour, logger).WithStopTimeout(5 * time.Second)
+
+	s.Start(context.Background())
+
+	select {
+	case <-proc.started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("processor was not invoked")
+	}
+
+	s.Stop()
+
+	if !proc.finished.Load() {
+		t.Fatal("Stop returned before the in-flight run completed")
+	}
+}
+
+func TestStopCancelsAfterTimeout(t *testing.T) {
+	proc := &blockingProcessor{started: make(chan struct{}, 1), duration: time.Hour}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := New(proc, time.Hour, logger).WithStopTimeout(50 * time.Millisecond)
+
+	s.Start(context.Background())
+	<-proc.started
+
+	start := time.Now()
+	s.Stop()
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Stop took %s, expected cancellation after the stop timeout", elapsed)
+	}
+	if proc.finished.Load() {
+		t.Fatal("expected in-flight run to be cancelled")
+	}
+}
+
+func TestStatusReportsStalledRun(t *testing.T) {
+	proc := &blockingProcessor{started: make(chan struct{}, 1), duration: time.Hour}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := New(proc, 20*time.Millisecond, logger).WithStopTimeout(50 * time.Millisecond)
+
+	s.Start(context.Background())
+	<-proc.started
+
+	if status := s.Status(); !status.Running || status.Stalled(time.Now()) {
+		t.Fatalf("status right after the run started = %+v, want running and not stalled", status)
+	}
+
+	// The run hangs for longer than two intervals
+	time.Sleep(60 * time.Millisecond)
+	if !s.Status().Stalled(time.Now()) {
+		t.Error("a run hanging for three intervals is not reported as stalled")
+	}
+
+	s.Stop()
+	if s.Status().Stalled(time.Now()) {
+		t.Error("a stopped scheduler is reported as stalled")
+	}
+}
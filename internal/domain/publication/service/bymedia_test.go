@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+)
+
+// mediaIDRepo finds its publication by Instagram media ID and counts its comments
+type mediaIDRepo struct {
+	singlePubRepo
+	comments int64
+}
+
+func (r *mediaIDRepo) GetByInstagramMediaID(_ context.Context, mediaID string) (*entity.Publication, error) {
+	if mediaID != r.pub.InstagramMediaID {
+		return nil, nil
+	}
+	pub := r.pub
+	return &pub, nil
+}
+
+func (r *mediaIDRepo) CountComments(context.Context, string) (int64, error) {
+	return r.comments, nil
+}
+
+func TestGetByInstagramMediaID(t *testing.T) {
+	pubs := &mediaIDRepo{
+		singlePubRepo: singlePubRepo{pub: entity.Publication{
+			ID:               "pub-1",
+			InstagramMediaID: "17895695668004550",
+			Status:           entity.PublicationStatusPublished,
+		}},
+		comments: 12,
+	}
+	media := &orderedMediaRepo{items: []entity.MediaItem{{ID: "a"}}}
+	svc := New(pubs, media)
+
+	got, err := svc.GetByInstagramMediaID(context.Background(), "17895695668004550")
+	if err != nil {
+		t.Fatalf("GetByInstagramMediaID: %v", err)
+	}
+	if got.ID != "pub-1" || got.CommentsCount != 12 || len(got.Media) != 1 {
+		t.Errorf("got %s with %d comments and %d media, want pub-1 with 12 and 1", got.ID, got.CommentsCount, len(got.Media))
+	}
+
+	// The count sits next to the publication's fields in the response
+	body, _ := json.Marshal(got)
+	var fields map[string]any
+	_ = json.Unmarshal(body, &fields)
+	if fields["id"] != "pub-1" || fields["comments_count"] != float64(12) {
+		t.Errorf("encoded as %s", body)
+	}
+
+	if _, err := svc.GetByInstagramMediaID(context.Background(), "unknown"); !errors.Is(err, entity.ErrPublicationNotFound) {
+		t.Errorf("unknown media: err = %v, want ErrPublicationNotFound", err)
+	}
+}
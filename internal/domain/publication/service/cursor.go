@@ -0,0 +1,36 @@
+package service
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/publication/dao"
+	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+)
+
+// EncodeCursor encodes a keyset position into an opaque pagination cursor
+func EncodeCursor(c dao.Cursor) string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor
+func DecodeCursor(s string) (*dao.Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, entity.ErrInvalidCursor
+	}
+
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return nil, entity.ErrInvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, entity.ErrInvalidCursor
+	}
+
+	return &dao.Cursor{CreatedAt: createdAt, ID: id}, nil
+}
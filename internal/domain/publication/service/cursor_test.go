@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/publication/dao"
+	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+)
+
+// keysetRepo emulates the (created_at, id) keyset query of PublicationPostgres.List
+type keysetRepo struct {
+	dao.PublicationRepository
+	pubs []entity.Publication
+}
+
+func (r *keysetRepo) List(_ context.Context, _ dao.PublicationFilter, opts dao.ListOptions) ([]entity.Publication, error) {
+	sorted := append([]entity.Publication(nil), r.pubs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if !sorted[i].CreatedAt.Equal(sorted[j].CreatedAt) {
+			return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+		}
+		return sorted[i].ID > sorted[j].ID
+	})
+
+	var out []entity.Publication
+	for _, p := range sorted {
+		if opts.After != nil {
+			if p.CreatedAt.After(opts.After.CreatedAt) {
+				continue
+			}
+			if p.CreatedAt.Equal(opts.After.CreatedAt) && p.ID >= opts.After.ID {
+				continue
+			}
+		}
+		out = append(out, p)
+		if len(out) == opts.Limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+func (r *keysetRepo) Count(context.Context, dao.PublicationFilter) (int64, error) {
+	return int64(len(r.pubs)), nil
+}
+
+type noMediaRepo struct {
+	dao.MediaRepository
+}
+
+func (noMediaRepo) GetByPublicationID(context.Context, string) ([]entity.MediaItem, error) {
+	return nil, nil
+}
+
+func TestListPublicationsKeysetWalksAllPages(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := &keysetRepo{}
+	for i := 0; i < 23; i++ {
+		// Groups of three share a timestamp so the id tie-breaker is exercised
+		repo.pubs = append(repo.pubs, entity.Publication{
+			ID:        fmt.Sprintf("pub-%02d", i),
+			CreatedAt: base.Add(time.Duration(i/3) * time.Microsecond),
+		})
+	}
+	svc := New(repo, noMediaRepo{})
+
+	seen := make(map[string]bool)
+	after := ""
+	for pages := 0; ; pages++ {
+		if pages > len(repo.pubs) {
+			t.Fatal("pagination did not terminate")
+		}
+		cursor := after
+		out, err := svc.ListPublications(context.Background(), ListInput{Limit: 5, After: &cursor})
+		if err != nil {
+			t.Fatalf("ListPublications: %v", err)
+		}
+		for _, p := range out.Publications {
+			if seen[p.ID] {
+				t.Fatalf("publication %s returned twice", p.ID)
+			}
+			seen[p.ID] = true
+		}
+		if out.NextCursor == "" {
+			break
+		}
+		after = out.NextCursor
+	}
+
+	if len(seen) != len(repo.pubs) {
+		t.Fatalf("walked %d publications, want %d", len(seen), len(repo.pubs))
+	}
+}
+
+func TestDecodeCursorRejectsGarbage(t *testing.T) {
+	for _, s := range []string{"!!!", "bm8tc2VwYXJhdG9y", EncodeCursor(dao.Cursor{CreatedAt: time.Now()})} {
+		if _, err := DecodeCursor(s); err != entity.ErrInvalidCursor {
+			t.Errorf("DecodeCursor(%q) = %v, want ErrInvalidCursor", s, err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vadim/neo-metric/internal/domain/publication/dao"
+	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+)
+
+// listOptionsRepo records the options of the last listing
+type listOptionsRepo struct {
+	dao.PublicationRepository
+	opts  *dao.ListOptions
+	lists int
+}
+
+func (r *listOptionsRepo) List(_ context.Context, _ dao.PublicationFilter, opts dao.ListOptions) ([]entity.Publication, error) {
+	r.opts = &opts
+	r.lists++
+	return nil, nil
+}
+
+func (r *listOptionsRepo) Count(context.Context, dao.PublicationFilter) (int64, error) {
+	return 0, nil
+}
+
+func TestListPublicationsSort(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       ListInput
+		wantSort string
+		wantDesc bool
+	}{
+		{"default", ListInput{}, "scheduled_at", true},
+		{"scheduled_at", ListInput{SortBy: entity.PublicationSortScheduledAt, Order: entity.SortOrderAsc}, "scheduled_at", false},
+		{"created_at", ListInput{SortBy: entity.PublicationSortCreatedAt}, "created_at", true},
+		{"published_at", ListInput{SortBy: entity.PublicationSortPublishedAt, Order: entity.SortOrderDesc}, "published_at", true},
+		{"updated_at", ListInput{SortBy: entity.PublicationSortUpdatedAt, Order: entity.SortOrderAsc}, "updated_at", false},
+		{"order only", ListInput{Order: entity.SortOrderAsc}, "scheduled_at", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &listOptionsRepo{}
+			if _, err := New(repo, &orderedMediaRepo{}).ListPublications(context.Background(), tt.in); err != nil {
+				t.Fatalf("ListPublications: %v", err)
+			}
+			if repo.opts.SortBy != tt.wantSort || repo.opts.Desc != tt.wantDesc {
+				t.Errorf("sorted by %s desc=%v, want %s desc=%v", repo.opts.SortBy, repo.opts.Desc, tt.wantSort, tt.wantDesc)
+			}
+		})
+	}
+}
+
+func TestListPublicationsConfiguredDefaultSort(t *testing.T) {
+	repo := &listOptionsRepo{}
+	svc := New(repo, &orderedMediaRepo{}).WithDefaultSort(entity.PublicationSortCreatedAt, entity.SortOrderAsc)
+
+	if _, err := svc.ListPublications(context.Background(), ListInput{}); err != nil {
+		t.Fatalf("ListPublications: %v", err)
+	}
+	if repo.opts.SortBy != "created_at" || repo.opts.Desc {
+		t.Errorf("sorted by %s desc=%v, want created_at ascending", repo.opts.SortBy, repo.opts.Desc)
+	}
+}
+
+func TestListPublicationsRejectsInvalidSort(t *testing.T) {
+	after := ""
+	tests := []struct {
+		name string
+		in   ListInput
+		want error
+	}{
+		{"unknown column", ListInput{SortBy: "caption; DROP TABLE publications"}, entity.ErrInvalidSort},
+		{"unknown order", ListInput{SortBy: entity.PublicationSortCreatedAt, Order: "sideways"}, entity.ErrInvalidSortOrder},
+		{"sort with cursor", ListInput{SortBy: entity.PublicationSortCreatedAt, After: &after}, entity.ErrSortWithCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &listOptionsRepo{}
+			_, err := New(repo, &orderedMediaRepo{}).ListPublications(context.Background(), tt.in)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if repo.lists != 0 {
+				t.Error("rejected listing reached the repository")
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+)
+
+// statusRepo applies final status updates to a single publication
+type statusRepo struct {
+	singlePubRepo
+}
+
+func (r *statusRepo) SetPublished(_ context.Context, _ string, instagramMediaID, _ string, _ time.Time) error {
+	r.pub.Status = entity.PublicationStatusPublished
+	r.pub.InstagramMediaID = instagramMediaID
+	return nil
+}
+
+func (r *statusRepo) UpdateStatus(_ context.Context, _ string, status entity.PublicationStatus, errorMsg string) error {
+	r.pub.Status = status
+	r.pub.ErrorMessage = errorMsg
+	return nil
+}
+
+// recordingNotifier keeps the publications it was told about
+type recordingNotifier struct {
+	got []entity.Publication
+}
+
+func (n *recordingNotifier) PublicationStatusChanged(_ context.Context, pub *entity.Publication) {
+	n.got = append(n.got, *pub)
+}
+
+func TestMarkAsNotifiesStoredStatus(t *testing.T) {
+	repo := &statusRepo{singlePubRepo{pub: entity.Publication{ID: "pub-1", AccountID: "acc-1", Status: entity.PublicationStatusScheduled}}}
+	notifier := &recordingNotifier{}
+	svc := New(repo, nil).WithStatusNotifier(notifier)
+
+	if err := svc.MarkAsFailed(context.Background(), "pub-1", "media expired"); err != nil {
+		t.Fatalf("MarkAsFailed: %v", err)
+	}
+	if err := svc.MarkAsPublished(context.Background(), "pub-1", "ig-1", ""); err != nil {
+		t.Fatalf("MarkAsPublished: %v", err)
+	}
+
+	if len(notifier.got) != 2 {
+		t.Fatalf("got %d notifications, want 2", len(notifier.got))
+	}
+	failed, published := notifier.got[0], notifier.got[1]
+	if failed.Status != entity.PublicationStatusError || failed.ErrorMessage != "media expired" || failed.AccountID != "acc-1" {
+		t.Errorf("failed notification = %+v", failed)
+	}
+	if published.Status != entity.PublicationStatusPublished || published.InstagramMediaID != "ig-1" {
+		t.Errorf("published notification = %+v", published)
+	}
+}
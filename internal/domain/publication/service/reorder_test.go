@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/vadim/neo-metric/internal/domain/publication/dao"
+	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+)
+
+type singlePubRepo struct {
+	dao.PublicationRepository
+	pub entity.Publication
+}
+
+func (r *singlePubRepo) GetByID(_ context.Context, id string) (*entity.Publication, error) {
+	if id != r.pub.ID {
+		return nil, nil
+	}
+	pub := r.pub
+	return &pub, nil
+}
+
+// orderedMediaRepo keeps media sorted by Order, like GetByPublicationID's ORDER BY sort_order
+type orderedMediaRepo struct {
+	dao.MediaRepository
+	items []entity.MediaItem
+}
+
+func (r *orderedMediaRepo) GetByPublicationID(context.Context, string) ([]entity.MediaItem, error) {
+	out := append([]entity.MediaItem(nil), r.items...)
+	sort.Slice(out, func(i, j int) bool { return out[i].Order < out[j].Order })
+	return out, nil
+}
+
+func (r *orderedMediaRepo) UpdateOrder(_ context.Context, _ string, mediaIDs []string) error {
+	for pos, id := range mediaIDs {
+		for i := range r.items {
+			if r.items[i].ID == id {
+				r.items[i].Order = pos
+			}
+		}
+	}
+	return nil
+}
+
+func newReorderService(status entity.PublicationStatus) *Service {
+	pubs := &singlePubRepo{pub: entity.Publication{ID: "pub-1", Status: status}}
+	media := &orderedMediaRepo{items: []entity.MediaItem{
+		{ID: "a", Order: 0},
+		{ID: "b", Order: 1},
+		{ID: "c", Order: 2},
+	}}
+	return New(pubs, media)
+}
+
+func TestReorderMediaSortsByOrder(t *testing.T) {
+	svc := newReorderService(entity.PublicationStatusDraft)
+
+	pub, err := svc.ReorderMedia(context.Background(), "pub-1", []MediaOrder{
+		{ID: "a", Order: 5},
+		{ID: "b", Order: 1},
+		{ID: "c", Order: 3},
+	})
+	if err != nil {
+		t.Fatalf("ReorderMedia: %v", err)
+	}
+
+	var got []string
+	for _, m := range pub.Media {
+		got = append(got, m.ID)
+	}
+	if want := []string{"b", "c", "a"}; len(got) != 3 || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("media order = %v, want %v", got, want)
+	}
+}
+
+func TestReorderMediaRejectsMismatch(t *testing.T) {
+	cases := map[string][]MediaOrder{
+		"missing":   {{ID: "a"}, {ID: "b"}},
+		"unknown":   {{ID: "a"}, {ID: "b"}, {ID: "x"}},
+		"duplicate": {{ID: "a"}, {ID: "b"}, {ID: "b"}},
+	}
+	for name, order := range cases {
+		svc := newReorderService(entity.PublicationStatusDraft)
+		if _, err := svc.ReorderMedia(context.Background(), "pub-1", order); err != entity.ErrMediaOrderMismatch {
+			t.Errorf("%s: err = %v, want ErrMediaOrderMismatch", name, err)
+		}
+	}
+}
+
+func TestReorderMediaRejectsNotEditable(t *testing.T) {
+	svc := newReorderService(entity.PublicationStatusPublished)
+
+	_, err := svc.ReorderMedia(context.Background(), "pub-1", []MediaOrder{{ID: "a"}, {ID: "b"}, {ID: "c"}})
+	if err != entity.ErrPublicationNotEditable {
+		t.Fatalf("err = %v, want ErrPublicationNotEditable", err)
+	}
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +15,17 @@ import (
 type Service struct {
 	publications dao.PublicationRepository
 	media        dao.MediaRepository
+	notifier     StatusNotifier // optional, told about published and failed publications
+
+	// Listing order when the caller asks for none
+	defaultSort  entity.PublicationSort
+	defaultOrder entity.SortOrder
+}
+
+// StatusNotifier is told when a publication reaches the published or error status.
+// Implementations must not block; the publish path waits for the call to return.
+type StatusNotifier interface {
+	PublicationStatusChanged(ctx context.Context, pub *entity.Publication)
 }
 
 // New creates a new publication service
@@ -21,7 +33,27 @@ func New(publications dao.PublicationRepository, media dao.MediaRepository) *Ser
 	return &Service{
 		publications: publications,
 		media:        media,
+		defaultSort:  entity.PublicationSortScheduledAt,
+		defaultOrder: entity.SortOrderDesc,
+	}
+}
+
+// WithDefaultSort sets the listing order used when the caller asks for none;
+// invalid values keep the default of scheduled_at descending
+func (s *Service) WithDefaultSort(by entity.PublicationSort, order entity.SortOrder) *Service {
+	if by.IsValid() {
+		s.defaultSort = by
+	}
+	if order.IsValid() {
+		s.defaultOrder = order
 	}
+	return s
+}
+
+// WithStatusNotifier sets the notifier for final publication statuses (nil disables it)
+func (s *Service) WithStatusNotifier(n StatusNotifier) *Service {
+	s.notifier = n
+	return s
 }
 
 // CreateInput represents input for creating a publication
@@ -31,14 +63,18 @@ type CreateInput struct {
 	Caption     string
 	Media       []MediaInput
 	ReelOptions *entity.ReelOptions // Optional settings for Reels
+	StoryOptions *entity.StoryOptions // Link sticker and mentions of stories
+	Collaborators []string // Usernames to invite as collaborators (feed posts)
 	ScheduledAt *time.Time
+	HashtagSetID *string // Hashtag set posted as the first comment after publishing
 }
 
 // MediaInput represents input for a media item
 type MediaInput struct {
-	URL   string
-	Type  entity.MediaType
-	Order int
+	URL     string
+	Type    entity.MediaType
+	Order   int
+	AltText string
 }
 
 // CreatePublication creates a new publication
@@ -59,6 +95,7 @@ func (s *Service) CreatePublication(ctx context.Context, in CreateInput) (*entit
 			URL:       m.URL,
 			Type:      m.Type,
 			Order:     m.Order,
+			AltText:   m.AltText,
 			CreatedAt: now,
 		}
 	}
@@ -71,7 +108,10 @@ func (s *Service) CreatePublication(ctx context.Context, in CreateInput) (*entit
 		Caption:     in.Caption,
 		Media:       mediaItems,
 		ReelOptions: in.ReelOptions,
+		StoryOptions: in.StoryOptions,
+		Collaborators: in.Collaborators,
 		ScheduledAt: in.ScheduledAt,
+		HashtagSetID: in.HashtagSetID,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -101,19 +141,22 @@ type UpdateInput struct {
 	ID          string
 	Caption     *string
 	Media       []MediaInput
+	ReelOptions *entity.ReelOptions // Replaces the Reel settings when set
+	StoryOptions *entity.StoryOptions // Replaces the story link and mentions when set
+	Collaborators []string // Replaces the collaborators when non-nil; empty removes them
 	ScheduledAt *time.Time
 	ClearSchedule bool // If true, clears scheduled_at and sets status to draft
+	HashtagSetID *string // Replaces the hashtag set when set
+	ClearHashtagSet bool // If true, removes the hashtag set
 }
 
 // UpdatePublication updates an existing publication
 func (s *Service) UpdatePublication(ctx context.Context, in UpdateInput) (*entity.Publication, error) {
-	pub, err := s.publications.GetByID(ctx, in.ID)
+	// Load with media: updates that keep the media are validated against it
+	pub, err := s.GetPublication(ctx, in.ID)
 	if err != nil {
 		return nil, err
 	}
-	if pub == nil {
-		return nil, entity.ErrPublicationNotFound
-	}
 
 	if !pub.IsEditable() {
 		return nil, entity.ErrPublicationNotEditable
@@ -123,13 +166,30 @@ func (s *Service) UpdatePublication(ctx context.Context, in UpdateInput) (*entit
 	if in.Caption != nil {
 		pub.Caption = *in.Caption
 	}
+	if in.ReelOptions != nil {
+		pub.ReelOptions = in.ReelOptions
+	}
+	if in.StoryOptions != nil {
+		pub.StoryOptions = in.StoryOptions
+	}
+	if in.Collaborators != nil {
+		pub.Collaborators = in.Collaborators
+	}
+	if in.ClearHashtagSet {
+		pub.HashtagSetID = nil
+	} else if in.HashtagSetID != nil {
+		pub.HashtagSetID = in.HashtagSetID
+	}
 
 	if in.ClearSchedule {
 		pub.ScheduledAt = nil
 		pub.Status = entity.PublicationStatusDraft
 	} else if in.ScheduledAt != nil {
 		pub.ScheduledAt = in.ScheduledAt
-		pub.Status = entity.PublicationStatusScheduled
+		// A paused publication stays paused with its new time until it is resumed
+		if pub.Status != entity.PublicationStatusPaused {
+			pub.Status = entity.PublicationStatusScheduled
+		}
 	}
 
 	// Update media if provided
@@ -148,6 +208,7 @@ func (s *Service) UpdatePublication(ctx context.Context, in UpdateInput) (*entit
 				URL:       m.URL,
 				Type:      m.Type,
 				Order:     m.Order,
+				AltText:   m.AltText,
 				CreatedAt: now,
 			}
 			if err := s.media.Create(ctx, pub.ID, &pub.Media[i]); err != nil {
@@ -166,10 +227,25 @@ func (s *Service) UpdatePublication(ctx context.Context, in UpdateInput) (*entit
 	if err := s.publications.Update(ctx, pub); err != nil {
 		return nil, err
 	}
+	if in.Caption != nil || len(in.Media) > 0 || in.ReelOptions != nil || in.StoryOptions != nil || in.Collaborators != nil {
+		if err := s.dropContainer(ctx, pub); err != nil {
+			return nil, err
+		}
+	}
 
 	return pub, nil
 }
 
+// dropContainer forgets a container created before the publication's content changed,
+// so the next publish builds one from the current caption and media
+func (s *Service) dropContainer(ctx context.Context, pub *entity.Publication) error {
+	if pub.ContainerID == "" {
+		return nil
+	}
+	pub.ContainerID = ""
+	return s.publications.SetContainerID(ctx, pub.ID, "")
+}
+
 // GetPublication retrieves a publication by ID
 func (s *Service) GetPublication(ctx context.Context, id string) (*entity.Publication, error) {
 	pub, err := s.publications.GetByID(ctx, id)
@@ -190,12 +266,42 @@ func (s *Service) GetPublication(ctx context.Context, id string) (*entity.Public
 	return pub, nil
 }
 
-// DeletePublication deletes a publication
+// GetByInstagramMediaID retrieves a published publication by its Instagram media ID with its comments count
+func (s *Service) GetByInstagramMediaID(ctx context.Context, instagramMediaID string) (*entity.PublicationWithComments, error) {
+	pub, err := s.publications.GetByInstagramMediaID(ctx, instagramMediaID)
+	if err != nil {
+		return nil, err
+	}
+	if pub == nil {
+		return nil, entity.ErrPublicationNotFound
+	}
+
+	media, err := s.media.GetByPublicationID(ctx, pub.ID)
+	if err != nil {
+		return nil, err
+	}
+	pub.Media = media
+
+	count, err := s.publications.CountComments(ctx, instagramMediaID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.PublicationWithComments{Publication: *pub, CommentsCount: count}, nil
+}
+
+// DeletePublication permanently deletes a publication, including one in trash
 func (s *Service) DeletePublication(ctx context.Context, id string) error {
 	pub, err := s.publications.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
+	if pub == nil {
+		pub, err = s.publications.GetTrashedByID(ctx, id)
+		if err != nil {
+			return err
+		}
+	}
 	if pub == nil {
 		return entity.ErrPublicationNotFound
 	}
@@ -212,6 +318,100 @@ func (s *Service) DeletePublication(ctx context.Context, id string) error {
 	return s.publications.Delete(ctx, id)
 }
 
+// MediaOrder assigns a position to an existing media item
+type MediaOrder struct {
+	ID    string
+	Order int
+}
+
+// ReorderMedia changes the order of a publication's media items
+// The order must list every media item of the publication exactly once.
+// Items are sorted by Order (ties keep request order) and stored with positions 0..n-1.
+func (s *Service) ReorderMedia(ctx context.Context, id string, order []MediaOrder) (*entity.Publication, error) {
+	pub, err := s.GetPublication(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !pub.IsEditable() {
+		return nil, entity.ErrPublicationNotEditable
+	}
+
+	if len(order) != len(pub.Media) {
+		return nil, entity.ErrMediaOrderMismatch
+	}
+	existing := make(map[string]bool, len(pub.Media))
+	for _, m := range pub.Media {
+		existing[m.ID] = true
+	}
+	for _, o := range order {
+		if !existing[o.ID] {
+			return nil, entity.ErrMediaOrderMismatch
+		}
+		delete(existing, o.ID) // a repeated ID no longer matches
+	}
+
+	sorted := append([]MediaOrder(nil), order...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Order < sorted[j].Order
+	})
+	mediaIDs := make([]string, len(sorted))
+	for i, o := range sorted {
+		mediaIDs[i] = o.ID
+	}
+
+	if err := s.media.UpdateOrder(ctx, id, mediaIDs); err != nil {
+		return nil, err
+	}
+	if err := s.dropContainer(ctx, pub); err != nil {
+		return nil, err
+	}
+
+	return s.GetPublication(ctx, id)
+}
+
+// TrashPublication moves a publication to trash
+func (s *Service) TrashPublication(ctx context.Context, id string) error {
+	pub, err := s.publications.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if pub == nil {
+		return entity.ErrPublicationNotFound
+	}
+
+	return s.publications.SoftDelete(ctx, id, time.Now())
+}
+
+// GetTrashedPublication retrieves a publication in trash by ID, without its media
+func (s *Service) GetTrashedPublication(ctx context.Context, id string) (*entity.Publication, error) {
+	pub, err := s.publications.GetTrashedByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if pub == nil {
+		return nil, entity.ErrPublicationNotFound
+	}
+	return pub, nil
+}
+
+// RestorePublication moves a publication out of trash
+func (s *Service) RestorePublication(ctx context.Context, id string) (*entity.Publication, error) {
+	pub, err := s.publications.GetTrashedByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if pub == nil {
+		return nil, entity.ErrPublicationNotFound
+	}
+
+	if err := s.publications.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.GetPublication(ctx, id)
+}
+
 // ListInput represents input for listing publications
 type ListInput struct {
 	AccountID string
@@ -219,14 +419,19 @@ type ListInput struct {
 	Status    *entity.PublicationStatus
 	Year      *int
 	Month     *int
+	Trashed   bool
 	Limit     int
 	Offset    int
+	After     *string                // Keyset cursor; when set (even empty), keyset pagination is used instead of offset
+	SortBy    entity.PublicationSort // Empty uses the service default; not allowed with After
+	Order     entity.SortOrder       // Empty uses the service default; not allowed with After
 }
 
 // ListOutput represents output from listing publications
 type ListOutput struct {
 	Publications []entity.Publication
 	Total        int64
+	NextCursor   string // Set in keyset mode when more pages are available
 }
 
 // ListPublications retrieves publications with filtering
@@ -237,24 +442,51 @@ func (s *Service) ListPublications(ctx context.Context, in ListInput) (*ListOutp
 		Status:    in.Status,
 		Year:      in.Year,
 		Month:     in.Month,
+		Trashed:   in.Trashed,
+	}
+
+	sortBy, order, err := s.listOrder(in)
+	if err != nil {
+		return nil, err
 	}
 
 	opts := dao.ListOptions{
 		Limit:  in.Limit,
 		Offset: in.Offset,
-		SortBy: "scheduled_at",
-		Desc:   true,
+		SortBy: string(sortBy),
+		Desc:   order == entity.SortOrderDesc,
 	}
 
 	if opts.Limit == 0 {
 		opts.Limit = 50
 	}
 
+	limit := opts.Limit
+	if in.After != nil {
+		opts.Keyset = true
+		if *in.After != "" {
+			cursor, err := DecodeCursor(*in.After)
+			if err != nil {
+				return nil, err
+			}
+			opts.After = cursor
+		}
+		// Fetch one extra row to know whether another page exists
+		opts.Limit = limit + 1
+	}
+
 	publications, err := s.publications.List(ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
 
+	var nextCursor string
+	if opts.Keyset && len(publications) > limit {
+		publications = publications[:limit]
+		last := publications[limit-1]
+		nextCursor = EncodeCursor(dao.Cursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
 	total, err := s.publications.Count(ctx, filter)
 	if err != nil {
 		return nil, err
@@ -272,9 +504,37 @@ func (s *Service) ListPublications(ctx context.Context, in ListInput) (*ListOutp
 	return &ListOutput{
 		Publications: publications,
 		Total:        total,
+		NextCursor:   nextCursor,
 	}, nil
 }
 
+// listOrder validates the requested listing order and fills in the defaults
+func (s *Service) listOrder(in ListInput) (entity.PublicationSort, entity.SortOrder, error) {
+	if in.After != nil && (in.SortBy != "" || in.Order != "") {
+		return "", "", entity.ErrSortWithCursor
+	}
+
+	sortBy, order := s.defaultSort, s.defaultOrder
+	if in.SortBy != "" {
+		if !in.SortBy.IsValid() {
+			return "", "", entity.ErrInvalidSort
+		}
+		sortBy = in.SortBy
+	}
+	if in.Order != "" {
+		if !in.Order.IsValid() {
+			return "", "", entity.ErrInvalidSortOrder
+		}
+		order = in.Order
+	}
+	return sortBy, order, nil
+}
+
+// GetPublishedTimesSince returns when an account's publications were published since the given time, oldest first
+func (s *Service) GetPublishedTimesSince(ctx context.Context, accountID string, since time.Time) ([]time.Time, error) {
+	return s.publications.GetPublishedTimesSince(ctx, accountID, since)
+}
+
 // GetScheduledForPublishing retrieves all publications ready to be published
 func (s *Service) GetScheduledForPublishing(ctx context.Context) ([]entity.Publication, error) {
 	pubs, err := s.publications.GetScheduledForPublishing(ctx, time.Now())
@@ -282,6 +542,55 @@ func (s *Service) GetScheduledForPublishing(ctx context.Context) ([]entity.Publi
 		return nil, err
 	}
 
+	return s.loadMedia(ctx, pubs)
+}
+
+// ClaimScheduledForPublishing claims up to limit publications ready to be published for the lease duration
+func (s *Service) ClaimScheduledForPublishing(ctx context.Context, lease time.Duration, limit int) ([]entity.Publication, error) {
+	now := time.Now()
+	pubs, err := s.publications.ClaimScheduledForPublishing(ctx, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.loadMedia(ctx, pubs)
+}
+
+// EnqueuePublish queues a publication for the async publish worker.
+// Returns ErrPublicationNotEditable if it is already published, queued or trashed.
+func (s *Service) EnqueuePublish(ctx context.Context, id string) error {
+	queued, err := s.publications.EnqueuePublish(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !queued {
+		return entity.ErrPublicationNotEditable
+	}
+	s.notifyStatus(ctx, id)
+	return nil
+}
+
+// ClaimQueuedForPublishing claims up to limit queued publications for the lease duration
+func (s *Service) ClaimQueuedForPublishing(ctx context.Context, lease time.Duration, limit int) ([]entity.Publication, error) {
+	now := time.Now()
+	pubs, err := s.publications.ClaimQueuedForPublishing(ctx, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.loadMedia(ctx, pubs)
+}
+
+// ClaimForReconciliation claims up to limit publications whose publish may have gone through
+// on Instagram unrecorded: in publishing or error with a container, last changed between
+// maxAge and minAge ago
+func (s *Service) ClaimForReconciliation(ctx context.Context, lease, minAge, maxAge time.Duration, limit int) ([]entity.Publication, error) {
+	now := time.Now()
+	return s.publications.ClaimForReconciliation(ctx, now, now.Add(lease), limit, now.Add(-minAge), now.Add(-maxAge))
+}
+
+// loadMedia attaches media items to each publication
+func (s *Service) loadMedia(ctx context.Context, pubs []entity.Publication) ([]entity.Publication, error) {
 	// Load media for each publication
 	for i := range pubs {
 		media, err := s.media.GetByPublicationID(ctx, pubs[i].ID)
@@ -295,13 +604,65 @@ func (s *Service) GetScheduledForPublishing(ctx context.Context) ([]entity.Publi
 }
 
 // MarkAsPublished marks a publication as successfully published
-func (s *Service) MarkAsPublished(ctx context.Context, id string, instagramMediaID string) error {
-	return s.publications.SetPublished(ctx, id, instagramMediaID, time.Now())
+// permalink may be empty if Instagram's media details could not be fetched.
+func (s *Service) MarkAsPublished(ctx context.Context, id string, instagramMediaID, permalink string) error {
+	if err := s.publications.SetPublished(ctx, id, instagramMediaID, permalink, time.Now()); err != nil {
+		return err
+	}
+	s.notifyStatus(ctx, id)
+	return nil
+}
+
+// ListPublishedWithoutPermalink returns a page of the published publications whose permalink is unknown
+func (s *Service) ListPublishedWithoutPermalink(ctx context.Context, limit, offset int) ([]entity.Publication, error) {
+	return s.publications.GetPublishedWithoutPermalink(ctx, limit, offset)
+}
+
+// SetPermalink stores the link to a published publication on Instagram
+func (s *Service) SetPermalink(ctx context.Context, id string, permalink string) error {
+	return s.publications.SetPermalink(ctx, id, permalink)
+}
+
+// SetMediaInfo stores the product type and comment setting Instagram reports for a published media
+func (s *Service) SetMediaInfo(ctx context.Context, id string, productType string, commentsEnabled *bool) error {
+	return s.publications.SetMediaInfo(ctx, id, productType, commentsEnabled)
+}
+
+// SetCommentsEnabled stores whether comments are enabled for a published media
+func (s *Service) SetCommentsEnabled(ctx context.Context, id string, enabled bool) error {
+	return s.publications.SetCommentsEnabled(ctx, id, enabled)
+}
+
+// SetContainerID remembers the Instagram container created for a publication (empty clears it)
+func (s *Service) SetContainerID(ctx context.Context, id string, containerID string) error {
+	return s.publications.SetContainerID(ctx, id, containerID)
+}
+
+// ScheduleRetry keeps a publication scheduled after a failed attempt and delays the next one
+func (s *Service) ScheduleRetry(ctx context.Context, id string, attempts int, nextAttemptAt time.Time, errorMsg string) error {
+	return s.publications.SetRetry(ctx, id, attempts, nextAttemptAt, errorMsg)
 }
 
 // MarkAsFailed marks a publication as failed with error message
 func (s *Service) MarkAsFailed(ctx context.Context, id string, errorMsg string) error {
-	return s.publications.UpdateStatus(ctx, id, entity.PublicationStatusError, errorMsg)
+	if err := s.publications.UpdateStatus(ctx, id, entity.PublicationStatusError, errorMsg); err != nil {
+		return err
+	}
+	s.notifyStatus(ctx, id)
+	return nil
+}
+
+// notifyStatus reports the stored state of a publication to the notifier.
+// Best effort: if the publication cannot be reloaded, no notification is sent.
+func (s *Service) notifyStatus(ctx context.Context, id string) {
+	if s.notifier == nil {
+		return
+	}
+	pub, err := s.publications.GetByID(ctx, id)
+	if err != nil || pub == nil {
+		return
+	}
+	s.notifier.PublicationStatusChanged(ctx, pub)
 }
 
 // SaveAsDraft saves a publication as draft (removes scheduled time)
@@ -320,6 +681,49 @@ func (s *Service) Schedule(ctx context.Context, id string, scheduledAt time.Time
 	})
 }
 
+// Pause keeps a scheduled publication with its scheduled time out of publishing until Resume
+func (s *Service) Pause(ctx context.Context, id string) (*entity.Publication, error) {
+	pub, err := s.GetPublication(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if pub.Status == entity.PublicationStatusPaused {
+		return pub, nil
+	}
+	if pub.Status != entity.PublicationStatusScheduled {
+		return nil, entity.ErrPublicationNotScheduled
+	}
+
+	pub.Status = entity.PublicationStatusPaused
+	pub.UpdatedAt = time.Now()
+	if err := s.publications.Update(ctx, pub); err != nil {
+		return nil, err
+	}
+	return pub, nil
+}
+
+// Resume schedules a paused publication again at its scheduled time.
+// Returns ErrScheduledTimeInPast if that time passed while it was paused.
+func (s *Service) Resume(ctx context.Context, id string) (*entity.Publication, error) {
+	pub, err := s.GetPublication(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if pub.Status != entity.PublicationStatusPaused {
+		return nil, entity.ErrPublicationNotPaused
+	}
+
+	pub.Status = entity.PublicationStatusScheduled
+	pub.UpdatedAt = time.Now()
+	if err := pub.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.publications.Update(ctx, pub); err != nil {
+		return nil, err
+	}
+	return pub, nil
+}
+
 // GetStatistics retrieves publication statistics for an account
 func (s *Service) GetStatistics(ctx context.Context, accountID string) (*entity.PublicationStatistics, error) {
 	return s.publications.GetStatistics(ctx, accountID)
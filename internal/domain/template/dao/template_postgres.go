@@ -50,7 +50,7 @@ func (r *TemplatePostgres) Create(ctx context.Context, tmpl *entity.Template) er
 // GetByID retrieves a template by ID
 func (r *TemplatePostgres) GetByID(ctx context.Context, id string) (*entity.Template, error) {
 	query := `
-		SELECT id, account_id, title, content, images, icon, type, usage_count, created_at, updated_at
+		SELECT id, account_id, title, content, images, icon, type, usage_count, last_used_at, created_at, updated_at
 		FROM templates
 		WHERE id = $1
 	`
@@ -65,6 +65,7 @@ func (r *TemplatePostgres) GetByID(ctx context.Context, id string) (*entity.Temp
 		&tmpl.Icon,
 		&tmpl.Type,
 		&tmpl.UsageCount,
+		&tmpl.LastUsedAt,
 		&tmpl.CreatedAt,
 		&tmpl.UpdatedAt,
 	)
@@ -126,8 +127,14 @@ func (r *TemplatePostgres) Delete(ctx context.Context, id string) error {
 type ListFilter struct {
 	AccountID string
 	Type      *entity.TemplateType
+	Types     []entity.TemplateType // Any of these types, e.g. the ones usable in direct messages
+	Query     string                // Full-text search over title and content
 }
 
+// searchVector is the tsvector expression matched by ListFilter.Query
+// Must stay in sync with idx_templates_text_search.
+const searchVector = "to_tsvector('simple', COALESCE(title, '') || ' ' || COALESCE(content, ''))"
+
 // ListOptions contains pagination and sorting options
 type ListOptions struct {
 	Limit   int
@@ -138,33 +145,14 @@ type ListOptions struct {
 
 // List retrieves templates with filtering and pagination
 func (r *TemplatePostgres) List(ctx context.Context, filter ListFilter, opts ListOptions) ([]entity.Template, error) {
+	where, args := listConditions(filter)
 	query := `
-		SELECT id, account_id, title, content, images, icon, type, usage_count, created_at, updated_at
+		SELECT id, account_id, title, content, images, icon, type, usage_count, last_used_at, created_at, updated_at
 		FROM templates
-		WHERE account_id = $1
-	`
-	args := []interface{}{filter.AccountID}
-	argNum := 2
-
-	if filter.Type != nil {
-		query += fmt.Sprintf(" AND type = $%d", argNum)
-		args = append(args, *filter.Type)
-		argNum++
-	}
+	` + where
+	argNum := len(args) + 1
 
-	// Sorting
-	sortCol := "usage_count"
-	if opts.SortBy != "" {
-		switch opts.SortBy {
-		case "usage_count", "created_at", "updated_at", "title":
-			sortCol = opts.SortBy
-		}
-	}
-	order := "DESC"
-	if !opts.Desc {
-		order = "ASC"
-	}
-	query += fmt.Sprintf(" ORDER BY %s %s", sortCol, order)
+	query += listOrderBy(opts)
 
 	// Pagination
 	if opts.Limit > 0 {
@@ -195,6 +183,7 @@ func (r *TemplatePostgres) List(ctx context.Context, filter ListFilter, opts Lis
 			&tmpl.Icon,
 			&tmpl.Type,
 			&tmpl.UsageCount,
+			&tmpl.LastUsedAt,
 			&tmpl.CreatedAt,
 			&tmpl.UpdatedAt,
 		)
@@ -207,15 +196,59 @@ func (r *TemplatePostgres) List(ctx context.Context, filter ListFilter, opts Lis
 	return templates, nil
 }
 
-// Count returns the total count of templates for an account
-func (r *TemplatePostgres) Count(ctx context.Context, filter ListFilter) (int64, error) {
-	query := "SELECT COUNT(*) FROM templates WHERE account_id = $1"
+// listConditions builds the WHERE clause shared by List and Count, filtering before any limit applies
+func listConditions(filter ListFilter) (string, []interface{}) {
+	where := " WHERE account_id = $1"
 	args := []interface{}{filter.AccountID}
 
 	if filter.Type != nil {
-		query += " AND type = $2"
 		args = append(args, *filter.Type)
+		where += fmt.Sprintf(" AND type = $%d", len(args))
+	}
+
+	if len(filter.Types) > 0 {
+		types := make([]string, len(filter.Types))
+		for i, t := range filter.Types {
+			types[i] = string(t)
+		}
+		args = append(args, types)
+		where += fmt.Sprintf(" AND type = ANY($%d)", len(args))
+	}
+
+	if filter.Query != "" {
+		args = append(args, filter.Query)
+		where += fmt.Sprintf(" AND %s @@ plainto_tsquery('simple', $%d)", searchVector, len(args))
+	}
+
+	return where, args
+}
+
+// templateSortColumns are the columns List may sort by; SortBy is interpolated into the
+// query, so anything else falls back to the default rather than reaching the database
+var templateSortColumns = map[string]bool{
+	"usage_count": true,
+	"created_at":  true,
+	"updated_at":  true,
+	"title":       true,
+}
+
+// listOrderBy builds the ORDER BY clause of List
+func listOrderBy(opts ListOptions) string {
+	sortCol := "usage_count"
+	if templateSortColumns[opts.SortBy] {
+		sortCol = opts.SortBy
+	}
+	order := "DESC"
+	if !opts.Desc {
+		order = "ASC"
 	}
+	return fmt.Sprintf(" ORDER BY %s %s", sortCol, order)
+}
+
+// Count returns the total count of templates for an account
+func (r *TemplatePostgres) Count(ctx context.Context, filter ListFilter) (int64, error) {
+	where, args := listConditions(filter)
+	query := "SELECT COUNT(*) FROM templates" + where
 
 	var count int64
 	err := r.pool.QueryRow(ctx, query, args...).Scan(&count)
@@ -226,10 +259,10 @@ func (r *TemplatePostgres) Count(ctx context.Context, filter ListFilter) (int64,
 	return count, nil
 }
 
-// IncrementUsageCount increments the usage count of a template
+// IncrementUsageCount increments the usage count of a template and records when it was used
 func (r *TemplatePostgres) IncrementUsageCount(ctx context.Context, id string) error {
 	result, err := r.pool.Exec(ctx,
-		"UPDATE templates SET usage_count = usage_count + 1, updated_at = $2 WHERE id = $1",
+		"UPDATE templates SET usage_count = usage_count + 1, last_used_at = $2, updated_at = $2 WHERE id = $1",
 		id, time.Now(),
 	)
 	if err != nil {
@@ -242,3 +275,56 @@ func (r *TemplatePostgres) IncrementUsageCount(ctx context.Context, id string) e
 
 	return nil
 }
+
+// AnalyticsFilter contains filters for template usage analytics
+type AnalyticsFilter struct {
+	AccountID string
+	Type      *entity.TemplateType
+	From      *time.Time // Only templates last used at or after From
+	To        *time.Time // Only templates last used at or before To
+}
+
+// GetUsageAnalytics returns per-template usage, most used first
+func (r *TemplatePostgres) GetUsageAnalytics(ctx context.Context, filter AnalyticsFilter) ([]entity.TemplateUsage, error) {
+	query := `
+		SELECT id, title, type, usage_count, last_used_at
+		FROM templates
+		WHERE account_id = $1
+	`
+	args := []interface{}{filter.AccountID}
+	argNum := 2
+
+	if filter.Type != nil {
+		query += fmt.Sprintf(" AND type = $%d", argNum)
+		args = append(args, *filter.Type)
+		argNum++
+	}
+	if filter.From != nil {
+		query += fmt.Sprintf(" AND last_used_at >= $%d", argNum)
+		args = append(args, *filter.From)
+		argNum++
+	}
+	if filter.To != nil {
+		query += fmt.Sprintf(" AND last_used_at <= $%d", argNum)
+		args = append(args, *filter.To)
+	}
+
+	query += " ORDER BY usage_count DESC, last_used_at DESC NULLS LAST, title ASC"
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying template usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []entity.TemplateUsage
+	for rows.Next() {
+		var u entity.TemplateUsage
+		if err := rows.Scan(&u.ID, &u.Title, &u.Type, &u.UsageCount, &u.LastUsedAt); err != nil {
+			return nil, fmt.Errorf("scanning template usage: %w", err)
+		}
+		usage = append(usage, u)
+	}
+
+	return usage, nil
+}
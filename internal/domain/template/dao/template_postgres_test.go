@@ -0,0 +1,49 @@
+package dao
+
+import (
+	"testing"
+
+	"github.com/vadim/neo-metric/internal/domain/template/entity"
+)
+
+func TestListOrderBy(t *testing.T) {
+	tests := []struct {
+		opts ListOptions
+		want string
+	}{
+		{ListOptions{}, " ORDER BY usage_count ASC"},
+		{ListOptions{SortBy: "title", Desc: true}, " ORDER BY title DESC"},
+		{ListOptions{SortBy: "updated_at"}, " ORDER BY updated_at ASC"},
+		// Unknown columns never reach the query
+		{ListOptions{SortBy: "id; DROP TABLE templates", Desc: true}, " ORDER BY usage_count DESC"},
+		{ListOptions{SortBy: "title DESC, (SELECT 1)"}, " ORDER BY usage_count ASC"},
+	}
+	for _, tt := range tests {
+		if got := listOrderBy(tt.opts); got != tt.want {
+			t.Errorf("listOrderBy(%+v) = %q, want %q", tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestListConditions(t *testing.T) {
+	direct := entity.TemplateTypeDirect
+	tests := []struct {
+		filter    ListFilter
+		wantWhere string
+		wantArgs  int
+	}{
+		{ListFilter{AccountID: "acc-1"}, " WHERE account_id = $1", 1},
+		{ListFilter{AccountID: "acc-1", Type: &direct}, " WHERE account_id = $1 AND type = $2", 2},
+		{
+			ListFilter{AccountID: "acc-1", Types: []entity.TemplateType{entity.TemplateTypeDirect, entity.TemplateTypeBoth}, Query: "hi"},
+			" WHERE account_id = $1 AND type = ANY($2) AND " + searchVector + " @@ plainto_tsquery('simple', $3)",
+			3,
+		},
+	}
+	for _, tt := range tests {
+		where, args := listConditions(tt.filter)
+		if where != tt.wantWhere || len(args) != tt.wantArgs {
+			t.Errorf("listConditions(%+v) = %q with %d args, want %q with %d", tt.filter, where, len(args), tt.wantWhere, tt.wantArgs)
+		}
+	}
+}
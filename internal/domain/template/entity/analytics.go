@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// TemplateUsage describes how often a template has been used
+type TemplateUsage struct {
+	ID         string       `json:"id"`
+	Title      string       `json:"title"`
+	Type       TemplateType `json:"type"`
+	UsageCount int          `json:"usage_count"`
+	LastUsedAt *time.Time   `json:"last_used_at,omitempty"`
+}
+
+// TemplateAnalytics summarizes template usage for an account
+// Usage counts are lifetime totals; a date range selects templates by last use.
+type TemplateAnalytics struct {
+	Templates       []TemplateUsage `json:"templates"`
+	TotalTemplates  int             `json:"total_templates"`
+	TotalUsage      int64           `json:"total_usage"`
+	UnusedTemplates int             `json:"unused_templates"`
+}
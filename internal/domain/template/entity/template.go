@@ -3,6 +3,8 @@ package entity
 import (
 	"errors"
 	"time"
+
+	"github.com/vadim/neo-metric/internal/hashtag"
 )
 
 // TemplateType represents the type of template
@@ -12,6 +14,9 @@ const (
 	TemplateTypeDirect  TemplateType = "direct"
 	TemplateTypeComment TemplateType = "comment"
 	TemplateTypeBoth    TemplateType = "both"
+
+	// TemplateTypeHashtags is a hashtag set, posted as the first comment of a publication
+	TemplateTypeHashtags TemplateType = "hashtags"
 )
 
 // Template represents a reusable message template
@@ -24,6 +29,7 @@ type Template struct {
 	Icon       string       `json:"icon,omitempty"`
 	Type       TemplateType `json:"type"`
 	UsageCount int          `json:"usage_count"`
+	LastUsedAt *time.Time   `json:"last_used_at,omitempty"`
 	CreatedAt  time.Time    `json:"created_at"`
 	UpdatedAt  time.Time    `json:"updated_at"`
 }
@@ -37,6 +43,10 @@ var (
 	ErrTitleTooLong        = errors.New("template title exceeds maximum length")
 	ErrContentTooLong      = errors.New("template content exceeds maximum length")
 	ErrTooManyImages       = errors.New("too many images in template")
+	ErrInvalidDateRange    = errors.New("start date must not be after end date")
+	ErrNoHashtags          = errors.New("hashtag set must contain at least one hashtag")
+	ErrTooManyHashtags     = errors.New("hashtag set exceeds Instagram's limit of 30 hashtags")
+	ErrAccountForbidden    = errors.New("not allowed to access this account")
 )
 
 // MaxTitleLength is the maximum length of a template title
@@ -62,13 +72,22 @@ func (t *Template) Validate() error {
 	if !IsValidTemplateType(t.Type) {
 		return ErrInvalidTemplateType
 	}
+	if t.Type == TemplateTypeHashtags {
+		n := hashtag.Count(t.Content)
+		if n == 0 {
+			return ErrNoHashtags
+		}
+		if n > hashtag.MaxPerPost {
+			return ErrTooManyHashtags
+		}
+	}
 	return nil
 }
 
 // IsValidTemplateType checks if a template type is valid
 func IsValidTemplateType(t TemplateType) bool {
 	switch t {
-	case TemplateTypeDirect, TemplateTypeComment, TemplateTypeBoth:
+	case TemplateTypeDirect, TemplateTypeComment, TemplateTypeBoth, TemplateTypeHashtags:
 		return true
 	}
 	return false
@@ -83,6 +102,8 @@ func ParseTemplateType(s string) (TemplateType, error) {
 		return TemplateTypeComment, nil
 	case "both":
 		return TemplateTypeBoth, nil
+	case "hashtags":
+		return TemplateTypeHashtags, nil
 	default:
 		return "", ErrInvalidTemplateType
 	}
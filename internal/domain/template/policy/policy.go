@@ -2,6 +2,7 @@ package policy
 
 import (
 	"context"
+	"time"
 
 	"github.com/vadim/neo-metric/internal/domain/template/entity"
 	"github.com/vadim/neo-metric/internal/domain/template/service"
@@ -15,11 +16,18 @@ type TemplateService interface {
 	Delete(ctx context.Context, id, accountID string) error
 	List(ctx context.Context, in service.ListInput) (*service.ListOutput, error)
 	IncrementUsage(ctx context.Context, id, accountID string) error
+	GetAnalytics(ctx context.Context, in service.AnalyticsInput) (*entity.TemplateAnalytics, error)
+}
+
+// AccountAuthorizer reports whether the caller may act on an account
+type AccountAuthorizer interface {
+	AccountAllowed(ctx context.Context, accountID string) bool
 }
 
 // Policy handles template operations
 type Policy struct {
-	svc TemplateService
+	svc   TemplateService
+	authz AccountAuthorizer // optional, all accounts allowed if unset
 }
 
 // New creates a new template policy
@@ -27,6 +35,21 @@ func New(svc TemplateService) *Policy {
 	return &Policy{svc: svc}
 }
 
+// WithAccountAuthorizer restricts every operation to the accounts the caller may act on
+func (p *Policy) WithAccountAuthorizer(a AccountAuthorizer) *Policy {
+	p.authz = a
+	return p
+}
+
+// authorize returns ErrAccountForbidden if the caller may not act on the account.
+// An empty account ID stands for all accounts, which only unrestricted callers may act on.
+func (p *Policy) authorize(ctx context.Context, accountID string) error {
+	if p.authz != nil && !p.authz.AccountAllowed(ctx, accountID) {
+		return entity.ErrAccountForbidden
+	}
+	return nil
+}
+
 // CreateInput represents input for creating a template
 type CreateInput struct {
 	AccountID string
@@ -39,6 +62,9 @@ type CreateInput struct {
 
 // Create creates a new template
 func (p *Policy) Create(ctx context.Context, in CreateInput) (*entity.Template, error) {
+	if err := p.authorize(ctx, in.AccountID); err != nil {
+		return nil, err
+	}
 	return p.svc.Create(ctx, service.CreateInput{
 		AccountID: in.AccountID,
 		Title:     in.Title,
@@ -51,6 +77,10 @@ func (p *Policy) Create(ctx context.Context, in CreateInput) (*entity.Template,
 
 // GetByID retrieves a template by ID
 func (p *Policy) GetByID(ctx context.Context, id, accountID string) (*entity.Template, error) {
+	if err := p.authorize(ctx, accountID); err != nil {
+		return nil, err
+	}
+
 	tmpl, err := p.svc.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -77,6 +107,9 @@ type UpdateInput struct {
 
 // Update updates an existing template
 func (p *Policy) Update(ctx context.Context, in UpdateInput) (*entity.Template, error) {
+	if err := p.authorize(ctx, in.AccountID); err != nil {
+		return nil, err
+	}
 	return p.svc.Update(ctx, service.UpdateInput{
 		ID:        in.ID,
 		AccountID: in.AccountID,
@@ -90,6 +123,9 @@ func (p *Policy) Update(ctx context.Context, in UpdateInput) (*entity.Template,
 
 // Delete removes a template
 func (p *Policy) Delete(ctx context.Context, id, accountID string) error {
+	if err := p.authorize(ctx, accountID); err != nil {
+		return err
+	}
 	return p.svc.Delete(ctx, id, accountID)
 }
 
@@ -97,6 +133,8 @@ func (p *Policy) Delete(ctx context.Context, id, accountID string) error {
 type ListInput struct {
 	AccountID string
 	Type      *entity.TemplateType
+	Types     []entity.TemplateType // Any of these types
+	Query     string
 	Limit     int
 	Offset    int
 	SortBy    string
@@ -111,9 +149,15 @@ type ListOutput struct {
 
 // List retrieves templates with filtering and pagination
 func (p *Policy) List(ctx context.Context, in ListInput) (*ListOutput, error) {
+	if err := p.authorize(ctx, in.AccountID); err != nil {
+		return nil, err
+	}
+
 	result, err := p.svc.List(ctx, service.ListInput{
 		AccountID: in.AccountID,
 		Type:      in.Type,
+		Types:     in.Types,
+		Query:     in.Query,
 		Limit:     in.Limit,
 		Offset:    in.Offset,
 		SortBy:    in.SortBy,
@@ -131,5 +175,29 @@ func (p *Policy) List(ctx context.Context, in ListInput) (*ListOutput, error) {
 
 // IncrementUsage increments the usage count of a template
 func (p *Policy) IncrementUsage(ctx context.Context, id, accountID string) error {
+	if err := p.authorize(ctx, accountID); err != nil {
+		return err
+	}
 	return p.svc.IncrementUsage(ctx, id, accountID)
 }
+
+// AnalyticsInput represents input for template usage analytics
+type AnalyticsInput struct {
+	AccountID string
+	Type      *entity.TemplateType
+	From      *time.Time
+	To        *time.Time
+}
+
+// GetAnalytics returns template usage analytics for an account
+func (p *Policy) GetAnalytics(ctx context.Context, in AnalyticsInput) (*entity.TemplateAnalytics, error) {
+	if err := p.authorize(ctx, in.AccountID); err != nil {
+		return nil, err
+	}
+	return p.svc.GetAnalytics(ctx, service.AnalyticsInput{
+		AccountID: in.AccountID,
+		Type:      in.Type,
+		From:      in.From,
+		To:        in.To,
+	})
+}
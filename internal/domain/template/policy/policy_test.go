@@ -0,0 +1,59 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vadim/neo-metric/internal/domain/template/entity"
+)
+
+// scopedAuthorizer allows only the listed accounts, like an API key restricted to them
+type scopedAuthorizer map[string]bool
+
+func (a scopedAuthorizer) AccountAllowed(_ context.Context, accountID string) bool {
+	return a[accountID]
+}
+
+// untouchedService fails the test if the policy lets a call through
+type untouchedService struct {
+	TemplateService
+	t *testing.T
+}
+
+func (s untouchedService) GetByID(context.Context, string) (*entity.Template, error) {
+	s.t.Error("GetByID called for a forbidden account")
+	return &entity.Template{ID: "tpl-1", AccountID: "acc-1"}, nil
+}
+
+func (s untouchedService) Delete(context.Context, string, string) error {
+	s.t.Error("Delete called for a forbidden account")
+	return nil
+}
+
+func TestScopedKeyCannotTouchOtherAccountsTemplates(t *testing.T) {
+	p := New(untouchedService{t: t}).WithAccountAuthorizer(scopedAuthorizer{"acc-2": true})
+	ctx := context.Background()
+	title := "changed"
+
+	ops := map[string]func() error{
+		"create": func() error { _, err := p.Create(ctx, CreateInput{AccountID: "acc-1", Title: "t"}); return err },
+		"get":    func() error { _, err := p.GetByID(ctx, "tpl-1", "acc-1"); return err },
+		"update": func() error {
+			_, err := p.Update(ctx, UpdateInput{ID: "tpl-1", AccountID: "acc-1", Title: &title})
+			return err
+		},
+		"delete":        func() error { return p.Delete(ctx, "tpl-1", "acc-1") },
+		"list":          func() error { _, err := p.List(ctx, ListInput{AccountID: "acc-1"}); return err },
+		"list all":      func() error { _, err := p.List(ctx, ListInput{}); return err },
+		"increment use": func() error { return p.IncrementUsage(ctx, "tpl-1", "acc-1") },
+		"analytics":     func() error { _, err := p.GetAnalytics(ctx, AnalyticsInput{AccountID: "acc-1"}); return err },
+	}
+	for name, op := range ops {
+		t.Run(name, func(t *testing.T) {
+			if err := op(); !errors.Is(err, entity.ErrAccountForbidden) {
+				t.Errorf("error = %v, want ErrAccountForbidden", err)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/vadim/neo-metric/internal/domain/template/entity"
 )
@@ -16,12 +18,15 @@ type TemplateRepository interface {
 	List(ctx context.Context, filter ListFilter, opts ListOptions) ([]entity.Template, error)
 	Count(ctx context.Context, filter ListFilter) (int64, error)
 	IncrementUsageCount(ctx context.Context, id string) error
+	GetUsageAnalytics(ctx context.Context, filter AnalyticsFilter) ([]entity.TemplateUsage, error)
 }
 
 // ListFilter contains filters for listing templates
 type ListFilter struct {
 	AccountID string
 	Type      *entity.TemplateType
+	Types     []entity.TemplateType // Any of these types
+	Query     string
 }
 
 // ListOptions contains pagination and sorting options
@@ -32,6 +37,14 @@ type ListOptions struct {
 	Desc   bool
 }
 
+// AnalyticsFilter contains filters for template usage analytics
+type AnalyticsFilter struct {
+	AccountID string
+	Type      *entity.TemplateType
+	From      *time.Time
+	To        *time.Time
+}
+
 // Service handles template business logic
 type Service struct {
 	repo TemplateRepository
@@ -166,6 +179,8 @@ func (s *Service) Delete(ctx context.Context, id, accountID string) error {
 type ListInput struct {
 	AccountID string
 	Type      *entity.TemplateType
+	Types     []entity.TemplateType // Any of these types
+	Query     string                // Full-text search over title and content
 	Limit     int
 	Offset    int
 	SortBy    string
@@ -188,6 +203,8 @@ func (s *Service) List(ctx context.Context, in ListInput) (*ListOutput, error) {
 	filter := ListFilter{
 		AccountID: in.AccountID,
 		Type:      in.Type,
+		Types:     in.Types,
+		Query:     strings.TrimSpace(in.Query),
 	}
 
 	opts := ListOptions{
@@ -234,3 +251,47 @@ func (s *Service) IncrementUsage(ctx context.Context, id, accountID string) erro
 
 	return nil
 }
+
+// AnalyticsInput represents input for template usage analytics
+type AnalyticsInput struct {
+	AccountID string
+	Type      *entity.TemplateType
+	From      *time.Time
+	To        *time.Time
+}
+
+// GetAnalytics returns templates sorted by usage with account totals
+func (s *Service) GetAnalytics(ctx context.Context, in AnalyticsInput) (*entity.TemplateAnalytics, error) {
+	if in.Type != nil && !entity.IsValidTemplateType(*in.Type) {
+		return nil, entity.ErrInvalidTemplateType
+	}
+	if in.From != nil && in.To != nil && in.From.After(*in.To) {
+		return nil, entity.ErrInvalidDateRange
+	}
+
+	usage, err := s.repo.GetUsageAnalytics(ctx, AnalyticsFilter{
+		AccountID: in.AccountID,
+		Type:      in.Type,
+		From:      in.From,
+		To:        in.To,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting template usage: %w", err)
+	}
+
+	out := &entity.TemplateAnalytics{
+		Templates:      usage,
+		TotalTemplates: len(usage),
+	}
+	if out.Templates == nil {
+		out.Templates = []entity.TemplateUsage{}
+	}
+	for _, u := range usage {
+		out.TotalUsage += int64(u.UsageCount)
+		if u.UsageCount == 0 {
+			out.UnusedTemplates++
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,33 @@
+// Package hashtag counts Instagram hashtags, which Instagram limits per post across the
+// caption and the comments its author adds.
+package hashtag
+
+import "unicode"
+
+// MaxPerPost is the number of hashtags Instagram allows on a post
+const MaxPerPost = 30
+
+// Count returns the number of hashtags in text. A hashtag is a # starting a word and
+// followed by at least one letter, digit or underscore; "#" alone or "a#b" is not one.
+func Count(text string) int {
+	count := 0
+	prev := ' '
+	inTag := false
+	for _, r := range text {
+		switch {
+		case r == '#' && !isTagRune(prev) && prev != '#':
+			inTag = true
+		case inTag && isTagRune(r):
+			count++
+			inTag = false
+		default:
+			inTag = false
+		}
+		prev = r
+	}
+	return count
+}
+
+func isTagRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
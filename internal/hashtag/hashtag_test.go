@@ -0,0 +1,31 @@
+package hashtag
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"no tags here", 0},
+		{"#sunset", 1},
+		{"Golden hour #sunset #beach_life\n#море", 3},
+		{"#one#two", 1}, // A # inside a word does not start a tag
+		{"# alone, ## and #!", 0},
+		{"price#1 vs #1", 1},
+		{"(#wrapped) #tag.", 2},
+	}
+	for _, tt := range tests {
+		if got := Count(tt.text); got != tt.want {
+			t.Errorf("Count(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+
+	if got := Count(strings.Repeat("#tag ", MaxPerPost+1)); got != MaxPerPost+1 {
+		t.Errorf("Count of %d tags = %d", MaxPerPost+1, got)
+	}
+}
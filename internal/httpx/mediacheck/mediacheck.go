@@ -0,0 +1,60 @@
+// Package mediacheck verifies that media URLs serve an image or video before
+// Instagram is asked to fetch them.
+package mediacheck
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Checker issues HEAD requests to media URLs
+type Checker struct {
+	client *http.Client
+	logger *slog.Logger
+}
+
+// New creates a checker; each request is bounded by timeout
+func New(timeout time.Duration, logger *slog.Logger) *Checker {
+	return &Checker{
+		client: &http.Client{Timeout: timeout},
+		logger: logger,
+	}
+}
+
+// Check returns an error describing why url cannot be used as media: a non-2xx
+// status or a content type other than image or video. URLs that cannot be
+// reached at all (DNS, connection, timeout) are logged and pass, since the
+// problem may be on our side of the network rather than with the media.
+func (c *Checker) Check(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return fmt.Errorf("%s is not a valid URL: %v", url, err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		c.logger.Warn("media URL check skipped: URL unreachable", "url", url, "error", err)
+		return nil
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned HTTP %d", url, resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !(strings.HasPrefix(mediaType, "image/") || strings.HasPrefix(mediaType, "video/")) {
+		return fmt.Errorf("%s has content type %q, want an image or video", url, contentType)
+	}
+
+	return nil
+}
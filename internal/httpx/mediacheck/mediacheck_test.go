@@ -0,0 +1,64 @@
+package mediacheck
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newChecker() *Checker {
+	return New(time.Second, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestCheck(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("method = %s, want HEAD", r.Method)
+		}
+		switch r.URL.Path {
+		case "/photo.jpg":
+			w.Header().Set("Content-Type", "image/jpeg")
+		case "/clip.mp4":
+			w.Header().Set("Content-Type", "video/mp4")
+		case "/page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		path    string
+		wantErr string
+	}{
+		{"/photo.jpg", ""},
+		{"/clip.mp4", ""},
+		{"/missing.jpg", "returned HTTP 404"},
+		{"/page", `content type "text/html; charset=utf-8"`},
+	}
+	for _, tc := range tests {
+		err := newChecker().Check(context.Background(), srv.URL+tc.path)
+		switch {
+		case tc.wantErr == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tc.path, err)
+		case tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)):
+			t.Errorf("%s: err = %v, want it to contain %q", tc.path, err, tc.wantErr)
+		}
+	}
+}
+
+func TestCheckUnreachablePasses(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL + "/photo.jpg"
+	srv.Close()
+
+	if err := newChecker().Check(context.Background(), url); err != nil {
+		t.Errorf("unreachable URL: err = %v, want nil", err)
+	}
+}
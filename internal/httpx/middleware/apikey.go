@@ -0,0 +1,210 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/vadim/neo-metric/internal/httpx/response"
+)
+
+// APIKey is an accepted API key with an optional account scope
+type APIKey struct {
+	Key        string
+	AccountIDs []string // Empty means the key may act on any account
+	Tier       string   // Rate limit tier; empty uses the default limit
+}
+
+// ParseAPIKeys parses key entries of the form "key", "key:acc1|acc2" or
+// "key:acc1|acc2:tier"; "key::tier" sets a tier without restricting accounts
+func ParseAPIKeys(entries []string) ([]APIKey, error) {
+	keys := make([]APIKey, 0, len(entries))
+	for i, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		key, rest, _ := strings.Cut(entry, ":")
+		if key == "" {
+			return nil, fmt.Errorf("api key %d is empty", i)
+		}
+		scope, tier, _ := strings.Cut(rest, ":")
+
+		k := APIKey{Key: key, Tier: strings.TrimSpace(tier)}
+		for _, id := range strings.Split(scope, "|") {
+			if id = strings.TrimSpace(id); id != "" {
+				k.AccountIDs = append(k.AccountIDs, id)
+			}
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+type contextKey int
+
+const (
+	accountScopeKey contextKey = iota
+	principalKey
+	tierKey
+)
+
+// maxScopeBodySize bounds how much of a JSON body is buffered to find account_id
+const maxScopeBodySize = 1 << 20
+
+type hashedKey struct {
+	hash      [sha256.Size]byte
+	scope     map[string]struct{} // nil means unrestricted
+	principal string              // Fingerprint identifying the key without revealing it
+	tier      string
+}
+
+// APIKeyAuth returns a middleware that requires a valid API key in the
+// "Authorization: Bearer <key>" or "X-API-Key" header.
+// For account-scoped keys, the account_id in the query string or JSON body
+// must be one of the allowed accounts; handlers can check other account
+// references with AccountAllowed.
+func APIKeyAuth(keys []APIKey) func(http.Handler) http.Handler {
+	hashed := make([]hashedKey, len(keys))
+	for i, k := range keys {
+		hashed[i].hash = sha256.Sum256([]byte(k.Key))
+		hashed[i].principal = "key:" + hex.EncodeToString(hashed[i].hash[:6])
+		hashed[i].tier = k.Tier
+		if len(k.AccountIDs) > 0 {
+			hashed[i].scope = make(map[string]struct{}, len(k.AccountIDs))
+			for _, id := range k.AccountIDs {
+				hashed[i].scope[id] = struct{}{}
+			}
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := extractAPIKey(r)
+			if token == "" {
+				response.Unauthorized(w, "missing API key")
+				return
+			}
+
+			// Compare against every key so timing does not reveal which one matched
+			tokenHash := sha256.Sum256([]byte(token))
+			var matched *hashedKey
+			for i := range hashed {
+				if subtle.ConstantTimeCompare(tokenHash[:], hashed[i].hash[:]) == 1 {
+					matched = &hashed[i]
+				}
+			}
+			if matched == nil {
+				response.Unauthorized(w, "invalid API key")
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), principalKey, matched.principal)
+			r = r.WithContext(context.WithValue(ctx, tierKey, matched.tier))
+
+			if matched.scope != nil {
+				accountID, err := requestAccountID(r)
+				if err != nil {
+					response.BadRequest(w, err.Error())
+					return
+				}
+				if accountID != "" {
+					if _, ok := matched.scope[accountID]; !ok {
+						response.Forbidden(w, "API key is not allowed to access this account")
+						return
+					}
+				}
+				r = r.WithContext(context.WithValue(r.Context(), accountScopeKey, matched.scope))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// AccountAllowed reports whether the request's API key may act on the account.
+// Always true when authentication is disabled or the key is unrestricted.
+func AccountAllowed(ctx context.Context, accountID string) bool {
+	scope, ok := ctx.Value(accountScopeKey).(map[string]struct{})
+	if !ok {
+		return true
+	}
+	_, allowed := scope[accountID]
+	return allowed
+}
+
+// AllowedAccounts returns the accounts the request's API key is restricted to, sorted,
+// or nil when authentication is disabled or the key is unrestricted
+func AllowedAccounts(ctx context.Context) []string {
+	scope, ok := ctx.Value(accountScopeKey).(map[string]struct{})
+	if !ok {
+		return nil
+	}
+	return slices.Sorted(maps.Keys(scope))
+}
+
+// Principal returns a fingerprint of the API key that authenticated the request,
+// or an empty string when authentication is disabled or the call did not come from a request.
+func Principal(ctx context.Context) string {
+	principal, _ := ctx.Value(principalKey).(string)
+	return principal
+}
+
+// keyTier returns the rate limit tier of the API key that authenticated the request
+func keyTier(ctx context.Context) string {
+	tier, _ := ctx.Value(tierKey).(string)
+	return tier
+}
+
+func extractAPIKey(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if token, ok := strings.CutPrefix(auth, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
+
+// requestAccountID finds the account_id a request targets in the query string
+// and a JSON body, which must agree if both are set. The body is restored for the handler.
+func requestAccountID(r *http.Request) (string, error) {
+	queryID := r.URL.Query().Get("account_id")
+
+	if r.Body == nil || r.ContentLength == 0 {
+		return queryID, nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		return queryID, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxScopeBodySize+1))
+	if err != nil {
+		return "", fmt.Errorf("reading request body: %w", err)
+	}
+	if len(body) > maxScopeBodySize {
+		return "", fmt.Errorf("request body too large")
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	var payload struct {
+		AccountID string `json:"account_id"`
+	}
+	// Malformed JSON is left for the handler to report
+	_ = json.Unmarshal(body, &payload)
+
+	switch {
+	case queryID == "":
+		return payload.AccountID, nil
+	case payload.AccountID != "" && payload.AccountID != queryID:
+		return "", fmt.Errorf("account_id in query and body differ")
+	}
+	return queryID, nil
+}
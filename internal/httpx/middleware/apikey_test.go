@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAPIKeyAuthSetsPrincipal(t *testing.T) {
+	var principal string
+	h := APIKeyAuth([]APIKey{{Key: "secret-key"}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal = Principal(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/publications/pub-1/publish", nil)
+	req.Header.Set("X-API-Key", "secret-key")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !strings.HasPrefix(principal, "key:") || len(principal) != len("key:")+12 {
+		t.Fatalf("principal = %q, want key fingerprint", principal)
+	}
+	if strings.Contains(principal, "secret") {
+		t.Errorf("principal %q reveals the key", principal)
+	}
+}
+
+func TestPrincipalWithoutAuth(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if got := Principal(req.Context()); got != "" {
+		t.Errorf("principal = %q, want empty", got)
+	}
+}
+
+func TestScopedKeyRejectsConflictingAccountIDs(t *testing.T) {
+	var called bool
+	h := APIKeyAuth([]APIKey{{Key: "scoped", AccountIDs: []string{"acc-1"}}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	send := func(query, body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/templates"+query, strings.NewReader(body))
+		req.Header.Set("X-API-Key", "scoped")
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := send("?account_id=acc-1", `{"account_id":"acc-2"}`); code != http.StatusBadRequest || called {
+		t.Errorf("query and body differ = %d (handler called: %v), want 400", code, called)
+	}
+	if code := send("", `{"account_id":"acc-2"}`); code != http.StatusForbidden || called {
+		t.Errorf("body account outside scope = %d, want 403", code)
+	}
+	if code := send("?account_id=acc-1", `{"account_id":"acc-1"}`); code != http.StatusOK || !called {
+		t.Errorf("matching account_id = %d, want 200", code)
+	}
+}
+
+func TestParseAPIKeysTier(t *testing.T) {
+	keys, err := ParseAPIKeys([]string{"plain", "scoped:1|2", "scoped-bulk:1:bulk", "bulk::bulk"})
+	if err != nil {
+		t.Fatalf("ParseAPIKeys: %v", err)
+	}
+	want := []struct {
+		accounts int
+		tier     string
+	}{{0, ""}, {2, ""}, {1, "bulk"}, {0, "bulk"}}
+	for i, w := range want {
+		if len(keys[i].AccountIDs) != w.accounts || keys[i].Tier != w.tier {
+			t.Errorf("key %d = %+v, want %d accounts and tier %q", i, keys[i], w.accounts, w.tier)
+		}
+	}
+}
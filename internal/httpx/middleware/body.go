@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+
+	"github.com/vadim/neo-metric/internal/httpx/response"
+)
+
+// JSONBody returns a middleware for JSON endpoints: POST, PUT and PATCH requests
+// with a body must declare Content-Type application/json, and every body is
+// limited to maxBytes (reads beyond the limit fail with *http.MaxBytesError).
+func JSONBody(maxBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch:
+				if r.ContentLength != 0 {
+					mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+					if err != nil || mediaType != "application/json" {
+						response.Error(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+						return
+					}
+				}
+			}
+
+			if r.Body != nil {
+				r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// serveCompressed runs h behind Compress with a 1 KiB threshold
+func serveCompressed(t *testing.T, h http.HandlerFunc, acceptEncoding string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/comments", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	rec := httptest.NewRecorder()
+	Compress(CompressOptions{MinSize: 1024})(h).ServeHTTP(rec, req)
+	return rec
+}
+
+// jsonHandler answers with a JSON array of n comments
+func jsonHandler(n int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		comments := make([]map[string]string, n)
+		for i := range comments {
+			comments[i] = map[string]string{"id": "17858893269000001", "text": "Nice post!"}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(comments)
+	}
+}
+
+func TestCompressGzipsLargeJSON(t *testing.T) {
+	rec := serveCompressed(t, jsonHandler(1000), "br, gzip;q=0.8")
+
+	if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+	if got := rec.Header().Get("Vary"); got != "Accept-Encoding" {
+		t.Errorf("Vary = %q, want Accept-Encoding", got)
+	}
+
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	var comments []map[string]string
+	if err := json.NewDecoder(zr).Decode(&comments); err != nil {
+		t.Fatalf("decoding body: %v", err)
+	}
+	if len(comments) != 1000 {
+		t.Errorf("got %d comments, want 1000", len(comments))
+	}
+}
+
+func TestCompressLeavesResponsesUncompressed(t *testing.T) {
+	image := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write(make([]byte, 4096))
+	}
+	precompressed := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "br")
+		_, _ = w.Write(make([]byte, 4096))
+	}
+
+	tests := []struct {
+		name           string
+		handler        http.HandlerFunc
+		acceptEncoding string
+		wantLen        int
+	}{
+		{"client without gzip", jsonHandler(1000), "", -1},
+		{"gzip refused", jsonHandler(1000), "gzip;q=0", -1},
+		{"small body", jsonHandler(1), "gzip", -1},
+		{"image", image, "gzip", 4096},
+		{"already encoded", precompressed, "gzip", 4096},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveCompressed(t, tt.handler, tt.acceptEncoding)
+			if got := rec.Header().Get("Content-Encoding"); got == "gzip" {
+				t.Fatalf("response was gzipped")
+			}
+			if tt.wantLen >= 0 && rec.Body.Len() != tt.wantLen {
+				t.Errorf("body has %d bytes, want %d", rec.Body.Len(), tt.wantLen)
+			}
+			if tt.wantLen < 0 && !strings.HasPrefix(rec.Body.String(), "[{") {
+				t.Errorf("body = %.20q..., want the JSON as is", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestCompressKeepsStatusOfSmallBodies(t *testing.T) {
+	rec := serveCompressed(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"error":"not found"}`)
+	}, "gzip")
+
+	if rec.Code != http.StatusNotFound || rec.Body.String() != `{"error":"not found"}` {
+		t.Errorf("got %d %q, want the 404 body as is", rec.Code, rec.Body)
+	}
+}
+
+func TestCompressFlushesStreamedResponse(t *testing.T) {
+	rec := serveCompressed(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"conversations":[`)
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("Flush: %v", err)
+		}
+		_, _ = io.WriteString(w, `]}`)
+	}, "gzip")
+
+	if !rec.Flushed || rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("flushed = %v, Content-Encoding = %q; want a flushed gzip stream", rec.Flushed, rec.Header().Get("Content-Encoding"))
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	body, _ := io.ReadAll(zr)
+	if string(body) != `{"conversations":[]}` {
+		t.Errorf("body = %q", body)
+	}
+}
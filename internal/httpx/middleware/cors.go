@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSOptions configures the CORS middleware
+type CORSOptions struct {
+	AllowedOrigins   []string // Exact origins or "*"; empty denies all cross-origin requests
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string // Response headers scripts may read, e.g. ETag
+	AllowCredentials bool
+	MaxAge           time.Duration // How long browsers may cache a preflight response
+}
+
+// ErrCORSWildcardCredentials is returned when "*" is combined with credentials
+var ErrCORSWildcardCredentials = errors.New(`CORS: wildcard origin "*" cannot be used with credentials`)
+
+// CORS returns a middleware that adds CORS headers for allowed origins and answers preflight requests
+func CORS(opts CORSOptions) (func(http.Handler) http.Handler, error) {
+	origins := make(map[string]struct{}, len(opts.AllowedOrigins))
+	wildcard := false
+	for _, o := range opts.AllowedOrigins {
+		o = strings.TrimSpace(o)
+		if o == "*" {
+			wildcard = true
+			continue
+		}
+		if o != "" {
+			origins[o] = struct{}{}
+		}
+	}
+	if wildcard && opts.AllowCredentials {
+		return nil, ErrCORSWildcardCredentials
+	}
+
+	methods := make(map[string]struct{}, len(opts.AllowedMethods))
+	for _, m := range opts.AllowedMethods {
+		methods[strings.ToUpper(strings.TrimSpace(m))] = struct{}{}
+	}
+	allowMethods := strings.Join(opts.AllowedMethods, ", ")
+	allowHeaders := strings.Join(opts.AllowedHeaders, ", ")
+	exposeHeaders := strings.Join(opts.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(opts.MaxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			_, allowed := origins[origin]
+			if !allowed && !wildcard {
+				if preflight {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				// Serve without CORS headers; the browser will block the response
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if wildcard && !allowed {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+			if opts.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if !preflight {
+				if exposeHeaders != "" {
+					w.Header().Set("Access-Control-Expose-Headers", exposeHeaders)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			requested := strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))
+			if _, ok := methods[requested]; !ok {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+			w.Header().Set("Access-Control-Allow-Methods", allowMethods)
+			if allowHeaders != "" {
+				w.Header().Set("Access-Control-Allow-Headers", allowHeaders)
+			}
+			if opts.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", maxAge)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}, nil
+}
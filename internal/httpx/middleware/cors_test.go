@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newCORSHandler(t *testing.T, opts CORSOptions) http.Handler {
+	t.Helper()
+	mw, err := CORS(opts)
+	if err != nil {
+		t.Fatalf("CORS: %v", err)
+	}
+	return mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+var testCORSOptions = CORSOptions{
+	AllowedOrigins:   []string{"https://admin.example.com"},
+	AllowedMethods:   []string{"GET", "POST", "DELETE"},
+	AllowedHeaders:   []string{"Authorization", "Content-Type"},
+	AllowCredentials: true,
+	MaxAge:           10 * time.Minute,
+}
+
+func TestCORSPreflightAllowedOrigin(t *testing.T) {
+	h := newCORSHandler(t, testCORSOptions)
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/v1/publications", nil)
+	req.Header.Set("Origin", "https://admin.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	want := map[string]string{
+		"Access-Control-Allow-Origin":      "https://admin.example.com",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Allow-Methods":     "GET, POST, DELETE",
+		"Access-Control-Allow-Headers":     "Authorization, Content-Type",
+		"Access-Control-Max-Age":           "600",
+	}
+	for k, v := range want {
+		if got := rec.Header().Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+}
+
+func TestCORSExposesHeaders(t *testing.T) {
+	opts := testCORSOptions
+	opts.ExposedHeaders = []string{"ETag", "Retry-After"}
+	h := newCORSHandler(t, opts)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/publications/pub-1", nil)
+	req.Header.Set("Origin", "https://admin.example.com")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Access-Control-Expose-Headers"); got != "ETag, Retry-After" {
+		t.Errorf("Access-Control-Expose-Headers = %q, want %q", got, "ETag, Retry-After")
+	}
+}
+
+func TestCORSDisallowedOrigin(t *testing.T) {
+	h := newCORSHandler(t, testCORSOptions)
+
+	// Preflight from an unknown origin is rejected
+	req := httptest.NewRequest(http.MethodOptions, "/api/v1/publications", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("preflight status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("preflight Access-Control-Allow-Origin = %q, want empty", got)
+	}
+
+	// Simple request is served but without CORS headers
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/publications", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want empty", got)
+	}
+}
+
+func TestCORSRejectsWildcardWithCredentials(t *testing.T) {
+	_, err := CORS(CORSOptions{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+	if err != ErrCORSWildcardCredentials {
+		t.Fatalf("err = %v, want ErrCORSWildcardCredentials", err)
+	}
+}
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/httpx/response"
+)
+
+// rateLimitSweepInterval is how often buckets of clients gone quiet are dropped
+const rateLimitSweepInterval = time.Minute
+
+// Limit is a token bucket: Rate requests per second on average, with bursts of up to Burst
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimitOptions configures RateLimit
+type RateLimitOptions struct {
+	Default Limit            // Keys without a tier, and clients identified by IP when authentication is off
+	Tiers   map[string]Limit // By the tier of the API key, see APIKey.Tier
+}
+
+// ParseRateLimitTiers parses tier entries of the form "name=rate/burst", e.g. "bulk=50/100"
+func ParseRateLimitTiers(entries []string) (map[string]Limit, error) {
+	tiers := make(map[string]Limit, len(entries))
+	for i, entry := range entries {
+		name, spec, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		name = strings.TrimSpace(name)
+		rateStr, burstStr, okSpec := strings.Cut(spec, "/")
+		if !ok || !okSpec || name == "" {
+			return nil, fmt.Errorf("rate limit tier %d: want name=rate/burst, got %q", i, entry)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(rateStr), 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("rate limit tier %q: rate must be a positive number", name)
+		}
+		burst, err := strconv.Atoi(strings.TrimSpace(burstStr))
+		if err != nil || burst < 1 {
+			return nil, fmt.Errorf("rate limit tier %q: burst must be a positive integer", name)
+		}
+		tiers[name] = Limit{Rate: rate, Burst: burst}
+	}
+	return tiers, nil
+}
+
+// RateLimit returns a middleware that limits requests per API key, or per client IP
+// for requests without one. It must run after APIKeyAuth to see the key; a client over
+// its limit gets 429 Too Many Requests with Retry-After in seconds.
+func RateLimit(opts RateLimitOptions) func(http.Handler) http.Handler {
+	return newRateLimiter(opts, time.Now).middleware
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	limit  Limit
+}
+
+type rateLimiter struct {
+	opts RateLimitOptions
+	now  func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func newRateLimiter(opts RateLimitOptions, now func() time.Time) *rateLimiter {
+	return &rateLimiter{opts: opts, now: now, buckets: make(map[string]*bucket), lastSweep: now()}
+}
+
+func (l *rateLimiter) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client, limit := l.client(r)
+		if wait, ok := l.take(client, limit); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			response.CodedError(w, http.StatusTooManyRequests, response.CodeRateLimited, "rate limit exceeded")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// client identifies who a request counts against and the limit that applies
+func (l *rateLimiter) client(r *http.Request) (string, Limit) {
+	if principal := Principal(r.Context()); principal != "" {
+		if limit, ok := l.opts.Tiers[keyTier(r.Context())]; ok {
+			return principal, limit
+		}
+		return principal, l.opts.Default
+	}
+
+	// RealIP has already replaced RemoteAddr with the client address behind a proxy
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	return "ip:" + ip, l.opts.Default
+}
+
+// take spends a token of the client's bucket, or reports how long until one is available
+func (l *rateLimiter) take(client string, limit Limit) (time.Duration, bool) {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= rateLimitSweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[client]
+	if !ok || b.limit != limit {
+		b = &bucket{tokens: float64(limit.Burst), last: now, limit: limit}
+		l.buckets[client] = b
+	}
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second)), false
+	}
+	b.tokens--
+	return 0, true
+}
+
+// sweep drops buckets that have refilled completely; a new bucket starts full anyway
+func (l *rateLimiter) sweep(now time.Time) {
+	for client, b := range l.buckets {
+		refill := time.Duration((float64(b.limit.Burst) - b.tokens) / b.limit.Rate * float64(time.Second))
+		if now.Sub(b.last) >= refill {
+			delete(l.buckets, client)
+		}
+	}
+	l.lastSweep = now
+}
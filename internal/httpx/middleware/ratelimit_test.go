@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/httpx/response"
+)
+
+// fakeClock is a settable time source for the rate limiter
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func newLimitedHandler(opts RateLimitOptions, keys []APIKey, clock *fakeClock) http.Handler {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	h := newRateLimiter(opts, clock.now).middleware(ok)
+	if keys != nil {
+		h = APIKeyAuth(keys)(h)
+	}
+	return h
+}
+
+func sendAs(h http.Handler, key, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/publications", nil)
+	req.RemoteAddr = remoteAddr
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimitRejectsClientOverLimit(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	h := newLimitedHandler(RateLimitOptions{Default: Limit{Rate: 0.5, Burst: 3}}, []APIKey{{Key: "a"}, {Key: "b"}}, clock)
+
+	for i := 0; i < 3; i++ {
+		if rec := sendAs(h, "a", "10.0.0.1:1234"); rec.Code != http.StatusOK {
+			t.Fatalf("request %d = %d, want 200 within the burst", i+1, rec.Code)
+		}
+	}
+
+	rec := sendAs(h, "a", "10.0.0.1:1234")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("request over the burst = %d, want 429", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want 2 (one token at 0.5/s)", got)
+	}
+	var body struct {
+		Error response.ErrorBody `json:"error"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || body.Error.Code != response.CodeRateLimited {
+		t.Errorf("error code = %q (%v), want %s", body.Error.Code, err, response.CodeRateLimited)
+	}
+
+	// Another key from the same address has its own bucket
+	if rec := sendAs(h, "b", "10.0.0.1:1234"); rec.Code != http.StatusOK {
+		t.Errorf("other key = %d, want 200", rec.Code)
+	}
+
+	clock.t = clock.t.Add(2 * time.Second)
+	if rec := sendAs(h, "a", "10.0.0.1:1234"); rec.Code != http.StatusOK {
+		t.Errorf("after Retry-After = %d, want 200", rec.Code)
+	}
+}
+
+func TestRateLimitUsesKeyTier(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	opts := RateLimitOptions{
+		Default: Limit{Rate: 1, Burst: 1},
+		Tiers:   map[string]Limit{"bulk": {Rate: 1, Burst: 5}},
+	}
+	h := newLimitedHandler(opts, []APIKey{{Key: "basic"}, {Key: "batch", Tier: "bulk"}}, clock)
+
+	allowed := func(key string) int {
+		n := 0
+		for i := 0; i < 10; i++ {
+			if sendAs(h, key, "10.0.0.1:1234").Code == http.StatusOK {
+				n++
+			}
+		}
+		return n
+	}
+	if got := allowed("basic"); got != 1 {
+		t.Errorf("default key got %d requests through, want 1", got)
+	}
+	if got := allowed("batch"); got != 5 {
+		t.Errorf("bulk key got %d requests through, want 5", got)
+	}
+}
+
+func TestRateLimitFallsBackToIP(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	h := newLimitedHandler(RateLimitOptions{Default: Limit{Rate: 1, Burst: 1}}, nil, clock)
+
+	if rec := sendAs(h, "", "10.0.0.1:1234"); rec.Code != http.StatusOK {
+		t.Fatalf("first request = %d, want 200", rec.Code)
+	}
+	if rec := sendAs(h, "", "10.0.0.1:5678"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("same IP, other port = %d, want 429", rec.Code)
+	}
+	if rec := sendAs(h, "", "10.0.0.2:1234"); rec.Code != http.StatusOK {
+		t.Errorf("other IP = %d, want 200", rec.Code)
+	}
+}
+
+func TestRateLimitSweepsIdleClients(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	l := newRateLimiter(RateLimitOptions{Default: Limit{Rate: 1, Burst: 2}}, clock.now)
+
+	l.take("ip:10.0.0.1", l.opts.Default)
+	clock.t = clock.t.Add(rateLimitSweepInterval)
+	l.take("ip:10.0.0.2", l.opts.Default)
+
+	if _, ok := l.buckets["ip:10.0.0.1"]; ok {
+		t.Error("idle client's bucket was kept")
+	}
+	if len(l.buckets) != 1 {
+		t.Errorf("buckets = %d, want 1", len(l.buckets))
+	}
+}
+
+func TestParseRateLimitTiers(t *testing.T) {
+	got, err := ParseRateLimitTiers([]string{"bulk=50/100", " slow = 0.5 / 2 "})
+	if err != nil {
+		t.Fatalf("ParseRateLimitTiers: %v", err)
+	}
+	want := map[string]Limit{"bulk": {Rate: 50, Burst: 100}, "slow": {Rate: 0.5, Burst: 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tiers = %v, want %v", got, want)
+	}
+
+	for _, entry := range []string{"bulk", "bulk=50", "=1/1", "bulk=0/10", "bulk=5/0", "bulk=x/1"} {
+		if _, err := ParseRateLimitTiers([]string{entry}); err == nil {
+			t.Errorf("ParseRateLimitTiers(%q) succeeded, want an error", entry)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// writeGrace is added to the route timeout when extending the connection write
+// deadline, so a handler that hits its timeout can still send its error response.
+const writeGrace = 5 * time.Second
+
+// TimeoutOptions configures per-route request timeouts. A zero duration means no limit.
+type TimeoutOptions struct {
+	Read  time.Duration // GET, HEAD and OPTIONS requests
+	Write time.Duration // Other requests
+	Long  time.Duration // Requests matched by IsLong, e.g. publishing that polls Instagram
+
+	IsLong func(r *http.Request) bool
+}
+
+// For returns the timeout that applies to r
+func (o TimeoutOptions) For(r *http.Request) time.Duration {
+	if o.IsLong != nil && o.IsLong(r) {
+		return o.Long
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return o.Read
+	default:
+		return o.Write
+	}
+}
+
+// Timeout returns a middleware that bounds each request's context by its route timeout.
+// The connection write deadline is moved to match, so long routes are not cut off by
+// the server-wide WriteTimeout and short routes give up on slow clients sooner.
+func Timeout(opts TimeoutOptions) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			d := opts.For(r)
+			rc := http.NewResponseController(w)
+
+			if d <= 0 {
+				// Not supported by every ResponseWriter (e.g. in tests); the server default applies then
+				_ = rc.SetWriteDeadline(time.Time{})
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			_ = rc.SetWriteDeadline(time.Now().Add(d + writeGrace))
+
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testTimeoutOptions = TimeoutOptions{
+	Read:  20 * time.Millisecond,
+	Write: 20 * time.Millisecond,
+	Long:  0,
+	IsLong: func(r *http.Request) bool {
+		return r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/publish")
+	},
+}
+
+// slowHandler simulates a handler polling Instagram for d; it reports 504 if its context ends first
+func slowHandler(d time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(d):
+			w.WriteHeader(http.StatusOK)
+		case <-r.Context().Done():
+			w.WriteHeader(http.StatusGatewayTimeout)
+		}
+	})
+}
+
+func TestTimeoutFor(t *testing.T) {
+	opts := TimeoutOptions{
+		Read:   time.Second,
+		Write:  2 * time.Second,
+		Long:   3 * time.Second,
+		IsLong: testTimeoutOptions.IsLong,
+	}
+
+	tests := []struct {
+		method, path string
+		want         time.Duration
+	}{
+		{http.MethodGet, "/api/v1/publications", time.Second},
+		{http.MethodPut, "/api/v1/publications/1", 2 * time.Second},
+		{http.MethodPost, "/api/v1/publications/1/publish", 3 * time.Second},
+		{http.MethodGet, "/api/v1/publications/1/publish", time.Second},
+	}
+	for _, tt := range tests {
+		if got := opts.For(httptest.NewRequest(tt.method, tt.path, nil)); got != tt.want {
+			t.Errorf("%s %s: timeout = %s, want %s", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestTimeoutCancelsShortRoutes(t *testing.T) {
+	h := Timeout(testTimeoutOptions)(slowHandler(time.Second))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/publications", nil))
+
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusGatewayTimeout)
+	}
+}
+
+func TestTimeoutLongPublishNotCancelled(t *testing.T) {
+	// Server-wide WriteTimeout far below the publish duration, as in production
+	srv := httptest.NewUnstartedServer(Timeout(testTimeoutOptions)(slowHandler(200 * time.Millisecond)))
+	srv.Config.WriteTimeout = 50 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/api/v1/publications/1/publish", "application/json", nil)
+	if err != nil {
+		t.Fatalf("publish request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
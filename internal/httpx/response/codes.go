@@ -0,0 +1,165 @@
+package response
+
+import "net/http"
+
+// Code is a stable machine-readable error code. Clients branch on the code;
+// the message accompanying it is for humans and may change.
+type Code string
+
+// Generic codes, sent when no more specific code applies
+const (
+	CodeBadRequest           Code = "BAD_REQUEST"
+	CodeUnauthorized         Code = "UNAUTHORIZED"
+	CodeForbidden            Code = "FORBIDDEN"
+	CodeNotFound             Code = "NOT_FOUND"
+	CodeConflict             Code = "CONFLICT"
+	CodeUnsupportedMediaType Code = "UNSUPPORTED_MEDIA_TYPE"
+	CodeUnprocessable        Code = "UNPROCESSABLE"
+	CodeRateLimited          Code = "RATE_LIMITED"
+	CodeInternal             Code = "INTERNAL_ERROR"
+	CodeBadGateway           Code = "BAD_GATEWAY"
+	CodeUnavailable          Code = "SERVICE_UNAVAILABLE"
+)
+
+// Request codes
+const (
+	CodeAccountIDRequired Code = "ACCOUNT_ID_REQUIRED"
+	CodeInvalidJSON       Code = "INVALID_JSON"
+	CodeValidationFailed  Code = "VALIDATION_FAILED" // See ErrorBody.Fields
+)
+
+// Field codes, sent per field with CodeValidationFailed
+const (
+	CodeRequired Code = "REQUIRED"
+	CodeInvalid  Code = "INVALID"
+)
+
+// Publication codes
+const (
+	CodePublicationNotFound     Code = "PUBLICATION_NOT_FOUND"
+	CodePublicationNotEditable  Code = "PUBLICATION_NOT_EDITABLE"
+	CodePublicationNotDeletable Code = "PUBLICATION_NOT_DELETABLE"
+	CodePublicationNotPublished Code = "PUBLICATION_NOT_PUBLISHED"
+	CodePublicationNotScheduled Code = "PUBLICATION_NOT_SCHEDULED"
+	CodePublicationNotPaused    Code = "PUBLICATION_NOT_PAUSED"
+	CodeNoMedia                 Code = "NO_MEDIA"
+	CodeTooManyMediaItems       Code = "TOO_MANY_MEDIA_ITEMS"
+	CodeTooFewCarouselItems     Code = "TOO_FEW_CAROUSEL_ITEMS"
+	CodeSingleMediaRequired     Code = "SINGLE_MEDIA_REQUIRED"
+	CodeCaptionTooLong          Code = "CAPTION_TOO_LONG"
+	CodeScheduledTimeInPast     Code = "SCHEDULED_TIME_IN_PAST"
+	CodePublishNowScheduled     Code = "PUBLISH_NOW_SCHEDULED"
+	CodeReelOptionsNotReel      Code = "REEL_OPTIONS_NOT_REEL"
+	CodeStoryOptionsNotStory    Code = "STORY_OPTIONS_NOT_STORY"
+	CodeInvalidStoryLink        Code = "INVALID_STORY_LINK"
+	CodeTooManyMentions         Code = "TOO_MANY_MENTIONS"
+	CodeStoryStickersRejected   Code = "STORY_STICKERS_REJECTED"
+	CodeCollaboratorsNotPost    Code = "COLLABORATORS_NOT_POST"
+	CodeTooManyCollaborators    Code = "TOO_MANY_COLLABORATORS"
+	CodeInvalidPublicationType  Code = "INVALID_PUBLICATION_TYPE"
+	CodeInvalidStatus           Code = "INVALID_STATUS"
+	CodeAltTextTooLong          Code = "ALT_TEXT_TOO_LONG"
+	CodeAltTextNotSupported     Code = "ALT_TEXT_NOT_SUPPORTED"
+	CodeInvalidCursor           Code = "INVALID_CURSOR"
+	CodeInvalidSort             Code = "INVALID_SORT"
+	CodeInvalidSortOrder        Code = "INVALID_SORT_ORDER"
+	CodeSortWithCursor          Code = "SORT_WITH_CURSOR"
+	CodeMediaOrderMismatch      Code = "MEDIA_ORDER_MISMATCH"
+	CodeMediaURLUnusable        Code = "MEDIA_URL_UNUSABLE"
+	CodeContainerFailed         Code = "CONTAINER_FAILED"
+	CodeContainerExpired        Code = "CONTAINER_EXPIRED"
+	CodeContainerNotReady       Code = "CONTAINER_NOT_READY"
+	CodeContainerPublished      Code = "CONTAINER_PUBLISHED"
+	CodeDailyPublishingLimit    Code = "DAILY_PUBLISHING_LIMIT"
+	CodeTooManyHashtags         Code = "TOO_MANY_HASHTAGS"
+	CodeHashtagSetNotFound      Code = "HASHTAG_SET_NOT_FOUND"
+)
+
+// Instagram codes
+const (
+	CodeInstagramUnauthorized Code = "INSTAGRAM_UNAUTHORIZED"
+	CodeInstagramRateLimited  Code = "INSTAGRAM_RATE_LIMITED"
+	CodeInstagramUnavailable  Code = "INSTAGRAM_UNAVAILABLE"
+	CodeInstagramAPIFailure   Code = "INSTAGRAM_API_FAILURE"
+)
+
+// Comment codes
+const (
+	CodeCommentNotFound     Code = "COMMENT_NOT_FOUND"
+	CodeMediaNotFound       Code = "MEDIA_NOT_FOUND"
+	CodeEmptyReplyText      Code = "EMPTY_REPLY_TEXT"
+	CodeReplyTextTooLong    Code = "REPLY_TEXT_TOO_LONG"
+	CodeNoCommentIDs        Code = "NO_COMMENT_IDS"
+	CodeTooManyCommentIDs   Code = "TOO_MANY_COMMENT_IDS"
+	CodeInvalidSentiment    Code = "INVALID_SENTIMENT"
+	CodeInvalidTopPostsSort Code = "INVALID_TOP_POSTS_SORT"
+	CodeInvalidTreeDepth    Code = "INVALID_TREE_DEPTH"
+	CodeCommentingDisabled  Code = "COMMENTING_DISABLED"
+	CodeSyncInProgress      Code = "SYNC_IN_PROGRESS"
+)
+
+// Direct message codes
+const (
+	CodeConversationNotFound Code = "CONVERSATION_NOT_FOUND"
+	CodeMessageNotFound      Code = "MESSAGE_NOT_FOUND"
+	CodeEmptyMessage         Code = "EMPTY_MESSAGE"
+	CodeMessageTooLong       Code = "MESSAGE_TOO_LONG"
+	CodeInvalidMediaType     Code = "INVALID_MEDIA_TYPE"
+	CodeInvalidReaction      Code = "INVALID_REACTION"
+	CodeTemplateNotDirect    Code = "TEMPLATE_NOT_DIRECT"
+	CodeNoLabels             Code = "NO_LABELS"
+	CodeInvalidLabel         Code = "INVALID_LABEL"
+	CodeTooManyLabels        Code = "TOO_MANY_LABELS"
+)
+
+// Media upload codes
+const (
+	CodeUploadRejected      Code = "UPLOAD_REJECTED"
+	CodeStorageUnavailable  Code = "STORAGE_UNAVAILABLE"
+	CodeMediaTypeNotAllowed Code = "MEDIA_TYPE_NOT_ALLOWED"
+	CodeMediaTypeMismatch   Code = "MEDIA_TYPE_MISMATCH"
+	CodeFitSourceInvalid    Code = "FIT_SOURCE_INVALID"
+)
+
+// Template codes
+const (
+	CodeTemplateNotFound    Code = "TEMPLATE_NOT_FOUND"
+	CodeEmptyTitle          Code = "EMPTY_TITLE"
+	CodeEmptyContent        Code = "EMPTY_CONTENT"
+	CodeInvalidTemplateType Code = "INVALID_TEMPLATE_TYPE"
+	CodeTitleTooLong        Code = "TITLE_TOO_LONG"
+	CodeContentTooLong      Code = "CONTENT_TOO_LONG"
+	CodeTooManyImages       Code = "TOO_MANY_IMAGES"
+	CodeInvalidDateRange    Code = "INVALID_DATE_RANGE"
+	CodeNoHashtags          Code = "NO_HASHTAGS"
+)
+
+// statusCode returns the generic code for an HTTP status
+func statusCode(status int) Code {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusUnsupportedMediaType:
+		return CodeUnsupportedMediaType
+	case http.StatusUnprocessableEntity:
+		return CodeUnprocessable
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusBadGateway:
+		return CodeBadGateway
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
@@ -0,0 +1,42 @@
+package response
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WeakETag returns a weak entity tag for a resource that changes whenever its updated_at does
+func WeakETag(id string, updatedAt time.Time) string {
+	sum := sha256.Sum256([]byte(id + "|" + updatedAt.UTC().Format(time.RFC3339Nano)))
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// NotModified sets the ETag header and, if the request's If-None-Match matches it,
+// sends a 304 Not Modified and reports true; the caller then must not write a body
+func NotModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	if !etagMatches(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches compares an If-None-Match header with an entity tag the weak way,
+// ignoring the W/ prefix on either side as RFC 9110 asks for If-None-Match
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWeakETagChangesWithUpdatedAt(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tag := WeakETag("pub-1", at)
+	if tag != WeakETag("pub-1", at.In(time.FixedZone("MSK", 3*3600))) {
+		t.Error("same instant in another zone gave a different tag")
+	}
+	if tag == WeakETag("pub-1", at.Add(time.Microsecond)) {
+		t.Error("tag did not change with updated_at")
+	}
+	if tag == WeakETag("pub-2", at) {
+		t.Error("tag did not change with id")
+	}
+	if tag[:3] != `W/"` || tag[len(tag)-1] != '"' {
+		t.Errorf("tag = %s, want a weak quoted tag", tag)
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	const etag = `W/"abc"`
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        bool
+	}{
+		{"no header", "", false},
+		{"same tag", `W/"abc"`, true},
+		{"strong form of the tag", `"abc"`, true},
+		{"one of a list", `"old", W/"abc"`, true},
+		{"any", "*", true},
+		{"other tag", `W/"def"`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			rec := httptest.NewRecorder()
+
+			if got := NotModified(rec, req, etag); got != tt.want {
+				t.Fatalf("NotModified = %v, want %v", got, tt.want)
+			}
+			if rec.Header().Get("ETag") != etag {
+				t.Errorf("ETag = %q, want %q", rec.Header().Get("ETag"), etag)
+			}
+			if tt.want && rec.Code != http.StatusNotModified {
+				t.Errorf("status = %d, want 304", rec.Code)
+			}
+		})
+	}
+}
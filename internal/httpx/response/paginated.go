@@ -0,0 +1,101 @@
+package response
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+// Pagination parameter errors
+var (
+	ErrInvalidLimit  = errors.New("invalid limit")
+	ErrInvalidOffset = errors.New("invalid offset")
+)
+
+// EnvelopeHeader opts a request into the paginated envelope; the "envelope" query parameter does the same.
+// List endpoints keep their legacy shapes for clients that send neither.
+const EnvelopeHeader = "X-Response-Envelope"
+
+// Pagination describes the page returned by a list endpoint
+type Pagination struct {
+	Total      *int64 `json:"total,omitempty"` // Omitted when the source cannot count, e.g. Instagram cursors
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// Page is the paginated envelope shared by list endpoints
+type Page struct {
+	Data       interface{} `json:"data"`
+	Pagination Pagination  `json:"pagination"`
+}
+
+// WantsEnvelope reports whether the client asked for the paginated envelope
+func WantsEnvelope(r *http.Request) bool {
+	if v := r.URL.Query().Get("envelope"); v != "" {
+		b, _ := strconv.ParseBool(v)
+		return b
+	}
+	b, _ := strconv.ParseBool(r.Header.Get(EnvelopeHeader))
+	return b
+}
+
+// Paginated sends a 200 OK response with items wrapped in the paginated envelope.
+// A nil slice is sent as an empty array.
+func Paginated(w http.ResponseWriter, items interface{}, meta Pagination) {
+	if v := reflect.ValueOf(items); !v.IsValid() || (v.Kind() == reflect.Slice && v.IsNil()) {
+		items = []interface{}{}
+	}
+	OK(w, Page{Data: items, Pagination: meta})
+}
+
+// PageLimits are the page sizes of a list endpoint: Default when the client sends no limit,
+// Max as the cap for larger ones
+type PageLimits struct {
+	Default int
+	Max     int
+}
+
+// DefaultPageLimits are used by list endpoints unless configured otherwise
+var DefaultPageLimits = PageLimits{Default: 50, Max: 100}
+
+// ParseLimit reads the "limit" query parameter. Limits above the cap are clamped;
+// anything but a positive integer is rejected with ErrInvalidLimit.
+func (l PageLimits) ParseLimit(r *http.Request) (int, error) {
+	s := r.URL.Query().Get("limit")
+	if s == "" {
+		return l.Default, nil
+	}
+	limit, err := strconv.Atoi(s)
+	if err != nil || limit < 1 {
+		return 0, ErrInvalidLimit
+	}
+	return min(limit, l.Max), nil
+}
+
+// Parse reads the "limit" and "offset" query parameters, see ParseLimit.
+// A negative or non-integer offset is rejected with ErrInvalidOffset.
+func (l PageLimits) Parse(r *http.Request) (limit, offset int, err error) {
+	if limit, err = l.ParseLimit(r); err != nil {
+		return 0, 0, err
+	}
+	if s := r.URL.Query().Get("offset"); s != "" {
+		offset, err = strconv.Atoi(s)
+		if err != nil || offset < 0 {
+			return 0, 0, ErrInvalidOffset
+		}
+	}
+	return limit, offset, nil
+}
+
+// ParsePagination reads the "limit" and "offset" query parameters with the given page sizes
+func ParsePagination(r *http.Request, defaultLimit, maxLimit int) (limit, offset int, err error) {
+	return PageLimits{Default: defaultLimit, Max: maxLimit}.Parse(r)
+}
+
+// Total returns a pointer to n for Pagination.Total
+func Total(n int64) *int64 {
+	return &n
+}
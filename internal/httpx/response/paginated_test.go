@@ -0,0 +1,100 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWantsEnvelope(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		header string
+		want   bool
+	}{
+		{"default", "/items", "", false},
+		{"query flag", "/items?envelope=true", "", true},
+		{"header", "/items", "true", true},
+		{"query overrides header", "/items?envelope=false", "true", false},
+		{"invalid flag", "/items?envelope=yes", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.header != "" {
+				r.Header.Set(EnvelopeHeader, tt.header)
+			}
+			if got := WantsEnvelope(r); got != tt.want {
+				t.Errorf("WantsEnvelope = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaginated(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Paginated(rec, []string{"a", "b"}, Pagination{
+		Total:   Total(5),
+		Limit:   2,
+		Offset:  2,
+		HasMore: true,
+	})
+
+	var got map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if string(got["data"]) != `["a","b"]` {
+		t.Errorf("data = %s", got["data"])
+	}
+	if want := `{"total":5,"limit":2,"offset":2,"has_more":true}`; string(got["pagination"]) != want {
+		t.Errorf("pagination = %s, want %s", got["pagination"], want)
+	}
+}
+
+func TestPaginatedNilSlice(t *testing.T) {
+	rec := httptest.NewRecorder()
+	var items []string
+	Paginated(rec, items, Pagination{Limit: 50, NextCursor: "abc"})
+
+	want := `{"data":[],"pagination":{"limit":50,"offset":0,"next_cursor":"abc","has_more":false}}` + "\n"
+	if rec.Body.String() != want {
+		t.Errorf("body = %s, want %s", rec.Body.String(), want)
+	}
+}
+
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		limits     PageLimits
+		wantLimit  int
+		wantOffset int
+		wantErr    error
+	}{
+		{"defaults", "", DefaultPageLimits, 50, 0, nil},
+		{"explicit", "limit=20&offset=40", DefaultPageLimits, 20, 40, nil},
+		{"clamped to max", "limit=500", DefaultPageLimits, 100, 0, nil},
+		{"configured limits", "limit=500", PageLimits{Default: 200, Max: 1000}, 500, 0, nil},
+		{"configured default", "offset=10", PageLimits{Default: 200, Max: 1000}, 200, 10, nil},
+		{"zero limit", "limit=0", DefaultPageLimits, 0, 0, ErrInvalidLimit},
+		{"negative limit", "limit=-5", DefaultPageLimits, 0, 0, ErrInvalidLimit},
+		{"non-numeric limit", "limit=ten", DefaultPageLimits, 0, 0, ErrInvalidLimit},
+		{"negative offset", "offset=-1", DefaultPageLimits, 0, 0, ErrInvalidOffset},
+		{"non-numeric offset", "limit=10&offset=x", DefaultPageLimits, 0, 0, ErrInvalidOffset},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/items?"+tt.query, nil)
+			limit, offset, err := ParsePagination(r, tt.limits.Default, tt.limits.Max)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if limit != tt.wantLimit || offset != tt.wantOffset {
+				t.Errorf("limit, offset = %d, %d, want %d, %d", limit, offset, tt.wantLimit, tt.wantOffset)
+			}
+		})
+	}
+}
@@ -2,14 +2,48 @@ package response
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 )
 
-// Error sends an error response
+// ErrorBody is the error object sent as {"error": {...}} in error responses
+type ErrorBody struct {
+	Code    Code         `json:"code"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"` // Every invalid field, with CodeValidationFailed only
+}
+
+// FieldError is a problem with one field of a request body
+type FieldError struct {
+	Field   string `json:"field"` // JSON name, with the index for list items, e.g. "media[1].type"
+	Code    Code   `json:"code"`  // CodeRequired or CodeInvalid
+	Message string `json:"message"`
+}
+
+// Error sends an error response with the generic code of the status
 func Error(w http.ResponseWriter, code int, message string) {
+	CodedError(w, code, "", message)
+}
+
+// CodedError sends an error response with a specific code; an empty code falls back to the status's generic code
+func CodedError(w http.ResponseWriter, status int, code Code, message string) {
+	if code == "" {
+		code = statusCode(status)
+	}
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	json.NewEncoder(w).Encode(map[string]string{"error": message})
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]ErrorBody{"error": {Code: code, Message: message}})
+}
+
+// ValidationFailed sends a 422 Unprocessable Entity listing every invalid field of the request
+func ValidationFailed(w http.ResponseWriter, fields []FieldError) {
+	message := "request has an invalid field"
+	if len(fields) > 1 {
+		message = fmt.Sprintf("request has %d invalid fields", len(fields))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(map[string]ErrorBody{"error": {Code: CodeValidationFailed, Message: message, Fields: fields}})
 }
 
 // JSON sends a JSON response
@@ -31,6 +65,11 @@ func Created(w http.ResponseWriter, data interface{}) {
 	JSON(w, http.StatusCreated, data)
 }
 
+// Accepted sends a 202 Accepted response with JSON body
+func Accepted(w http.ResponseWriter, data interface{}) {
+	JSON(w, http.StatusAccepted, data)
+}
+
 // OK sends a 200 OK response with JSON body
 func OK(w http.ResponseWriter, data interface{}) {
 	JSON(w, http.StatusOK, data)
@@ -60,3 +99,8 @@ func Unauthorized(w http.ResponseWriter, message string) {
 func Forbidden(w http.ResponseWriter, message string) {
 	Error(w, http.StatusForbidden, message)
 }
+
+// AccountIDRequired sends a 400 for a request missing account_id
+func AccountIDRequired(w http.ResponseWriter) {
+	CodedError(w, http.StatusBadRequest, CodeAccountIDRequired, "account_id is required")
+}
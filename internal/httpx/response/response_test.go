@@ -0,0 +1,65 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func decodeError(t *testing.T, rec *httptest.ResponseRecorder) ErrorBody {
+	t.Helper()
+	var body struct {
+		Error ErrorBody `json:"error"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding error body: %v", err)
+	}
+	return body.Error
+}
+
+func TestCodedError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	CodedError(rec, http.StatusConflict, CodeSyncInProgress, "sync is already running")
+
+	if rec.Code != http.StatusConflict {
+		t.Errorf("status = %d, want 409", rec.Code)
+	}
+	want := ErrorBody{Code: CodeSyncInProgress, Message: "sync is already running"}
+	if got := decodeError(t, rec); !reflect.DeepEqual(got, want) {
+		t.Errorf("body = %+v, want %+v", got, want)
+	}
+}
+
+func TestErrorUsesStatusCode(t *testing.T) {
+	tests := []struct {
+		status int
+		want   Code
+	}{
+		{http.StatusBadRequest, CodeBadRequest},
+		{http.StatusNotFound, CodeNotFound},
+		{http.StatusTooManyRequests, CodeRateLimited},
+		{http.StatusInternalServerError, CodeInternal},
+		{http.StatusGatewayTimeout, CodeInternal},
+	}
+	for _, tc := range tests {
+		rec := httptest.NewRecorder()
+		Error(rec, tc.status, "message")
+		if got := decodeError(t, rec); got.Code != tc.want || got.Message != "message" {
+			t.Errorf("status %d: body = %+v, want code %s", tc.status, got, tc.want)
+		}
+	}
+}
+
+func TestAccountIDRequired(t *testing.T) {
+	rec := httptest.NewRecorder()
+	AccountIDRequired(rec)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+	if got := decodeError(t, rec); got.Code != CodeAccountIDRequired {
+		t.Errorf("code = %s, want %s", got.Code, CodeAccountIDRequired)
+	}
+}
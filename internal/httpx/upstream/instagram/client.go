@@ -3,26 +3,34 @@ package instagram
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/vadim/neo-metric/internal/logctx"
 )
 
 const (
 	defaultBaseURL    = "https://graph.instagram.com"
 	defaultAPIVersion = "v21.0"
 	defaultTimeout    = 30 * time.Second
+	defaultUserAgent  = "neo-metric/1.0"
 )
 
 // Client is an Instagram Graph API client for content publishing
 type Client struct {
 	baseURL    string
 	apiVersion string
+	userAgent  string
 	httpClient *http.Client
 	logger     *slog.Logger
+	usage      UsageRecorder // Receives the rate limit usage reported by responses; optional
 }
 
 // ClientOption is a function that configures the Client
@@ -42,6 +50,15 @@ func WithAPIVersion(version string) ClientOption {
 	}
 }
 
+// WithUserAgent sets the User-Agent sent with every request; empty keeps the default
+func WithUserAgent(ua string) ClientOption {
+	return func(c *Client) {
+		if ua != "" {
+			c.userAgent = ua
+		}
+	}
+}
+
 // WithHTTPClient sets a custom HTTP client
 func WithHTTPClient(httpClient *http.Client) ClientOption {
 	return func(c *Client) {
@@ -61,6 +78,7 @@ func New(opts ...ClientOption) *Client {
 	c := &Client{
 		baseURL:    defaultBaseURL,
 		apiVersion: defaultAPIVersion,
+		userAgent:  defaultUserAgent,
 		httpClient: &http.Client{
 			Timeout: defaultTimeout,
 		},
@@ -80,12 +98,57 @@ type APIError struct {
 	Code         int    `json:"code"`
 	ErrorSubcode int    `json:"error_subcode"`
 	FBTraceID    string `json:"fbtrace_id"`
+	IsTransient  bool   `json:"is_transient"`
+	StatusCode   int    `json:"-"` // HTTP status of the response carrying the error
 }
 
 func (e *APIError) Error() string {
 	return fmt.Sprintf("instagram API error: %s (code: %d, subcode: %d)", e.Message, e.Code, e.ErrorSubcode)
 }
 
+// IsRateLimited returns true if the error is a Graph API throttling error
+func (e *APIError) IsRateLimited() bool {
+	switch e.Code {
+	case 4, 17, 32, 613:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsTemporary returns true if the request may succeed when retried later:
+// throttling, errors Graph API flags as transient, and server-side failures
+func (e *APIError) IsTemporary() bool {
+	return e.IsRateLimited() || e.IsTransient || e.Code == 1 || e.Code == 2 || e.StatusCode >= 500
+}
+
+// HTTPError is returned for error responses without a Graph API error body, e.g. from a proxy
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// IsTokenInvalid returns true if the access token is expired, revoked or otherwise unusable
+func (e *APIError) IsTokenInvalid() bool {
+	return e.Code == 190 || e.Code == 102
+}
+
+// IsObjectUnavailable returns true if the requested object does not exist or the token
+// may not read it, e.g. a restricted or deleted user profile
+func (e *APIError) IsObjectUnavailable() bool {
+	return e.Code == 100 || e.Code == 10 || e.Code == 230
+}
+
+// IsObjectDeleted returns true if the requested object does not exist, e.g. a media
+// deleted on Instagram; retrying the request will not help
+func (e *APIError) IsObjectDeleted() bool {
+	return e.Code == 100 && e.ErrorSubcode == 33
+}
+
 // ErrorResponse represents an error response from the API
 type ErrorResponse struct {
 	Error APIError `json:"error"`
@@ -118,6 +181,7 @@ type CreateMediaContainerInput struct {
 	UserID      string
 	AccessToken string
 	ImageURL    string    // For single image
+	AltText     string    // Accessibility description (images only)
 	VideoURL    string    // For video/reel
 	MediaType   MediaType // IMAGE, VIDEO, REELS, STORIES
 	Caption     string
@@ -130,7 +194,14 @@ type CreateMediaContainerInput struct {
 	ThumbOffset           *int     // Offset in ms for auto-generated thumbnail
 	AudioName             string   // Custom audio name for original audio
 	LocationID            string   // Facebook Page ID for location tagging
-	CollaboratorUsernames []string // Instagram usernames to invite as collaborators
+
+	// CollaboratorUsernames are Instagram usernames to invite as collaborators on a reel,
+	// single media post or carousel (not on carousel items)
+	CollaboratorUsernames []string
+
+	// Story-specific options; Instagram only accepts them for accounts it enables them for
+	LinkURL          string   // Target of the story's link sticker
+	MentionUsernames []string // Users mentioned in the story, sent as user_tags
 }
 
 // CreateMediaContainerOutput represents output from creating a media container
@@ -141,14 +212,16 @@ type CreateMediaContainerOutput struct {
 // CreateMediaContainer creates a media container for publishing
 // Step 1 of the publishing process
 func (c *Client) CreateMediaContainer(ctx context.Context, in CreateMediaContainerInput) (*CreateMediaContainerOutput, error) {
-	endpoint := fmt.Sprintf("%s/%s/%s/media", c.baseURL, c.apiVersion, in.UserID)
-
+	ctx = withOperation(ctx, "create_media_container", "ig_user_id", in.UserID)
 	params := url.Values{}
 	params.Set("access_token", in.AccessToken)
 
 	// Set media URL based on type
 	if in.ImageURL != "" {
 		params.Set("image_url", in.ImageURL)
+		if in.AltText != "" {
+			params.Set("alt_text", in.AltText)
+		}
 	}
 	if in.VideoURL != "" {
 		params.Set("video_url", in.VideoURL)
@@ -178,11 +251,14 @@ func (c *Client) CreateMediaContainer(ctx context.Context, in CreateMediaContain
 		if in.LocationID != "" {
 			params.Set("location_id", in.LocationID)
 		}
-		if len(in.CollaboratorUsernames) > 0 {
-			params.Set("collaborators", joinStrings(in.CollaboratorUsernames, ","))
-		}
 	case MediaTypeStories:
 		params.Set("media_type", "STORIES")
+		if in.LinkURL != "" {
+			params.Set("link", in.LinkURL)
+		}
+		if len(in.MentionUsernames) > 0 {
+			params.Set("user_tags", storyUserTags(in.MentionUsernames))
+		}
 	case MediaTypeCarousel:
 		params.Set("media_type", "CAROUSEL")
 		// Add children for carousel
@@ -201,13 +277,13 @@ func (c *Client) CreateMediaContainer(ctx context.Context, in CreateMediaContain
 		params.Set("caption", in.Caption)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"?"+params.Encode(), nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+	// Collaborators are invited by the container that gets published
+	if len(in.CollaboratorUsernames) > 0 && !in.IsCarousel {
+		params.Set("collaborators", joinStrings(in.CollaboratorUsernames, ","))
 	}
 
 	var out CreateMediaContainerOutput
-	if err := c.do(req, &out); err != nil {
+	if err := c.call(ctx, http.MethodPost, in.UserID+"/media", params, &out); err != nil {
 		return nil, err
 	}
 
@@ -224,25 +300,19 @@ type GetContainerStatusInput struct {
 type GetContainerStatusOutput struct {
 	ID           string          `json:"id"`
 	Status       ContainerStatus `json:"status_code"`
-	ErrorMessage string          `json:"error_message,omitempty"`
+	ErrorMessage string          `json:"status,omitempty"` // Instagram's description of the status, e.g. why processing failed
 }
 
 // GetContainerStatus checks the status of a media container
 // Step 2 of the publishing process (for video content)
 func (c *Client) GetContainerStatus(ctx context.Context, in GetContainerStatusInput) (*GetContainerStatusOutput, error) {
-	endpoint := fmt.Sprintf("%s/%s/%s", c.baseURL, c.apiVersion, in.ContainerID)
-
+	ctx = withOperation(ctx, "get_container_status", "container_id", in.ContainerID)
 	params := url.Values{}
 	params.Set("access_token", in.AccessToken)
-	params.Set("fields", "status_code,error_message")
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+params.Encode(), nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
+	params.Set("fields", "status_code,status")
 
 	var out GetContainerStatusOutput
-	if err := c.do(req, &out); err != nil {
+	if err := c.call(ctx, http.MethodGet, in.ContainerID, params, &out); err != nil {
 		return nil, err
 	}
 
@@ -264,19 +334,13 @@ type PublishMediaOutput struct {
 // PublishMedia publishes a media container
 // Step 3 of the publishing process
 func (c *Client) PublishMedia(ctx context.Context, in PublishMediaInput) (*PublishMediaOutput, error) {
-	endpoint := fmt.Sprintf("%s/%s/%s/media_publish", c.baseURL, c.apiVersion, in.UserID)
-
+	ctx = withOperation(ctx, "publish_media", "ig_user_id", in.UserID, "container_id", in.ContainerID)
 	params := url.Values{}
 	params.Set("access_token", in.AccessToken)
 	params.Set("creation_id", in.ContainerID)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"?"+params.Encode(), nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-
 	var out PublishMediaOutput
-	if err := c.do(req, &out); err != nil {
+	if err := c.call(ctx, http.MethodPost, in.UserID+"/media_publish", params, &out); err != nil {
 		return nil, err
 	}
 
@@ -292,18 +356,34 @@ type DeleteMediaInput struct {
 // DeleteMedia deletes published media from Instagram
 // Note: This only works for media published via the API
 func (c *Client) DeleteMedia(ctx context.Context, in DeleteMediaInput) error {
-	endpoint := fmt.Sprintf("%s/%s/%s", c.baseURL, c.apiVersion, in.MediaID)
-
+	ctx = withOperation(ctx, "delete_media", "media_id", in.MediaID)
 	params := url.Values{}
 	params.Set("access_token", in.AccessToken)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, endpoint+"?"+params.Encode(), nil)
-	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
-	}
+	var result map[string]interface{}
+	return c.call(ctx, http.MethodDelete, in.MediaID, params, &result)
+}
+
+// SetCommentEnabled turns comments on or off for a published media
+func (c *Client) SetCommentEnabled(ctx context.Context, mediaID, accessToken string, enabled bool) error {
+	ctx = withOperation(ctx, "set_comment_enabled", "media_id", mediaID)
+	params := url.Values{}
+	params.Set("comment_enabled", strconv.FormatBool(enabled))
+	params.Set("access_token", accessToken)
 
 	var result map[string]interface{}
-	return c.do(req, &result)
+	return c.call(ctx, http.MethodPost, mediaID, params, &result)
+}
+
+// SetMediaAltText sets the alt text of a published image, for media whose container does not accept it
+func (c *Client) SetMediaAltText(ctx context.Context, mediaID, accessToken, altText string) error {
+	ctx = withOperation(ctx, "set_media_alt_text", "media_id", mediaID)
+	params := url.Values{}
+	params.Set("alt_text", altText)
+	params.Set("access_token", accessToken)
+
+	var result map[string]interface{}
+	return c.call(ctx, http.MethodPost, mediaID, params, &result)
 }
 
 // GetMediaInput represents input for getting media details
@@ -323,39 +403,155 @@ type GetMediaOutput struct {
 	ThumbnailURL string `json:"thumbnail_url,omitempty"`
 	Timestamp    string `json:"timestamp,omitempty"`
 	Username     string `json:"username,omitempty"`
+	// MediaProductType is where the media is shown: FEED, STORY, REELS or AD
+	MediaProductType string `json:"media_product_type,omitempty"`
+	// IsCommentEnabled is nil when Instagram did not return the field
+	IsCommentEnabled *bool `json:"is_comment_enabled,omitempty"`
+	// Owner is the account that published the media; only returned when requested
+	Owner *MediaOwner `json:"owner,omitempty"`
+}
+
+// MediaOwner identifies the Instagram user that owns a media
+type MediaOwner struct {
+	ID string `json:"id"`
 }
 
 // GetMedia retrieves details of a published media
 func (c *Client) GetMedia(ctx context.Context, in GetMediaInput) (*GetMediaOutput, error) {
-	endpoint := fmt.Sprintf("%s/%s/%s", c.baseURL, c.apiVersion, in.MediaID)
-
+	ctx = withOperation(ctx, "get_media", "media_id", in.MediaID)
 	params := url.Values{}
 	params.Set("access_token", in.AccessToken)
 
 	fields := in.Fields
 	if len(fields) == 0 {
-		fields = []string{"id", "caption", "media_type", "media_url", "permalink", "thumbnail_url", "timestamp", "username"}
+		fields = []string{"id", "caption", "media_type", "media_url", "permalink", "thumbnail_url", "timestamp", "username", "media_product_type", "is_comment_enabled"}
 	}
 	params.Set("fields", joinStrings(fields, ","))
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+params.Encode(), nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+	var out GetMediaOutput
+	if err := c.call(ctx, http.MethodGet, in.MediaID, params, &out); err != nil {
+		return nil, err
 	}
 
-	var out GetMediaOutput
-	if err := c.do(req, &out); err != nil {
+	return &out, nil
+}
+
+// GetUserMediaInput represents input for listing an account's recent media
+type GetUserMediaInput struct {
+	UserID      string
+	AccessToken string
+	Stories     bool // List the live stories instead of the feed and reels
+	Limit       int  // Media per page; Instagram's default if 0
+}
+
+// GetUserMediaOutput represents a page of an account's media, newest first
+type GetUserMediaOutput struct {
+	Data   []GetMediaOutput `json:"data"`
+	Paging *Paging          `json:"paging,omitempty"`
+}
+
+// GetUserMedia retrieves the first page of an account's media
+func (c *Client) GetUserMedia(ctx context.Context, in GetUserMediaInput) (*GetUserMediaOutput, error) {
+	ctx = withOperation(ctx, "get_user_media", "ig_user_id", in.UserID, "stories", in.Stories)
+	params := url.Values{}
+	params.Set("access_token", in.AccessToken)
+	params.Set("fields", "id,caption,permalink,timestamp,media_product_type,is_comment_enabled")
+	if in.Limit > 0 {
+		params.Set("limit", strconv.Itoa(in.Limit))
+	}
+
+	edge := "/media"
+	if in.Stories {
+		edge = "/stories"
+	}
+
+	var out GetUserMediaOutput
+	if err := c.call(ctx, http.MethodGet, in.UserID+edge, params, &out); err != nil {
 		return nil, err
 	}
 
 	return &out, nil
 }
 
+// GraphGetInput describes a read of any Graph API node or edge
+type GraphGetInput struct {
+	Path        string            // Node or edge below the API version, e.g. "{media-id}" or "{media-id}/comments"
+	Fields      []string          // Sent as the comma-separated "fields" parameter
+	Params      map[string]string // Other query parameters, e.g. "limit" or "after"
+	AccessToken string
+}
+
+// GraphPostInput describes a write to any Graph API node or edge
+type GraphPostInput struct {
+	Path        string
+	Params      map[string]string
+	AccessToken string
+}
+
+// Get reads a Graph API node or edge and decodes the response into out.
+// Use it for fields the typed methods do not expose, e.g. comments_count.
+func (c *Client) Get(ctx context.Context, in GraphGetInput, out interface{}) error {
+	ctx = withOperation(ctx, "graph_get", "path", in.Path)
+	params := graphParams(in.Params, in.AccessToken)
+	if len(in.Fields) > 0 {
+		params.Set("fields", joinStrings(in.Fields, ","))
+	}
+	return c.call(ctx, http.MethodGet, in.Path, params, out)
+}
+
+// Post writes to a Graph API node or edge and decodes the response into out (may be nil)
+func (c *Client) Post(ctx context.Context, in GraphPostInput, out interface{}) error {
+	ctx = withOperation(ctx, "graph_post", "path", in.Path)
+	return c.call(ctx, http.MethodPost, in.Path, graphParams(in.Params, in.AccessToken), out)
+}
+
+// graphParams builds query parameters; the access token always wins over a caller-supplied one
+func graphParams(extra map[string]string, accessToken string) url.Values {
+	params := url.Values{}
+	for k, v := range extra {
+		params.Set(k, v)
+	}
+	params.Set("access_token", accessToken)
+	return params
+}
+
+// withOperation labels the requests made with ctx for the client's logs: the API operation
+// and the objects it acts on, in addition to any logctx attributes set by the caller
+func withOperation(ctx context.Context, name string, args ...any) context.Context {
+	return logctx.With(ctx, append([]any{"operation", name}, args...)...)
+}
+
+// call sends a request for path below the API version, with params in the query string
+func (c *Client) call(ctx context.Context, method, path string, params url.Values, out interface{}) error {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, seg := range segments {
+		if seg == "" || seg == "." || seg == ".." {
+			return fmt.Errorf("invalid Graph API path %q", path)
+		}
+		segments[i] = url.PathEscape(seg)
+	}
+	endpoint := fmt.Sprintf("%s/%s/%s", c.baseURL, c.apiVersion, strings.Join(segments, "/"))
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	return c.do(req, out)
+}
+
 // do executes an HTTP request and decodes the response
 func (c *Client) do(req *http.Request, out interface{}) error {
+	req.Header.Set("User-Agent", c.userAgent)
+
+	// Attach the operation and the account or objects it concerns to every record
+	logger := c.logger
+	if logger != nil {
+		logger = logger.With(logctx.Args(req.Context())...)
+	}
+
 	// Log request details at DEBUG level
-	if c.logger != nil {
-		c.logger.Debug("instagram API request",
+	if logger != nil {
+		logger.Debug("instagram API request",
 			"method", req.Method,
 			"url", sanitizeURL(req.URL.String()),
 		)
@@ -366,26 +562,38 @@ func (c *Client) do(req *http.Request, out interface{}) error {
 	duration := time.Since(start)
 
 	if err != nil {
-		if c.logger != nil {
-			c.logger.Debug("instagram API request failed",
+		if logger != nil {
+			logger.Debug("instagram API request failed",
 				"method", req.Method,
 				"url", sanitizeURL(req.URL.String()),
 				"duration_ms", duration.Milliseconds(),
 				"error", err.Error(),
 			)
 		}
+		// The URL carries the access token; keep it out of errors that reach API responses
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = sanitizeURL(urlErr.URL)
+		}
 		return fmt.Errorf("executing request: %w", err)
 	}
 	defer resp.Body.Close()
 
+	// Throttled responses carry the usage too, so record it before looking at the status
+	if c.usage != nil {
+		if u, ok := parseUsage(resp.Header, time.Now()); ok {
+			c.usage.Record(u)
+		}
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return fmt.Errorf("reading response body: %w", err)
 	}
 
 	// Log response at DEBUG level
-	if c.logger != nil {
-		c.logger.Debug("instagram API response",
+	if logger != nil {
+		logger.Debug("instagram API response",
 			"method", req.Method,
 			"url", sanitizeURL(req.URL.String()),
 			"status", resp.StatusCode,
@@ -399,16 +607,16 @@ func (c *Client) do(req *http.Request, out interface{}) error {
 	if resp.StatusCode >= 400 {
 		var errResp ErrorResponse
 		if err := json.Unmarshal(body, &errResp); err != nil {
-			if c.logger != nil {
-				c.logger.Error("instagram API error response",
+			if logger != nil {
+				logger.Error("instagram API error response",
 					"status", resp.StatusCode,
 					"body", string(body),
 				)
 			}
-			return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+			return &HTTPError{StatusCode: resp.StatusCode, Body: string(body)}
 		}
-		if c.logger != nil {
-			c.logger.Error("instagram API error",
+		if logger != nil {
+			logger.Error("instagram API error",
 				"code", errResp.Error.Code,
 				"subcode", errResp.Error.ErrorSubcode,
 				"message", errResp.Error.Message,
@@ -416,6 +624,7 @@ func (c *Client) do(req *http.Request, out interface{}) error {
 				"trace_id", errResp.Error.FBTraceID,
 			)
 		}
+		errResp.Error.StatusCode = resp.StatusCode
 		return &errResp.Error
 	}
 
@@ -442,6 +651,17 @@ func sanitizeURL(rawURL string) string {
 	return u.String()
 }
 
+// storyUserTags encodes mentioned usernames as the user_tags JSON array;
+// stories take no x/y position, Instagram places the mention itself
+func storyUserTags(usernames []string) string {
+	tags := make([]map[string]string, len(usernames))
+	for i, u := range usernames {
+		tags[i] = map[string]string{"username": u}
+	}
+	b, _ := json.Marshal(tags)
+	return string(b)
+}
+
 func joinStrings(strs []string, sep string) string {
 	if len(strs) == 0 {
 		return ""
@@ -457,15 +677,55 @@ func joinStrings(strs []string, sep string) string {
 // Comments API
 // ============================================================================
 
+// commentFields are the fields requested for comments and replies.
+// "from" may be omitted by the API when the author's data is restricted.
+const commentFields = "id,text,username,timestamp,like_count,hidden,from{id,username}"
+
+// mediaCommentFields additionally embeds up to 100 reply IDs per top-level comment to count
+// its replies: Instagram has no replies count field
+const mediaCommentFields = commentFields + ",replies.limit(100){id}"
+
 // CommentData represents a comment from Instagram API
 type CommentData struct {
-	ID           string `json:"id"`
-	Text         string `json:"text"`
-	Username     string `json:"username"`
-	Timestamp    string `json:"timestamp"`
-	LikeCount    int    `json:"like_count"`
-	Hidden       bool   `json:"hidden"`
-	RepliesCount int    `json:"replies_count,omitempty"`
+	ID        string          `json:"id"`
+	Text      string          `json:"text"`
+	Username  string          `json:"username"`
+	Timestamp string          `json:"timestamp"`
+	LikeCount int             `json:"like_count"`
+	Hidden    bool            `json:"hidden"`
+	Replies   *CommentReplies `json:"replies,omitempty"` // Only requested for top-level comments
+	From      *CommentAuthor  `json:"from,omitempty"`
+}
+
+// CommentReplies is the first page of reply IDs embedded in a top-level comment
+type CommentReplies struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+	Paging *Paging `json:"paging,omitempty"`
+}
+
+// ReplyCount returns the number of replies Instagram reported for the comment
+// It is a lower bound when the comment has more than 100 replies.
+func (c CommentData) ReplyCount() int {
+	if c.Replies == nil {
+		return 0
+	}
+	return len(c.Replies.Data)
+}
+
+// CommentAuthor represents the author of a comment
+type CommentAuthor struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+// AuthorID returns the Instagram-scoped user ID of the author, or "" if unavailable
+func (c CommentData) AuthorID() string {
+	if c.From == nil {
+		return ""
+	}
+	return c.From.ID
 }
 
 // GetCommentsInput represents input for getting comments
@@ -473,7 +733,9 @@ type GetCommentsInput struct {
 	MediaID     string
 	AccessToken string
 	Limit       int
-	After       string // Cursor for pagination
+	After       string    // Cursor for pagination
+	Since       time.Time // Only comments created at or after; zero means unbounded
+	Until       time.Time // Only comments created at or before; zero means unbounded
 }
 
 // GetCommentsOutput represents output from getting comments
@@ -494,11 +756,10 @@ type Paging struct {
 // GetComments retrieves comments for a media
 // GET /{media-id}/comments
 func (c *Client) GetComments(ctx context.Context, in GetCommentsInput) (*GetCommentsOutput, error) {
-	endpoint := fmt.Sprintf("%s/%s/%s/comments", c.baseURL, c.apiVersion, in.MediaID)
-
+	ctx = withOperation(ctx, "get_comments", "media_id", in.MediaID)
 	params := url.Values{}
 	params.Set("access_token", in.AccessToken)
-	params.Set("fields", "id,text,username,timestamp,like_count,hidden")
+	params.Set("fields", mediaCommentFields)
 
 	if in.Limit > 0 {
 		params.Set("limit", fmt.Sprintf("%d", in.Limit))
@@ -506,14 +767,15 @@ func (c *Client) GetComments(ctx context.Context, in GetCommentsInput) (*GetComm
 	if in.After != "" {
 		params.Set("after", in.After)
 	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+params.Encode(), nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+	if !in.Since.IsZero() {
+		params.Set("since", strconv.FormatInt(in.Since.Unix(), 10))
+	}
+	if !in.Until.IsZero() {
+		params.Set("until", strconv.FormatInt(in.Until.Unix(), 10))
 	}
 
 	var out GetCommentsOutput
-	if err := c.do(req, &out); err != nil {
+	if err := c.call(ctx, http.MethodGet, in.MediaID+"/comments", params, &out); err != nil {
 		return nil, err
 	}
 
@@ -531,11 +793,10 @@ type GetCommentRepliesInput struct {
 // GetCommentReplies retrieves replies to a comment
 // GET /{comment-id}/replies
 func (c *Client) GetCommentReplies(ctx context.Context, in GetCommentRepliesInput) (*GetCommentsOutput, error) {
-	endpoint := fmt.Sprintf("%s/%s/%s/replies", c.baseURL, c.apiVersion, in.CommentID)
-
+	ctx = withOperation(ctx, "get_comment_replies", "comment_id", in.CommentID)
 	params := url.Values{}
 	params.Set("access_token", in.AccessToken)
-	params.Set("fields", "id,text,username,timestamp,like_count,hidden")
+	params.Set("fields", commentFields)
 
 	if in.Limit > 0 {
 		params.Set("limit", fmt.Sprintf("%d", in.Limit))
@@ -544,13 +805,8 @@ func (c *Client) GetCommentReplies(ctx context.Context, in GetCommentRepliesInpu
 		params.Set("after", in.After)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+params.Encode(), nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-
 	var out GetCommentsOutput
-	if err := c.do(req, &out); err != nil {
+	if err := c.call(ctx, http.MethodGet, in.CommentID+"/replies", params, &out); err != nil {
 		return nil, err
 	}
 
@@ -572,19 +828,13 @@ type ReplyToCommentOutput struct {
 // ReplyToComment posts a reply to a comment
 // POST /{comment-id}/replies
 func (c *Client) ReplyToComment(ctx context.Context, in ReplyToCommentInput) (*ReplyToCommentOutput, error) {
-	endpoint := fmt.Sprintf("%s/%s/%s/replies", c.baseURL, c.apiVersion, in.CommentID)
-
+	ctx = withOperation(ctx, "reply_to_comment", "comment_id", in.CommentID)
 	params := url.Values{}
 	params.Set("access_token", in.AccessToken)
 	params.Set("message", in.Message)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"?"+params.Encode(), nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-
 	var out ReplyToCommentOutput
-	if err := c.do(req, &out); err != nil {
+	if err := c.call(ctx, http.MethodPost, in.CommentID+"/replies", params, &out); err != nil {
 		return nil, err
 	}
 
@@ -600,18 +850,12 @@ type DeleteCommentInput struct {
 // DeleteComment deletes a comment
 // DELETE /{comment-id}
 func (c *Client) DeleteComment(ctx context.Context, in DeleteCommentInput) error {
-	endpoint := fmt.Sprintf("%s/%s/%s", c.baseURL, c.apiVersion, in.CommentID)
-
+	ctx = withOperation(ctx, "delete_comment", "comment_id", in.CommentID)
 	params := url.Values{}
 	params.Set("access_token", in.AccessToken)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, endpoint+"?"+params.Encode(), nil)
-	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
-	}
-
 	var result map[string]interface{}
-	return c.do(req, &result)
+	return c.call(ctx, http.MethodDelete, in.CommentID, params, &result)
 }
 
 // HideCommentInput represents input for hiding/unhiding a comment
@@ -624,19 +868,13 @@ type HideCommentInput struct {
 // HideComment hides or unhides a comment
 // POST /{comment-id}?hide=true/false
 func (c *Client) HideComment(ctx context.Context, in HideCommentInput) error {
-	endpoint := fmt.Sprintf("%s/%s/%s", c.baseURL, c.apiVersion, in.CommentID)
-
+	ctx = withOperation(ctx, "hide_comment", "comment_id", in.CommentID)
 	params := url.Values{}
 	params.Set("access_token", in.AccessToken)
 	params.Set("hide", fmt.Sprintf("%t", in.Hide))
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"?"+params.Encode(), nil)
-	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
-	}
-
 	var result map[string]interface{}
-	return c.do(req, &result)
+	return c.call(ctx, http.MethodPost, in.CommentID, params, &result)
 }
 
 // CreateCommentInput represents input for creating a comment on media
@@ -654,19 +892,13 @@ type CreateCommentOutput struct {
 // CreateComment creates a new comment on a media
 // POST /{media-id}/comments
 func (c *Client) CreateComment(ctx context.Context, in CreateCommentInput) (*CreateCommentOutput, error) {
-	endpoint := fmt.Sprintf("%s/%s/%s/comments", c.baseURL, c.apiVersion, in.MediaID)
-
+	ctx = withOperation(ctx, "create_comment", "media_id", in.MediaID)
 	params := url.Values{}
 	params.Set("access_token", in.AccessToken)
 	params.Set("message", in.Message)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"?"+params.Encode(), nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-
 	var out CreateCommentOutput
-	if err := c.do(req, &out); err != nil {
+	if err := c.call(ctx, http.MethodPost, in.MediaID+"/comments", params, &out); err != nil {
 		return nil, err
 	}
 
@@ -764,8 +996,7 @@ type GetDMConversationsOutput struct {
 // GetDMConversations retrieves DM conversations for a user
 // GET /{user-id}/conversations
 func (c *Client) GetDMConversations(ctx context.Context, in GetDMConversationsInput) (*GetDMConversationsOutput, error) {
-	endpoint := fmt.Sprintf("%s/%s/%s/conversations", c.baseURL, c.apiVersion, in.UserID)
-
+	ctx = withOperation(ctx, "get_dm_conversations", "ig_user_id", in.UserID)
 	params := url.Values{}
 	params.Set("access_token", in.AccessToken)
 	params.Set("platform", "instagram")
@@ -778,13 +1009,8 @@ func (c *Client) GetDMConversations(ctx context.Context, in GetDMConversationsIn
 		params.Set("after", in.After)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+params.Encode(), nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-
 	var out GetDMConversationsOutput
-	if err := c.do(req, &out); err != nil {
+	if err := c.call(ctx, http.MethodGet, in.UserID+"/conversations", params, &out); err != nil {
 		return nil, err
 	}
 
@@ -808,8 +1034,7 @@ type GetDMMessagesOutput struct {
 // GetDMMessages retrieves messages in a conversation
 // GET /{conversation-id}/messages
 func (c *Client) GetDMMessages(ctx context.Context, in GetDMMessagesInput) (*GetDMMessagesOutput, error) {
-	endpoint := fmt.Sprintf("%s/%s/%s/messages", c.baseURL, c.apiVersion, in.ConversationID)
-
+	ctx = withOperation(ctx, "get_dm_messages", "conversation_id", in.ConversationID)
 	params := url.Values{}
 	params.Set("access_token", in.AccessToken)
 	params.Set("fields", "id,message,from,created_time,attachments{id,mime_type,name,size,image_data,video_data}")
@@ -821,13 +1046,8 @@ func (c *Client) GetDMMessages(ctx context.Context, in GetDMMessagesInput) (*Get
 		params.Set("after", in.After)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+params.Encode(), nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-
 	var out GetDMMessagesOutput
-	if err := c.do(req, &out); err != nil {
+	if err := c.call(ctx, http.MethodGet, in.ConversationID+"/messages", params, &out); err != nil {
 		return nil, err
 	}
 
@@ -851,20 +1071,20 @@ type SendDMMessageOutput struct {
 // SendDMMessage sends a text message via Instagram DM
 // POST /{user-id}/messages
 func (c *Client) SendDMMessage(ctx context.Context, in SendDMMessageInput) (*SendDMMessageOutput, error) {
-	endpoint := fmt.Sprintf("%s/%s/%s/messages", c.baseURL, c.apiVersion, in.UserID)
+	ctx = withOperation(ctx, "send_dm_message", "ig_user_id", in.UserID, "recipient_id", in.RecipientID)
+	// Marshal rather than format so quotes and newlines in the text stay valid JSON
+	messageJSON, err := json.Marshal(map[string]string{"text": in.Message})
+	if err != nil {
+		return nil, fmt.Errorf("encoding message: %w", err)
+	}
 
 	params := url.Values{}
 	params.Set("access_token", in.AccessToken)
 	params.Set("recipient", fmt.Sprintf(`{"id":"%s"}`, in.RecipientID))
-	params.Set("message", fmt.Sprintf(`{"text":"%s"}`, in.Message))
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"?"+params.Encode(), nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
+	params.Set("message", string(messageJSON))
 
 	var out SendDMMessageOutput
-	if err := c.do(req, &out); err != nil {
+	if err := c.call(ctx, http.MethodPost, in.UserID+"/messages", params, &out); err != nil {
 		return nil, err
 	}
 
@@ -878,31 +1098,111 @@ type SendDMMediaMessageInput struct {
 	AccessToken string
 	MediaURL    string
 	MediaType   string // "image" or "video"
+	Text        string // Optional caption sent in the same message
+}
+
+// dmAttachment is the attachment part of a DM message payload
+type dmAttachment struct {
+	Type    string `json:"type"`
+	Payload struct {
+		URL string `json:"url"`
+	} `json:"payload"`
 }
 
-// SendDMMediaMessage sends a media message via Instagram DM
+// dmMediaMessage is a DM message payload with an attachment and optional text
+type dmMediaMessage struct {
+	Attachment dmAttachment `json:"attachment"`
+	Text       string       `json:"text,omitempty"`
+}
+
+// SendDMMediaMessage sends a media message, with optional text, via Instagram DM
 // POST /{user-id}/messages
 func (c *Client) SendDMMediaMessage(ctx context.Context, in SendDMMediaMessageInput) (*SendDMMessageOutput, error) {
-	endpoint := fmt.Sprintf("%s/%s/%s/messages", c.baseURL, c.apiVersion, in.UserID)
+	ctx = withOperation(ctx, "send_dm_media_message", "ig_user_id", in.UserID, "recipient_id", in.RecipientID)
+	// Build attachment based on media type
+	msg := dmMediaMessage{Text: in.Text}
+	msg.Attachment.Type = "image"
+	if in.MediaType == "video" {
+		msg.Attachment.Type = "video"
+	}
+	msg.Attachment.Payload.URL = in.MediaURL
+	messageJSON, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("encoding message: %w", err)
+	}
 
 	params := url.Values{}
 	params.Set("access_token", in.AccessToken)
 	params.Set("recipient", fmt.Sprintf(`{"id":"%s"}`, in.RecipientID))
+	params.Set("message", string(messageJSON))
 
-	// Build attachment based on media type
-	attachmentType := "image"
-	if in.MediaType == "video" {
-		attachmentType = "video"
+	var out SendDMMessageOutput
+	if err := c.call(ctx, http.MethodPost, in.UserID+"/messages", params, &out); err != nil {
+		return nil, err
 	}
-	params.Set("message", fmt.Sprintf(`{"attachment":{"type":"%s","payload":{"url":"%s"}}}`, attachmentType, in.MediaURL))
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"?"+params.Encode(), nil)
+	return &out, nil
+}
+
+// SendReactionInput represents input for reacting to a DM message
+type SendReactionInput struct {
+	UserID      string // Instagram user ID of the sender (page-scoped)
+	RecipientID string // Instagram user ID of the conversation participant
+	MessageID   string
+	Reaction    string // Emoji reaction, e.g. "love"; empty removes the reaction
+	AccessToken string
+}
+
+// SendReaction reacts to a DM message, or removes the reaction if Reaction is empty
+// POST /{user-id}/messages with sender_action=react|unreact
+func (c *Client) SendReaction(ctx context.Context, in SendReactionInput) error {
+	ctx = withOperation(ctx, "send_reaction", "ig_user_id", in.UserID, "recipient_id", in.RecipientID)
+	payload := map[string]string{"message_id": in.MessageID}
+	senderAction := "unreact"
+	if in.Reaction != "" {
+		payload["reaction"] = in.Reaction
+		senderAction = "react"
+	}
+	payloadJSON, err := json.Marshal(payload)
 	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+		return fmt.Errorf("encoding reaction payload: %w", err)
 	}
 
-	var out SendDMMessageOutput
-	if err := c.do(req, &out); err != nil {
+	params := url.Values{}
+	params.Set("access_token", in.AccessToken)
+	params.Set("recipient", fmt.Sprintf(`{"id":"%s"}`, in.RecipientID))
+	params.Set("sender_action", senderAction)
+	params.Set("payload", string(payloadJSON))
+
+	return c.call(ctx, http.MethodPost, in.UserID+"/messages", params, nil)
+}
+
+// GetAccountProfileInput represents input for getting the account's own profile
+type GetAccountProfileInput struct {
+	UserID      string
+	AccessToken string
+}
+
+// GetAccountProfileOutput represents the account's profile info
+type GetAccountProfileOutput struct {
+	ID                string `json:"id"`
+	Username          string `json:"username"`
+	Name              string `json:"name,omitempty"`
+	ProfilePictureURL string `json:"profile_picture_url,omitempty"`
+	FollowersCount    int    `json:"followers_count"`
+	MediaCount        int    `json:"media_count"`
+}
+
+// GetAccountProfile retrieves profile info for an Instagram business account
+// GET /{user-id}
+func (c *Client) GetAccountProfile(ctx context.Context, in GetAccountProfileInput) (*GetAccountProfileOutput, error) {
+	ctx = withOperation(ctx, "get_account_profile", "ig_user_id", in.UserID)
+	params := url.Values{}
+	params.Set("access_token", in.AccessToken)
+	params.Set("fields", "username,name,profile_picture_url,followers_count,media_count")
+
+	var out GetAccountProfileOutput
+	if err := c.call(ctx, http.MethodGet, in.UserID, params, &out); err != nil {
 		return nil, err
 	}
 
@@ -927,19 +1227,13 @@ type GetDMParticipantOutput struct {
 // GetDMParticipant retrieves profile info for a DM participant
 // GET /{user-id}
 func (c *Client) GetDMParticipant(ctx context.Context, in GetDMParticipantInput) (*GetDMParticipantOutput, error) {
-	endpoint := fmt.Sprintf("%s/%s/%s", c.baseURL, c.apiVersion, in.UserID)
-
+	ctx = withOperation(ctx, "get_dm_participant", "participant_id", in.UserID)
 	params := url.Values{}
 	params.Set("access_token", in.AccessToken)
 	params.Set("fields", "id,username,name,profile_pic,followers_count")
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+params.Encode(), nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-
 	var out GetDMParticipantOutput
-	if err := c.do(req, &out); err != nil {
+	if err := c.call(ctx, http.MethodGet, in.UserID, params, &out); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,614 @@
+package instagram_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/apiusage"
+	"github.com/vadim/neo-metric/internal/httpx/upstream/instagram"
+	"github.com/vadim/neo-metric/internal/httpx/upstream/instagram/instagramtest"
+)
+
+func TestCreateMediaContainerImageParams(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.Handle(http.MethodPost, "/ig_user/media", http.StatusOK, `{"id":"container_1"}`)
+
+	out, err := srv.Client().CreateMediaContainer(context.Background(), instagram.CreateMediaContainerInput{
+		UserID:      "ig_user",
+		AccessToken: "token",
+		ImageURL:    "https://cdn.example.com/a.jpg?x=1&y=2",
+		AltText:     "A cat & a dog",
+		Caption:     "Hello #world",
+	})
+	if err != nil {
+		t.Fatalf("CreateMediaContainer: %v", err)
+	}
+	if out.ID != "container_1" {
+		t.Errorf("ID = %q, want container_1", out.ID)
+	}
+
+	q := srv.LastRequest().Query
+	want := map[string]string{
+		"access_token": "token",
+		"image_url":    "https://cdn.example.com/a.jpg?x=1&y=2",
+		"alt_text":     "A cat & a dog",
+		"caption":      "Hello #world",
+	}
+	for k, v := range want {
+		if got := q.Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+	if q.Has("media_type") {
+		t.Errorf("media_type = %q, want unset for feed image", q.Get("media_type"))
+	}
+}
+
+func TestCreateMediaContainerCarouselParams(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.Handle(http.MethodPost, "/ig_user/media", http.StatusOK, `{"id":"carousel_1"}`)
+
+	_, err := srv.Client().CreateMediaContainer(context.Background(), instagram.CreateMediaContainerInput{
+		UserID:      "ig_user",
+		AccessToken: "token",
+		MediaType:   instagram.MediaTypeCarousel,
+		Caption:     "Slides",
+		Children:    []string{"child_1", "child_2"},
+	})
+	if err != nil {
+		t.Fatalf("CreateMediaContainer: %v", err)
+	}
+
+	q := srv.LastRequest().Query
+	if got := q.Get("media_type"); got != "CAROUSEL" {
+		t.Errorf("media_type = %q, want CAROUSEL", got)
+	}
+	if got := q["children"]; len(got) != 2 || got[0] != "child_1" || got[1] != "child_2" {
+		t.Errorf("children = %v, want [child_1 child_2]", got)
+	}
+}
+
+func TestCreateMediaContainerCarouselItemOmitsCaption(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.Handle(http.MethodPost, "/ig_user/media", http.StatusOK, `{"id":"item_1"}`)
+
+	_, err := srv.Client().CreateMediaContainer(context.Background(), instagram.CreateMediaContainerInput{
+		UserID:      "ig_user",
+		AccessToken: "token",
+		VideoURL:    "https://cdn.example.com/v.mp4",
+		IsCarousel:  true,
+		Caption:     "ignored",
+	})
+	if err != nil {
+		t.Fatalf("CreateMediaContainer: %v", err)
+	}
+
+	q := srv.LastRequest().Query
+	if got := q.Get("is_carousel_item"); got != "true" {
+		t.Errorf("is_carousel_item = %q, want true", got)
+	}
+	if q.Has("caption") {
+		t.Errorf("caption = %q, want unset for carousel item", q.Get("caption"))
+	}
+}
+
+func TestCreateMediaContainerReelParams(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.Handle(http.MethodPost, "/ig_user/media", http.StatusOK, `{"id":"reel_1"}`)
+
+	shareToFeed := false
+	thumbOffset := 1500
+	_, err := srv.Client().CreateMediaContainer(context.Background(), instagram.CreateMediaContainerInput{
+		UserID:                "ig_user",
+		AccessToken:           "token",
+		VideoURL:              "https://cdn.example.com/r.mp4",
+		MediaType:             instagram.MediaTypeReels,
+		ShareToFeed:           &shareToFeed,
+		ThumbOffset:           &thumbOffset,
+		CollaboratorUsernames: []string{"alice", "bob"},
+	})
+	if err != nil {
+		t.Fatalf("CreateMediaContainer: %v", err)
+	}
+
+	q := srv.LastRequest().Query
+	want := map[string]string{
+		"media_type":    "REELS",
+		"share_to_feed": "false",
+		"thumb_offset":  "1500",
+		"collaborators": "alice,bob",
+	}
+	for k, v := range want {
+		if got := q.Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+}
+
+func TestPublishMediaParams(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.Handle(http.MethodPost, "/ig_user/media_publish", http.StatusOK, `{"id":"media_1"}`)
+
+	out, err := srv.Client().PublishMedia(context.Background(), instagram.PublishMediaInput{
+		UserID:      "ig_user",
+		AccessToken: "token",
+		ContainerID: "container_1",
+	})
+	if err != nil {
+		t.Fatalf("PublishMedia: %v", err)
+	}
+	if out.ID != "media_1" {
+		t.Errorf("ID = %q, want media_1", out.ID)
+	}
+
+	req := srv.LastRequest()
+	if req.Method != http.MethodPost {
+		t.Errorf("method = %s, want POST", req.Method)
+	}
+	if got := req.Query.Get("creation_id"); got != "container_1" {
+		t.Errorf("creation_id = %q, want container_1", got)
+	}
+}
+
+func TestGetCommentsPagination(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.Handle(http.MethodGet, "/media_1/comments", http.StatusOK, `{
+		"data": [{"id": "c1", "text": "hi", "username": "alice", "timestamp": "2025-12-24T07:53:58+0000"}],
+		"paging": {"cursors": {"before": "b1", "after": "a2"}, "next": "https://graph.instagram.com/next"}
+	}`)
+
+	out, err := srv.Client().GetComments(context.Background(), instagram.GetCommentsInput{
+		MediaID:     "media_1",
+		AccessToken: "token",
+		Limit:       25,
+		After:       "a1",
+	})
+	if err != nil {
+		t.Fatalf("GetComments: %v", err)
+	}
+
+	q := srv.LastRequest().Query
+	if got := q.Get("limit"); got != "25" {
+		t.Errorf("limit = %q, want 25", got)
+	}
+	if got := q.Get("after"); got != "a1" {
+		t.Errorf("after = %q, want a1", got)
+	}
+	if len(out.Data) != 1 || out.Data[0].Username != "alice" {
+		t.Fatalf("data = %+v, want one comment by alice", out.Data)
+	}
+	if out.Paging == nil || out.Paging.Cursors.After != "a2" {
+		t.Errorf("paging = %+v, want after cursor a2", out.Paging)
+	}
+}
+
+func TestGetCommentsTimeRange(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.Handle(http.MethodGet, "/media_1/comments", http.StatusOK, `{"data": []}`)
+
+	since := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 6, 2, 12, 30, 0, 0, time.FixedZone("UTC+3", 3*60*60))
+	if _, err := srv.Client().GetComments(context.Background(), instagram.GetCommentsInput{
+		MediaID:     "media_1",
+		AccessToken: "token",
+		Since:       since,
+		Until:       until,
+	}); err != nil {
+		t.Fatalf("GetComments: %v", err)
+	}
+
+	q := srv.LastRequest().Query
+	if got := q.Get("since"); got != "1748736000" {
+		t.Errorf("since = %q, want 1748736000", got)
+	}
+	if got := q.Get("until"); got != "1748856600" {
+		t.Errorf("until = %q, want 1748856600", got)
+	}
+
+	// A zero time leaves the range open
+	if _, err := srv.Client().GetComments(context.Background(), instagram.GetCommentsInput{
+		MediaID:     "media_1",
+		AccessToken: "token",
+	}); err != nil {
+		t.Fatalf("GetComments: %v", err)
+	}
+	q = srv.LastRequest().Query
+	if q.Has("since") || q.Has("until") {
+		t.Errorf("query = %v, want no time range", q)
+	}
+}
+
+func TestGetCommentsReplyCount(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.Handle(http.MethodGet, "/media_1/comments", http.StatusOK, `{
+		"data": [
+			{"id": "c1", "text": "hi", "like_count": 4, "replies": {"data": [{"id": "r1"}, {"id": "r2"}, {"id": "r3"}]}},
+			{"id": "c2", "text": "no replies", "like_count": 0}
+		]
+	}`)
+
+	out, err := srv.Client().GetComments(context.Background(), instagram.GetCommentsInput{
+		MediaID:     "media_1",
+		AccessToken: "token",
+	})
+	if err != nil {
+		t.Fatalf("GetComments: %v", err)
+	}
+
+	if got := srv.LastRequest().Query.Get("fields"); !strings.Contains(got, "replies.limit(100){id}") {
+		t.Errorf("fields = %q, want reply IDs embedded", got)
+	}
+	if got := out.Data[0].ReplyCount(); got != 3 {
+		t.Errorf("c1 reply count = %d, want 3", got)
+	}
+	if got := out.Data[0].LikeCount; got != 4 {
+		t.Errorf("c1 like count = %d, want 4", got)
+	}
+	if got := out.Data[1].ReplyCount(); got != 0 {
+		t.Errorf("c2 reply count = %d, want 0", got)
+	}
+}
+
+func TestHideCommentParams(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.Handle(http.MethodPost, "/comment_1", http.StatusOK, `{"success":true}`)
+
+	err := srv.Client().HideComment(context.Background(), instagram.HideCommentInput{
+		CommentID:   "comment_1",
+		AccessToken: "token",
+		Hide:        true,
+	})
+	if err != nil {
+		t.Fatalf("HideComment: %v", err)
+	}
+	if got := srv.LastRequest().Query.Get("hide"); got != "true" {
+		t.Errorf("hide = %q, want true", got)
+	}
+}
+
+func TestSetCommentEnabledParams(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.Handle(http.MethodPost, "/media_1", http.StatusOK, `{"success":true}`)
+
+	if err := srv.Client().SetCommentEnabled(context.Background(), "media_1", "token", false); err != nil {
+		t.Fatalf("SetCommentEnabled: %v", err)
+	}
+	if got := srv.LastRequest().Query.Get("comment_enabled"); got != "false" {
+		t.Errorf("comment_enabled = %q, want false", got)
+	}
+}
+
+func TestSendDMMessageEncodesText(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.Handle(http.MethodPost, "/ig_user/messages", http.StatusOK, `{"recipient_id":"user_2","message_id":"mid_1"}`)
+
+	text := "He said \"hi\"\nand left \\o/"
+	out, err := srv.Client().SendDMMessage(context.Background(), instagram.SendDMMessageInput{
+		UserID:      "ig_user",
+		RecipientID: "user_2",
+		AccessToken: "token",
+		Message:     text,
+	})
+	if err != nil {
+		t.Fatalf("SendDMMessage: %v", err)
+	}
+	if out.MessageID != "mid_1" {
+		t.Errorf("MessageID = %q, want mid_1", out.MessageID)
+	}
+
+	q := srv.LastRequest().Query
+	var message struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal([]byte(q.Get("message")), &message); err != nil {
+		t.Fatalf("message param is not valid JSON: %v (%s)", err, q.Get("message"))
+	}
+	if message.Text != text {
+		t.Errorf("message text = %q, want %q", message.Text, text)
+	}
+	if got := q.Get("recipient"); got != `{"id":"user_2"}` {
+		t.Errorf("recipient = %q, want {\"id\":\"user_2\"}", got)
+	}
+}
+
+func TestAPIErrorDecoding(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.HandleError(http.MethodPost, "/ig_user/media_publish", http.StatusBadRequest, 4, 2207051, "Application request limit reached")
+
+	_, err := srv.Client().PublishMedia(context.Background(), instagram.PublishMediaInput{
+		UserID:      "ig_user",
+		AccessToken: "token",
+		ContainerID: "container_1",
+	})
+
+	var apiErr *instagram.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want *instagram.APIError", err)
+	}
+	if apiErr.Code != 4 || apiErr.ErrorSubcode != 2207051 {
+		t.Errorf("code/subcode = %d/%d, want 4/2207051", apiErr.Code, apiErr.ErrorSubcode)
+	}
+	if apiErr.Message != "Application request limit reached" {
+		t.Errorf("message = %q", apiErr.Message)
+	}
+	if !apiErr.IsRateLimited() {
+		t.Error("IsRateLimited = false, want true for code 4")
+	}
+}
+
+func TestNonJSONErrorResponse(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.Handle(http.MethodGet, "/container_1", http.StatusBadGateway, "<html>bad gateway</html>")
+
+	_, err := srv.Client().GetContainerStatus(context.Background(), instagram.GetContainerStatusInput{
+		ContainerID: "container_1",
+		AccessToken: "token",
+	})
+	if err == nil {
+		t.Fatal("expected error for 502 response")
+	}
+
+	var apiErr *instagram.APIError
+	if errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want plain error for non-JSON body", err)
+	}
+	var httpErr *instagram.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("err = %v, want *instagram.HTTPError with status 502", err)
+	}
+	if !strings.Contains(err.Error(), "status 502") {
+		t.Errorf("err = %v, want status code in message", err)
+	}
+}
+
+func TestGetAccountProfile(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.Handle(http.MethodGet, "/ig_user", http.StatusOK, `{
+		"id": "ig_user",
+		"username": "neo",
+		"name": "Neo Metric",
+		"profile_picture_url": "https://cdn.example.com/p.jpg",
+		"followers_count": 1200,
+		"media_count": 87
+	}`)
+
+	out, err := srv.Client().GetAccountProfile(context.Background(), instagram.GetAccountProfileInput{
+		UserID:      "ig_user",
+		AccessToken: "token",
+	})
+	if err != nil {
+		t.Fatalf("GetAccountProfile: %v", err)
+	}
+
+	if got := srv.LastRequest().Query.Get("fields"); got != "username,name,profile_picture_url,followers_count,media_count" {
+		t.Errorf("fields = %q", got)
+	}
+	if out.Username != "neo" || out.Name != "Neo Metric" {
+		t.Errorf("username/name = %q/%q, want neo/Neo Metric", out.Username, out.Name)
+	}
+	if out.ProfilePictureURL != "https://cdn.example.com/p.jpg" {
+		t.Errorf("ProfilePictureURL = %q", out.ProfilePictureURL)
+	}
+	if out.FollowersCount != 1200 || out.MediaCount != 87 {
+		t.Errorf("followers/media = %d/%d, want 1200/87", out.FollowersCount, out.MediaCount)
+	}
+}
+
+func TestGetMediaProductTypeAndComments(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.Handle(http.MethodGet, "/media_1", http.StatusOK, `{
+		"id": "media_1",
+		"media_type": "VIDEO",
+		"media_product_type": "REELS",
+		"is_comment_enabled": false,
+		"permalink": "https://www.instagram.com/reel/abc/"
+	}`)
+
+	out, err := srv.Client().GetMedia(context.Background(), instagram.GetMediaInput{
+		MediaID:     "media_1",
+		AccessToken: "token",
+	})
+	if err != nil {
+		t.Fatalf("GetMedia: %v", err)
+	}
+
+	fields := srv.LastRequest().Query.Get("fields")
+	if !strings.Contains(fields, "media_product_type") || !strings.Contains(fields, "is_comment_enabled") {
+		t.Errorf("fields = %q, want media_product_type and is_comment_enabled", fields)
+	}
+	if out.MediaProductType != "REELS" {
+		t.Errorf("MediaProductType = %q, want REELS", out.MediaProductType)
+	}
+	if out.IsCommentEnabled == nil || *out.IsCommentEnabled {
+		t.Errorf("IsCommentEnabled = %v, want false", out.IsCommentEnabled)
+	}
+}
+
+func TestGetAccountProfileExpiredToken(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.HandleError(http.MethodGet, "/ig_user", http.StatusUnauthorized, 190, 463, "Error validating access token: Session has expired")
+
+	_, err := srv.Client().GetAccountProfile(context.Background(), instagram.GetAccountProfileInput{
+		UserID:      "ig_user",
+		AccessToken: "expired",
+	})
+
+	var apiErr *instagram.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want *instagram.APIError", err)
+	}
+	if !apiErr.IsTokenInvalid() {
+		t.Error("IsTokenInvalid = false, want true for code 190")
+	}
+	if apiErr.IsRateLimited() {
+		t.Error("IsRateLimited = true, want false for code 190")
+	}
+}
+
+func TestGetCommentsDeletedMedia(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.HandleError(http.MethodGet, "/media_1/comments", http.StatusBadRequest, 100, 33,
+		"Unsupported get request. Object with ID 'media_1' does not exist, cannot be loaded due to missing permissions, or does not support this operation")
+
+	_, err := srv.Client().GetComments(context.Background(), instagram.GetCommentsInput{
+		MediaID:     "media_1",
+		AccessToken: "token",
+	})
+
+	var apiErr *instagram.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want *instagram.APIError", err)
+	}
+	if !apiErr.IsObjectDeleted() {
+		t.Error("IsObjectDeleted = false, want true for code 100 subcode 33")
+	}
+	if apiErr.IsTemporary() {
+		t.Error("IsTemporary = true, want false for a deleted media")
+	}
+
+	// Other invalid parameter errors share code 100 but do not mean the media is gone
+	invalid := &instagram.APIError{Code: 100, Message: "Invalid parameter"}
+	if invalid.IsObjectDeleted() {
+		t.Error("IsObjectDeleted = true, want false for code 100 without subcode 33")
+	}
+}
+
+func TestClientRecordsUsage(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.SetHeader("X-App-Usage", `{"call_count":12,"total_time":8,"total_cputime":8}`)
+	srv.SetHeader("X-Business-Use-Case-Usage", `{"17841400000000001":[{"type":"instagram","call_count":96,"total_cputime":30,"total_time":30,"estimated_time_to_regain_access":0}]}`)
+	srv.HandleError(http.MethodGet, "/media_1/comments", http.StatusBadRequest, 4, 0, "Application request limit reached")
+
+	monitor := apiusage.NewMonitor(90, time.Minute)
+	_, err := srv.Client(instagram.WithUsageRecorder(monitor)).GetComments(context.Background(), instagram.GetCommentsInput{
+		MediaID:     "media_1",
+		AccessToken: "token",
+	})
+	if err == nil {
+		t.Fatal("GetComments succeeded, want the rate limit error")
+	}
+
+	// The usage of the throttled response is recorded all the same
+	u := monitor.Last()
+	if u == nil || u.Percent != 96 {
+		t.Fatalf("usage = %+v, want 96%%", u)
+	}
+	if !monitor.Throttled(time.Now()) {
+		t.Error("monitor is not throttled at 96% usage")
+	}
+}
+
+func TestAPIErrorIsTemporary(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		code    int
+		subcode int
+		want    bool
+	}{
+		{"rate limited", http.StatusBadRequest, 4, 0, true},
+		{"service unavailable", http.StatusServiceUnavailable, 2, 0, true},
+		{"server error", http.StatusInternalServerError, 100, 0, true},
+		{"invalid media", http.StatusBadRequest, 9004, 2207052, false},
+		{"expired token", http.StatusUnauthorized, 190, 463, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := instagramtest.NewServer(t)
+			srv.HandleError(http.MethodPost, "/ig_user/media_publish", tt.status, tt.code, tt.subcode, tt.name)
+
+			_, err := srv.Client().PublishMedia(context.Background(), instagram.PublishMediaInput{
+				UserID:      "ig_user",
+				AccessToken: "token",
+				ContainerID: "container_1",
+			})
+
+			var apiErr *instagram.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("err = %v, want *instagram.APIError", err)
+			}
+			if apiErr.StatusCode != tt.status {
+				t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, tt.status)
+			}
+			if got := apiErr.IsTemporary(); got != tt.want {
+				t.Errorf("IsTemporary = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGraphGetEncodesParams(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.Handle(http.MethodGet, "/media_1", http.StatusOK, `{"id":"media_1","comments_count":12,"media_product_type":"REELS"}`)
+
+	var out struct {
+		ID               string `json:"id"`
+		CommentsCount    int    `json:"comments_count"`
+		MediaProductType string `json:"media_product_type"`
+	}
+	err := srv.Client().Get(context.Background(), instagram.GraphGetInput{
+		Path:        "/media_1/",
+		Fields:      []string{"id", "comments_count", "media_product_type"},
+		Params:      map[string]string{"since": "2025-01-01 00:00", "access_token": "caller"},
+		AccessToken: "token",
+	}, &out)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if out.CommentsCount != 12 || out.MediaProductType != "REELS" {
+		t.Errorf("out = %+v", out)
+	}
+
+	q := srv.LastRequest().Query
+	want := map[string]string{
+		"fields":       "id,comments_count,media_product_type",
+		"since":        "2025-01-01 00:00",
+		"access_token": "token", // The input's token wins over Params
+	}
+	for k, v := range want {
+		if got := q.Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+}
+
+func TestGraphPostDecodesAPIError(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.HandleError(http.MethodPost, "/comment_1", http.StatusBadRequest, 100, 33, "Object does not exist")
+
+	err := srv.Client().Post(context.Background(), instagram.GraphPostInput{
+		Path:        "comment_1",
+		Params:      map[string]string{"hide": "true"},
+		AccessToken: "token",
+	}, nil)
+
+	var apiErr *instagram.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want *instagram.APIError", err)
+	}
+	if apiErr.Code != 100 || apiErr.ErrorSubcode != 33 || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("code/subcode/status = %d/%d/%d, want 100/33/400", apiErr.Code, apiErr.ErrorSubcode, apiErr.StatusCode)
+	}
+	if got := srv.LastRequest().Query.Get("hide"); got != "true" {
+		t.Errorf("hide = %q, want true", got)
+	}
+}
+
+func TestGraphGetRejectsPathTraversal(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+
+	for _, path := range []string{"", "media_1/../me", "media_1//comments"} {
+		err := srv.Client().Get(context.Background(), instagram.GraphGetInput{Path: path, AccessToken: "token"}, nil)
+		if err == nil {
+			t.Errorf("Get(%q) succeeded, want invalid path error", path)
+		}
+	}
+	if n := len(srv.Requests()); n != 0 {
+		t.Errorf("%d requests sent, want 0", n)
+	}
+}
@@ -0,0 +1,239 @@
+package instagram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/logctx"
+)
+
+func TestGetCommentsParsesAuthor(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fields := r.URL.Query().Get("fields"); !strings.Contains(fields, "from{id,username}") {
+			t.Errorf("fields = %q, want from{id,username} requested", fields)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"data": [
+				{
+					"id": "17858893269000001",
+					"text": "Nice post!",
+					"username": "alice",
+					"timestamp": "2025-12-24T07:53:58+0000",
+					"like_count": 2,
+					"hidden": false,
+					"from": {"id": "17841400000000001", "username": "alice"}
+				},
+				{
+					"id": "17858893269000002",
+					"text": "Restricted author",
+					"username": "bob",
+					"timestamp": "2025-12-24T08:00:00+0000"
+				}
+			]
+		}`))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	out, err := c.GetComments(context.Background(), GetCommentsInput{MediaID: "media_1", AccessToken: "token"})
+	if err != nil {
+		t.Fatalf("GetComments: %v", err)
+	}
+
+	if len(out.Data) != 2 {
+		t.Fatalf("got %d comments, want 2", len(out.Data))
+	}
+	if got := out.Data[0].AuthorID(); got != "17841400000000001" {
+		t.Errorf("AuthorID = %q, want 17841400000000001", got)
+	}
+	if got := out.Data[1].AuthorID(); got != "" {
+		t.Errorf("AuthorID without from = %q, want empty", got)
+	}
+}
+
+func TestTransportErrorRedactsAccessToken(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	_, err := c.GetAccountProfile(context.Background(), GetAccountProfileInput{UserID: "ig_user", AccessToken: "secret-token"})
+	if err == nil {
+		t.Fatal("GetAccountProfile succeeded against a closed server")
+	}
+	if strings.Contains(err.Error(), "secret-token") {
+		t.Errorf("error leaks the access token: %v", err)
+	}
+}
+
+func TestRequestsIdentifyClient(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []ClientOption
+		want string
+	}{
+		{"default", nil, defaultUserAgent},
+		{"configured", []ClientOption{WithUserAgent("neo-metric/2.3 (ops@example.com)")}, "neo-metric/2.3 (ops@example.com)"},
+		{"empty keeps default", []ClientOption{WithUserAgent("")}, defaultUserAgent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("User-Agent")
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"data": []}`))
+			}))
+			defer srv.Close()
+
+			c := New(append(tt.opts, WithBaseURL(srv.URL))...)
+			if _, err := c.GetComments(context.Background(), GetCommentsInput{MediaID: "media_1", AccessToken: "token"}); err != nil {
+				t.Fatalf("GetComments: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("User-Agent = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendDMMediaMessageEncodesCaption(t *testing.T) {
+	tests := []struct {
+		name string
+		in   SendDMMediaMessageInput
+		want string
+	}{
+		{
+			"media only",
+			SendDMMediaMessageInput{MediaURL: "https://cdn.example.com/a.jpg", MediaType: "image"},
+			`{"attachment":{"type":"image","payload":{"url":"https://cdn.example.com/a.jpg"}}}`,
+		},
+		{
+			"media with caption",
+			SendDMMediaMessageInput{MediaURL: "https://cdn.example.com/a.mp4", MediaType: "video", Text: "Say \"hi\"\nto the team"},
+			`{"attachment":{"type":"video","payload":{"url":"https://cdn.example.com/a.mp4"}},"text":"Say \"hi\"\nto the team"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := r.ParseForm(); err != nil {
+					t.Errorf("parsing form: %v", err)
+				}
+				got = r.Form.Get("message")
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"recipient_id": "r1", "message_id": "m1"}`))
+			}))
+			defer srv.Close()
+
+			in := tt.in
+			in.UserID, in.RecipientID, in.AccessToken = "u1", "r1", "token"
+			c := New(WithBaseURL(srv.URL))
+			if _, err := c.SendDMMediaMessage(context.Background(), in); err != nil {
+				t.Fatalf("SendDMMediaMessage: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("message = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestLogsCarryOperation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": []}`))
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c := New(WithBaseURL(srv.URL), WithLogger(logger))
+
+	ctx := logctx.With(context.Background(), "account_id", "acc_7")
+	if _, err := c.GetComments(ctx, GetCommentsInput{MediaID: "media_1", AccessToken: "secret-token"}); err != nil {
+		t.Fatalf("GetComments: %v", err)
+	}
+
+	if strings.Contains(buf.String(), "secret-token") {
+		t.Error("access token leaked into logs")
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d log records, want request and response", len(lines))
+	}
+	for _, line := range lines {
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("decoding log record: %v", err)
+		}
+		for key, want := range map[string]string{"operation": "get_comments", "media_id": "media_1", "account_id": "acc_7"} {
+			if rec[key] != want {
+				t.Errorf("%s: %s = %v, want %s", rec["msg"], key, rec[key], want)
+			}
+		}
+	}
+}
+
+func TestParseUsage(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		app        string
+		business   string
+		wantOK     bool
+		wantPct    int
+		wantRegain time.Duration // 0 = no regain time
+	}{
+		{"no headers", "", "", false, 0, 0},
+		{"app usage", `{"call_count":28,"total_time":25,"total_cputime":12}`, "", true, 28, 0},
+		{"app time dominates", `{"call_count":5,"total_time":91,"total_cputime":40}`, "", true, 91, 0},
+		{
+			"business usage",
+			"",
+			`{"17841400000000001":[{"type":"instagram","call_count":95,"total_cputime":20,"total_time":20,"estimated_time_to_regain_access":0}]}`,
+			true, 95, 0,
+		},
+		{
+			"throttled business",
+			`{"call_count":10,"total_time":10,"total_cputime":10}`,
+			`{"1":[{"type":"instagram","call_count":100,"total_cputime":30,"total_time":30,"estimated_time_to_regain_access":7}],` +
+				`"2":[{"type":"instagram","call_count":20,"total_cputime":5,"total_time":5,"estimated_time_to_regain_access":0}]}`,
+			true, 100, 7 * time.Minute,
+		},
+		{"malformed", `not json`, "", false, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			if tt.app != "" {
+				h.Set("X-App-Usage", tt.app)
+			}
+			if tt.business != "" {
+				h.Set("X-Business-Use-Case-Usage", tt.business)
+			}
+
+			u, ok := parseUsage(h, now)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if u.Percent != tt.wantPct {
+				t.Errorf("Percent = %d, want %d", u.Percent, tt.wantPct)
+			}
+			switch {
+			case tt.wantRegain == 0 && u.RegainAt != nil:
+				t.Errorf("RegainAt = %v, want nil", u.RegainAt)
+			case tt.wantRegain != 0 && (u.RegainAt == nil || !u.RegainAt.Equal(now.Add(tt.wantRegain))):
+				t.Errorf("RegainAt = %v, want %v", u.RegainAt, now.Add(tt.wantRegain))
+			}
+		})
+	}
+}
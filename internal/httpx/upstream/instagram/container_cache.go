@@ -0,0 +1,95 @@
+package instagram
+
+import (
+	"sync"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+)
+
+// ContainerCache remembers the carousel item containers created for each account and media,
+// so a carousel that repeats media of an earlier one (a retry, a repost) reuses the child
+// container instead of having Instagram download and process the media again.
+// Only carousel items are cached: they carry no caption, so the same media gives the same container.
+// Entries expire after the TTL, which must stay below Instagram's ~24h container validity;
+// the publisher still checks the container status before reusing it.
+type ContainerCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[containerKey]containerEntry
+}
+
+type containerKey struct {
+	userID  string
+	url     string
+	altText string // Alt text is set on the container, so media with other alt text needs its own
+}
+
+type containerEntry struct {
+	containerID string
+	expiresAt   time.Time
+}
+
+// NewContainerCache creates a carousel item container cache; a non-positive ttl disables caching
+func NewContainerCache(ttl time.Duration) *ContainerCache {
+	return &ContainerCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[containerKey]containerEntry),
+	}
+}
+
+func newContainerKey(userID string, media entity.MediaItem) containerKey {
+	return containerKey{userID: userID, url: media.URL, altText: media.AltText}
+}
+
+// Get returns the cached carousel item container of the media, if it has not expired
+func (c *ContainerCache) Get(userID string, media entity.MediaItem) (string, bool) {
+	if c == nil || c.ttl <= 0 {
+		return "", false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := newContainerKey(userID, media)
+	e, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	if !c.now().Before(e.expiresAt) {
+		delete(c.entries, key)
+		return "", false
+	}
+	return e.containerID, true
+}
+
+// Put stores a newly created carousel item container of the media
+func (c *ContainerCache) Put(userID string, media entity.MediaItem, containerID string) {
+	if c == nil || c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	// Drop expired entries here so media that is never used again doesn't pile up
+	for key, e := range c.entries {
+		if !now.Before(e.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+	c.entries[newContainerKey(userID, media)] = containerEntry{containerID: containerID, expiresAt: now.Add(c.ttl)}
+}
+
+// Forget drops the cached container of the media, e.g. after Instagram stopped accepting it
+func (c *ContainerCache) Forget(userID string, media entity.MediaItem) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	delete(c.entries, newContainerKey(userID, media))
+	c.mu.Unlock()
+}
@@ -0,0 +1,109 @@
+package instagram_test
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+	"github.com/vadim/neo-metric/internal/httpx/upstream/instagram"
+	"github.com/vadim/neo-metric/internal/httpx/upstream/instagram/instagramtest"
+)
+
+func carouselPost() *entity.Publication {
+	return &entity.Publication{
+		ID:      "pub_1",
+		Type:    entity.PublicationTypePost,
+		Caption: "Summer collection",
+		Media: []entity.MediaItem{
+			{URL: "https://cdn.example.com/a.jpg", Type: entity.MediaTypeImage},
+			{URL: "https://cdn.example.com/b.jpg", Type: entity.MediaTypeImage},
+		},
+	}
+}
+
+// newCarouselServer names item containers after their image (child_a.jpg) and publishes the
+// carousel as media_1. childStatus is the status Instagram reports for the item containers.
+func newCarouselServer(t *testing.T, childStatus string) *instagramtest.Server {
+	srv := instagramtest.NewServer(t)
+	srv.HandleFunc(http.MethodPost, "/ig_user/media", func(r instagramtest.Request) instagramtest.Response {
+		if r.Query.Get("media_type") == "CAROUSEL" {
+			return instagramtest.Response{Body: `{"id":"carousel"}`}
+		}
+		return instagramtest.Response{Body: `{"id":"child_` + path.Base(r.Query.Get("image_url")) + `"}`}
+	})
+	for _, id := range []string{"child_a.jpg", "child_b.jpg"} {
+		srv.Handle(http.MethodGet, "/"+id, http.StatusOK, `{"id":"`+id+`","status_code":"`+childStatus+`"}`)
+	}
+	srv.Handle(http.MethodGet, "/carousel", http.StatusOK, `{"id":"carousel","status_code":"FINISHED"}`)
+	srv.Handle(http.MethodPost, "/ig_user/media_publish", http.StatusOK, `{"id":"media_1"}`)
+	srv.Handle(http.MethodGet, "/media_1", http.StatusOK, `{"id":"media_1"}`)
+	return srv
+}
+
+func publishCarousel(t *testing.T, publisher *instagram.Publisher) {
+	t.Helper()
+	_, err := publisher.Publish(context.Background(), instagram.PublishInput{
+		UserID:      "ig_user",
+		AccessToken: "token",
+		Publication: carouselPost(),
+	})
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+}
+
+func TestContainerCacheReusesCarouselItemsWithinTTL(t *testing.T) {
+	srv := newCarouselServer(t, "FINISHED")
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	cache := instagram.NewContainerCache(time.Hour)
+	cache.SetNow(func() time.Time { return now })
+	publisher := instagram.NewPublisher(srv.Client()).WithContainerCache(cache)
+
+	publishCarousel(t, publisher)
+	if n := countRequests(srv, http.MethodPost, "/ig_user/media"); n != 3 {
+		t.Fatalf("created %d containers, want 2 items and the carousel", n)
+	}
+
+	// Within the TTL only the carousel container is created again
+	now = now.Add(59 * time.Minute)
+	publishCarousel(t, publisher)
+	if n := countRequests(srv, http.MethodPost, "/ig_user/media"); n != 4 {
+		t.Errorf("created %d containers in total, want 4 with the items reused", n)
+	}
+
+	// After the TTL the items are created again
+	now = now.Add(2 * time.Minute)
+	publishCarousel(t, publisher)
+	if n := countRequests(srv, http.MethodPost, "/ig_user/media"); n != 7 {
+		t.Errorf("created %d containers in total, want 7 after the cache expired", n)
+	}
+}
+
+func TestContainerCacheRecreatesRejectedItems(t *testing.T) {
+	srv := newCarouselServer(t, "EXPIRED")
+	publisher := instagram.NewPublisher(srv.Client()).WithContainerCache(instagram.NewContainerCache(time.Hour))
+
+	publishCarousel(t, publisher)
+	publishCarousel(t, publisher)
+	if n := countRequests(srv, http.MethodPost, "/ig_user/media"); n != 6 {
+		t.Errorf("created %d containers, want 6 when Instagram no longer accepts the cached items", n)
+	}
+}
+
+func TestContainerCacheDisabled(t *testing.T) {
+	var cache *instagram.ContainerCache
+	media := entity.MediaItem{URL: "https://cdn.example.com/a.jpg"}
+	cache.Put("ig_user", media, "container_1")
+	if _, ok := cache.Get("ig_user", media); ok {
+		t.Error("nil cache returned a container")
+	}
+
+	cache = instagram.NewContainerCache(0)
+	cache.Put("ig_user", media, "container_1")
+	if _, ok := cache.Get("ig_user", media); ok {
+		t.Error("cache with zero TTL returned a container")
+	}
+}
@@ -0,0 +1,8 @@
+package instagram
+
+import "time"
+
+// SetNow replaces the clock of the cache in tests
+func (c *ContainerCache) SetNow(now func() time.Time) {
+	c.now = now
+}
@@ -0,0 +1,166 @@
+// Package instagramtest provides a fake Instagram Graph API for testing code built on instagram.Client.
+package instagramtest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/vadim/neo-metric/internal/httpx/upstream/instagram"
+)
+
+// APIVersion is the Graph API version the fake server expects in request paths
+const APIVersion = "v21.0"
+
+// Request is a request recorded by the fake server
+type Request struct {
+	Method string
+	Path   string // Path without the API version prefix, e.g. "/123/media"
+	Query  url.Values
+	Body   []byte
+}
+
+// Response is a canned response returned by the fake server
+type Response struct {
+	Status int
+	Body   string
+}
+
+// Server is a fake Graph API backed by httptest.Server
+// Routes are matched by method and path (without the API version prefix).
+// Unmatched requests fail the test and receive a Graph API error response.
+type Server struct {
+	*httptest.Server
+
+	t        testing.TB
+	mu       sync.Mutex
+	routes   map[string]Response
+	funcs    map[string]func(Request) Response // Dynamic routes, take precedence over routes
+	header   http.Header                       // Sent with every response
+	requests []Request
+}
+
+// NewServer starts a fake Graph API that is closed when the test finishes
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	s := &Server{
+		t:      t,
+		routes: make(map[string]Response),
+		funcs:  make(map[string]func(Request) Response),
+		header: make(http.Header),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// Client returns an instagram.Client pointed at the fake server
+func (s *Server) Client(opts ...instagram.ClientOption) *instagram.Client {
+	opts = append([]instagram.ClientOption{
+		instagram.WithBaseURL(s.URL),
+		instagram.WithAPIVersion(APIVersion),
+	}, opts...)
+	return instagram.New(opts...)
+}
+
+// Handle registers a canned JSON response for method and path
+func (s *Server) Handle(method, path string, status int, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes[routeKey(method, path)] = Response{Status: status, Body: body}
+}
+
+// HandleFunc registers a function building the response for method and path from the request,
+// e.g. to return a different container ID per media URL
+func (s *Server) HandleFunc(method, path string, fn func(Request) Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.funcs[routeKey(method, path)] = fn
+}
+
+// HandleError registers a Graph API error response for method and path
+func (s *Server) HandleError(method, path string, status, code, subcode int, message string) {
+	s.Handle(method, path, status, ErrorBody(code, subcode, message))
+}
+
+// SetHeader sets a header sent with every response, e.g. the rate limit usage headers
+func (s *Server) SetHeader(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.header.Set(key, value)
+}
+
+// Requests returns all requests received so far
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// LastRequest returns the most recent request, failing the test if there was none
+func (s *Server) LastRequest() Request {
+	s.t.Helper()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.requests) == 0 {
+		s.t.Fatal("instagramtest: no requests received")
+	}
+	return s.requests[len(s.requests)-1]
+}
+
+// ErrorBody builds a Graph API error response body
+func ErrorBody(code, subcode int, message string) string {
+	return fmt.Sprintf(`{"error":{"message":%q,"type":"OAuthException","code":%d,"error_subcode":%d,"fbtrace_id":"trace"}}`,
+		message, code, subcode)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	path := strings.TrimPrefix(r.URL.Path, "/"+APIVersion)
+
+	s.mu.Lock()
+	req := Request{
+		Method: r.Method,
+		Path:   path,
+		Query:  r.URL.Query(),
+		Body:   body,
+	}
+	s.requests = append(s.requests, req)
+	resp, ok := s.routes[routeKey(r.Method, path)]
+	fn := s.funcs[routeKey(r.Method, path)]
+	for k, v := range s.header {
+		w.Header()[k] = v
+	}
+	s.mu.Unlock()
+
+	if fn != nil {
+		resp, ok = fn(req), true
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		s.t.Errorf("instagramtest: unexpected request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, ErrorBody(803, 0, "unknown route"))
+		return
+	}
+
+	status := resp.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	io.WriteString(w, resp.Body)
+}
+
+func routeKey(method, path string) string {
+	return method + " " + path
+}
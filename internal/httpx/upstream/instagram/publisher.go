@@ -2,15 +2,20 @@ package instagram
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+	"github.com/vadim/neo-metric/internal/logctx"
 )
 
 // Publisher handles the complete publishing workflow for Instagram content
 type Publisher struct {
-	client *Client
+	client     *Client
+	containers *ContainerCache // nil creates every carousel item container
 }
 
 // NewPublisher creates a new Instagram publisher
@@ -18,70 +23,176 @@ func NewPublisher(client *Client) *Publisher {
 	return &Publisher{client: client}
 }
 
+// WithContainerCache reuses carousel item containers created for the same account and media
+func (p *Publisher) WithContainerCache(c *ContainerCache) *Publisher {
+	p.containers = c
+	return p
+}
+
 // PublishInput represents input for publishing content
+// If Publication.ContainerID is set, that container is published instead of creating a new one
+// as long as it is still valid.
 type PublishInput struct {
-	UserID      string
-	AccessToken string
-	Publication *entity.Publication
+	UserID             string
+	AccessToken        string
+	Publication        *entity.Publication
+	OnContainerCreated func(containerID string) // Called when a new container is created, before publishing it
 }
 
 // PublishOutput represents output from publishing content
 type PublishOutput struct {
 	InstagramMediaID string
 	Permalink        string
+	MediaProductType string // FEED, STORY or REELS; empty if the media details could not be fetched
+	CommentsEnabled  *bool  // nil if the media details could not be fetched
 }
 
 // Publish publishes a publication to Instagram
 // Handles the complete 3-step workflow: create container -> wait for processing -> publish
 func (p *Publisher) Publish(ctx context.Context, in PublishInput) (*PublishOutput, error) {
 	pub := in.Publication
+	ctx = logctx.With(ctx, "account_id", pub.AccountID, "publication_id", pub.ID)
+
+	// Retry of an earlier attempt: publish the existing container if Instagram still accepts it
+	if pub.ContainerID != "" {
+		out, reused, err := p.publishExistingContainer(ctx, in, pub.ContainerID)
+		if reused {
+			if err == nil {
+				p.setAltTextAfterPublish(ctx, in, out.InstagramMediaID)
+			}
+			return out, err
+		}
+	}
+
+	containerID, err := p.createContainer(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.OnContainerCreated != nil {
+		in.OnContainerCreated(containerID)
+	}
 
-	switch pub.Type {
+	// Wait for container to be ready (for video content)
+	if err := p.waitForContainer(ctx, containerID, in.AccessToken); err != nil {
+		return nil, fmt.Errorf("waiting for container: %w", err)
+	}
+
+	out, err := p.publishContainer(ctx, in.UserID, in.AccessToken, containerID)
+	if err != nil {
+		return nil, err
+	}
+	p.setAltTextAfterPublish(ctx, in, out.InstagramMediaID)
+	return out, nil
+}
+
+// setAltTextAfterPublish sets the alt text of a story image on the published media, as story
+// containers do not take it. Failing is not fatal: the story is already live, and the client logs the error.
+func (p *Publisher) setAltTextAfterPublish(ctx context.Context, in PublishInput, mediaID string) {
+	pub := in.Publication
+	if pub.Type != entity.PublicationTypeStory || len(pub.Media) != 1 {
+		return
+	}
+	if media := pub.Media[0]; media.Type == entity.MediaTypeImage && media.AltText != "" {
+		_ = p.client.SetMediaAltText(ctx, mediaID, in.AccessToken, media.AltText)
+	}
+}
+
+// PrepareContainer creates the media container for a publication ahead of publishing it
+// and waits until Instagram has processed the media, so bad media is reported early.
+// A container still processing when polling gives up is returned as well: Instagram keeps
+// processing it and Publish waits for it later. Containers expire about 24 hours after creation.
+func (p *Publisher) PrepareContainer(ctx context.Context, in PublishInput) (string, error) {
+	ctx = logctx.With(ctx, "account_id", in.Publication.AccountID, "publication_id", in.Publication.ID)
+	containerID, err := p.createContainer(ctx, in)
+	if err != nil {
+		return "", err
+	}
+
+	if err := p.waitForContainer(ctx, containerID, in.AccessToken); err != nil && !errors.Is(err, entity.ErrContainerNotReady) {
+		return "", fmt.Errorf("waiting for container: %w", err)
+	}
+
+	return containerID, nil
+}
+
+// createContainer creates the container matching the publication type
+func (p *Publisher) createContainer(ctx context.Context, in PublishInput) (string, error) {
+	switch in.Publication.Type {
 	case entity.PublicationTypePost:
-		return p.publishPost(ctx, in)
+		return p.createPostContainer(ctx, in)
 	case entity.PublicationTypeStory:
-		return p.publishStory(ctx, in)
+		return p.createStoryContainer(ctx, in)
 	case entity.PublicationTypeReel:
-		return p.publishReel(ctx, in)
+		return p.createReelContainer(ctx, in)
 	default:
-		return nil, entity.ErrInvalidPublicationType
+		return "", entity.ErrInvalidPublicationType
 	}
 }
 
-// publishPost publishes a feed post (single image, video, or carousel)
-func (p *Publisher) publishPost(ctx context.Context, in PublishInput) (*PublishOutput, error) {
+// publishExistingContainer publishes a container created by an earlier attempt
+// reused is false if the container expired or failed and a new one should be created.
+func (p *Publisher) publishExistingContainer(ctx context.Context, in PublishInput, containerID string) (out *PublishOutput, reused bool, err error) {
+	status, err := p.client.GetContainerStatus(ctx, GetContainerStatusInput{
+		ContainerID: containerID,
+		AccessToken: in.AccessToken,
+	})
+	if err != nil {
+		// Don't create a second container while the first one may still be publishable
+		return nil, true, fmt.Errorf("checking existing container: %w", err)
+	}
+
+	switch status.Status {
+	case ContainerStatusExpired, ContainerStatusError:
+		return nil, false, nil
+	case ContainerStatusPublished:
+		// media_publish went through but the response was lost; publishing again would fail
+		return nil, true, entity.ErrContainerPublished
+	}
+
+	if status.Status == ContainerStatusInProgress {
+		if err := p.waitForContainer(ctx, containerID, in.AccessToken); err != nil {
+			return nil, true, fmt.Errorf("waiting for container: %w", err)
+		}
+	}
+
+	out, err = p.publishContainer(ctx, in.UserID, in.AccessToken, containerID)
+	return out, true, err
+}
+
+// createPostContainer creates the container for a feed post (single image, video, or carousel)
+func (p *Publisher) createPostContainer(ctx context.Context, in PublishInput) (string, error) {
 	pub := in.Publication
 
 	var containerID string
 	var err error
 
+	if len(pub.Media) == 0 {
+		return "", entity.ErrNoMedia
+	}
+
 	if len(pub.Media) == 1 {
 		// Single media post
-		containerID, err = p.createSingleMediaContainer(ctx, in.UserID, in.AccessToken, pub.Media[0], pub.Caption, false)
+		containerID, err = p.createSingleMediaContainer(ctx, in.UserID, in.AccessToken, pub.Media[0], pub.Caption, pub.Collaborators, false)
 	} else {
 		// Carousel post
-		containerID, err = p.createCarouselContainer(ctx, in.UserID, in.AccessToken, pub.Media, pub.Caption)
+		containerID, err = p.createCarouselContainer(ctx, in.UserID, in.AccessToken, pub.Media, pub.Caption, pub.Collaborators)
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("creating media container: %w", err)
-	}
-
-	// Wait for container to be ready (for video content)
-	if err := p.waitForContainer(ctx, containerID, in.AccessToken); err != nil {
-		return nil, fmt.Errorf("waiting for container: %w", err)
+		return "", fmt.Errorf("creating media container: %w", err)
 	}
 
-	// Publish
-	return p.publishContainer(ctx, in.UserID, in.AccessToken, containerID)
+	return containerID, nil
 }
 
-// publishStory publishes a story
-func (p *Publisher) publishStory(ctx context.Context, in PublishInput) (*PublishOutput, error) {
+// createStoryContainer creates the container for a story
+// Story containers take no alt text; Publish sets it on the published media instead.
+func (p *Publisher) createStoryContainer(ctx context.Context, in PublishInput) (string, error) {
 	pub := in.Publication
 
 	if len(pub.Media) != 1 {
-		return nil, entity.ErrSingleMediaRequired
+		return "", entity.ErrSingleMediaRequired
 	}
 
 	media := pub.Media[0]
@@ -99,30 +210,37 @@ func (p *Publisher) publishStory(ctx context.Context, in PublishInput) (*Publish
 		containerIn.VideoURL = media.URL
 	}
 
-	containerOut, err := p.client.CreateMediaContainer(ctx, containerIn)
-	if err != nil {
-		return nil, fmt.Errorf("creating story container: %w", err)
+	stickers := pub.StoryOptions != nil && (pub.StoryOptions.LinkURL != "" || len(pub.StoryOptions.Mentions) > 0)
+	if stickers {
+		containerIn.LinkURL = pub.StoryOptions.LinkURL
+		containerIn.MentionUsernames = pub.StoryOptions.Mentions
 	}
 
-	// Wait for processing
-	if err := p.waitForContainer(ctx, containerOut.ID, in.AccessToken); err != nil {
-		return nil, fmt.Errorf("waiting for story container: %w", err)
+	containerOut, err := p.client.CreateMediaContainer(ctx, containerIn)
+	if err != nil {
+		// Instagram rejects the link and mentions as an invalid parameter or a missing
+		// permission for accounts it has not enabled them for (subcode 33 is a missing account instead)
+		var apiErr *APIError
+		if stickers && errors.As(err, &apiErr) && (apiErr.Code == 100 || apiErr.Code == 10) && !apiErr.IsObjectDeleted() {
+			return "", fmt.Errorf("%w: %v", entity.ErrStoryStickersRejected, err)
+		}
+		return "", fmt.Errorf("creating story container: %w", err)
 	}
 
-	return p.publishContainer(ctx, in.UserID, in.AccessToken, containerOut.ID)
+	return containerOut.ID, nil
 }
 
-// publishReel publishes a reel
-func (p *Publisher) publishReel(ctx context.Context, in PublishInput) (*PublishOutput, error) {
+// createReelContainer creates the container for a reel
+func (p *Publisher) createReelContainer(ctx context.Context, in PublishInput) (string, error) {
 	pub := in.Publication
 
 	if len(pub.Media) != 1 {
-		return nil, entity.ErrSingleMediaRequired
+		return "", entity.ErrSingleMediaRequired
 	}
 
 	media := pub.Media[0]
 	if media.Type != entity.MediaTypeVideo {
-		return nil, fmt.Errorf("reels require video content")
+		return "", fmt.Errorf("reels require video content")
 	}
 
 	containerIn := CreateMediaContainerInput{
@@ -145,19 +263,14 @@ func (p *Publisher) publishReel(ctx context.Context, in PublishInput) (*PublishO
 
 	containerOut, err := p.client.CreateMediaContainer(ctx, containerIn)
 	if err != nil {
-		return nil, fmt.Errorf("creating reel container: %w", err)
-	}
-
-	// Reels require waiting for video processing
-	if err := p.waitForContainer(ctx, containerOut.ID, in.AccessToken); err != nil {
-		return nil, fmt.Errorf("waiting for reel container: %w", err)
+		return "", fmt.Errorf("creating reel container: %w", err)
 	}
 
-	return p.publishContainer(ctx, in.UserID, in.AccessToken, containerOut.ID)
+	return containerOut.ID, nil
 }
 
 // createSingleMediaContainer creates a container for a single media item
-func (p *Publisher) createSingleMediaContainer(ctx context.Context, userID, accessToken string, media entity.MediaItem, caption string, isCarouselItem bool) (string, error) {
+func (p *Publisher) createSingleMediaContainer(ctx context.Context, userID, accessToken string, media entity.MediaItem, caption string, collaborators []string, isCarouselItem bool) (string, error) {
 	containerIn := CreateMediaContainerInput{
 		UserID:      userID,
 		AccessToken: accessToken,
@@ -166,12 +279,14 @@ func (p *Publisher) createSingleMediaContainer(ctx context.Context, userID, acce
 
 	if media.Type == entity.MediaTypeImage {
 		containerIn.ImageURL = media.URL
+		containerIn.AltText = media.AltText
 	} else {
 		containerIn.VideoURL = media.URL
 	}
 
 	if !isCarouselItem {
 		containerIn.Caption = caption
+		containerIn.CollaboratorUsernames = collaborators
 	}
 
 	containerOut, err := p.client.CreateMediaContainer(ctx, containerIn)
@@ -183,21 +298,29 @@ func (p *Publisher) createSingleMediaContainer(ctx context.Context, userID, acce
 }
 
 // createCarouselContainer creates a carousel container with multiple media items
-func (p *Publisher) createCarouselContainer(ctx context.Context, userID, accessToken string, media []entity.MediaItem, caption string) (string, error) {
+func (p *Publisher) createCarouselContainer(ctx context.Context, userID, accessToken string, media []entity.MediaItem, caption string, collaborators []string) (string, error) {
+	// Check the size first: Instagram only rejects it when the carousel container is
+	// created, after the child containers already exist
+	if len(media) < entity.MinCarouselItems {
+		return "", entity.ErrTooFewCarouselItems
+	}
+	if len(media) > entity.MaxCarouselItems {
+		return "", entity.ErrTooManyMediaItems
+	}
+
 	// First, create containers for each carousel item
 	childIDs := make([]string, len(media))
 
 	for i, m := range media {
-		childID, err := p.createSingleMediaContainer(ctx, userID, accessToken, m, "", true)
-		if err != nil {
-			return "", fmt.Errorf("creating carousel item %d: %w", i, err)
+		childID, err := p.prepareCarouselItem(ctx, userID, accessToken, m, childIDs[:i])
+		if err != nil && isTemporaryItemError(err) {
+			// One more try, so a hiccup on a single item doesn't fail the whole carousel
+			childID, err = p.prepareCarouselItem(ctx, userID, accessToken, m, childIDs[:i])
 		}
-
-		// Wait for video items to be processed
-		if m.Type == entity.MediaTypeVideo {
-			if err := p.waitForContainer(ctx, childID, accessToken); err != nil {
-				return "", fmt.Errorf("waiting for carousel item %d: %w", i, err)
-			}
+		if err != nil {
+			// Instagram cannot delete unpublished containers: the items created so far expire
+			// within 24h, and until then the container cache hands them to the next attempt
+			return "", &CarouselItemError{Index: i, Count: len(media), MediaType: m.Type, Err: err}
 		}
 
 		childIDs[i] = childID
@@ -210,6 +333,8 @@ func (p *Publisher) createCarouselContainer(ctx context.Context, userID, accessT
 		MediaType:   MediaTypeCarousel,
 		Caption:     caption,
 		Children:    childIDs,
+
+		CollaboratorUsernames: collaborators,
 	}
 
 	containerOut, err := p.client.CreateMediaContainer(ctx, containerIn)
@@ -220,6 +345,76 @@ func (p *Publisher) createCarouselContainer(ctx context.Context, userID, accessT
 	return containerOut.ID, nil
 }
 
+// CarouselItemError identifies the carousel item whose container could not be prepared
+type CarouselItemError struct {
+	Index     int // Position of the item in the carousel, from 0
+	Count     int // Items in the carousel
+	MediaType entity.MediaType
+	Err       error
+}
+
+func (e *CarouselItemError) Error() string {
+	return fmt.Sprintf("carousel item %d of %d (%s): %v", e.Index+1, e.Count, e.MediaType, e.Err)
+}
+
+func (e *CarouselItemError) Unwrap() error {
+	return e.Err
+}
+
+// prepareCarouselItem returns a container for a carousel item that is ready to be added to the carousel
+func (p *Publisher) prepareCarouselItem(ctx context.Context, userID, accessToken string, media entity.MediaItem, used []string) (string, error) {
+	childID, err := p.carouselItemContainer(ctx, userID, accessToken, media, used)
+	if err != nil {
+		return "", fmt.Errorf("creating container: %w", err)
+	}
+
+	// Wait for video items to be processed
+	if media.Type == entity.MediaTypeVideo {
+		if err := p.waitForContainer(ctx, childID, accessToken); err != nil {
+			if errors.Is(err, entity.ErrContainerFailed) || errors.Is(err, entity.ErrContainerExpired) {
+				p.containers.Forget(userID, media)
+			}
+			return "", fmt.Errorf("waiting for container %s: %w", childID, err)
+		}
+	}
+
+	return childID, nil
+}
+
+// isTemporaryItemError reports whether preparing a carousel item may succeed right away when
+// tried again; throttling is left to the publication's retry schedule
+func isTemporaryItemError(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.IsTemporary() && !apiErr.IsRateLimited()
+	}
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode >= 500
+}
+
+// carouselItemContainer returns a container for a carousel item, reusing a cached one that
+// Instagram still accepts and that is not already one of the carousel's earlier items (used)
+func (p *Publisher) carouselItemContainer(ctx context.Context, userID, accessToken string, media entity.MediaItem, used []string) (string, error) {
+	if childID, ok := p.containers.Get(userID, media); ok && !slices.Contains(used, childID) {
+		status, err := p.client.GetContainerStatus(ctx, GetContainerStatusInput{
+			ContainerID: childID,
+			AccessToken: accessToken,
+		})
+		if err == nil && (status.Status == ContainerStatusFinished || status.Status == ContainerStatusInProgress) {
+			return childID, nil
+		}
+		// Expired, failed or already published with an earlier carousel
+		p.containers.Forget(userID, media)
+	}
+
+	childID, err := p.createSingleMediaContainer(ctx, userID, accessToken, media, "", nil, true)
+	if err != nil {
+		return "", err
+	}
+	p.containers.Put(userID, media, childID)
+	return childID, nil
+}
+
 // waitForContainer waits for a media container to be ready for publishing
 func (p *Publisher) waitForContainer(ctx context.Context, containerID, accessToken string) error {
 	maxAttempts := 30
@@ -238,9 +433,13 @@ func (p *Publisher) waitForContainer(ctx context.Context, containerID, accessTok
 		case ContainerStatusFinished:
 			return nil
 		case ContainerStatusError:
-			return fmt.Errorf("container error: %s", status.ErrorMessage)
+			if status.ErrorMessage == "" {
+				return entity.ErrContainerFailed
+			}
+			// Keep Instagram's reason, it tells the user what to fix in the media
+			return fmt.Errorf("%w: %s", entity.ErrContainerFailed, status.ErrorMessage)
 		case ContainerStatusExpired:
-			return fmt.Errorf("container expired")
+			return entity.ErrContainerExpired
 		case ContainerStatusInProgress:
 			// Continue waiting
 		case ContainerStatusPublished:
@@ -269,11 +468,11 @@ func (p *Publisher) publishContainer(ctx context.Context, userID, accessToken, c
 		return nil, fmt.Errorf("publishing media: %w", err)
 	}
 
-	// Get permalink
+	// Get permalink and the settings Instagram applied to the media
 	mediaDetails, err := p.client.GetMedia(ctx, GetMediaInput{
 		MediaID:     publishOut.ID,
 		AccessToken: accessToken,
-		Fields:      []string{"id", "permalink"},
+		Fields:      []string{"id", "permalink", "media_product_type", "is_comment_enabled"},
 	})
 	if err != nil {
 		// Non-fatal error, we still have the media ID
@@ -285,9 +484,93 @@ func (p *Publisher) publishContainer(ctx context.Context, userID, accessToken, c
 	return &PublishOutput{
 		InstagramMediaID: publishOut.ID,
 		Permalink:        mediaDetails.Permalink,
+		MediaProductType: mediaDetails.MediaProductType,
+		CommentsEnabled:  mediaDetails.IsCommentEnabled,
 	}, nil
 }
 
+// reconcileMediaLimit is how many of the account's newest media are searched for a published container
+const reconcileMediaLimit = 50
+
+// FindPublishedMedia looks up the media a publication's stored container was published as, for
+// a publish whose outcome was never recorded. It returns nil if the container was not published
+// yet and ErrContainerExpired if it can no longer be. The container status does not name the
+// media, so it is searched among the account's newest media by caption (stories have none) and
+// must be the only match created since the publication; otherwise ErrPublishedMediaNotFound.
+func (p *Publisher) FindPublishedMedia(ctx context.Context, in PublishInput) (*PublishOutput, error) {
+	pub := in.Publication
+	ctx = logctx.With(ctx, "account_id", pub.AccountID, "publication_id", pub.ID)
+
+	status, err := p.client.GetContainerStatus(ctx, GetContainerStatusInput{
+		ContainerID: pub.ContainerID,
+		AccessToken: in.AccessToken,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("checking container: %w", err)
+	}
+
+	switch status.Status {
+	case ContainerStatusPublished:
+	case ContainerStatusExpired, ContainerStatusError:
+		return nil, entity.ErrContainerExpired
+	default:
+		return nil, nil
+	}
+
+	story := pub.Type == entity.PublicationTypeStory
+	media, err := p.client.GetUserMedia(ctx, GetUserMediaInput{
+		UserID:      in.UserID,
+		AccessToken: in.AccessToken,
+		Stories:     story,
+		Limit:       reconcileMediaLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing media: %w", err)
+	}
+
+	var found *GetMediaOutput
+	for i, m := range media.Data {
+		if !story && strings.TrimSpace(m.Caption) != strings.TrimSpace(pub.Caption) {
+			continue
+		}
+		if at, err := time.Parse("2006-01-02T15:04:05-0700", m.Timestamp); err == nil && at.Before(pub.CreatedAt) {
+			continue
+		}
+		if found != nil {
+			return nil, entity.ErrPublishedMediaNotFound // Ambiguous
+		}
+		found = &media.Data[i]
+	}
+	if found == nil {
+		return nil, entity.ErrPublishedMediaNotFound
+	}
+
+	return &PublishOutput{
+		InstagramMediaID: found.ID,
+		Permalink:        found.Permalink,
+		MediaProductType: found.MediaProductType,
+		CommentsEnabled:  found.IsCommentEnabled,
+	}, nil
+}
+
+// SetCommentEnabled turns comments on or off for a published media
+func (p *Publisher) SetCommentEnabled(ctx context.Context, mediaID, accessToken string, enabled bool) error {
+	return p.client.SetCommentEnabled(ctx, mediaID, accessToken, enabled)
+}
+
+// GetPermalink returns the link to a published media on Instagram
+func (p *Publisher) GetPermalink(ctx context.Context, mediaID, accessToken string) (string, error) {
+	media, err := p.client.GetMedia(ctx, GetMediaInput{
+		MediaID:     mediaID,
+		AccessToken: accessToken,
+		Fields:      []string{"id", "permalink"},
+	})
+	if err != nil {
+		return "", err
+	}
+	return media.Permalink, nil
+}
+
 // Delete deletes a published media from Instagram
 func (p *Publisher) Delete(ctx context.Context, mediaID, accessToken string) error {
 	return p.client.DeleteMedia(ctx, DeleteMediaInput{
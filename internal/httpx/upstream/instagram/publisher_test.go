@@ -0,0 +1,561 @@
+package instagram_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/domain/publication/entity"
+	"github.com/vadim/neo-metric/internal/httpx/upstream/instagram"
+	"github.com/vadim/neo-metric/internal/httpx/upstream/instagram/instagramtest"
+)
+
+func imagePost() *entity.Publication {
+	return &entity.Publication{
+		ID:   "pub_1",
+		Type: entity.PublicationTypePost,
+		Media: []entity.MediaItem{
+			{URL: "https://cdn.example.com/a.jpg", Type: entity.MediaTypeImage},
+		},
+	}
+}
+
+func countRequests(srv *instagramtest.Server, method, path string) int {
+	n := 0
+	for _, r := range srv.Requests() {
+		if r.Method == method && r.Path == path {
+			n++
+		}
+	}
+	return n
+}
+
+func TestPublishRetryReusesContainer(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.Handle(http.MethodPost, "/ig_user/media", http.StatusOK, `{"id":"container_1"}`)
+	srv.Handle(http.MethodGet, "/container_1", http.StatusOK, `{"id":"container_1","status_code":"FINISHED"}`)
+	srv.HandleError(http.MethodPost, "/ig_user/media_publish", http.StatusInternalServerError, 2, 0, "Service temporarily unavailable")
+
+	publisher := instagram.NewPublisher(srv.Client())
+	pub := imagePost()
+
+	var stored string
+	in := instagram.PublishInput{
+		UserID:             "ig_user",
+		AccessToken:        "token",
+		Publication:        pub,
+		OnContainerCreated: func(containerID string) { stored = containerID },
+	}
+
+	// First attempt fails after the container was created
+	if _, err := publisher.Publish(context.Background(), in); err == nil {
+		t.Fatal("expected first publish to fail")
+	}
+	if stored != "container_1" {
+		t.Fatalf("stored container = %q, want container_1", stored)
+	}
+
+	// Retry with the stored container
+	srv.Handle(http.MethodPost, "/ig_user/media_publish", http.StatusOK, `{"id":"media_1"}`)
+	srv.Handle(http.MethodGet, "/media_1", http.StatusOK, `{"id":"media_1","permalink":"https://instagram.com/p/abc"}`)
+	pub.ContainerID = stored
+	stored = ""
+
+	out, err := publisher.Publish(context.Background(), in)
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if out.InstagramMediaID != "media_1" {
+		t.Errorf("media ID = %q, want media_1", out.InstagramMediaID)
+	}
+	if stored != "" {
+		t.Errorf("retry created container %q, want reuse", stored)
+	}
+	if n := countRequests(srv, http.MethodPost, "/ig_user/media"); n != 1 {
+		t.Errorf("created %d containers, want 1", n)
+	}
+	if got := srv.LastRequest(); got.Path != "/media_1" {
+		t.Errorf("last request = %s %s, want permalink lookup", got.Method, got.Path)
+	}
+	for _, r := range srv.Requests() {
+		if r.Path == "/ig_user/media_publish" && r.Query.Get("creation_id") != "container_1" {
+			t.Errorf("media_publish creation_id = %q, want container_1", r.Query.Get("creation_id"))
+		}
+	}
+}
+
+func TestPublishRetryReplacesExpiredContainer(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.Handle(http.MethodGet, "/container_old", http.StatusOK, `{"id":"container_old","status_code":"EXPIRED"}`)
+	srv.Handle(http.MethodPost, "/ig_user/media", http.StatusOK, `{"id":"container_new"}`)
+	srv.Handle(http.MethodGet, "/container_new", http.StatusOK, `{"id":"container_new","status_code":"FINISHED"}`)
+	srv.Handle(http.MethodPost, "/ig_user/media_publish", http.StatusOK, `{"id":"media_1"}`)
+	srv.Handle(http.MethodGet, "/media_1", http.StatusOK, `{"id":"media_1"}`)
+
+	pub := imagePost()
+	pub.ContainerID = "container_old"
+
+	var stored string
+	_, err := instagram.NewPublisher(srv.Client()).Publish(context.Background(), instagram.PublishInput{
+		UserID:             "ig_user",
+		AccessToken:        "token",
+		Publication:        pub,
+		OnContainerCreated: func(containerID string) { stored = containerID },
+	})
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if stored != "container_new" {
+		t.Errorf("stored container = %q, want container_new", stored)
+	}
+}
+
+func TestPublishRetryDetectsPublishedContainer(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.Handle(http.MethodGet, "/container_1", http.StatusOK, `{"id":"container_1","status_code":"PUBLISHED"}`)
+
+	pub := imagePost()
+	pub.ContainerID = "container_1"
+
+	_, err := instagram.NewPublisher(srv.Client()).Publish(context.Background(), instagram.PublishInput{
+		UserID:      "ig_user",
+		AccessToken: "token",
+		Publication: pub,
+	})
+	if err != entity.ErrContainerPublished {
+		t.Fatalf("err = %v, want ErrContainerPublished", err)
+	}
+	if n := countRequests(srv, http.MethodPost, "/ig_user/media_publish"); n != 0 {
+		t.Errorf("media_publish called %d times, want 0", n)
+	}
+}
+
+func TestFindPublishedMediaMatchesCaption(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.Handle(http.MethodGet, "/container_1", http.StatusOK, `{"id":"container_1","status_code":"PUBLISHED"}`)
+	srv.Handle(http.MethodGet, "/ig_user/media", http.StatusOK, `{"data":[
+		{"id":"media_new","caption":"Something else","timestamp":"2025-06-01T12:10:00+0000"},
+		{"id":"media_1","caption":"Launch day","timestamp":"2025-06-01T12:05:00+0000","permalink":"https://instagram.com/p/abc","media_product_type":"FEED"},
+		{"id":"media_old","caption":"Launch day","timestamp":"2025-05-01T09:00:00+0000"}
+	]}`)
+
+	pub := imagePost()
+	pub.Caption = "Launch day"
+	pub.ContainerID = "container_1"
+	pub.CreatedAt = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	in := instagram.PublishInput{UserID: "ig_user", AccessToken: "token", Publication: pub}
+
+	publisher := instagram.NewPublisher(srv.Client())
+	out, err := publisher.FindPublishedMedia(context.Background(), in)
+	if err != nil {
+		t.Fatalf("FindPublishedMedia: %v", err)
+	}
+	if out == nil || out.InstagramMediaID != "media_1" || out.Permalink != "https://instagram.com/p/abc" || out.MediaProductType != "FEED" {
+		t.Fatalf("out = %+v, want media_1 with its details", out)
+	}
+
+	// A container not published yet has no media
+	srv.Handle(http.MethodGet, "/container_1", http.StatusOK, `{"id":"container_1","status_code":"FINISHED"}`)
+	if out, err := publisher.FindPublishedMedia(context.Background(), in); out != nil || err != nil {
+		t.Errorf("unpublished container: out = %+v, err = %v, want nil", out, err)
+	}
+}
+
+func TestPrepareContainerWaitsWithoutPublishing(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.Handle(http.MethodPost, "/ig_user/media", http.StatusOK, `{"id":"container_1"}`)
+	srv.Handle(http.MethodGet, "/container_1", http.StatusOK, `{"id":"container_1","status_code":"FINISHED"}`)
+
+	containerID, err := instagram.NewPublisher(srv.Client()).PrepareContainer(context.Background(), instagram.PublishInput{
+		UserID:      "ig_user",
+		AccessToken: "token",
+		Publication: imagePost(),
+	})
+	if err != nil {
+		t.Fatalf("PrepareContainer: %v", err)
+	}
+	if containerID != "container_1" {
+		t.Errorf("container = %q, want container_1", containerID)
+	}
+	if n := countRequests(srv, http.MethodPost, "/ig_user/media_publish"); n != 0 {
+		t.Errorf("media_publish called %d times, want 0", n)
+	}
+}
+
+func TestPrepareContainerReportsBadMedia(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.Handle(http.MethodPost, "/ig_user/media", http.StatusOK, `{"id":"container_1"}`)
+	srv.Handle(http.MethodGet, "/container_1", http.StatusOK, `{"id":"container_1","status_code":"ERROR","status":"unsupported aspect ratio"}`)
+
+	_, err := instagram.NewPublisher(srv.Client()).PrepareContainer(context.Background(), instagram.PublishInput{
+		UserID:      "ig_user",
+		AccessToken: "token",
+		Publication: imagePost(),
+	})
+	if !errors.Is(err, entity.ErrContainerFailed) {
+		t.Fatalf("err = %v, want ErrContainerFailed", err)
+	}
+	if !strings.Contains(err.Error(), "unsupported aspect ratio") {
+		t.Errorf("err = %v, want Instagram's reason", err)
+	}
+}
+
+func TestCarouselSizeCheckedBeforeCreatingContainers(t *testing.T) {
+	tests := []struct {
+		items      int
+		wantErr    error
+		wantCreate int // POST /media calls: one per child plus the carousel itself
+	}{
+		{items: 1, wantCreate: 1}, // single image post
+		{items: 2, wantCreate: 3},
+		{items: 10, wantCreate: 11},
+		{items: 11, wantErr: entity.ErrTooManyMediaItems},
+	}
+
+	for _, tt := range tests {
+		srv := instagramtest.NewServer(t)
+		srv.Handle(http.MethodPost, "/ig_user/media", http.StatusOK, `{"id":"container_1"}`)
+		srv.Handle(http.MethodGet, "/container_1", http.StatusOK, `{"id":"container_1","status_code":"FINISHED"}`)
+
+		pub := imagePost()
+		pub.Media = make([]entity.MediaItem, tt.items)
+		for i := range pub.Media {
+			pub.Media[i] = entity.MediaItem{URL: "https://cdn.example.com/a.jpg", Type: entity.MediaTypeImage}
+		}
+
+		_, err := instagram.NewPublisher(srv.Client()).PrepareContainer(context.Background(), instagram.PublishInput{
+			UserID:      "ig_user",
+			AccessToken: "token",
+			Publication: pub,
+		})
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%d items: err = %v, want %v", tt.items, err, tt.wantErr)
+		}
+		if n := countRequests(srv, http.MethodPost, "/ig_user/media"); n != tt.wantCreate {
+			t.Errorf("%d items: %d containers created, want %d", tt.items, n, tt.wantCreate)
+		}
+	}
+}
+
+func TestFeedPostInvitesCollaborators(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.Handle(http.MethodPost, "/ig_user/media", http.StatusOK, `{"id":"container_1"}`)
+	srv.Handle(http.MethodGet, "/container_1", http.StatusOK, `{"id":"container_1","status_code":"FINISHED"}`)
+
+	pub := imagePost()
+	pub.Collaborators = []string{"brand_a", "brand_b"}
+	pub.Media = append(pub.Media, entity.MediaItem{URL: "https://cdn.example.com/b.jpg", Type: entity.MediaTypeImage})
+
+	_, err := instagram.NewPublisher(srv.Client()).PrepareContainer(context.Background(), instagram.PublishInput{
+		UserID:      "ig_user",
+		AccessToken: "token",
+		Publication: pub,
+	})
+	if err != nil {
+		t.Fatalf("PrepareContainer: %v", err)
+	}
+
+	// Only the carousel container invites collaborators, not its items
+	for _, r := range srv.Requests() {
+		if r.Method != http.MethodPost || r.Path != "/ig_user/media" {
+			continue
+		}
+		got := r.Query.Get("collaborators")
+		if r.Query.Get("media_type") == "CAROUSEL" {
+			if got != "brand_a,brand_b" {
+				t.Errorf("carousel collaborators = %q, want brand_a,brand_b", got)
+			}
+		} else if r.Query.Has("collaborators") {
+			t.Errorf("carousel item collaborators = %q, want unset", got)
+		}
+	}
+
+	// A single media post invites them on its own container
+	pub.Media = pub.Media[:1]
+	if _, err := instagram.NewPublisher(srv.Client()).PrepareContainer(context.Background(), instagram.PublishInput{
+		UserID:      "ig_user",
+		AccessToken: "token",
+		Publication: pub,
+	}); err != nil {
+		t.Fatalf("PrepareContainer single: %v", err)
+	}
+	var last instagramtest.Request
+	for _, r := range srv.Requests() {
+		if r.Method == http.MethodPost && r.Path == "/ig_user/media" {
+			last = r
+		}
+	}
+	if got := last.Query.Get("collaborators"); got != "brand_a,brand_b" {
+		t.Errorf("single post collaborators = %q, want brand_a,brand_b", got)
+	}
+}
+
+func TestPublishContainerTerminalStates(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      string
+		wantErr     error
+		wantMessage string // Instagram's reason kept in the error
+	}{
+		{name: "finished", status: `{"id":"container_1","status_code":"FINISHED"}`},
+		{name: "error with reason", status: `{"id":"container_1","status_code":"ERROR","status":"Error: video duration exceeds 90 seconds"}`,
+			wantErr: entity.ErrContainerFailed, wantMessage: "video duration exceeds 90 seconds"},
+		{name: "error without reason", status: `{"id":"container_1","status_code":"ERROR"}`, wantErr: entity.ErrContainerFailed},
+		{name: "expired", status: `{"id":"container_1","status_code":"EXPIRED"}`, wantErr: entity.ErrContainerExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := instagramtest.NewServer(t)
+			srv.Handle(http.MethodPost, "/ig_user/media", http.StatusOK, `{"id":"container_1"}`)
+			srv.Handle(http.MethodGet, "/container_1", http.StatusOK, tt.status)
+			srv.Handle(http.MethodPost, "/ig_user/media_publish", http.StatusOK, `{"id":"media_1"}`)
+			srv.Handle(http.MethodGet, "/media_1", http.StatusOK, `{"id":"media_1"}`)
+
+			_, err := instagram.NewPublisher(srv.Client()).Publish(context.Background(), instagram.PublishInput{
+				UserID:      "ig_user",
+				AccessToken: "token",
+				Publication: imagePost(),
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantMessage != "" && !strings.Contains(err.Error(), tt.wantMessage) {
+				t.Errorf("err = %v, want Instagram's reason %q", err, tt.wantMessage)
+			}
+
+			wantPublish := 0
+			if tt.wantErr == nil {
+				wantPublish = 1
+			}
+			if n := countRequests(srv, http.MethodPost, "/ig_user/media_publish"); n != wantPublish {
+				t.Errorf("media_publish called %d times, want %d", n, wantPublish)
+			}
+		})
+	}
+}
+
+func storyWithStickers() *entity.Publication {
+	return &entity.Publication{
+		ID:   "pub_1",
+		Type: entity.PublicationTypeStory,
+		Media: []entity.MediaItem{
+			{URL: "https://cdn.example.com/a.jpg", Type: entity.MediaTypeImage},
+		},
+		StoryOptions: &entity.StoryOptions{
+			LinkURL:  "https://shop.example.com/sale?utm_source=ig",
+			Mentions: []string{"brand_a", "brand_b"},
+		},
+	}
+}
+
+func TestStoryEncodesLinkAndMentions(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.Handle(http.MethodPost, "/ig_user/media", http.StatusOK, `{"id":"container_1"}`)
+	srv.Handle(http.MethodGet, "/container_1", http.StatusOK, `{"id":"container_1","status_code":"FINISHED"}`)
+
+	if _, err := instagram.NewPublisher(srv.Client()).PrepareContainer(context.Background(), instagram.PublishInput{
+		UserID:      "ig_user",
+		AccessToken: "token",
+		Publication: storyWithStickers(),
+	}); err != nil {
+		t.Fatalf("PrepareContainer: %v", err)
+	}
+
+	var create instagramtest.Request
+	for _, r := range srv.Requests() {
+		if r.Method == http.MethodPost && r.Path == "/ig_user/media" {
+			create = r
+		}
+	}
+	if got := create.Query.Get("media_type"); got != "STORIES" {
+		t.Errorf("media_type = %q, want STORIES", got)
+	}
+	if got := create.Query.Get("link"); got != "https://shop.example.com/sale?utm_source=ig" {
+		t.Errorf("link = %q", got)
+	}
+	if got := create.Query.Get("user_tags"); got != `[{"username":"brand_a"},{"username":"brand_b"}]` {
+		t.Errorf("user_tags = %q", got)
+	}
+}
+
+func storyImageWithAltText() *entity.Publication {
+	return &entity.Publication{
+		ID:   "pub_1",
+		Type: entity.PublicationTypeStory,
+		Media: []entity.MediaItem{
+			{URL: "https://cdn.example.com/a.jpg", Type: entity.MediaTypeImage, AltText: "Sale banner"},
+		},
+	}
+}
+
+func TestStoryAltTextSetAfterPublish(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.Handle(http.MethodPost, "/ig_user/media", http.StatusOK, `{"id":"container_1"}`)
+	srv.Handle(http.MethodGet, "/container_1", http.StatusOK, `{"id":"container_1","status_code":"FINISHED"}`)
+	srv.Handle(http.MethodPost, "/ig_user/media_publish", http.StatusOK, `{"id":"media_1"}`)
+	srv.Handle(http.MethodGet, "/media_1", http.StatusOK, `{"id":"media_1"}`)
+	srv.Handle(http.MethodPost, "/media_1", http.StatusOK, `{"success":true}`)
+
+	out, err := instagram.NewPublisher(srv.Client()).Publish(context.Background(), instagram.PublishInput{
+		UserID:      "ig_user",
+		AccessToken: "token",
+		Publication: storyImageWithAltText(),
+	})
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if out.InstagramMediaID != "media_1" {
+		t.Errorf("media ID = %q, want media_1", out.InstagramMediaID)
+	}
+
+	var create, update instagramtest.Request
+	for _, r := range srv.Requests() {
+		switch {
+		case r.Method == http.MethodPost && r.Path == "/ig_user/media":
+			create = r
+		case r.Method == http.MethodPost && r.Path == "/media_1":
+			update = r
+		}
+	}
+	if create.Query.Has("alt_text") {
+		t.Errorf("story container got alt_text %q", create.Query.Get("alt_text"))
+	}
+	if got := update.Query.Get("alt_text"); got != "Sale banner" {
+		t.Errorf("alt_text set after publish = %q, want Sale banner", got)
+	}
+}
+
+func TestStoryAltTextFailureKeepsPublish(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.Handle(http.MethodPost, "/ig_user/media", http.StatusOK, `{"id":"container_1"}`)
+	srv.Handle(http.MethodGet, "/container_1", http.StatusOK, `{"id":"container_1","status_code":"FINISHED"}`)
+	srv.Handle(http.MethodPost, "/ig_user/media_publish", http.StatusOK, `{"id":"media_1"}`)
+	srv.Handle(http.MethodGet, "/media_1", http.StatusOK, `{"id":"media_1"}`)
+	srv.HandleError(http.MethodPost, "/media_1", http.StatusBadRequest, 100, 0, "Unsupported request")
+
+	out, err := instagram.NewPublisher(srv.Client()).Publish(context.Background(), instagram.PublishInput{
+		UserID:      "ig_user",
+		AccessToken: "token",
+		Publication: storyImageWithAltText(),
+	})
+	if err != nil {
+		t.Fatalf("Publish: %v, want the story published despite the alt text error", err)
+	}
+	if out.InstagramMediaID != "media_1" {
+		t.Errorf("media ID = %q, want media_1", out.InstagramMediaID)
+	}
+}
+
+func TestStoryStickersRejectedForAccount(t *testing.T) {
+	srv := instagramtest.NewServer(t)
+	srv.HandleError(http.MethodPost, "/ig_user/media", http.StatusBadRequest, 100, 0, "Invalid parameter")
+
+	_, err := instagram.NewPublisher(srv.Client()).Publish(context.Background(), instagram.PublishInput{
+		UserID:      "ig_user",
+		AccessToken: "token",
+		Publication: storyWithStickers(),
+	})
+	if !errors.Is(err, entity.ErrStoryStickersRejected) {
+		t.Fatalf("err = %v, want ErrStoryStickersRejected", err)
+	}
+	if !strings.Contains(err.Error(), "Invalid parameter") {
+		t.Errorf("err = %v, want Instagram's message kept", err)
+	}
+}
+
+// newFlakyCarouselServer serves a four-image carousel whose third image (c.jpg) fails
+// container creation with the given responses, one per attempt, before succeeding
+func newFlakyCarouselServer(t *testing.T, failures ...instagramtest.Response) *instagramtest.Server {
+	srv := instagramtest.NewServer(t)
+	srv.HandleFunc(http.MethodPost, "/ig_user/media", func(r instagramtest.Request) instagramtest.Response {
+		if r.Query.Get("media_type") == "CAROUSEL" {
+			return instagramtest.Response{Body: `{"id":"carousel"}`}
+		}
+		name := path.Base(r.Query.Get("image_url"))
+		if name == "c.jpg" && len(failures) > 0 {
+			resp := failures[0]
+			failures = failures[1:]
+			return resp
+		}
+		return instagramtest.Response{Body: `{"id":"child_` + name + `"}`}
+	})
+	return srv
+}
+
+func fourImageCarousel() *entity.Publication {
+	pub := imagePost()
+	pub.Media = nil
+	for _, name := range []string{"a.jpg", "b.jpg", "c.jpg", "d.jpg"} {
+		pub.Media = append(pub.Media, entity.MediaItem{URL: "https://cdn.example.com/" + name, Type: entity.MediaTypeImage})
+	}
+	return pub
+}
+
+func TestCarouselItemFailureIdentifiesItem(t *testing.T) {
+	srv := newFlakyCarouselServer(t, instagramtest.Response{
+		Status: http.StatusBadRequest,
+		Body:   instagramtest.ErrorBody(9004, 2207052, "Media download has failed"),
+	})
+
+	_, err := instagram.NewPublisher(srv.Client()).PrepareContainer(context.Background(), instagram.PublishInput{
+		UserID:      "ig_user",
+		AccessToken: "token",
+		Publication: fourImageCarousel(),
+	})
+
+	var itemErr *instagram.CarouselItemError
+	if !errors.As(err, &itemErr) {
+		t.Fatalf("err = %v, want a CarouselItemError", err)
+	}
+	if itemErr.Index != 2 || itemErr.Count != 4 || !strings.Contains(err.Error(), "carousel item 3 of 4 (image)") {
+		t.Errorf("err = %v (item %d of %d), want item 3 of 4", err, itemErr.Index+1, itemErr.Count)
+	}
+	var apiErr *instagram.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != 9004 {
+		t.Errorf("err = %v, want Instagram's error kept", err)
+	}
+
+	// A permanent failure is not retried, and no later item or carousel is created
+	if n := countRequests(srv, http.MethodPost, "/ig_user/media"); n != 3 {
+		t.Errorf("created %d containers, want 3 (a, b and the failed c)", n)
+	}
+}
+
+func TestCarouselItemRetriedAfterTemporaryFailure(t *testing.T) {
+	srv := newFlakyCarouselServer(t, instagramtest.Response{
+		Status: http.StatusInternalServerError,
+		Body:   instagramtest.ErrorBody(2, 0, "Service temporarily unavailable"),
+	})
+	srv.Handle(http.MethodGet, "/carousel", http.StatusOK, `{"id":"carousel","status_code":"FINISHED"}`)
+
+	containerID, err := instagram.NewPublisher(srv.Client()).PrepareContainer(context.Background(), instagram.PublishInput{
+		UserID:      "ig_user",
+		AccessToken: "token",
+		Publication: fourImageCarousel(),
+	})
+	if err != nil {
+		t.Fatalf("PrepareContainer: %v", err)
+	}
+	if containerID != "carousel" {
+		t.Errorf("container = %q, want carousel", containerID)
+	}
+
+	// Four items, one retry and the carousel
+	if n := countRequests(srv, http.MethodPost, "/ig_user/media"); n != 6 {
+		t.Errorf("created %d containers, want 6", n)
+	}
+	var children string
+	for _, r := range srv.Requests() {
+		if r.Query.Get("media_type") == "CAROUSEL" {
+			children = strings.Join(r.Query["children"], ",")
+		}
+	}
+	if children != "child_a.jpg,child_b.jpg,child_c.jpg,child_d.jpg" {
+		t.Errorf("children = %q, want all four items in order", children)
+	}
+}
@@ -0,0 +1,79 @@
+package instagram
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/vadim/neo-metric/internal/apiusage"
+)
+
+// Rate limit usage headers sent with Graph API responses
+const (
+	headerAppUsage             = "X-App-Usage"               // {"call_count":28,"total_time":25,"total_cputime":25}
+	headerBusinessUseCaseUsage = "X-Business-Use-Case-Usage" // {"<id>":[{"type":"instagram","call_count":95,...,"estimated_time_to_regain_access":0}]}
+)
+
+// UsageRecorder receives the rate limit usage reported with every response that carries it
+type UsageRecorder interface {
+	Record(u apiusage.Usage)
+}
+
+// WithUsageRecorder reports the rate limit usage headers of responses to r
+func WithUsageRecorder(r UsageRecorder) ClientOption {
+	return func(c *Client) {
+		c.usage = r
+	}
+}
+
+// appUsage is the app-wide usage in percent of each limit
+type appUsage struct {
+	CallCount    int `json:"call_count"`
+	TotalTime    int `json:"total_time"`
+	TotalCPUTime int `json:"total_cputime"`
+}
+
+func (u appUsage) highest() int {
+	return max(u.CallCount, u.TotalTime, u.TotalCPUTime)
+}
+
+// businessUseCaseUsage is the usage of one business account and use case
+type businessUseCaseUsage struct {
+	appUsage
+	Type                        string `json:"type"`
+	EstimatedTimeToRegainAccess int    `json:"estimated_time_to_regain_access"` // Minutes; 0 unless throttled
+}
+
+// parseUsage reads the rate limit usage headers of a response; ok is false if it carries none
+// or none can be decoded. The usage is the highest percent across all reported limits.
+func parseUsage(h http.Header, now time.Time) (u apiusage.Usage, ok bool) {
+	u.At = now
+
+	if v := h.Get(headerAppUsage); v != "" {
+		var app appUsage
+		if json.Unmarshal([]byte(v), &app) == nil {
+			u.Percent = app.highest()
+			ok = true
+		}
+	}
+
+	if v := h.Get(headerBusinessUseCaseUsage); v != "" {
+		var byBusiness map[string][]businessUseCaseUsage
+		if json.Unmarshal([]byte(v), &byBusiness) == nil {
+			regain := 0
+			for _, usages := range byBusiness {
+				for _, bu := range usages {
+					u.Percent = max(u.Percent, bu.highest())
+					regain = max(regain, bu.EstimatedTimeToRegainAccess)
+					ok = true
+				}
+			}
+			if regain > 0 {
+				at := now.Add(time.Duration(regain) * time.Minute)
+				u.RegainAt = &at
+			}
+		}
+	}
+
+	return u, ok
+}
@@ -0,0 +1,52 @@
+// Package validate collects the field problems of a request body, so a handler reports
+// all of them in one response instead of stopping at the first.
+package validate
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/vadim/neo-metric/internal/httpx/response"
+)
+
+// Errors accumulates field errors; the zero value is ready to use
+type Errors struct {
+	fields []response.FieldError
+}
+
+// Required records a missing field unless present
+func (e *Errors) Required(field string, present bool) {
+	if !present {
+		e.Add(field, response.CodeRequired, field+" is required")
+	}
+}
+
+// Invalid records a field whose value is not accepted
+func (e *Errors) Invalid(field, message string) {
+	e.Add(field, response.CodeInvalid, message)
+}
+
+// Add records a field error with a specific code
+func (e *Errors) Add(field string, code response.Code, message string) {
+	e.fields = append(e.fields, response.FieldError{Field: field, Code: code, Message: message})
+}
+
+// Item returns the name of a field of the i-th item of list, e.g. Item("media", 1, "type") is "media[1].type"
+func Item(list string, i int, field string) string {
+	return fmt.Sprintf("%s[%d].%s", list, i, field)
+}
+
+// Fields returns the recorded field errors in the order they were added
+func (e *Errors) Fields() []response.FieldError {
+	return e.fields
+}
+
+// Respond sends a 422 listing the recorded field errors and returns true, or returns false
+// without writing anything if there are none
+func (e *Errors) Respond(w http.ResponseWriter) bool {
+	if len(e.fields) == 0 {
+		return false
+	}
+	response.ValidationFailed(w, e.fields)
+	return true
+}
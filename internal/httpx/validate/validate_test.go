@@ -0,0 +1,60 @@
+package validate
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vadim/neo-metric/internal/httpx/response"
+)
+
+func TestRespondListsAllFields(t *testing.T) {
+	var errs Errors
+	errs.Required("account_id", false)
+	errs.Required("caption", true)
+	errs.Invalid(Item("media", 1, "type"), "invalid media type")
+
+	rec := httptest.NewRecorder()
+	if !errs.Respond(rec) {
+		t.Fatal("Respond = false with field errors")
+	}
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want 422", rec.Code)
+	}
+
+	var body struct {
+		Error response.ErrorBody `json:"error"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding body: %v", err)
+	}
+	want := []response.FieldError{
+		{Field: "account_id", Code: response.CodeRequired, Message: "account_id is required"},
+		{Field: "media[1].type", Code: response.CodeInvalid, Message: "invalid media type"},
+	}
+	if body.Error.Code != response.CodeValidationFailed || body.Error.Message != "request has 2 invalid fields" {
+		t.Errorf("error = %s %q", body.Error.Code, body.Error.Message)
+	}
+	if len(body.Error.Fields) != len(want) {
+		t.Fatalf("fields = %+v, want %+v", body.Error.Fields, want)
+	}
+	for i := range want {
+		if body.Error.Fields[i] != want[i] {
+			t.Errorf("fields[%d] = %+v, want %+v", i, body.Error.Fields[i], want[i])
+		}
+	}
+}
+
+func TestRespondWithoutErrorsWritesNothing(t *testing.T) {
+	var errs Errors
+	errs.Required("account_id", true)
+
+	rec := httptest.NewRecorder()
+	if errs.Respond(rec) {
+		t.Error("Respond = true without field errors")
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("body = %q, want nothing written", rec.Body.String())
+	}
+}
@@ -0,0 +1,145 @@
+// Package webhook delivers signed event notifications to an integrator's endpoint.
+//
+// Each request carries the Unix time it was sent in TimestampHeader and
+// "sha256=<hex>" in SignatureHeader, where <hex> is the HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the shared secret. Receivers should
+// recompute the signature and reject requests with an old timestamp to
+// prevent replays.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Request headers set on every delivery
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+)
+
+// Event is the JSON body of a delivery
+type Event struct {
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+// Sender posts events to a single URL in the background
+type Sender struct {
+	url        string
+	secret     []byte
+	client     *http.Client
+	maxRetries int
+	retryDelay time.Duration // Doubled after each failed attempt
+	logger     *slog.Logger
+	wg         sync.WaitGroup
+}
+
+// NewSender creates a sender; each attempt is bounded by timeout and failed
+// deliveries are retried up to maxRetries times
+func NewSender(url, secret string, timeout time.Duration, maxRetries int, logger *slog.Logger) *Sender {
+	return &Sender{
+		url:        url,
+		secret:     []byte(secret),
+		client:     &http.Client{Timeout: timeout},
+		maxRetries: maxRetries,
+		retryDelay: time.Second,
+		logger:     logger,
+	}
+}
+
+// Send delivers the event in the background and returns immediately.
+// Delivery is best effort: failures are logged once the retries are used up.
+func (s *Sender) Send(eventType string, data any) {
+	body, err := json.Marshal(Event{Type: eventType, OccurredAt: time.Now().UTC(), Data: data})
+	if err != nil {
+		s.logger.Error("webhook: encoding event", "type", eventType, "error", err)
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.deliver(body, eventType)
+	}()
+}
+
+// Wait blocks until in-flight deliveries finish or ctx is done
+func (s *Sender) Wait(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+// deliver posts body, retrying network errors, 429 and 5xx responses
+func (s *Sender) deliver(body []byte, eventType string) {
+	delay := s.retryDelay
+	for attempt := 0; ; attempt++ {
+		retry, err := s.post(body)
+		if err == nil {
+			return
+		}
+		if !retry || attempt >= s.maxRetries {
+			s.logger.Warn("webhook: delivery failed", "type", eventType, "attempts", attempt+1, "error", err)
+			return
+		}
+
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// post makes one delivery attempt and reports whether a failure is worth retrying
+func (s *Sender) post(body []byte) (retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	// Signed per attempt so the timestamp reflects when the request was sent
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, strconv.FormatInt(ts, 10))
+	req.Header.Set(SignatureHeader, "sha256="+Sign(s.secret, ts, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("endpoint returned %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("endpoint returned %d", resp.StatusCode)
+	}
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with secret
+func Sign(secret []byte, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
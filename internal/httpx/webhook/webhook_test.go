@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestSender(url string, maxRetries int) *Sender {
+	s := NewSender(url, "secret", time.Second, maxRetries, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	s.retryDelay = time.Millisecond
+	return s
+}
+
+func TestSendSignsPayload(t *testing.T) {
+	got := make(chan *http.Request, 1)
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		got <- r
+	}))
+	defer srv.Close()
+
+	s := newTestSender(srv.URL, 0)
+	s.Send("publication.published", map[string]string{"publication_id": "pub_1"})
+	s.Wait(context.Background())
+
+	r := <-got
+	ts, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		t.Fatalf("timestamp header: %v", err)
+	}
+	if time.Since(time.Unix(ts, 0)) > time.Minute {
+		t.Errorf("timestamp %d is not current", ts)
+	}
+	if want := "sha256=" + Sign([]byte("secret"), ts, body); r.Header.Get(SignatureHeader) != want {
+		t.Errorf("signature = %q, want %q", r.Header.Get(SignatureHeader), want)
+	}
+
+	var event struct {
+		Type string            `json:"type"`
+		Data map[string]string `json:"data"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		t.Fatalf("body: %v", err)
+	}
+	if event.Type != "publication.published" || event.Data["publication_id"] != "pub_1" {
+		t.Errorf("event = %+v", event)
+	}
+}
+
+func TestSendRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		wantCalls int32
+	}{
+		{"server error is retried", http.StatusBadGateway, 3},
+		{"rate limit is retried", http.StatusTooManyRequests, 3},
+		{"client error is not retried", http.StatusBadRequest, 1},
+		{"success", http.StatusNoContent, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			s := newTestSender(srv.URL, 2)
+			s.Send("publication.error", nil)
+			s.Wait(context.Background())
+
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// Package imagefit pads or crops images to the aspect ratios Instagram accepts.
+package imagefit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+
+	// Decoders for the formats accepted by the media upload endpoint
+	_ "image/gif"
+	_ "image/png"
+)
+
+// DefaultMaxPixels bounds decoded and fitted images, so a small compressed file can't expand into gigabytes of memory
+const DefaultMaxPixels = 40_000_000
+
+// Errors returned when decoding and fitting
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrTooLarge          = errors.New("image dimensions too large")
+	ErrExtremeAspect     = errors.New("image aspect ratio too extreme to fit")
+)
+
+// Aspect is a width:height ratio
+type Aspect struct {
+	W, H int
+}
+
+// Aspect ratios Instagram accepts without cropping on its side
+var (
+	AspectSquare    = Aspect{1, 1}
+	AspectPortrait  = Aspect{4, 5}     // Tallest feed image
+	AspectLandscape = Aspect{191, 100} // Widest feed image, 1.91:1
+	AspectVertical  = Aspect{9, 16}    // Stories and reels
+)
+
+// Mode is how an image is brought to the target aspect
+type Mode string
+
+const (
+	ModePad  Mode = "pad"  // Add bars, keeping the whole image
+	ModeCrop Mode = "crop" // Cut the edges around the center
+)
+
+// Decode decodes a JPEG, PNG or GIF image, checking its dimensions from the header
+// before allocating pixels. Images over maxPixels return ErrTooLarge.
+func Decode(data []byte, maxPixels int) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > int64(maxPixels) {
+		return nil, fmt.Errorf("%w: %dx%d exceeds %d pixels", ErrTooLarge, cfg.Width, cfg.Height, maxPixels)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	return img, nil
+}
+
+// Fit returns img at the target aspect. Padding centers the image on a background
+// of bg; cropping keeps the center. Images already at the aspect are copied as is.
+// Padding a narrow strip grows it a lot, so the result is checked against maxPixels
+// before allocating: ErrTooLarge if over, ErrExtremeAspect if cropping leaves nothing.
+func Fit(img image.Image, target Aspect, mode Mode, bg color.Color, maxPixels int) (*image.RGBA, error) {
+	src := img.Bounds()
+	w, h := int64(src.Dx()), int64(src.Dy())
+	tw, th := int64(target.W), int64(target.H)
+
+	// Compare w/h with tw/th without floating point
+	wider := w*th > tw*h
+	taller := w*th < tw*h
+
+	outW, outH := w, h
+	switch {
+	case mode == ModeCrop && wider:
+		outW = h * tw / th
+	case mode == ModeCrop && taller:
+		outH = w * th / tw
+	case wider:
+		outH = (w*th + tw - 1) / tw
+	case taller:
+		outW = (h*tw + th - 1) / th
+	}
+	if outW < 1 || outH < 1 {
+		return nil, fmt.Errorf("%w: %dx%d to %d:%d", ErrExtremeAspect, w, h, tw, th)
+	}
+	// Checked by division: the product of two huge sides can overflow int64
+	if outW > int64(maxPixels)/outH {
+		return nil, fmt.Errorf("%w: fitted %dx%d exceeds %d pixels", ErrTooLarge, outW, outH, maxPixels)
+	}
+
+	out := image.NewRGBA(image.Rect(0, 0, int(outW), int(outH)))
+	draw.Draw(out, out.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+
+	// Center the source: for padding the offset lands inside out, for cropping inside src
+	offset := image.Pt(int(outW-w)/2, int(outH-h)/2)
+	if mode == ModeCrop {
+		draw.Draw(out, out.Bounds(), img, src.Min.Sub(offset), draw.Over)
+	} else {
+		draw.Draw(out, src.Sub(src.Min).Add(offset), img, src.Min, draw.Over)
+	}
+	return out, nil
+}
+
+// EncodeJPEG encodes img as JPEG, the only image format Instagram publishes
+func EncodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90}); err != nil {
+		return nil, fmt.Errorf("encoding jpeg: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,146 @@
+package imagefit
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+var (
+	red   = color.RGBA{255, 0, 0, 255}
+	green = color.RGBA{0, 255, 0, 255}
+	white = color.RGBA{255, 255, 255, 255}
+)
+
+// bandedImage is green with red bands of the given thickness along the long edges' ends
+func bandedImage(w, h, band int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := green
+			if (h > w && (y < band || y >= h-band)) || (w > h && (x < band || x >= w-band)) {
+				c = red
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func assertSize(t *testing.T, img image.Image, w, h int) {
+	t.Helper()
+	if got := img.Bounds().Size(); got != image.Pt(w, h) {
+		t.Fatalf("size = %v, want %dx%d", got, w, h)
+	}
+}
+
+func assertColor(t *testing.T, img image.Image, x, y int, want color.RGBA) {
+	t.Helper()
+	if got := color.RGBAModel.Convert(img.At(x, y)); got != want {
+		t.Errorf("pixel (%d,%d) = %v, want %v", x, y, got, want)
+	}
+}
+
+func mustFit(t *testing.T, img image.Image, target Aspect, mode Mode) *image.RGBA {
+	t.Helper()
+	out, err := Fit(img, target, mode, white, DefaultMaxPixels)
+	if err != nil {
+		t.Fatalf("Fit: %v", err)
+	}
+	return out
+}
+
+func TestFitPortraitToSquare(t *testing.T) {
+	src := bandedImage(60, 100, 20)
+
+	padded := mustFit(t, src, AspectSquare, ModePad)
+	assertSize(t, padded, 100, 100)
+	assertColor(t, padded, 10, 50, white) // Left bar
+	assertColor(t, padded, 89, 50, white) // Right bar
+	assertColor(t, padded, 50, 5, red)    // Whole image kept, top band included
+	assertColor(t, padded, 50, 50, green)
+
+	cropped := mustFit(t, src, AspectSquare, ModeCrop)
+	assertSize(t, cropped, 60, 60)
+	assertColor(t, cropped, 30, 0, green) // Red bands cut off
+	assertColor(t, cropped, 30, 59, green)
+}
+
+func TestFitLandscapeToPortrait(t *testing.T) {
+	src := bandedImage(200, 100, 60)
+
+	padded := mustFit(t, src, AspectPortrait, ModePad)
+	assertSize(t, padded, 200, 250)
+	assertColor(t, padded, 100, 10, white) // Top bar
+	assertColor(t, padded, 100, 240, white)
+	assertColor(t, padded, 100, 125, green)
+	assertColor(t, padded, 5, 125, red)
+
+	cropped := mustFit(t, src, AspectPortrait, ModeCrop)
+	assertSize(t, cropped, 80, 100)
+	assertColor(t, cropped, 0, 50, green)
+	assertColor(t, cropped, 79, 50, green)
+}
+
+func TestFitKeepsMatchingAspect(t *testing.T) {
+	src := bandedImage(90, 160, 10)
+	assertSize(t, mustFit(t, src, AspectVertical, ModePad), 90, 160)
+	assertSize(t, mustFit(t, src, AspectVertical, ModeCrop), 90, 160)
+}
+
+func TestDecodeBoundsDimensions(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, bandedImage(10, 20, 2)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Decode(buf.Bytes(), 199); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("err = %v, want ErrTooLarge", err)
+	}
+	if _, err := Decode(buf.Bytes(), 200); err != nil {
+		t.Errorf("Decode: %v", err)
+	}
+	if _, err := Decode([]byte("not an image"), 200); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("err = %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+// blankImage has the given size without allocating pixels
+type blankImage struct {
+	*image.Uniform
+	w, h int
+}
+
+func (b blankImage) Bounds() image.Rectangle { return image.Rect(0, 0, b.w, b.h) }
+
+func TestFitBoundsExtremeAspect(t *testing.T) {
+	tests := []struct {
+		name    string
+		w, h    int
+		target  Aspect
+		mode    Mode
+		wantErr error
+	}{
+		{"strip padded to vertical", 40_000, 1_000, AspectVertical, ModePad, ErrTooLarge},
+		{"one pixel row padded to vertical", 40_000_000, 1, AspectVertical, ModePad, ErrTooLarge},
+		{"one pixel column padded to landscape", 1, 40_000_000, AspectLandscape, ModePad, ErrTooLarge},
+		{"one pixel row cropped to vertical", 40_000_000, 1, AspectVertical, ModeCrop, ErrExtremeAspect},
+	}
+	for _, tt := range tests {
+		src := blankImage{Uniform: image.NewUniform(green), w: tt.w, h: tt.h}
+		if _, err := Fit(src, tt.target, tt.mode, white, DefaultMaxPixels); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	// The same strip cropped stays within the limit
+	src := blankImage{Uniform: image.NewUniform(green), w: 4_000, h: 100}
+	out, err := Fit(src, AspectVertical, ModeCrop, white, DefaultMaxPixels)
+	if err != nil {
+		t.Fatalf("Fit: %v", err)
+	}
+	assertSize(t, out, 56, 100)
+}
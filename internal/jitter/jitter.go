@@ -0,0 +1,34 @@
+// Package jitter provides randomized durations used to spread out periodic work
+// so that independent schedulers don't hit the Instagram API at the same instant.
+package jitter
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Apply returns d randomly shifted by up to ±fraction of d.
+// A fraction of 0.1 yields a duration in [0.9d, 1.1d]. Fractions are clamped to [0, 1].
+func Apply(d time.Duration, fraction float64) time.Duration {
+	if d <= 0 || fraction <= 0 {
+		return d
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+
+	delta := (rand.Float64()*2 - 1) * fraction * float64(d)
+	next := d + time.Duration(delta)
+	if next <= 0 {
+		return d
+	}
+	return next
+}
+
+// UpTo returns a random duration in [0, max)
+func UpTo(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int64N(int64(max)))
+}
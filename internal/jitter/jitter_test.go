@@ -0,0 +1,39 @@
+package jitter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApplyStaysWithinBounds(t *testing.T) {
+	base := time.Minute
+	fraction := 0.1
+	min := time.Duration(float64(base) * (1 - fraction))
+	max := time.Duration(float64(base) * (1 + fraction))
+
+	for i := 0; i < 10000; i++ {
+		got := Apply(base, fraction)
+		if got < min || got > max {
+			t.Fatalf("Apply(%s, %.2f) = %s, want within [%s, %s]", base, fraction, got, min, max)
+		}
+	}
+}
+
+func TestApplyWithoutJitter(t *testing.T) {
+	if got := Apply(time.Minute, 0); got != time.Minute {
+		t.Errorf("Apply with zero fraction = %s, want %s", got, time.Minute)
+	}
+}
+
+func TestUpToStaysWithinBounds(t *testing.T) {
+	max := 30 * time.Second
+	for i := 0; i < 10000; i++ {
+		got := UpTo(max)
+		if got < 0 || got >= max {
+			t.Fatalf("UpTo(%s) = %s, want within [0, %s)", max, got, max)
+		}
+	}
+	if got := UpTo(0); got != 0 {
+		t.Errorf("UpTo(0) = %s, want 0", got)
+	}
+}
@@ -0,0 +1,82 @@
+// Package liveness records the runs of periodic jobs so that a job whose loop died
+// or whose run hangs can be reported, even though the process keeps serving requests.
+package liveness
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// StallFactor is how many intervals a job may go without starting a run before it is stalled
+const StallFactor = 2
+
+// Tracker records when a job was started and when its latest run began and how long it took.
+// The zero value is ready to use; all methods are safe for concurrent use.
+type Tracker struct {
+	startedAt       atomic.Int64 // Unix nanoseconds; 0 while the job is not started
+	lastRunAt       atomic.Int64 // Unix nanoseconds of the latest run start; 0 before the first run
+	lastRunDuration atomic.Int64 // Duration of the latest finished run
+	running         atomic.Bool
+}
+
+// Started marks the job as started at now
+func (t *Tracker) Started(now time.Time) {
+	t.startedAt.Store(now.UnixNano())
+}
+
+// Stopped marks the job as stopped; a stopped job is never stalled
+func (t *Tracker) Stopped() {
+	t.startedAt.Store(0)
+}
+
+// RunStarted marks the beginning of a run and returns the function that marks its end
+func (t *Tracker) RunStarted(now time.Time) (finished func()) {
+	t.lastRunAt.Store(now.UnixNano())
+	t.running.Store(true)
+	return func() {
+		t.lastRunDuration.Store(int64(time.Since(now)))
+		t.running.Store(false)
+	}
+}
+
+// Status returns a snapshot of the job running every interval
+func (t *Tracker) Status(interval time.Duration) Status {
+	s := Status{
+		Interval:        interval,
+		Started:         t.startedAt.Load() != 0,
+		Running:         t.running.Load(),
+		LastRunDuration: time.Duration(t.lastRunDuration.Load()),
+	}
+	if ns := t.startedAt.Load(); ns != 0 {
+		startedAt := time.Unix(0, ns)
+		s.StartedAt = &startedAt
+	}
+	if ns := t.lastRunAt.Load(); ns != 0 {
+		lastRunAt := time.Unix(0, ns)
+		s.LastRunAt = &lastRunAt
+	}
+	return s
+}
+
+// Status is a snapshot of a tracked job
+type Status struct {
+	Interval        time.Duration
+	Started         bool
+	StartedAt       *time.Time
+	Running         bool       // A run is in progress
+	LastRunAt       *time.Time // Start of the latest run; nil before the first run
+	LastRunDuration time.Duration
+}
+
+// Stalled reports whether a started job has gone more than StallFactor intervals without
+// starting a run: its loop is gone or its current run hangs
+func (s Status) Stalled(now time.Time) bool {
+	if !s.Started || s.Interval <= 0 {
+		return false
+	}
+	last := s.StartedAt
+	if s.LastRunAt != nil && s.LastRunAt.After(*last) {
+		last = s.LastRunAt
+	}
+	return now.Sub(*last) > StallFactor*s.Interval
+}
@@ -0,0 +1,47 @@
+package liveness
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStalled(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	interval := time.Minute
+
+	var tr Tracker
+	if tr.Status(interval).Stalled(start.Add(time.Hour)) {
+		t.Error("a job that was never started is stalled")
+	}
+
+	tr.Started(start)
+	if tr.Status(interval).Stalled(start.Add(90 * time.Second)) {
+		t.Error("stalled before the first run was due")
+	}
+	if !tr.Status(interval).Stalled(start.Add(3 * time.Minute)) {
+		t.Error("a job that never ran is not stalled after two intervals")
+	}
+
+	finished := tr.RunStarted(start.Add(5 * time.Minute))
+	status := tr.Status(interval)
+	if !status.Running || status.LastRunAt == nil || !status.LastRunAt.Equal(start.Add(5*time.Minute)) {
+		t.Fatalf("status during run = %+v", status)
+	}
+	if status.Stalled(start.Add(6 * time.Minute)) {
+		t.Error("stalled one interval after the last run")
+	}
+	// The run hangs: no new run starts
+	if !status.Stalled(start.Add(8 * time.Minute)) {
+		t.Error("a hanging run is not stalled after two intervals")
+	}
+
+	finished()
+	if tr.Status(interval).Running {
+		t.Error("still running after the run finished")
+	}
+
+	tr.Stopped()
+	if tr.Status(interval).Stalled(start.Add(time.Hour)) {
+		t.Error("a stopped job is stalled")
+	}
+}
@@ -0,0 +1,43 @@
+// Package logctx carries identifiers of the work in progress, such as the account
+// being synced, in a context, so that lower layers like the Instagram client can
+// attach them to their log records without taking them as arguments.
+package logctx
+
+import (
+	"context"
+	"log/slog"
+)
+
+type attrsKey struct{}
+
+// With returns a copy of ctx carrying args, given as alternating keys and values or
+// slog.Attr like slog.Logger.With. A key already carried by ctx is replaced.
+func With(ctx context.Context, args ...any) context.Context {
+	var r slog.Record
+	r.Add(args...)
+
+	prev, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	attrs := make([]slog.Attr, 0, len(prev)+r.NumAttrs())
+	attrs = append(attrs, prev...)
+	r.Attrs(func(a slog.Attr) bool {
+		for i := range attrs {
+			if attrs[i].Key == a.Key {
+				attrs[i] = a
+				return true
+			}
+		}
+		attrs = append(attrs, a)
+		return true
+	})
+	return context.WithValue(ctx, attrsKey{}, attrs)
+}
+
+// Args returns the attributes carried by ctx, ready to pass to slog.Logger.With
+func Args(ctx context.Context) []any {
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	args := make([]any, len(attrs))
+	for i, a := range attrs {
+		args[i] = a
+	}
+	return args
+}
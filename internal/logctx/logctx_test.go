@@ -0,0 +1,36 @@
+package logctx
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestWithReplacesKeys(t *testing.T) {
+	ctx := With(context.Background(), "account_id", "7", "operation", "sync")
+	ctx = With(ctx, "operation", "get_comments", slog.String("media_id", "m1"))
+
+	got := map[string]string{}
+	for _, arg := range Args(ctx) {
+		a := arg.(slog.Attr)
+		got[a.Key] = a.Value.String()
+	}
+	want := map[string]string{"account_id": "7", "operation": "get_comments", "media_id": "m1"}
+	if len(got) != len(want) {
+		t.Fatalf("attrs = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestWithDoesNotShareParentAttrs(t *testing.T) {
+	parent := With(context.Background(), "account_id", "7")
+	_ = With(parent, "account_id", "8")
+
+	if args := Args(parent); len(args) != 1 || args[0].(slog.Attr).Value.String() != "7" {
+		t.Errorf("parent attrs = %v, want account_id=7", args)
+	}
+}
@@ -0,0 +1,65 @@
+// Package mention finds the Instagram accounts a text @-mentions, such as the brand in a comment.
+package mention
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxUsernameLength is the longest username Instagram allows
+const MaxUsernameLength = 30
+
+// Extract returns the usernames mentioned in text, lowercased and without the @, in order of
+// first appearance. A mention is an @ not preceded by a letter, digit or underscore (so e-mail
+// addresses are skipped), followed by letters, digits, underscores and periods in any script.
+// Periods at the end belong to the sentence, as in "thanks @brand."; longer names than
+// Instagram allows are not mentions.
+func Extract(text string) []string {
+	var names []string
+	seen := make(map[string]bool)
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '@' || (i > 0 && (isWordRune(runes[i-1]) || runes[i-1] == '@')) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && isNameRune(runes[end]) {
+			end++
+		}
+		name := strings.TrimRight(string(runes[i+1:end]), ".")
+		i = end - 1
+
+		if name == "" || utf8.RuneCountInString(name) > MaxUsernameLength {
+			continue
+		}
+		name = strings.ToLower(name)
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// Normalize returns username the way Extract stores it: lowercased, without a leading @.
+// It returns "" if username is not a valid mention.
+func Normalize(username string) string {
+	username = strings.TrimPrefix(strings.TrimSpace(username), "@")
+	names := Extract("@" + username)
+	if len(names) != 1 || names[0] != strings.ToLower(username) {
+		return ""
+	}
+	return names[0]
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
+
+func isNameRune(r rune) bool {
+	return r == '.' || isWordRune(r)
+}
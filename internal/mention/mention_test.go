@@ -0,0 +1,51 @@
+package mention
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"no mentions here", nil},
+		{"@brand", []string{"brand"}},
+		{"Love it @Brand_Official!", []string{"brand_official"}},
+		{"thanks @brand.", []string{"brand"}},                               // Trailing period ends the sentence
+		{"ask @shop.team, @shop.team...", []string{"shop.team"}},            // Inner periods belong to the name, duplicates once
+		{"(@first) and @second?", []string{"first", "second"}},              // Surrounding punctuation
+		{"@магазин и @카페_서울 и @café", []string{"магазин", "카페_서울", "café"}}, // Unicode names
+		{"mail me at info@brand.com", nil},                                  // E-mail address
+		{"@ alone, @@double and @.", nil},
+		{"@one@two", []string{"one"}},
+		{"@" + strings.Repeat("a", MaxUsernameLength+1), nil}, // Longer than Instagram allows
+		{"@" + strings.Repeat("a", MaxUsernameLength), []string{strings.Repeat("a", MaxUsernameLength)}},
+	}
+	for _, tt := range tests {
+		if got := Extract(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Extract(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"Brand":        "brand",
+		" @Shop.Team ": "shop.team",
+		"@Café":        "café",
+		"":             "",
+		"@":            "",
+		"two words":    "",
+		"brand.":       "",
+		"info@brand":   "",
+	}
+	for in, want := range tests {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -1,18 +1,48 @@
 package storage
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 	"github.com/google/uuid"
 )
 
+// Upload errors, wrapping the storage error
+var (
+	ErrUploadRejected    = errors.New("upload rejected by storage")      // The request itself is invalid, e.g. too large
+	ErrUploadUnavailable = errors.New("storage temporarily unavailable") // Retries were exhausted or impossible
+)
+
+// Download errors
+var (
+	ErrObjectNotFound = errors.New("object not found")
+	ErrObjectTooLarge = errors.New("object too large")
+	ErrForeignURL     = errors.New("url is not in this storage")
+)
+
+const (
+	defaultUploadAttempts = 3
+	uploadRetryDelay      = 500 * time.Millisecond // Doubled after each failed attempt
+
+	defaultMultipartThreshold = 16 << 20
+	defaultPartSize           = 8 << 20
+	minPartSize               = 5 << 20 // S3 rejects smaller parts, except the last one
+	maxParts                  = 10000   // S3 limit on parts per upload
+)
+
 // S3Config holds S3/MinIO configuration
 type S3Config struct {
 	Endpoint        string // e.g., "http://localhost:9000" for MinIO
@@ -21,13 +51,33 @@ type S3Config struct {
 	Bucket          string
 	Region          string
 	PublicURL       string // Public URL for accessing files (e.g., "http://localhost:9000/media")
+	UploadAttempts  int    // Attempts per upload on transient errors; 0 uses the default of 3
+
+	MultipartThreshold int64 // Declared sizes above this are uploaded in parts; 0 uses the default of 16 MiB
+	PartSize           int64 // Size of each part; 0 uses the default of 8 MiB
+}
+
+// objectStore is the part of the S3 client used by S3Storage
+type objectStore interface {
+	PutObject(ctx context.Context, in *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	GetObject(ctx context.Context, in *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	DeleteObject(ctx context.Context, in *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+	CreateMultipartUpload(ctx context.Context, in *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error)
+	UploadPart(ctx context.Context, in *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error)
+	CompleteMultipartUpload(ctx context.Context, in *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error)
+	AbortMultipartUpload(ctx context.Context, in *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error)
 }
 
 // S3Storage provides S3-compatible storage operations
 type S3Storage struct {
-	client    *s3.Client
-	bucket    string
-	publicURL string
+	client     objectStore
+	bucket     string
+	publicURL  string
+	attempts   int
+	retryDelay time.Duration
+
+	multipartThreshold int64
+	partSize           int64
 }
 
 // NewS3Storage creates a new S3 storage client
@@ -44,10 +94,31 @@ func NewS3Storage(cfg S3Config) (*S3Storage, error) {
 		UsePathStyle: true, // Required for MinIO
 	})
 
+	attempts := cfg.UploadAttempts
+	if attempts <= 0 {
+		attempts = defaultUploadAttempts
+	}
+	threshold := cfg.MultipartThreshold
+	if threshold <= 0 {
+		threshold = defaultMultipartThreshold
+	}
+	partSize := cfg.PartSize
+	if partSize <= 0 {
+		partSize = defaultPartSize
+	}
+	if partSize < minPartSize {
+		return nil, fmt.Errorf("s3 part size %d is below the minimum of %d bytes", partSize, minPartSize)
+	}
+
 	return &S3Storage{
-		client:    client,
-		bucket:    cfg.Bucket,
-		publicURL: cfg.PublicURL,
+		client:     client,
+		bucket:     cfg.Bucket,
+		publicURL:  cfg.PublicURL,
+		attempts:   attempts,
+		retryDelay: uploadRetryDelay,
+
+		multipartThreshold: threshold,
+		partSize:           partSize,
 	}, nil
 }
 
@@ -67,7 +138,11 @@ type UploadOutput struct {
 	UploadedAt time.Time
 }
 
-// Upload uploads a file to S3 and returns the public URL
+// Upload uploads a file to S3 and returns the public URL.
+// Files declared larger than the multipart threshold are sent in parts, each retried on its own,
+// so a dropped connection only costs the current part. Smaller files go in a single PUT whose
+// transient failures are retried when the reader can be rewound (io.Seeker).
+// Errors wrap ErrUploadRejected if storage refused the request and ErrUploadUnavailable otherwise.
 func (s *S3Storage) Upload(ctx context.Context, in UploadInput) (*UploadOutput, error) {
 	// Generate unique key
 	ext := path.Ext(in.Filename)
@@ -77,15 +152,14 @@ func (s *S3Storage) Upload(ctx context.Context, in UploadInput) (*UploadOutput,
 	key := fmt.Sprintf("%s/%s%s", time.Now().Format("2006/01/02"), uuid.New().String(), ext)
 
 	// Upload to S3
-	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket:        aws.String(s.bucket),
-		Key:           aws.String(key),
-		Body:          in.Reader,
-		ContentType:   aws.String(in.ContentType),
-		ContentLength: aws.Int64(in.Size),
-	})
+	var err error
+	if in.Size > s.multipartThreshold {
+		err = s.uploadMultipart(ctx, key, in)
+	} else {
+		err = s.putObject(ctx, key, in)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("uploading to s3: %w", err)
+		return nil, err
 	}
 
 	// Build public URL
@@ -99,6 +173,192 @@ func (s *S3Storage) Upload(ctx context.Context, in UploadInput) (*UploadOutput,
 	}, nil
 }
 
+// putObject uploads the whole file in a single request
+func (s *S3Storage) putObject(ctx context.Context, key string, in UploadInput) error {
+	var rewind func() error
+	if seeker, ok := in.Reader.(io.Seeker); ok {
+		rewind = func() error {
+			_, err := seeker.Seek(0, io.SeekStart)
+			return err
+		}
+	}
+
+	return s.retry(ctx, "uploading to s3", rewind, func() error {
+		_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:        aws.String(s.bucket),
+			Key:           aws.String(key),
+			Body:          in.Reader,
+			ContentType:   aws.String(in.ContentType),
+			ContentLength: aws.Int64(in.Size),
+		})
+		return err
+	})
+}
+
+// uploadMultipart uploads the file in parts read one at a time into memory.
+// Any failure after the upload is initiated aborts it, so S3 doesn't keep billing for orphaned parts.
+func (s *S3Storage) uploadMultipart(ctx context.Context, key string, in UploadInput) (err error) {
+	var created *s3.CreateMultipartUploadOutput
+	err = s.retry(ctx, "initiating multipart upload", noRewind, func() error {
+		var cerr error
+		created, cerr = s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+			Bucket:      aws.String(s.bucket),
+			Key:         aws.String(key),
+			ContentType: aws.String(in.ContentType),
+		})
+		return cerr
+	})
+	if err != nil {
+		return err
+	}
+	uploadID := created.UploadId
+
+	defer func() {
+		if err == nil {
+			return
+		}
+		// Abort even if the request was cancelled, that's when orphaned uploads are most likely
+		_, abortErr := s.client.AbortMultipartUpload(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(s.bucket),
+			Key:      aws.String(key),
+			UploadId: uploadID,
+		})
+		if abortErr != nil {
+			err = errors.Join(err, fmt.Errorf("aborting multipart upload: %w", abortErr))
+		}
+	}()
+
+	// Grow parts for very large files so they fit in the S3 part limit
+	partSize := max(s.partSize, (in.Size+maxParts-1)/maxParts)
+	buf := make([]byte, partSize)
+	var parts []types.CompletedPart
+	for number := int32(1); ; number++ {
+		n, rerr := io.ReadFull(in.Reader, buf)
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil && rerr != io.ErrUnexpectedEOF {
+			return fmt.Errorf("reading upload part %d: %w", number, rerr)
+		}
+		if number > maxParts {
+			return fmt.Errorf("%w: file exceeds %d parts of %d bytes", ErrUploadRejected, maxParts, partSize)
+		}
+
+		body := bytes.NewReader(buf[:n])
+		var uploaded *s3.UploadPartOutput
+		err = s.retry(ctx, fmt.Sprintf("uploading part %d", number), func() error {
+			_, serr := body.Seek(0, io.SeekStart)
+			return serr
+		}, func() error {
+			var uerr error
+			uploaded, uerr = s.client.UploadPart(ctx, &s3.UploadPartInput{
+				Bucket:        aws.String(s.bucket),
+				Key:           aws.String(key),
+				UploadId:      uploadID,
+				PartNumber:    aws.Int32(number),
+				Body:          body,
+				ContentLength: aws.Int64(int64(n)),
+			})
+			return uerr
+		})
+		if err != nil {
+			return err
+		}
+		parts = append(parts, types.CompletedPart{ETag: uploaded.ETag, PartNumber: aws.Int32(number)})
+
+		if rerr == io.ErrUnexpectedEOF {
+			break
+		}
+	}
+	if len(parts) == 0 {
+		return fmt.Errorf("%w: upload is empty", ErrUploadRejected)
+	}
+
+	return s.retry(ctx, "completing multipart upload", noRewind, func() error {
+		_, cerr := s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(s.bucket),
+			Key:             aws.String(key),
+			UploadId:        uploadID,
+			MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+		})
+		return cerr
+	})
+}
+
+// noRewind is the rewind func for requests without a body
+func noRewind() error { return nil }
+
+// retry runs call until it succeeds, storage rejects it, or attempts run out, doubling the delay
+// between tries. rewind resets the request body before each retry; nil means the body can't be
+// sent twice, so the first transient failure is final.
+func (s *S3Storage) retry(ctx context.Context, op string, rewind func() error, call func() error) error {
+	delay := s.retryDelay
+	for attempt := 1; ; attempt++ {
+		err := call()
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if isRejected(err) {
+			return fmt.Errorf("%w: %s: %w", ErrUploadRejected, op, err)
+		}
+		if attempt >= s.attempts || rewind == nil {
+			return fmt.Errorf("%w: %s (attempt %d): %w", ErrUploadUnavailable, op, attempt, err)
+		}
+		if rerr := rewind(); rerr != nil {
+			return fmt.Errorf("%w: rewinding upload: %w", ErrUploadUnavailable, rerr)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: %w", op, ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// Download reads an object into memory, refusing objects larger than maxSize bytes
+func (s *S3Storage) Download(ctx context.Context, key string, maxSize int64) ([]byte, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var noKey *types.NoSuchKey
+		if errors.As(err, &noKey) {
+			return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+		}
+		return nil, fmt.Errorf("downloading from s3: %w", err)
+	}
+	defer out.Body.Close()
+
+	if size := aws.ToInt64(out.ContentLength); size > maxSize {
+		return nil, fmt.Errorf("%w: %d bytes exceeds %d", ErrObjectTooLarge, size, maxSize)
+	}
+	// The declared length can't be trusted to bound the read
+	data, err := io.ReadAll(io.LimitReader(out.Body, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading s3 object: %w", err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("%w: exceeds %d bytes", ErrObjectTooLarge, maxSize)
+	}
+	return data, nil
+}
+
+// KeyFromURL returns the object key of a public URL produced by Upload.
+// URLs pointing anywhere else return ErrForeignURL, so callers never fetch arbitrary hosts.
+func (s *S3Storage) KeyFromURL(rawURL string) (string, error) {
+	key, ok := strings.CutPrefix(rawURL, s.publicURL+"/")
+	if !ok || key == "" {
+		return "", fmt.Errorf("%w: %s", ErrForeignURL, rawURL)
+	}
+	return key, nil
+}
+
 // Delete removes a file from S3
 func (s *S3Storage) Delete(ctx context.Context, key string) error {
 	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
@@ -111,6 +371,23 @@ func (s *S3Storage) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+// isRejected reports whether S3 refused the upload itself, e.g. as too large, so sending it
+// again cannot help. Network errors, throttling, server faults and our own misconfigured
+// credentials are not the uploader's fault and are retried.
+func isRejected(err error) bool {
+	var respErr *smithyhttp.ResponseError
+	if errors.As(err, &respErr) {
+		switch status := respErr.HTTPStatusCode(); status {
+		case http.StatusUnauthorized, http.StatusForbidden, http.StatusRequestTimeout, http.StatusTooManyRequests:
+			return false
+		default:
+			return status >= 400 && status < 500
+		}
+	}
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorFault() == smithy.FaultClient
+}
+
 // getExtensionFromContentType returns file extension based on content type
 func getExtensionFromContentType(contentType string) string {
 	switch contentType {
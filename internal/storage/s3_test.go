@@ -0,0 +1,224 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// flakyStore fails the first len(errs) uploads with those errors and records every body it receives
+type flakyStore struct {
+	objectStore
+	errs   []error
+	bodies []string
+}
+
+func (f *flakyStore) PutObject(_ context.Context, in *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	body, _ := io.ReadAll(in.Body)
+	f.bodies = append(f.bodies, string(body))
+	if n := len(f.bodies); n <= len(f.errs) {
+		return nil, f.errs[n-1]
+	}
+	return &s3.PutObjectOutput{}, nil
+}
+
+func responseError(status int) error {
+	return &smithyhttp.ResponseError{
+		Response: &smithyhttp.Response{Response: &http.Response{StatusCode: status}},
+		Err:      errors.New("s3 error"),
+	}
+}
+
+func newTestStorage(store objectStore, attempts int) *S3Storage {
+	return &S3Storage{
+		client:             store,
+		bucket:             "media",
+		publicURL:          "https://cdn.example.com",
+		attempts:           attempts,
+		multipartThreshold: defaultMultipartThreshold,
+		partSize:           defaultPartSize,
+	}
+}
+
+func TestUploadRetriesTransientErrors(t *testing.T) {
+	store := &flakyStore{errs: []error{&net.OpError{Op: "write", Err: errors.New("connection reset")}, responseError(503)}}
+	s := newTestStorage(store, 3)
+
+	out, err := s.Upload(context.Background(), UploadInput{Reader: strings.NewReader("video"), ContentType: "video/mp4", Size: 5})
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if len(store.bodies) != 3 {
+		t.Fatalf("attempts = %d, want 3", len(store.bodies))
+	}
+	for i, body := range store.bodies {
+		if body != "video" {
+			t.Errorf("attempt %d sent %q, want the whole file", i+1, body)
+		}
+	}
+	if !strings.HasPrefix(out.URL, "https://cdn.example.com/") || !strings.HasSuffix(out.URL, ".mp4") {
+		t.Errorf("URL = %s", out.URL)
+	}
+}
+
+func TestUploadGivesUpAfterAttempts(t *testing.T) {
+	store := &flakyStore{errs: []error{responseError(500), responseError(500), responseError(500)}}
+	s := newTestStorage(store, 2)
+
+	_, err := s.Upload(context.Background(), UploadInput{Reader: strings.NewReader("video"), ContentType: "video/mp4", Size: 5})
+	if !errors.Is(err, ErrUploadUnavailable) {
+		t.Fatalf("err = %v, want ErrUploadUnavailable", err)
+	}
+	if len(store.bodies) != 2 {
+		t.Errorf("attempts = %d, want 2", len(store.bodies))
+	}
+}
+
+func TestUploadDoesNotRetryRejectedFile(t *testing.T) {
+	store := &flakyStore{errs: []error{responseError(400)}}
+	s := newTestStorage(store, 3)
+
+	_, err := s.Upload(context.Background(), UploadInput{Reader: strings.NewReader("video"), ContentType: "video/mp4", Size: 5})
+	if !errors.Is(err, ErrUploadRejected) {
+		t.Fatalf("err = %v, want ErrUploadRejected", err)
+	}
+	if len(store.bodies) != 1 {
+		t.Errorf("attempts = %d, want 1", len(store.bodies))
+	}
+}
+
+func TestUploadDoesNotRetryUnrewindableReader(t *testing.T) {
+	store := &flakyStore{errs: []error{responseError(503)}}
+	s := newTestStorage(store, 3)
+
+	_, err := s.Upload(context.Background(), UploadInput{Reader: io.MultiReader(strings.NewReader("video")), ContentType: "video/mp4", Size: 5})
+	if !errors.Is(err, ErrUploadUnavailable) {
+		t.Fatalf("err = %v, want ErrUploadUnavailable", err)
+	}
+	if len(store.bodies) != 1 {
+		t.Errorf("attempts = %d, want 1", len(store.bodies))
+	}
+}
+
+// multipartStore records multipart calls and fails UploadPart for the part numbers in failParts
+type multipartStore struct {
+	flakyStore
+	failParts map[int32]error
+	created   int
+	parts     map[int32]string
+	completed []types.CompletedPart
+	aborted   bool
+}
+
+func (m *multipartStore) CreateMultipartUpload(_ context.Context, _ *s3.CreateMultipartUploadInput, _ ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	m.created++
+	return &s3.CreateMultipartUploadOutput{UploadId: aws.String("upload-1")}, nil
+}
+
+func (m *multipartStore) UploadPart(_ context.Context, in *s3.UploadPartInput, _ ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	body, _ := io.ReadAll(in.Body)
+	number := aws.ToInt32(in.PartNumber)
+	if err, ok := m.failParts[number]; ok {
+		delete(m.failParts, number)
+		return nil, err
+	}
+	if m.parts == nil {
+		m.parts = map[int32]string{}
+	}
+	m.parts[number] = string(body)
+	return &s3.UploadPartOutput{ETag: aws.String(fmt.Sprintf("etag-%d", number))}, nil
+}
+
+func (m *multipartStore) CompleteMultipartUpload(_ context.Context, in *s3.CompleteMultipartUploadInput, _ ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	m.completed = in.MultipartUpload.Parts
+	return &s3.CompleteMultipartUploadOutput{}, nil
+}
+
+func (m *multipartStore) AbortMultipartUpload(_ context.Context, _ *s3.AbortMultipartUploadInput, _ ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	m.aborted = true
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
+
+func newMultipartStorage(store objectStore, threshold, partSize int64) *S3Storage {
+	s := newTestStorage(store, 3)
+	s.multipartThreshold = threshold
+	s.partSize = partSize
+	return s
+}
+
+func TestUploadUsesSinglePutBelowThreshold(t *testing.T) {
+	store := &multipartStore{}
+	s := newMultipartStorage(store, 10, 4)
+
+	if _, err := s.Upload(context.Background(), UploadInput{Reader: strings.NewReader("0123456789"), ContentType: "video/mp4", Size: 10}); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if len(store.bodies) != 1 || store.created != 0 {
+		t.Errorf("puts = %d, multipart uploads = %d; want a single put", len(store.bodies), store.created)
+	}
+}
+
+func TestUploadUsesMultipartAboveThreshold(t *testing.T) {
+	// Part 2 drops once and must be resent on its own
+	store := &multipartStore{failParts: map[int32]error{2: responseError(503)}}
+	s := newMultipartStorage(store, 10, 4)
+
+	if _, err := s.Upload(context.Background(), UploadInput{Reader: io.MultiReader(strings.NewReader("0123456789a")), ContentType: "video/mp4", Size: 11}); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if len(store.bodies) != 0 || store.created != 1 {
+		t.Fatalf("puts = %d, multipart uploads = %d; want one multipart upload", len(store.bodies), store.created)
+	}
+	want := map[int32]string{1: "0123", 2: "4567", 3: "89a"}
+	for number, body := range want {
+		if store.parts[number] != body {
+			t.Errorf("part %d = %q, want %q", number, store.parts[number], body)
+		}
+	}
+	if len(store.completed) != 3 || aws.ToString(store.completed[2].ETag) != "etag-3" {
+		t.Errorf("completed parts = %+v", store.completed)
+	}
+	if store.aborted {
+		t.Error("successful upload was aborted")
+	}
+}
+
+func TestUploadAbortsFailedMultipart(t *testing.T) {
+	store := &multipartStore{failParts: map[int32]error{2: responseError(400)}}
+	s := newMultipartStorage(store, 10, 4)
+
+	_, err := s.Upload(context.Background(), UploadInput{Reader: strings.NewReader("0123456789a"), ContentType: "video/mp4", Size: 11})
+	if !errors.Is(err, ErrUploadRejected) {
+		t.Fatalf("err = %v, want ErrUploadRejected", err)
+	}
+	if !store.aborted {
+		t.Error("failed multipart upload was not aborted")
+	}
+	if store.completed != nil {
+		t.Error("failed multipart upload was completed")
+	}
+}
+
+func TestKeyFromURL(t *testing.T) {
+	s := newTestStorage(&flakyStore{}, 1)
+
+	key, err := s.KeyFromURL("https://cdn.example.com/2024/05/01/photo.jpg")
+	if err != nil || key != "2024/05/01/photo.jpg" {
+		t.Errorf("KeyFromURL = %q, %v", key, err)
+	}
+	for _, u := range []string{"https://evil.example.com/photo.jpg", "https://cdn.example.com.evil.com/photo.jpg", "https://cdn.example.com/"} {
+		if _, err := s.KeyFromURL(u); !errors.Is(err, ErrForeignURL) {
+			t.Errorf("KeyFromURL(%s) err = %v, want ErrForeignURL", u, err)
+		}
+	}
+}
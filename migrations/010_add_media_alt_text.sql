@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Add alt_text column to publication_media table
+-- Accessibility description passed to Instagram for image media
+ALTER TABLE publication_media ADD COLUMN IF NOT EXISTS alt_text TEXT;
+
+COMMENT ON COLUMN publication_media.alt_text IS 'Accessibility alt text for image media (max 1000 characters)';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE publication_media DROP COLUMN IF EXISTS alt_text;
+
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Add deleted_at column to publications table
+-- Drafts are moved to trash instead of being deleted immediately
+ALTER TABLE publications ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+COMMENT ON COLUMN publications.deleted_at IS 'When the publication was moved to trash (NULL if not trashed)';
+
+CREATE INDEX IF NOT EXISTS idx_publications_deleted_at ON publications(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_publications_deleted_at;
+ALTER TABLE publications DROP COLUMN IF EXISTS deleted_at;
+
+-- +goose StatementEnd
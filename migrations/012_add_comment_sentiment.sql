@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Add sentiment column to comments table
+-- Filled in after sync by the comment classifier (NULL until classified)
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS sentiment VARCHAR(16);
+
+CREATE INDEX IF NOT EXISTS idx_comments_media_sentiment ON comments(instagram_media_id, sentiment);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_comments_media_sentiment;
+ALTER TABLE comments DROP COLUMN IF EXISTS sentiment;
+
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Add reaction column to dm_messages table
+-- Emoji reaction we sent to the message (NULL if none)
+ALTER TABLE dm_messages ADD COLUMN IF NOT EXISTS reaction VARCHAR(32);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE dm_messages DROP COLUMN IF EXISTS reaction;
+
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Per-account labels on DM conversations (e.g. "lead", "complaint", "resolved")
+CREATE TABLE IF NOT EXISTS conversation_labels (
+    conversation_id TEXT NOT NULL REFERENCES dm_conversations(id) ON DELETE CASCADE,
+    account_id BIGINT NOT NULL REFERENCES instagram_accounts(id) ON DELETE CASCADE,
+    label VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (conversation_id, label)
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversation_labels_account_label ON conversation_labels(account_id, label);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_conversation_labels_account_label;
+DROP TABLE IF EXISTS conversation_labels;
+
+-- +goose StatementEnd
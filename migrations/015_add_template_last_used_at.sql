@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Add last_used_at column to templates table
+-- Set together with usage_count each time a template is used (NULL if never used)
+ALTER TABLE templates ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_templates_account_last_used ON templates(account_id, last_used_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_templates_account_last_used;
+ALTER TABLE templates DROP COLUMN IF EXISTS last_used_at;
+
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Add GIN index for full-text search on template title and content
+CREATE INDEX IF NOT EXISTS idx_templates_text_search ON templates
+    USING GIN (to_tsvector('simple', COALESCE(title, '') || ' ' || COALESCE(content, '')));
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_templates_text_search;
+
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Add container_id column to publications table
+-- Instagram media container (creation_id) created for the publication but not yet published.
+-- Kept so a retry can call media_publish again instead of creating an orphaned container.
+ALTER TABLE publications ADD COLUMN IF NOT EXISTS container_id VARCHAR(64);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE publications DROP COLUMN IF EXISTS container_id;
+
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Add cached profile columns to instagram_accounts table
+-- Filled by POST /accounts/{id}/refresh-profile from the Graph API user node.
+ALTER TABLE instagram_accounts ADD COLUMN IF NOT EXISTS name VARCHAR(255);
+ALTER TABLE instagram_accounts ADD COLUMN IF NOT EXISTS profile_picture_url TEXT;
+ALTER TABLE instagram_accounts ADD COLUMN IF NOT EXISTS followers_count INTEGER;
+ALTER TABLE instagram_accounts ADD COLUMN IF NOT EXISTS media_count INTEGER;
+ALTER TABLE instagram_accounts ADD COLUMN IF NOT EXISTS profile_refreshed_at TIMESTAMP;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE instagram_accounts DROP COLUMN IF EXISTS profile_refreshed_at;
+ALTER TABLE instagram_accounts DROP COLUMN IF EXISTS media_count;
+ALTER TABLE instagram_accounts DROP COLUMN IF EXISTS followers_count;
+ALTER TABLE instagram_accounts DROP COLUMN IF EXISTS profile_picture_url;
+ALTER TABLE instagram_accounts DROP COLUMN IF EXISTS name;
+
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Add token check result to instagram_accounts table
+-- token_status: valid, unauthorized, missing or error; NULL until the first check.
+ALTER TABLE instagram_accounts ADD COLUMN IF NOT EXISTS token_status VARCHAR(20);
+ALTER TABLE instagram_accounts ADD COLUMN IF NOT EXISTS token_checked_at TIMESTAMP;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE instagram_accounts DROP COLUMN IF EXISTS token_checked_at;
+ALTER TABLE instagram_accounts DROP COLUMN IF EXISTS token_status;
+
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Add retry tracking to publications table
+-- A scheduled publication that fails with a temporary Instagram error stays scheduled,
+-- and the scheduler picks it up again once next_attempt_at has passed.
+ALTER TABLE publications ADD COLUMN IF NOT EXISTS publish_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE publications ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE publications DROP COLUMN IF EXISTS next_attempt_at;
+ALTER TABLE publications DROP COLUMN IF EXISTS publish_attempts;
+
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Participant data exports look up every conversation an account has with one user
+CREATE INDEX IF NOT EXISTS idx_dm_conversations_account_participant ON dm_conversations(account_id, participant_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_dm_conversations_account_participant;
+
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Store media details reported by Instagram after publishing
+-- media_product_type is FEED, STORY or REELS; comments_enabled is NULL until fetched.
+-- Comment sync skips media whose comments are disabled.
+ALTER TABLE publications ADD COLUMN IF NOT EXISTS media_product_type TEXT;
+ALTER TABLE publications ADD COLUMN IF NOT EXISTS comments_enabled BOOLEAN;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE publications DROP COLUMN IF EXISTS comments_enabled;
+ALTER TABLE publications DROP COLUMN IF EXISTS media_product_type;
+
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Audit log of mutating actions (publish, delete, comment moderation, DM send)
+-- account_id is kept as text without a foreign key, so entries outlive deleted accounts.
+-- principal identifies the API key that made the request; NULL for scheduler actions
+-- or when authentication is disabled.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    account_id TEXT,
+    principal TEXT,
+    action TEXT NOT NULL,
+    target_id TEXT,
+    success BOOLEAN NOT NULL,
+    error_message TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_account_created ON audit_log(account_id, created_at DESC);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS audit_log;
+
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Track when a conversation was last read; unread counts are computed from
+-- incoming messages newer than last_read_at, so they stay correct across devices.
+-- The stored unread_count column is deprecated and no longer written.
+ALTER TABLE dm_conversations ADD COLUMN IF NOT EXISTS last_read_at TIMESTAMP;
+
+-- Speeds up counting incoming messages per conversation
+CREATE INDEX IF NOT EXISTS idx_dm_messages_incoming ON dm_messages(conversation_id, timestamp) WHERE NOT is_from_me;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_dm_messages_incoming;
+ALTER TABLE dm_conversations DROP COLUMN IF EXISTS last_read_at;
+
+-- +goose StatementEnd
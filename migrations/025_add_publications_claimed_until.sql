@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- A scheduler instance claims a due publication until claimed_until before publishing it,
+-- so overlapping runs or several instances never publish the same row twice.
+-- An expired claim (e.g. the instance crashed) makes the publication due again.
+ALTER TABLE publications ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMP;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE publications DROP COLUMN IF EXISTS claimed_until;
+
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Instagram usernames invited as collaborators on a feed post or carousel.
+-- Reels keep theirs in reel_options.collaborator_usernames.
+ALTER TABLE publications ADD COLUMN IF NOT EXISTS collaborators TEXT[];
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE publications DROP COLUMN IF EXISTS collaborators;
+
+-- +goose StatementEnd
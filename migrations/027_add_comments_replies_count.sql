@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Number of replies reported by Instagram when the comment was last synced.
+-- Reads use the larger of this and the replies stored locally, so the count is
+-- right before the replies themselves are synced.
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS replies_count INTEGER NOT NULL DEFAULT 0;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE comments DROP COLUMN IF EXISTS replies_count;
+
+-- +goose StatementEnd
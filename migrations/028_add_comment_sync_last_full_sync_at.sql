@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- When the comments of a media were last fetched in full. Full syncs remove
+-- comments that are no longer listed by Instagram (deleted by their authors).
+ALTER TABLE comment_sync_status ADD COLUMN IF NOT EXISTS last_full_sync_at TIMESTAMP;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE comment_sync_status DROP COLUMN IF EXISTS last_full_sync_at;
+
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- When the conversation list of an account was last fetched in full. Between full
+-- passes only recently updated conversations are fetched; an interrupted full pass
+-- resumes from next_cursor.
+ALTER TABLE dm_account_sync_status ADD COLUMN IF NOT EXISTS last_full_sync_at TIMESTAMP;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE dm_account_sync_status DROP COLUMN IF EXISTS last_full_sync_at;
+
+-- +goose StatementEnd
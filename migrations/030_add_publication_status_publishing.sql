@@ -0,0 +1,18 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+-- +goose StatementBegin
+
+-- Publications waiting in or taken from the async publish queue. The queue worker claims
+-- them through claimed_until like the scheduler does; the publish result sets the final status.
+-- ADD VALUE cannot run inside a transaction block before PostgreSQL 12.
+ALTER TYPE publication_status ADD VALUE IF NOT EXISTS 'publishing';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- Enum values cannot be dropped; put queued publications back to draft so nothing uses it
+UPDATE publications SET status = 'draft', claimed_until = NULL WHERE status = 'publishing';
+
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Per-account override of how long stored comments are served before they are
+-- refreshed from Instagram. NULL uses the global COMMENT_CACHE_MAX_AGE.
+ALTER TABLE instagram_accounts ADD COLUMN IF NOT EXISTS comment_cache_max_age_seconds INTEGER;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE instagram_accounts DROP COLUMN IF EXISTS comment_cache_max_age_seconds;
+
+-- +goose StatementEnd
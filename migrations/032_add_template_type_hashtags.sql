@@ -0,0 +1,17 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+-- +goose StatementBegin
+
+-- Hashtag sets: templates whose content is posted as the first comment of a publication.
+-- ADD VALUE cannot run inside a transaction block before PostgreSQL 12.
+ALTER TYPE template_type ADD VALUE IF NOT EXISTS 'hashtags';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- Enum values cannot be dropped; keep the sets as comment templates
+UPDATE templates SET type = 'comment' WHERE type = 'hashtags';
+
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Hashtag set posted as the first comment once the publication is published.
+-- Deleting the set leaves the publication without a first comment.
+ALTER TABLE publications ADD COLUMN IF NOT EXISTS hashtag_set_id UUID REFERENCES templates(id) ON DELETE SET NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE publications DROP COLUMN IF EXISTS hashtag_set_id;
+
+-- +goose StatementEnd
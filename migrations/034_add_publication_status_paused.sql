@@ -0,0 +1,17 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+-- +goose StatementBegin
+
+-- Scheduled publications the scheduler skips until they are resumed; they keep scheduled_at.
+-- ADD VALUE cannot run inside a transaction block before PostgreSQL 12.
+ALTER TYPE publication_status ADD VALUE IF NOT EXISTS 'paused';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- Enum values cannot be dropped; put paused publications back on the schedule so nothing uses it
+UPDATE publications SET status = 'scheduled' WHERE status = 'paused';
+
+-- +goose StatementEnd
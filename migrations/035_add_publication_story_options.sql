@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Link sticker and mentions of a story, see entity.StoryOptions
+ALTER TABLE publications ADD COLUMN IF NOT EXISTS story_options JSONB;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE publications DROP COLUMN IF EXISTS story_options;
+
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Usernames @-mentioned in a comment's text, lowercased and without the @ (see package mention).
+-- Filled when comments are stored; comments synced before this migration get theirs on the next full sync.
+CREATE TABLE IF NOT EXISTS comment_mentions (
+    comment_id VARCHAR(64) NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    username VARCHAR(255) NOT NULL,
+    PRIMARY KEY (comment_id, username)
+);
+
+CREATE INDEX IF NOT EXISTS idx_comment_mentions_username ON comment_mentions(username);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS comment_mentions;
+
+-- +goose StatementEnd
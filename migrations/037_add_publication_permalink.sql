@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Link to the published post on Instagram, reported when it is published.
+-- Publications published before this column are filled in by the permalink backfill.
+ALTER TABLE publications ADD COLUMN IF NOT EXISTS permalink TEXT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE publications DROP COLUMN IF EXISTS permalink;
+
+-- +goose StatementEnd
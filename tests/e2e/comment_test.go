@@ -0,0 +1,69 @@
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+)
+
+type CommentStatistics struct {
+	TotalComments   int64 `json:"total_comments"`
+	RepliedComments int64 `json:"replied_comments"`
+	TopPosts        []struct {
+		MediaID       string `json:"media_id"`
+		CommentsCount int64  `json:"comments_count"`
+	} `json:"top_posts"`
+}
+
+// Helper function to fetch comment statistics with extra query params
+func getCommentStatistics(t *testing.T, query string) CommentStatistics {
+	t.Helper()
+
+	resp, err := http.Get(fmt.Sprintf("%s/comments/statistics?account_id=%s%s", baseURL, accountID, query))
+	if err != nil {
+		t.Fatalf("Failed to get comment statistics: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		t.Fatalf("Expected status 200, got %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var stats CommentStatistics
+	json.NewDecoder(resp.Body).Decode(&stats)
+	return stats
+}
+
+// TestCommentStatisticsDateRange tests GET /comments/statistics with start_date/end_date
+func TestCommentStatisticsDateRange(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping e2e test in short mode")
+	}
+
+	allTime := getCommentStatistics(t, "")
+
+	t.Run("future range has no comments", func(t *testing.T) {
+		stats := getCommentStatistics(t, "&start_date=2999-01-01&end_date=2999-12-31")
+
+		if stats.TotalComments != 0 || stats.RepliedComments != 0 {
+			t.Errorf("Expected zero counts, got total=%d replied=%d", stats.TotalComments, stats.RepliedComments)
+		}
+		for _, p := range stats.TopPosts {
+			if p.CommentsCount != 0 {
+				t.Errorf("Expected top post %s to have 0 comments in range, got %d", p.MediaID, p.CommentsCount)
+			}
+		}
+	})
+
+	t.Run("range never exceeds all-time", func(t *testing.T) {
+		stats := getCommentStatistics(t, "&start_date=2025-01-01")
+
+		if stats.TotalComments > allTime.TotalComments {
+			t.Errorf("Ranged total %d exceeds all-time total %d", stats.TotalComments, allTime.TotalComments)
+		}
+		t.Logf("All-time comments: %d, since 2025-01-01: %d", allTime.TotalComments, stats.TotalComments)
+	})
+}
@@ -181,8 +181,8 @@ func TestPublicationCreate(t *testing.T) {
 		}
 		defer resp.Body.Close()
 
-		if resp.StatusCode != http.StatusBadRequest {
-			t.Errorf("Expected status 400, got %d", resp.StatusCode)
+		if resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status 422, got %d", resp.StatusCode)
 		}
 	})
 
@@ -201,8 +201,8 @@ func TestPublicationCreate(t *testing.T) {
 		}
 		defer resp.Body.Close()
 
-		if resp.StatusCode != http.StatusBadRequest {
-			t.Errorf("Expected status 400, got %d", resp.StatusCode)
+		if resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status 422, got %d", resp.StatusCode)
 		}
 	})
 }
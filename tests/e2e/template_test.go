@@ -0,0 +1,144 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type CreateTemplateRequest struct {
+	AccountID string `json:"account_id"`
+	Title     string `json:"title"`
+	Content   string `json:"content"`
+	Type      string `json:"type"`
+}
+
+type Template struct {
+	ID        string `json:"id"`
+	AccountID string `json:"account_id"`
+	Title     string `json:"title"`
+	Content   string `json:"content"`
+	Type      string `json:"type"`
+}
+
+type TemplateListResponse struct {
+	Templates []Template `json:"templates"`
+	Total     int64      `json:"total"`
+}
+
+// Helper function to create a test template
+func createTestTemplate(t *testing.T, title, content, templateType string) Template {
+	t.Helper()
+
+	body, _ := json.Marshal(CreateTemplateRequest{
+		AccountID: accountID,
+		Title:     title,
+		Content:   content,
+		Type:      templateType,
+	})
+	resp, err := http.Post(baseURL+"/templates", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create template: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		respBody, _ := io.ReadAll(resp.Body)
+		t.Fatalf("Expected status 201, got %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var tmpl Template
+	json.NewDecoder(resp.Body).Decode(&tmpl)
+	return tmpl
+}
+
+// Helper function to delete a template
+func deleteTestTemplate(t *testing.T, id string) {
+	t.Helper()
+
+	req, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/templates/%s?account_id=%s", baseURL, id, accountID), nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Logf("Warning: Failed to delete template %s: %v", id, err)
+		return
+	}
+	defer resp.Body.Close()
+}
+
+// Helper function to search templates
+func searchTemplates(t *testing.T, params url.Values) TemplateListResponse {
+	t.Helper()
+
+	params.Set("account_id", accountID)
+	resp, err := http.Get(baseURL + "/templates?" + params.Encode())
+	if err != nil {
+		t.Fatalf("Failed to list templates: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		t.Fatalf("Expected status 200, got %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var listResp TemplateListResponse
+	json.NewDecoder(resp.Body).Decode(&listResp)
+	return listResp
+}
+
+// TestTemplateSearch tests GET /templates?q=
+func TestTemplateSearch(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping e2e test in short mode")
+	}
+
+	// Unique word so the search does not pick up unrelated templates
+	marker := fmt.Sprintf("searchmarker%d", time.Now().UnixNano())
+
+	direct := createTestTemplate(t, "E2E search direct", "Thanks for your order "+marker, "direct")
+	defer deleteTestTemplate(t, direct.ID)
+	comment := createTestTemplate(t, "E2E search comment", "See our catalog "+marker, "comment")
+	defer deleteTestTemplate(t, comment.ID)
+	other := createTestTemplate(t, "E2E search other", "Unrelated content", "direct")
+	defer deleteTestTemplate(t, other.ID)
+
+	t.Run("search by content word", func(t *testing.T) {
+		listResp := searchTemplates(t, url.Values{"q": {marker}})
+
+		if listResp.Total != 2 {
+			t.Errorf("Expected total 2, got %d", listResp.Total)
+		}
+		for _, tmpl := range listResp.Templates {
+			if tmpl.ID == other.ID {
+				t.Errorf("Template %s should not match search", other.ID)
+			}
+		}
+	})
+
+	t.Run("search with type filter", func(t *testing.T) {
+		listResp := searchTemplates(t, url.Values{"q": {marker}, "type": {"comment"}})
+
+		if listResp.Total != 1 || len(listResp.Templates) != 1 {
+			t.Fatalf("Expected 1 template, got %d (total: %d)", len(listResp.Templates), listResp.Total)
+		}
+		if listResp.Templates[0].ID != comment.ID {
+			t.Errorf("Expected template %s, got %s", comment.ID, listResp.Templates[0].ID)
+		}
+	})
+
+	t.Run("search with pagination", func(t *testing.T) {
+		listResp := searchTemplates(t, url.Values{"q": {marker}, "limit": {"1"}})
+
+		if len(listResp.Templates) != 1 {
+			t.Errorf("Expected 1 template on page, got %d", len(listResp.Templates))
+		}
+		if listResp.Total != 2 {
+			t.Errorf("Expected total 2, got %d", listResp.Total)
+		}
+	})
+}